@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/yourorg/nms-go/internal/alert"
+	"github.com/yourorg/nms-go/internal/alert/repository"
 	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/database"
 	"github.com/yourorg/nms-go/internal/common/queue"
+	devicerepo "github.com/yourorg/nms-go/internal/device/repository"
+	deviceservice "github.com/yourorg/nms-go/internal/device/service"
 	"github.com/yourorg/nms-go/internal/notification"
 )
 
@@ -21,16 +27,58 @@ func main() {
 	}
 
 	// Connect to NATS
-	nc, err := queue.NewNATSConnection(cfg.NATS)
+	natsConn, err := queue.NewNATSConnection(cfg.NATS)
 	if err != nil {
 		log.Fatalf("Failed to connect to NATS: %v", err)
 	}
-	defer nc.Close()
+	defer natsConn.Close()
+
+	// Poll tasks and metrics go through JetStream streams unless disabled
+	nc, err := queue.NewConn(natsConn, cfg.NATS)
+	if err != nil {
+		log.Fatalf("Failed to set up NATS JetStream: %v", err)
+	}
+
+	// Connect to Database (alert history)
+	db, err := database.NewPostgresConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+
+	if err := database.EnsureUniqueWhere(db, "alert_history", alert.ActiveCondition, "device_id", "rule_id"); err != nil {
+		log.Printf("Failed to run migrations: %v", err)
+	}
+	if err := database.Migrate(db, &alert.AlertHistory{}); err != nil {
+		log.Printf("Failed to run migrations: %v", err)
+	}
 
 	// Initialize Services
 	notifier := notification.NewEmailService()
-	engine := alert.NewEngine(nc, notifier)
-	go engine.Start()
+	alertRepo := repository.NewAlertRepository(db)
+	engine := alert.NewEngine(nc, notifier, alertRepo, cfg.Alert)
+	for _, wh := range cfg.Notification.Webhooks {
+		webhook, err := notification.NewWebhookNotifier(wh)
+		if err != nil {
+			log.Fatalf("Invalid webhook channel: %v", err)
+		}
+		engine.RegisterChannel(wh.Name, webhook)
+	}
+	if cfg.Notification.Telegram.Enabled {
+		groups := deviceservice.NewGroupService(devicerepo.NewGroupRepository(db), devicerepo.NewDeviceRepository(db))
+		telegram, err := notification.NewTelegramNotifier(cfg.Notification.Telegram, deviceGroupsFunc(groups))
+		if err != nil {
+			log.Fatalf("Invalid telegram channel: %v", err)
+		}
+		engine.RegisterChannel(notification.TelegramChannel, telegram)
+	}
+	if err := engine.CheckChannels(); err != nil {
+		log.Fatalf("Invalid alert routing: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		engine.Start()
+		close(done)
+	}()
 
 	// Wait for shutdown signal
 	c := make(chan os.Signal, 1)
@@ -39,4 +87,28 @@ func main() {
 
 	log.Println("Stopping Alert Service...")
 	engine.Stop()
+
+	// Start returns once the queued notifications are sent. The timeout
+	// leaves room for a webhook's retries but bounds a hung channel.
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		log.Println("Timed out sending queued notifications; the rest are dropped")
+	}
+}
+
+// deviceGroupsFunc looks up device groups in the device registry, which
+// shares the alert database
+func deviceGroupsFunc(groups deviceservice.GroupService) notification.DeviceGroupsFunc {
+	return func(ctx context.Context, deviceID string) ([]notification.GroupRef, error) {
+		path, err := groups.DeviceGroupPath(ctx, deviceID)
+		if err != nil {
+			return nil, err
+		}
+		refs := make([]notification.GroupRef, len(path))
+		for i, g := range path {
+			refs[i] = notification.GroupRef{ID: g.ID, Name: g.Name}
+		}
+		return refs, nil
+	}
 }
@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/yourorg/nms-go/internal/alert"
 	apigateway "github.com/yourorg/nms-go/internal/api-gateway"
 	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	"github.com/yourorg/nms-go/internal/common/database"
+	"github.com/yourorg/nms-go/internal/common/sink"
 	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
 	"github.com/yourorg/nms-go/internal/features/monitoring"
+	"github.com/yourorg/nms-go/internal/features/olt"
+	"github.com/yourorg/nms-go/internal/features/tr069"
 	// "github.com/yourorg/nms-go/internal/common/database"
 )
 
@@ -19,6 +31,15 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Stored device credentials are encrypted with this key and decrypted by
+	// the protocol clients.
+	if err := crypto.SetDefaultKey(cfg.Security.Encryption.Key); err != nil {
+		log.Fatalf("Invalid security.encryption.key: %v", err)
+	}
+	if !crypto.Enabled() {
+		log.Println("WARNING: security.encryption.key is not set; device credentials are stored in plaintext")
+	}
+
 	// db, err := database.NewPostgresConnection(cfg.Database) ...
 
 	// Connect to Database
@@ -28,31 +49,66 @@ func main() {
 	}
 
 	// Auto Migrate
-	if err := database.Migrate(db, &model.Device{}, &model.DeviceCredentials{}, &model.DeviceGroup{}); err != nil {
+	if err := database.EnsureUnique(db, "devices", "ip_address"); err != nil {
+		log.Printf("Failed to run migrations: %v", err)
+	}
+	if err := database.EnsureUniqueWhere(db, "alert_history", alert.ActiveCondition, "device_id", "rule_id"); err != nil {
+		log.Printf("Failed to run migrations: %v", err)
+	}
+	if err := database.Migrate(db, &model.Device{}, &model.DeviceCredentials{}, &model.DeviceGroup{}, &model.TagRule{}, &alert.AlertHistory{}, &tr069.CPE{}, &tr069.CPEParameter{}); err != nil {
 		log.Printf("Failed to run migrations: %v", err)
 	}
 
 	// Initialize Monitoring Components
 	targetStore := monitoring.NewTargetStore()
 
-	influxWriter := monitoring.NewInfluxDBWriter(
-		cfg.Influx.URL,
-		cfg.Influx.Token,
-		cfg.Influx.Org,
-		cfg.Influx.Bucket,
-	)
+	metricSink, err := sink.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create metric sink: %v", err)
+	}
+
+	// A target that missed three polls in a row is reported as stale.
+	pollInterval := cfg.Monitoring.Interval
+	reachability := monitoring.NewReachabilityCache(3 * pollInterval)
 
-	scheduler := monitoring.NewScheduler(targetStore, influxWriter)
-	scheduler.Start(60 * time.Second) // Poll every 60s
+	scheduler := monitoring.NewScheduler(targetStore, monitoring.NewSinkWriter(metricSink), reachability)
+	scheduler.SetCycleBudget(cfg.Monitoring.CycleBudget, cfg.Monitoring.SkipOnOverrun)
+	scheduler.Start(pollInterval)
 	defer scheduler.Stop()
 
-	monitoringHandler := monitoring.NewHandler(targetStore)
+	monitoringHandler := monitoring.NewHandler(targetStore, reachability)
+
+	// openaccess passes OLT credentials in each request; looking them up by
+	// device_id is opt-in.
+	var oltCredentials olt.CredentialStore
+	if cfg.OLT.StoredCredentials {
+		oltCredentials = repository.NewDeviceRepository(db)
+	}
+	oltService := olt.NewOLTService(cfg.OLT, oltCredentials)
 
-	r := apigateway.NewRouter(cfg, db, monitoringHandler)
+	r := apigateway.NewRouter(cfg, db, monitoringHandler, oltService)
 
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
-	log.Printf("Starting API Gateway on %s", addr)
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{Addr: addr, Handler: r}
+	go func() {
+		log.Printf("Starting API Gateway on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for shutdown signal
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+
+	log.Println("Stopping API Gateway...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server shutdown: %v", err)
+	}
+	if err := oltService.Shutdown(ctx); err != nil {
+		log.Printf("OLT service shutdown: %v", err)
 	}
 }
@@ -5,13 +5,17 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/yourorg/nms-go/internal/collector"
 	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	"github.com/yourorg/nms-go/internal/common/database"
 	"github.com/yourorg/nms-go/internal/common/queue"
 	"github.com/yourorg/nms-go/internal/device/repository"
 	"github.com/yourorg/nms-go/internal/device/service"
+	"github.com/yourorg/nms-go/internal/notification"
+	"github.com/yourorg/nms-go/internal/retention"
 )
 
 func main() {
@@ -22,6 +26,15 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Stored device credentials are encrypted with this key and decrypted by
+	// the protocol clients.
+	if err := crypto.SetDefaultKey(cfg.Security.Encryption.Key); err != nil {
+		log.Fatalf("Invalid security.encryption.key: %v", err)
+	}
+	if !crypto.Enabled() {
+		log.Println("WARNING: security.encryption.key is not set; device credentials are stored in plaintext")
+	}
+
 	// Connect to Database
 	db, err := database.NewPostgresConnection(cfg.Database)
 	if err != nil {
@@ -29,20 +42,77 @@ func main() {
 	}
 
 	// Connect to NATS
-	nc, err := queue.NewNATSConnection(cfg.NATS)
+	natsConn, err := queue.NewNATSConnection(cfg.NATS)
 	if err != nil {
 		log.Fatalf("Failed to connect to NATS: %v", err)
 	}
-	defer nc.Close()
+	defer natsConn.Close()
+
+	// Poll tasks and metrics go through JetStream streams unless disabled
+	nc, err := queue.NewConn(natsConn, cfg.NATS)
+	if err != nil {
+		log.Fatalf("Failed to set up NATS JetStream: %v", err)
+	}
 
-	// Initialize Services
+	// Initialize Repositories
 	deviceRepo := repository.NewDeviceRepository(db)
-	deviceService := service.NewDeviceService(deviceRepo)
+
+	// Start Leader Election (only the leader of several collectors schedules polls)
+	var leader collector.Leadership
+	var elector *collector.Elector
+	if le := cfg.Collector.LeaderElection; le.Enabled {
+		if err := le.Validate(); err != nil {
+			log.Fatalf("Invalid collector.leader_election: %v", err)
+		}
+		// Instances sharing an ID would all count as the lock holder
+		if le.ID == "" {
+			le.ID, _ = os.Hostname()
+		}
+		if le.ID == "" {
+			log.Fatal("collector.leader_election.id is required")
+		}
+
+		rdb, err := database.NewRedisConnection(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis for leader election: %v", err)
+		}
+		defer rdb.Close()
+
+		elector = collector.NewElector(collector.NewRedisLock(rdb, le.Key), le.ID, le.TTL)
+		leader = elector
+		go elector.Start()
+	}
 
 	// Start Scheduler
-	scheduler := collector.NewScheduler(deviceService, nc)
+	scheduler := collector.NewScheduler(deviceRepo, nc, leader)
 	go scheduler.Start()
 
+	// Start Status Reconciler (updates device status from poll results)
+	var publisher service.EventPublisher
+	if cfg.Webhook.URL != "" {
+		publisher = notification.NewWebhookSender(cfg.Webhook)
+	}
+	reconciler := service.NewStatusReconciler(deviceRepo, publisher)
+	reconcilerDone := make(chan struct{})
+	go func() {
+		reconciler.Start(nc, cfg.Collector.ReconcilerGroup)
+		close(reconcilerDone)
+	}()
+
+	// Start Retention Job (cleans up audit logs, config backups, alert history)
+	var retentionJob *retention.Job
+	if cfg.Retention.Enabled {
+		retentionJob = retention.NewJob(retention.NewRepository(db), cfg.Retention)
+		go retentionJob.Start()
+	}
+
+	// Start Identity Refresh (reads device hostnames into metadata)
+	var identityRefresher *service.IdentityRefresher
+	if cfg.Identity.Enabled {
+		identityRefresher = service.NewIdentityRefresher(deviceRepo, cfg.Identity)
+		go identityRefresher.Start()
+	}
+
 	// Wait for shutdown signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -50,4 +120,21 @@ func main() {
 
 	log.Println("Stopping Collector Service...")
 	scheduler.Stop()
+	if elector != nil {
+		elector.Stop()
+	}
+	reconciler.Stop()
+	if retentionJob != nil {
+		retentionJob.Stop()
+	}
+	if identityRefresher != nil {
+		identityRefresher.Stop()
+	}
+
+	// The reconciler returns once its queued status-change events are sent.
+	select {
+	case <-reconcilerDone:
+	case <-time.After(30 * time.Second):
+		log.Println("Timed out publishing queued status changes; the rest are dropped")
+	}
 }
@@ -3,9 +3,11 @@ package main
 import (
 	"log"
 
+	"github.com/yourorg/nms-go/internal/alert"
 	"github.com/yourorg/nms-go/internal/common/config"
 	"github.com/yourorg/nms-go/internal/common/database"
 	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/features/tr069"
 )
 
 func main() {
@@ -23,10 +25,23 @@ func main() {
 
 	// Run migrations
 	log.Println("Starting migration...")
+	if err := database.EnsureUUIDSupport(db); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	if err := database.EnsureUnique(db, "devices", "ip_address"); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	if err := database.EnsureUniqueWhere(db, "alert_history", alert.ActiveCondition, "device_id", "rule_id"); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
 	err = db.AutoMigrate(
 		&model.Device{},
 		&model.DeviceCredentials{},
 		&model.DeviceGroup{},
+		&model.TagRule{},
+		&alert.AlertHistory{},
+		&tr069.CPE{},
+		&tr069.CPEParameter{},
 	)
 	if err != nil {
 		log.Fatalf("Migration failed: %v", err)
@@ -17,6 +17,10 @@ func main() {
 	if len(os.Args) > 2 {
 		community = os.Args[2]
 	}
+	root := "1.3.6.1.2.1.1" // Standard System OIDs
+	if len(os.Args) > 3 {
+		root = os.Args[3] // e.g. 1.3.6.1.4.1.3902.1012.3.28.2.1.4 for ONT phase states
+	}
 
 	g := &gosnmp.GoSNMP{
 		Target:    ip,
@@ -33,9 +37,8 @@ func main() {
 	}
 	defer g.Conn.Close()
 
-	// Walk 1.3.6.1.2.1.1 (Standard System OIDs)
-	fmt.Println("\n--- Walk 1.3.6.1.2.1.1 (Standard System) ---")
-	err := g.Walk("1.3.6.1.2.1.1", func(pdu gosnmp.SnmpPDU) error {
+	fmt.Printf("\n--- Walk %s ---\n", root)
+	err := g.Walk(root, func(pdu gosnmp.SnmpPDU) error {
 		fmt.Printf("%-30s %-10s %v\n", pdu.Name, pdu.Type, pdu.Value)
 		return nil
 	})
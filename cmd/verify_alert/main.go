@@ -2,20 +2,28 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"log"
 	"time"
 
+	"github.com/yourorg/nms-go/internal/alert"
 	"github.com/yourorg/nms-go/internal/common/config"
 	commonModel "github.com/yourorg/nms-go/internal/common/model"
 	"github.com/yourorg/nms-go/internal/common/queue"
 )
 
 func main() {
+	natsURL := flag.String("nats", "", "NATS URL (default: nats.url from config)")
+	subject := flag.String("subject", alert.MetricsSubject, "Subject the alert engine consumes metrics from")
+	flag.Parse()
+
 	// 1. Load Config (for NATS)
-	cfg := config.Config{
-		NATS: config.NATSConfig{
-			URL: "nats://localhost:4222",
-		},
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *natsURL != "" {
+		cfg.NATS.URL = *natsURL
 	}
 
 	// 2. Connect to NATS
@@ -36,10 +44,12 @@ func main() {
 			"success": true,
 		},
 	}
-	
+
 	payload, _ := json.Marshal(highLatency)
-	nc.Publish("nms.metrics", payload)
-	log.Println("Sent High Latency Metric (>100ms)")
+	if err := nc.Publish(*subject, payload); err != nil {
+		log.Fatalf("Failed to publish to %s: %v", *subject, err)
+	}
+	log.Printf("Sent High Latency Metric (>100ms) to %s", *subject)
 
 	// 4. Publish Device Down Metric
 	deviceDown := commonModel.Metric{
@@ -52,10 +62,17 @@ func main() {
 			"success": false,
 		},
 	}
-	
+
 	payload, _ = json.Marshal(deviceDown)
-	nc.Publish("nms.metrics", payload)
-	log.Println("Sent Device Down Metric (success=false)")
+	if err := nc.Publish(*subject, payload); err != nil {
+		log.Fatalf("Failed to publish to %s: %v", *subject, err)
+	}
+	log.Printf("Sent Device Down Metric (success=false) to %s", *subject)
+
+	// Publish only buffers; make sure both messages reached the server.
+	if err := nc.Flush(); err != nil {
+		log.Fatalf("Failed to flush NATS connection: %v", err)
+	}
 
 	time.Sleep(2 * time.Second)
 	log.Println("Verification messages sent. Check Alert Engine logs.")
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/yourorg/nms-go/internal/common/config"
-	"github.com/yourorg/nms-go/internal/common/database"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	"github.com/yourorg/nms-go/internal/common/queue"
+	"github.com/yourorg/nms-go/internal/common/sink"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
 	"github.com/yourorg/nms-go/internal/worker"
 )
 
@@ -20,22 +27,50 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// SNMP communities in poll tasks are encrypted by the collector with
+	// this key
+	if err := crypto.SetDefaultKey(cfg.Security.Encryption.Key); err != nil {
+		log.Fatalf("Invalid security.encryption.key: %v", err)
+	}
+	if !crypto.Enabled() {
+		log.Println("WARNING: security.encryption.key is not set; poll tasks carry SNMP communities in plaintext")
+	}
+
 	// Connect to NATS
-	nc, err := queue.NewNATSConnection(cfg.NATS)
+	natsConn, err := queue.NewNATSConnection(cfg.NATS)
 	if err != nil {
 		log.Fatalf("Failed to connect to NATS: %v", err)
 	}
-	defer nc.Close()
+	defer natsConn.Close()
 
-	// Connect to InfluxDB
-	influxClient, err := database.NewInfluxConnection(cfg.Influx)
+	// Poll tasks and metrics go through JetStream streams unless disabled
+	nc, err := queue.NewConn(natsConn, cfg.NATS)
 	if err != nil {
-		log.Fatalf("Failed to connect to InfluxDB: %v", err)
+		log.Fatalf("Failed to set up NATS JetStream: %v", err)
+	}
+
+	// Create metric sink (InfluxDB by default)
+	metricSink, err := sink.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create metric sink: %v", err)
+	}
+	defer metricSink.Close()
+
+	// Connect metrics are recorded here, where devices are polled, so the
+	// worker serves them itself
+	var metricsSrv *http.Server
+	if cfg.Worker.MetricsPort > 0 {
+		metricsSrv = telemetry.NewMetricsServer(fmt.Sprintf(":%d", cfg.Worker.MetricsPort))
+		go func() {
+			log.Printf("Serving worker metrics on %s/metrics", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Failed to serve metrics: %v", err)
+			}
+		}()
 	}
-	defer influxClient.Close()
 
 	// Start Worker
-	w := worker.NewWorker(nc, influxClient, cfg.Influx)
+	w := worker.NewWorker(nc, metricSink, cfg.Worker)
 	go w.Start()
 
 	// Wait for shutdown signal
@@ -45,4 +80,11 @@ func main() {
 
 	log.Println("Stopping Worker Service...")
 	w.Stop()
+	if metricsSrv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			log.Printf("Metrics server shutdown: %v", err)
+		}
+	}
 }
@@ -1,25 +1,80 @@
 package alert
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/yourorg/nms-go/internal/common/config"
 	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/queue"
 	"github.com/yourorg/nms-go/internal/notification"
 )
 
+// AlertStore persists alerts and their lifecycle state. The engine keeps one
+// active alert per device and rule in it.
+type AlertStore interface {
+	// Create persists a new active alert. It returns ErrAlreadyActive when
+	// the device already has an active alert of the rule.
+	Create(ctx context.Context, history *AlertHistory) error
+	// GetActive returns the firing or acknowledged alert of the rule on the
+	// device, or nil if there is none.
+	GetActive(ctx context.Context, deviceID, ruleID string) (*AlertHistory, error)
+	// Touch saves the value, last seen and notification fields of an alert.
+	Touch(ctx context.Context, history *AlertHistory) error
+	// ResolveActive resolves the active alerts of the rule on the device and
+	// returns how many there were.
+	ResolveActive(ctx context.Context, deviceID, ruleID string, at time.Time) (int64, error)
+}
+
+// MetricsSubject is the subject workers publish poll results on.
+const MetricsSubject = "nms.metrics"
+
+// EmailChannel is the name of the notifier passed to NewEngine.
+const EmailChannel = "email"
+
+// Defaults of the notification pool when the config leaves it unset
+const (
+	defaultNotifyWorkers = 4
+	defaultNotifyQueue   = 256
+)
+
 type Engine struct {
-	natsConn       *nats.Conn
-	notifier       notification.Service
-	rules          []Rule
-	stopChan       chan struct{}
+	natsConn        queue.Conn
+	group           string
+	channels        map[string]notification.Service
+	defaultChannels []string
+	routes          map[string][]string
+	store           AlertStore
+	rules           []Rule
+	renotify        time.Duration
+	stopChan        chan struct{}
+
+	// queues feed the notification workers; the notifications of one
+	// channel and device always use the same queue, so they stay in order.
+	queues  []chan delivery
+	pending sync.WaitGroup
 }
 
-func NewEngine(nc *nats.Conn, notifier notification.Service) *Engine {
+// delivery is one notification of an alert to one channel
+type delivery struct {
+	name     string
+	notifier notification.Service
+	alert    notification.Alert
+}
+
+// DefaultRules returns the rules the engine evaluates
+func DefaultRules() []Rule {
 	// Hardcoded rules for MVP
-	rules := []Rule{
+	return []Rule{
 		{
 			ID:          "rule-1",
 			MetricName:  "rtt_ms",
@@ -37,27 +92,132 @@ func NewEngine(nc *nats.Conn, notifier notification.Service) *Engine {
 			Severity:    "critical",
 		},
 	}
+}
 
+// NewEngine creates an alert engine. notifier is registered as the "email"
+// channel; more channels are added with RegisterChannel. store may be nil to
+// keep alert state in memory only; it is then lost on restart and not shared
+// between replicas.
+func NewEngine(nc queue.Conn, notifier notification.Service, store AlertStore, cfg config.AlertConfig) *Engine {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	defaultChannels := cfg.Channels
+	if len(defaultChannels) == 0 {
+		defaultChannels = []string{EmailChannel}
+	}
+	routes := make(map[string][]string, len(cfg.Routes))
+	for ruleID, channels := range cfg.Routes {
+		routes[strings.ToLower(ruleID)] = channels
+	}
+	workers, size := cfg.NotifyWorkers, cfg.NotifyQueue
+	if workers <= 0 {
+		workers = defaultNotifyWorkers
+	}
+	if size <= 0 {
+		size = defaultNotifyQueue
+	}
+	queues := make([]chan delivery, workers)
+	for i := range queues {
+		queues[i] = make(chan delivery, size)
+	}
 	return &Engine{
-		natsConn:       nc,
-		notifier:       notifier,
-		rules:          rules,
-		stopChan:       make(chan struct{}),
+		natsConn:        nc,
+		group:           cfg.Group,
+		channels:        map[string]notification.Service{EmailChannel: notifier},
+		defaultChannels: defaultChannels,
+		routes:          routes,
+		store:           store,
+		rules:           DefaultRules(),
+		renotify:        cfg.RenotifyInterval,
+		stopChan:        make(chan struct{}),
+		queues:          queues,
+	}
+}
+
+// RegisterChannel adds a notification channel rules can route to. Call it
+// before Start.
+func (e *Engine) RegisterChannel(name string, notifier notification.Service) {
+	e.channels[name] = notifier
+}
+
+// CheckChannels returns an error naming the channels that rules, routes or
+// the defaults use but that are not registered.
+func (e *Engine) CheckChannels() error {
+	unknown := make(map[string]bool)
+	check := func(channels []string) {
+		for _, name := range channels {
+			if _, ok := e.channels[name]; !ok {
+				unknown[name] = true
+			}
+		}
+	}
+	check(e.defaultChannels)
+	for _, channels := range e.routes {
+		check(channels)
+	}
+	for _, rule := range e.rules {
+		check(rule.Channels)
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(unknown))
+	for name := range unknown {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown notification channels: %s", strings.Join(names, ", "))
 }
 
+// channelsFor returns the channels the alerts of rule go to
+func (e *Engine) channelsFor(rule Rule) []string {
+	if len(rule.Channels) > 0 {
+		return rule.Channels
+	}
+	if channels, ok := e.routes[strings.ToLower(rule.ID)]; ok {
+		return channels
+	}
+	return e.defaultChannels
+}
+
+// Start subscribes to metrics and blocks until Stop is called. Replicas join
+// the configured queue group so each metric is evaluated, and notified, once.
+// Notifications are sent by a pool of workers, which finish the queued ones
+// before Start returns.
 func (e *Engine) Start() {
-	log.Println("Alert Engine started, subscribing to nms.metrics")
+	log.Printf("Alert Engine started, subscribing to %s (queue group %q)", MetricsSubject, e.group)
+
+	var workers sync.WaitGroup
+	for _, q := range e.queues {
+		workers.Add(1)
+		go func(q chan delivery) {
+			defer workers.Done()
+			e.sendLoop(q)
+		}(q)
+	}
+	defer workers.Wait()
 
-	sub, err := e.natsConn.Subscribe("nms.metrics", func(msg *nats.Msg) {
+	handler := func(msg *nats.Msg) {
 		var metric commonModel.Metric
 		if err := json.Unmarshal(msg.Data, &metric); err != nil {
 			log.Printf("Error unmarshalling metric: %v", err)
+			queue.Term(msg)
 			return
 		}
 
 		e.evaluate(metric)
-	})
+		queue.Ack(msg)
+	}
+
+	var sub *nats.Subscription
+	var err error
+	if e.group != "" {
+		sub, err = e.natsConn.QueueSubscribe(MetricsSubject, e.group, handler)
+	} else {
+		sub, err = e.natsConn.Subscribe(MetricsSubject, handler)
+	}
 
 	if err != nil {
 		log.Fatalf("Error communicating with NATS: %v", err)
@@ -71,46 +231,268 @@ func (e *Engine) Stop() {
 	close(e.stopChan)
 }
 
+// Flush blocks until every notification queued so far has been sent.
+func (e *Engine) Flush() {
+	e.pending.Wait()
+}
+
+// sendLoop sends the notifications of q until Stop, then the ones still
+// queued.
+func (e *Engine) sendLoop(q chan delivery) {
+	for {
+		select {
+		case d := <-q:
+			e.send(d)
+		case <-e.stopChan:
+			for {
+				select {
+				case d := <-q:
+					e.send(d)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *Engine) send(d delivery) {
+	defer e.pending.Done()
+
+	var err error
+	if an, ok := d.notifier.(notification.AlertNotifier); ok {
+		err = an.NotifyAlert(context.Background(), d.alert)
+	} else {
+		err = d.notifier.Send("admin@example.com", d.alert.Subject, d.alert.Body)
+	}
+	if err != nil {
+		log.Printf("Error sending alert notification via %s: %v", d.name, err)
+	}
+}
+
+// enqueue hands d to the worker of its channel and device. It blocks while
+// that worker's queue is full, and drops d once the engine is stopping.
+func (e *Engine) enqueue(d delivery) {
+	h := fnv.New32a()
+	h.Write([]byte(d.name + "/" + d.alert.DeviceID))
+	q := e.queues[h.Sum32()%uint32(len(e.queues))]
+
+	e.pending.Add(1)
+	select {
+	case q <- d:
+	case <-e.stopChan:
+		e.pending.Done()
+		log.Printf("Dropping %s notification of rule %s: alert engine is stopping", d.name, d.alert.RuleID)
+	}
+}
+
+// evaluate moves the alert of every rule that applies to metric through its
+// lifecycle: a rule that starts firing opens an alert and notifies, one that
+// keeps firing updates it and re-notifies at most every renotify interval
+// unless acknowledged, and one that stops firing resolves it.
 func (e *Engine) evaluate(metric commonModel.Metric) {
+	at := metric.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
 	for _, rule := range e.rules {
-		// specific device check (if rule has DeviceID)
-		if rule.DeviceID != "" && rule.DeviceID != metric.DeviceID {
+		value, ok, fired := Check(rule, metric)
+		if !ok {
 			continue
 		}
+		if fired {
+			e.fire(rule, metric, value, at)
+		} else {
+			e.resolve(rule, metric, value, at)
+		}
+	}
+}
 
-		val, ok := metric.Values[rule.MetricName]
-		if !ok {
-			continue
+func (e *Engine) fire(rule Rule, metric commonModel.Metric, value float64, at time.Time) {
+	ctx := context.Background()
+	msg := alertMessage(rule, metric, value)
+
+	active, err := e.store.GetActive(ctx, metric.DeviceID, rule.ID)
+	if err != nil {
+		// Notifying twice beats missing an alert
+		log.Printf("Error loading active alert: %v", err)
+	}
+	if active == nil {
+		if !e.open(rule, metric, value, msg, at) {
+			return
 		}
+		log.Println("⚡ " + msg)
+		e.notify(rule, metric, value, at, notification.AlertFiring, "NMS Alert: "+rule.Description, msg)
+		return
+	}
+
+	active.Value = value
+	active.LastSeenAt = &at
+	if e.shouldRenotify(active, at) {
+		log.Println("⚡ " + msg)
+		e.notify(rule, metric, value, active.TriggeredAt, notification.AlertRepeat, "NMS Alert: "+rule.Description,
+			fmt.Sprintf("%s (firing since %s)", msg, active.TriggeredAt.UTC().Format(time.RFC3339)))
+		active.LastNotifiedAt = &at
+		active.NotificationCount++
+	}
+	if err := e.store.Touch(ctx, active); err != nil {
+		log.Printf("Error updating alert %s: %v", active.ID, err)
+	}
+}
+
+// shouldRenotify reports whether a firing alert is due another notification
+func (e *Engine) shouldRenotify(active *AlertHistory, at time.Time) bool {
+	if active.State != AlertStateFiring || e.renotify <= 0 {
+		return false
+	}
+	return active.LastNotifiedAt == nil || at.Sub(*active.LastNotifiedAt) >= e.renotify
+}
+
+func (e *Engine) resolve(rule Rule, metric commonModel.Metric, value float64, at time.Time) {
+	ctx := context.Background()
+
+	active, err := e.store.GetActive(ctx, metric.DeviceID, rule.ID)
+	if err != nil {
+		log.Printf("Error loading active alert: %v", err)
+		return
+	}
+	if active == nil {
+		return
+	}
+
+	if _, err := e.store.ResolveActive(ctx, metric.DeviceID, rule.ID, at); err != nil {
+		log.Printf("Error resolving alert %s: %v", active.ID, err)
+		return
+	}
+	msg := fmt.Sprintf("RESOLVED [%s]: Device %s (%s) - %s (Value: %.2f)",
+		rule.Severity, metric.DeviceName, metric.IPAddress, rule.Description, value)
+	log.Println("✅ " + msg)
+	e.notify(rule, metric, value, active.TriggeredAt, notification.AlertResolved, "NMS Resolved: "+rule.Description, msg)
+}
 
-		// Convert boolean to float for comparison if needed
-		floatVal, ok := toFloat(val)
+// notify queues a notification to every channel of rule, sent as a
+// notification.Alert to channels that take one. A failing or slow channel
+// does not hold up the others or rule evaluation.
+func (e *Engine) notify(rule Rule, metric commonModel.Metric, value float64, triggeredAt time.Time, kind, subject, body string) {
+	a := notification.Alert{
+		Kind:        kind,
+		Subject:     subject,
+		Body:        body,
+		RuleID:      rule.ID,
+		Description: rule.Description,
+		Severity:    rule.Severity,
+		DeviceID:    metric.DeviceID,
+		DeviceName:  metric.DeviceName,
+		IPAddress:   metric.IPAddress,
+		MetricName:  rule.MetricName,
+		Value:       value,
+		Threshold:   rule.Threshold,
+		TriggeredAt: triggeredAt,
+	}
+
+	for _, name := range e.channelsFor(rule) {
+		notifier, ok := e.channels[name]
 		if !ok {
+			log.Printf("WARNING: rule %s routes to unknown notification channel %q", rule.ID, name)
 			continue
 		}
 
-		triggered := false
-		switch rule.Operator {
-		case ">":
-			triggered = floatVal > rule.Threshold
-		case "<":
-			triggered = floatVal < rule.Threshold
-		case "=":
-			triggered = floatVal == rule.Threshold
-		case ">=":
-			triggered = floatVal >= rule.Threshold
-		case "<=":
-			triggered = floatVal <= rule.Threshold
-		}
+		e.enqueue(delivery{name: name, notifier: notifier, alert: a})
+	}
+}
+
+// Firing is a rule that matched a metric
+type Firing struct {
+	Rule    Rule
+	Value   float64
+	Message string
+}
 
-		if triggered {
-			alertMsg := fmt.Sprintf("ALERT [%s]: Device %s (%s) - %s (Value: %.2f)", 
-				rule.Severity, metric.DeviceName, metric.IPAddress, rule.Description, floatVal)
-			
-			log.Println("⚡ " + alertMsg)
-			e.notifier.Send("admin@example.com", "NMS Alert: "+rule.Description, alertMsg)
+// Evaluate returns the rules that fire for metric, in rule order. It has no
+// side effects, so it also serves dry runs.
+func Evaluate(rules []Rule, metric commonModel.Metric) []Firing {
+	var fired []Firing
+	for _, rule := range rules {
+		floatVal, ok, triggered := Check(rule, metric)
+		if !ok || !triggered {
+			continue
 		}
+
+		fired = append(fired, Firing{Rule: rule, Value: floatVal, Message: alertMessage(rule, metric, floatVal)})
+	}
+	return fired
+}
+
+func alertMessage(rule Rule, metric commonModel.Metric, value float64) string {
+	return fmt.Sprintf("ALERT [%s]: Device %s (%s) - %s (Value: %.2f)",
+		rule.Severity, metric.DeviceName, metric.IPAddress, rule.Description, value)
+}
+
+// Check evaluates a single rule against metric and returns the compared
+// value and whether the rule fires. ok is false when the rule does not apply:
+// it targets another device, or metric has no numeric value for it.
+func Check(rule Rule, metric commonModel.Metric) (value float64, ok bool, fired bool) {
+	// specific device check (if rule has DeviceID)
+	if rule.DeviceID != "" && rule.DeviceID != metric.DeviceID {
+		return 0, false, false
+	}
+
+	val, ok := metric.Values[rule.MetricName]
+	if !ok {
+		return 0, false, false
+	}
+
+	// Convert boolean to float for comparison if needed
+	floatVal, ok := toFloat(val)
+	if !ok {
+		return 0, false, false
+	}
+
+	switch rule.Operator {
+	case ">":
+		fired = floatVal > rule.Threshold
+	case "<":
+		fired = floatVal < rule.Threshold
+	case "=":
+		fired = floatVal == rule.Threshold
+	case ">=":
+		fired = floatVal >= rule.Threshold
+	case "<=":
+		fired = floatVal <= rule.Threshold
+	}
+	return floatVal, true, fired
+}
+
+// open persists a new alert and reports whether it should be notified. It
+// reports false only when the alert is already active, i.e. another replica
+// opened and notified it first; a failed write is still notified.
+func (e *Engine) open(rule Rule, metric commonModel.Metric, value float64, msg string, at time.Time) bool {
+	entry := &AlertHistory{
+		RuleID:            rule.ID,
+		DeviceID:          metric.DeviceID,
+		DeviceName:        metric.DeviceName,
+		IPAddress:         metric.IPAddress,
+		MetricName:        rule.MetricName,
+		Severity:          rule.Severity,
+		State:             AlertStateFiring,
+		Value:             value,
+		Threshold:         rule.Threshold,
+		Message:           msg,
+		TriggeredAt:       at,
+		LastSeenAt:        &at,
+		LastNotifiedAt:    &at,
+		NotificationCount: 1,
+	}
+
+	err := e.store.Create(context.Background(), entry)
+	if errors.Is(err, ErrAlreadyActive) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error persisting alert history: %v", err)
 	}
+	return true
 }
 
 func toFloat(unk interface{}) (float64, bool) {
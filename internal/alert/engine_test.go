@@ -0,0 +1,335 @@
+package alert_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/alert"
+	"github.com/yourorg/nms-go/internal/common/config"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/notification"
+)
+
+// fakeConn records subscriptions instead of talking to a NATS server.
+type fakeConn struct {
+	mu         sync.Mutex
+	subject    string
+	queue      string
+	handler    nats.MsgHandler
+	subscribed chan struct{}
+
+	// flush waits for the engine's queued notifications
+	flush func()
+}
+
+func (f *fakeConn) Publish(subj string, data []byte) error { return nil }
+
+func (f *fakeConn) Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return f.QueueSubscribe(subj, "", cb)
+}
+
+func (f *fakeConn) QueueSubscribe(subj, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	f.mu.Lock()
+	f.subject, f.queue, f.handler = subj, queue, cb
+	f.mu.Unlock()
+	close(f.subscribed)
+	return &nats.Subscription{}, nil
+}
+
+type fakeNotifier struct {
+	mu       sync.Mutex
+	subjects []string
+}
+
+func (f *fakeNotifier) Send(to, subject, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subjects = append(f.subjects, subject)
+	return nil
+}
+
+func startEngine(t *testing.T, cfg config.AlertConfig, notifier *fakeNotifier) *fakeConn {
+	t.Helper()
+	nc := &fakeConn{subscribed: make(chan struct{})}
+	engine := alert.NewEngine(nc, notifier, nil, cfg)
+	nc.flush = engine.Flush
+
+	done := make(chan struct{})
+	go func() {
+		engine.Start()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		engine.Stop()
+		<-done
+	})
+
+	select {
+	case <-nc.subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("engine did not subscribe")
+	}
+	return nc
+}
+
+func TestEngine_UsesQueueGroup(t *testing.T) {
+	notifier := &fakeNotifier{}
+	nc := startEngine(t, config.AlertConfig{Group: "nms-alert-engine"}, notifier)
+
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	assert.Equal(t, alert.MetricsSubject, nc.subject)
+	assert.Equal(t, "nms-alert-engine", nc.queue)
+
+	// Metrics delivered to the queue subscription are evaluated
+	payload, err := json.Marshal(commonModel.Metric{
+		DeviceID: "dev-1",
+		Values:   map[string]interface{}{"success": false},
+	})
+	require.NoError(t, err)
+	nc.handler(&nats.Msg{Subject: alert.MetricsSubject, Data: payload})
+	nc.flush()
+
+	assert.Equal(t, []string{"NMS Alert: Device Down"}, notifier.sent())
+}
+
+func TestEngine_NoGroupSubscribesToAll(t *testing.T) {
+	nc := startEngine(t, config.AlertConfig{}, &fakeNotifier{})
+
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	assert.Equal(t, alert.MetricsSubject, nc.subject)
+	assert.Empty(t, nc.queue)
+}
+
+func (f *fakeNotifier) sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.subjects...)
+}
+
+func deliver(t *testing.T, nc *fakeConn, at time.Time, success bool) {
+	t.Helper()
+	payload, err := json.Marshal(commonModel.Metric{
+		DeviceID:  "dev-1",
+		Timestamp: at,
+		Values:    map[string]interface{}{"success": success},
+	})
+	require.NoError(t, err)
+	nc.mu.Lock()
+	handler := nc.handler
+	nc.mu.Unlock()
+	handler(&nats.Msg{Subject: alert.MetricsSubject, Data: payload})
+	nc.flush()
+}
+
+func TestEngine_DeduplicatesAndRenotifies(t *testing.T) {
+	notifier := &fakeNotifier{}
+	nc := startEngine(t, config.AlertConfig{RenotifyInterval: time.Hour}, notifier)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	deliver(t, nc, start, false)
+	deliver(t, nc, start.Add(time.Minute), false)
+	deliver(t, nc, start.Add(30*time.Minute), false)
+	assert.Equal(t, []string{"NMS Alert: Device Down"}, notifier.sent(), "a firing alert is notified once")
+
+	deliver(t, nc, start.Add(time.Hour), false)
+	assert.Len(t, notifier.sent(), 2, "re-notified after the interval")
+
+	deliver(t, nc, start.Add(61*time.Minute), true)
+	assert.Equal(t, "NMS Resolved: Device Down", notifier.sent()[2])
+
+	deliver(t, nc, start.Add(62*time.Minute), true)
+	deliver(t, nc, start.Add(63*time.Minute), false)
+	assert.Equal(t, []string{
+		"NMS Alert: Device Down",
+		"NMS Alert: Device Down",
+		"NMS Resolved: Device Down",
+		"NMS Alert: Device Down",
+	}, notifier.sent(), "resolving is notified once and a new firing starts a new alert")
+}
+
+func TestEngine_NoRenotifyWhenDisabled(t *testing.T) {
+	notifier := &fakeNotifier{}
+	nc := startEngine(t, config.AlertConfig{}, notifier)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	deliver(t, nc, start, false)
+	deliver(t, nc, start.Add(24*time.Hour), false)
+	assert.Len(t, notifier.sent(), 1)
+}
+
+// ackStore is an AlertStore whose active alert is acknowledged
+type ackStore struct {
+	*alert.MemoryStore
+}
+
+func (s ackStore) GetActive(ctx context.Context, deviceID, ruleID string) (*alert.AlertHistory, error) {
+	a, err := s.MemoryStore.GetActive(ctx, deviceID, ruleID)
+	if a != nil {
+		a.State = alert.AlertStateAcknowledged
+	}
+	return a, err
+}
+
+func TestEngine_AcknowledgedAlertIsNotRenotified(t *testing.T) {
+	notifier := &fakeNotifier{}
+	nc := &fakeConn{subscribed: make(chan struct{})}
+	engine := alert.NewEngine(nc, notifier, ackStore{alert.NewMemoryStore()}, config.AlertConfig{RenotifyInterval: time.Minute})
+	nc.flush = engine.Flush
+	go engine.Start()
+	t.Cleanup(engine.Stop)
+	<-nc.subscribed
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	deliver(t, nc, start, false)
+	deliver(t, nc, start.Add(time.Hour), false)
+	assert.Len(t, notifier.sent(), 1)
+
+	deliver(t, nc, start.Add(2*time.Hour), true)
+	assert.Equal(t, "NMS Resolved: Device Down", notifier.sent()[1], "an acknowledged alert still resolves")
+}
+
+func TestEngine_RoutesRulesToChannels(t *testing.T) {
+	email, ops := &fakeNotifier{}, &fakeNotifier{}
+	nc := &fakeConn{subscribed: make(chan struct{})}
+	engine := alert.NewEngine(nc, email, nil, config.AlertConfig{
+		Routes: map[string][]string{"rule-2": {"email", "ops"}},
+	})
+	engine.RegisterChannel("ops", ops)
+	nc.flush = engine.Flush
+	require.NoError(t, engine.CheckChannels())
+	go engine.Start()
+	t.Cleanup(engine.Stop)
+	<-nc.subscribed
+
+	deliver(t, nc, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), false)
+	assert.Equal(t, []string{"NMS Alert: Device Down"}, email.sent())
+	assert.Equal(t, []string{"NMS Alert: Device Down"}, ops.sent())
+}
+
+func TestEngine_CheckChannels(t *testing.T) {
+	engine := alert.NewEngine(&fakeConn{}, &fakeNotifier{}, nil, config.AlertConfig{
+		Channels: []string{"email", "pager"},
+		Routes:   map[string][]string{"rule-1": {"slack"}},
+	})
+	assert.EqualError(t, engine.CheckChannels(), "unknown notification channels: pager, slack")
+}
+
+// alertRecorder is a channel that takes the structured alert
+type alertRecorder struct {
+	fakeNotifier
+	alerts []notification.Alert
+}
+
+func (r *alertRecorder) NotifyAlert(_ context.Context, a notification.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func TestEngine_PassesAlertToAlertNotifiers(t *testing.T) {
+	chat := &alertRecorder{}
+	nc := &fakeConn{subscribed: make(chan struct{})}
+	engine := alert.NewEngine(nc, &fakeNotifier{}, nil, config.AlertConfig{Channels: []string{"chat"}})
+	engine.RegisterChannel("chat", chat)
+	nc.flush = engine.Flush
+	go engine.Start()
+	t.Cleanup(engine.Stop)
+	<-nc.subscribed
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	deliver(t, nc, start, false)
+	deliver(t, nc, start.Add(time.Minute), true)
+
+	chat.mu.Lock()
+	defer chat.mu.Unlock()
+	assert.Empty(t, chat.subjects, "Send is not used")
+	require.Len(t, chat.alerts, 2)
+	assert.Equal(t, notification.AlertFiring, chat.alerts[0].Kind)
+	assert.Equal(t, "dev-1", chat.alerts[0].DeviceID)
+	assert.Equal(t, "rule-2", chat.alerts[0].RuleID)
+	assert.Equal(t, "critical", chat.alerts[0].Severity)
+	assert.Equal(t, notification.AlertResolved, chat.alerts[1].Kind)
+	assert.Equal(t, start, chat.alerts[1].TriggeredAt)
+}
+
+// blockingNotifier holds every Send until release is closed
+type blockingNotifier struct {
+	fakeNotifier
+	release chan struct{}
+}
+
+func (b *blockingNotifier) Send(to, subject, body string) error {
+	<-b.release
+	return b.fakeNotifier.Send(to, subject, body)
+}
+
+func TestEngine_SlowChannelDoesNotBlockEvaluation(t *testing.T) {
+	slow := &blockingNotifier{release: make(chan struct{})}
+	fast := &fakeNotifier{}
+	nc := &fakeConn{subscribed: make(chan struct{})}
+	engine := alert.NewEngine(nc, slow, nil, config.AlertConfig{Channels: []string{"email", "chat"}, NotifyWorkers: 2})
+	engine.RegisterChannel("chat", fast)
+	go engine.Start()
+	t.Cleanup(engine.Stop)
+	<-nc.subscribed
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	evaluated := make(chan struct{})
+	go func() {
+		defer close(evaluated)
+		nc.mu.Lock()
+		handler := nc.handler
+		nc.mu.Unlock()
+		for i, success := range []bool{false, true} {
+			payload, _ := json.Marshal(commonModel.Metric{
+				DeviceID:  "dev-1",
+				Timestamp: start.Add(time.Duration(i) * time.Minute),
+				Values:    map[string]interface{}{"success": success},
+			})
+			handler(&nats.Msg{Subject: alert.MetricsSubject, Data: payload})
+		}
+	}()
+
+	select {
+	case <-evaluated:
+	case <-time.After(time.Second):
+		t.Fatal("a blocked channel held up evaluation")
+	}
+
+	close(slow.release)
+	engine.Flush()
+	assert.Equal(t, []string{"NMS Alert: Device Down", "NMS Resolved: Device Down"}, slow.sent(), "a channel gets a device's alerts in order")
+	assert.Equal(t, []string{"NMS Alert: Device Down", "NMS Resolved: Device Down"}, fast.sent())
+}
+
+// racingStore is an AlertStore where another replica opens every alert
+// between GetActive and Create
+type racingStore struct {
+	*alert.MemoryStore
+}
+
+func (s racingStore) Create(ctx context.Context, history *alert.AlertHistory) error {
+	return alert.ErrAlreadyActive
+}
+
+func TestEngine_AlreadyActiveAlertIsNotNotified(t *testing.T) {
+	notifier := &fakeNotifier{}
+	nc := &fakeConn{subscribed: make(chan struct{})}
+	engine := alert.NewEngine(nc, notifier, racingStore{alert.NewMemoryStore()}, config.AlertConfig{})
+	nc.flush = engine.Flush
+	go engine.Start()
+	t.Cleanup(engine.Stop)
+	<-nc.subscribed
+
+	deliver(t, nc, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), false)
+	assert.Empty(t, notifier.sent(), "the replica that opened the alert notifies it")
+}
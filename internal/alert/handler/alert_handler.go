@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/alert"
+	"github.com/yourorg/nms-go/internal/alert/service"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+type AlertHandler struct {
+	service service.AlertService
+}
+
+func NewAlertHandler(service service.AlertService) *AlertHandler {
+	return &AlertHandler{service: service}
+}
+
+// ListAlerts handles GET /api/v1/alerts
+//
+// Query parameters: device_id, severity, state, from, to (RFC3339), page, page_size.
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	q := &service.ListAlertsQuery{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if err := bindFilters(c, q); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	alerts, total, err := h.service.ListAlerts(c.Request.Context(), q)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTimeRange) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"data":      alerts,
+		"total":     total,
+		"page":      q.Page,
+		"page_size": q.PageSize,
+	})
+}
+
+// AcknowledgeRequest is the optional request body for
+// POST /api/v1/alerts/:id/acknowledge
+type AcknowledgeRequest struct {
+	AcknowledgedBy string `json:"acknowledged_by" binding:"max=255"`
+}
+
+// GetAlert handles GET /api/v1/alerts/:id
+func (h *AlertHandler) GetAlert(c *gin.Context) {
+	a, err := h.service.GetAlert(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeAlertError(c, err)
+		return
+	}
+
+	c.JSON(200, a)
+}
+
+// AcknowledgeAlert handles POST /api/v1/alerts/:id/acknowledge
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	var req AcknowledgeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, validator.ErrorResponse("invalid request body", err))
+			return
+		}
+	}
+
+	a, err := h.service.AcknowledgeAlert(c.Request.Context(), c.Param("id"), req.AcknowledgedBy)
+	if err != nil {
+		writeAlertError(c, err)
+		return
+	}
+
+	c.JSON(200, a)
+}
+
+// ResolveAlert handles POST /api/v1/alerts/:id/resolve
+func (h *AlertHandler) ResolveAlert(c *gin.Context) {
+	a, err := h.service.ResolveAlert(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeAlertError(c, err)
+		return
+	}
+
+	c.JSON(200, a)
+}
+
+func writeAlertError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAlertNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTransition):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// csvHeader is the column order of the alert export
+var csvHeader = []string{
+	"id", "triggered_at", "resolved_at", "device_id", "device_name", "ip_address",
+	"rule_id", "metric_name", "severity", "state", "value", "threshold", "message",
+}
+
+// ExportAlerts handles GET /api/v1/alerts/export
+//
+// Streams the alert history as CSV, newest first. Takes the same filters as
+// ListAlerts, plus range (Go duration, e.g. "168h") as a shorthand for from
+// and format, which must be "csv".
+func (h *AlertHandler) ExportAlerts(c *gin.Context) {
+	if format := c.DefaultQuery("format", "csv"); format != "csv" {
+		c.JSON(400, gin.H{"error": "unsupported format: " + format})
+		return
+	}
+
+	q := &service.ListAlertsQuery{}
+	if err := bindFilters(c, q); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if raw := c.Query("range"); raw != "" {
+		rng, err := time.ParseDuration(raw)
+		if err != nil || rng <= 0 {
+			c.JSON(400, gin.H{"error": "invalid range: expected a positive duration such as 24h"})
+			return
+		}
+		if q.From != nil {
+			c.JSON(400, gin.H{"error": "range and from are mutually exclusive"})
+			return
+		}
+		end := time.Now()
+		if q.To != nil {
+			end = *q.To
+		}
+		from := end.Add(-rng)
+		q.From = &from
+	}
+
+	// The header is written with the first row, so errors before any output
+	// can still be reported as JSON
+	w := csv.NewWriter(c.Writer)
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="alerts-%s.csv"`, time.Now().UTC().Format("20060102T150405Z")))
+		c.Status(200)
+		return w.Write(csvHeader)
+	}
+
+	rows := 0
+	err := h.service.ExportAlerts(c.Request.Context(), q, func(a *alert.AlertHistory) error {
+		if err := start(); err != nil {
+			return err
+		}
+		if err := w.Write(csvRow(a)); err != nil {
+			return err
+		}
+		rows++
+		if rows%100 == 0 {
+			w.Flush()
+		}
+		return w.Error()
+	})
+	if err != nil && !started {
+		if errors.Is(err, service.ErrInvalidTimeRange) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		// The status is already sent; a truncated file is all we can signal
+		log.Printf("Alert export aborted after %d rows: %v", rows, err)
+		return
+	}
+
+	if err := start(); err != nil {
+		log.Printf("Alert export failed: %v", err)
+		return
+	}
+	w.Flush()
+}
+
+func csvRow(a *alert.AlertHistory) []string {
+	resolved := ""
+	if a.ResolvedAt != nil {
+		resolved = a.ResolvedAt.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		csvText(a.ID),
+		a.TriggeredAt.UTC().Format(time.RFC3339),
+		resolved,
+		csvText(a.DeviceID),
+		csvText(a.DeviceName),
+		csvText(a.IPAddress),
+		csvText(a.RuleID),
+		csvText(a.MetricName),
+		csvText(a.Severity),
+		csvText(string(a.State)),
+		strconv.FormatFloat(a.Value, 'f', -1, 64),
+		strconv.FormatFloat(a.Threshold, 'f', -1, 64),
+		csvText(a.Message),
+	}
+}
+
+// csvText prefixes text that a spreadsheet would run as a formula, such as a
+// device named "=HYPERLINK(...)", with ' so it is shown as text. Numbers are
+// written as is, so a negative value stays a number.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// bindFilters reads the device_id, severity, state, from and to query
+// parameters into q
+func bindFilters(c *gin.Context, q *service.ListAlertsQuery) error {
+	q.DeviceID = c.Query("device_id")
+	q.Severity = c.Query("severity")
+	q.State = alert.AlertState(c.Query("state"))
+
+	for param, dst := range map[string]**time.Time{"from": &q.From, "to": &q.To} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s: expected RFC3339 timestamp", param)
+		}
+		*dst = &t
+	}
+	return nil
+}
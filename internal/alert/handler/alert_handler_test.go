@@ -0,0 +1,419 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/alert"
+	"github.com/yourorg/nms-go/internal/alert/handler"
+	"github.com/yourorg/nms-go/internal/alert/repository"
+	"github.com/yourorg/nms-go/internal/alert/service"
+)
+
+// MockAlertRepository
+type MockAlertRepository struct {
+	ListFunc  func(ctx context.Context, filter *repository.AlertFilter) ([]*alert.AlertHistory, error)
+	CountFunc func(ctx context.Context, filter *repository.AlertFilter) (int64, error)
+
+	LastFilter *repository.AlertFilter
+
+	// Alerts backs GetByID and the state transitions
+	Alerts map[string]*alert.AlertHistory
+}
+
+func (m *MockAlertRepository) Create(ctx context.Context, history *alert.AlertHistory) error {
+	return nil
+}
+
+func (m *MockAlertRepository) List(ctx context.Context, filter *repository.AlertFilter) ([]*alert.AlertHistory, error) {
+	m.LastFilter = filter
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, filter)
+	}
+	return []*alert.AlertHistory{}, nil
+}
+
+func (m *MockAlertRepository) Count(ctx context.Context, filter *repository.AlertFilter) (int64, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc(ctx, filter)
+	}
+	return 0, nil
+}
+
+func (m *MockAlertRepository) GetByID(ctx context.Context, id string) (*alert.AlertHistory, error) {
+	a, ok := m.Alerts[id]
+	if !ok {
+		return nil, repository.ErrAlertNotFound
+	}
+	loaded := *a
+	return &loaded, nil
+}
+
+func (m *MockAlertRepository) GetActive(ctx context.Context, deviceID, ruleID string) (*alert.AlertHistory, error) {
+	return nil, nil
+}
+
+func (m *MockAlertRepository) Touch(ctx context.Context, history *alert.AlertHistory) error {
+	return nil
+}
+
+func (m *MockAlertRepository) ResolveActive(ctx context.Context, deviceID, ruleID string, at time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockAlertRepository) Acknowledge(ctx context.Context, id, by string, at time.Time) (bool, error) {
+	a, ok := m.Alerts[id]
+	if !ok || a.State != alert.AlertStateFiring {
+		return false, nil
+	}
+	a.State, a.AcknowledgedBy, a.AcknowledgedAt = alert.AlertStateAcknowledged, by, &at
+	return true, nil
+}
+
+func (m *MockAlertRepository) Resolve(ctx context.Context, id string, at time.Time) (bool, error) {
+	a, ok := m.Alerts[id]
+	if !ok || !a.State.Active() {
+		return false, nil
+	}
+	a.State, a.ResolvedAt = alert.AlertStateResolved, &at
+	return true, nil
+}
+
+type listResponse struct {
+	Data     []*alert.AlertHistory `json:"data"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+func setupRouter(repo repository.AlertRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewAlertHandler(service.NewAlertService(repo))
+	r.GET("/api/v1/alerts", h.ListAlerts)
+	r.GET("/api/v1/alerts/export", h.ExportAlerts)
+	r.GET("/api/v1/alerts/:id", h.GetAlert)
+	r.POST("/api/v1/alerts/:id/acknowledge", h.AcknowledgeAlert)
+	r.POST("/api/v1/alerts/:id/resolve", h.ResolveAlert)
+	return r
+}
+
+func doGet(t *testing.T, r *gin.Engine, url string) (*httptest.ResponseRecorder, listResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	r.ServeHTTP(w, req)
+
+	var resp listResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+func TestListAlerts_Filters(t *testing.T) {
+	repo := &MockAlertRepository{
+		ListFunc: func(ctx context.Context, filter *repository.AlertFilter) ([]*alert.AlertHistory, error) {
+			return []*alert.AlertHistory{{ID: "a-1", DeviceID: "dev-1", Severity: "critical"}}, nil
+		},
+		CountFunc: func(ctx context.Context, filter *repository.AlertFilter) (int64, error) {
+			return 1, nil
+		},
+	}
+	r := setupRouter(repo)
+
+	w, resp := doGet(t, r, "/api/v1/alerts?device_id=dev-1&severity=critical&state=firing"+
+		"&from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), resp.Total)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "a-1", resp.Data[0].ID)
+
+	f := repo.LastFilter
+	require.NotNil(t, f)
+	assert.Equal(t, "dev-1", *f.DeviceID)
+	assert.Equal(t, "critical", *f.Severity)
+	assert.Equal(t, alert.AlertStateFiring, *f.State)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *f.From)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), *f.To)
+}
+
+func TestListAlerts_NoFilters(t *testing.T) {
+	repo := &MockAlertRepository{}
+	r := setupRouter(repo)
+
+	w, _ := doGet(t, r, "/api/v1/alerts")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	f := repo.LastFilter
+	assert.Nil(t, f.DeviceID)
+	assert.Nil(t, f.Severity)
+	assert.Nil(t, f.State)
+	assert.Nil(t, f.From)
+	assert.Nil(t, f.To)
+}
+
+func TestListAlerts_PageBoundaries(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantPage     int
+		wantPageSize int
+		wantOffset   int
+	}{
+		{"defaults", "", 1, 20, 0},
+		{"second page", "?page=2&page_size=10", 2, 10, 10},
+		{"page below one", "?page=0", 1, 20, 0},
+		{"negative page", "?page=-3", 1, 20, 0},
+		{"page size zero", "?page_size=0", 1, 20, 0},
+		{"page size at max", "?page_size=100", 1, 100, 0},
+		{"page size above max", "?page=3&page_size=500", 3, 100, 200},
+		{"non numeric", "?page=abc&page_size=xyz", 1, 20, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockAlertRepository{}
+			w, resp := doGet(t, setupRouter(repo), "/api/v1/alerts"+tt.query)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			assert.Equal(t, tt.wantPage, resp.Page)
+			assert.Equal(t, tt.wantPageSize, resp.PageSize)
+			assert.Equal(t, tt.wantPageSize, repo.LastFilter.Limit)
+			assert.Equal(t, tt.wantOffset, repo.LastFilter.Offset)
+		})
+	}
+}
+
+func TestListAlerts_InvalidTimeRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"bad from", "?from=yesterday"},
+		{"bad to", "?to=2024-13-01"},
+		{"inverted", "?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z"},
+		{"empty", "?from=2024-01-01T00:00:00Z&to=2024-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockAlertRepository{}
+			w, _ := doGet(t, setupRouter(repo), "/api/v1/alerts"+tt.query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Nil(t, repo.LastFilter, "repository must not be queried")
+		})
+	}
+}
+
+func TestListAlerts_RepositoryError(t *testing.T) {
+	repo := &MockAlertRepository{
+		ListFunc: func(ctx context.Context, filter *repository.AlertFilter) ([]*alert.AlertHistory, error) {
+			return nil, errors.New("db down")
+		},
+	}
+
+	w, _ := doGet(t, setupRouter(repo), "/api/v1/alerts")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestExportAlerts_CSV(t *testing.T) {
+	resolved := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	repo := &MockAlertRepository{
+		ListFunc: func(ctx context.Context, filter *repository.AlertFilter) ([]*alert.AlertHistory, error) {
+			if filter.Offset > 0 {
+				return nil, nil
+			}
+			return []*alert.AlertHistory{
+				{
+					ID: "a-2", RuleID: "cpu-high", DeviceID: "dev-1", DeviceName: "core-1", IPAddress: "10.0.0.1",
+					MetricName: "cpu_usage", Severity: "critical", State: alert.AlertStateFiring,
+					Value: 97.5, Threshold: 90, Message: "CPU high, check load",
+					TriggeredAt: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+				},
+				{
+					ID: "a-1", RuleID: "cpu-high", DeviceID: "dev-1", MetricName: "cpu_usage",
+					Severity: "critical", State: alert.AlertStateResolved, Value: 91, Threshold: 90,
+					TriggeredAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), ResolvedAt: &resolved,
+				},
+			}, nil
+		},
+	}
+	r := setupRouter(repo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/alerts/export?format=csv&device_id=dev-1&range=24h", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=\"alerts-")
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{
+		"id", "triggered_at", "resolved_at", "device_id", "device_name", "ip_address",
+		"rule_id", "metric_name", "severity", "state", "value", "threshold", "message",
+	}, records[0])
+	assert.Equal(t, []string{
+		"a-2", "2024-01-01T11:00:00Z", "", "dev-1", "core-1", "10.0.0.1",
+		"cpu-high", "cpu_usage", "critical", "firing", "97.5", "90", "CPU high, check load",
+	}, records[1])
+	assert.Equal(t, "2024-01-01T10:30:00Z", records[2][2])
+
+	f := repo.LastFilter
+	require.NotNil(t, f)
+	assert.Equal(t, "dev-1", *f.DeviceID)
+	require.NotNil(t, f.From)
+	require.NotNil(t, f.To)
+	assert.Equal(t, 24*time.Hour, f.To.Sub(*f.From).Round(time.Second))
+}
+
+func TestExportAlerts_EscapesFormulas(t *testing.T) {
+	repo := &MockAlertRepository{
+		ListFunc: func(ctx context.Context, filter *repository.AlertFilter) ([]*alert.AlertHistory, error) {
+			if filter.Offset > 0 {
+				return nil, nil
+			}
+			return []*alert.AlertHistory{{
+				ID: "a-1", DeviceName: `=HYPERLINK("http://evil.example","x")`, IPAddress: "10.0.0.1",
+				RuleID: "+rule", MetricName: "@metric", Severity: "warning", State: alert.AlertStateFiring,
+				Value: -3.5, Threshold: -1, Message: "-1 dBm below threshold",
+				TriggeredAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			}}, nil
+		},
+	}
+	r := setupRouter(repo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/alerts/export", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	row := records[1]
+	assert.Equal(t, `'=HYPERLINK("http://evil.example","x")`, row[4])
+	assert.Equal(t, "10.0.0.1", row[5])
+	assert.Equal(t, "'+rule", row[6])
+	assert.Equal(t, "'@metric", row[7])
+	assert.Equal(t, "-3.5", row[10], "numbers are not escaped")
+	assert.Equal(t, "-1", row[11])
+	assert.Equal(t, "'-1 dBm below threshold", row[12])
+}
+
+func TestExportAlerts_ReadsInBatches(t *testing.T) {
+	var offsets []int
+	repo := &MockAlertRepository{
+		ListFunc: func(ctx context.Context, filter *repository.AlertFilter) ([]*alert.AlertHistory, error) {
+			offsets = append(offsets, filter.Offset)
+			n := filter.Limit
+			if filter.Offset > 0 {
+				n = 3
+			}
+			alerts := make([]*alert.AlertHistory, n)
+			for i := range alerts {
+				alerts[i] = &alert.AlertHistory{ID: "a", State: alert.AlertStateFiring}
+			}
+			return alerts, nil
+		},
+	}
+	r := setupRouter(repo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/alerts/export", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1+500+3)
+	assert.Equal(t, []int{0, 500}, offsets)
+}
+
+func TestExportAlerts_EmptyHistoryHasHeader(t *testing.T) {
+	r := setupRouter(&MockAlertRepository{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/alerts/export", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "id,triggered_at,"))
+}
+
+func TestExportAlerts_InvalidParams(t *testing.T) {
+	r := setupRouter(&MockAlertRepository{})
+
+	for _, url := range []string{
+		"/api/v1/alerts/export?format=xlsx",
+		"/api/v1/alerts/export?range=yesterday",
+		"/api/v1/alerts/export?range=24h&from=2024-01-01T00:00:00Z",
+		"/api/v1/alerts/export?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
+
+func doPost(r *gin.Engine, url, body string) (*httptest.ResponseRecorder, *alert.AlertHistory) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	r.ServeHTTP(w, req)
+
+	var a alert.AlertHistory
+	if w.Code == http.StatusOK {
+		_ = json.Unmarshal(w.Body.Bytes(), &a)
+	}
+	return w, &a
+}
+
+func TestAlertLifecycle_AcknowledgeThenResolve(t *testing.T) {
+	repo := &MockAlertRepository{Alerts: map[string]*alert.AlertHistory{
+		"a1": {ID: "a1", DeviceID: "dev-1", RuleID: "rule-2", State: alert.AlertStateFiring},
+	}}
+	r := setupRouter(repo)
+
+	w, a := doPost(r, "/api/v1/alerts/a1/acknowledge", `{"acknowledged_by":"noc-oncall"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, alert.AlertStateAcknowledged, a.State)
+	assert.Equal(t, "noc-oncall", a.AcknowledgedBy)
+	assert.NotNil(t, a.AcknowledgedAt)
+
+	w, _ = doPost(r, "/api/v1/alerts/a1/acknowledge", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "only firing alerts can be acknowledged")
+
+	w, a = doPost(r, "/api/v1/alerts/a1/resolve", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, alert.AlertStateResolved, a.State)
+	assert.NotNil(t, a.ResolvedAt)
+
+	w, _ = doPost(r, "/api/v1/alerts/a1/resolve", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAlertLifecycle_NotFound(t *testing.T) {
+	r := setupRouter(&MockAlertRepository{})
+
+	w, _ := doPost(r, "/api/v1/alerts/missing/acknowledge", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/alerts/missing", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
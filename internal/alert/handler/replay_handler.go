@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/alert"
+	"github.com/yourorg/nms-go/internal/alert/service"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+// ReplayRequest is the request body for POST /api/v1/alerts/replay
+type ReplayRequest struct {
+	// Minutes is how far back to replay (default 60, max 1440)
+	Minutes int `json:"minutes"`
+
+	// DeviceID restricts the replay to one device
+	DeviceID string `json:"device_id"`
+
+	// Rules are evaluated instead of the engine's rules when set
+	Rules []alert.Rule `json:"rules"`
+}
+
+type ReplayHandler struct {
+	service service.ReplayService
+}
+
+func NewReplayHandler(service service.ReplayService) *ReplayHandler {
+	return &ReplayHandler{service: service}
+}
+
+// Replay handles POST /api/v1/alerts/replay
+//
+// Runs the stored poll results of the last N minutes through the alert rules
+// in dry-run mode: nothing is notified or recorded. Returns the alerts that
+// would have fired.
+func (h *ReplayHandler) Replay(c *gin.Context) {
+	var req ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	result, err := h.service.Replay(c.Request.Context(), &service.ReplayQuery{
+		Window:   time.Duration(req.Minutes) * time.Minute,
+		DeviceID: req.DeviceID,
+		Rules:    req.Rules,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReplay) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, result)
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/alert/handler"
+	"github.com/yourorg/nms-go/internal/alert/service"
+	"github.com/yourorg/nms-go/internal/features/metrics"
+)
+
+// fakeQuerier serves canned device_poll series and records the range query.
+type fakeQuerier struct {
+	series []metrics.Series
+	rng    *metrics.RangeQuery
+}
+
+func (f *fakeQuerier) QueryLatest(ctx context.Context, q metrics.LatestQuery) ([]metrics.Series, error) {
+	return nil, nil
+}
+
+func (f *fakeQuerier) QueryRange(ctx context.Context, q metrics.RangeQuery) ([]metrics.Series, error) {
+	f.rng = &q
+	return f.series, nil
+}
+
+var replayNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func pollSeries(field, deviceID string, points ...metrics.Point) metrics.Series {
+	return metrics.Series{
+		Measurement: "device_poll",
+		Field:       field,
+		Tags:        map[string]string{"device_id": deviceID, "ip_address": "10.0.0." + deviceID[len(deviceID)-1:]},
+		Points:      points,
+	}
+}
+
+func historicalPolls() *fakeQuerier {
+	t1 := replayNow.Add(-20 * time.Minute)
+	t2 := replayNow.Add(-10 * time.Minute)
+	return &fakeQuerier{series: []metrics.Series{
+		pollSeries("rtt_ms", "dev-1", metrics.Point{Time: t1, Value: 150}, metrics.Point{Time: t2, Value: 0}),
+		pollSeries("success", "dev-1", metrics.Point{Time: t1, Value: 1}, metrics.Point{Time: t2, Value: 0}),
+		pollSeries("rtt_ms", "dev-2", metrics.Point{Time: t1, Value: 20}, metrics.Point{Time: t2, Value: 25}),
+		pollSeries("success", "dev-2", metrics.Point{Time: t1, Value: 1}, metrics.Point{Time: t2, Value: 1}),
+	}}
+}
+
+func postReplay(t *testing.T, q metrics.MetricQuerier, body string) (*httptest.ResponseRecorder, service.ReplayResult) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewReplayHandler(service.NewReplayServiceForTest(q, func() time.Time { return replayNow }))
+	r.POST("/api/v1/alerts/replay", h.Replay)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/alerts/replay", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var resp service.ReplayResult
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+func TestReplay_DefaultRules(t *testing.T) {
+	q := historicalPolls()
+
+	w, resp := postReplay(t, q, `{"minutes": 30}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, "device_poll", q.rng.Measurement)
+	assert.Equal(t, replayNow.Add(-30*time.Minute), q.rng.Start)
+	assert.Equal(t, replayNow, q.rng.Stop)
+
+	assert.Equal(t, []string{"rule-1", "rule-2"}, resp.Rules)
+	assert.Equal(t, 4, resp.MetricsEvaluated)
+	require.Len(t, resp.Fired, 2)
+
+	latency := resp.Fired[0]
+	assert.Equal(t, "rule-1", latency.RuleID)
+	assert.Equal(t, "dev-1", latency.DeviceID)
+	assert.Equal(t, "10.0.0.1", latency.IPAddress)
+	assert.Equal(t, 150.0, latency.Value)
+	assert.Equal(t, replayNow.Add(-20*time.Minute), latency.TriggeredAt)
+
+	down := resp.Fired[1]
+	assert.Equal(t, "rule-2", down.RuleID)
+	assert.Equal(t, "dev-1", down.DeviceID)
+	assert.Equal(t, "critical", down.Severity)
+	assert.Equal(t, replayNow.Add(-10*time.Minute), down.TriggeredAt)
+}
+
+func TestReplay_CandidateRules(t *testing.T) {
+	q := historicalPolls()
+
+	w, resp := postReplay(t, q, `{
+		"device_id": "dev-2",
+		"rules": [{"id": "rtt-strict", "metric_name": "rtt_ms", "operator": ">=", "threshold": 25, "severity": "info"}]
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, "dev-2", q.rng.Tags["device_id"])
+	assert.Equal(t, replayNow.Add(-time.Hour), q.rng.Start, "the window defaults to an hour")
+	assert.Equal(t, []string{"rtt-strict"}, resp.Rules)
+
+	// The fake ignores the device filter, so dev-1's 150ms poll matches too.
+	require.Len(t, resp.Fired, 2)
+	assert.Equal(t, "dev-1", resp.Fired[0].DeviceID)
+	assert.Equal(t, "dev-2", resp.Fired[1].DeviceID)
+	assert.Equal(t, 25.0, resp.Fired[1].Value)
+}
+
+func TestReplay_WarnsOnUnknownMetric(t *testing.T) {
+	w, resp := postReplay(t, historicalPolls(), `{
+		"rules": [
+			{"id": "typo", "metric_name": "rtt_mss", "operator": ">", "threshold": 25},
+			{"id": "rtt", "metric_name": "rtt_ms", "operator": ">", "threshold": 25}
+		]
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], `"typo"`)
+	for _, f := range resp.Fired {
+		assert.Equal(t, "rtt", f.RuleID)
+	}
+}
+
+func TestReplay_NothingFires(t *testing.T) {
+	w, resp := postReplay(t, &fakeQuerier{}, `{"minutes": 5}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, resp.MetricsEvaluated)
+	assert.NotNil(t, resp.Fired)
+	assert.Empty(t, resp.Fired)
+}
+
+func TestReplay_InvalidRequests(t *testing.T) {
+	for _, body := range []string{
+		`{"minutes": 1441}`,
+		`{"minutes": -5}`,
+		`{"rules": [{"id": "bad", "metric_name": "rtt_ms", "operator": "!="}]}`,
+		`{"rules": [{"id": "bad", "operator": ">"}]}`,
+		`not json`,
+	} {
+		q := &fakeQuerier{}
+		w, _ := postReplay(t, q, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, q.rng, "no metrics are read for %s", body)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/alert"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+// TestRuleRequest is the request body for POST /api/v1/alerts/rules/test
+type TestRuleRequest struct {
+	Rule alert.Rule `json:"rule"`
+
+	// Metric is a sample poll result, shaped like the metrics workers publish
+	Metric commonModel.Metric `json:"metric"`
+}
+
+// TestRuleResult is the outcome of checking one rule against a sample metric
+type TestRuleResult struct {
+	// Fired reports whether the rule would fire for the metric
+	Fired bool `json:"fired"`
+
+	// Matched is false when the rule does not apply to the metric: it targets
+	// another device, or the metric has no numeric value for it
+	Matched bool `json:"matched"`
+
+	// Value is the metric value compared against the threshold, nil when
+	// the rule did not match
+	Value *float64 `json:"value"`
+
+	// Warnings flag rules that cannot fire, e.g. on an unknown metric
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type RuleHandler struct{}
+
+func NewRuleHandler() *RuleHandler {
+	return &RuleHandler{}
+}
+
+// TestRule handles POST /api/v1/alerts/rules/test
+//
+// Evaluates a single rule against a sample metric so rule authors can check
+// the operator and threshold before saving. Nothing is notified or recorded.
+func (h *RuleHandler) TestRule(c *gin.Context) {
+	var req TestRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := req.Rule.Validate(); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	value, ok, fired := alert.Check(req.Rule, req.Metric)
+	result := TestRuleResult{Fired: fired, Matched: ok, Warnings: req.Rule.Warnings()}
+	if ok {
+		result.Value = &value
+	}
+	c.JSON(200, result)
+}
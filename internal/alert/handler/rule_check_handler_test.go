@@ -0,0 +1,135 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/alert/handler"
+)
+
+func postRuleTest(t *testing.T, body string) (*httptest.ResponseRecorder, handler.TestRuleResult) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/v1/alerts/rules/test", handler.NewRuleHandler().TestRule)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/alerts/rules/test", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var resp handler.TestRuleResult
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+func TestTestRule_Operators(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    string
+		fired    bool
+	}{
+		{">", "150", true},
+		{">", "100", false},
+		{"<", "50", true},
+		{"<", "100", false},
+		{"=", "100", true},
+		{"=", "100.5", false},
+		{">=", "100", true},
+		{">=", "99.9", false},
+		{"<=", "100", true},
+		{"<=", "100.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operator+" "+tt.value, func(t *testing.T) {
+			w, resp := postRuleTest(t, `{
+				"rule": {"id": "r", "metric_name": "rtt_ms", "operator": "`+tt.operator+`", "threshold": 100},
+				"metric": {"device_id": "dev-1", "values": {"rtt_ms": `+tt.value+`}}
+			}`)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			assert.Equal(t, tt.fired, resp.Fired)
+			assert.True(t, resp.Matched)
+			require.NotNil(t, resp.Value)
+			expected, _ := json.Number(tt.value).Float64()
+			assert.Equal(t, expected, *resp.Value)
+		})
+	}
+}
+
+func TestTestRule_BooleanMetric(t *testing.T) {
+	w, resp := postRuleTest(t, `{
+		"rule": {"id": "down", "metric_name": "success", "operator": "=", "threshold": 0},
+		"metric": {"values": {"success": false}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.True(t, resp.Fired)
+	require.NotNil(t, resp.Value)
+	assert.Equal(t, 0.0, *resp.Value)
+}
+
+func TestTestRule_MetricNameDoesNotMatch(t *testing.T) {
+	w, resp := postRuleTest(t, `{
+		"rule": {"id": "r", "metric_name": "rtt_ms", "operator": ">", "threshold": 100},
+		"metric": {"values": {"packet_loss": 150}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.False(t, resp.Fired)
+	assert.False(t, resp.Matched)
+	assert.Nil(t, resp.Value)
+	assert.Contains(t, w.Body.String(), `"value":null`)
+}
+
+func TestTestRule_OtherDevice(t *testing.T) {
+	w, resp := postRuleTest(t, `{
+		"rule": {"id": "r", "device_id": "dev-2", "metric_name": "rtt_ms", "operator": ">", "threshold": 100},
+		"metric": {"device_id": "dev-1", "values": {"rtt_ms": 150}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.False(t, resp.Fired)
+	assert.False(t, resp.Matched)
+}
+
+func TestTestRule_InvalidRequests(t *testing.T) {
+	for _, body := range []string{
+		`{"rule": {"id": "bad", "metric_name": "rtt_ms", "operator": "!="}, "metric": {"values": {"rtt_ms": 1}}}`,
+		`{"rule": {"id": "bad", "operator": ">"}, "metric": {"values": {"rtt_ms": 1}}}`,
+		`not json`,
+	} {
+		w, _ := postRuleTest(t, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestTestRule_WarnsOnUnknownMetric(t *testing.T) {
+	w, resp := postRuleTest(t, `{
+		"rule": {"id": "typo", "metric_name": "rtt_mss", "operator": ">", "threshold": 100},
+		"metric": {"values": {"rtt_ms": 150}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.False(t, resp.Matched)
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "rtt_mss")
+}
+
+func TestTestRule_NoWarningForKnownMetric(t *testing.T) {
+	w, _ := postRuleTest(t, `{
+		"rule": {"id": "r", "metric_name": "rtt_ms", "operator": ">", "threshold": 100},
+		"metric": {"values": {"rtt_ms": 150}}
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "warnings")
+}
@@ -0,0 +1,60 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+)
+
+// knownMetrics are the numeric values workers publish on MetricsSubject,
+// which rules can compare against. Keep in sync with the collectors in
+// internal/worker.
+var knownMetrics = map[string]bool{
+	// Every poll
+	"rtt_ms":  true,
+	"success": true,
+
+	// MikroTik system resources
+	"cpu_load":     true,
+	"free_memory":  true,
+	"total_memory": true,
+
+	// OLT system metrics and PON/ONT rollups
+	"uptime_seconds":       true,
+	"cpu_usage_percent":    true,
+	"memory_total_kb":      true,
+	"memory_used_kb":       true,
+	"memory_usage_percent": true,
+	"temperature_celsius":  true,
+	"pon_ports_down":       true,
+	"onts_online":          true,
+	"onts_offline":         true,
+
+	// SNMP interface traffic, summed over interfaces
+	"rx_bps": true,
+	"tx_bps": true,
+}
+
+// IsKnownMetric reports whether workers publish a metric named name
+func IsKnownMetric(name string) bool {
+	return knownMetrics[name]
+}
+
+// KnownMetrics returns the names of the published metrics, sorted
+func KnownMetrics() []string {
+	names := make([]string, 0, len(knownMetrics))
+	for name := range knownMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Warnings returns problems with the rule that do not make it invalid, such
+// as a metric name no worker publishes: the rule would never fire.
+func (r Rule) Warnings() []string {
+	var warnings []string
+	if r.MetricName != "" && !IsKnownMetric(r.MetricName) {
+		warnings = append(warnings, fmt.Sprintf("rule %q: metric_name %q is not a published metric and will never fire", r.ID, r.MetricName))
+	}
+	return warnings
+}
@@ -0,0 +1,38 @@
+package alert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yourorg/nms-go/internal/alert"
+)
+
+func TestRuleWarnings_KnownMetric(t *testing.T) {
+	for _, name := range []string{"rtt_ms", "success", "cpu_load", "onts_offline"} {
+		rule := alert.Rule{ID: "r", MetricName: name, Operator: ">"}
+		assert.True(t, alert.IsKnownMetric(name), name)
+		assert.Empty(t, rule.Warnings(), name)
+	}
+}
+
+func TestRuleWarnings_UnknownMetric(t *testing.T) {
+	rule := alert.Rule{ID: "typo", MetricName: "rtt_mss", Operator: ">"}
+
+	assert.False(t, alert.IsKnownMetric("rtt_mss"))
+	warnings := rule.Warnings()
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `"rtt_mss"`)
+	assert.Contains(t, warnings[0], `"typo"`)
+}
+
+func TestDefaultRulesUseKnownMetrics(t *testing.T) {
+	for _, rule := range alert.DefaultRules() {
+		assert.Empty(t, rule.Warnings(), rule.ID)
+	}
+}
+
+func TestKnownMetricsSorted(t *testing.T) {
+	names := alert.KnownMetrics()
+	assert.Contains(t, names, "rtt_ms")
+	assert.IsNonDecreasing(t, names)
+}
@@ -0,0 +1,76 @@
+package alert
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore keeps the active alert of each device and rule in memory. The
+// engine uses it when no database is configured; resolved alerts are
+// dropped.
+type MemoryStore struct {
+	mu     sync.Mutex
+	active map[string]*AlertHistory // deviceID/ruleID -> alert
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{active: make(map[string]*AlertHistory)}
+}
+
+func memoryKey(deviceID, ruleID string) string {
+	return deviceID + "/" + ruleID
+}
+
+// Create stores a new alert, or returns ErrAlreadyActive if the device has
+// an active alert of the rule
+func (m *MemoryStore) Create(_ context.Context, history *AlertHistory) error {
+	key := memoryKey(history.DeviceID, history.RuleID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.active[key]; ok {
+		return ErrAlreadyActive
+	}
+	if history.ID == "" {
+		history.ID = uuid.NewString()
+	}
+	stored := *history
+	m.active[key] = &stored
+	return nil
+}
+
+// GetActive returns a copy of the active alert of the rule on the device
+func (m *MemoryStore) GetActive(_ context.Context, deviceID, ruleID string) (*AlertHistory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.active[memoryKey(deviceID, ruleID)]
+	if !ok {
+		return nil, nil
+	}
+	loaded := *stored
+	return &loaded, nil
+}
+
+// Touch replaces the stored alert with history
+func (m *MemoryStore) Touch(_ context.Context, history *AlertHistory) error {
+	stored := *history
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active[memoryKey(history.DeviceID, history.RuleID)] = &stored
+	return nil
+}
+
+// ResolveActive drops the active alert of the rule on the device
+func (m *MemoryStore) ResolveActive(_ context.Context, deviceID, ruleID string, _ time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := memoryKey(deviceID, ruleID)
+	if _, ok := m.active[key]; !ok {
+		return 0, nil
+	}
+	delete(m.active, key)
+	return 1, nil
+}
@@ -1,5 +1,14 @@
 package alert
 
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
 // Rule represents a condition to trigger an alert
 type Rule struct {
 	ID          string  `json:"id"`
@@ -9,4 +18,92 @@ type Rule struct {
 	Threshold   float64 `json:"threshold"`
 	Description string  `json:"description"`
 	Severity    string  `json:"severity"` // info, warning, critical
+	// Channels names the notification channels of the rule's alerts, e.g.
+	// "email" or a configured webhook. Empty uses the alert config's routes,
+	// then its default channels.
+	Channels []string `json:"channels,omitempty"`
+}
+
+// Validate checks that the rule names a metric and a supported operator
+func (r Rule) Validate() error {
+	if r.MetricName == "" {
+		return fmt.Errorf("rule %q: metric_name is required", r.ID)
+	}
+	switch r.Operator {
+	case ">", "<", "=", ">=", "<=":
+		return nil
+	}
+	return fmt.Errorf("rule %q: unsupported operator %q", r.ID, r.Operator)
+}
+
+// AlertState represents the lifecycle state of a fired alert: firing, then
+// optionally acknowledged, then resolved.
+type AlertState string
+
+const (
+	AlertStateFiring       AlertState = "firing"
+	AlertStateAcknowledged AlertState = "acknowledged"
+	AlertStateResolved     AlertState = "resolved"
+)
+
+// Active reports whether the alert is not resolved yet
+func (s AlertState) Active() bool {
+	return s == AlertStateFiring || s == AlertStateAcknowledged
+}
+
+// ErrAlreadyActive is returned by AlertStore.Create when the device already
+// has an active alert of the rule, e.g. one another engine replica opened
+// first.
+var ErrAlreadyActive = errors.New("alert already active")
+
+// Active alerts are unique per device and rule in the database: the partial
+// unique index ActiveIndex covers the rows matching ActiveCondition.
+const (
+	ActiveIndex     = "idx_alert_history_active"
+	ActiveCondition = "state <> 'resolved'"
+)
+
+// AlertHistory is a persisted record of a fired alert. There is at most one
+// active alert per device and rule: while the condition persists the same
+// record is updated instead of adding a new one, and ActiveIndex rejects a
+// second one.
+// The composite indexes back the filtered, time-ordered history queries and
+// the lookup of the active alert.
+type AlertHistory struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	RuleID      string     `json:"rule_id" gorm:"not null;size:100;index:idx_alert_history_device_rule,priority:2;uniqueIndex:idx_alert_history_active,priority:2"`
+	DeviceID    string     `json:"device_id" gorm:"not null;size:100;index:idx_alert_history_device_triggered,priority:1;index:idx_alert_history_device_rule,priority:1;uniqueIndex:idx_alert_history_active,priority:1,where:state <> 'resolved'"`
+	DeviceName  string     `json:"device_name,omitempty" gorm:"size:255"`
+	IPAddress   string     `json:"ip_address,omitempty" gorm:"size:64"`
+	MetricName  string     `json:"metric_name" gorm:"size:100"`
+	Severity    string     `json:"severity" gorm:"size:20;index:idx_alert_history_severity_triggered,priority:1"`
+	State       AlertState `json:"state" gorm:"size:20;index:idx_alert_history_state_triggered,priority:1"`
+	Value       float64    `json:"value"`
+	Threshold   float64    `json:"threshold"`
+	Message     string     `json:"message" gorm:"type:text"`
+	TriggeredAt time.Time  `json:"triggered_at" gorm:"not null;index:idx_alert_history_triggered;index:idx_alert_history_device_triggered,priority:2;index:idx_alert_history_severity_triggered,priority:2;index:idx_alert_history_state_triggered,priority:2"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// LastSeenAt is when the condition was last observed
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	// LastNotifiedAt is when a notification was last sent, for re-notifying
+	// alerts that stay firing
+	LastNotifiedAt    *time.Time `json:"last_notified_at,omitempty"`
+	NotificationCount int        `json:"notification_count"`
+	AcknowledgedAt    *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy    string     `json:"acknowledged_by,omitempty" gorm:"size:255"`
+}
+
+// TableName specifies the table name for AlertHistory
+func (AlertHistory) TableName() string {
+	return "alert_history"
+}
+
+// BeforeCreate assigns the ID in Go if unset
+func (h *AlertHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.NewString()
+	}
+	return nil
 }
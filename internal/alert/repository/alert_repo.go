@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yourorg/nms-go/internal/alert"
+	"gorm.io/gorm"
+)
+
+// ErrAlertNotFound is returned when no alert has the ID.
+var ErrAlertNotFound = errors.New("alert not found")
+
+// uniqueViolation is the Postgres SQLSTATE for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// AlertRepository defines the interface for alert history data access. It
+// implements alert.AlertStore for the engine.
+type AlertRepository interface {
+	Create(ctx context.Context, history *alert.AlertHistory) error
+	GetByID(ctx context.Context, id string) (*alert.AlertHistory, error)
+	List(ctx context.Context, filter *AlertFilter) ([]*alert.AlertHistory, error)
+	Count(ctx context.Context, filter *AlertFilter) (int64, error)
+
+	GetActive(ctx context.Context, deviceID, ruleID string) (*alert.AlertHistory, error)
+	Touch(ctx context.Context, history *alert.AlertHistory) error
+	ResolveActive(ctx context.Context, deviceID, ruleID string, at time.Time) (int64, error)
+
+	// Acknowledge moves a firing alert to acknowledged. It returns false if
+	// the alert was not firing.
+	Acknowledge(ctx context.Context, id, by string, at time.Time) (bool, error)
+	// Resolve resolves a firing or acknowledged alert. It returns false if
+	// the alert was not active.
+	Resolve(ctx context.Context, id string, at time.Time) (bool, error)
+}
+
+// AlertFilter represents filtering options for alert history queries
+type AlertFilter struct {
+	DeviceID *string
+	Severity *string
+	State    *alert.AlertState
+	States   []alert.AlertState // any of these states
+	From     *time.Time         // inclusive
+	To       *time.Time         // exclusive
+	Limit    int
+	Offset   int
+}
+
+type alertRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertRepository creates a new instance of AlertRepository
+func NewAlertRepository(db *gorm.DB) AlertRepository {
+	return &alertRepository{db: db}
+}
+
+// Create persists a fired alert. It returns alert.ErrAlreadyActive when the
+// active-alert index already holds one for the device and rule.
+func (r *alertRepository) Create(ctx context.Context, history *alert.AlertHistory) error {
+	err := r.db.WithContext(ctx).Create(history).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == alert.ActiveIndex {
+		return fmt.Errorf("%w: device %s, rule %s", alert.ErrAlreadyActive, history.DeviceID, history.RuleID)
+	}
+	return err
+}
+
+// GetByID retrieves an alert by ID
+func (r *alertRepository) GetByID(ctx context.Context, id string) (*alert.AlertHistory, error) {
+	var history alert.AlertHistory
+	err := r.db.WithContext(ctx).First(&history, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAlertNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// activeStates are the states of an alert that is not resolved
+var activeStates = []alert.AlertState{alert.AlertStateFiring, alert.AlertStateAcknowledged}
+
+// GetActive returns the newest active alert of the rule on the device, or nil
+func (r *alertRepository) GetActive(ctx context.Context, deviceID, ruleID string) (*alert.AlertHistory, error) {
+	var alerts []*alert.AlertHistory
+	err := r.db.WithContext(ctx).
+		Where("device_id = ? AND rule_id = ? AND state IN ?", deviceID, ruleID, activeStates).
+		Order("triggered_at DESC").Order("id DESC").
+		Limit(1).
+		Find(&alerts).Error
+	if err != nil || len(alerts) == 0 {
+		return nil, err
+	}
+	return alerts[0], nil
+}
+
+// Touch saves the latest value and the last seen and notification fields
+func (r *alertRepository) Touch(ctx context.Context, history *alert.AlertHistory) error {
+	return r.db.WithContext(ctx).
+		Model(history).
+		Select("value", "last_seen_at", "last_notified_at", "notification_count").
+		Updates(history).Error
+}
+
+// ResolveActive resolves every active alert of the rule on the device, which
+// also closes duplicates recorded before alerts were deduplicated
+func (r *alertRepository) ResolveActive(ctx context.Context, deviceID, ruleID string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&alert.AlertHistory{}).
+		Where("device_id = ? AND rule_id = ? AND state IN ?", deviceID, ruleID, activeStates).
+		Updates(map[string]interface{}{"state": alert.AlertStateResolved, "resolved_at": at})
+	return result.RowsAffected, result.Error
+}
+
+// Acknowledge moves a firing alert to acknowledged
+func (r *alertRepository) Acknowledge(ctx context.Context, id, by string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&alert.AlertHistory{}).
+		Where("id = ? AND state = ?", id, alert.AlertStateFiring).
+		Updates(map[string]interface{}{
+			"state":           alert.AlertStateAcknowledged,
+			"acknowledged_at": at,
+			"acknowledged_by": by,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Resolve resolves an active alert
+func (r *alertRepository) Resolve(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&alert.AlertHistory{}).
+		Where("id = ? AND state IN ?", id, activeStates).
+		Updates(map[string]interface{}{"state": alert.AlertStateResolved, "resolved_at": at})
+	return result.RowsAffected > 0, result.Error
+}
+
+// List retrieves alert history newest first based on filter criteria
+func (r *alertRepository) List(ctx context.Context, filter *AlertFilter) ([]*alert.AlertHistory, error) {
+	var alerts []*alert.AlertHistory
+
+	query := r.db.WithContext(ctx).Model(&alert.AlertHistory{})
+	query = r.applyFilter(query, filter)
+
+	// id breaks ties so pages are stable when alerts share a timestamp
+	query = query.Order("triggered_at DESC").Order("id DESC")
+
+	if filter != nil {
+		if filter.Limit > 0 {
+			query = query.Limit(filter.Limit)
+		}
+		if filter.Offset > 0 {
+			query = query.Offset(filter.Offset)
+		}
+	}
+
+	err := query.Find(&alerts).Error
+	return alerts, err
+}
+
+// Count returns the total number of alerts matching the filter
+func (r *alertRepository) Count(ctx context.Context, filter *AlertFilter) (int64, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&alert.AlertHistory{})
+	query = r.applyFilter(query, filter)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// applyFilter applies filter criteria to the query
+func (r *alertRepository) applyFilter(query *gorm.DB, filter *AlertFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	if filter.DeviceID != nil {
+		query = query.Where("device_id = ?", *filter.DeviceID)
+	}
+
+	if filter.Severity != nil {
+		query = query.Where("severity = ?", *filter.Severity)
+	}
+
+	if filter.State != nil {
+		query = query.Where("state = ?", *filter.State)
+	}
+
+	if len(filter.States) > 0 {
+		query = query.Where("state IN ?", filter.States)
+	}
+
+	if filter.From != nil {
+		query = query.Where("triggered_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("triggered_at < ?", *filter.To)
+	}
+
+	return query
+}
@@ -0,0 +1,224 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/alert"
+	"github.com/yourorg/nms-go/internal/alert/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db, mock
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestAlertRepository_List_Filters(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	firing := alert.AlertStateFiring
+
+	tests := []struct {
+		name   string
+		filter *repository.AlertFilter
+		where  string
+		args   []driver.Value
+	}{
+		{
+			name:   "no filter",
+			filter: nil,
+			where:  "",
+		},
+		{
+			name:   "device",
+			filter: &repository.AlertFilter{DeviceID: strPtr("dev-1")},
+			where:  " WHERE device_id = $1",
+			args:   []driver.Value{"dev-1"},
+		},
+		{
+			name:   "severity",
+			filter: &repository.AlertFilter{Severity: strPtr("critical")},
+			where:  " WHERE severity = $1",
+			args:   []driver.Value{"critical"},
+		},
+		{
+			name:   "state",
+			filter: &repository.AlertFilter{State: &firing},
+			where:  " WHERE state = $1",
+			args:   []driver.Value{firing},
+		},
+		{
+			name:   "states",
+			filter: &repository.AlertFilter{States: []alert.AlertState{alert.AlertStateFiring, alert.AlertStateAcknowledged}},
+			where:  " WHERE state IN ($1,$2)",
+			args:   []driver.Value{alert.AlertStateFiring, alert.AlertStateAcknowledged},
+		},
+		{
+			name:   "time range",
+			filter: &repository.AlertFilter{From: &from, To: &to},
+			where:  " WHERE triggered_at >= $1 AND triggered_at < $2",
+			args:   []driver.Value{from, to},
+		},
+		{
+			name: "all filters",
+			filter: &repository.AlertFilter{
+				DeviceID: strPtr("dev-1"), Severity: strPtr("warning"), State: &firing, From: &from, To: &to,
+			},
+			where: " WHERE device_id = $1 AND severity = $2 AND state = $3 AND triggered_at >= $4 AND triggered_at < $5",
+			args:  []driver.Value{"dev-1", "warning", firing, from, to},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := repository.NewAlertRepository(db)
+
+			sql := `SELECT * FROM "alert_history"` + tt.where + ` ORDER BY triggered_at DESC,id DESC`
+			expect := mock.ExpectQuery("^" + regexp.QuoteMeta(sql) + "$")
+			if len(tt.args) > 0 {
+				expect = expect.WithArgs(tt.args...)
+			}
+			expect.WillReturnRows(sqlmock.NewRows([]string{"id", "device_id"}).AddRow("a-1", "dev-1"))
+
+			alerts, err := repo.List(context.Background(), tt.filter)
+			require.NoError(t, err)
+			require.Len(t, alerts, 1)
+			assert.Equal(t, "a-1", alerts[0].ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAlertRepository_List_Pagination(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *repository.AlertFilter
+		suffix string
+	}{
+		{"first page", &repository.AlertFilter{Limit: 20}, " LIMIT 20"},
+		{"later page", &repository.AlertFilter{Limit: 20, Offset: 40}, " LIMIT 20 OFFSET 40"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			repo := repository.NewAlertRepository(db)
+
+			sql := `SELECT * FROM "alert_history" ORDER BY triggered_at DESC,id DESC` + tt.suffix
+			mock.ExpectQuery("^" + regexp.QuoteMeta(sql) + "$").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			alerts, err := repo.List(context.Background(), tt.filter)
+			require.NoError(t, err)
+			assert.Empty(t, alerts)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAlertRepository_Count_IgnoresPagination(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewAlertRepository(db)
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT count(*) FROM "alert_history" WHERE severity = $1`) + "$").
+		WithArgs("critical").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	count, err := repo.Count(context.Background(), &repository.AlertFilter{
+		Severity: strPtr("critical"), Limit: 20, Offset: 40,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRepository_GetActive(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewAlertRepository(db)
+
+	query := "^" + regexp.QuoteMeta(`SELECT * FROM "alert_history" WHERE device_id = $1 AND rule_id = $2 AND state IN ($3,$4) ORDER BY triggered_at DESC,id DESC LIMIT 1`) + "$"
+	mock.ExpectQuery(query).
+		WithArgs("dev-1", "rule-2", "firing", "acknowledged").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow("a1", "acknowledged"))
+	mock.ExpectQuery(query).
+		WithArgs("dev-2", "rule-2", "firing", "acknowledged").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state"}))
+
+	active, err := repo.GetActive(context.Background(), "dev-1", "rule-2")
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	assert.Equal(t, alert.AlertStateAcknowledged, active.State)
+
+	active, err = repo.GetActive(context.Background(), "dev-2", "rule-2")
+	require.NoError(t, err)
+	assert.Nil(t, active)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRepository_Acknowledge_OnlyFiring(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewAlertRepository(db)
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "alert_history" SET "acknowledged_at"=$1,"acknowledged_by"=$2,"state"=$3 WHERE id = $4 AND state = $5`)).
+		WithArgs(at, "noc", "acknowledged", "a1", "firing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	changed, err := repo.Acknowledge(context.Background(), "a1", "noc", at)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRepository_Create_AlreadyActive(t *testing.T) {
+	insert := `INSERT INTO "alert_history"`
+
+	t.Run("active index", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(regexp.QuoteMeta(insert)).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: alert.ActiveIndex})
+
+		err := repository.NewAlertRepository(db).Create(context.Background(), &alert.AlertHistory{DeviceID: "dev-1", RuleID: "rule-2"})
+		assert.ErrorIs(t, err, alert.ErrAlreadyActive)
+	})
+
+	t.Run("other constraint", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(regexp.QuoteMeta(insert)).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "alert_history_pkey"})
+
+		err := repository.NewAlertRepository(db).Create(context.Background(), &alert.AlertHistory{DeviceID: "dev-1", RuleID: "rule-2"})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, alert.ErrAlreadyActive)
+	})
+}
+
+func TestAlertHistory_ActiveIndex(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE UNIQUE INDEX IF NOT EXISTS "idx_alert_history_active" ON "alert_history" ("device_id","rule_id") WHERE state <> 'resolved'`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, db.Migrator().CreateIndex(&alert.AlertHistory{}, alert.ActiveIndex))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
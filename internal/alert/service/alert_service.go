@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/alert"
+	"github.com/yourorg/nms-go/internal/alert/repository"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	// exportBatchSize is the number of alerts read per query while exporting
+	exportBatchSize = 500
+)
+
+// ErrInvalidTimeRange is returned when the requested time range is empty or inverted.
+var ErrInvalidTimeRange = errors.New("to must be after from")
+
+// ErrAlertNotFound is returned when no alert has the ID.
+var ErrAlertNotFound = errors.New("alert not found")
+
+// ErrInvalidTransition is returned when an alert cannot move to the requested
+// state from its current one, e.g. acknowledging a resolved alert.
+var ErrInvalidTransition = errors.New("invalid alert state transition")
+
+type AlertService interface {
+	ListAlerts(ctx context.Context, query *ListAlertsQuery) ([]*alert.AlertHistory, int64, error)
+	GetAlert(ctx context.Context, id string) (*alert.AlertHistory, error)
+
+	// AcknowledgeAlert moves a firing alert to acknowledged, which stops
+	// re-notifications until it resolves.
+	AcknowledgeAlert(ctx context.Context, id, by string) (*alert.AlertHistory, error)
+	// ResolveAlert resolves a firing or acknowledged alert by hand. It fires
+	// again as a new alert if the condition is still met.
+	ResolveAlert(ctx context.Context, id string) (*alert.AlertHistory, error)
+
+	// ExportAlerts calls fn for every alert matching the query, newest first,
+	// reading the history in batches. Page and PageSize are ignored.
+	ExportAlerts(ctx context.Context, query *ListAlertsQuery, fn func(*alert.AlertHistory) error) error
+}
+
+// ListAlertsQuery holds the filters and pagination for the alert history.
+// Empty filter fields are ignored.
+type ListAlertsQuery struct {
+	DeviceID string
+	Severity string
+	State    alert.AlertState
+	States   []alert.AlertState // any of these states, e.g. all active ones
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	PageSize int
+}
+
+type alertService struct {
+	repo repository.AlertRepository
+}
+
+func NewAlertService(repo repository.AlertRepository) AlertService {
+	return &alertService{repo: repo}
+}
+
+func (s *alertService) ListAlerts(ctx context.Context, q *ListAlertsQuery) ([]*alert.AlertHistory, int64, error) {
+	if q.From != nil && q.To != nil && !q.To.After(*q.From) {
+		return nil, 0, ErrInvalidTimeRange
+	}
+
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+
+	filter := newFilter(q)
+	filter.Limit = q.PageSize
+	filter.Offset = (q.Page - 1) * q.PageSize
+
+	alerts, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return alerts, total, nil
+}
+
+func (s *alertService) GetAlert(ctx context.Context, id string) (*alert.AlertHistory, error) {
+	history, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrAlertNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrAlertNotFound, id)
+	}
+	return history, err
+}
+
+func (s *alertService) AcknowledgeAlert(ctx context.Context, id, by string) (*alert.AlertHistory, error) {
+	return s.transition(ctx, id, alert.AlertStateAcknowledged, func() (bool, error) {
+		return s.repo.Acknowledge(ctx, id, by, time.Now())
+	})
+}
+
+func (s *alertService) ResolveAlert(ctx context.Context, id string) (*alert.AlertHistory, error) {
+	return s.transition(ctx, id, alert.AlertStateResolved, func() (bool, error) {
+		return s.repo.Resolve(ctx, id, time.Now())
+	})
+}
+
+// transition applies a state change and returns the updated alert. The
+// update only matches alerts in a state it may leave, so a change racing the
+// engine or another user reports ErrInvalidTransition.
+func (s *alertService) transition(ctx context.Context, id string, to alert.AlertState, apply func() (bool, error)) (*alert.AlertHistory, error) {
+	current, err := s.GetAlert(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := apply()
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: %s alert cannot become %s", ErrInvalidTransition, current.State, to)
+	}
+	return s.GetAlert(ctx, id)
+}
+
+func (s *alertService) ExportAlerts(ctx context.Context, q *ListAlertsQuery, fn func(*alert.AlertHistory) error) error {
+	if q.From != nil && q.To != nil && !q.To.After(*q.From) {
+		return ErrInvalidTimeRange
+	}
+
+	filter := newFilter(q)
+	// Pin the end of the range so alerts fired during the export don't shift
+	// the offsets of later batches
+	if filter.To == nil {
+		now := time.Now()
+		filter.To = &now
+	}
+	filter.Limit = exportBatchSize
+
+	for {
+		alerts, err := s.repo.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, a := range alerts {
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		if len(alerts) < exportBatchSize {
+			return nil
+		}
+		filter.Offset += len(alerts)
+	}
+}
+
+// newFilter converts the query's filter fields, ignoring empty ones
+func newFilter(q *ListAlertsQuery) *repository.AlertFilter {
+	filter := &repository.AlertFilter{
+		From: q.From,
+		To:   q.To,
+	}
+	if q.DeviceID != "" {
+		filter.DeviceID = &q.DeviceID
+	}
+	if q.Severity != "" {
+		filter.Severity = &q.Severity
+	}
+	if q.State != "" {
+		filter.State = &q.State
+	}
+	filter.States = q.States
+	return filter
+}
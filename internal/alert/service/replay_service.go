@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/alert"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/features/metrics"
+)
+
+const (
+	// pollMeasurement holds the poll results workers also publish to the engine
+	pollMeasurement = "device_poll"
+
+	defaultReplayWindow = time.Hour
+	maxReplayWindow     = 24 * time.Hour
+)
+
+// ErrInvalidReplay is returned for replay requests with an invalid window or rule
+var ErrInvalidReplay = errors.New("invalid replay")
+
+// ReplayService evaluates alert rules against stored metrics without
+// notifying or recording anything.
+type ReplayService interface {
+	Replay(ctx context.Context, query *ReplayQuery) (*ReplayResult, error)
+}
+
+// ReplayQuery selects the metrics to replay and the rules to evaluate.
+type ReplayQuery struct {
+	// Window is how far back to replay (default 1h, max 24h)
+	Window time.Duration
+	// DeviceID restricts the replay to one device when set
+	DeviceID string
+	// Rules replaces the engine's rules when non-empty, to try out a change
+	Rules []alert.Rule
+}
+
+// WouldFire is an alert the engine would have raised during the replay window
+type WouldFire struct {
+	RuleID      string    `json:"rule_id"`
+	DeviceID    string    `json:"device_id"`
+	IPAddress   string    `json:"ip_address,omitempty"`
+	MetricName  string    `json:"metric_name"`
+	Severity    string    `json:"severity"`
+	Value       float64   `json:"value"`
+	Threshold   float64   `json:"threshold"`
+	Message     string    `json:"message"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+// ReplayResult lists what would have fired, oldest first.
+type ReplayResult struct {
+	Start            time.Time   `json:"start"`
+	Stop             time.Time   `json:"stop"`
+	Rules            []string    `json:"rules"`
+	MetricsEvaluated int         `json:"metrics_evaluated"`
+	Fired            []WouldFire `json:"fired"`
+
+	// Warnings flag candidate rules that cannot fire, e.g. on an unknown metric
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type replayService struct {
+	querier metrics.MetricQuerier
+	now     func() time.Time
+}
+
+// NewReplayService creates a ReplayService reading poll results from querier
+func NewReplayService(querier metrics.MetricQuerier) ReplayService {
+	return NewReplayServiceForTest(querier, time.Now)
+}
+
+// NewReplayServiceForTest creates a ReplayService with a custom clock
+func NewReplayServiceForTest(querier metrics.MetricQuerier, now func() time.Time) ReplayService {
+	return &replayService{querier: querier, now: now}
+}
+
+func (s *replayService) Replay(ctx context.Context, q *ReplayQuery) (*ReplayResult, error) {
+	window := q.Window
+	if window == 0 {
+		window = defaultReplayWindow
+	}
+	if window < 0 || window > maxReplayWindow {
+		return nil, fmt.Errorf("%w: window must be between 0 and %s", ErrInvalidReplay, maxReplayWindow)
+	}
+
+	rules := q.Rules
+	if len(rules) == 0 {
+		rules = alert.DefaultRules()
+	}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidReplay, err)
+		}
+	}
+
+	stop := s.now()
+	rq := metrics.RangeQuery{
+		Measurement: pollMeasurement,
+		Tags:        map[string]string{},
+		Start:       stop.Add(-window),
+		Stop:        stop,
+	}
+	if q.DeviceID != "" {
+		rq.Tags["device_id"] = q.DeviceID
+	}
+
+	series, err := s.querier.QueryRange(ctx, rq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	result := &ReplayResult{
+		Start: rq.Start,
+		Stop:  rq.Stop,
+		Fired: []WouldFire{},
+	}
+	for _, rule := range rules {
+		result.Rules = append(result.Rules, rule.ID)
+		result.Warnings = append(result.Warnings, rule.Warnings()...)
+	}
+
+	for _, metric := range metricsFromSeries(series) {
+		result.MetricsEvaluated++
+		for _, f := range alert.Evaluate(rules, metric) {
+			result.Fired = append(result.Fired, WouldFire{
+				RuleID:      f.Rule.ID,
+				DeviceID:    metric.DeviceID,
+				IPAddress:   metric.IPAddress,
+				MetricName:  f.Rule.MetricName,
+				Severity:    f.Rule.Severity,
+				Value:       f.Value,
+				Threshold:   f.Rule.Threshold,
+				Message:     f.Message,
+				TriggeredAt: metric.Timestamp,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// metricsFromSeries rebuilds the metrics workers published from the stored
+// series: fields of one poll share a device and a timestamp. The result is
+// ordered by time, then device.
+func metricsFromSeries(series []metrics.Series) []commonModel.Metric {
+	type pollKey struct {
+		deviceID string
+		at       time.Time
+	}
+
+	polls := make(map[pollKey]*commonModel.Metric)
+	for _, s := range series {
+		deviceID := s.Tags["device_id"]
+		for _, p := range s.Points {
+			key := pollKey{deviceID: deviceID, at: p.Time}
+			m, ok := polls[key]
+			if !ok {
+				m = &commonModel.Metric{
+					DeviceID:  deviceID,
+					IPAddress: s.Tags["ip_address"],
+					Timestamp: p.Time,
+					Values:    make(map[string]interface{}),
+				}
+				polls[key] = m
+			}
+			m.Values[s.Field] = p.Value
+		}
+	}
+
+	out := make([]commonModel.Metric, 0, len(polls))
+	for _, m := range polls {
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Timestamp.Equal(out[j].Timestamp) {
+			return out[i].Timestamp.Before(out[j].Timestamp)
+		}
+		return out[i].DeviceID < out[j].DeviceID
+	})
+	return out
+}
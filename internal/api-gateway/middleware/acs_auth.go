@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ACSRealm is the basic auth realm CPEs are challenged with on /cwmp.
+const ACSRealm = "nms-acs"
+
+// ACSAuthMiddleware authenticates CPEs on the TR-069 ACS endpoint. With a
+// password set, requests need HTTP basic auth with username and password;
+// with allowedCIDRs set, they must come from one of those networks. The
+// source is the TCP peer, not X-Forwarded-For, which a CPE could forge.
+// Entries that are neither a CIDR nor an IP are logged and skipped.
+func ACSAuthMiddleware(username, password string, allowedCIDRs []string) gin.HandlerFunc {
+	var allowed []*net.IPNet
+	for _, entry := range allowedCIDRs {
+		network, err := parseNetwork(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid ACS allowed CIDR %q: %v", entry, err)
+			continue
+		}
+		allowed = append(allowed, network)
+	}
+	restrictSource := len(allowedCIDRs) > 0
+	challenge := `Basic realm="` + ACSRealm + `"`
+
+	return func(c *gin.Context) {
+		if restrictSource && !containsIP(allowed, net.ParseIP(c.RemoteIP())) {
+			c.AbortWithStatusJSON(403, gin.H{"error": "Source address not allowed"})
+			return
+		}
+
+		if password != "" {
+			user, pass, ok := c.Request.BasicAuth()
+			if !ok {
+				c.Header("WWW-Authenticate", challenge)
+				c.AbortWithStatusJSON(401, gin.H{"error": "Authorization required"})
+				return
+			}
+			userOK := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+			passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+			if !userOK || !passOK {
+				c.Header("WWW-Authenticate", challenge)
+				c.AbortWithStatusJSON(401, gin.H{"error": "Invalid credentials"})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// parseNetwork accepts a CIDR or a single address.
+func parseNetwork(entry string) (*net.IPNet, error) {
+	entry = strings.TrimSpace(entry)
+	if !strings.Contains(entry, "/") {
+		if ip := net.ParseIP(entry); ip != nil {
+			if v4 := ip.To4(); v4 != nil {
+				return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+			}
+			return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+		}
+	}
+	_, network, err := net.ParseCIDR(entry)
+	return network, err
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yourorg/nms-go/internal/api-gateway/middleware"
+)
+
+const informBody = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:cwmp="urn:dslforum-org:cwmp-1-0"><soap:Body><cwmp:Inform><DeviceId><SerialNumber>ZTEG12345678</SerialNumber></DeviceId></cwmp:Inform></soap:Body></soap:Envelope>`
+
+func setupACSRouter(username, password string, allowed []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/cwmp", middleware.ACSAuthMiddleware(username, password, allowed), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func informRequest(remoteAddr string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/cwmp", strings.NewReader(informBody))
+	req.Header.Set("Content-Type", "text/xml")
+	req.RemoteAddr = remoteAddr
+	return req
+}
+
+func TestACSAuthMiddleware_UnauthenticatedInform(t *testing.T) {
+	r := setupACSRouter("acs", "s3cret", nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, informRequest("10.0.0.5:7547"))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="nms-acs"`, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestACSAuthMiddleware_WrongPassword(t *testing.T) {
+	r := setupACSRouter("acs", "s3cret", nil)
+
+	req := informRequest("10.0.0.5:7547")
+	req.SetBasicAuth("acs", "guess")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestACSAuthMiddleware_ValidCredentials(t *testing.T) {
+	r := setupACSRouter("acs", "s3cret", nil)
+
+	req := informRequest("10.0.0.5:7547")
+	req.SetBasicAuth("acs", "s3cret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestACSAuthMiddleware_SourceAllowlist(t *testing.T) {
+	r := setupACSRouter("", "", []string{"10.0.0.0/24", "192.0.2.7", "not-a-cidr"})
+
+	for addr, want := range map[string]int{
+		"10.0.0.5:7547":  http.StatusOK,
+		"192.0.2.7:7547": http.StatusOK,
+		"10.0.1.5:7547":  http.StatusForbidden,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, informRequest(addr))
+		assert.Equal(t, want, w.Code, addr)
+	}
+
+	// X-Forwarded-For is not trusted
+	req := informRequest("10.0.1.5:7547")
+	req.Header.Set("X-Forwarded-For", "10.0.0.5")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestACSAuthMiddleware_NoValidCIDRsDeniesAll(t *testing.T) {
+	r := setupACSRouter("", "", []string{"bogus"})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, informRequest("10.0.0.5:7547"))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yourorg/nms-go/internal/api-gateway/middleware"
+)
+
+func setupLimitedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.BodyLimitMiddleware(16, map[string]int64{"/upload": 64}))
+
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%d", len(body))
+	}
+	r.POST("/devices", echo)
+	r.POST("/upload", echo)
+	return r
+}
+
+func TestBodyLimit_OversizedBodyRejected(t *testing.T) {
+	r := setupLimitedRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/devices", strings.NewReader(strings.Repeat("x", 17)))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestBodyLimit_ChunkedOversizedBodyRejected(t *testing.T) {
+	r := setupLimitedRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/devices", strings.NewReader(strings.Repeat("x", 100)))
+	req.ContentLength = -1 // unknown length, as with chunked encoding
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestBodyLimit_WithinLimitPassesBody(t *testing.T) {
+	r := setupLimitedRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/devices", bytes.NewReader([]byte(strings.Repeat("x", 16))))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "16", w.Body.String())
+}
+
+func TestBodyLimit_ExemptRouteUsesHigherLimit(t *testing.T) {
+	r := setupLimitedRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 64)))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "64", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 65)))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/signature"
+)
+
+// DefaultSignatureMaxAge is how far a request timestamp may drift from the
+// server clock before the request is rejected as expired or replayed.
+const DefaultSignatureMaxAge = 5 * time.Minute
+
+// SignatureMiddleware verifies HMAC-SHA256 signed requests from integration
+// partners. Clients send X-NMS-Timestamp (Unix seconds) and
+// X-NMS-Signature ("sha256=" + hex HMAC of "<timestamp>.<body>").
+func SignatureMiddleware(secret string, maxAge time.Duration) gin.HandlerFunc {
+	if maxAge <= 0 {
+		maxAge = DefaultSignatureMaxAge
+	}
+	key := []byte(secret)
+
+	return func(c *gin.Context) {
+		ts := c.GetHeader(signature.HeaderTimestamp)
+		sig := c.GetHeader(signature.HeaderSignature)
+		if ts == "" || sig == "" {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Signature headers required"})
+			return
+		}
+
+		signedAt, err := signature.ParseTimestamp(ts)
+		if err != nil {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid signature timestamp"})
+			return
+		}
+
+		age := time.Since(signedAt)
+		if age > maxAge || age < -maxAge {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Signature expired"})
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(400, gin.H{"error": "Failed to read request body"})
+				return
+			}
+		}
+		// Restore the body for downstream handlers
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !signature.Verify(key, ts, body, sig) {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid signature"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,106 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yourorg/nms-go/internal/api-gateway/middleware"
+	"github.com/yourorg/nms-go/internal/common/signature"
+)
+
+const testSecret = "shared-secret"
+
+func setupSignedRouter(received *[]byte) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.SignatureMiddleware(testSecret, time.Minute))
+	r.POST("/inventory/sync", func(c *gin.Context) {
+		*received, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func signedRequest(body []byte, signedAt time.Time, secret string) *http.Request {
+	ts := signature.Timestamp(signedAt)
+	req, _ := http.NewRequest(http.MethodPost, "/inventory/sync", bytes.NewReader(body))
+	req.Header.Set(signature.HeaderTimestamp, ts)
+	req.Header.Set(signature.HeaderSignature, signature.Sign([]byte(secret), ts, body))
+	return req
+}
+
+func TestSignatureMiddleware_ValidSignature(t *testing.T) {
+	var received []byte
+	r := setupSignedRouter(&received)
+	body := []byte(`{"devices":[]}`)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest(body, time.Now(), testSecret))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, received, "handler must still be able to read the body")
+}
+
+func TestSignatureMiddleware_TamperedBody(t *testing.T) {
+	var received []byte
+	r := setupSignedRouter(&received)
+
+	req := signedRequest([]byte(`{"devices":[]}`), time.Now(), testSecret)
+	req.Body = io.NopCloser(bytes.NewReader([]byte(`{"devices":[{"ip":"10.0.0.66"}]}`)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, received)
+}
+
+func TestSignatureMiddleware_WrongSecret(t *testing.T) {
+	var received []byte
+	r := setupSignedRouter(&received)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest([]byte(`{}`), time.Now(), "other-secret"))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSignatureMiddleware_ReplayedOldTimestamp(t *testing.T) {
+	var received []byte
+	r := setupSignedRouter(&received)
+
+	// Correctly signed, but captured ten minutes ago.
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest([]byte(`{}`), time.Now().Add(-10*time.Minute), testSecret))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "expired")
+	assert.Nil(t, received)
+}
+
+func TestSignatureMiddleware_FutureTimestamp(t *testing.T) {
+	var received []byte
+	r := setupSignedRouter(&received)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest([]byte(`{}`), time.Now().Add(10*time.Minute), testSecret))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSignatureMiddleware_MissingHeaders(t *testing.T) {
+	var received []byte
+	r := setupSignedRouter(&received)
+
+	req, _ := http.NewRequest(http.MethodPost, "/inventory/sync", bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -2,18 +2,29 @@ package apigateway
 
 import (
 	"github.com/gin-gonic/gin"
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	alerthandler "github.com/yourorg/nms-go/internal/alert/handler"
+	alertrepo "github.com/yourorg/nms-go/internal/alert/repository"
+	alertservice "github.com/yourorg/nms-go/internal/alert/service"
+	"github.com/yourorg/nms-go/internal/api-gateway/middleware"
 	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/infra"
 	"github.com/yourorg/nms-go/internal/config_mgt"
 	"github.com/yourorg/nms-go/internal/device/handler"
 	"github.com/yourorg/nms-go/internal/device/repository"
 	"github.com/yourorg/nms-go/internal/device/service"
+	"github.com/yourorg/nms-go/internal/features/admin"
+	"github.com/yourorg/nms-go/internal/features/devicedetail"
 	"github.com/yourorg/nms-go/internal/features/execution"
+	"github.com/yourorg/nms-go/internal/features/metrics"
 	"github.com/yourorg/nms-go/internal/features/monitoring"
 	"github.com/yourorg/nms-go/internal/features/olt"
+	"github.com/yourorg/nms-go/internal/features/tr069"
 	"gorm.io/gorm"
 )
 
-func NewRouter(cfg *config.Config, db *gorm.DB, monitoringHandler *monitoring.Handler) *gin.Engine {
+func NewRouter(cfg *config.Config, db *gorm.DB, monitoringHandler *monitoring.Handler, oltService olt.OLTService) *gin.Engine {
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -22,15 +33,45 @@ func NewRouter(cfg *config.Config, db *gorm.DB, monitoringHandler *monitoring.Ha
 	r.Use(gin.Recovery())
 	r.Use(gin.Logger())
 
+	// Bound request bodies to avoid reading arbitrarily large payloads into memory.
+	// Bulk upload routes get a higher limit.
+	r.Use(middleware.BodyLimitMiddleware(cfg.Server.MaxBodyBytes, map[string]int64{
+		"/api/v1/inventory/sync": cfg.Server.UploadMaxBodyBytes,
+	}))
+
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
+	// Readiness: whether Postgres, Redis, NATS and InfluxDB are reachable
+	r.GET("/health/ready", infra.ReadyHandler(infra.Checks(cfg), infra.DefaultTimeout))
+
+	// Prometheus metrics (connect durations, success rates, ...)
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Initialize dependencies
 	deviceRepo := repository.NewDeviceRepository(db)
-	deviceService := service.NewDeviceService(deviceRepo)
+	deviceService := service.NewDeviceService(deviceRepo, repository.NewTagRuleRepository(db))
 	deviceHandler := handler.NewDeviceHandler(deviceService)
+	credentialsService := service.NewCredentialsService(repository.NewCredentialsRepository(db), deviceRepo)
+	credentialsHandler := handler.NewCredentialsHandler(credentialsService)
+	groupHandler := handler.NewGroupHandler(service.NewGroupService(repository.NewGroupRepository(db), deviceRepo))
+
+	// TR-069 ACS endpoint for CPEs (ONTs). Not under /api/v1: CPEs are
+	// configured with a plain ACS URL and cannot sign requests. They
+	// authenticate with the shared ACS credentials and/or connect from an
+	// allowed network; without either the endpoint is not mounted.
+	tr069Store := tr069.NewStore(db)
+	tr069Queue := tr069.NewTaskQueue()
+	if cfg.TR069.Password != "" || len(cfg.TR069.AllowedCIDRs) > 0 {
+		acs := tr069.NewACS(tr069Store, tr069Queue, deviceRepo)
+		acsAuth := middleware.ACSAuthMiddleware(cfg.TR069.Username, cfg.TR069.Password, cfg.TR069.AllowedCIDRs)
+		r.POST("/cwmp", acsAuth, acs.ServeCWMP)
+	}
+
+	// Polls devices directly instead of reading stored metrics; concurrent
+	// viewers of one device share a poll.
+	livePoller := monitoring.NewCachedLivePoller(monitoring.NewLivePoller(), cfg.Live.PollCacheTTL)
 
 	// API v1 group
 	v1 := r.Group("/api/v1")
@@ -38,13 +79,59 @@ func NewRouter(cfg *config.Config, db *gorm.DB, monitoringHandler *monitoring.Ha
 		devices := v1.Group("/devices")
 		{
 			devices.GET("", deviceHandler.ListDevices)
+			devices.GET("/recent", deviceHandler.ListRecentDevices)
 			devices.POST("", deviceHandler.RegisterDevice)
 			devices.GET("/:id", deviceHandler.GetDevice)
+			devices.POST("/bulk-update", deviceHandler.BulkUpdate)
+			devices.PUT("/:id/credentials", credentialsHandler.AttachToDevice)
+
+			liveHandler := monitoring.NewLiveHandler(deviceService, livePoller)
+			devices.GET("/:id/metrics/live", liveHandler.GetLiveMetrics)
+			devices.GET("/:id/metrics/ws", liveHandler.StreamLiveMetrics)
+		}
+
+		// Device credential sets. Secrets are write-only and stored encrypted.
+		credentials := v1.Group("/credentials")
+		{
+			credentials.GET("", credentialsHandler.ListCredentials)
+			credentials.POST("", credentialsHandler.CreateCredentials)
+			credentials.POST("/test", credentialsHandler.TestCredentials)
+			credentials.GET("/:id", credentialsHandler.GetCredentials)
+			credentials.PUT("/:id", credentialsHandler.UpdateCredentials)
+			credentials.DELETE("/:id", credentialsHandler.DeleteCredentials)
+		}
+
+		// Device group hierarchy
+		groups := v1.Group("/groups")
+		{
+			groups.GET("", groupHandler.ListGroups)
+			groups.POST("", groupHandler.CreateGroup)
+			groups.GET("/tree", groupHandler.GetTree)
+			groups.GET("/:id", groupHandler.GetGroup)
+			groups.PUT("/:id", groupHandler.UpdateGroup)
+			groups.DELETE("/:id", groupHandler.DeleteGroup)
+			groups.GET("/:id/devices", groupHandler.ListGroupDevices)
+			groups.POST("/:id/devices", groupHandler.MoveDevices)
+		}
+
+		// Alert history
+		alertRepo := alertrepo.NewAlertRepository(db)
+		alertService := alertservice.NewAlertService(alertRepo)
+		alertHandler := alerthandler.NewAlertHandler(alertService)
+
+		alerts := v1.Group("/alerts")
+		{
+			alerts.GET("", alertHandler.ListAlerts)
+			alerts.GET("/export", alertHandler.ExportAlerts)
+			alerts.GET("/:id", alertHandler.GetAlert)
+			alerts.POST("/:id/acknowledge", alertHandler.AcknowledgeAlert)
+			alerts.POST("/:id/resolve", alertHandler.ResolveAlert)
 		}
 
 		// Config Management routes
 		sshAdapter := config_mgt.NewSSHAdapter()
-		configService := config_mgt.NewConfigService(deviceService, sshAdapter)
+		telnetAdapter := config_mgt.NewTelnetAdapter()
+		configService := config_mgt.NewConfigService(deviceService, sshAdapter, telnetAdapter)
 		configHandler := config_mgt.NewConfigHandler(configService)
 
 		configGroup := v1.Group("/config")
@@ -58,18 +145,66 @@ func NewRouter(cfg *config.Config, db *gorm.DB, monitoringHandler *monitoring.Ha
 		v1.POST("/realtime/execute", execHandler.ExecuteCommand)
 		v1.POST("/realtime/stats", execHandler.GetStats)
 
+		// Server-to-server integration routes (openaccess). When an HMAC secret
+		// is configured, requests must carry a valid, fresh signature.
+		integration := v1.Group("")
+		if cfg.Integration.HMACSecret != "" {
+			integration.Use(middleware.SignatureMiddleware(cfg.Integration.HMACSecret, cfg.Integration.SignatureMaxAge))
+		}
+
 		// Monitoring feature (Background)
-		v1.POST("/inventory/sync", monitoringHandler.SyncInventory)
+		integration.POST("/inventory/sync", monitoringHandler.SyncInventory)
+		integration.GET("/inventory/summary", monitoringHandler.GetSummary)
 
 		// OLT feature — exposes ZTE C320 SNMP data to openaccess and nms-rekayasa.
 		// openaccess is the single source of truth for device inventory;
 		// go-nms connects directly to OLTs using IP + SNMP credentials from the request body.
 		// Endpoints:
 		//   POST /api/v1/olt/system     — system metrics (CPU, memory, uptime, temperature)
+		//   POST /api/v1/olt/cards      — installed cards per slot
 		//   POST /api/v1/olt/pon-ports  — PON port status and optical power
 		//   POST /api/v1/olt/onts       — ONT list (optional pon_port filter in body)
-		oltService := olt.NewOLTService()
-		olt.RegisterRoutes(v1, oltService)
+		//   POST /api/v1/olt/optical-report — every ONT with its optical class
+		olt.RegisterRoutes(integration, oltService)
+
+		// Metrics read API — backed by a MetricQuerier so the handlers do not depend on Flux.
+		//   GET /api/v1/metrics/latest — most recent value per series
+		//   GET /api/v1/metrics/range  — values over time, optionally aggregated
+		//   GET /api/v1/metrics/query  — selected fields over time for dashboards
+		//   GET /api/v1/devices/:id/metrics — stored metrics of one device
+		//   GET /api/v1/groups/:id/metrics — health rollup of a device group
+		influxClient := influxdb2.NewClient(cfg.Influx.URL, cfg.Influx.Token)
+		metricsQuerier := metrics.NewInfluxQuerier(influxClient, cfg.Influx.Org, cfg.Influx.Bucket)
+		metrics.RegisterRoutes(v1, metricsQuerier, deviceRepo)
+
+		// Everything the device page shows, in one call
+		//   GET /api/v1/devices/:id/detail — device, latest metrics and active alerts
+		devicedetail.RegisterRoutes(v1, deviceService, metricsQuerier, alertService)
+
+		// Dry-run the alert rules against stored poll results
+		//   POST /api/v1/alerts/replay — alerts that would have fired
+		replayHandler := alerthandler.NewReplayHandler(alertservice.NewReplayService(metricsQuerier))
+		alerts.POST("/replay", replayHandler.Replay)
+
+		// Check a single rule against a sample metric
+		//   POST /api/v1/alerts/rules/test — whether the rule would fire
+		ruleHandler := alerthandler.NewRuleHandler()
+		alerts.POST("/rules/test", ruleHandler.TestRule)
+
+		// TR-069 management: inspect CPE parameters and queue RPCs for the next session
+		tr069.RegisterRoutes(v1, tr069Store, tr069Queue)
+
+		// Operator endpoints, only mounted when a JWT secret is configured.
+		//   POST /api/v1/admin/cache/invalidate — force caches to refresh
+		if cfg.Admin.JWTSecret != "" {
+			adminGroup := v1.Group("")
+			adminGroup.Use(middleware.AuthMiddleware(cfg.Admin.JWTSecret))
+			admin.RegisterRoutes(adminGroup, map[string]admin.CacheInvalidator{
+				"olt_vendor":   oltService,
+				"live_poll":    livePoller,
+				"reachability": monitoringHandler.Reachability(),
+			})
+		}
 	}
 
 	return r
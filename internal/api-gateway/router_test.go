@@ -5,15 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/yourorg/nms-go/internal/config_mgt"
 	"github.com/yourorg/nms-go/internal/device/handler"
 	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
 	"github.com/yourorg/nms-go/internal/device/service"
 )
 
@@ -22,6 +26,9 @@ type MockDeviceService struct {
 	GetDeviceFunc      func(ctx context.Context, id string) (*model.Device, error)
 	RegisterDeviceFunc func(ctx context.Context, req *service.RegisterDeviceRequest) (*model.Device, error)
 	ListDevicesFunc    func(ctx context.Context, page, pageSize int) ([]*model.Device, int64, error)
+	ListRecentFunc     func(ctx context.Context, since time.Time, page, pageSize int) ([]*model.Device, int64, error)
+	ListAfterFunc      func(ctx context.Context, after string, pageSize int) ([]*model.Device, string, error)
+	BulkUpdateFunc     func(ctx context.Context, req *service.BulkUpdateRequest) (*service.BulkUpdateResponse, error)
 }
 
 func (m *MockDeviceService) GetDevice(ctx context.Context, id string) (*model.Device, error) {
@@ -45,6 +52,27 @@ func (m *MockDeviceService) ListDevices(ctx context.Context, page, pageSize int)
 	return nil, 0, nil
 }
 
+func (m *MockDeviceService) ListRecentDevices(ctx context.Context, since time.Time, page, pageSize int) ([]*model.Device, int64, error) {
+	if m.ListRecentFunc != nil {
+		return m.ListRecentFunc(ctx, since, page, pageSize)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockDeviceService) ListDevicesAfter(ctx context.Context, after string, pageSize int) ([]*model.Device, string, error) {
+	if m.ListAfterFunc != nil {
+		return m.ListAfterFunc(ctx, after, pageSize)
+	}
+	return nil, "", nil
+}
+
+func (m *MockDeviceService) BulkUpdate(ctx context.Context, req *service.BulkUpdateRequest) (*service.BulkUpdateResponse, error) {
+	if m.BulkUpdateFunc != nil {
+		return m.BulkUpdateFunc(ctx, req)
+	}
+	return &service.BulkUpdateResponse{}, nil
+}
+
 // MockConfigService
 type MockConfigService struct {
 	ExecuteCommandFunc func(ctx context.Context, deviceID, command string) (interface{}, error)
@@ -74,8 +102,10 @@ func setupRouter(deviceService service.DeviceService, configService config_mgt.C
 		devices := v1.Group("/devices")
 		{
 			devices.GET("", deviceHandler.ListDevices)
+			devices.GET("/recent", deviceHandler.ListRecentDevices)
 			devices.POST("", deviceHandler.RegisterDevice)
 			devices.GET("/:id", deviceHandler.GetDevice)
+			devices.POST("/bulk-update", deviceHandler.BulkUpdate)
 		}
 
 		configGroup := v1.Group("/config")
@@ -168,6 +198,195 @@ func TestGetDevice(t *testing.T) {
 	assert.Equal(t, 404, w2.Code)
 }
 
+func TestListDevices_Pagination(t *testing.T) {
+	var gotPage, gotPageSize int
+	called := false
+	mockService := &MockDeviceService{
+		ListDevicesFunc: func(ctx context.Context, page, pageSize int) ([]*model.Device, int64, error) {
+			called = true
+			gotPage, gotPageSize = page, pageSize
+			return []*model.Device{}, 0, nil
+		},
+	}
+	router := setupRouter(mockService, nil)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		page         int
+		pageSize     int
+	}{
+		{"Defaults", "", 200, 1, 20},
+		{"Explicit", "?page=3&page_size=50", 200, 3, 50},
+		{"Page size clamped", "?page_size=500", 200, 1, 100},
+		{"Non-numeric page size", "?page_size=abc", 400, 0, 0},
+		{"Negative page size", "?page_size=-5", 400, 0, 0},
+		{"Zero page", "?page=0", 400, 0, 0},
+		{"Non-numeric page", "?page=two", 400, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			req, _ := http.NewRequest("GET", "/api/v1/devices"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+			if tt.expectedCode != 200 {
+				assert.False(t, called, "invalid pagination is not passed on")
+				assert.Contains(t, w.Body.String(), "must be a positive integer")
+				return
+			}
+
+			assert.Equal(t, tt.page, gotPage)
+			assert.Equal(t, tt.pageSize, gotPageSize)
+
+			var body map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, float64(tt.pageSize), body["page_size"], "the effective page size is reported")
+		})
+	}
+}
+
+func TestListDevices_Cursor(t *testing.T) {
+	var gotAfter string
+	var gotPageSize int
+	mockService := &MockDeviceService{
+		ListDevicesFunc: func(ctx context.Context, page, pageSize int) ([]*model.Device, int64, error) {
+			t.Error("offset paging must not be used with after")
+			return nil, 0, nil
+		},
+		ListAfterFunc: func(ctx context.Context, after string, pageSize int) ([]*model.Device, string, error) {
+			gotAfter, gotPageSize = after, pageSize
+			switch after {
+			case "bad":
+				return nil, "", fmt.Errorf("%w: %q", service.ErrInvalidCursor, after)
+			case "":
+				return []*model.Device{{ID: "d1"}}, "c1", nil
+			default:
+				return []*model.Device{{ID: "d2"}}, "", nil
+			}
+		},
+	}
+	router := setupRouter(mockService, nil)
+
+	get := func(query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/api/v1/devices"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var body map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	w, body := get("?after=&page_size=500")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "", gotAfter)
+	assert.Equal(t, 100, gotPageSize)
+	assert.Equal(t, "c1", body["next_cursor"])
+	assert.NotContains(t, body, "total")
+
+	w, body = get("?after=c1")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "c1", gotAfter)
+	assert.Nil(t, body["next_cursor"])
+	assert.Contains(t, body, "next_cursor")
+
+	w, _ = get("?after=bad")
+	assert.Equal(t, 400, w.Code)
+	w, _ = get("?after=c1&page=2")
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestListRecentDevices(t *testing.T) {
+	var gotSince time.Time
+	var gotPageSize int
+	mockService := &MockDeviceService{
+		ListRecentFunc: func(ctx context.Context, since time.Time, page, pageSize int) ([]*model.Device, int64, error) {
+			gotSince, gotPageSize = since, pageSize
+			return []*model.Device{{ID: "dev-2"}, {ID: "dev-1"}}, 2, nil
+		},
+	}
+	router := setupRouter(mockService, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/devices/recent?since=2026-03-01T12:00:00%2B07:00&page_size=10", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code, w.Body.String())
+	assert.True(t, gotSince.Equal(time.Date(2026, 3, 1, 5, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 10, gotPageSize)
+
+	var body struct {
+		Data  []model.Device `json:"data"`
+		Total int64          `json:"total"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(2), body.Total)
+	if assert.Len(t, body.Data, 2) {
+		assert.Equal(t, "dev-2", body.Data[0].ID)
+	}
+
+	for name, query := range map[string]string{
+		"Missing since":    "",
+		"Malformed since":  "?since=yesterday",
+		"Invalid pagesize": "?since=2026-03-01T12:00:00Z&page_size=-1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/devices/recent"+query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, 400, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestBulkUpdateDevices(t *testing.T) {
+	mockService := &MockDeviceService{
+		BulkUpdateFunc: func(ctx context.Context, req *service.BulkUpdateRequest) (*service.BulkUpdateResponse, error) {
+			if len(req.IDs) == 0 {
+				return nil, fmt.Errorf("%w: ids or filter is required", service.ErrInvalidBulkUpdate)
+			}
+			return &service.BulkUpdateResponse{
+				Updated: 1,
+				Failed:  1,
+				Results: []repository.BulkUpdateResult{
+					{ID: req.IDs[0], Success: true},
+					{ID: req.IDs[1], Error: "device not found"},
+				},
+			}, nil
+		},
+	}
+
+	router := setupRouter(mockService, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"ids":    []string{"dev-1", "missing"},
+		"fields": map[string]interface{}{"enabled": false},
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/devices/bulk-update", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var resp service.BulkUpdateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Updated)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Equal(t, "device not found", resp.Results[1].Error)
+
+	// Invalid selection
+	body2, _ := json.Marshal(map[string]interface{}{"fields": map[string]interface{}{"enabled": false}})
+	req2, _ := http.NewRequest("POST", "/api/v1/devices/bulk-update", bytes.NewBuffer(body2))
+	req2.Header.Set("Content-Type", "application/json")
+	w2 := httptest.NewRecorder()
+	router.ServeHTTP(w2, req2)
+	assert.Equal(t, 400, w2.Code)
+}
+
 func TestExecuteCommand(t *testing.T) {
 	mockConfig := &MockConfigService{
 		ExecuteCommandFunc: func(ctx context.Context, deviceID, command string) (interface{}, error) {
@@ -0,0 +1,178 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// LeaderLock is a lock that expires unless its holder renews it, so a
+// crashed holder frees it after its TTL.
+type LeaderLock interface {
+	// Acquire takes the lock for id if it is free, or renews it if id
+	// already holds it, and reports whether id holds it for the next ttl.
+	Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error)
+
+	// Release frees the lock if id holds it.
+	Release(ctx context.Context, id string) error
+}
+
+// acquireScript renews the lock when ARGV[1] holds it and takes it when it
+// is free, in one step so a lock that expires in between is not lost.
+var acquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0`)
+
+// releaseScript deletes the lock only when ARGV[1] still holds it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisLock is a LeaderLock stored under a Redis key whose value is the
+// holder's ID.
+type RedisLock struct {
+	client redis.Scripter
+	key    string
+}
+
+func NewRedisLock(client redis.Scripter, key string) *RedisLock {
+	return &RedisLock{client: client, key: key}
+}
+
+func (l *RedisLock) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	n, err := acquireScript.Run(ctx, l.client, []string{l.key}, id, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire leader lock %s: %w", l.key, err)
+	}
+	return n == 1, nil
+}
+
+func (l *RedisLock) Release(ctx context.Context, id string) error {
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, id).Err(); err != nil {
+		return fmt.Errorf("failed to release leader lock %s: %w", l.key, err)
+	}
+	return nil
+}
+
+// Elector campaigns for a LeaderLock and keeps renewing it while it holds
+// it. Leadership is only reported until the lock could have expired since
+// its last renewal, so a leader cut off from Redis stops acting as one
+// before another instance can take over.
+type Elector struct {
+	lock LeaderLock
+	id   string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu          sync.Mutex
+	leaderUntil time.Time
+	stopped     bool
+
+	stopChan chan struct{}
+}
+
+func NewElector(lock LeaderLock, id string, ttl time.Duration) *Elector {
+	return NewElectorForTest(lock, id, ttl, time.Now)
+}
+
+// NewElectorForTest creates an Elector that reads the time from now.
+func NewElectorForTest(lock LeaderLock, id string, ttl time.Duration, now func() time.Time) *Elector {
+	return &Elector{
+		lock:     lock,
+		id:       id,
+		ttl:      ttl,
+		now:      now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start campaigns every third of the TTL, so a leader renews its lock
+// twice before it would expire, until Stop is called.
+func (e *Elector) Start() {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	log.Printf("Leader election started as %s", e.id)
+	e.RunOnce(context.Background())
+
+	for {
+		select {
+		case <-ticker.C:
+			e.RunOnce(context.Background())
+		case <-e.stopChan:
+			return
+		}
+	}
+}
+
+// Stop ends the campaign and releases the lock, so another instance takes
+// over without waiting for it to expire.
+func (e *Elector) Stop() {
+	close(e.stopChan)
+
+	wasLeader := e.IsLeader()
+	e.mu.Lock()
+	e.leaderUntil = time.Time{}
+	e.stopped = true
+	e.mu.Unlock()
+
+	if wasLeader {
+		ctx, cancel := context.WithTimeout(context.Background(), e.ttl)
+		defer cancel()
+		if err := e.lock.Release(ctx, e.id); err != nil {
+			log.Printf("Error releasing leader lock: %v", err)
+		}
+	}
+}
+
+// RunOnce acquires or renews the lock. A failed attempt keeps the current
+// leadership until it runs out.
+func (e *Elector) RunOnce(ctx context.Context) {
+	start := e.now()
+	ok, err := e.lock.Acquire(ctx, e.id, e.ttl)
+	if err != nil {
+		log.Printf("Error campaigning for leader: %v", err)
+		return
+	}
+
+	wasLeader := e.IsLeader()
+
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return
+	}
+	if ok {
+		// The lock expires ttl after Redis handled the request, which is
+		// no earlier than when it was sent.
+		e.leaderUntil = start.Add(e.ttl)
+	} else {
+		e.leaderUntil = time.Time{}
+	}
+	e.mu.Unlock()
+
+	switch {
+	case ok && !wasLeader:
+		log.Printf("Elected leader as %s", e.id)
+	case !ok && wasLeader:
+		log.Printf("Lost leadership as %s", e.id)
+	}
+}
+
+// IsLeader reports whether this instance holds the lock.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.now().Before(e.leaderUntil)
+}
@@ -0,0 +1,167 @@
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/collector"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository/repositorytest"
+)
+
+// fakeLock is an in-memory LeaderLock with an expiry, like the Redis one.
+type fakeLock struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	holder   string
+	expires  time.Time
+	err      error
+	released []string
+}
+
+func (l *fakeLock) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return false, l.err
+	}
+	if l.holder == id || l.holder == "" || !l.now().Before(l.expires) {
+		l.holder, l.expires = id, l.now().Add(ttl)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (l *fakeLock) Release(ctx context.Context, id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = append(l.released, id)
+	if l.holder == id {
+		l.holder = ""
+	}
+	return nil
+}
+
+func TestElector_FailsOverWhenLeaderStopsRenewing(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	lock := &fakeLock{now: clock}
+	a := collector.NewElectorForTest(lock, "collector-a", 15*time.Second, clock)
+	b := collector.NewElectorForTest(lock, "collector-b", 15*time.Second, clock)
+	ctx := context.Background()
+
+	a.RunOnce(ctx)
+	b.RunOnce(ctx)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+
+	// a renews in time and stays leader
+	now = now.Add(5 * time.Second)
+	a.RunOnce(ctx)
+	b.RunOnce(ctx)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+
+	// a dies; its lock expires and b takes over
+	now = now.Add(15 * time.Second)
+	assert.False(t, a.IsLeader(), "leadership runs out with the lock")
+	b.RunOnce(ctx)
+	assert.True(t, b.IsLeader())
+
+	a.RunOnce(ctx)
+	assert.False(t, a.IsLeader())
+}
+
+func TestElector_KeepsLeadershipUntilExpiryOnErrors(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	lock := &fakeLock{now: clock}
+	e := collector.NewElectorForTest(lock, "collector-a", 15*time.Second, clock)
+	ctx := context.Background()
+
+	e.RunOnce(ctx)
+	lock.err = errors.New("dial tcp: connection refused")
+
+	now = now.Add(10 * time.Second)
+	e.RunOnce(ctx)
+	assert.True(t, e.IsLeader(), "a failed renewal keeps the lease it has")
+
+	now = now.Add(5 * time.Second)
+	assert.False(t, e.IsLeader(), "but not past the point another instance may hold the lock")
+}
+
+func TestElector_StopReleasesLock(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	lock := &fakeLock{now: clock}
+	a := collector.NewElectorForTest(lock, "collector-a", 15*time.Second, clock)
+	b := collector.NewElectorForTest(lock, "collector-b", 15*time.Second, clock)
+	ctx := context.Background()
+
+	a.RunOnce(ctx)
+	a.Stop()
+	assert.False(t, a.IsLeader())
+	assert.Equal(t, []string{"collector-a"}, lock.released)
+
+	b.RunOnce(ctx)
+	assert.True(t, b.IsLeader(), "b takes over without waiting for the TTL")
+}
+
+func TestScheduler_OnlyLeaderDispatches(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{ID: "router-1", Name: "router-1", IPAddress: "10.0.1.1", DeviceType: model.DeviceTypeRouter, Protocol: model.ProtocolMikrotikAPI, Enabled: true, PollingInterval: 60},
+	)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	lock := &fakeLock{now: clock}
+	a := collector.NewElectorForTest(lock, "collector-a", 15*time.Second, clock)
+	b := collector.NewElectorForTest(lock, "collector-b", 15*time.Second, clock)
+	ncA, ncB := &fakeConn{}, &fakeConn{}
+	schedA := collector.NewSchedulerForTest(repo, ncA, a, clock, func() float64 { return 0.5 })
+	schedB := collector.NewSchedulerForTest(repo, ncB, b, clock, func() float64 { return 0.5 })
+	ctx := context.Background()
+
+	a.RunOnce(ctx)
+	b.RunOnce(ctx)
+	schedA.RunOnce(ctx)
+	schedB.RunOnce(ctx)
+	assert.Equal(t, []string{"router-1"}, ncA.devices())
+	assert.Empty(t, ncB.devices(), "followers do not publish")
+
+	// a dies; b picks up the schedule a left in the database
+	now = now.Add(time.Minute)
+	b.RunOnce(ctx)
+	require.True(t, b.IsLeader())
+	schedB.RunOnce(ctx)
+	assert.Equal(t, []string{"router-1"}, ncB.devices())
+}
+
+// countdownLeader reports leadership for its first n checks only.
+type countdownLeader struct{ n int }
+
+func (l *countdownLeader) IsLeader() bool {
+	l.n--
+	return l.n >= 0
+}
+
+func TestScheduler_StopsWhenLeadershipRunsOutMidBatch(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{ID: "router-1", Name: "router-1", IPAddress: "10.0.1.1", DeviceType: model.DeviceTypeRouter, Protocol: model.ProtocolMikrotikAPI, Enabled: true, PollingInterval: 60},
+		&model.Device{ID: "router-2", Name: "router-2", IPAddress: "10.0.1.2", DeviceType: model.DeviceTypeRouter, Protocol: model.ProtocolMikrotikAPI, Enabled: true, PollingInterval: 60},
+	)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	nc := &fakeConn{}
+	sched := collector.NewSchedulerForTest(repo, nc, &countdownLeader{n: 2}, func() time.Time { return now }, func() float64 { return 0.5 })
+
+	sched.RunOnce(context.Background())
+
+	assert.Len(t, nc.devices(), 1, "the second device is left for the next leader")
+	due, err := repo.ListDueForPolling(context.Background(), now, 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+}
@@ -4,29 +4,74 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"math/rand"
 	"time"
 
-	"github.com/nats-io/nats.go"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	commonModel "github.com/yourorg/nms-go/internal/common/model"
-	"github.com/yourorg/nms-go/internal/device/service"
+	"github.com/yourorg/nms-go/internal/common/queue"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
 )
 
+const (
+	// defaultPollInterval applies to devices registered without a polling
+	// interval.
+	defaultPollInterval = 300 * time.Second
+
+	// pollJitter is the fraction of its interval a device's next poll is
+	// moved by at random, so devices registered or polled together drift
+	// apart instead of all falling due on the same tick.
+	pollJitter = 0.1
+
+	// pollBatchSize bounds the devices dispatched per tick; the rest are
+	// still due on the next one, most overdue first.
+	pollBatchSize = 1000
+)
+
+// Leadership reports whether this instance is the one that schedules polls.
+// Elector implements it.
+type Leadership interface {
+	IsLeader() bool
+}
+
+// Scheduler publishes a poll task for each enabled device when its
+// next_poll_at is due, then schedules its next poll one polling interval
+// later. With several collector replicas, only the leader dispatches; the
+// schedule lives in the database, so a new leader carries on where the
+// last one stopped.
 type Scheduler struct {
-	deviceService service.DeviceService
-	natsConn      *nats.Conn
-	stopChan      chan struct{}
+	repo     repository.DeviceRepository
+	natsConn queue.Conn
+	leader   Leadership
+	now      func() time.Time
+	rand     func() float64
+
+	stopChan chan struct{}
+}
+
+// NewScheduler creates a Scheduler. leader may be nil for a single
+// collector instance, which always dispatches.
+func NewScheduler(repo repository.DeviceRepository, nc queue.Conn, leader Leadership) *Scheduler {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return NewSchedulerForTest(repo, nc, leader, time.Now, rng.Float64)
 }
 
-func NewScheduler(ds service.DeviceService, nc *nats.Conn) *Scheduler {
+// NewSchedulerForTest creates a Scheduler that reads the time from now and
+// draws the jitter from rand, which returns values in [0, 1).
+func NewSchedulerForTest(repo repository.DeviceRepository, nc queue.Conn, leader Leadership, now func() time.Time, rand func() float64) *Scheduler {
 	return &Scheduler{
-		deviceService: ds,
-		natsConn:      nc,
-		stopChan:      make(chan struct{}),
+		repo:     repo,
+		natsConn: nc,
+		leader:   leader,
+		now:      now,
+		rand:     rand,
+		stopChan: make(chan struct{}),
 	}
 }
 
 func (s *Scheduler) Start() {
-	ticker := time.NewTicker(10 * time.Second) // Check every 10 seconds (simplification)
+	ticker := time.NewTicker(10 * time.Second) // resolution of next_poll_at
 	defer ticker.Stop()
 
 	log.Println("Collector Scheduler started")
@@ -34,7 +79,7 @@ func (s *Scheduler) Start() {
 	for {
 		select {
 		case <-ticker.C:
-			s.schedulePolls()
+			s.RunOnce(context.Background())
 		case <-s.stopChan:
 			log.Println("Collector Scheduler stopped")
 			return
@@ -46,37 +91,70 @@ func (s *Scheduler) Stop() {
 	close(s.stopChan)
 }
 
-func (s *Scheduler) schedulePolls() {
-	ctx := context.Background()
-	// In a real app, we would query DB for devices "due" for polling.
-	// For now, we fetch all enabled devices and dispatch tasks.
-	// Optimization: Use pagination or specific DB query for 'next_poll_at'
-	
-	devices, _, err := s.deviceService.ListDevices(ctx, 1, 1000)
+// RunOnce publishes a poll task for every enabled device that is due and
+// moves its next_poll_at forward. A device whose task could not be published
+// stays due and is retried on the next run. It does nothing unless this
+// instance is the leader, and stops as soon as it no longer is.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	if s.leader != nil && !s.leader.IsLeader() {
+		return
+	}
+
+	now := s.now()
+	devices, err := s.repo.ListDueForPolling(ctx, now, pollBatchSize)
 	if err != nil {
-		log.Printf("Error fetching devices: %v", err)
+		log.Printf("Error fetching devices due for polling: %v", err)
 		return
 	}
 
 	for _, d := range devices {
-		if !d.Enabled {
-			continue
+		// Leadership can run out part way through a batch; stop before a
+		// new leader starts dispatching the same devices.
+		if s.leader != nil && !s.leader.IsLeader() {
+			return
 		}
 
+		next := s.nextPoll(d, now)
 		task := commonModel.PollTask{
 			DeviceID:   d.ID,
 			IPAddress:  d.IPAddress,
 			DeviceType: string(d.DeviceType),
 			Protocol:   string(d.Protocol),
-			Timestamp:  time.Now(),
+			Timestamp:  now,
+			ExpiresAt:  next,
+			Priority:   d.Priority,
+		}
+		if d.Protocol == model.ProtocolSNMP && d.Credentials != nil {
+			// Stored communities are already encrypted and pass through
+			// unchanged; ones saved before the key was set are encrypted here.
+			community, err := crypto.Encrypt(d.Credentials.SNMPCommunity)
+			if err != nil {
+				log.Printf("Error encrypting SNMP community for device %s: %v", d.Name, err)
+				continue
+			}
+			task.SNMPCommunity = community
 		}
 
 		payload, _ := json.Marshal(task)
 		err := s.natsConn.Publish("nms.poll.tasks", payload)
 		if err != nil {
 			log.Printf("Error publishing task for device %s: %v", d.Name, err)
-		} else {
-			// log.Printf("Scheduled poll for %s (%s)", d.Name, d.IPAddress)
+			continue
 		}
+
+		if err := s.repo.UpdateNextPollAt(ctx, d.ID, next); err != nil {
+			log.Printf("Error scheduling next poll for device %s: %v", d.Name, err)
+		}
+	}
+}
+
+// nextPoll returns when d is next due after a poll at now: its polling
+// interval later, give or take pollJitter of the interval.
+func (s *Scheduler) nextPoll(d *model.Device, now time.Time) time.Time {
+	interval := d.GetPollingIntervalDuration()
+	if interval <= 0 {
+		interval = defaultPollInterval
 	}
+	jitter := time.Duration((s.rand()*2 - 1) * pollJitter * float64(interval))
+	return now.Add(interval + jitter)
 }
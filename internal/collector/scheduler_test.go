@@ -0,0 +1,170 @@
+package collector_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/collector"
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository/repositorytest"
+)
+
+// fakeConn records published poll tasks instead of talking to NATS.
+type fakeConn struct {
+	tasks []commonModel.PollTask
+}
+
+func (f *fakeConn) Publish(subj string, data []byte) error {
+	var task commonModel.PollTask
+	if err := json.Unmarshal(data, &task); err != nil {
+		return err
+	}
+	f.tasks = append(f.tasks, task)
+	return nil
+}
+
+func (f *fakeConn) Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return &nats.Subscription{}, nil
+}
+
+func (f *fakeConn) QueueSubscribe(subj, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return &nats.Subscription{}, nil
+}
+
+func (f *fakeConn) devices() []string {
+	var out []string
+	for _, task := range f.tasks {
+		out = append(out, task.DeviceID)
+	}
+	f.tasks = nil
+	return out
+}
+
+func TestScheduler_PollsDevicesOnTheirInterval(t *testing.T) {
+	credentialsID := "cred-1"
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{ID: "olt-1", Name: "olt-1", IPAddress: "10.0.0.1", DeviceType: model.DeviceTypeOLT, Protocol: model.ProtocolSNMP, Enabled: true, PollingInterval: 60, Priority: 10,
+			CredentialsID: &credentialsID, Credentials: &model.DeviceCredentials{ID: credentialsID, SNMPCommunity: "s3cret"}},
+		&model.Device{ID: "olt-2", Name: "olt-2", IPAddress: "10.0.0.2", DeviceType: model.DeviceTypeOLT, Protocol: model.ProtocolSNMP, Enabled: true},
+		&model.Device{ID: "router-1", Name: "router-1", IPAddress: "10.0.1.1", DeviceType: model.DeviceTypeRouter, Protocol: model.ProtocolMikrotikAPI, Enabled: true, PollingInterval: 30},
+		&model.Device{ID: "olt-off", Name: "olt-off", IPAddress: "10.0.0.3", DeviceType: model.DeviceTypeOLT, Protocol: model.ProtocolSNMP},
+	)
+	nc := &fakeConn{}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := collector.NewSchedulerForTest(repo, nc, nil, func() time.Time { return now }, func() float64 { return 0.5 })
+	ctx := context.Background()
+
+	s.RunOnce(ctx)
+	require.Len(t, nc.tasks, 3)
+	for _, task := range nc.tasks {
+		if task.DeviceID == "olt-1" {
+			assert.Equal(t, "olt", task.DeviceType)
+			assert.Equal(t, "snmp", task.Protocol)
+			assert.Equal(t, "s3cret", task.SNMPCommunity)
+			assert.Equal(t, 10, task.Priority)
+			assert.Equal(t, now.Add(time.Minute), task.ExpiresAt, "superseded by the next poll")
+		} else {
+			assert.Empty(t, task.SNMPCommunity, task.DeviceID)
+		}
+	}
+	nc.tasks = nil
+
+	olt, err := repo.GetByID(ctx, "olt-1")
+	require.NoError(t, err)
+	require.NotNil(t, olt.NextPollAt)
+	assert.Equal(t, now.Add(time.Minute), *olt.NextPollAt)
+
+	now = now.Add(10 * time.Second)
+	s.RunOnce(ctx)
+	assert.Empty(t, nc.devices(), "nothing is due before its interval")
+
+	now = now.Add(20 * time.Second)
+	s.RunOnce(ctx)
+	assert.Equal(t, []string{"router-1"}, nc.devices())
+
+	now = now.Add(30 * time.Second)
+	s.RunOnce(ctx)
+	assert.ElementsMatch(t, []string{"olt-1", "router-1"}, nc.devices())
+
+	// olt-2 has no interval and uses the default of five minutes.
+	now = now.Add(240 * time.Second)
+	s.RunOnce(ctx)
+	assert.ElementsMatch(t, []string{"olt-1", "olt-2", "router-1"}, nc.devices())
+}
+
+func TestScheduler_EncryptsSNMPCommunity(t *testing.T) {
+	c, err := crypto.NewCipherFromKey("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	crypto.SetDefault(c)
+	t.Cleanup(func() { crypto.SetDefault(nil) })
+
+	credentialsID := "cred-1"
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{ID: "olt-1", Name: "olt-1", IPAddress: "10.0.0.1", DeviceType: model.DeviceTypeOLT, Protocol: model.ProtocolSNMP, Enabled: true,
+			CredentialsID: &credentialsID, Credentials: &model.DeviceCredentials{ID: credentialsID, SNMPCommunity: "s3cret"}},
+	)
+	nc := &fakeConn{}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := collector.NewSchedulerForTest(repo, nc, nil, func() time.Time { return now }, func() float64 { return 0.5 })
+
+	s.RunOnce(context.Background())
+	require.Len(t, nc.tasks, 1)
+	community := nc.tasks[0].SNMPCommunity
+	assert.True(t, crypto.IsEncrypted(community), community)
+	plain, err := crypto.Decrypt(community)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", plain)
+}
+
+func TestScheduler_JittersNextPoll(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{ID: "sw-1", Name: "sw-1", IPAddress: "10.0.2.1", DeviceType: model.DeviceTypeSwitch, Protocol: model.ProtocolSNMP, Enabled: true, PollingInterval: 100},
+		&model.Device{ID: "sw-2", Name: "sw-2", IPAddress: "10.0.2.2", DeviceType: model.DeviceTypeSwitch, Protocol: model.ProtocolSNMP, Enabled: true, PollingInterval: 100},
+	)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	draws := []float64{0, 0.999}
+	s := collector.NewSchedulerForTest(repo, &fakeConn{}, nil, func() time.Time { return now }, func() float64 {
+		r := draws[0]
+		draws = draws[1:]
+		return r
+	})
+	ctx := context.Background()
+
+	s.RunOnce(ctx)
+
+	first, err := repo.GetByID(ctx, "sw-1")
+	require.NoError(t, err)
+	second, err := repo.GetByID(ctx, "sw-2")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(90*time.Second), *first.NextPollAt, "up to 10% early")
+	assert.WithinDuration(t, now.Add(110*time.Second), *second.NextPollAt, 100*time.Millisecond, "up to 10% late")
+}
+
+// failingConn fails every publish.
+type failingConn struct{ fakeConn }
+
+func (f *failingConn) Publish(subj string, data []byte) error {
+	return errors.New("nats: connection closed")
+}
+
+func TestScheduler_RetriesUnpublishedTasks(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{ID: "router-1", Name: "router-1", IPAddress: "10.0.1.1", DeviceType: model.DeviceTypeRouter, Protocol: model.ProtocolMikrotikAPI, Enabled: true, PollingInterval: 60},
+	)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	collector.NewSchedulerForTest(repo, &failingConn{}, nil, func() time.Time { return now }, func() float64 { return 0.5 }).RunOnce(ctx)
+
+	device, err := repo.GetByID(ctx, "router-1")
+	require.NoError(t, err)
+	assert.Nil(t, device.NextPollAt, "the device stays due")
+}
@@ -3,8 +3,11 @@ package adapter
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-routeros/routeros"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+	"github.com/yourorg/nms-go/internal/device/model"
 )
 
 type MikrotikAdapter struct{}
@@ -20,7 +23,7 @@ func (m *MikrotikAdapter) FetchSystemResources(ip, username, password string) (m
 	address := fmt.Sprintf("%s:8728", ip)
 
 	// Dial the device
-	c, err := routeros.Dial(address, username, password)
+	c, err := dial(address, username, password)
 	if err != nil {
 		return nil, false
 	}
@@ -63,7 +66,7 @@ func (m *MikrotikAdapter) FetchSystemResources(ip, username, password string) (m
 // RunCommand executes a command via Mikrotik API
 func (m *MikrotikAdapter) RunCommand(ip, username, password, command string) (string, error) {
 	address := fmt.Sprintf("%s:8728", ip)
-	c, err := routeros.Dial(address, username, password)
+	c, err := dial(address, username, password)
 	if err != nil {
 		return "", fmt.Errorf("failed to dial mikrotik: %w", err)
 	}
@@ -106,6 +109,14 @@ func (m *MikrotikAdapter) RunCommand(ip, username, password, command string) (st
 	return output.String(), nil
 }
 
+// dial opens a RouterOS API session and records connect telemetry.
+func dial(address, username, password string) (*routeros.Client, error) {
+	start := time.Now()
+	c, err := routeros.Dial(address, username, password)
+	telemetry.ObserveConnect(string(model.ProtocolMikrotikAPI), start, err)
+	return c, err
+}
+
 func parsePercentage(s string) float64 {
 	var f float64
 	fmt.Sscanf(strings.TrimSuffix(s, "%"), "%f", &f)
@@ -121,7 +132,7 @@ func parseBytes(s string) int64 {
 // RunCommandStructured executes a command and returns the raw result map
 func (m *MikrotikAdapter) RunCommandStructured(ip, username, password, command string) ([]map[string]string, error) {
 	address := fmt.Sprintf("%s:8728", ip)
-	c, err := routeros.Dial(address, username, password)
+	c, err := dial(address, username, password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial mikrotik: %w", err)
 	}
@@ -1,17 +1,39 @@
 package config
 
 import (
+	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// AppEnvVar selects an environment-specific config file overlay.
+const AppEnvVar = "APP_ENV"
+
 type Config struct {
-	Database DatabaseConfig
-	Redis    RedisConfig
-	NATS     NATSConfig
-	Influx   InfluxConfig
-	Server   ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	NATS         NATSConfig
+	Influx       InfluxConfig
+	Server       ServerConfig
+	Metrics      MetricsConfig
+	Retention    RetentionConfig
+	Webhook      WebhookConfig
+	Integration  IntegrationConfig
+	SMTP         SMTPConfig
+	Collector    CollectorConfig
+	Worker       WorkerConfig
+	Alert        AlertConfig
+	OLT          OLTConfig
+	Admin        AdminConfig
+	Live         LiveConfig
+	TR069        TR069Config
+	Monitoring   MonitoringConfig
+	Identity     IdentityConfig
+	Security     SecurityConfig
+	Notification NotificationConfig
 }
 
 type DatabaseConfig struct {
@@ -30,7 +52,34 @@ type RedisConfig struct {
 }
 
 type NATSConfig struct {
-	URL string
+	URL       string
+	JetStream JetStreamConfig `mapstructure:"jetstream"`
+}
+
+// JetStreamConfig moves poll tasks and metrics onto JetStream streams, so
+// messages published while a consumer is down wait for it. A message is
+// redelivered until its consumer acknowledges it.
+type JetStreamConfig struct {
+	Enabled bool
+
+	// MaxAge is how long the metrics stream keeps messages.
+	MaxAge time.Duration `mapstructure:"max_age"`
+
+	// MetricsMaxBytes caps the size of the metrics stream; the oldest
+	// metrics are dropped beyond it.
+	MetricsMaxBytes int64 `mapstructure:"metrics_max_bytes"`
+
+	// TaskMaxAge is how long the poll task stream keeps tasks. A task is
+	// useless once the device's next poll is due, so keep it to about the
+	// shortest polling interval.
+	TaskMaxAge time.Duration `mapstructure:"task_max_age"`
+
+	// AckWait is how long a delivered message may go unacknowledged before
+	// it is delivered again.
+	AckWait time.Duration `mapstructure:"ack_wait"`
+
+	// MaxDeliver bounds the deliveries of a message; -1 is unlimited.
+	MaxDeliver int `mapstructure:"max_deliver"`
 }
 
 type InfluxConfig struct {
@@ -40,51 +89,481 @@ type InfluxConfig struct {
 	Bucket string
 }
 
+// placeholderInfluxTokens are example values shipped in configs and tooling.
+var placeholderInfluxTokens = map[string]bool{
+	"my-token":            true,
+	"your-influxdb-token": true,
+}
+
+// Validate checks that the settings required to write to InfluxDB are present.
+func (c InfluxConfig) Validate() error {
+	var missing []string
+	if strings.TrimSpace(c.URL) == "" {
+		missing = append(missing, "url")
+	}
+	if token := strings.TrimSpace(c.Token); token == "" || placeholderInfluxTokens[token] {
+		missing = append(missing, "token")
+	}
+	if strings.TrimSpace(c.Org) == "" {
+		missing = append(missing, "org")
+	}
+	if strings.TrimSpace(c.Bucket) == "" {
+		missing = append(missing, "bucket")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("influx config incomplete: missing or placeholder %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 type ServerConfig struct {
 	Port int
 	Mode string
+
+	// MaxBodyBytes limits request bodies; UploadMaxBodyBytes applies to bulk upload routes.
+	MaxBodyBytes       int64 `mapstructure:"max_body_bytes"`
+	UploadMaxBodyBytes int64 `mapstructure:"upload_max_body_bytes"`
+}
+
+// MetricsConfig selects where collected metrics are written.
+type MetricsConfig struct {
+	Sink string // influx (default), stdout, noop
+}
+
+// CollectorConfig configures the collector service.
+type CollectorConfig struct {
+	LeaderElection LeaderElectionConfig `mapstructure:"leader_election"`
+
+	// ReconcilerGroup is the queue group the status reconcilers of all
+	// collectors share, so each poll result updates the device once. Over
+	// JetStream it also names the durable consumer that keeps results
+	// published while every collector is down.
+	ReconcilerGroup string `mapstructure:"reconciler_group"`
+}
+
+// LeaderElectionConfig lets several collector replicas run for failover.
+// They compete for a lock in Redis and only the holder schedules polls; when
+// it stops renewing the lock, another replica takes over within TTL.
+type LeaderElectionConfig struct {
+	Enabled bool
+	ID      string // defaults to the hostname
+	Key     string
+	TTL     time.Duration
+}
+
+// MinLeaderTTL is the shortest lock TTL. The lock is renewed every third of
+// it, so shorter TTLs leave too little room for a slow Redis round trip.
+const MinLeaderTTL = 3 * time.Second
+
+// Validate checks that the TTL is long enough to renew the lock in time.
+func (c LeaderElectionConfig) Validate() error {
+	if c.Enabled && c.TTL < MinLeaderTTL {
+		return fmt.Errorf("leader election ttl %s is below the minimum of %s", c.TTL, MinLeaderTTL)
+	}
+	return nil
+}
+
+// WorkerConfig identifies a poll worker replica. Workers sharing a Group
+// form a NATS queue group, so each poll task is handled by only one of them.
+type WorkerConfig struct {
+	ID    string // defaults to the hostname
+	Group string
+
+	// Concurrency is the number of tasks a worker polls at once. Tasks
+	// beyond it wait, highest priority first.
+	Concurrency int `mapstructure:"concurrency"`
+
+	// Profiles lists the metric groups ("reachability", "system",
+	// "interfaces", "pon_ports", "onts") collected per device type. Device types without a profile get every group.
+	Profiles map[string][]string `mapstructure:"profiles"`
+
+	// MetricsPort serves the worker's Prometheus metrics, such as device
+	// connect durations, at /metrics. 0 disables it.
+	MetricsPort int `mapstructure:"metrics_port"`
+}
+
+// AlertConfig configures the alert engine. Replicas sharing a Group form a
+// NATS queue group, so each metric is evaluated by only one of them.
+type AlertConfig struct {
+	Group string
+
+	// Channels are the notification channels of rules without a route:
+	// "email" or the name of a webhook in NotificationConfig.
+	Channels []string
+	// Routes maps a rule ID to the channels its alerts go to. Viper lowercases
+	// the rule IDs.
+	Routes map[string][]string
+
+	// RenotifyInterval is how often an alert that stays firing is notified
+	// again. Acknowledged alerts are not; 0 notifies only when it fires.
+	RenotifyInterval time.Duration `mapstructure:"renotify_interval"`
+
+	// NotifyWorkers is how many notifications are sent at once, so a slow
+	// channel does not hold up rule evaluation. NotifyQueue is how many wait
+	// per worker before evaluation blocks.
+	NotifyWorkers int `mapstructure:"notify_workers"`
+	NotifyQueue   int `mapstructure:"notify_queue"`
+}
+
+// OLTConfig sets the SNMP timeout for each kind of OLT API operation. Reading
+// system scalars is quick, while walking every ONT on a large OLT is slow.
+type OLTConfig struct {
+	SystemTimeout time.Duration `mapstructure:"system_timeout"`
+	PONTimeout    time.Duration `mapstructure:"pon_timeout"`
+	ONTTimeout    time.Duration `mapstructure:"ont_timeout"`
+
+	// VendorCacheTTL is how long a detected OLT vendor is reused before
+	// sysObjectID/sysDescr are read again. A reboot invalidates it early.
+	VendorCacheTTL time.Duration `mapstructure:"vendor_cache_ttl"`
+
+	// MaxONTs caps the ONTs collected in one walk, so a looping agent cannot
+	// exhaust memory. Results beyond it are dropped and flagged as truncated.
+	MaxONTs int `mapstructure:"max_onts"`
+
+	// ONTDistanceUnit is the unit the OLT reports ONT distance in: "m" (most
+	// firmware), "dm" or "cm".
+	ONTDistanceUnit string `mapstructure:"ont_distance_unit"`
+
+	// MaxSessions bounds the SNMP sessions open at once across all OLT
+	// requests. Further requests wait for a free slot, so a burst cannot
+	// exhaust sockets or ephemeral ports.
+	MaxSessions int `mapstructure:"max_sessions"`
+
+	// StoredCredentials lets requests name a registered device by device_id
+	// and use the SNMP credentials stored for it instead of the body's.
+	StoredCredentials bool `mapstructure:"stored_credentials"`
+}
+
+// RetentionConfig controls the cleanup job for append-only tables.
+type RetentionConfig struct {
+	Enabled  bool
+	DryRun   bool `mapstructure:"dry_run"`
+	Interval time.Duration
+	Tables   map[string]int // table name -> retention in days
 }
 
+// IdentityConfig controls the periodic refresh of device identities
+// (Mikrotik /system/identity, SNMP sysName) into device metadata.
+type IdentityConfig struct {
+	Enabled  bool
+	Interval time.Duration
+
+	// OverwriteName renames devices to their identity. By default the
+	// user-set name is kept and a mismatch is only flagged in metadata.
+	OverwriteName bool `mapstructure:"overwrite_name"`
+}
+
+// WebhookConfig configures outbound event webhooks (e.g. device status changes).
+// Webhooks are disabled when URL is empty.
+type WebhookConfig struct {
+	URL        string
+	Secret     string // HMAC-SHA256 signing secret
+	Timeout    time.Duration
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// NotificationConfig configures the channels alerts can be delivered to,
+// besides email.
+type NotificationConfig struct {
+	Webhooks []WebhookChannelConfig
+	Telegram TelegramConfig
+}
+
+// TelegramConfig configures the "telegram" alert channel, a Telegram bot.
+type TelegramConfig struct {
+	Enabled  bool
+	BotToken string `mapstructure:"bot_token"`
+	// DefaultChatID receives alerts of devices whose groups have no chat
+	DefaultChatID string `mapstructure:"default_chat_id"`
+	// GroupChats maps a device group name or ID to a chat ID. A device's
+	// alerts go to the chat of its group or of the nearest ancestor group
+	// that has one.
+	GroupChats map[string]string `mapstructure:"group_chats"`
+	// Template is a text/template rendering a notification.Alert; empty uses
+	// the built-in one.
+	Template string
+	// RateLimit is the most messages sent to one chat per minute; more are
+	// held and sent as one digest when the minute is up.
+	RateLimit int    `mapstructure:"rate_limit"`
+	APIURL    string `mapstructure:"api_url"`
+	Timeout   time.Duration
+}
+
+// WebhookChannelConfig is a named webhook alerts can be routed to.
+type WebhookChannelConfig struct {
+	Name          string
+	WebhookConfig `mapstructure:",squash"`
+	// Format is the payload: "generic" (default) posts the signed event
+	// envelope, "slack" a {"text": ...} message Slack and Mattermost accept.
+	Format string
+}
+
+// IntegrationConfig configures request signing for server-to-server endpoints.
+// Signature verification is disabled when HMACSecret is empty.
+type IntegrationConfig struct {
+	HMACSecret      string        `mapstructure:"hmac_secret"`
+	SignatureMaxAge time.Duration `mapstructure:"signature_max_age"`
+}
+
+// TR069Config secures the TR-069 ACS endpoint POST /cwmp. CPEs send HTTP
+// basic auth with Username and Password, and must connect from one of
+// AllowedCIDRs when it is set. The endpoint is not mounted when neither a
+// password nor AllowedCIDRs is configured.
+type TR069Config struct {
+	Username     string
+	Password     string
+	AllowedCIDRs []string `mapstructure:"allowed_cidrs"`
+}
+
+// LiveConfig tunes on-demand device polling (/devices/:id/metrics/live and /ws).
+type LiveConfig struct {
+	// PollCacheTTL is how long a live poll result is shared with other
+	// requests for the same device. Concurrent requests always share one poll.
+	PollCacheTTL time.Duration `mapstructure:"poll_cache_ttl"`
+}
+
+// MonitoringConfig drives the API gateway's scheduled device collection.
+type MonitoringConfig struct {
+	Interval time.Duration
+
+	// CycleBudget is how long one collection cycle may take before it is
+	// reported as an overrun. Zero uses Interval.
+	CycleBudget time.Duration `mapstructure:"cycle_budget"`
+
+	// SkipOnOverrun drops the tick that queued up during an overrunning
+	// cycle, so the next cycle starts on schedule instead of immediately.
+	SkipOnOverrun bool `mapstructure:"skip_on_overrun"`
+}
+
+// AdminConfig secures the operator endpoints under /api/v1/admin. They are
+// not mounted when JWTSecret is empty.
+type AdminConfig struct {
+	JWTSecret string `mapstructure:"jwt_secret"`
+}
+
+// SecurityConfig holds the settings under security in the config file.
+type SecurityConfig struct {
+	Encryption EncryptionConfig
+}
+
+// EncryptionConfig configures encryption at rest of stored device
+// credentials. They are stored in plaintext when Key is empty.
+type EncryptionConfig struct {
+	// Key is the AES-256 key: 32 characters, or 32 bytes base64 encoded
+	Key string
+}
+
+// SMTPConfig holds the mail server settings for the email notifier.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// secretEnvVars maps sensitive config keys to their environment variable.
+// Each may instead be supplied as a file path in <VAR>_FILE (Docker/K8s
+// secrets); the file content takes precedence over inline values.
+var secretEnvVars = map[string]string{
+	"database.password":               "DATABASE_PASSWORD",
+	"redis.password":                  "REDIS_PASSWORD",
+	"influx.token":                    "INFLUX_TOKEN",
+	"smtp.username":                   "SMTP_USERNAME",
+	"smtp.password":                   "SMTP_PASSWORD",
+	"webhook.secret":                  "WEBHOOK_SECRET",
+	"integration.hmac_secret":         "INTEGRATION_HMAC_SECRET",
+	"admin.jwt_secret":                "ADMIN_JWT_SECRET",
+	"tr069.password":                  "TR069_ACS_PASSWORD",
+	"security.encryption.key":         "ENCRYPTION_KEY",
+	"notification.telegram.bot_token": "TELEGRAM_BOT_TOKEN",
+}
+
+// applySecretFiles overrides sensitive keys with the content of their *_FILE path.
+func applySecretFiles(v *viper.Viper) error {
+	for key, envVar := range secretEnvVars {
+		path := strings.TrimSpace(os.Getenv(envVar + "_FILE"))
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s_FILE: %w", envVar, err)
+		}
+		// Secret files commonly end with a newline
+		v.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return nil
+}
+
+// LoadConfig reads config.yaml from . or ./configs, then merges
+// config.<APP_ENV>.yaml over it when APP_ENV is set (e.g. config.production.yaml).
+// Environment variables take precedence over both files.
 func LoadConfig() (*Config, error) {
-	viper.SetDefault("server.port", 8008)
-	viper.SetDefault("server.mode", "debug")
-	viper.SetDefault("database.sslmode", "disable")
-	viper.SetDefault("redis.db", 0)
+	v := viper.New()
 
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./configs")
+	v.SetDefault("server.port", 8008)
+	v.SetDefault("server.mode", "debug")
+	v.SetDefault("server.max_body_bytes", 1<<20)         // 1 MiB
+	v.SetDefault("server.upload_max_body_bytes", 32<<20) // 32 MiB
+	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("redis.db", 0)
+	v.SetDefault("nats.jetstream.enabled", true)
+	v.SetDefault("nats.jetstream.max_age", "24h")
+	v.SetDefault("nats.jetstream.ack_wait", "2m")
+	v.SetDefault("nats.jetstream.max_deliver", 5)
+	v.SetDefault("nats.jetstream.metrics_max_bytes", 1<<30) // 1 GiB
+	v.SetDefault("nats.jetstream.task_max_age", "5m")
+	v.SetDefault("metrics.sink", "influx")
+	v.SetDefault("webhook.timeout", "10s")
+	v.SetDefault("webhook.max_retries", 3)
+	v.SetDefault("integration.signature_max_age", "5m")
+	v.SetDefault("collector.leader_election.enabled", false)
+	v.SetDefault("collector.leader_election.key", "nms:collector:leader")
+	v.SetDefault("collector.leader_election.ttl", "15s")
+	v.SetDefault("collector.reconciler_group", "nms-status-reconcilers")
+	v.SetDefault("worker.group", "nms-workers")
+	v.SetDefault("worker.concurrency", 20)
+	v.SetDefault("worker.metrics_port", 9102)
+	if hostname, err := os.Hostname(); err == nil {
+		v.SetDefault("worker.id", hostname)
+		v.SetDefault("collector.leader_election.id", hostname)
+	}
+	v.SetDefault("alert.group", "nms-alert-engine")
+	v.SetDefault("alert.renotify_interval", "1h")
+	v.SetDefault("alert.notify_workers", 4)
+	v.SetDefault("alert.notify_queue", 256)
+	v.SetDefault("alert.channels", []string{"email"})
+	v.SetDefault("notification.telegram.rate_limit", 20)
+	v.SetDefault("notification.telegram.timeout", "10s")
+	v.SetDefault("olt.system_timeout", "5s")
+	v.SetDefault("olt.pon_timeout", "15s")
+	v.SetDefault("olt.ont_timeout", "60s")
+	v.SetDefault("olt.vendor_cache_ttl", "24h")
+	v.SetDefault("olt.max_onts", 8192)
+	v.SetDefault("olt.ont_distance_unit", "m")
+	v.SetDefault("olt.max_sessions", 64)
+	v.SetDefault("olt.stored_credentials", false)
+	v.SetDefault("live.poll_cache_ttl", "1s")
+	v.SetDefault("monitoring.interval", "60s")
+	v.SetDefault("monitoring.cycle_budget", "0s")
+	v.SetDefault("monitoring.skip_on_overrun", false)
+	v.SetDefault("identity.enabled", false)
+	v.SetDefault("identity.interval", "6h")
+	v.SetDefault("identity.overwrite_name", false)
+	v.SetDefault("retention.enabled", true)
+	v.SetDefault("retention.dry_run", false)
+	v.SetDefault("retention.interval", "24h")
+	v.SetDefault("retention.tables", map[string]int{
+		"audit_logs":     90,
+		"config_backups": 30,
+		"alert_history":  90,
+	})
+
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./configs")
 
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	// Explicitly bind environment variables for nested config keys.
-	_ = viper.BindEnv("server.port", "SERVER_PORT")
-	_ = viper.BindEnv("server.mode", "SERVER_MODE")
-	_ = viper.BindEnv("database.host", "DATABASE_HOST")
-	_ = viper.BindEnv("database.port", "DATABASE_PORT")
-	_ = viper.BindEnv("database.user", "DATABASE_USER")
-	_ = viper.BindEnv("database.password", "DATABASE_PASSWORD")
-	_ = viper.BindEnv("database.dbname", "DATABASE_DBNAME")
-	_ = viper.BindEnv("database.sslmode", "DATABASE_SSLMODE")
-	_ = viper.BindEnv("redis.addr", "REDIS_ADDR")
-	_ = viper.BindEnv("redis.password", "REDIS_PASSWORD")
-	_ = viper.BindEnv("redis.db", "REDIS_DB")
-	_ = viper.BindEnv("nats.url", "NATS_URL")
-	_ = viper.BindEnv("influx.url", "INFLUX_URL")
-	_ = viper.BindEnv("influx.token", "INFLUX_TOKEN")
-	_ = viper.BindEnv("influx.org", "INFLUX_ORG")
-	_ = viper.BindEnv("influx.bucket", "INFLUX_BUCKET")
-
-	if err := viper.ReadInConfig(); err != nil {
+	_ = v.BindEnv("server.port", "SERVER_PORT")
+	_ = v.BindEnv("server.mode", "SERVER_MODE")
+	_ = v.BindEnv("server.max_body_bytes", "SERVER_MAX_BODY_BYTES")
+	_ = v.BindEnv("server.upload_max_body_bytes", "SERVER_UPLOAD_MAX_BODY_BYTES")
+	_ = v.BindEnv("database.host", "DATABASE_HOST")
+	_ = v.BindEnv("database.port", "DATABASE_PORT")
+	_ = v.BindEnv("database.user", "DATABASE_USER")
+	_ = v.BindEnv("database.password", "DATABASE_PASSWORD")
+	_ = v.BindEnv("database.dbname", "DATABASE_DBNAME")
+	_ = v.BindEnv("database.sslmode", "DATABASE_SSLMODE")
+	_ = v.BindEnv("redis.addr", "REDIS_ADDR")
+	_ = v.BindEnv("redis.password", "REDIS_PASSWORD")
+	_ = v.BindEnv("redis.db", "REDIS_DB")
+	_ = v.BindEnv("nats.url", "NATS_URL")
+	_ = v.BindEnv("nats.jetstream.enabled", "NATS_JETSTREAM_ENABLED")
+	_ = v.BindEnv("nats.jetstream.max_age", "NATS_JETSTREAM_MAX_AGE")
+	_ = v.BindEnv("nats.jetstream.ack_wait", "NATS_JETSTREAM_ACK_WAIT")
+	_ = v.BindEnv("nats.jetstream.max_deliver", "NATS_JETSTREAM_MAX_DELIVER")
+	_ = v.BindEnv("nats.jetstream.metrics_max_bytes", "NATS_JETSTREAM_METRICS_MAX_BYTES")
+	_ = v.BindEnv("nats.jetstream.task_max_age", "NATS_JETSTREAM_TASK_MAX_AGE")
+	_ = v.BindEnv("influx.url", "INFLUX_URL")
+	_ = v.BindEnv("influx.token", "INFLUX_TOKEN")
+	_ = v.BindEnv("influx.org", "INFLUX_ORG")
+	_ = v.BindEnv("influx.bucket", "INFLUX_BUCKET")
+	_ = v.BindEnv("metrics.sink", "METRICS_SINK")
+	_ = v.BindEnv("retention.enabled", "RETENTION_ENABLED")
+	_ = v.BindEnv("retention.dry_run", "RETENTION_DRY_RUN")
+	_ = v.BindEnv("retention.interval", "RETENTION_INTERVAL")
+	_ = v.BindEnv("webhook.url", "WEBHOOK_URL")
+	_ = v.BindEnv("webhook.secret", "WEBHOOK_SECRET")
+	_ = v.BindEnv("integration.hmac_secret", "INTEGRATION_HMAC_SECRET")
+	_ = v.BindEnv("collector.leader_election.enabled", "COLLECTOR_LEADER_ELECTION_ENABLED")
+	_ = v.BindEnv("collector.leader_election.id", "COLLECTOR_ID")
+	_ = v.BindEnv("collector.leader_election.ttl", "COLLECTOR_LEADER_ELECTION_TTL")
+	_ = v.BindEnv("collector.reconciler_group", "COLLECTOR_RECONCILER_GROUP")
+	_ = v.BindEnv("worker.id", "WORKER_ID")
+	_ = v.BindEnv("worker.group", "WORKER_GROUP")
+	_ = v.BindEnv("worker.concurrency", "WORKER_CONCURRENCY")
+	_ = v.BindEnv("worker.metrics_port", "WORKER_METRICS_PORT")
+	_ = v.BindEnv("alert.group", "ALERT_GROUP")
+	_ = v.BindEnv("alert.renotify_interval", "ALERT_RENOTIFY_INTERVAL")
+	_ = v.BindEnv("alert.notify_workers", "ALERT_NOTIFY_WORKERS")
+	_ = v.BindEnv("notification.telegram.enabled", "TELEGRAM_ENABLED")
+	_ = v.BindEnv("notification.telegram.bot_token", "TELEGRAM_BOT_TOKEN")
+	_ = v.BindEnv("notification.telegram.default_chat_id", "TELEGRAM_CHAT_ID")
+	_ = v.BindEnv("olt.system_timeout", "OLT_SYSTEM_TIMEOUT")
+	_ = v.BindEnv("olt.pon_timeout", "OLT_PON_TIMEOUT")
+	_ = v.BindEnv("olt.ont_timeout", "OLT_ONT_TIMEOUT")
+	_ = v.BindEnv("olt.vendor_cache_ttl", "OLT_VENDOR_CACHE_TTL")
+	_ = v.BindEnv("olt.max_onts", "OLT_MAX_ONTS")
+	_ = v.BindEnv("olt.ont_distance_unit", "OLT_ONT_DISTANCE_UNIT")
+	_ = v.BindEnv("olt.max_sessions", "OLT_MAX_SESSIONS")
+	_ = v.BindEnv("olt.stored_credentials", "OLT_STORED_CREDENTIALS")
+	_ = v.BindEnv("admin.jwt_secret", "ADMIN_JWT_SECRET")
+	_ = v.BindEnv("tr069.username", "TR069_ACS_USERNAME")
+	_ = v.BindEnv("tr069.password", "TR069_ACS_PASSWORD")
+	_ = v.BindEnv("tr069.allowed_cidrs", "TR069_ACS_ALLOWED_CIDRS")
+	_ = v.BindEnv("live.poll_cache_ttl", "LIVE_POLL_CACHE_TTL")
+	_ = v.BindEnv("monitoring.interval", "MONITORING_INTERVAL")
+	_ = v.BindEnv("monitoring.cycle_budget", "MONITORING_CYCLE_BUDGET")
+	_ = v.BindEnv("monitoring.skip_on_overrun", "MONITORING_SKIP_ON_OVERRUN")
+	_ = v.BindEnv("identity.enabled", "IDENTITY_ENABLED")
+	_ = v.BindEnv("identity.interval", "IDENTITY_INTERVAL")
+	_ = v.BindEnv("identity.overwrite_name", "IDENTITY_OVERWRITE_NAME")
+	_ = v.BindEnv("security.encryption.key", "ENCRYPTION_KEY")
+	_ = v.BindEnv("smtp.host", "SMTP_HOST")
+	_ = v.BindEnv("smtp.port", "SMTP_PORT")
+	_ = v.BindEnv("smtp.username", "SMTP_USERNAME")
+	_ = v.BindEnv("smtp.password", "SMTP_PASSWORD")
+	_ = v.BindEnv("smtp.from", "SMTP_FROM")
+
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
 		}
 	}
 
+	// Merge the environment-specific file over the base, if present.
+	if env := strings.TrimSpace(os.Getenv(AppEnvVar)); env != "" {
+		v.SetConfigName("config." + env)
+		if err := v.MergeInConfig(); err != nil {
+			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+				return nil, fmt.Errorf("failed to read config for %s=%s: %w", AppEnvVar, env, err)
+			}
+		}
+	}
+
+	if err := applySecretFiles(v); err != nil {
+		return nil, err
+	}
+
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,296 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+)
+
+const baseConfig = `
+server:
+  port: 1111
+  mode: debug
+database:
+  host: base-db
+  port: 5432
+`
+
+const productionConfig = `
+server:
+  port: 2222
+  mode: release
+`
+
+// inTempDir writes files into a temporary directory and makes it the working
+// directory for the duration of the test.
+func inTempDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	return dir
+}
+
+func TestLoadConfig_EnvOverrideWins(t *testing.T) {
+	inTempDir(t, map[string]string{
+		"config.yaml":            baseConfig,
+		"config.production.yaml": productionConfig,
+	})
+	t.Setenv(config.AppEnvVar, "production")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, 2222, cfg.Server.Port, "override must win")
+	assert.Equal(t, "release", cfg.Server.Mode)
+	assert.Equal(t, "base-db", cfg.Database.Host, "keys missing from the override fall back to base")
+	assert.Equal(t, 5432, cfg.Database.Port)
+}
+
+func TestLoadConfig_BaseOnlyWithoutAppEnv(t *testing.T) {
+	inTempDir(t, map[string]string{
+		"config.yaml":            baseConfig,
+		"config.production.yaml": productionConfig,
+	})
+	t.Setenv(config.AppEnvVar, "")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 1111, cfg.Server.Port)
+}
+
+func TestLoadConfig_MissingEnvFileFallsBackToBase(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": baseConfig})
+	t.Setenv(config.AppEnvVar, "staging")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 1111, cfg.Server.Port)
+}
+
+func TestLoadConfig_EnvFileWithoutBase(t *testing.T) {
+	inTempDir(t, map[string]string{"config.production.yaml": productionConfig})
+	t.Setenv(config.AppEnvVar, "production")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 2222, cfg.Server.Port)
+	assert.Equal(t, "disable", cfg.Database.SSLMode, "defaults still apply")
+}
+
+func writeSecret(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig_SecretFiles(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+database:
+  password: inline-db-password
+influx:
+  token: inline-token
+`})
+	t.Setenv(config.AppEnvVar, "")
+	t.Setenv("DATABASE_PASSWORD", "env-db-password")
+	t.Setenv("DATABASE_PASSWORD_FILE", writeSecret(t, "file-db-password\n"))
+	t.Setenv("INFLUX_TOKEN_FILE", writeSecret(t, "file-token"))
+	t.Setenv("SMTP_PASSWORD_FILE", writeSecret(t, "smtp-pass\r\n"))
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, "file-db-password", cfg.Database.Password, "file must win over env and inline values")
+	assert.Equal(t, "file-token", cfg.Influx.Token, "file must win over the inline value")
+	assert.Equal(t, "smtp-pass", cfg.SMTP.Password, "trailing newline is trimmed")
+}
+
+func TestLoadConfig_EncryptionKey(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+security:
+  encryption:
+    key: inline-key
+`})
+	t.Setenv(config.AppEnvVar, "")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "inline-key", cfg.Security.Encryption.Key)
+
+	t.Setenv("ENCRYPTION_KEY_FILE", writeSecret(t, "file-key\n"))
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "file-key", cfg.Security.Encryption.Key)
+}
+
+func TestLoadConfig_InlineSecretWithoutFile(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+influx:
+  token: inline-token
+`})
+	t.Setenv(config.AppEnvVar, "")
+	t.Setenv("INFLUX_TOKEN_FILE", "")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "inline-token", cfg.Influx.Token)
+}
+
+func TestLoadConfig_MissingSecretFile(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": baseConfig})
+	t.Setenv(config.AppEnvVar, "")
+	t.Setenv("DATABASE_PASSWORD_FILE", filepath.Join(t.TempDir(), "does-not-exist"))
+
+	_, err := config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DATABASE_PASSWORD_FILE")
+}
+
+func TestLoadConfig_WorkerProfiles(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+worker:
+  profiles:
+    olt: [reachability]
+    router: [reachability, system]
+`})
+	t.Setenv(config.AppEnvVar, "")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"olt":    {"reachability"},
+		"router": {"reachability", "system"},
+	}, cfg.Worker.Profiles)
+	assert.Equal(t, 9102, cfg.Worker.MetricsPort, "default metrics port")
+
+	t.Setenv("WORKER_METRICS_PORT", "0")
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Worker.MetricsPort)
+}
+
+func TestLoadConfig_LeaderElection(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+collector:
+  leader_election:
+    enabled: true
+    id: collector-a
+`})
+	t.Setenv(config.AppEnvVar, "")
+	t.Setenv("COLLECTOR_LEADER_ELECTION_TTL", "30s")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, config.LeaderElectionConfig{
+		Enabled: true,
+		ID:      "collector-a",
+		Key:     "nms:collector:leader",
+		TTL:     30 * time.Second,
+	}, cfg.Collector.LeaderElection)
+}
+
+func TestLeaderElectionConfig_Validate(t *testing.T) {
+	cfg := config.LeaderElectionConfig{Enabled: true, TTL: config.MinLeaderTTL}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.TTL = time.Second
+	assert.Error(t, cfg.Validate())
+
+	cfg.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadConfig_JetStream(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+nats:
+  jetstream:
+    max_deliver: 3
+`})
+	t.Setenv(config.AppEnvVar, "")
+	t.Setenv("NATS_JETSTREAM_ACK_WAIT", "30s")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, config.JetStreamConfig{
+		Enabled:         true,
+		MaxAge:          24 * time.Hour,
+		MetricsMaxBytes: 1 << 30,
+		TaskMaxAge:      5 * time.Minute,
+		AckWait:         30 * time.Second,
+		MaxDeliver:      3,
+	}, cfg.NATS.JetStream)
+}
+
+func TestLoadConfig_TR069(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+tr069:
+  username: acs
+`})
+	t.Setenv(config.AppEnvVar, "")
+	t.Setenv("TR069_ACS_PASSWORD", "s3cret")
+	t.Setenv("TR069_ACS_ALLOWED_CIDRS", "10.0.0.0/8,192.0.2.7")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, config.TR069Config{
+		Username:     "acs",
+		Password:     "s3cret",
+		AllowedCIDRs: []string{"10.0.0.0/8", "192.0.2.7"},
+	}, cfg.TR069)
+}
+
+func TestLoadConfig_DevelopmentExample(t *testing.T) {
+	example, err := os.ReadFile("../../../configs/env/development.yaml.example")
+	require.NoError(t, err)
+	inTempDir(t, map[string]string{"config.yaml": string(example)})
+	t.Setenv(config.AppEnvVar, "")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err, "a copied example config must load")
+	assert.Equal(t, 60*time.Second, cfg.Monitoring.Interval)
+	assert.Equal(t, "influx", cfg.Metrics.Sink)
+	assert.Equal(t, "nms-status-reconcilers", cfg.Collector.ReconcilerGroup)
+}
+
+func TestLoadConfig_AlertRouting(t *testing.T) {
+	inTempDir(t, map[string]string{"config.yaml": `
+alert:
+  routes:
+    rule-2: [email, ops-slack]
+notification:
+  webhooks:
+    - name: ops-slack
+      url: https://hooks.example.com/T000/B000
+      format: slack
+      max_retries: 5
+`})
+	t.Setenv(config.AppEnvVar, "")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email"}, cfg.Alert.Channels, "defaults to email")
+	assert.Equal(t, 4, cfg.Alert.NotifyWorkers)
+	assert.Equal(t, 256, cfg.Alert.NotifyQueue)
+	assert.Equal(t, map[string][]string{"rule-2": {"email", "ops-slack"}}, cfg.Alert.Routes)
+
+	require.Len(t, cfg.Notification.Webhooks, 1)
+	wh := cfg.Notification.Webhooks[0]
+	assert.Equal(t, "ops-slack", wh.Name)
+	assert.Equal(t, "https://hooks.example.com/T000/B000", wh.URL)
+	assert.Equal(t, "slack", wh.Format)
+	assert.Equal(t, 5, wh.MaxRetries)
+}
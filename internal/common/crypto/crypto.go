@@ -0,0 +1,171 @@
+// Package crypto encrypts secrets stored at rest, such as device
+// credentials, with AES-256-GCM.
+//
+// Encrypted values are text, "enc:v1:<base64 nonce+ciphertext>", so they fit
+// the existing columns. Values without the prefix are taken as plaintext:
+// credentials stored before a key was configured, or passed in a request
+// body, keep working and are encrypted the next time they are saved.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// KeySize is the length of an AES-256 key in bytes.
+const KeySize = 32
+
+const prefix = "enc:v1:"
+
+var (
+	// ErrInvalidKey is returned for a key that is neither 32 characters nor
+	// a base64-encoded 32-byte value.
+	ErrInvalidKey = errors.New("encryption key must be 32 characters or 32 bytes base64 encoded")
+
+	// ErrNoKey is returned when decrypting an encrypted value without a
+	// configured key.
+	ErrNoKey = errors.New("no encryption key configured")
+
+	// ErrDecrypt is returned for a value that is malformed or was encrypted
+	// with another key.
+	ErrDecrypt = errors.New("failed to decrypt value")
+)
+
+// Cipher encrypts and decrypts values with one key. It is safe for
+// concurrent use.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a Cipher from a raw 32-byte key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// NewCipherFromKey creates a Cipher from a key as read from config: 32
+// characters used as is, or 32 bytes base64 encoded, as generated by
+// `openssl rand -base64 32`.
+func NewCipherFromKey(key string) (*Cipher, error) {
+	key = strings.TrimSpace(key)
+	if len(key) == KeySize {
+		return NewCipher([]byte(key))
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return NewCipher(raw)
+}
+
+// IsEncrypted reports whether value was produced by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt encrypts plaintext with a fresh random nonce. Empty and already
+// encrypted values are returned unchanged, so saving a loaded record does not
+// encrypt twice.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of value. Values that are not encrypted are
+// returned unchanged.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", fmt.Errorf("%w: value too short", ErrDecrypt)
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	return string(plaintext), nil
+}
+
+// defaultCipher is the process-wide cipher set from config at startup. The
+// repository encrypts with it and protocol clients decrypt with it.
+var defaultCipher atomic.Pointer[Cipher]
+
+// SetDefault sets the process-wide cipher. nil disables encryption: values
+// are then stored as plaintext.
+func SetDefault(c *Cipher) {
+	defaultCipher.Store(c)
+}
+
+// SetDefaultKey sets the process-wide cipher from a config key, see
+// NewCipherFromKey. An empty key disables encryption.
+func SetDefaultKey(key string) error {
+	if strings.TrimSpace(key) == "" {
+		SetDefault(nil)
+		return nil
+	}
+	c, err := NewCipherFromKey(key)
+	if err != nil {
+		return err
+	}
+	SetDefault(c)
+	return nil
+}
+
+// Enabled reports whether a process-wide cipher is set.
+func Enabled() bool {
+	return defaultCipher.Load() != nil
+}
+
+// Encrypt encrypts plaintext with the process-wide cipher. Without one, it is
+// returned unchanged.
+func Encrypt(plaintext string) (string, error) {
+	c := defaultCipher.Load()
+	if c == nil {
+		return plaintext, nil
+	}
+	return c.Encrypt(plaintext)
+}
+
+// Decrypt decrypts value with the process-wide cipher. Plaintext values are
+// returned unchanged; an encrypted value without a cipher returns ErrNoKey.
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	c := defaultCipher.Load()
+	if c == nil {
+		return "", ErrNoKey
+	}
+	return c.Decrypt(value)
+}
@@ -0,0 +1,110 @@
+package crypto_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/crypto"
+)
+
+const testKey = "0123456789abcdef0123456789abcdef"
+
+func newCipher(t *testing.T, key string) *crypto.Cipher {
+	t.Helper()
+	c, err := crypto.NewCipherFromKey(key)
+	require.NoError(t, err)
+	return c
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c := newCipher(t, testKey)
+
+	encrypted, err := c.Encrypt("s3cret")
+	require.NoError(t, err)
+	assert.True(t, crypto.IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "s3cret")
+
+	again, err := c.Encrypt("s3cret")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "every value gets a fresh nonce")
+
+	plaintext, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", plaintext)
+}
+
+func TestCipher_EncryptKeepsEmptyAndEncryptedValues(t *testing.T) {
+	c := newCipher(t, testKey)
+
+	empty, err := c.Encrypt("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	encrypted, err := c.Encrypt("s3cret")
+	require.NoError(t, err)
+	twice, err := c.Encrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, encrypted, twice)
+}
+
+func TestCipher_DecryptPlaintextPassesThrough(t *testing.T) {
+	plaintext, err := newCipher(t, testKey).Decrypt("legacy-password")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-password", plaintext)
+}
+
+func TestCipher_DecryptWithOtherKey(t *testing.T) {
+	encrypted, err := newCipher(t, testKey).Encrypt("s3cret")
+	require.NoError(t, err)
+
+	_, err = newCipher(t, strings.Repeat("x", 32)).Decrypt(encrypted)
+	assert.ErrorIs(t, err, crypto.ErrDecrypt)
+
+	_, err = newCipher(t, testKey).Decrypt("enc:v1:not-base64!")
+	assert.ErrorIs(t, err, crypto.ErrDecrypt)
+}
+
+func TestNewCipherFromKey(t *testing.T) {
+	raw := []byte(testKey)
+	encoded := base64.StdEncoding.EncodeToString(raw)
+
+	// The raw and the base64 form of a key are the same key.
+	encrypted, err := newCipher(t, testKey).Encrypt("s3cret")
+	require.NoError(t, err)
+	plaintext, err := newCipher(t, encoded).Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", plaintext)
+
+	for _, key := range []string{"too-short", "your-32-character-encryption-key-change-in-production", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		_, err := crypto.NewCipherFromKey(key)
+		assert.ErrorIs(t, err, crypto.ErrInvalidKey, key)
+	}
+}
+
+func TestDefaultCipher(t *testing.T) {
+	t.Cleanup(func() { crypto.SetDefault(nil) })
+
+	require.NoError(t, crypto.SetDefaultKey(""))
+	assert.False(t, crypto.Enabled())
+	stored, err := crypto.Encrypt("s3cret")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", stored, "without a key values are stored as is")
+
+	require.NoError(t, crypto.SetDefaultKey(testKey))
+	assert.True(t, crypto.Enabled())
+	stored, err = crypto.Encrypt("s3cret")
+	require.NoError(t, err)
+	assert.True(t, crypto.IsEncrypted(stored))
+	plaintext, err := crypto.Decrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", plaintext)
+
+	crypto.SetDefault(nil)
+	_, err = crypto.Decrypt(stored)
+	assert.ErrorIs(t, err, crypto.ErrNoKey)
+
+	assert.ErrorIs(t, crypto.SetDefaultKey("invalid"), crypto.ErrInvalidKey)
+}
@@ -18,10 +18,12 @@ func NewInfluxConnection(cfg config.InfluxConfig) (influxdb2.Client, error) {
 
 	ok, err := client.Ping(ctx)
 	if err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to influxdb: %w", err)
 	}
 
 	if !ok {
+		client.Close()
 		return nil, fmt.Errorf("influxdb health check failed")
 	}
 
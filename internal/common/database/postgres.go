@@ -1,9 +1,12 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
@@ -11,10 +14,7 @@ import (
 )
 
 func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
-		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(PostgresDSN(cfg)), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -22,7 +22,82 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
+// PostgresDSN returns the connection string for cfg.
+func PostgresDSN(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode)
+}
+
 func Migrate(db *gorm.DB, models ...interface{}) error {
 	log.Println("Running database migrations...")
+	if err := EnsureUUIDSupport(db); err != nil {
+		return err
+	}
 	return db.AutoMigrate(models...)
 }
+
+// EnsureUUIDSupport makes sure gen_random_uuid(), used as the default for
+// UUID primary keys, exists. It is built in from PostgreSQL 13 and provided
+// by the pgcrypto extension before that.
+func EnsureUUIDSupport(db *gorm.DB) error {
+	extErr := db.Exec("CREATE EXTENSION IF NOT EXISTS pgcrypto").Error
+	if extErr == nil {
+		return nil
+	}
+
+	if err := db.Exec("SELECT gen_random_uuid()").Error; err != nil {
+		return fmt.Errorf("gen_random_uuid() is unavailable: enable the pgcrypto extension or use PostgreSQL 13+ (create extension: %v): %w", extErr, err)
+	}
+	return nil
+}
+
+// undefinedTable is the Postgres SQLSTATE for a relation that does not exist.
+const undefinedTable = "42P01"
+
+// EnsureUnique checks that table has no duplicate values in column, so that
+// AutoMigrate can add a unique index on it. It fails listing a few of the
+// duplicates otherwise. A missing table passes; it is created with the index.
+func EnsureUnique(db *gorm.DB, table, column string) error {
+	return EnsureUniqueWhere(db, table, "", column)
+}
+
+// EnsureUniqueWhere is EnsureUnique for a partial unique index over several
+// columns: only rows matching where, if set, are checked. Duplicates are
+// listed with their column values joined by "/".
+func EnsureUniqueWhere(db *gorm.DB, table, where string, columns ...string) error {
+	name := strings.Join(columns, ", ")
+	value := columns[0]
+	if len(columns) > 1 {
+		value = "CONCAT_WS('/', " + name + ")"
+	}
+
+	query := db.Table(table)
+	if where != "" {
+		query = query.Where(where)
+	}
+	var dups []string
+	err := query.
+		Group(name).
+		Having("COUNT(*) > 1").
+		Limit(10).
+		Pluck(value, &dups).Error
+
+	if len(columns) > 1 {
+		name = "(" + name + ")"
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check %s.%s for duplicates: %w", table, name, err)
+	}
+	if len(dups) > 0 {
+		scope := ""
+		if where != "" {
+			scope = " where " + where
+		}
+		return fmt.Errorf("%s.%s must be unique%s, remove the duplicates first: %s", table, name, scope, strings.Join(dups, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package database_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	return db, mock
+}
+
+func TestEnsureUUIDSupport(t *testing.T) {
+	createExt := regexp.QuoteMeta("CREATE EXTENSION IF NOT EXISTS pgcrypto")
+	selectFn := regexp.QuoteMeta("SELECT gen_random_uuid()")
+
+	t.Run("extension created", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(createExt).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.NoError(t, database.EnsureUUIDSupport(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("built-in function without extension privileges", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(createExt).WillReturnError(errors.New("permission denied to create extension"))
+		mock.ExpectExec(selectFn).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, database.EnsureUUIDSupport(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(createExt).WillReturnError(errors.New("extension \"pgcrypto\" is not available"))
+		mock.ExpectExec(selectFn).WillReturnError(errors.New("function gen_random_uuid() does not exist"))
+
+		err := database.EnsureUUIDSupport(db)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "enable the pgcrypto extension")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestEnsureUnique(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT "ip_address" FROM "devices" GROUP BY "ip_address" HAVING COUNT(*) > 1 LIMIT 10`)
+
+	t.Run("no duplicates", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"ip_address"}))
+
+		assert.NoError(t, database.EnsureUnique(db, "devices", "ip_address"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("duplicates", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"ip_address"}).
+			AddRow("10.0.0.1").AddRow("10.0.0.2"))
+
+		err := database.EnsureUnique(db, "devices", "ip_address")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "10.0.0.1, 10.0.0.2")
+	})
+
+	t.Run("table not created yet", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(query).WillReturnError(&pgconn.PgError{Code: "42P01"})
+
+		assert.NoError(t, database.EnsureUnique(db, "devices", "ip_address"))
+	})
+}
+
+func TestEnsureUniqueWhere(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT CONCAT_WS('/', device_id, rule_id) FROM "alert_history" WHERE state <> 'resolved' ` +
+		`GROUP BY device_id, rule_id HAVING COUNT(*) > 1 LIMIT 10`)
+
+	t.Run("no duplicates", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+		assert.NoError(t, database.EnsureUniqueWhere(db, "alert_history", "state <> 'resolved'", "device_id", "rule_id"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("duplicates", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("dev-1/rule-2"))
+
+		err := database.EnsureUniqueWhere(db, "alert_history", "state <> 'resolved'", "device_id", "rule_id")
+		assert.EqualError(t, err, "alert_history.(device_id, rule_id) must be unique where state <> 'resolved', "+
+			"remove the duplicates first: dev-1/rule-2")
+	})
+}
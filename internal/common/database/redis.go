@@ -9,16 +9,23 @@ import (
 )
 
 func NewRedisConnection(cfg config.RedisConfig) (*redis.Client, error) {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.Addr,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+	rdb := NewRedisClient(cfg)
 
 	_, err := rdb.Ping(context.Background()).Result()
 	if err != nil {
+		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
 	return rdb, nil
 }
+
+// NewRedisClient creates a client for cfg without checking that Redis is
+// reachable. It connects on first use.
+func NewRedisClient(cfg config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
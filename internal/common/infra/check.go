@@ -0,0 +1,154 @@
+// Package infra checks that the infrastructure services (Postgres, Redis,
+// NATS and InfluxDB) are reachable. It backs the verify tools and the
+// readiness endpoint.
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/nats-io/nats.go"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/database"
+)
+
+// Service names reported in Results.
+const (
+	ServicePostgres = "postgres"
+	ServiceRedis    = "redis"
+	ServiceNATS     = "nats"
+	ServiceInflux   = "influxdb"
+)
+
+// DefaultTimeout bounds each check run by CheckAll.
+const DefaultTimeout = 5 * time.Second
+
+// Check verifies that one service is reachable.
+type Check struct {
+	Service string
+	Ping    func(ctx context.Context) error
+}
+
+// Result is the outcome of one Check.
+type Result struct {
+	Service   string `json:"service"`
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Checks returns a Check for each service configured in cfg. Every check
+// opens a single connection bounded by its context and closes it again,
+// whether or not the service answered.
+func Checks(cfg *config.Config) []Check {
+	return []Check{
+		{Service: ServicePostgres, Ping: func(ctx context.Context) error {
+			conn, err := pgx.Connect(ctx, database.PostgresDSN(cfg.Database))
+			if err != nil {
+				return err
+			}
+			defer conn.Close(context.Background())
+			return conn.Ping(ctx)
+		}},
+		{Service: ServiceRedis, Ping: func(ctx context.Context) error {
+			rdb := database.NewRedisClient(cfg.Redis)
+			defer rdb.Close()
+			return rdb.Ping(ctx).Err()
+		}},
+		{Service: ServiceNATS, Ping: func(ctx context.Context) error {
+			// nats.Connect takes no context, so its dial timeout is what is
+			// left of ctx
+			opts := []nats.Option{nats.NoReconnect()}
+			if deadline, ok := ctx.Deadline(); ok {
+				opts = append(opts, nats.Timeout(time.Until(deadline)))
+			}
+			nc, err := nats.Connect(cfg.NATS.URL, opts...)
+			if err != nil {
+				return err
+			}
+			defer nc.Close()
+			return nc.FlushWithContext(ctx)
+		}},
+		{Service: ServiceInflux, Ping: func(ctx context.Context) error {
+			client := influxdb2.NewClient(cfg.Influx.URL, cfg.Influx.Token)
+			defer client.Close()
+			ok, err := client.Ping(ctx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New("influxdb health check failed")
+			}
+			return nil
+		}},
+	}
+}
+
+// CheckAll checks every service configured in cfg, each within
+// DefaultTimeout.
+func CheckAll(ctx context.Context, cfg *config.Config) []Result {
+	return Run(ctx, Checks(cfg), DefaultTimeout)
+}
+
+// Run runs checks concurrently and returns their results in the same order.
+// A check that has not finished within timeout, or before ctx is done, is
+// reported as failed without waiting for it.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check, timeout)
+		}(i, check)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func run(ctx context.Context, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check.Ping(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("no response within %v", timeout)
+		}
+	}
+
+	result := Result{
+		Service:   check.Service,
+		OK:        err == nil,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Healthy reports whether every check succeeded.
+func Healthy(results []Result) bool {
+	for _, r := range results {
+		if !r.OK {
+			return false
+		}
+	}
+	return true
+}
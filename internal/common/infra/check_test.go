@@ -0,0 +1,197 @@
+package infra_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/infra"
+)
+
+func ok(service string) infra.Check {
+	return infra.Check{Service: service, Ping: func(ctx context.Context) error { return nil }}
+}
+
+func failing(service string, err error) infra.Check {
+	return infra.Check{Service: service, Ping: func(ctx context.Context) error { return err }}
+}
+
+// hanging never answers on its own, like a service behind a dropped route.
+func hanging(service string) infra.Check {
+	return infra.Check{Service: service, Ping: func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return ctx.Err()
+	}}
+}
+
+func TestRun_ReportsEachCheckInOrder(t *testing.T) {
+	results := infra.Run(context.Background(), []infra.Check{
+		ok(infra.ServicePostgres),
+		failing(infra.ServiceRedis, errors.New("connection refused")),
+		ok(infra.ServiceNATS),
+	}, time.Second)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, infra.ServicePostgres, results[0].Service)
+	assert.True(t, results[0].OK)
+	assert.Empty(t, results[0].Error)
+
+	assert.Equal(t, infra.ServiceRedis, results[1].Service)
+	assert.False(t, results[1].OK)
+	assert.Equal(t, "connection refused", results[1].Error)
+
+	assert.True(t, results[2].OK)
+	assert.False(t, infra.Healthy(results))
+}
+
+func TestRun_TimesOutHangingCheck(t *testing.T) {
+	start := time.Now()
+	results := infra.Run(context.Background(), []infra.Check{
+		hanging(infra.ServiceInflux),
+		ok(infra.ServiceNATS),
+	}, 50*time.Millisecond)
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "Run does not wait for the hanging check")
+	assert.False(t, results[0].OK)
+	assert.Contains(t, results[0].Error, "no response within 50ms")
+	assert.True(t, results[1].OK)
+}
+
+// closedAddr returns a local address nothing listens on.
+func closedAddr(t *testing.T) (string, int) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().(*net.TCPAddr)
+	require.NoError(t, l.Close())
+	return addr.IP.String(), addr.Port
+}
+
+func TestCheckAll_UnreachableServices(t *testing.T) {
+	host, port := closedAddr(t)
+	hostPort := net.JoinHostPort(host, strconv.Itoa(port))
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Host: host, Port: port, User: "nms", DBName: "nms", SSLMode: "disable"},
+		Redis:    config.RedisConfig{Addr: hostPort},
+		NATS:     config.NATSConfig{URL: "nats://" + hostPort},
+		Influx:   config.InfluxConfig{URL: "http://" + hostPort},
+	}
+
+	results := infra.CheckAll(context.Background(), cfg)
+
+	require.Len(t, results, 4)
+	services := make([]string, len(results))
+	for i, r := range results {
+		services[i] = r.Service
+		assert.False(t, r.OK, r.Service)
+		assert.NotEmpty(t, r.Error, r.Service)
+	}
+	assert.Equal(t, []string{infra.ServicePostgres, infra.ServiceRedis, infra.ServiceNATS, infra.ServiceInflux}, services)
+	assert.False(t, infra.Healthy(results))
+}
+
+// silentListener accepts connections and never answers. It reports how
+// many were accepted and how many the client has closed since.
+func silentListener(t *testing.T) (addr string, accepted, closed func() int) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	var mu sync.Mutex
+	var nAccepted, nClosed int
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			nAccepted++
+			mu.Unlock()
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+				mu.Lock()
+				nClosed++
+				mu.Unlock()
+			}()
+		}
+	}()
+	count := func(n *int) func() int {
+		return func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return *n
+		}
+	}
+	return l.Addr().String(), count(&nAccepted), count(&nClosed)
+}
+
+func TestChecks_CloseConnectionsToSilentServices(t *testing.T) {
+	addr, accepted, closed := silentListener(t)
+	host, portStr, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Host: host, Port: port, User: "nms", DBName: "nms", SSLMode: "disable"},
+		Redis:    config.RedisConfig{Addr: addr},
+		NATS:     config.NATSConfig{URL: "nats://" + addr},
+		Influx:   config.InfluxConfig{URL: "http://" + addr},
+	}
+
+	results := infra.Run(context.Background(), infra.Checks(cfg), 200*time.Millisecond)
+	for _, r := range results {
+		assert.False(t, r.OK, r.Service)
+	}
+
+	assert.Eventually(t, func() bool {
+		return accepted() >= len(results) && closed() == accepted()
+	}, 5*time.Second, 20*time.Millisecond, "every connection is closed once its check gives up")
+}
+
+func TestReadyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		checks []infra.Check
+		code   int
+		status string
+	}{
+		{"all reachable", []infra.Check{ok(infra.ServicePostgres), ok(infra.ServiceNATS)}, http.StatusOK, "ready"},
+		{"one down", []infra.Check{ok(infra.ServicePostgres), failing(infra.ServiceNATS, errors.New("no servers available"))}, http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/health/ready", infra.ReadyHandler(tt.checks, time.Second))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+			require.Equal(t, tt.code, w.Code)
+
+			var resp struct {
+				Status   string         `json:"status"`
+				Services []infra.Result `json:"services"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.status, resp.Status)
+			assert.Len(t, resp.Services, len(tt.checks))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package infra
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadyHandler serves GET /health/ready. It runs checks on every request and
+// responds 200 when all services are reachable and 503 otherwise, listing
+// the result of each check.
+func ReadyHandler(checks []Check, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		results := Run(c.Request.Context(), checks, timeout)
+
+		status, code := "ready", http.StatusOK
+		if !Healthy(results) {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+		c.JSON(code, gin.H{
+			"status":   status,
+			"services": results,
+		})
+	}
+}
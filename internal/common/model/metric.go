@@ -2,6 +2,9 @@ package model
 
 import "time"
 
+// ValueError is the Values key carrying a poll error message, if any
+const ValueError = "error"
+
 // Metric represents a data point collected from a device
 type Metric struct {
 	DeviceID   string                 `json:"device_id"`
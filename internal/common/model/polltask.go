@@ -4,9 +4,22 @@ import "time"
 
 // PollTask represents a task to poll a specific device
 type PollTask struct {
-	DeviceID   string      `json:"device_id"`
-	IPAddress  string      `json:"ip_address"`
-	DeviceType string      `json:"device_type"`
-	Protocol   string      `json:"protocol"`
-	Timestamp  time.Time   `json:"timestamp"`
+	DeviceID   string    `json:"device_id"`
+	IPAddress  string    `json:"ip_address"`
+	DeviceType string    `json:"device_type"`
+	Protocol   string    `json:"protocol"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// ExpiresAt is when the device's next poll is due. A task still
+	// undelivered by then is superseded and dropped by the worker.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	// Priority of the device; workers with a backlog poll higher first.
+	Priority int `json:"priority,omitempty"`
+
+	// SNMPCommunity is the device's SNMP community for protocols that poll
+	// over SNMP, e.g. OLTs. Empty means the client default. It is encrypted
+	// with security.encryption.key when one is set, since tasks are kept in
+	// the poll task stream.
+	SNMPCommunity string `json:"snmp_community,omitempty"`
 }
@@ -0,0 +1,312 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/yourorg/nms-go/internal/common/config"
+)
+
+// JetStream streams, each holding one subject.
+const (
+	PollTasksStream = "NMS_POLL_TASKS"
+	MetricsStream   = "NMS_METRICS"
+)
+
+// streamSubjects maps each stream to its subject; they are the subjects the
+// collector publishes poll tasks on and workers publish metrics on.
+var streamSubjects = map[string]string{
+	PollTasksStream: "nms.poll.tasks",
+	MetricsStream:   "nms.metrics",
+}
+
+// Defaults for a JetStreamConfig left unset.
+const (
+	defaultStreamMaxAge    = 24 * time.Hour
+	defaultMetricsMaxBytes = 1 << 30 // 1 GiB
+	defaultTaskMaxAge      = 5 * time.Minute
+	defaultAckWait         = 2 * time.Minute
+	defaultMaxDeliver      = 5
+)
+
+var (
+	// ErrNoStream is returned when subscribing to a subject no stream holds.
+	ErrNoStream = errors.New("no jetstream stream for subject")
+
+	// ErrConsumerMismatch is returned when a queue group's durable consumer
+	// exists as a push consumer and is subscribed to for pulling, or the
+	// other way round. Delete the consumer to switch.
+	ErrConsumerMismatch = errors.New("jetstream consumer exists with another delivery mode")
+)
+
+// JetStream is the part of nats.JetStreamContext used by JetStreamConn.
+type JetStream interface {
+	Publish(subj string, data []byte, opts ...nats.PubOpt) (*nats.PubAck, error)
+	Subscribe(subj string, cb nats.MsgHandler, opts ...nats.SubOpt) (*nats.Subscription, error)
+	QueueSubscribe(subj, queue string, cb nats.MsgHandler, opts ...nats.SubOpt) (*nats.Subscription, error)
+	PullSubscribe(subj, durable string, opts ...nats.SubOpt) (*nats.Subscription, error)
+	StreamInfo(stream string, opts ...nats.JSOpt) (*nats.StreamInfo, error)
+	AddStream(cfg *nats.StreamConfig, opts ...nats.JSOpt) (*nats.StreamInfo, error)
+	UpdateStream(cfg *nats.StreamConfig, opts ...nats.JSOpt) (*nats.StreamInfo, error)
+	ConsumerInfo(stream, name string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error)
+	AddConsumer(stream string, cfg *nats.ConsumerConfig, opts ...nats.JSOpt) (*nats.ConsumerInfo, error)
+	UpdateConsumer(stream string, cfg *nats.ConsumerConfig, opts ...nats.JSOpt) (*nats.ConsumerInfo, error)
+}
+
+var _ JetStream = (nats.JetStreamContext)(nil)
+
+// PullSubscription hands out messages only when asked for them.
+type PullSubscription interface {
+	// Fetch returns up to batch messages, waiting for at least one until
+	// the nats.MaxWait option; nats.ErrTimeout means none arrived.
+	Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error)
+	Unsubscribe() error
+}
+
+var _ PullSubscription = (*nats.Subscription)(nil)
+
+// PullConn is implemented by a Conn whose queue groups can pull messages as
+// they have capacity, instead of having every message pushed to them.
+type PullConn interface {
+	PullSubscribe(subj, queue string) (PullSubscription, error)
+}
+
+var _ PullConn = (*JetStreamConn)(nil)
+
+// JetStreamConn is a Conn over JetStream streams with at-least-once
+// delivery. Publish returns once the stream has stored the message.
+// QueueSubscribe and PullSubscribe consume through a durable consumer named
+// after the queue group, which keeps its place across restarts; Subscribe
+// through an ephemeral one that only sees new messages. Handlers must
+// acknowledge each message with Ack, or it is redelivered after AckWait.
+type JetStreamConn struct {
+	js  JetStream
+	cfg config.JetStreamConfig
+}
+
+var _ Conn = (*JetStreamConn)(nil)
+
+// NewConn returns nc itself, or a JetStreamConn over it when JetStream is
+// enabled.
+func NewConn(nc *nats.Conn, cfg config.NATSConfig) (Conn, error) {
+	if !cfg.JetStream.Enabled {
+		return nc, nil
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+	return NewJetStreamConn(js, cfg.JetStream)
+}
+
+// NewJetStreamConn creates the streams, or updates their limits, and
+// returns a JetStreamConn publishing to them. Poll tasks are kept for
+// cfg.TaskMaxAge only, so workers back from an outage do not work through
+// tasks long superseded; metrics for cfg.MaxAge, up to cfg.MetricsMaxBytes.
+func NewJetStreamConn(js JetStream, cfg config.JetStreamConfig) (*JetStreamConn, error) {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaultStreamMaxAge
+	}
+	if cfg.MetricsMaxBytes <= 0 {
+		cfg.MetricsMaxBytes = defaultMetricsMaxBytes
+	}
+	if cfg.TaskMaxAge <= 0 {
+		cfg.TaskMaxAge = defaultTaskMaxAge
+	}
+	if cfg.AckWait <= 0 {
+		cfg.AckWait = defaultAckWait
+	}
+	if cfg.MaxDeliver == 0 {
+		cfg.MaxDeliver = defaultMaxDeliver
+	}
+
+	c := &JetStreamConn{js: js, cfg: cfg}
+	for name, subject := range streamSubjects {
+		if err := c.ensureStream(name, subject); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *JetStreamConn) ensureStream(name, subject string) error {
+	want := &nats.StreamConfig{
+		Name:      name,
+		Subjects:  []string{subject},
+		Retention: nats.LimitsPolicy,
+		Storage:   nats.FileStorage,
+		MaxAge:    c.cfg.MaxAge,
+		MaxBytes:  -1,
+	}
+	switch name {
+	case PollTasksStream:
+		want.MaxAge = c.cfg.TaskMaxAge
+	case MetricsStream:
+		want.MaxBytes = c.cfg.MetricsMaxBytes
+	}
+
+	info, err := c.js.StreamInfo(name)
+	switch {
+	case errors.Is(err, nats.ErrStreamNotFound):
+		if _, err := c.js.AddStream(want); err != nil {
+			return fmt.Errorf("failed to create stream %s: %w", name, err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to look up stream %s: %w", name, err)
+	}
+
+	if info.Config.MaxAge != want.MaxAge || info.Config.MaxBytes != want.MaxBytes {
+		updated := info.Config
+		updated.MaxAge = want.MaxAge
+		updated.MaxBytes = want.MaxBytes
+		if _, err := c.js.UpdateStream(&updated); err != nil {
+			return fmt.Errorf("failed to update stream %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (c *JetStreamConn) Publish(subj string, data []byte) error {
+	_, err := c.js.Publish(subj, data)
+	return err
+}
+
+func (c *JetStreamConn) Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return c.js.Subscribe(subj, cb,
+		nats.DeliverNew(),
+		nats.ManualAck(),
+		nats.AckWait(c.cfg.AckWait),
+		nats.MaxDeliver(c.cfg.MaxDeliver),
+	)
+}
+
+// QueueSubscribe binds to the durable consumer of queue, creating it first
+// if needed. It is created here rather than by the subscription, which
+// would delete it again on Unsubscribe.
+func (c *JetStreamConn) QueueSubscribe(subj, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	stream, err := streamFor(subj)
+	if err != nil {
+		return nil, err
+	}
+	durable := durableName(queue)
+	consumer := c.consumerConfig(durable, subj)
+	consumer.DeliverSubject = nats.NewInbox()
+	consumer.DeliverGroup = queue
+	if err := c.ensureConsumer(stream, consumer); err != nil {
+		return nil, err
+	}
+	return c.js.QueueSubscribe(subj, queue, cb, nats.Bind(stream, durable), nats.ManualAck())
+}
+
+// PullSubscribe binds to the durable pull consumer of queue, creating it
+// first if needed. Members of the queue share it, each fetching only as many
+// messages as it can handle.
+func (c *JetStreamConn) PullSubscribe(subj, queue string) (PullSubscription, error) {
+	stream, err := streamFor(subj)
+	if err != nil {
+		return nil, err
+	}
+	durable := durableName(queue)
+	if err := c.ensureConsumer(stream, c.consumerConfig(durable, subj)); err != nil {
+		return nil, err
+	}
+	return c.js.PullSubscribe(subj, durable, nats.Bind(stream, durable), nats.ManualAck())
+}
+
+// consumerConfig is a durable pull consumer of subj; push consumers add a
+// deliver subject.
+func (c *JetStreamConn) consumerConfig(durable, subj string) *nats.ConsumerConfig {
+	return &nats.ConsumerConfig{
+		Durable:       durable,
+		DeliverPolicy: nats.DeliverNewPolicy,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       c.cfg.AckWait,
+		MaxDeliver:    c.cfg.MaxDeliver,
+		FilterSubject: subj,
+	}
+}
+
+func (c *JetStreamConn) ensureConsumer(stream string, want *nats.ConsumerConfig) error {
+	durable := want.Durable
+	info, err := c.js.ConsumerInfo(stream, durable)
+	switch {
+	case errors.Is(err, nats.ErrConsumerNotFound):
+		if _, err := c.js.AddConsumer(stream, want); err != nil {
+			return fmt.Errorf("failed to create consumer %s on %s: %w", durable, stream, err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to look up consumer %s on %s: %w", durable, stream, err)
+	}
+
+	// A push consumer cannot be turned into a pull consumer or back
+	if (info.Config.DeliverSubject == "") != (want.DeliverSubject == "") {
+		return fmt.Errorf("%w: %s on %s", ErrConsumerMismatch, durable, stream)
+	}
+
+	if info.Config.AckWait != c.cfg.AckWait || info.Config.MaxDeliver != c.cfg.MaxDeliver {
+		updated := info.Config
+		updated.AckWait = c.cfg.AckWait
+		updated.MaxDeliver = c.cfg.MaxDeliver
+		if _, err := c.js.UpdateConsumer(stream, &updated); err != nil {
+			return fmt.Errorf("failed to update consumer %s on %s: %w", durable, stream, err)
+		}
+	}
+	return nil
+}
+
+func streamFor(subj string) (string, error) {
+	for name, subject := range streamSubjects {
+		if subject == subj {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%w %s", ErrNoStream, subj)
+}
+
+// durableName turns a queue group into a consumer name, which may not
+// contain '.', '*' or '>'.
+func durableName(queue string) string {
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(queue)
+}
+
+// Ack acknowledges a JetStream message so it is not delivered again. Core
+// NATS messages need no acknowledgment and are ignored, as are the other
+// acknowledgment helpers.
+func Ack(msg *nats.Msg) {
+	acknowledge(msg, "ack", (*nats.Msg).Ack)
+}
+
+// Nak asks for msg to be delivered again now, e.g. to another member of
+// the queue group.
+func Nak(msg *nats.Msg) {
+	acknowledge(msg, "nak", (*nats.Msg).Nak)
+}
+
+// Term stops the delivery of a message that can never be handled, e.g. one
+// that cannot be decoded.
+func Term(msg *nats.Msg) {
+	acknowledge(msg, "term", (*nats.Msg).Term)
+}
+
+// InProgress restarts the AckWait of a message still being handled.
+func InProgress(msg *nats.Msg) {
+	acknowledge(msg, "in-progress", (*nats.Msg).InProgress)
+}
+
+func acknowledge(msg *nats.Msg, what string, fn func(*nats.Msg, ...nats.AckOpt) error) {
+	if msg == nil {
+		return
+	}
+	if _, err := msg.Metadata(); err != nil {
+		return // not a JetStream message
+	}
+	if err := fn(msg); err != nil && !errors.Is(err, nats.ErrMsgAlreadyAckd) {
+		log.Printf("Error sending %s for message on %s: %v", what, msg.Subject, err)
+	}
+}
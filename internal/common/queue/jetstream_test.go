@@ -0,0 +1,203 @@
+package queue_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/queue"
+)
+
+// fakeJetStream keeps streams and consumers in memory and records calls.
+type fakeJetStream struct {
+	streams   map[string]*nats.StreamConfig
+	consumers map[string]*nats.ConsumerConfig
+	published []string
+	queueSubs []string
+	pullSubs  []string
+	updated   []string
+}
+
+func newFakeJetStream() *fakeJetStream {
+	return &fakeJetStream{streams: map[string]*nats.StreamConfig{}, consumers: map[string]*nats.ConsumerConfig{}}
+}
+
+func (f *fakeJetStream) Publish(subj string, data []byte, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	f.published = append(f.published, subj)
+	return &nats.PubAck{}, nil
+}
+
+func (f *fakeJetStream) Subscribe(subj string, cb nats.MsgHandler, opts ...nats.SubOpt) (*nats.Subscription, error) {
+	return &nats.Subscription{}, nil
+}
+
+func (f *fakeJetStream) QueueSubscribe(subj, queue string, cb nats.MsgHandler, opts ...nats.SubOpt) (*nats.Subscription, error) {
+	f.queueSubs = append(f.queueSubs, subj+"/"+queue)
+	return &nats.Subscription{}, nil
+}
+
+func (f *fakeJetStream) PullSubscribe(subj, durable string, opts ...nats.SubOpt) (*nats.Subscription, error) {
+	f.pullSubs = append(f.pullSubs, subj+"/"+durable)
+	return &nats.Subscription{}, nil
+}
+
+func (f *fakeJetStream) StreamInfo(stream string, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	cfg, ok := f.streams[stream]
+	if !ok {
+		return nil, nats.ErrStreamNotFound
+	}
+	return &nats.StreamInfo{Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) AddStream(cfg *nats.StreamConfig, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	f.streams[cfg.Name] = cfg
+	return &nats.StreamInfo{Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) UpdateStream(cfg *nats.StreamConfig, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	f.updated = append(f.updated, cfg.Name)
+	return f.AddStream(cfg)
+}
+
+func (f *fakeJetStream) ConsumerInfo(stream, name string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	cfg, ok := f.consumers[stream+"/"+name]
+	if !ok {
+		return nil, nats.ErrConsumerNotFound
+	}
+	return &nats.ConsumerInfo{Stream: stream, Name: name, Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) AddConsumer(stream string, cfg *nats.ConsumerConfig, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	f.consumers[stream+"/"+cfg.Durable] = cfg
+	return &nats.ConsumerInfo{Stream: stream, Name: cfg.Durable, Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) UpdateConsumer(stream string, cfg *nats.ConsumerConfig, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	f.updated = append(f.updated, stream+"/"+cfg.Durable)
+	return f.AddConsumer(stream, cfg)
+}
+
+func TestNewJetStreamConn_CreatesStreams(t *testing.T) {
+	js := newFakeJetStream()
+
+	_, err := queue.NewJetStreamConn(js, config.JetStreamConfig{MaxAge: 6 * time.Hour, TaskMaxAge: time.Minute, MetricsMaxBytes: 1 << 20})
+	require.NoError(t, err)
+
+	require.Contains(t, js.streams, queue.PollTasksStream)
+	require.Contains(t, js.streams, queue.MetricsStream)
+	tasks := js.streams[queue.PollTasksStream]
+	assert.Equal(t, []string{"nms.poll.tasks"}, tasks.Subjects)
+	assert.Equal(t, time.Minute, tasks.MaxAge, "stale tasks are not kept")
+	assert.Equal(t, int64(-1), tasks.MaxBytes)
+	assert.Equal(t, nats.FileStorage, tasks.Storage)
+	metrics := js.streams[queue.MetricsStream]
+	assert.Equal(t, []string{"nms.metrics"}, metrics.Subjects)
+	assert.Equal(t, 6*time.Hour, metrics.MaxAge)
+	assert.Equal(t, int64(1<<20), metrics.MaxBytes)
+	assert.Empty(t, js.updated)
+}
+
+func TestNewJetStreamConn_UpdatesRetention(t *testing.T) {
+	js := newFakeJetStream()
+	js.streams[queue.MetricsStream] = &nats.StreamConfig{Name: queue.MetricsStream, Subjects: []string{"nms.metrics"}, MaxAge: time.Hour, MaxBytes: -1, Replicas: 3}
+	js.streams[queue.PollTasksStream] = &nats.StreamConfig{Name: queue.PollTasksStream, Subjects: []string{"nms.poll.tasks"}, MaxAge: 5 * time.Minute, MaxBytes: -1}
+
+	_, err := queue.NewJetStreamConn(js, config.JetStreamConfig{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{queue.MetricsStream}, js.updated)
+	assert.Equal(t, 24*time.Hour, js.streams[queue.MetricsStream].MaxAge, "the default max age")
+	assert.Equal(t, int64(1<<30), js.streams[queue.MetricsStream].MaxBytes, "the default max bytes")
+	assert.Equal(t, 3, js.streams[queue.MetricsStream].Replicas, "other settings are kept")
+}
+
+func TestJetStreamConn_QueueSubscribeUsesDurableConsumer(t *testing.T) {
+	js := newFakeJetStream()
+	conn, err := queue.NewJetStreamConn(js, config.JetStreamConfig{AckWait: time.Minute, MaxDeliver: 3})
+	require.NoError(t, err)
+
+	_, err = conn.QueueSubscribe("nms.poll.tasks", "nms-workers", func(*nats.Msg) {})
+	require.NoError(t, err)
+
+	consumer := js.consumers[queue.PollTasksStream+"/nms-workers"]
+	require.NotNil(t, consumer)
+	assert.Equal(t, "nms-workers", consumer.DeliverGroup)
+	assert.NotEmpty(t, consumer.DeliverSubject)
+	assert.Equal(t, "nms.poll.tasks", consumer.FilterSubject)
+	assert.Equal(t, nats.AckExplicitPolicy, consumer.AckPolicy)
+	assert.Equal(t, time.Minute, consumer.AckWait)
+	assert.Equal(t, 3, consumer.MaxDeliver)
+
+	// A restarted worker binds to the same consumer
+	deliverSubject := consumer.DeliverSubject
+	_, err = conn.QueueSubscribe("nms.poll.tasks", "nms-workers", func(*nats.Msg) {})
+	require.NoError(t, err)
+	assert.Equal(t, deliverSubject, js.consumers[queue.PollTasksStream+"/nms-workers"].DeliverSubject)
+	assert.Equal(t, []string{"nms.poll.tasks/nms-workers", "nms.poll.tasks/nms-workers"}, js.queueSubs)
+	assert.Empty(t, js.updated)
+}
+
+func TestJetStreamConn_PullSubscribeUsesDurablePullConsumer(t *testing.T) {
+	js := newFakeJetStream()
+	conn, err := queue.NewJetStreamConn(js, config.JetStreamConfig{AckWait: time.Minute, MaxDeliver: 3})
+	require.NoError(t, err)
+
+	_, err = conn.PullSubscribe("nms.poll.tasks", "nms-workers")
+	require.NoError(t, err)
+
+	consumer := js.consumers[queue.PollTasksStream+"/nms-workers"]
+	require.NotNil(t, consumer)
+	assert.Empty(t, consumer.DeliverSubject, "a pull consumer")
+	assert.Empty(t, consumer.DeliverGroup)
+	assert.Equal(t, "nms.poll.tasks", consumer.FilterSubject)
+	assert.Equal(t, nats.AckExplicitPolicy, consumer.AckPolicy)
+	assert.Equal(t, time.Minute, consumer.AckWait)
+	assert.Equal(t, 3, consumer.MaxDeliver)
+	assert.Equal(t, []string{"nms.poll.tasks/nms-workers"}, js.pullSubs)
+}
+
+func TestJetStreamConn_PullSubscribeToPushConsumer(t *testing.T) {
+	js := newFakeJetStream()
+	conn, err := queue.NewJetStreamConn(js, config.JetStreamConfig{})
+	require.NoError(t, err)
+
+	_, err = conn.QueueSubscribe("nms.poll.tasks", "nms-workers", func(*nats.Msg) {})
+	require.NoError(t, err)
+
+	_, err = conn.PullSubscribe("nms.poll.tasks", "nms-workers")
+	assert.ErrorIs(t, err, queue.ErrConsumerMismatch)
+	assert.Empty(t, js.pullSubs)
+}
+
+func TestJetStreamConn_QueueSubscribeUnknownSubject(t *testing.T) {
+	conn, err := queue.NewJetStreamConn(newFakeJetStream(), config.JetStreamConfig{})
+	require.NoError(t, err)
+
+	_, err = conn.QueueSubscribe("nms.events", "group", func(*nats.Msg) {})
+	assert.True(t, errors.Is(err, queue.ErrNoStream), err)
+}
+
+func TestJetStreamConn_Publish(t *testing.T) {
+	js := newFakeJetStream()
+	conn, err := queue.NewJetStreamConn(js, config.JetStreamConfig{})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Publish("nms.metrics", []byte(`{}`)))
+	assert.Equal(t, []string{"nms.metrics"}, js.published)
+}
+
+func TestAck_IgnoresCoreNATSMessages(t *testing.T) {
+	msg := &nats.Msg{Subject: "nms.metrics", Data: []byte(`{}`)}
+
+	assert.NotPanics(t, func() {
+		queue.Ack(msg)
+		queue.Nak(msg)
+		queue.Term(msg)
+		queue.InProgress(msg)
+		queue.Ack(nil)
+	})
+}
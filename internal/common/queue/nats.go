@@ -7,6 +7,16 @@ import (
 	"github.com/yourorg/nms-go/internal/common/config"
 )
 
+// Conn is the subset of *nats.Conn used by the services, so they can be
+// tested without a NATS server.
+type Conn interface {
+	Publish(subj string, data []byte) error
+	Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error)
+	QueueSubscribe(subj, queue string, cb nats.MsgHandler) (*nats.Subscription, error)
+}
+
+var _ Conn = (*nats.Conn)(nil)
+
 func NewNATSConnection(cfg config.NATSConfig) (*nats.Conn, error) {
 	nc, err := nats.Connect(cfg.URL)
 	if err != nil {
@@ -0,0 +1,117 @@
+// Package rates turns the byte counters of successive interface samples into
+// bits per second, for every place interface traffic is polled.
+package rates
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// MaxGap is the longest gap between two samples a rate is computed over.
+// Beyond it a 32-bit counter may have wrapped more than once, so the older
+// sample is only replaced. Samples older than this are also evicted, which
+// drops interfaces and devices that are no longer polled.
+const MaxGap = 15 * time.Minute
+
+// Rate is the traffic of an interface between two counter samples.
+type Rate struct {
+	RxBps float64
+	TxBps float64
+}
+
+type sample struct {
+	at       time.Time
+	bytesIn  uint64
+	bytesOut uint64
+	rate     Rate
+	hasRate  bool
+}
+
+// Calculator keeps the last counter sample of every key, e.g. a device and
+// interface, and is safe for concurrent use.
+type Calculator struct {
+	mu        sync.Mutex
+	last      map[string]*sample
+	lastSweep time.Time
+}
+
+// New creates an empty Calculator.
+func New() *Calculator {
+	return &Calculator{last: make(map[string]*sample)}
+}
+
+// Update records the counters of key at the given time and returns the rate
+// since its previous sample. bits is the counter width: 32 for counters that
+// wrap at 2^32, 0 or 64 otherwise. It reports false for the first sample,
+// after a gap over MaxGap and after a counter reset. The same sample seen
+// again, e.g. a cached poll, returns the rate it got the first time; an older
+// one is ignored.
+func (c *Calculator) Update(key string, at time.Time, bytesIn, bytesOut uint64, bits int) (Rate, bool) {
+	cur := &sample{at: at, bytesIn: bytesIn, bytesOut: bytesOut}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, ok := c.last[key]
+	if ok && !cur.at.After(prev.at) {
+		if cur.at.Equal(prev.at) {
+			return prev.rate, prev.hasRate
+		}
+		return Rate{}, false
+	}
+	c.last[key] = cur
+	c.sweep(cur.at)
+
+	if !ok || cur.at.Sub(prev.at) > MaxGap {
+		return Rate{}, false
+	}
+	in, okIn := counterDelta(prev.bytesIn, cur.bytesIn, bits)
+	out, okOut := counterDelta(prev.bytesOut, cur.bytesOut, bits)
+	if !okIn || !okOut {
+		return Rate{}, false
+	}
+
+	seconds := cur.at.Sub(prev.at).Seconds()
+	cur.rate = Rate{
+		RxBps: float64(in) * 8 / seconds,
+		TxBps: float64(out) * 8 / seconds,
+	}
+	cur.hasRate = true
+	return cur.rate, true
+}
+
+// Len returns the number of keys with a stored sample.
+func (c *Calculator) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.last)
+}
+
+// sweep evicts the samples not updated within MaxGap of now, at most once
+// per MaxGap. Callers must hold c.mu.
+func (c *Calculator) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < MaxGap {
+		return
+	}
+	c.lastSweep = now
+	for key, s := range c.last {
+		if now.Sub(s.at) > MaxGap {
+			delete(c.last, key)
+		}
+	}
+}
+
+// counterDelta returns how far a counter of the given width advanced from
+// prev to cur. A decrease of a 32-bit counter (ifInOctets) is taken as a
+// wrap; a decrease of a 64-bit counter cannot be a wrap in practice, so it is
+// a reset, e.g. a device reboot, and reports false.
+func counterDelta(prev, cur uint64, bits int) (uint64, bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	if bits == 32 && prev <= math.MaxUint32 {
+		return math.MaxUint32 - prev + cur + 1, true
+	}
+	return 0, false
+}
@@ -0,0 +1,25 @@
+package rates_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/rates"
+)
+
+func TestCalculator_CounterWidth(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := rates.New()
+
+	c.Update("sw-1/1", t0, math.MaxUint32-999, 0, 32)
+	rate, ok := c.Update("sw-1/1", t0.Add(time.Second), 1000, 0, 32)
+	require.True(t, ok, "a 32-bit counter wraps")
+	assert.Equal(t, 2000.0*8, rate.RxBps)
+
+	c.Update("sw-1/2", t0, 1<<20, 0, 64)
+	_, ok = c.Update("sw-1/2", t0.Add(time.Second), 1000, 0, 64)
+	assert.False(t, ok, "a 64-bit counter going down is a reset, however small")
+}
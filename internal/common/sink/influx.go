@@ -0,0 +1,34 @@
+package sink
+
+import (
+	"context"
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+)
+
+// InfluxSink writes metric points to an InfluxDB bucket.
+type InfluxSink struct {
+	client   influxdb2.Client
+	writeAPI api.WriteAPIBlocking
+}
+
+// NewInfluxSink creates an InfluxSink. The sink takes ownership of the client
+// and closes it on Close.
+func NewInfluxSink(client influxdb2.Client, org, bucket string) *InfluxSink {
+	return &InfluxSink{
+		client:   client,
+		writeAPI: client.WriteAPIBlocking(org, bucket),
+	}
+}
+
+func (s *InfluxSink) Write(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	p := influxdb2.NewPoint(measurement, tags, fields, ts)
+	return s.writeAPI.WritePoint(ctx, p)
+}
+
+func (s *InfluxSink) Close() error {
+	s.client.Close()
+	return nil
+}
@@ -0,0 +1,50 @@
+// Package sink provides backend-agnostic destinations for time-series metrics.
+// InfluxDB is the default backend; other sinks can be selected via config.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/yourorg/nms-go/internal/common/config"
+)
+
+const (
+	// BackendInflux writes metrics to InfluxDB (default).
+	BackendInflux = "influx"
+	// BackendStdout writes metrics to stdout in line protocol.
+	BackendStdout = "stdout"
+	// BackendNoop discards metrics.
+	BackendNoop = "noop"
+)
+
+// MetricSink defines a destination for metric points.
+type MetricSink interface {
+	Write(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error
+	Close() error
+}
+
+// New creates the MetricSink selected by cfg.Metrics.Sink.
+// If the Influx backend is selected but its settings are incomplete, New logs
+// a warning and returns a NoopSink instead of a client whose writes would fail.
+func New(cfg *config.Config) (MetricSink, error) {
+	switch cfg.Metrics.Sink {
+	case "", BackendInflux:
+		if err := cfg.Influx.Validate(); err != nil {
+			log.Printf("WARNING: %v; metrics will be discarded (set metrics.sink to silence this)", err)
+			return NewNoopSink(), nil
+		}
+		client := influxdb2.NewClient(cfg.Influx.URL, cfg.Influx.Token)
+		return NewInfluxSink(client, cfg.Influx.Org, cfg.Influx.Bucket), nil
+	case BackendStdout:
+		return NewStdoutSink(os.Stdout), nil
+	case BackendNoop:
+		return NewNoopSink(), nil
+	default:
+		return nil, fmt.Errorf("unknown metric sink %q", cfg.Metrics.Sink)
+	}
+}
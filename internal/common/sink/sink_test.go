@@ -0,0 +1,87 @@
+package sink_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/sink"
+)
+
+func TestStdoutSink_WritesLineProtocol(t *testing.T) {
+	var buf bytes.Buffer
+	s := sink.NewStdoutSink(&buf)
+
+	ts := time.Unix(1700000000, 0)
+	err := s.Write(context.Background(), "device_poll",
+		map[string]string{"ip_address": "10.0.0.1", "device_id": "dev-1"},
+		map[string]interface{}{"success": true, "rtt_ms": 1.5, "count": 3, "name": "core"},
+		ts,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"device_poll,device_id=dev-1,ip_address=10.0.0.1 count=3i,name=\"core\",rtt_ms=1.5,success=true 1700000000000000000\n",
+		buf.String(),
+	)
+}
+
+func TestNew_SelectsBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "default is influx", backend: "", want: &sink.InfluxSink{}},
+		{name: "influx", backend: sink.BackendInflux, want: &sink.InfluxSink{}},
+		{name: "stdout", backend: sink.BackendStdout, want: &sink.StdoutSink{}},
+		{name: "noop", backend: sink.BackendNoop, want: &sink.NoopSink{}},
+		{name: "unknown", backend: "graphite", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Influx: config.InfluxConfig{
+					URL: "http://localhost:8086", Token: "t0ken", Org: "nms", Bucket: "metrics",
+				},
+				Metrics: config.MetricsConfig{Sink: tt.backend},
+			}
+
+			s, err := sink.New(cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer s.Close()
+			assert.IsType(t, tt.want, s)
+		})
+	}
+}
+
+func TestNew_IncompleteInfluxConfigFallsBackToNoop(t *testing.T) {
+	tests := []struct {
+		name   string
+		influx config.InfluxConfig
+	}{
+		{"empty token", config.InfluxConfig{URL: "http://localhost:8086", Org: "nms", Bucket: "metrics"}},
+		{"whitespace token", config.InfluxConfig{URL: "http://localhost:8086", Token: "  ", Org: "nms", Bucket: "metrics"}},
+		{"placeholder token", config.InfluxConfig{URL: "http://localhost:8086", Token: "my-token", Org: "nms", Bucket: "metrics"}},
+		{"missing bucket", config.InfluxConfig{URL: "http://localhost:8086", Token: "t0ken", Org: "nms"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := sink.New(&config.Config{Influx: tt.influx})
+			require.NoError(t, err)
+			assert.IsType(t, &sink.NoopSink{}, s)
+			assert.NoError(t, s.Write(context.Background(), "m", nil, map[string]interface{}{"v": 1}, time.Now()))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StdoutSink writes metric points as InfluxDB line protocol to an io.Writer.
+// It is useful for debugging and for piping metrics into other collectors.
+type StdoutSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewStdoutSink creates a StdoutSink writing to out.
+func NewStdoutSink(out io.Writer) *StdoutSink {
+	return &StdoutSink{out: out}
+}
+
+func (s *StdoutSink) Write(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	line := formatLine(measurement, tags, fields, ts)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintln(s.out, line)
+	return err
+}
+
+func (s *StdoutSink) Close() error {
+	return nil
+}
+
+// formatLine renders a point in line protocol with tags and fields sorted by key.
+func formatLine(measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) string {
+	var b strings.Builder
+	b.WriteString(measurement)
+
+	for _, k := range sortedKeys(tags) {
+		fmt.Fprintf(&b, ",%s=%s", k, tags[k])
+	}
+
+	fieldKeys := make([]string, 0, len(fields))
+	for k := range fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+
+	for i, k := range fieldKeys {
+		sep := ","
+		if i == 0 {
+			sep = " "
+		}
+		fmt.Fprintf(&b, "%s%s=%s", sep, k, formatField(fields[k]))
+	}
+
+	fmt.Fprintf(&b, " %d", ts.UnixNano())
+	return b.String()
+}
+
+func formatField(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", val)
+	case int, int8, int16, int32, int64:
+		return fmt.Sprintf("%di", val)
+	case uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%du", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
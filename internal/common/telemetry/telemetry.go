@@ -0,0 +1,109 @@
+// Package telemetry holds the Prometheus instrumentation shared by the
+// protocol clients and services in go-nms.
+package telemetry
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Connect result label values.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	// ConnectDuration tracks how long it takes to establish a device session,
+	// labelled by protocol (mikrotik_api, snmp, ssh).
+	ConnectDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nms",
+		Name:      "device_connect_duration_seconds",
+		Help:      "Time taken to establish a session with a device, by protocol.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"protocol"})
+
+	// ConnectTotal counts connect attempts by protocol and result (success/failure).
+	ConnectTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nms",
+		Name:      "device_connect_total",
+		Help:      "Device connect attempts, by protocol and result.",
+	}, []string{"protocol", "result"})
+
+	// CycleDuration tracks how long a scheduled collection cycle takes, from
+	// its tick until every poll in it has finished, labelled by scheduler.
+	CycleDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nms",
+		Name:      "collection_cycle_duration_seconds",
+		Help:      "Time taken by a scheduled collection cycle, by scheduler.",
+		Buckets:   []float64{1, 5, 10, 15, 30, 45, 60, 90, 120, 300},
+	}, []string{"scheduler"})
+
+	// CycleOverrunsTotal counts collection cycles that took longer than their
+	// budget, labelled by scheduler.
+	CycleOverrunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nms",
+		Name:      "collection_cycle_overruns_total",
+		Help:      "Collection cycles that exceeded their time budget, by scheduler.",
+	}, []string{"scheduler"})
+
+	// DisconnectErrorsTotal counts device sessions that failed to close,
+	// labelled by protocol. Each may have left a socket open.
+	DisconnectErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nms",
+		Name:      "device_disconnect_errors_total",
+		Help:      "Device sessions that failed to close, by protocol.",
+	}, []string{"protocol"})
+)
+
+func init() {
+	prometheus.MustRegister(ConnectDuration, ConnectTotal, CycleDuration, CycleOverrunsTotal, DisconnectErrorsTotal)
+}
+
+// NewMetricsServer returns a server exposing the registered metrics at
+// /metrics on addr, for services that have no HTTP server of their own.
+func NewMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
+
+// Disconnecter is a device client or session that can be closed.
+type Disconnecter interface {
+	Disconnect() error
+}
+
+// Disconnect closes client's session to target and records a failure with
+// RecordDisconnect. Use it instead of discarding the error, e.g.
+//
+//	defer telemetry.Disconnect(client, "snmp", ip)
+func Disconnect(client Disconnecter, protocol, target string) error {
+	return RecordDisconnect(protocol, target, client.Disconnect())
+}
+
+// RecordDisconnect logs and counts a failed close of a session to target.
+// A nil err records nothing. It returns err.
+func RecordDisconnect(protocol, target string, err error) error {
+	if err == nil {
+		return nil
+	}
+	DisconnectErrorsTotal.WithLabelValues(protocol).Inc()
+	log.Printf("WARNING: disconnect failed protocol=%s target=%s error=%q", protocol, target, err.Error())
+	return err
+}
+
+// ObserveConnect records the duration and outcome of a connect attempt that
+// started at start. A nil err counts as a success.
+func ObserveConnect(protocol string, start time.Time, err error) {
+	ConnectDuration.WithLabelValues(protocol).Observe(time.Since(start).Seconds())
+
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+	}
+	ConnectTotal.WithLabelValues(protocol, result).Inc()
+}
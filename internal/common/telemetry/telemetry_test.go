@@ -0,0 +1,74 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+)
+
+type fakeClient struct {
+	err    error
+	closed int
+}
+
+func (f *fakeClient) Disconnect() error {
+	f.closed++
+	return f.err
+}
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	return &buf
+}
+
+func disconnectErrors(protocol string) float64 {
+	return testutil.ToFloat64(telemetry.DisconnectErrorsTotal.WithLabelValues(protocol))
+}
+
+func TestDisconnect_FailureIsLoggedAndCounted(t *testing.T) {
+	logs := captureLog(t)
+	before := disconnectErrors("snmp")
+
+	client := &fakeClient{err: errors.New("use of closed network connection")}
+	err := telemetry.Disconnect(client, "snmp", "10.0.0.1")
+
+	assert.EqualError(t, err, "use of closed network connection")
+	assert.Equal(t, 1, client.closed)
+	assert.Equal(t, before+1, disconnectErrors("snmp"))
+	assert.Contains(t, logs.String(), `disconnect failed protocol=snmp target=10.0.0.1 error="use of closed network connection"`)
+}
+
+func TestDisconnect_SuccessRecordsNothing(t *testing.T) {
+	logs := captureLog(t)
+	before := disconnectErrors("mikrotik_api")
+
+	client := &fakeClient{}
+	assert.NoError(t, telemetry.Disconnect(client, "mikrotik_api", "10.0.0.2"))
+
+	assert.Equal(t, 1, client.closed)
+	assert.Equal(t, before, disconnectErrors("mikrotik_api"))
+	assert.Empty(t, logs.String())
+}
+
+func TestNewMetricsServer_ServesConnectMetrics(t *testing.T) {
+	telemetry.ObserveConnect("snmp", time.Now(), nil)
+
+	srv := telemetry.NewMetricsServer(":0")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `nms_device_connect_total{protocol="snmp",result="success"}`)
+	assert.Contains(t, w.Body.String(), "nms_device_connect_duration_seconds_bucket")
+}
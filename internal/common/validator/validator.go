@@ -0,0 +1,116 @@
+// Package validator turns Gin binding errors into readable, per-field
+// messages for the standard {"error": ...} response.
+//
+// Fields are named by their JSON (or form) tag and nested fields are joined
+// with dots, so a bad target IP is reported as "target.ip must be a valid IP
+// address" instead of the raw validator string.
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a failed validation of one request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// Report fields by the names clients send rather than Go field names.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// Fields returns the per-field messages of a binding error, or nil when err
+// is not a validation error (malformed JSON, for example).
+func Fields(err error) []FieldError {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, FieldError{Field: fieldPath(fe), Message: message(fe)})
+	}
+	return fields
+}
+
+// ErrorResponse builds the 400 response body for a binding error. The error
+// is prefixed with context, e.g. "invalid request body", and validation
+// failures are also listed under "fields".
+func ErrorResponse(context string, err error) gin.H {
+	fields := Fields(err)
+	if len(fields) == 0 {
+		return gin.H{"error": context + ": " + err.Error()}
+	}
+
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, f.Field+" "+f.Message)
+	}
+	return gin.H{"error": context + ": " + strings.Join(msgs, "; "), "fields": fields}
+}
+
+// fieldPath drops the top-level struct name from the namespace, leaving
+// e.g. "target.ip".
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return ns
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "ip":
+		return "must be a valid IP address"
+	case "ipv4":
+		return "must be a valid IPv4 address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min":
+		return "must be at least " + sized(fe)
+	case "max":
+		return "must be at most " + sized(fe)
+	default:
+		return fmt.Sprintf("failed the %q check", fe.Tag())
+	}
+}
+
+// sized phrases a min/max bound for the kind of field it applies to.
+func sized(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fe.Param() + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fe.Param() + " items"
+	default:
+		return fe.Param()
+	}
+}
@@ -0,0 +1,82 @@
+package validator_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+type pingRequest struct {
+	Target struct {
+		IP string `json:"ip" binding:"required,ip"`
+	} `json:"target" binding:"required"`
+	Count  int    `json:"count" binding:"omitempty,min=1,max=10"`
+	Driver string `json:"driver" binding:"omitempty,oneof=icmp tcp"`
+}
+
+type errorBody struct {
+	Error  string                 `json:"error"`
+	Fields []validator.FieldError `json:"fields"`
+}
+
+func bind(t *testing.T, body string) errorBody {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/ping", func(c *gin.Context) {
+		var req pingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/ping", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestErrorResponse_Required(t *testing.T) {
+	resp := bind(t, `{"target":{}}`)
+
+	assert.Equal(t, "invalid request body: target.ip is required", resp.Error)
+	assert.Equal(t, []validator.FieldError{{Field: "target.ip", Message: "is required"}}, resp.Fields)
+}
+
+func TestErrorResponse_IP(t *testing.T) {
+	resp := bind(t, `{"target":{"ip":"10.0.0.300"}}`)
+
+	assert.Equal(t, "invalid request body: target.ip must be a valid IP address", resp.Error)
+	assert.NotContains(t, resp.Error, "Key:")
+}
+
+func TestErrorResponse_SeveralFields(t *testing.T) {
+	resp := bind(t, `{"target":{"ip":"10.0.0.1"},"count":50,"driver":"udp"}`)
+
+	assert.Equal(t, []validator.FieldError{
+		{Field: "count", Message: "must be at most 10"},
+		{Field: "driver", Message: "must be one of: icmp, tcp"},
+	}, resp.Fields)
+	assert.Equal(t, "invalid request body: count must be at most 10; driver must be one of: icmp, tcp", resp.Error)
+}
+
+func TestErrorResponse_MalformedJSON(t *testing.T) {
+	resp := bind(t, `{"target":`)
+
+	assert.True(t, strings.HasPrefix(resp.Error, "invalid request body: "), resp.Error)
+	assert.Empty(t, resp.Fields)
+}
@@ -2,6 +2,7 @@ package config_mgt
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
 )
 
 type ConfigHandler struct {
@@ -20,7 +21,7 @@ type ExecuteCommandRequest struct {
 func (h *ConfigHandler) ExecuteCommand(c *gin.Context) {
 	var req ExecuteCommandRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
 		return
 	}
 
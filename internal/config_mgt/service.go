@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/yourorg/nms-go/internal/common/adapter"
+	"github.com/yourorg/nms-go/internal/device/model"
 	"github.com/yourorg/nms-go/internal/device/service"
 )
 
@@ -16,12 +17,14 @@ type ConfigService interface {
 type configService struct {
 	deviceService service.DeviceService
 	sshAdapter    *SSHAdapter
+	telnetAdapter *TelnetAdapter
 }
 
-func NewConfigService(ds service.DeviceService, ssh *SSHAdapter) ConfigService {
+func NewConfigService(ds service.DeviceService, ssh *SSHAdapter, telnet *TelnetAdapter) ConfigService {
 	return &configService{
 		deviceService: ds,
 		sshAdapter:    ssh,
+		telnetAdapter: telnet,
 	}
 }
 
@@ -44,6 +47,14 @@ func (s *configService) ExecuteCommand(ctx context.Context, deviceID, command st
 		return mtAdapter.RunCommandStructured(device.IPAddress, user, password, command)
 	}
 
+	if device.Protocol == model.ProtocolTelnet {
+		output, err := s.telnetAdapter.Execute(device.IPAddress, user, password, command)
+		if err != nil {
+			return output, fmt.Errorf("execution failed: %w", err)
+		}
+		return output, nil
+	}
+
 	// Default to SSH
 	output, err := s.sshAdapter.Execute(device.IPAddress, user, password, command)
 	if err != nil {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+	"github.com/yourorg/nms-go/internal/device/model"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -23,7 +25,9 @@ func (a *SSHAdapter) Execute(ip, user, password, command string) (string, error)
 		Timeout:         5 * time.Second,
 	}
 
+	start := time.Now()
 	client, err := ssh.Dial("tcp", ip+":22", config)
+	telemetry.ObserveConnect(string(model.ProtocolSSH), start, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to dial: %w", err)
 	}
@@ -0,0 +1,52 @@
+package config_mgt
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/worker/protocols/telnet"
+)
+
+// TelnetAdapter runs CLI commands on legacy devices only reachable via Telnet
+type TelnetAdapter struct {
+	port    int
+	timeout time.Duration
+}
+
+func NewTelnetAdapter() *TelnetAdapter {
+	return &TelnetAdapter{
+		port:    telnet.DefaultPort,
+		timeout: 10 * time.Second,
+	}
+}
+
+// NewTelnetAdapterForTest creates a TelnetAdapter targeting a non-standard port
+func NewTelnetAdapterForTest(port int, timeout time.Duration) *TelnetAdapter {
+	return &TelnetAdapter{
+		port:    port,
+		timeout: timeout,
+	}
+}
+
+func (a *TelnetAdapter) Execute(ip, user, password, command string) (string, error) {
+	addr := net.JoinHostPort(ip, strconv.Itoa(a.port))
+	client, err := telnet.Dial(context.Background(), addr, telnet.Config{Timeout: a.timeout})
+	if err != nil {
+		return "", fmt.Errorf("failed to dial: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.Login(user, password); err != nil {
+		return "", fmt.Errorf("failed to login: %w", err)
+	}
+
+	output, err := client.Execute(command)
+	if err != nil {
+		return output, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+// AttachCredentialsRequest is the request body for PUT /api/v1/devices/:id/credentials.
+// A null credentials_id detaches the device's credentials.
+type AttachCredentialsRequest struct {
+	CredentialsID *string `json:"credentials_id"`
+}
+
+type CredentialsHandler struct {
+	service service.CredentialsService
+}
+
+func NewCredentialsHandler(service service.CredentialsService) *CredentialsHandler {
+	return &CredentialsHandler{service: service}
+}
+
+// CreateCredentials handles POST /api/v1/credentials
+func (h *CredentialsHandler) CreateCredentials(c *gin.Context) {
+	var req service.CredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	credentials, err := h.service.CreateCredentials(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, credentials)
+}
+
+// ListCredentials handles GET /api/v1/credentials
+func (h *CredentialsHandler) ListCredentials(c *gin.Context) {
+	credentials, err := h.service.ListCredentials(c.Request.Context())
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"data": credentials, "total": len(credentials)})
+}
+
+// GetCredentials handles GET /api/v1/credentials/:id
+func (h *CredentialsHandler) GetCredentials(c *gin.Context) {
+	credentials, err := h.service.GetCredentials(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeCredentialsError(c, err)
+		return
+	}
+
+	c.JSON(200, credentials)
+}
+
+// UpdateCredentials handles PUT /api/v1/credentials/:id
+//
+// Only the fields present in the body change, so a password can be rotated
+// without resending the other secrets.
+func (h *CredentialsHandler) UpdateCredentials(c *gin.Context) {
+	var req service.UpdateCredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	credentials, err := h.service.UpdateCredentials(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		writeCredentialsError(c, err)
+		return
+	}
+
+	c.JSON(200, credentials)
+}
+
+// DeleteCredentials handles DELETE /api/v1/credentials/:id
+func (h *CredentialsHandler) DeleteCredentials(c *gin.Context) {
+	if err := h.service.DeleteCredentials(c.Request.Context(), c.Param("id")); err != nil {
+		writeCredentialsError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// TestCredentials handles POST /api/v1/credentials/test
+//
+// Connects to the device with the credentials in the body, without saving
+// them. A failed login is reported with success false and status 200.
+func (h *CredentialsHandler) TestCredentials(c *gin.Context) {
+	var req service.TestCredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	result, err := h.service.TestCredentials(c.Request.Context(), &req)
+	if err != nil {
+		writeCredentialsError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// AttachToDevice handles PUT /api/v1/devices/:id/credentials
+func (h *CredentialsHandler) AttachToDevice(c *gin.Context) {
+	var req AttachCredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	deviceID := c.Param("id")
+	if err := h.service.AttachToDevice(c.Request.Context(), deviceID, req.CredentialsID); err != nil {
+		writeCredentialsError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"device_id": deviceID, "credentials_id": req.CredentialsID})
+}
+
+func writeCredentialsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCredentialsNotFound), errors.Is(err, service.ErrDeviceNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCredentialsInUse):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnsupportedProtocol):
+		c.JSON(422, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
 	"github.com/yourorg/nms-go/internal/device/service"
 )
 
@@ -18,11 +22,15 @@ func NewDeviceHandler(service service.DeviceService) *DeviceHandler {
 func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
 	var req service.RegisterDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
 		return
 	}
 
 	device, err := h.service.RegisterDevice(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrDeviceExists) {
+		c.JSON(409, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
@@ -32,8 +40,24 @@ func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
 }
 
 func (h *DeviceHandler) ListDevices(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if after, ok := c.GetQuery("after"); ok {
+		h.listDevicesAfter(c, after)
+		return
+	}
+
+	page, err := positiveQuery(c, "page", 1)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	pageSize, err := positiveQuery(c, "page_size", service.DefaultPageSize)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if pageSize > service.MaxPageSize {
+		pageSize = service.MaxPageSize
+	}
 
 	devices, total, err := h.service.ListDevices(c.Request.Context(), page, pageSize)
 	if err != nil {
@@ -49,6 +73,102 @@ func (h *DeviceHandler) ListDevices(c *gin.Context) {
 	})
 }
 
+// listDevicesAfter serves ListDevices with keyset pagination: after is empty
+// for the first page, then the next_cursor of the previous page.
+func (h *DeviceHandler) listDevicesAfter(c *gin.Context, after string) {
+	if _, ok := c.GetQuery("page"); ok {
+		c.JSON(400, gin.H{"error": "invalid query: page cannot be combined with after"})
+		return
+	}
+	pageSize, err := positiveQuery(c, "page_size", service.DefaultPageSize)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if pageSize > service.MaxPageSize {
+		pageSize = service.MaxPageSize
+	}
+
+	devices, next, err := h.service.ListDevicesAfter(c.Request.Context(), after, pageSize)
+	if errors.Is(err, service.ErrInvalidCursor) {
+		c.JSON(400, gin.H{"error": "invalid query: after must be a next_cursor returned by this endpoint"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	var nextCursor *string
+	if next != "" {
+		nextCursor = &next
+	}
+	c.JSON(200, gin.H{
+		"data":        devices,
+		"page_size":   pageSize,
+		"next_cursor": nextCursor,
+	})
+}
+
+// ListRecentDevices handles GET /api/v1/devices/recent
+//
+// Returns devices added or modified after the required since query parameter
+// (RFC3339), most recently updated first, paginated like ListDevices.
+func (h *DeviceHandler) ListRecentDevices(c *gin.Context) {
+	raw := c.Query("since")
+	if raw == "" {
+		c.JSON(400, gin.H{"error": "invalid query: since is required"})
+		return
+	}
+	since, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid query: since must be an RFC3339 timestamp"})
+		return
+	}
+
+	page, err := positiveQuery(c, "page", 1)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	pageSize, err := positiveQuery(c, "page_size", service.DefaultPageSize)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if pageSize > service.MaxPageSize {
+		pageSize = service.MaxPageSize
+	}
+
+	devices, total, err := h.service.ListRecentDevices(c.Request.Context(), since, page, pageSize)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"data":      devices,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"since":     since,
+	})
+}
+
+// positiveQuery reads an optional positive integer query parameter, returning
+// def when it is absent or empty.
+func positiveQuery(c *gin.Context, key string, def int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid query: %s must be a positive integer, got %q", key, raw)
+	}
+	return n, nil
+}
+
 func (h *DeviceHandler) GetDevice(c *gin.Context) {
 	id := c.Param("id")
 	device, err := h.service.GetDevice(c.Request.Context(), id)
@@ -59,3 +179,27 @@ func (h *DeviceHandler) GetDevice(c *gin.Context) {
 
 	c.JSON(200, device)
 }
+
+// BulkUpdate handles POST /api/v1/devices/bulk-update
+//
+// The update runs in one transaction but reports per-device results: devices
+// that fail are skipped while the rest are still updated.
+func (h *DeviceHandler) BulkUpdate(c *gin.Context) {
+	var req service.BulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	resp, err := h.service.BulkUpdate(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBulkUpdate) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, resp)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+type GroupHandler struct {
+	service service.GroupService
+}
+
+func NewGroupHandler(service service.GroupService) *GroupHandler {
+	return &GroupHandler{service: service}
+}
+
+// CreateGroup handles POST /api/v1/groups
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req service.GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	group, err := h.service.CreateGroup(c.Request.Context(), &req)
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+
+	c.JSON(201, group)
+}
+
+// ListGroups handles GET /api/v1/groups
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.service.ListGroups(c.Request.Context())
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"data": groups, "total": len(groups)})
+}
+
+// GetTree handles GET /api/v1/groups/tree
+func (h *GroupHandler) GetTree(c *gin.Context) {
+	tree, err := h.service.GetTree(c.Request.Context())
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"data": tree})
+}
+
+// GetGroup handles GET /api/v1/groups/:id
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	group, err := h.service.GetGroup(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+
+	c.JSON(200, group)
+}
+
+// UpdateGroup handles PUT /api/v1/groups/:id
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	var req service.UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	group, err := h.service.UpdateGroup(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+
+	c.JSON(200, group)
+}
+
+// DeleteGroup handles DELETE /api/v1/groups/:id
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	if err := h.service.DeleteGroup(c.Request.Context(), c.Param("id")); err != nil {
+		writeGroupError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// ListGroupDevices handles GET /api/v1/groups/:id/devices
+//
+// Devices in child groups are included unless recursive=false.
+func (h *GroupHandler) ListGroupDevices(c *gin.Context) {
+	recursive := true
+	if v := c.Query("recursive"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "recursive must be true or false"})
+			return
+		}
+		recursive = parsed
+	}
+
+	devices, err := h.service.ListGroupDevices(c.Request.Context(), c.Param("id"), recursive)
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"data": devices, "total": len(devices)})
+}
+
+// MoveDevices handles POST /api/v1/groups/:id/devices
+func (h *GroupHandler) MoveDevices(c *gin.Context) {
+	var req service.MoveDevicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	resp, err := h.service.MoveDevices(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		writeGroupError(c, err)
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+func writeGroupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrGroupNotEmpty), errors.Is(err, service.ErrDuplicateGroupName):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidGroupParent), errors.Is(err, service.ErrInvalidBulkUpdate):
+		c.JSON(400, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // DeviceType represents the type of network device
@@ -83,22 +86,24 @@ func (s *StringArray) Scan(value interface{}) error {
 
 // Device represents a network device
 type Device struct {
-	ID              string       `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ID              string       `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid();index:idx_devices_created_at_id,priority:2"`
 	Name            string       `json:"name" gorm:"not null;size:255"`
-	IPAddress       string       `json:"ip_address" gorm:"not null;type:inet"`
+	IPAddress       string       `json:"ip_address" gorm:"not null;type:inet;uniqueIndex"`
 	DeviceType      DeviceType   `json:"device_type" gorm:"not null;size:50"`
 	Protocol        Protocol     `json:"protocol" gorm:"not null;size:50"`
 	Status          DeviceStatus `json:"status" gorm:"size:20;default:'unknown'"`
 	PollingInterval int          `json:"polling_interval" gorm:"default:300"` // seconds
+	Priority        int          `json:"priority" gorm:"default:0"`           // higher is polled first when workers are backlogged
 	CredentialsID   *string      `json:"credentials_id" gorm:"type:uuid"`
 	GroupID         *string      `json:"group_id,omitempty" gorm:"type:uuid"`
 	Description     string       `json:"description" gorm:"type:text"`
 	Tags            StringArray  `json:"tags" gorm:"type:text[]"`
 	Metadata        JSONMap      `json:"metadata" gorm:"type:jsonb"`
 	LastSeen        *time.Time   `json:"last_seen,omitempty"`
+	NextPollAt      *time.Time   `json:"next_poll_at,omitempty" gorm:"index"` // set by the collector; nil polls on its next run
 	LastError       string       `json:"last_error,omitempty" gorm:"type:text"`
 	Enabled         bool         `json:"enabled" gorm:"default:true"`
-	CreatedAt       time.Time    `json:"created_at"`
+	CreatedAt       time.Time    `json:"created_at" gorm:"index:idx_devices_created_at_id,priority:1"`
 	UpdatedAt       time.Time    `json:"updated_at"`
 
 	// Relationships
@@ -153,6 +158,63 @@ func (DeviceGroup) TableName() string {
 	return "device_groups"
 }
 
+// BeforeCreate assigns the ID in Go so inserts don't depend on the
+// database's gen_random_uuid() being available, and sets the timestamps
+func (d *Device) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.NewString()
+	}
+	setCreateTimestamps(&d.CreatedAt, &d.UpdatedAt)
+	return nil
+}
+
+// BeforeUpdate refreshes UpdatedAt, including for column and map updates
+func (d *Device) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("updated_at", time.Now())
+	return nil
+}
+
+// BeforeCreate assigns the ID in Go if unset and sets the timestamps
+func (c *DeviceCredentials) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+	setCreateTimestamps(&c.CreatedAt, &c.UpdatedAt)
+	return nil
+}
+
+// BeforeUpdate refreshes UpdatedAt
+func (c *DeviceCredentials) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("updated_at", time.Now())
+	return nil
+}
+
+// BeforeCreate assigns the ID in Go if unset and sets the timestamps
+func (g *DeviceGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == "" {
+		g.ID = uuid.NewString()
+	}
+	setCreateTimestamps(&g.CreatedAt, &g.UpdatedAt)
+	return nil
+}
+
+// BeforeUpdate refreshes UpdatedAt
+func (g *DeviceGroup) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("updated_at", time.Now())
+	return nil
+}
+
+// setCreateTimestamps fills unset creation timestamps with the same instant
+func setCreateTimestamps(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
+
 // IsOnline checks if device is currently online
 func (d *Device) IsOnline() bool {
 	return d.Status == DeviceStatusOnline
@@ -0,0 +1,64 @@
+package model_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"gorm.io/gorm/schema"
+)
+
+func TestDevice_BeforeCreate_SetsTimestamps(t *testing.T) {
+	d := &model.Device{}
+	require.NoError(t, d.BeforeCreate(nil))
+
+	assert.NotEmpty(t, d.ID)
+	assert.False(t, d.CreatedAt.IsZero())
+	assert.Equal(t, d.CreatedAt, d.UpdatedAt)
+}
+
+func TestDevice_BeforeCreate_KeepsExplicitTimestamps(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &model.Device{CreatedAt: created, UpdatedAt: created}
+	require.NoError(t, d.BeforeCreate(nil))
+
+	assert.Equal(t, created, d.CreatedAt)
+	assert.Equal(t, created, d.UpdatedAt)
+}
+
+func TestCredentialsAndGroup_BeforeCreate_SetTimestamps(t *testing.T) {
+	c := &model.DeviceCredentials{}
+	require.NoError(t, c.BeforeCreate(nil))
+	assert.False(t, c.CreatedAt.IsZero())
+	assert.False(t, c.UpdatedAt.IsZero())
+
+	g := &model.DeviceGroup{}
+	require.NoError(t, g.BeforeCreate(nil))
+	assert.False(t, g.CreatedAt.IsZero())
+	assert.False(t, g.UpdatedAt.IsZero())
+}
+
+func TestDevice_IPAddressUniqueIndex(t *testing.T) {
+	s, err := schema.Parse(&model.Device{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+
+	idx, ok := s.ParseIndexes()["idx_devices_ip_address"]
+	require.True(t, ok, "ip_address must have a unique index")
+	assert.Equal(t, "UNIQUE", idx.Class)
+	require.Len(t, idx.Fields, 1)
+	assert.Equal(t, "ip_address", idx.Fields[0].DBName)
+}
+
+func TestDevice_CreatedAtIDIndex(t *testing.T) {
+	s, err := schema.Parse(&model.Device{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+
+	idx, ok := s.ParseIndexes()["idx_devices_created_at_id"]
+	require.True(t, ok, "keyset pagination needs an index on (created_at, id)")
+	require.Len(t, idx.Fields, 2)
+	assert.Equal(t, "created_at", idx.Fields[0].DBName)
+	assert.Equal(t, "id", idx.Fields[1].DBName)
+}
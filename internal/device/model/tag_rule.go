@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attributes a TagRule can match besides metadata keys
+const (
+	TagAttributeDeviceType = "device_type"
+	TagAttributeProtocol   = "protocol"
+)
+
+// TagRule adds Tag to a device whose Attribute equals Value (case-insensitive).
+// Attribute is "device_type", "protocol" or a string metadata key such as
+// "vendor" recorded by discovery.
+type TagRule struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Attribute string    `json:"attribute" gorm:"not null;size:100;uniqueIndex:idx_tag_rules_match"`
+	Value     string    `json:"value" gorm:"not null;size:255;uniqueIndex:idx_tag_rules_match"`
+	Tag       string    `json:"tag" gorm:"not null;size:255;uniqueIndex:idx_tag_rules_match"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for TagRule
+func (TagRule) TableName() string {
+	return "tag_rules"
+}
+
+// BeforeCreate assigns the ID in Go if unset and sets the timestamps
+func (r *TagRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	setCreateTimestamps(&r.CreatedAt, &r.UpdatedAt)
+	return nil
+}
+
+// BeforeUpdate refreshes UpdatedAt
+func (r *TagRule) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("updated_at", time.Now())
+	return nil
+}
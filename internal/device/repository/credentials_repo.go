@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"gorm.io/gorm"
+)
+
+// ErrCredentialsNotFound is returned when no credential set has the ID.
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// ErrDeviceNotFound is returned by AttachToDevice and DeviceRepository.GetByID
+// when no device has the ID.
+var ErrDeviceNotFound = errors.New("device not found")
+
+// CredentialsRepository defines data access for device credential sets.
+// Secrets are encrypted before they are written.
+type CredentialsRepository interface {
+	Create(ctx context.Context, credentials *model.DeviceCredentials) error
+	GetByID(ctx context.Context, id string) (*model.DeviceCredentials, error)
+	List(ctx context.Context) ([]*model.DeviceCredentials, error)
+	Update(ctx context.Context, credentials *model.DeviceCredentials) error
+	Delete(ctx context.Context, id string) error
+	// CountDevices returns how many devices use the credential set.
+	CountDevices(ctx context.Context, id string) (int64, error)
+	// AttachToDevice sets the credential set of a device; nil detaches it.
+	AttachToDevice(ctx context.Context, deviceID string, credentialsID *string) error
+}
+
+type credentialsRepository struct {
+	db *gorm.DB
+}
+
+// NewCredentialsRepository creates a new instance of CredentialsRepository
+func NewCredentialsRepository(db *gorm.DB) CredentialsRepository {
+	return &credentialsRepository{db: db}
+}
+
+// Create creates a new credential set
+func (r *credentialsRepository) Create(ctx context.Context, credentials *model.DeviceCredentials) error {
+	if err := encryptCredentials(credentials); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(credentials).Error
+}
+
+// GetByID retrieves a credential set by ID
+func (r *credentialsRepository) GetByID(ctx context.Context, id string) (*model.DeviceCredentials, error) {
+	var credentials model.DeviceCredentials
+	err := r.db.WithContext(ctx).First(&credentials, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCredentialsNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &credentials, nil
+}
+
+// List returns all credential sets ordered by name
+func (r *credentialsRepository) List(ctx context.Context) ([]*model.DeviceCredentials, error) {
+	var credentials []*model.DeviceCredentials
+	err := r.db.WithContext(ctx).Order("name, id").Find(&credentials).Error
+	return credentials, err
+}
+
+// Update saves every field of the credential set
+func (r *credentialsRepository) Update(ctx context.Context, credentials *model.DeviceCredentials) error {
+	if err := encryptCredentials(credentials); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).
+		Model(credentials).
+		Select("name", "username", "password_encrypted", "ssh_key_encrypted", "snmp_community", "snmp_version", "description").
+		Updates(credentials)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCredentialsNotFound
+	}
+	return nil
+}
+
+// Delete deletes a credential set
+func (r *credentialsRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&model.DeviceCredentials{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCredentialsNotFound
+	}
+	return nil
+}
+
+// CountDevices returns how many devices use the credential set
+func (r *credentialsRepository) CountDevices(ctx context.Context, id string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Device{}).
+		Where("credentials_id = ?", id).
+		Count(&count).Error
+	return count, err
+}
+
+// AttachToDevice sets or, with nil, clears the credential set of a device
+func (r *credentialsRepository) AttachToDevice(ctx context.Context, deviceID string, credentialsID *string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.Device{}).
+		Where("id = ?", deviceID).
+		Update("credentials_id", credentialsID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
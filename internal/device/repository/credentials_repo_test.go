@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"gorm.io/gorm"
+)
+
+func TestCredentialsRepository_Create_EncryptsSecrets(t *testing.T) {
+	c := useCipher(t)
+	db, mock := newMockDB(t)
+	repo := repository.NewCredentialsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "device_credentials"`)).
+		WithArgs("branch", "admin", encryptedArg{c, "s3cret"}, encryptedArg{c, "ssh-key"}, encryptedArg{c, "public"},
+			"2c", "", recentTime{}, recentTime{}, uuidArg{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	credentials := &model.DeviceCredentials{Name: "branch", Username: "admin", PasswordEncrypted: "s3cret", SSHKeyEncrypted: "ssh-key", SNMPCommunity: "public", SNMPVersion: "2c"}
+	require.NoError(t, repo.Create(context.Background(), credentials))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCredentialsRepository_GetByID_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewCredentialsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "device_credentials" WHERE id = $1`)).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := repo.GetByID(context.Background(), "missing")
+	assert.ErrorIs(t, err, repository.ErrCredentialsNotFound)
+}
+
+func TestCredentialsRepository_AttachToDevice(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewCredentialsRepository(db)
+	credentialsID := "7b0c6f4e-2f1a-4c56-9d3e-0a4b5c6d7e8f"
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "credentials_id"=$1,"updated_at"=$2 WHERE id = $3`)).
+		WithArgs(credentialsID, recentTime{}, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "credentials_id"=$1,"updated_at"=$2 WHERE id = $3`)).
+		WithArgs(nil, recentTime{}, "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.AttachToDevice(context.Background(), "dev-1", &credentialsID))
+	assert.ErrorIs(t, repo.AttachToDevice(context.Background(), "missing", nil), repository.ErrDeviceNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,12 +2,26 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	"github.com/yourorg/nms-go/internal/device/model"
 	"gorm.io/gorm"
 )
 
+// ErrDuplicateIPAddress is returned by Create when another device already
+// has the IP address.
+var ErrDuplicateIPAddress = errors.New("duplicate device IP address")
+
+// deviceIPIndex is the unique index GORM creates for Device.IPAddress.
+const deviceIPIndex = "idx_devices_ip_address"
+
+// uniqueViolation is the Postgres SQLSTATE for a unique constraint violation.
+const uniqueViolation = "23505"
+
 // DeviceRepository defines the interface for device data access
 type DeviceRepository interface {
 	Create(ctx context.Context, device *model.Device) error
@@ -16,10 +30,21 @@ type DeviceRepository interface {
 	List(ctx context.Context, filter *DeviceFilter) ([]*model.Device, error)
 	Update(ctx context.Context, device *model.Device) error
 	UpdateStatus(ctx context.Context, id string, status model.DeviceStatus) error
+	UpdateStatusDetails(ctx context.Context, id string, status model.DeviceStatus, lastSeen *time.Time, lastError string) error
+	UpdateIdentity(ctx context.Context, id string, name string, metadata model.JSONMap) error
 	Delete(ctx context.Context, id string) error
 	Count(ctx context.Context, filter *DeviceFilter) (int64, error)
 	GetByGroup(ctx context.Context, groupID string) ([]*model.Device, error)
-	ListForPolling(ctx context.Context, limit int) ([]*model.Device, error)
+	ListDueForPolling(ctx context.Context, now time.Time, limit int) ([]*model.Device, error)
+	UpdateNextPollAt(ctx context.Context, id string, next time.Time) error
+	BulkUpdate(ctx context.Context, ids []string, fields map[string]interface{}) ([]BulkUpdateResult, error)
+}
+
+// BulkUpdateResult is the outcome of one device in a bulk update
+type BulkUpdateResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
 }
 
 // DeviceFilter represents filtering options for device queries
@@ -33,6 +58,25 @@ type DeviceFilter struct {
 	Search     string // Search in name, IP, description
 	Limit      int
 	Offset     int
+
+	// UpdatedAfter keeps devices whose updated_at is strictly after it.
+	UpdatedAfter *time.Time
+	// RecentFirst orders List by updated_at, newest first.
+	RecentFirst bool
+
+	// OldestFirst orders List by created_at, then id: the order After pages
+	// through.
+	OldestFirst bool
+	// After keeps devices strictly after the cursor in created_at, id order.
+	// Unlike Offset it costs the same on every page.
+	After *Cursor
+}
+
+// Cursor is a position in the created_at, id order of devices, used for
+// keyset pagination.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
 }
 
 type deviceRepository struct {
@@ -44,9 +88,45 @@ func NewDeviceRepository(db *gorm.DB) DeviceRepository {
 	return &deviceRepository{db: db}
 }
 
-// Create creates a new device
+// Create creates a new device. The unique index on ip_address, not a prior
+// lookup, is what rejects a duplicate, so concurrent creates are safe.
 func (r *deviceRepository) Create(ctx context.Context, device *model.Device) error {
-	return r.db.WithContext(ctx).Create(device).Error
+	if err := encryptCredentials(device.Credentials); err != nil {
+		return err
+	}
+	err := r.db.WithContext(ctx).Create(device).Error
+	if isDuplicateIP(err) {
+		return fmt.Errorf("%w: %s", ErrDuplicateIPAddress, device.IPAddress)
+	}
+	return err
+}
+
+// encryptCredentials encrypts the secrets of c in place with the configured
+// key before they are written. Protocol clients decrypt them on use.
+func encryptCredentials(c *model.DeviceCredentials) error {
+	if c == nil {
+		return nil
+	}
+	password, err := crypto.Encrypt(c.PasswordEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	sshKey, err := crypto.Encrypt(c.SSHKeyEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt ssh key: %w", err)
+	}
+	community, err := crypto.Encrypt(c.SNMPCommunity)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt snmp community: %w", err)
+	}
+	c.PasswordEncrypted, c.SSHKeyEncrypted, c.SNMPCommunity = password, sshKey, community
+	return nil
+}
+
+// isDuplicateIP reports whether err is a violation of the ip_address unique index.
+func isDuplicateIP(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == deviceIPIndex
 }
 
 // GetByID retrieves a device by ID with related data
@@ -59,7 +139,7 @@ func (r *deviceRepository) GetByID(ctx context.Context, id string) (*model.Devic
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("device not found: %s", id)
+			return nil, fmt.Errorf("%w: %s", ErrDeviceNotFound, id)
 		}
 		return nil, err
 	}
@@ -95,6 +175,12 @@ func (r *deviceRepository) List(ctx context.Context, filter *DeviceFilter) ([]*m
 	query = r.applyFilter(query, filter)
 	
 	if filter != nil {
+		if filter.RecentFirst {
+			query = query.Order("updated_at DESC").Order("id")
+		}
+		if filter.OldestFirst {
+			query = query.Order("created_at").Order("id")
+		}
 		if filter.Limit > 0 {
 			query = query.Limit(filter.Limit)
 		}
@@ -107,10 +193,25 @@ func (r *deviceRepository) List(ctx context.Context, filter *DeviceFilter) ([]*m
 	return devices, err
 }
 
-// Update updates an existing device
+// Update updates an existing device. next_poll_at belongs to the collector
+// and is never written from here; a changed polling interval clears it so
+// the device is rescheduled on the next run instead of after the old interval.
 func (r *deviceRepository) Update(ctx context.Context, device *model.Device) error {
+	if err := encryptCredentials(device.Credentials); err != nil {
+		return err
+	}
+	if device.PollingInterval > 0 {
+		err := r.db.WithContext(ctx).
+			Model(&model.Device{}).
+			Where("id = ? AND polling_interval <> ?", device.ID, device.PollingInterval).
+			UpdateColumn("next_poll_at", nil).Error
+		if err != nil {
+			return err
+		}
+	}
 	return r.db.WithContext(ctx).
 		Model(device).
+		Omit("next_poll_at").
 		Updates(device).Error
 }
 
@@ -122,6 +223,41 @@ func (r *deviceRepository) UpdateStatus(ctx context.Context, id string, status m
 		Update("status", status).Error
 }
 
+// UpdateStatusDetails updates the status, last error and (when non-nil) last
+// seen time of a device. Like UpdateNextPollAt it skips the update hooks: the
+// poll outcome is written on every poll and is not a change to the device, so
+// it must not move updated_at.
+func (r *deviceRepository) UpdateStatusDetails(ctx context.Context, id string, status model.DeviceStatus, lastSeen *time.Time, lastError string) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"last_error": lastError,
+	}
+	if lastSeen != nil {
+		updates["last_seen"] = *lastSeen
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&model.Device{}).
+		Where("id = ?", id).
+		UpdateColumns(updates).Error
+}
+
+// UpdateIdentity merges metadata into the metadata of a device, leaving
+// other keys as stored, and, when name is non-empty, renames it
+func (r *deviceRepository) UpdateIdentity(ctx context.Context, id string, name string, metadata model.JSONMap) error {
+	updates := map[string]interface{}{
+		"metadata": gorm.Expr("COALESCE(metadata, '{}'::jsonb) || ?::jsonb", metadata),
+	}
+	if name != "" {
+		updates["name"] = name
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&model.Device{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
 // Delete soft deletes a device
 func (r *deviceRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).
@@ -147,18 +283,65 @@ func (r *deviceRepository) GetByGroup(ctx context.Context, groupID string) ([]*m
 	return devices, err
 }
 
-// ListForPolling retrieves enabled devices that are due for polling
-func (r *deviceRepository) ListForPolling(ctx context.Context, limit int) ([]*model.Device, error) {
+// ListDueForPolling retrieves enabled devices whose next poll is due at now,
+// never-scheduled devices first, then the most overdue. Credentials are
+// preloaded for building poll tasks.
+func (r *deviceRepository) ListDueForPolling(ctx context.Context, now time.Time, limit int) ([]*model.Device, error) {
 	var devices []*model.Device
 	err := r.db.WithContext(ctx).
 		Preload("Credentials").
-		Where("enabled = ? AND status != ?", true, model.DeviceStatusError).
-		Order("last_seen ASC NULLS FIRST").
+		Where("enabled = ? AND (next_poll_at IS NULL OR next_poll_at <= ?)", true, now).
+		Order("next_poll_at ASC NULLS FIRST").
+		Order("priority DESC").
 		Limit(limit).
 		Find(&devices).Error
 	return devices, err
 }
 
+// UpdateNextPollAt sets when a device is next due for polling. It skips the
+// update hooks: scheduling a poll is not a change to the device, so it must
+// not move updated_at.
+func (r *deviceRepository) UpdateNextPollAt(ctx context.Context, id string, next time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Device{}).
+		Where("id = ?", id).
+		UpdateColumn("next_poll_at", next).Error
+}
+
+// BulkUpdate applies the same column updates to each device in one
+// transaction. Each device is updated behind a savepoint so a failing item is
+// rolled back and reported without undoing the others.
+func (r *deviceRepository) BulkUpdate(ctx context.Context, ids []string, fields map[string]interface{}) ([]BulkUpdateResult, error) {
+	results := make([]BulkUpdateResult, 0, len(ids))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			if err := tx.SavePoint("bulk_item").Error; err != nil {
+				return err
+			}
+
+			res := tx.Model(&model.Device{}).Where("id = ?", id).Updates(fields)
+			switch {
+			case res.Error != nil:
+				if err := tx.RollbackTo("bulk_item").Error; err != nil {
+					return err
+				}
+				results = append(results, BulkUpdateResult{ID: id, Error: res.Error.Error()})
+			case res.RowsAffected == 0:
+				results = append(results, BulkUpdateResult{ID: id, Error: "device not found"})
+			default:
+				results = append(results, BulkUpdateResult{ID: id, Success: true})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bulk update failed: %w", err)
+	}
+
+	return results, nil
+}
+
 // applyFilter applies filter criteria to the query
 func (r *deviceRepository) applyFilter(query *gorm.DB, filter *DeviceFilter) *gorm.DB {
 	if filter == nil {
@@ -189,6 +372,14 @@ func (r *deviceRepository) applyFilter(query *gorm.DB, filter *DeviceFilter) *go
 		query = query.Where("tags @> ?", filter.Tags)
 	}
 	
+	if filter.UpdatedAfter != nil {
+		query = query.Where("updated_at > ?", *filter.UpdatedAfter)
+	}
+
+	if filter.After != nil {
+		query = query.Where("(created_at, id) > (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+	
 	if filter.Search != "" {
 		searchPattern := "%" + filter.Search + "%"
 		query = query.Where(
@@ -0,0 +1,394 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db, mock
+}
+
+// uuidArg matches any valid UUID argument
+type uuidArg struct{}
+
+func (uuidArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+func TestDeviceRepository_Create_AssignsID(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	// The ID is sent as the last insert argument rather than generated by gen_random_uuid()
+	args := make([]driver.Value, 19)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[18] = uuidArg{}
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "devices" (`) + `.*"id"\) VALUES .*`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	device := &model.Device{Name: "core-router", IPAddress: "10.0.0.1", DeviceType: model.DeviceTypeRouter, Protocol: model.ProtocolSNMP}
+	require.NoError(t, repo.Create(context.Background(), device))
+
+	_, err := uuid.Parse(device.ID)
+	assert.NoError(t, err, "ID must be set on create")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Create_KeepsExplicitID(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "devices"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	device := &model.Device{ID: "7b0c6f4e-2f1a-4c56-9d3e-0a4b5c6d7e8f", Name: "core-router", IPAddress: "10.0.0.1"}
+	require.NoError(t, repo.Create(context.Background(), device))
+
+	assert.Equal(t, "7b0c6f4e-2f1a-4c56-9d3e-0a4b5c6d7e8f", device.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// recentTime matches a time.Time argument set within the last minute
+type recentTime struct{}
+
+func (recentTime) Match(v driver.Value) bool {
+	ts, ok := v.(time.Time)
+	return ok && time.Since(ts) < time.Minute
+}
+
+func TestDeviceRepository_Create_SetsTimestamps(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "devices"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	device := &model.Device{Name: "core-router", IPAddress: "10.0.0.1"}
+	require.NoError(t, repo.Create(context.Background(), device))
+
+	assert.False(t, device.CreatedAt.IsZero())
+	assert.Equal(t, device.CreatedAt, device.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_UpdateStatus_SetsUpdatedAt(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "status"=$1,"updated_at"=$2 WHERE id = $3`)).
+		WithArgs(model.DeviceStatusOffline, recentTime{}, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.UpdateStatus(context.Background(), "dev-1", model.DeviceStatusOffline))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_UpdateStatusDetails_KeepsUpdatedAt(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+	seen := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "last_error"=$1,"status"=$2 WHERE id = $3`)).
+		WithArgs("timeout", model.DeviceStatusOffline, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "last_error"=$1,"last_seen"=$2,"status"=$3 WHERE id = $4`)).
+		WithArgs("", seen, model.DeviceStatusOnline, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.UpdateStatusDetails(context.Background(), "dev-1", model.DeviceStatusOffline, nil, "timeout"))
+	require.NoError(t, repo.UpdateStatusDetails(context.Background(), "dev-1", model.DeviceStatusOnline, &seen, ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_UpdateIdentity(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "metadata"=COALESCE(metadata, '{}'::jsonb) || $1::jsonb,"updated_at"=$2 WHERE id = $3`)).
+		WithArgs([]byte(`{"identity":"core-rtr-1"}`), recentTime{}, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "metadata"=COALESCE(metadata, '{}'::jsonb) || $1::jsonb,"name"=$2,"updated_at"=$3 WHERE id = $4`)).
+		WithArgs([]byte(`{"identity":"core-rtr-1"}`), "core-rtr-1", recentTime{}, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	metadata := model.JSONMap{"identity": "core-rtr-1"}
+	require.NoError(t, repo.UpdateIdentity(context.Background(), "dev-1", "", metadata), "an empty name keeps the current one")
+	require.NoError(t, repo.UpdateIdentity(context.Background(), "dev-1", "core-rtr-1", metadata))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_UpdateNextPollAt_KeepsUpdatedAt(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+	next := time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "next_poll_at"=$1 WHERE id = $2`)).
+		WithArgs(next, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.UpdateNextPollAt(context.Background(), "dev-1", next))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Update_SetsUpdatedAt(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	stale := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	device := &model.Device{ID: "dev-1", Name: "renamed", UpdatedAt: stale}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "name"=$1,"updated_at"=$2 WHERE "id" = $3`)).
+		WithArgs("renamed", recentTime{}, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Update(context.Background(), device))
+	assert.True(t, device.UpdatedAt.After(stale))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Update_LeavesNextPollAt(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	// A device read before the collector rescheduled it must not roll
+	// next_poll_at back.
+	next := time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC)
+	device := &model.Device{ID: "dev-1", Name: "renamed", NextPollAt: &next}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "name"=$1,"updated_at"=$2 WHERE "id" = $3`)).
+		WithArgs("renamed", recentTime{}, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Update(context.Background(), device))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Update_IntervalChangeClearsNextPollAt(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	device := &model.Device{ID: "dev-1", PollingInterval: 60}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "next_poll_at"=$1 WHERE id = $2 AND polling_interval <> $3`)).
+		WithArgs(nil, "dev-1", 60).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices" SET "polling_interval"=$1,"updated_at"=$2 WHERE "id" = $3`)).
+		WithArgs(60, recentTime{}, "dev-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Update(context.Background(), device))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_BulkUpdate_PartialSuccess(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	update := regexp.QuoteMeta(`UPDATE "devices" SET "enabled"=$1,"updated_at"=$2 WHERE id = $3`)
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT bulk_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(update).WithArgs(false, recentTime{}, "dev-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SAVEPOINT bulk_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(update).WithArgs(false, recentTime{}, "missing").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT bulk_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(update).WithArgs(false, recentTime{}, "not-a-uuid").WillReturnError(errors.New("invalid input syntax for type uuid"))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT bulk_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	results, err := repo.BulkUpdate(context.Background(), []string{"dev-1", "missing", "not-a-uuid"}, map[string]interface{}{"enabled": false})
+	require.NoError(t, err)
+
+	assert.Equal(t, []repository.BulkUpdateResult{
+		{ID: "dev-1", Success: true},
+		{ID: "missing", Error: "device not found"},
+		{ID: "not-a-uuid", Error: "invalid input syntax for type uuid"},
+	}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Create_DuplicateIP(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "devices"`)).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_devices_ip_address"})
+
+	err := repo.Create(context.Background(), &model.Device{Name: "core-router", IPAddress: "10.0.0.1"})
+
+	assert.ErrorIs(t, err, repository.ErrDuplicateIPAddress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Create_OtherUniqueViolation(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "devices"`)).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "devices_pkey"})
+
+	err := repo.Create(context.Background(), &model.Device{ID: "7b0c6f4e-2f1a-4c56-9d3e-0a4b5c6d7e8f", Name: "core-router", IPAddress: "10.0.0.1"})
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, repository.ErrDuplicateIPAddress)
+}
+
+func TestDeviceRepository_List_UpdatedAfterNewestFirst(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "devices" WHERE updated_at > $1 ORDER BY updated_at DESC,id LIMIT 20 OFFSET 20`)).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "updated_at"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "devices" WHERE updated_at > $1`)).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+
+	filter := &repository.DeviceFilter{UpdatedAfter: &since, RecentFirst: true, Limit: 20, Offset: 20}
+	_, err := repo.List(context.Background(), filter)
+	require.NoError(t, err)
+	count, err := repo.Count(context.Background(), filter)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(25), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_List_AfterCursor(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	after := repository.Cursor{
+		CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		ID:        "7b0c6f4e-2f1a-4c56-9d3e-0a4b5c6d7e8f",
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "devices" WHERE (created_at, id) > ($1, $2) ORDER BY created_at,id LIMIT 21`)).
+		WithArgs(after.CreatedAt, after.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+
+	_, err := repo.List(context.Background(), &repository.DeviceFilter{OldestFirst: true, After: &after, Limit: 21})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// encryptedArg matches a credential argument encrypted with cipher to plaintext
+type encryptedArg struct {
+	cipher    *crypto.Cipher
+	plaintext string
+}
+
+func (a encryptedArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok || !crypto.IsEncrypted(s) {
+		return false
+	}
+	plaintext, err := a.cipher.Decrypt(s)
+	return err == nil && plaintext == a.plaintext
+}
+
+func useCipher(t *testing.T) *crypto.Cipher {
+	t.Helper()
+	c, err := crypto.NewCipherFromKey("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	crypto.SetDefault(c)
+	t.Cleanup(func() { crypto.SetDefault(nil) })
+	return c
+}
+
+func TestDeviceRepository_Create_EncryptsCredentials(t *testing.T) {
+	c := useCipher(t)
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	// name, username, password_encrypted, ssh_key_encrypted, snmp_community, ...
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "device_credentials"`)).
+		WithArgs("core-router", "admin", encryptedArg{c, "s3cret"}, "", encryptedArg{c, "public"},
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "devices"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	device := &model.Device{Name: "core-router", IPAddress: "10.0.0.1", Credentials: &model.DeviceCredentials{
+		Name: "core-router", Username: "admin", PasswordEncrypted: "s3cret", SNMPCommunity: "public",
+	}}
+	require.NoError(t, repo.Create(context.Background(), device))
+
+	assert.True(t, crypto.IsEncrypted(device.Credentials.PasswordEncrypted))
+	assert.True(t, crypto.IsEncrypted(device.Credentials.SNMPCommunity))
+	assert.Empty(t, device.Credentials.SSHKeyEncrypted, "empty secrets stay empty")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Update_DoesNotEncryptTwice(t *testing.T) {
+	c := useCipher(t)
+	stored, err := c.Encrypt("s3cret")
+	require.NoError(t, err)
+
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "device_credentials"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "devices"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	credentialsID := "7b0c6f4e-2f1a-4c56-9d3e-0a4b5c6d7e8f"
+	device := &model.Device{ID: "dev-1", Name: "renamed", CredentialsID: &credentialsID, Credentials: &model.DeviceCredentials{
+		ID: credentialsID, Name: "core-router", Username: "admin", PasswordEncrypted: stored,
+	}}
+	require.NoError(t, repo.Update(context.Background(), device))
+
+	assert.Equal(t, stored, device.Credentials.PasswordEncrypted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceRepository_Create_PlaintextWithoutKey(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewDeviceRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "device_credentials"`)).
+		WithArgs("core-router", "admin", "s3cret", "", "",
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "devices"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	device := &model.Device{Name: "core-router", IPAddress: "10.0.0.1", Credentials: &model.DeviceCredentials{
+		Name: "core-router", Username: "admin", PasswordEncrypted: "s3cret",
+	}}
+	require.NoError(t, repo.Create(context.Background(), device))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrGroupNotFound is returned when no device group has the ID.
+var ErrGroupNotFound = errors.New("device group not found")
+
+// ErrDuplicateGroupName is returned when another group already has the name.
+var ErrDuplicateGroupName = errors.New("duplicate device group name")
+
+// groupNameIndex is the unique index GORM creates for DeviceGroup.Name.
+const groupNameIndex = "idx_device_groups_name"
+
+// GroupRepository defines data access for device groups
+type GroupRepository interface {
+	Create(ctx context.Context, group *model.DeviceGroup) error
+	GetByID(ctx context.Context, id string) (*model.DeviceGroup, error)
+	// List returns every group without relationships; the hierarchy is
+	// built from ParentID.
+	List(ctx context.Context) ([]*model.DeviceGroup, error)
+	Update(ctx context.Context, group *model.DeviceGroup) error
+	// Reparent saves the group like Update once check has accepted every
+	// group as stored. Groups are locked until it returns, so concurrent
+	// reparents are checked one after the other.
+	Reparent(ctx context.Context, group *model.DeviceGroup, check func(groups []*model.DeviceGroup) error) error
+	Delete(ctx context.Context, id string) error
+	// CountDevices returns the number of devices directly in each group.
+	// Groups without devices are absent.
+	CountDevices(ctx context.Context) (map[string]int64, error)
+	// ListDevices returns the devices directly in any of the groups.
+	ListDevices(ctx context.Context, groupIDs []string) ([]*model.Device, error)
+}
+
+type groupRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupRepository creates a new instance of GroupRepository
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+// Create creates a new device group
+func (r *groupRepository) Create(ctx context.Context, group *model.DeviceGroup) error {
+	err := r.db.WithContext(ctx).Create(group).Error
+	if isDuplicateGroupName(err) {
+		return ErrDuplicateGroupName
+	}
+	return err
+}
+
+// isDuplicateGroupName reports whether err is a violation of the name unique index.
+func isDuplicateGroupName(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == groupNameIndex
+}
+
+// GetByID retrieves a device group by ID
+func (r *groupRepository) GetByID(ctx context.Context, id string) (*model.DeviceGroup, error) {
+	var group model.DeviceGroup
+	err := r.db.WithContext(ctx).First(&group, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// List returns all device groups ordered by name
+func (r *groupRepository) List(ctx context.Context) ([]*model.DeviceGroup, error) {
+	var groups []*model.DeviceGroup
+	err := r.db.WithContext(ctx).Order("name, id").Find(&groups).Error
+	return groups, err
+}
+
+// Update saves the name, parent and description of the group
+func (r *groupRepository) Update(ctx context.Context, group *model.DeviceGroup) error {
+	result := r.db.WithContext(ctx).
+		Model(group).
+		Select("name", "parent_id", "description").
+		Updates(group)
+	if isDuplicateGroupName(result.Error) {
+		return ErrDuplicateGroupName
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+// Reparent saves the group after check accepts the groups, read with
+// FOR UPDATE in the same transaction
+func (r *groupRepository) Reparent(ctx context.Context, group *model.DeviceGroup, check func(groups []*model.DeviceGroup) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var groups []*model.DeviceGroup
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Order("name, id").
+			Find(&groups).Error
+		if err != nil {
+			return err
+		}
+		if err := check(groups); err != nil {
+			return err
+		}
+		return (&groupRepository{db: tx}).Update(ctx, group)
+	})
+}
+
+// Delete deletes a device group
+func (r *groupRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&model.DeviceGroup{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+// CountDevices returns the number of devices in each group
+func (r *groupRepository) CountDevices(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		GroupID string
+		Count   int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.Device{}).
+		Select("group_id, count(*) AS count").
+		Where("group_id IS NOT NULL").
+		Group("group_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.GroupID] = row.Count
+	}
+	return counts, nil
+}
+
+// ListDevices returns the devices in the groups ordered by name
+func (r *groupRepository) ListDevices(ctx context.Context, groupIDs []string) ([]*model.Device, error) {
+	var devices []*model.Device
+	if len(groupIDs) == 0 {
+		return devices, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("group_id IN ?", groupIDs).
+		Order("name, id").
+		Find(&devices).Error
+	return devices, err
+}
@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+func TestGroupRepository_CountDevices(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewGroupRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT group_id, count(*) AS count FROM "devices" WHERE group_id IS NOT NULL GROUP BY "group_id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"group_id", "count"}).
+			AddRow("region", 3).
+			AddRow("lab", 1))
+
+	counts, err := repo.CountDevices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"region": 3, "lab": 1}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepository_ListDevices(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewGroupRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "devices" WHERE group_id IN ($1,$2) ORDER BY name, id`)).
+		WithArgs("region", "site").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("dev-1", "core-1"))
+
+	devices, err := repo.ListDevices(context.Background(), []string{"region", "site"})
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "dev-1", devices[0].ID)
+
+	devices, err = repo.ListDevices(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, devices, "no groups means no query")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepository_Delete_NotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewGroupRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "device_groups" WHERE id = $1`)).
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.Delete(context.Background(), "missing"), repository.ErrGroupNotFound)
+}
+
+func TestGroupRepository_Reparent_ChecksLockedGroups(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewGroupRepository(db)
+	parentID := "region"
+	group := &model.DeviceGroup{ID: "site", Name: "site", ParentID: &parentID}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "device_groups" ORDER BY name, id FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("region", "region").AddRow("site", "site"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "device_groups" SET "name"=$1,"parent_id"=$2,"description"=$3,"updated_at"=$4 WHERE "id" = $5`)).
+		WithArgs("site", "region", "", recentTime{}, "site").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var checked []string
+	require.NoError(t, repo.Reparent(context.Background(), group, func(groups []*model.DeviceGroup) error {
+		for _, g := range groups {
+			checked = append(checked, g.ID)
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"region", "site"}, checked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepository_Reparent_RejectedCheckRollsBack(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := repository.NewGroupRepository(db)
+	parentID := "site"
+	group := &model.DeviceGroup{ID: "region", Name: "region", ParentID: &parentID}
+	rejected := errors.New("cycle")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectRollback()
+
+	err := repo.Reparent(context.Background(), group, func([]*model.DeviceGroup) error { return rejected })
+	assert.ErrorIs(t, err, rejected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,486 @@
+// Package repositorytest provides in-memory repositories for unit tests of
+// code that depends on the device repository, so they run without a
+// database.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"gorm.io/gorm/schema"
+)
+
+// DeviceRepository is a map-backed repository.DeviceRepository that is safe
+// for concurrent use. It follows the Postgres implementation where tests can
+// observe the difference: duplicate IPs fail Create with
+// repository.ErrDuplicateIPAddress, updates of unknown IDs are no-ops, and
+// Update only writes non-zero fields. Devices are copied on the way in and
+// out, so callers cannot change stored devices behind its back.
+//
+// Without an ORDER BY the database returns rows in no particular order; List
+// returns them by creation time, then ID, so tests are deterministic. That
+// is also the OldestFirst order.
+type DeviceRepository struct {
+	mu      sync.RWMutex
+	devices map[string]*model.Device
+	now     func() time.Time
+}
+
+var _ repository.DeviceRepository = (*DeviceRepository)(nil)
+
+// NewDeviceRepository creates an empty repository holding the given devices.
+func NewDeviceRepository(devices ...*model.Device) *DeviceRepository {
+	return NewDeviceRepositoryWithClock(time.Now, devices...)
+}
+
+// NewDeviceRepositoryWithClock creates a repository that stamps created_at
+// and updated_at from now, for tests of time-based filters.
+func NewDeviceRepositoryWithClock(now func() time.Time, devices ...*model.Device) *DeviceRepository {
+	r := &DeviceRepository{devices: make(map[string]*model.Device), now: now}
+	for _, device := range devices {
+		if err := r.Create(context.Background(), device); err != nil {
+			panic(fmt.Sprintf("repositorytest: seeding device %s: %v", device.IPAddress, err))
+		}
+	}
+	return r
+}
+
+func (r *DeviceRepository) Create(ctx context.Context, device *model.Device) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.devices {
+		if existing.IPAddress == device.IPAddress {
+			return fmt.Errorf("%w: %s", repository.ErrDuplicateIPAddress, device.IPAddress)
+		}
+	}
+
+	if device.ID == "" {
+		device.ID = uuid.NewString()
+	}
+	if _, ok := r.devices[device.ID]; ok {
+		return fmt.Errorf("duplicate device ID: %s", device.ID)
+	}
+	now := r.now()
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = now
+	}
+	if device.UpdatedAt.IsZero() {
+		device.UpdatedAt = now
+	}
+
+	r.devices[device.ID] = copyDevice(device)
+	return nil
+}
+
+func (r *DeviceRepository) GetByID(ctx context.Context, id string) (*model.Device, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	device, ok := r.devices[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", repository.ErrDeviceNotFound, id)
+	}
+	return copyDevice(device), nil
+}
+
+func (r *DeviceRepository) GetByIPAddress(ctx context.Context, ipAddress string) (*model.Device, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, device := range r.devices {
+		if device.IPAddress == ipAddress {
+			return copyDevice(device), nil
+		}
+	}
+	return nil, fmt.Errorf("device not found with IP: %s", ipAddress)
+}
+
+func (r *DeviceRepository) List(ctx context.Context, filter *repository.DeviceFilter) ([]*model.Device, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	devices := r.matching(filter)
+	if filter != nil {
+		if filter.RecentFirst {
+			sort.SliceStable(devices, func(i, j int) bool {
+				if !devices[i].UpdatedAt.Equal(devices[j].UpdatedAt) {
+					return devices[i].UpdatedAt.After(devices[j].UpdatedAt)
+				}
+				return devices[i].ID < devices[j].ID
+			})
+		}
+		devices = page(devices, filter.Offset, filter.Limit)
+	}
+	return copyDevices(devices), nil
+}
+
+func (r *DeviceRepository) Update(ctx context.Context, device *model.Device) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.devices[device.ID]
+	if !ok {
+		return nil
+	}
+
+	if device.PollingInterval > 0 && device.PollingInterval != stored.PollingInterval {
+		stored.NextPollAt = nil
+	}
+
+	// Like GORM's Updates with a struct, zero fields are left unchanged.
+	src := reflect.ValueOf(device).Elem()
+	dst := reflect.ValueOf(stored).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		switch src.Type().Field(i).Name {
+		case "ID", "CreatedAt", "UpdatedAt", "NextPollAt", "Credentials", "Group":
+			continue
+		}
+		if !src.Field(i).IsZero() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	stored.UpdatedAt = r.now()
+	*stored = *copyDevice(stored)
+	return nil
+}
+
+func (r *DeviceRepository) UpdateStatus(ctx context.Context, id string, status model.DeviceStatus) error {
+	return r.update(id, func(device *model.Device) {
+		device.Status = status
+	})
+}
+
+// UpdateStatusDetails leaves UpdatedAt alone, like the Postgres
+// implementation.
+func (r *DeviceRepository) UpdateStatusDetails(ctx context.Context, id string, status model.DeviceStatus, lastSeen *time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if device, ok := r.devices[id]; ok {
+		device.Status = status
+		device.LastError = lastError
+		if lastSeen != nil {
+			seen := *lastSeen
+			device.LastSeen = &seen
+		}
+	}
+	return nil
+}
+
+func (r *DeviceRepository) UpdateIdentity(ctx context.Context, id string, name string, metadata model.JSONMap) error {
+	return r.update(id, func(device *model.Device) {
+		merged := copyJSONMap(device.Metadata)
+		if merged == nil {
+			merged = make(model.JSONMap, len(metadata))
+		}
+		for k, v := range metadata {
+			merged[k] = v
+		}
+		device.Metadata = merged
+		if name != "" {
+			device.Name = name
+		}
+	})
+}
+
+func (r *DeviceRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.devices, id)
+	return nil
+}
+
+func (r *DeviceRepository) Count(ctx context.Context, filter *repository.DeviceFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.matching(filter))), nil
+}
+
+func (r *DeviceRepository) GetByGroup(ctx context.Context, groupID string) ([]*model.Device, error) {
+	return r.List(ctx, &repository.DeviceFilter{GroupID: &groupID})
+}
+
+func (r *DeviceRepository) ListDueForPolling(ctx context.Context, now time.Time, limit int) ([]*model.Device, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var devices []*model.Device
+	for _, device := range r.sorted() {
+		if device.Enabled && (device.NextPollAt == nil || !device.NextPollAt.After(now)) {
+			devices = append(devices, device)
+		}
+	}
+	// Never-scheduled devices first, then the most overdue, then by priority.
+	sort.SliceStable(devices, func(i, j int) bool {
+		a, b := devices[i].NextPollAt, devices[j].NextPollAt
+		switch {
+		case a == nil && b == nil:
+		case a == nil || b == nil:
+			return a == nil
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return devices[i].Priority > devices[j].Priority
+	})
+	return copyDevices(page(devices, 0, limit)), nil
+}
+
+// UpdateNextPollAt leaves UpdatedAt alone, like the Postgres implementation.
+func (r *DeviceRepository) UpdateNextPollAt(ctx context.Context, id string, next time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if device, ok := r.devices[id]; ok {
+		device.NextPollAt = &next
+	}
+	return nil
+}
+
+// BulkUpdate sets the columns, named as in the database, on each device.
+// Unknown columns and values of the wrong type fail the item.
+func (r *DeviceRepository) BulkUpdate(ctx context.Context, ids []string, fields map[string]interface{}) ([]repository.BulkUpdateResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]repository.BulkUpdateResult, 0, len(ids))
+	for _, id := range ids {
+		stored, ok := r.devices[id]
+		if !ok {
+			results = append(results, repository.BulkUpdateResult{ID: id, Error: "device not found"})
+			continue
+		}
+
+		updated := copyDevice(stored)
+		var err error
+		for column, value := range fields {
+			if err = setColumn(updated, column, value); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			results = append(results, repository.BulkUpdateResult{ID: id, Error: err.Error()})
+			continue
+		}
+
+		updated.UpdatedAt = r.now()
+		r.devices[id] = updated
+		results = append(results, repository.BulkUpdateResult{ID: id, Success: true})
+	}
+	return results, nil
+}
+
+// update applies fn to the stored device, if there is one, and stamps
+// updated_at.
+func (r *DeviceRepository) update(id string, fn func(device *model.Device)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	device, ok := r.devices[id]
+	if !ok {
+		return nil
+	}
+	fn(device)
+	device.UpdatedAt = r.now()
+	return nil
+}
+
+// sorted returns the stored devices by creation time, then ID. The caller
+// must hold the lock.
+func (r *DeviceRepository) sorted() []*model.Device {
+	devices := make([]*model.Device, 0, len(r.devices))
+	for _, device := range r.devices {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if !devices[i].CreatedAt.Equal(devices[j].CreatedAt) {
+			return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+		}
+		return devices[i].ID < devices[j].ID
+	})
+	return devices
+}
+
+// matching returns the stored devices that pass filter, ignoring its
+// paging. The caller must hold the lock.
+func (r *DeviceRepository) matching(filter *repository.DeviceFilter) []*model.Device {
+	var devices []*model.Device
+	for _, device := range r.sorted() {
+		if matches(device, filter) {
+			devices = append(devices, device)
+		}
+	}
+	return devices
+}
+
+// matches mirrors the WHERE clauses the Postgres repository builds from a
+// filter.
+func matches(device *model.Device, filter *repository.DeviceFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.DeviceType != nil && device.DeviceType != *filter.DeviceType {
+		return false
+	}
+	if filter.Protocol != nil && device.Protocol != *filter.Protocol {
+		return false
+	}
+	if filter.Status != nil && device.Status != *filter.Status {
+		return false
+	}
+	if filter.GroupID != nil && (device.GroupID == nil || *device.GroupID != *filter.GroupID) {
+		return false
+	}
+	if filter.Enabled != nil && device.Enabled != *filter.Enabled {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !containsString(device.Tags, tag) {
+			return false
+		}
+	}
+	if filter.UpdatedAfter != nil && !device.UpdatedAt.After(*filter.UpdatedAfter) {
+		return false
+	}
+	if filter.After != nil && !afterCursor(device, filter.After) {
+		return false
+	}
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		if !strings.Contains(strings.ToLower(device.Name), search) &&
+			!strings.Contains(strings.ToLower(device.IPAddress), search) &&
+			!strings.Contains(strings.ToLower(device.Description), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// afterCursor reports whether device comes after the cursor in created_at,
+// id order.
+func afterCursor(device *model.Device, cursor *repository.Cursor) bool {
+	if !device.CreatedAt.Equal(cursor.CreatedAt) {
+		return device.CreatedAt.After(cursor.CreatedAt)
+	}
+	return device.ID > cursor.ID
+}
+
+// page applies an offset and, when positive, a limit.
+func page(devices []*model.Device, offset, limit int) []*model.Device {
+	if offset > 0 {
+		if offset >= len(devices) {
+			return nil
+		}
+		devices = devices[offset:]
+	}
+	if limit > 0 && limit < len(devices) {
+		devices = devices[:limit]
+	}
+	return devices
+}
+
+var columnNames = schema.NamingStrategy{}
+
+// setColumn sets the Device field stored in column to value. A nil value
+// clears the field.
+func setColumn(device *model.Device, column string, value interface{}) error {
+	v := reflect.ValueOf(device).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if columnNames.ColumnName("", field.Name) != column {
+			continue
+		}
+
+		dst := v.Field(i)
+		if value == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		src := reflect.ValueOf(value)
+		switch {
+		case src.Type().ConvertibleTo(dst.Type()):
+			dst.Set(src.Convert(dst.Type()))
+		case dst.Kind() == reflect.Pointer && src.Type().ConvertibleTo(dst.Type().Elem()):
+			ptr := reflect.New(dst.Type().Elem())
+			ptr.Elem().Set(src.Convert(dst.Type().Elem()))
+			dst.Set(ptr)
+		default:
+			return fmt.Errorf("cannot set column %q of type %s to %T", column, dst.Type(), value)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown column %q", column)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// copyDevice copies a device along with the slices, maps and pointers it
+// owns.
+func copyDevice(device *model.Device) *model.Device {
+	c := *device
+	if device.Tags != nil {
+		c.Tags = append(model.StringArray(nil), device.Tags...)
+	}
+	c.Metadata = copyJSONMap(device.Metadata)
+	if device.LastSeen != nil {
+		seen := *device.LastSeen
+		c.LastSeen = &seen
+	}
+	if device.NextPollAt != nil {
+		next := *device.NextPollAt
+		c.NextPollAt = &next
+	}
+	if device.GroupID != nil {
+		groupID := *device.GroupID
+		c.GroupID = &groupID
+	}
+	if device.CredentialsID != nil {
+		credentialsID := *device.CredentialsID
+		c.CredentialsID = &credentialsID
+	}
+	if device.Credentials != nil {
+		credentials := *device.Credentials
+		c.Credentials = &credentials
+	}
+	if device.Group != nil {
+		group := *device.Group
+		c.Group = &group
+	}
+	return &c
+}
+
+func copyDevices(devices []*model.Device) []*model.Device {
+	copies := make([]*model.Device, 0, len(devices))
+	for _, device := range devices {
+		copies = append(copies, copyDevice(device))
+	}
+	return copies
+}
+
+func copyJSONMap(m model.JSONMap) model.JSONMap {
+	if m == nil {
+		return nil
+	}
+	c := make(model.JSONMap, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
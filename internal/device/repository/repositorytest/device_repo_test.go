@@ -0,0 +1,226 @@
+package repositorytest_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/device/repository/repositorytest"
+)
+
+func names(devices []*model.Device) []string {
+	var out []string
+	for _, d := range devices {
+		out = append(out, d.Name)
+	}
+	return out
+}
+
+func TestDeviceRepository_CreateRejectsDuplicateIP(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository()
+	ctx := context.Background()
+
+	first := &model.Device{Name: "r1", IPAddress: "10.0.0.1"}
+	require.NoError(t, repo.Create(ctx, first))
+	assert.NotEmpty(t, first.ID)
+	assert.False(t, first.CreatedAt.IsZero())
+
+	err := repo.Create(ctx, &model.Device{Name: "r2", IPAddress: "10.0.0.1"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateIPAddress)
+}
+
+func TestDeviceRepository_ListFilters(t *testing.T) {
+	olt, router := model.DeviceTypeOLT, model.DeviceTypeRouter
+	disabled := false
+	group := "g1"
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{Name: "olt-a", IPAddress: "10.0.0.1", DeviceType: olt, Enabled: true, Tags: model.StringArray{"pop-a", "core"}, GroupID: &group},
+		&model.Device{Name: "olt-b", IPAddress: "10.0.0.2", DeviceType: olt, Enabled: false, Tags: model.StringArray{"pop-b"}},
+		&model.Device{Name: "edge", IPAddress: "10.0.1.1", DeviceType: router, Enabled: true, Description: "POP-A uplink", Tags: model.StringArray{"pop-a"}},
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		filter *repository.DeviceFilter
+		want   []string
+	}{
+		{"nil filter", nil, []string{"olt-a", "olt-b", "edge"}},
+		{"device type", &repository.DeviceFilter{DeviceType: &olt}, []string{"olt-a", "olt-b"}},
+		{"disabled", &repository.DeviceFilter{Enabled: &disabled}, []string{"olt-b"}},
+		{"group", &repository.DeviceFilter{GroupID: &group}, []string{"olt-a"}},
+		{"all tags", &repository.DeviceFilter{Tags: []string{"pop-a", "core"}}, []string{"olt-a"}},
+		{"search ip", &repository.DeviceFilter{Search: "10.0.1."}, []string{"edge"}},
+		{"search description ignores case", &repository.DeviceFilter{Search: "pop-a"}, []string{"edge"}},
+		{"paged", &repository.DeviceFilter{Limit: 2, Offset: 1}, []string{"olt-b", "edge"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devices, err := repo.List(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(devices))
+		})
+	}
+
+	count, err := repo.Count(ctx, &repository.DeviceFilter{DeviceType: &olt, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count, "Count ignores paging")
+}
+
+func TestDeviceRepository_UpdatedAfterAndRecentFirst(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := repositorytest.NewDeviceRepositoryWithClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, repo.Create(ctx, &model.Device{ID: fmt.Sprintf("d%d", i), Name: fmt.Sprintf("d%d", i), IPAddress: fmt.Sprintf("10.0.0.%d", i)}))
+		now = now.Add(time.Minute)
+	}
+	require.NoError(t, repo.UpdateStatus(ctx, "d1", model.DeviceStatusOnline))
+
+	since := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+	devices, err := repo.List(ctx, &repository.DeviceFilter{UpdatedAfter: &since, RecentFirst: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d3", "d2"}, names(devices))
+}
+
+func TestDeviceRepository_ReturnsCopies(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository(&model.Device{ID: "d1", Name: "r1", IPAddress: "10.0.0.1", Tags: model.StringArray{"a"}})
+	ctx := context.Background()
+
+	got, err := repo.GetByID(ctx, "d1")
+	require.NoError(t, err)
+	got.Name = "changed"
+	got.Tags[0] = "changed"
+
+	again, err := repo.GetByID(ctx, "d1")
+	require.NoError(t, err)
+	assert.Equal(t, "r1", again.Name)
+	assert.Equal(t, model.StringArray{"a"}, again.Tags)
+}
+
+func TestDeviceRepository_UpdateSkipsZeroFields(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository(&model.Device{ID: "d1", Name: "r1", IPAddress: "10.0.0.1", Description: "core", Enabled: true})
+	ctx := context.Background()
+
+	require.NoError(t, repo.Update(ctx, &model.Device{ID: "d1", Name: "r1-new"}))
+
+	got, err := repo.GetByID(ctx, "d1")
+	require.NoError(t, err)
+	assert.Equal(t, "r1-new", got.Name)
+	assert.Equal(t, "core", got.Description)
+	assert.True(t, got.Enabled)
+}
+
+func TestDeviceRepository_BulkUpdate(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository(
+		&model.Device{ID: "d1", Name: "r1", IPAddress: "10.0.0.1", Enabled: true},
+		&model.Device{ID: "d2", Name: "r2", IPAddress: "10.0.0.2", Enabled: true},
+	)
+	ctx := context.Background()
+
+	results, err := repo.BulkUpdate(ctx, []string{"d1", "missing"}, map[string]interface{}{
+		"enabled":  false,
+		"group_id": "g1",
+		"tags":     model.StringArray{"pop-a"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []repository.BulkUpdateResult{
+		{ID: "d1", Success: true},
+		{ID: "missing", Error: "device not found"},
+	}, results)
+
+	got, err := repo.GetByID(ctx, "d1")
+	require.NoError(t, err)
+	assert.False(t, got.Enabled)
+	require.NotNil(t, got.GroupID)
+	assert.Equal(t, "g1", *got.GroupID)
+	assert.Equal(t, model.StringArray{"pop-a"}, got.Tags)
+
+	results, err = repo.BulkUpdate(ctx, []string{"d2"}, map[string]interface{}{"no_such_column": 1})
+	require.NoError(t, err)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "unknown column")
+}
+
+func TestDeviceRepository_ConcurrentCreatesKeepIPUnique(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = repo.Create(ctx, &model.Device{Name: fmt.Sprintf("r%d", i), IPAddress: "10.0.0.1"})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			assert.ErrorIs(t, err, repository.ErrDuplicateIPAddress)
+		}
+	}
+	assert.Equal(t, 1, created)
+}
+
+// TestDeviceRepository_AfterCursorIteration pages through devices sharing
+// creation times, adding and removing devices between pages, and expects
+// every device present throughout to be seen exactly once.
+func TestDeviceRepository_AfterCursorIteration(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var seed []*model.Device
+	for i := 0; i < 23; i++ {
+		seed = append(seed, &model.Device{
+			ID:        fmt.Sprintf("d%02d", i),
+			Name:      fmt.Sprintf("d%02d", i),
+			IPAddress: fmt.Sprintf("10.0.0.%d", i),
+			// Three devices per timestamp, so ties are broken by ID.
+			CreatedAt: base.Add(time.Duration(i/3) * time.Second),
+		})
+	}
+	repo := repositorytest.NewDeviceRepository(seed...)
+	ctx := context.Background()
+
+	seen := make(map[string]int)
+	var order []string
+	var after *repository.Cursor
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "iteration must terminate")
+		devices, err := repo.List(ctx, &repository.DeviceFilter{OldestFirst: true, After: after, Limit: 5})
+		require.NoError(t, err)
+		if len(devices) == 0 {
+			break
+		}
+		for _, d := range devices {
+			seen[d.ID]++
+			order = append(order, d.ID)
+		}
+		last := devices[len(devices)-1]
+		after = &repository.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+
+		if pages == 1 {
+			// Neither shifts the pages still to come.
+			require.NoError(t, repo.Delete(ctx, "d00"))
+			require.NoError(t, repo.Create(ctx, &model.Device{ID: "late", Name: "late", IPAddress: "10.0.1.1", CreatedAt: base.Add(time.Hour)}))
+		}
+	}
+
+	for _, d := range seed {
+		assert.Equal(t, 1, seen[d.ID], "device %s", d.ID)
+	}
+	assert.Equal(t, 1, seen["late"])
+	assert.Len(t, order, len(seed)+1)
+	assert.IsIncreasing(t, order[:len(seed)])
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"gorm.io/gorm"
+)
+
+// TagRuleRepository defines data access for automatic tagging rules
+type TagRuleRepository interface {
+	List(ctx context.Context) ([]*model.TagRule, error)
+	Create(ctx context.Context, rule *model.TagRule) error
+}
+
+type tagRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRuleRepository creates a new instance of TagRuleRepository
+func NewTagRuleRepository(db *gorm.DB) TagRuleRepository {
+	return &tagRuleRepository{db: db}
+}
+
+// List returns all tag rules
+func (r *tagRuleRepository) List(ctx context.Context) ([]*model.TagRule, error) {
+	var rules []*model.TagRule
+	err := r.db.WithContext(ctx).Order("attribute, value, tag").Find(&rules).Error
+	return rules, err
+}
+
+// Create creates a new tag rule
+func (r *tagRuleRepository) Create(ctx context.Context, rule *model.TagRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+// MaxBulkUpdateDevices bounds how many devices one bulk update may touch
+const MaxBulkUpdateDevices = 1000
+
+// BulkUpdateRequest changes the same fields on a set of devices, selected
+// either by ID or by filter.
+type BulkUpdateRequest struct {
+	IDs    []string          `json:"ids"`
+	Filter *BulkUpdateFilter `json:"filter"`
+	Fields BulkUpdateFields  `json:"fields"`
+}
+
+// BulkUpdateFilter selects devices for a bulk update
+type BulkUpdateFilter struct {
+	GroupID    *string           `json:"group_id"`
+	DeviceType *model.DeviceType `json:"device_type"`
+	Tags       []string          `json:"tags"`
+}
+
+// BulkUpdateFields lists the fields to change; omitted fields are left as is.
+// An empty group_id removes the devices from their group.
+type BulkUpdateFields struct {
+	Enabled  *bool    `json:"enabled"`
+	GroupID  *string  `json:"group_id"`
+	Tags     []string `json:"tags"`
+	Priority *int     `json:"priority"`
+}
+
+// BulkUpdateResponse reports the outcome for every selected device
+type BulkUpdateResponse struct {
+	Updated int                           `json:"updated"`
+	Failed  int                           `json:"failed"`
+	Results []repository.BulkUpdateResult `json:"results"`
+}
+
+// ErrInvalidBulkUpdate is returned when a bulk update request is malformed
+var ErrInvalidBulkUpdate = errors.New("invalid bulk update")
+
+func (s *deviceService) BulkUpdate(ctx context.Context, req *BulkUpdateRequest) (*BulkUpdateResponse, error) {
+	fields := req.Fields.columns()
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: no fields to update", ErrInvalidBulkUpdate)
+	}
+
+	ids, err := s.bulkUpdateTargets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.repo.BulkUpdate(ctx, ids, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBulkUpdateResponse(results), nil
+}
+
+// newBulkUpdateResponse counts the successful and failed results
+func newBulkUpdateResponse(results []repository.BulkUpdateResult) *BulkUpdateResponse {
+	resp := &BulkUpdateResponse{Results: results}
+	for _, r := range results {
+		if r.Success {
+			resp.Updated++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
+// bulkUpdateTargets resolves the request to a list of device IDs
+func (s *deviceService) bulkUpdateTargets(ctx context.Context, req *BulkUpdateRequest) ([]string, error) {
+	switch {
+	case len(req.IDs) > 0 && req.Filter != nil:
+		return nil, fmt.Errorf("%w: specify either ids or filter, not both", ErrInvalidBulkUpdate)
+	case len(req.IDs) > 0:
+		if len(req.IDs) > MaxBulkUpdateDevices {
+			return nil, fmt.Errorf("%w: at most %d ids allowed", ErrInvalidBulkUpdate, MaxBulkUpdateDevices)
+		}
+		return req.IDs, nil
+	case req.Filter != nil:
+		if req.Filter.GroupID == nil && req.Filter.DeviceType == nil && len(req.Filter.Tags) == 0 {
+			return nil, fmt.Errorf("%w: filter must set at least one criterion", ErrInvalidBulkUpdate)
+		}
+		// One past the limit tells a filter matching too many devices apart
+		// from one matching exactly the limit; it is rejected rather than
+		// updating only some of them.
+		devices, err := s.repo.List(ctx, &repository.DeviceFilter{
+			GroupID:    req.Filter.GroupID,
+			DeviceType: req.Filter.DeviceType,
+			Tags:       req.Filter.Tags,
+			Limit:      MaxBulkUpdateDevices + 1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve filter: %w", err)
+		}
+		if len(devices) > MaxBulkUpdateDevices {
+			return nil, fmt.Errorf("%w: filter matches more than %d devices, narrow it", ErrInvalidBulkUpdate, MaxBulkUpdateDevices)
+		}
+		ids := make([]string, len(devices))
+		for i, d := range devices {
+			ids[i] = d.ID
+		}
+		return ids, nil
+	default:
+		return nil, fmt.Errorf("%w: ids or filter is required", ErrInvalidBulkUpdate)
+	}
+}
+
+// columns maps the set fields to device columns
+func (f BulkUpdateFields) columns() map[string]interface{} {
+	cols := make(map[string]interface{})
+	if f.Enabled != nil {
+		cols["enabled"] = *f.Enabled
+	}
+	if f.GroupID != nil {
+		if *f.GroupID == "" {
+			cols["group_id"] = nil
+		} else {
+			cols["group_id"] = *f.GroupID
+		}
+	}
+	if f.Tags != nil {
+		cols["tags"] = model.StringArray(f.Tags)
+	}
+	if f.Priority != nil {
+		cols["priority"] = *f.Priority
+	}
+	return cols
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+func boolPtr(b bool) *bool    { return &b }
+func intPtr(i int) *int       { return &i }
+func strPtr(s string) *string { return &s }
+
+func TestBulkUpdate_ByFilterReportsPartialSuccess(t *testing.T) {
+	var gotFilter *repository.DeviceFilter
+	var gotIDs []string
+	var gotFields map[string]interface{}
+	repo := &MockDeviceRepository{
+		ListFunc: func(ctx context.Context, filter *repository.DeviceFilter) ([]*model.Device, error) {
+			gotFilter = filter
+			return []*model.Device{{ID: "dev-1"}, {ID: "dev-2"}}, nil
+		},
+		BulkUpdateFunc: func(ctx context.Context, ids []string, fields map[string]interface{}) ([]repository.BulkUpdateResult, error) {
+			gotIDs, gotFields = ids, fields
+			return []repository.BulkUpdateResult{
+				{ID: "dev-1", Success: true},
+				{ID: "dev-2", Error: "device not found"},
+			}, nil
+		},
+	}
+	svc := service.NewDeviceService(repo, nil)
+
+	resp, err := svc.BulkUpdate(context.Background(), &service.BulkUpdateRequest{
+		Filter: &service.BulkUpdateFilter{GroupID: strPtr("grp-1")},
+		Fields: service.BulkUpdateFields{Enabled: boolPtr(false), GroupID: strPtr(""), Tags: []string{"maintenance"}, Priority: intPtr(10)},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, gotFilter)
+	assert.Equal(t, "grp-1", *gotFilter.GroupID)
+	assert.Equal(t, []string{"dev-1", "dev-2"}, gotIDs)
+	assert.Equal(t, map[string]interface{}{
+		"enabled":  false,
+		"group_id": nil,
+		"tags":     model.StringArray{"maintenance"},
+		"priority": 10,
+	}, gotFields)
+	assert.Equal(t, 1, resp.Updated)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Len(t, resp.Results, 2)
+}
+
+func TestBulkUpdate_FilterMatchingTooManyDevices(t *testing.T) {
+	listed := func(n int) *MockDeviceRepository {
+		return &MockDeviceRepository{
+			ListFunc: func(ctx context.Context, filter *repository.DeviceFilter) ([]*model.Device, error) {
+				if filter.Limit < n {
+					n = filter.Limit
+				}
+				devices := make([]*model.Device, n)
+				for i := range devices {
+					devices[i] = &model.Device{ID: fmt.Sprintf("dev-%d", i)}
+				}
+				return devices, nil
+			},
+			BulkUpdateFunc: func(ctx context.Context, ids []string, fields map[string]interface{}) ([]repository.BulkUpdateResult, error) {
+				results := make([]repository.BulkUpdateResult, len(ids))
+				for i, id := range ids {
+					results[i] = repository.BulkUpdateResult{ID: id, Success: true}
+				}
+				return results, nil
+			},
+		}
+	}
+	req := &service.BulkUpdateRequest{
+		Filter: &service.BulkUpdateFilter{GroupID: strPtr("grp-1")},
+		Fields: service.BulkUpdateFields{Enabled: boolPtr(false)},
+	}
+
+	resp, err := service.NewDeviceService(listed(service.MaxBulkUpdateDevices), nil).BulkUpdate(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, service.MaxBulkUpdateDevices, resp.Updated)
+
+	_, err = service.NewDeviceService(listed(service.MaxBulkUpdateDevices+1), nil).BulkUpdate(context.Background(), req)
+	assert.ErrorIs(t, err, service.ErrInvalidBulkUpdate)
+}
+
+func TestBulkUpdate_InvalidRequests(t *testing.T) {
+	svc := service.NewDeviceService(&MockDeviceRepository{}, nil)
+	enable := service.BulkUpdateFields{Enabled: boolPtr(true)}
+
+	tests := []struct {
+		name string
+		req  service.BulkUpdateRequest
+	}{
+		{name: "no fields", req: service.BulkUpdateRequest{IDs: []string{"dev-1"}}},
+		{name: "no target", req: service.BulkUpdateRequest{Fields: enable}},
+		{name: "ids and filter", req: service.BulkUpdateRequest{IDs: []string{"dev-1"}, Filter: &service.BulkUpdateFilter{GroupID: strPtr("grp-1")}, Fields: enable}},
+		{name: "empty filter", req: service.BulkUpdateRequest{Filter: &service.BulkUpdateFilter{}, Fields: enable}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.BulkUpdate(context.Background(), &tt.req)
+			assert.True(t, errors.Is(err, service.ErrInvalidBulkUpdate), "got %v", err)
+		})
+	}
+}
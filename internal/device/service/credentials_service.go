@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+var (
+	// ErrCredentialsNotFound is returned when no credential set has the ID.
+	ErrCredentialsNotFound = errors.New("credentials not found")
+
+	// ErrCredentialsInUse is returned when deleting a credential set that is
+	// attached to devices.
+	ErrCredentialsInUse = errors.New("credentials are attached to devices")
+
+	// ErrDeviceNotFound is returned when no device has the ID.
+	ErrDeviceNotFound = errors.New("device not found")
+
+	// ErrUnsupportedProtocol is returned when credentials cannot be tested
+	// over the device's protocol.
+	ErrUnsupportedProtocol = errors.New("credentials cannot be tested over this protocol")
+)
+
+// CredentialsService manages device credential sets. Secrets are write-only:
+// they are accepted in requests and never returned.
+type CredentialsService interface {
+	CreateCredentials(ctx context.Context, req *CredentialsRequest) (*model.DeviceCredentials, error)
+	GetCredentials(ctx context.Context, id string) (*model.DeviceCredentials, error)
+	ListCredentials(ctx context.Context) ([]*model.DeviceCredentials, error)
+	// UpdateCredentials changes the fields set in req, e.g. to rotate a
+	// password. Devices using the set pick up the change on their next
+	// connection.
+	UpdateCredentials(ctx context.Context, id string, req *UpdateCredentialsRequest) (*model.DeviceCredentials, error)
+	DeleteCredentials(ctx context.Context, id string) error
+	// AttachToDevice makes a device use the credential set; nil detaches it.
+	AttachToDevice(ctx context.Context, deviceID string, credentialsID *string) error
+	// TestCredentials connects to a device with credentials that are not
+	// saved, to check them before saving.
+	TestCredentials(ctx context.Context, req *TestCredentialsRequest) (*TestCredentialsResult, error)
+}
+
+// CredentialsRequest is the request body for POST /api/v1/credentials
+type CredentialsRequest struct {
+	Name          string `json:"name" binding:"required,max=255"`
+	Username      string `json:"username" binding:"max=255"`
+	Password      string `json:"password"`
+	SSHKey        string `json:"ssh_key"`
+	SNMPCommunity string `json:"snmp_community" binding:"max=255"`
+	SNMPVersion   string `json:"snmp_version" binding:"omitempty,oneof=1 2c 3"`
+	Description   string `json:"description"`
+}
+
+// UpdateCredentialsRequest is the request body for PUT /api/v1/credentials/:id.
+// Nil fields are left unchanged.
+type UpdateCredentialsRequest struct {
+	Name          *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Username      *string `json:"username" binding:"omitempty,max=255"`
+	Password      *string `json:"password"`
+	SSHKey        *string `json:"ssh_key"`
+	SNMPCommunity *string `json:"snmp_community" binding:"omitempty,max=255"`
+	SNMPVersion   *string `json:"snmp_version" binding:"omitempty,oneof=1 2c 3"`
+	Description   *string `json:"description"`
+}
+
+// TestCredentialsRequest is the request body for POST /api/v1/credentials/test
+type TestCredentialsRequest struct {
+	DeviceID      string `json:"device_id" binding:"required"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	SNMPCommunity string `json:"snmp_community"`
+	SNMPVersion   string `json:"snmp_version" binding:"omitempty,oneof=1 2c 3"`
+}
+
+// TestCredentialsResult is the outcome of connecting to a device with the
+// tested credentials. A failed connection is a result, not an error.
+type TestCredentialsResult struct {
+	DeviceID string `json:"device_id"`
+	Protocol string `json:"protocol"`
+	Success  bool   `json:"success"`
+	// Identity is the name the device reported, read to prove the login works
+	Identity string `json:"identity,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type credentialsService struct {
+	repo    repository.CredentialsRepository
+	devices repository.DeviceRepository
+	readers map[model.Protocol]IdentityReader
+}
+
+// NewCredentialsService creates a credentials service that tests credentials
+// by reading the device identity over the Mikrotik API or SNMP.
+func NewCredentialsService(repo repository.CredentialsRepository, devices repository.DeviceRepository) CredentialsService {
+	return NewCredentialsServiceForTest(repo, devices, map[model.Protocol]IdentityReader{
+		model.ProtocolMikrotikAPI: MikrotikIdentityReader{},
+		model.ProtocolSNMP:        NewSNMPIdentityReader(),
+	})
+}
+
+// NewCredentialsServiceForTest creates a credentials service with custom readers
+func NewCredentialsServiceForTest(repo repository.CredentialsRepository, devices repository.DeviceRepository, readers map[model.Protocol]IdentityReader) CredentialsService {
+	return &credentialsService{repo: repo, devices: devices, readers: readers}
+}
+
+func (r *CredentialsRequest) toModel() *model.DeviceCredentials {
+	return &model.DeviceCredentials{
+		Name:              r.Name,
+		Username:          r.Username,
+		PasswordEncrypted: r.Password,
+		SSHKeyEncrypted:   r.SSHKey,
+		SNMPCommunity:     r.SNMPCommunity,
+		SNMPVersion:       r.SNMPVersion,
+		Description:       r.Description,
+	}
+}
+
+func (s *credentialsService) CreateCredentials(ctx context.Context, req *CredentialsRequest) (*model.DeviceCredentials, error) {
+	credentials := req.toModel()
+	if err := s.repo.Create(ctx, credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (s *credentialsService) GetCredentials(ctx context.Context, id string) (*model.DeviceCredentials, error) {
+	credentials, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrCredentialsNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrCredentialsNotFound, id)
+	}
+	return credentials, err
+}
+
+func (s *credentialsService) ListCredentials(ctx context.Context) ([]*model.DeviceCredentials, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *credentialsService) UpdateCredentials(ctx context.Context, id string, req *UpdateCredentialsRequest) (*model.DeviceCredentials, error) {
+	credentials, err := s.GetCredentials(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	setIfPresent(&credentials.Name, req.Name)
+	setIfPresent(&credentials.Username, req.Username)
+	setIfPresent(&credentials.PasswordEncrypted, req.Password)
+	setIfPresent(&credentials.SSHKeyEncrypted, req.SSHKey)
+	setIfPresent(&credentials.SNMPCommunity, req.SNMPCommunity)
+	setIfPresent(&credentials.SNMPVersion, req.SNMPVersion)
+	setIfPresent(&credentials.Description, req.Description)
+
+	err = s.repo.Update(ctx, credentials)
+	if errors.Is(err, repository.ErrCredentialsNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrCredentialsNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func setIfPresent(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func (s *credentialsService) DeleteCredentials(ctx context.Context, id string) error {
+	inUse, err := s.repo.CountDevices(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return fmt.Errorf("%w: %d device(s) use %s", ErrCredentialsInUse, inUse, id)
+	}
+
+	err = s.repo.Delete(ctx, id)
+	if errors.Is(err, repository.ErrCredentialsNotFound) {
+		return fmt.Errorf("%w: %s", ErrCredentialsNotFound, id)
+	}
+	return err
+}
+
+func (s *credentialsService) AttachToDevice(ctx context.Context, deviceID string, credentialsID *string) error {
+	if credentialsID != nil {
+		if _, err := s.GetCredentials(ctx, *credentialsID); err != nil {
+			return err
+		}
+	}
+
+	err := s.repo.AttachToDevice(ctx, deviceID, credentialsID)
+	if errors.Is(err, repository.ErrDeviceNotFound) {
+		return fmt.Errorf("%w: %s", ErrDeviceNotFound, deviceID)
+	}
+	return err
+}
+
+func (s *credentialsService) TestCredentials(ctx context.Context, req *TestCredentialsRequest) (*TestCredentialsResult, error) {
+	device, err := s.devices.GetByID(ctx, req.DeviceID)
+	if errors.Is(err, repository.ErrDeviceNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceNotFound, req.DeviceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reader, ok := s.readers[device.Protocol]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProtocol, device.Protocol)
+	}
+
+	// Connect with the candidate credentials instead of the stored ones.
+	candidate := *device
+	candidate.Credentials = &model.DeviceCredentials{
+		Username:          req.Username,
+		PasswordEncrypted: req.Password,
+		SNMPCommunity:     req.SNMPCommunity,
+		SNMPVersion:       req.SNMPVersion,
+	}
+
+	result := &TestCredentialsResult{DeviceID: device.ID, Protocol: string(device.Protocol)}
+	identity, err := reader.ReadIdentity(ctx, &candidate)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Success = true
+	result.Identity = identity
+	return result, nil
+}
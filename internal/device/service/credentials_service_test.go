@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+// fakeCredentialsRepo keeps credential sets and device attachments in memory.
+type fakeCredentialsRepo struct {
+	sets     map[string]*model.DeviceCredentials
+	attached map[string]*string // device ID -> credentials ID
+	devices  map[string]bool
+}
+
+func newFakeCredentialsRepo(deviceIDs ...string) *fakeCredentialsRepo {
+	f := &fakeCredentialsRepo{
+		sets:     make(map[string]*model.DeviceCredentials),
+		attached: make(map[string]*string),
+		devices:  make(map[string]bool),
+	}
+	for _, id := range deviceIDs {
+		f.devices[id] = true
+	}
+	return f
+}
+
+func (f *fakeCredentialsRepo) Create(ctx context.Context, c *model.DeviceCredentials) error {
+	if c.ID == "" {
+		c.ID = "cred-" + c.Name
+	}
+	stored := *c
+	f.sets[c.ID] = &stored
+	return nil
+}
+
+func (f *fakeCredentialsRepo) GetByID(ctx context.Context, id string) (*model.DeviceCredentials, error) {
+	c, ok := f.sets[id]
+	if !ok {
+		return nil, repository.ErrCredentialsNotFound
+	}
+	loaded := *c
+	return &loaded, nil
+}
+
+func (f *fakeCredentialsRepo) List(ctx context.Context) ([]*model.DeviceCredentials, error) {
+	var out []*model.DeviceCredentials
+	for _, c := range f.sets {
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (f *fakeCredentialsRepo) Update(ctx context.Context, c *model.DeviceCredentials) error {
+	if _, ok := f.sets[c.ID]; !ok {
+		return repository.ErrCredentialsNotFound
+	}
+	stored := *c
+	f.sets[c.ID] = &stored
+	return nil
+}
+
+func (f *fakeCredentialsRepo) Delete(ctx context.Context, id string) error {
+	if _, ok := f.sets[id]; !ok {
+		return repository.ErrCredentialsNotFound
+	}
+	delete(f.sets, id)
+	return nil
+}
+
+func (f *fakeCredentialsRepo) CountDevices(ctx context.Context, id string) (int64, error) {
+	var n int64
+	for _, credentialsID := range f.attached {
+		if credentialsID != nil && *credentialsID == id {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeCredentialsRepo) AttachToDevice(ctx context.Context, deviceID string, credentialsID *string) error {
+	if !f.devices[deviceID] {
+		return repository.ErrDeviceNotFound
+	}
+	f.attached[deviceID] = credentialsID
+	return nil
+}
+
+// loginReader accepts one username and password, like a device would
+type loginReader struct {
+	username, password string
+	tried              *model.Device
+}
+
+func (r *loginReader) ReadIdentity(_ context.Context, device *model.Device) (string, error) {
+	r.tried = device
+	c := device.Credentials
+	if c == nil || c.Username != r.username || c.PasswordEncrypted != r.password {
+		return "", errors.New("invalid user name or password")
+	}
+	return "branch-rtr-7", nil
+}
+
+func TestCredentialsService_CreateAndRotate(t *testing.T) {
+	repo := newFakeCredentialsRepo()
+	svc := service.NewCredentialsServiceForTest(repo, &MockDeviceRepository{}, nil)
+	ctx := context.Background()
+
+	created, err := svc.CreateCredentials(ctx, &service.CredentialsRequest{
+		Name: "branch", Username: "admin", Password: "old-pass", SNMPCommunity: "public", SNMPVersion: "2c",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "old-pass", repo.sets[created.ID].PasswordEncrypted)
+
+	updated, err := svc.UpdateCredentials(ctx, created.ID, &service.UpdateCredentialsRequest{Password: strPtr("new-pass")})
+	require.NoError(t, err)
+	assert.Equal(t, "branch", updated.Name)
+
+	stored := repo.sets[created.ID]
+	assert.Equal(t, "new-pass", stored.PasswordEncrypted)
+	assert.Equal(t, "admin", stored.Username, "fields missing from the update are kept")
+	assert.Equal(t, "public", stored.SNMPCommunity)
+
+	_, err = svc.UpdateCredentials(ctx, "missing", &service.UpdateCredentialsRequest{Password: strPtr("x")})
+	assert.ErrorIs(t, err, service.ErrCredentialsNotFound)
+}
+
+func TestCredentialsService_AttachAndDelete(t *testing.T) {
+	repo := newFakeCredentialsRepo("dev-1")
+	svc := service.NewCredentialsServiceForTest(repo, &MockDeviceRepository{}, nil)
+	ctx := context.Background()
+
+	created, err := svc.CreateCredentials(ctx, &service.CredentialsRequest{Name: "branch", Username: "admin"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.AttachToDevice(ctx, "dev-1", strPtr("missing")), service.ErrCredentialsNotFound)
+	assert.ErrorIs(t, svc.AttachToDevice(ctx, "dev-2", &created.ID), service.ErrDeviceNotFound)
+
+	require.NoError(t, svc.AttachToDevice(ctx, "dev-1", &created.ID))
+	assert.Equal(t, created.ID, *repo.attached["dev-1"])
+
+	assert.ErrorIs(t, svc.DeleteCredentials(ctx, created.ID), service.ErrCredentialsInUse)
+
+	require.NoError(t, svc.AttachToDevice(ctx, "dev-1", nil))
+	assert.Nil(t, repo.attached["dev-1"])
+	require.NoError(t, svc.DeleteCredentials(ctx, created.ID))
+	assert.ErrorIs(t, svc.DeleteCredentials(ctx, created.ID), service.ErrCredentialsNotFound)
+}
+
+func TestCredentialsService_TestCredentials(t *testing.T) {
+	stored := &model.DeviceCredentials{Username: "admin", PasswordEncrypted: "stored-pass"}
+	device := &model.Device{ID: "dev-1", IPAddress: "10.0.0.1", Protocol: model.ProtocolMikrotikAPI, Credentials: stored}
+	devices := &MockDeviceRepository{GetByIDFunc: func(_ context.Context, id string) (*model.Device, error) {
+		switch id {
+		case "dev-1":
+			return device, nil
+		case "broken":
+			return nil, errors.New("connection reset by peer")
+		}
+		return nil, fmt.Errorf("%w: %s", repository.ErrDeviceNotFound, id)
+	}}
+	reader := &loginReader{username: "admin", password: "new-pass"}
+	svc := service.NewCredentialsServiceForTest(newFakeCredentialsRepo(), devices, map[model.Protocol]service.IdentityReader{
+		model.ProtocolMikrotikAPI: reader,
+	})
+	ctx := context.Background()
+
+	result, err := svc.TestCredentials(ctx, &service.TestCredentialsRequest{DeviceID: "dev-1", Username: "admin", Password: "new-pass"})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "branch-rtr-7", result.Identity)
+	assert.Equal(t, "mikrotik_api", result.Protocol)
+	assert.Equal(t, "10.0.0.1", reader.tried.IPAddress)
+	assert.Same(t, stored, device.Credentials, "the stored credentials are not touched")
+
+	result, err = svc.TestCredentials(ctx, &service.TestCredentialsRequest{DeviceID: "dev-1", Username: "admin", Password: "wrong"})
+	require.NoError(t, err, "a failed login is a result")
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "invalid user name or password")
+
+	_, err = svc.TestCredentials(ctx, &service.TestCredentialsRequest{DeviceID: "missing"})
+	assert.ErrorIs(t, err, service.ErrDeviceNotFound)
+
+	_, err = svc.TestCredentials(ctx, &service.TestCredentialsRequest{DeviceID: "broken"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, service.ErrDeviceNotFound, "a failed lookup is not a missing device")
+
+	device.Protocol = model.ProtocolSSH
+	_, err = svc.TestCredentials(ctx, &service.TestCredentialsRequest{DeviceID: "dev-1"})
+	assert.ErrorIs(t, err, service.ErrUnsupportedProtocol)
+}
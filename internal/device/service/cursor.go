@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+// ErrInvalidCursor is returned for an after cursor that was not issued as a
+// next_cursor by ListDevicesAfter.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func (s *deviceService) ListDevicesAfter(ctx context.Context, after string, pageSize int) ([]*model.Device, string, error) {
+	filter := &repository.DeviceFilter{OldestFirst: true}
+	if after != "" {
+		cursor, err := decodeCursor(after)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = cursor
+	}
+
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	// One extra row tells whether there is a next page without a count.
+	filter.Limit = pageSize + 1
+
+	devices, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(devices) <= pageSize {
+		return devices, "", nil
+	}
+
+	devices = devices[:pageSize]
+	return devices, encodeCursor(devices[pageSize-1]), nil
+}
+
+// encodeCursor returns the opaque cursor positioned just after device.
+func encodeCursor(device *model.Device) string {
+	raw := device.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + device.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*repository.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	// Device IDs are UUIDs; anything else would fail in the query as a 500.
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	return &repository.Cursor{CreatedAt: t, ID: id}, nil
+}
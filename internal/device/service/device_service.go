@@ -9,18 +9,40 @@ import (
 	"github.com/yourorg/nms-go/internal/device/repository"
 )
 
+// ErrDeviceExists is returned by RegisterDevice when a device with the same
+// IP address is already registered.
+var ErrDeviceExists = errors.New("device with this IP address already exists")
+
+// Pagination bounds for ListDevices. Larger page sizes are clamped to
+// MaxPageSize.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
 type DeviceService interface {
 	RegisterDevice(ctx context.Context, req *RegisterDeviceRequest) (*model.Device, error)
 	GetDevice(ctx context.Context, id string) (*model.Device, error)
 	ListDevices(ctx context.Context, page, pageSize int) ([]*model.Device, int64, error)
+	// ListDevicesAfter pages through devices in creation order by keyset:
+	// after is "" for the first page, then the cursor returned with the
+	// previous page. The returned cursor is "" after the last page.
+	ListDevicesAfter(ctx context.Context, after string, pageSize int) ([]*model.Device, string, error)
+	// ListRecentDevices pages through devices updated after since, most
+	// recently updated first.
+	ListRecentDevices(ctx context.Context, since time.Time, page, pageSize int) ([]*model.Device, int64, error)
+	BulkUpdate(ctx context.Context, req *BulkUpdateRequest) (*BulkUpdateResponse, error)
 }
 
 type deviceService struct {
-	repo repository.DeviceRepository
+	repo     repository.DeviceRepository
+	tagRules repository.TagRuleRepository
 }
 
-func NewDeviceService(repo repository.DeviceRepository) DeviceService {
-	return &deviceService{repo: repo}
+// NewDeviceService creates a device service. tagRules may be nil, in which
+// case registered devices are not tagged automatically.
+func NewDeviceService(repo repository.DeviceRepository, tagRules repository.TagRuleRepository) DeviceService {
+	return &deviceService{repo: repo, tagRules: tagRules}
 }
 
 type RegisterDeviceRequest struct {
@@ -29,14 +51,16 @@ type RegisterDeviceRequest struct {
 	DeviceType      model.DeviceType   `json:"device_type"`
 	Protocol        model.Protocol     `json:"protocol"`
 	PollingInterval int                `json:"polling_interval"`
+	Priority        int                `json:"priority"`
 	Tags            []string           `json:"tags"`
 }
 
 func (s *deviceService) RegisterDevice(ctx context.Context, req *RegisterDeviceRequest) (*model.Device, error) {
-	// Check if device with same IP already exists
+	// Fast path only: concurrent registrations can both get past this check,
+	// so the unique index behind repo.Create is what actually rejects duplicates.
 	existing, _ := s.repo.GetByIPAddress(ctx, req.IPAddress)
 	if existing != nil {
-		return nil, errors.New("device with this IP address already exists")
+		return nil, ErrDeviceExists
 	}
 
 	device := &model.Device{
@@ -45,10 +69,9 @@ func (s *deviceService) RegisterDevice(ctx context.Context, req *RegisterDeviceR
 		DeviceType:      req.DeviceType,
 		Protocol:        req.Protocol,
 		PollingInterval: req.PollingInterval,
+		Priority:        req.Priority,
 		Tags:            req.Tags,
 		Status:          model.DeviceStatusUnknown,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
 		Enabled:         true,
 	}
 
@@ -56,7 +79,12 @@ func (s *deviceService) RegisterDevice(ctx context.Context, req *RegisterDeviceR
 		device.PollingInterval = 300 // Default 5 mins
 	}
 
+	ApplyTagRules(device, loadTagRules(ctx, s.tagRules))
+
 	err := s.repo.Create(ctx, device)
+	if errors.Is(err, repository.ErrDuplicateIPAddress) {
+		return nil, ErrDeviceExists
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -69,28 +97,37 @@ func (s *deviceService) GetDevice(ctx context.Context, id string) (*model.Device
 }
 
 func (s *deviceService) ListDevices(ctx context.Context, page, pageSize int) ([]*model.Device, int64, error) {
+	return s.listPage(ctx, &repository.DeviceFilter{}, page, pageSize)
+}
+
+func (s *deviceService) ListRecentDevices(ctx context.Context, since time.Time, page, pageSize int) ([]*model.Device, int64, error) {
+	return s.listPage(ctx, &repository.DeviceFilter{UpdatedAfter: &since, RecentFirst: true}, page, pageSize)
+}
+
+// listPage lists one page of the devices matching filter, clamping page and
+// pageSize, and counts every match.
+func (s *deviceService) listPage(ctx context.Context, filter *repository.DeviceFilter, page, pageSize int) ([]*model.Device, int64, error) {
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
 	}
-	offset := (page - 1) * pageSize
-	
-	filter := &repository.DeviceFilter{
-		Limit:  pageSize,
-		Offset: offset,
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
 	}
-	
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
 	devices, err := s.repo.List(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
-	
+
 	count, err := s.repo.Count(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
-	
+
 	return devices, count, nil
 }
@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/device/repository/repositorytest"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+func TestRegisterDevice_ExistingIPFastPath(t *testing.T) {
+	created := false
+	repo := &MockDeviceRepository{
+		GetByIPAddressFunc: func(ctx context.Context, ip string) (*model.Device, error) {
+			return &model.Device{ID: "dev-1", IPAddress: ip}, nil
+		},
+		CreateFunc: func(ctx context.Context, device *model.Device) error {
+			created = true
+			return nil
+		},
+	}
+	svc := service.NewDeviceService(repo, nil)
+
+	_, err := svc.RegisterDevice(context.Background(), &service.RegisterDeviceRequest{Name: "r1", IPAddress: "10.0.0.1"})
+
+	assert.ErrorIs(t, err, service.ErrDeviceExists)
+	assert.False(t, created)
+}
+
+func TestRegisterDevice_TranslatesDuplicateFromCreate(t *testing.T) {
+	repo := &MockDeviceRepository{
+		CreateFunc: func(ctx context.Context, device *model.Device) error {
+			return fmt.Errorf("%w: %s", repository.ErrDuplicateIPAddress, device.IPAddress)
+		},
+	}
+	svc := service.NewDeviceService(repo, nil)
+
+	_, err := svc.RegisterDevice(context.Background(), &service.RegisterDeviceRequest{Name: "r1", IPAddress: "10.0.0.1"})
+
+	require.ErrorIs(t, err, service.ErrDeviceExists)
+	assert.Equal(t, "device with this IP address already exists", err.Error())
+}
+
+// uniqueIPRepository enforces a unique IP on Create the way the database
+// index does, and holds lookups until every registration has made one so
+// that all of them pass the fast-path check.
+type uniqueIPRepository struct {
+	MockDeviceRepository
+	lookups sync.WaitGroup
+	mu      sync.Mutex
+	byIP    map[string]*model.Device
+}
+
+func (r *uniqueIPRepository) GetByIPAddress(ctx context.Context, ip string) (*model.Device, error) {
+	r.mu.Lock()
+	existing := r.byIP[ip]
+	r.mu.Unlock()
+	r.lookups.Done()
+	r.lookups.Wait()
+	return existing, nil
+}
+
+func (r *uniqueIPRepository) Create(ctx context.Context, device *model.Device) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byIP[device.IPAddress]; ok {
+		return fmt.Errorf("%w: %s", repository.ErrDuplicateIPAddress, device.IPAddress)
+	}
+	r.byIP[device.IPAddress] = device
+	return nil
+}
+
+func TestRegisterDevice_ConcurrentDuplicates(t *testing.T) {
+	const n = 8
+	repo := &uniqueIPRepository{byIP: make(map[string]*model.Device)}
+	repo.lookups.Add(n)
+	svc := service.NewDeviceService(repo, nil)
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = svc.RegisterDevice(context.Background(), &service.RegisterDeviceRequest{
+				Name:      fmt.Sprintf("r%d", i),
+				IPAddress: "10.0.0.1",
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, service.ErrDeviceExists)
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Len(t, repo.byIP, 1)
+}
+
+func TestRegisterDevice_StoresDefaultsInMemory(t *testing.T) {
+	repo := repositorytest.NewDeviceRepository()
+	svc := service.NewDeviceService(repo, nil)
+	ctx := context.Background()
+
+	device, err := svc.RegisterDevice(ctx, &service.RegisterDeviceRequest{
+		Name:       "olt-1",
+		IPAddress:  "10.0.0.1",
+		DeviceType: model.DeviceTypeOLT,
+		Protocol:   model.ProtocolSNMP,
+	})
+	require.NoError(t, err)
+
+	stored, err := svc.GetDevice(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "olt-1", stored.Name)
+	assert.Equal(t, 300, stored.PollingInterval)
+	assert.Equal(t, model.DeviceStatusUnknown, stored.Status)
+	assert.True(t, stored.Enabled)
+
+	_, err = svc.RegisterDevice(ctx, &service.RegisterDeviceRequest{Name: "olt-2", IPAddress: "10.0.0.1"})
+	assert.ErrorIs(t, err, service.ErrDeviceExists)
+}
+
+func TestListDevices_PagesInMemory(t *testing.T) {
+	// Devices are listed in creation order; tick so none share a timestamp.
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := repositorytest.NewDeviceRepositoryWithClock(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+	svc := service.NewDeviceService(repo, nil)
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		_, err := svc.RegisterDevice(ctx, &service.RegisterDeviceRequest{Name: fmt.Sprintf("r%d", i), IPAddress: fmt.Sprintf("10.0.0.%d", i)})
+		require.NoError(t, err)
+	}
+
+	devices, total, err := svc.ListDevices(ctx, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "r3", devices[0].Name)
+	assert.Equal(t, "r4", devices[1].Name)
+}
+
+func TestListRecentDevices_FiltersInMemory(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := repositorytest.NewDeviceRepositoryWithClock(func() time.Time { return now },
+		&model.Device{ID: "old", Name: "old", IPAddress: "10.0.0.1"},
+	)
+	now = now.Add(time.Hour)
+	require.NoError(t, repo.Create(context.Background(), &model.Device{ID: "new", Name: "new", IPAddress: "10.0.0.2"}))
+	svc := service.NewDeviceService(repo, nil)
+
+	devices, total, err := svc.ListRecentDevices(context.Background(), now.Add(-time.Minute), 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "new", devices[0].ID)
+}
+
+func TestListRecentDevices_IgnoresStatusUpdates(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := repositorytest.NewDeviceRepositoryWithClock(func() time.Time { return now },
+		&model.Device{ID: "polled", Name: "polled", IPAddress: "10.0.0.1"},
+	)
+	svc := service.NewDeviceService(repo, nil)
+
+	now = now.Add(time.Hour)
+	require.NoError(t, repo.UpdateStatusDetails(context.Background(), "polled", model.DeviceStatusOffline, nil, "timeout"))
+
+	devices, total, err := svc.ListRecentDevices(context.Background(), now.Add(-time.Minute), 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total, "a poll outcome is not a change to the device")
+	assert.Empty(t, devices)
+}
+
+func TestListDevicesAfter_IteratesAllPages(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := repositorytest.NewDeviceRepositoryWithClock(func() time.Time { return now })
+	svc := service.NewDeviceService(repo, nil)
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		_, err := svc.RegisterDevice(ctx, &service.RegisterDeviceRequest{Name: fmt.Sprintf("r%d", i), IPAddress: fmt.Sprintf("10.0.0.%d", i)})
+		require.NoError(t, err)
+	}
+
+	seen := make(map[string]bool)
+	after := ""
+	for pages := 1; ; pages++ {
+		require.LessOrEqual(t, pages, 3)
+		devices, next, err := svc.ListDevicesAfter(ctx, after, 2)
+		require.NoError(t, err)
+		for _, d := range devices {
+			assert.False(t, seen[d.Name], "%s listed twice", d.Name)
+			seen[d.Name] = true
+		}
+		if next == "" {
+			assert.Equal(t, 3, pages)
+			break
+		}
+		after = next
+	}
+	assert.Len(t, seen, 5)
+}
+
+func TestListDevicesAfter_RejectsInvalidCursor(t *testing.T) {
+	svc := service.NewDeviceService(repositorytest.NewDeviceRepository(), nil)
+
+	notUUID := base64.RawURLEncoding.EncodeToString([]byte("2024-05-01T12:00:00Z,d1"))
+	for _, after := range []string{"not base64!", "bm9jb21tYQ", "eWVzdGVyZGF5LGQx", notUUID} {
+		_, _, err := svc.ListDevicesAfter(context.Background(), after, 10)
+		assert.ErrorIs(t, err, service.ErrInvalidCursor, after)
+	}
+}
@@ -1,31 +1,103 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log"
 	"net"
 	"os/exec"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+// Metadata keys set on discovered devices
+const (
+	// MetadataHardwareID holds the stable identifier (serial or MAC) used for deduplication
+	MetadataHardwareID = "hardware_id"
+	// MetadataSecondaryIPs lists other addresses the same device answered on
+	MetadataSecondaryIPs = "secondary_ips"
+)
+
+// Ping probe defaults and limits for discovery
+const (
+	DefaultPingCount     = 1
+	DefaultPingTimeoutMs = 1000
+	MaxPingCount         = 10
+	MaxPingTimeoutMs     = 10000
 )
 
 type DiscoveryService interface {
-	ScanSubnet(ctx context.Context, cidr string) ([]*model.Device, error)
+	ScanSubnet(ctx context.Context, req *DiscoveryRequest) ([]*model.Device, error)
+	// DiscoverAndSave scans the subnet and persists devices that are not
+	// already registered, returning only the newly created ones.
+	DiscoverAndSave(ctx context.Context, req *DiscoveryRequest) ([]*model.Device, error)
+}
+
+// DiscoveryRequest describes a subnet scan. Raising the ping count or timeout
+// finds flaky hosts at the cost of a slower scan.
+type DiscoveryRequest struct {
+	CIDR          string `json:"cidr"`
+	PingCount     int    `json:"ping_count"`      // ICMP echo requests per host, default 1
+	PingTimeoutMs int    `json:"ping_timeout_ms"` // wait per reply, default 1000
+}
+
+// PingOptions controls the ICMP probe sent to each address
+type PingOptions struct {
+	Count   int
+	Timeout time.Duration
+}
+
+// Pinger reports whether a host answered any of the probes
+type Pinger func(ctx context.Context, ip string, opts PingOptions) bool
+
+// Identifier resolves a stable hardware identifier for a host. An empty
+// result means the host could not be identified.
+type Identifier interface {
+	Identify(ctx context.Context, ip string) (string, error)
+}
+
+// VendorDetector is optionally implemented by an Identifier that can also
+// tell which vendor made a host. An empty result means unknown.
+type VendorDetector interface {
+	DetectVendor(ctx context.Context, ip string) (string, error)
 }
 
 type discoveryService struct {
+	repo       repository.DeviceRepository
+	identifier Identifier
+	tagRules   repository.TagRuleRepository
+	ping       Pinger
 }
 
-func NewDiscoveryService() DiscoveryService {
-	return &discoveryService{}
+// NewDiscoveryService creates a discovery service. identifier may be nil, in
+// which case devices are only deduplicated by IP address. tagRules may be
+// nil, in which case discovered devices are not tagged automatically.
+func NewDiscoveryService(repo repository.DeviceRepository, identifier Identifier, tagRules repository.TagRuleRepository) DiscoveryService {
+	return &discoveryService{repo: repo, identifier: identifier, tagRules: tagRules, ping: checkPing}
 }
 
-func (s *discoveryService) ScanSubnet(ctx context.Context, cidr string) ([]*model.Device, error) {
-	ip, ipnet, err := net.ParseCIDR(cidr)
+// NewDiscoveryServiceForTest creates a discovery service with a custom ping probe
+func NewDiscoveryServiceForTest(repo repository.DeviceRepository, identifier Identifier, tagRules repository.TagRuleRepository, ping Pinger) DiscoveryService {
+	return &discoveryService{repo: repo, identifier: identifier, tagRules: tagRules, ping: ping}
+}
+
+// ScanSubnet pings every address in the subnet. Hosts that resolve to the
+// same hardware identifier are reported once, under their lowest address.
+func (s *discoveryService) ScanSubnet(ctx context.Context, req *DiscoveryRequest) ([]*model.Device, error) {
+	ip, ipnet, err := net.ParseCIDR(req.CIDR)
 	if err != nil {
 		return nil, fmt.Errorf("invalid CIDR: %w", err)
 	}
+	opts, err := req.pingOptions()
+	if err != nil {
+		return nil, err
+	}
 
 	var devices []*model.Device
 	var mu sync.Mutex
@@ -41,21 +113,158 @@ func (s *discoveryService) ScanSubnet(ctx context.Context, cidr string) ([]*mode
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			if checkPing(targetIP) {
-				mu.Lock()
-				devices = append(devices, &model.Device{
-					Name:       fmt.Sprintf("Discovered Device %s", targetIP),
-					IPAddress:  targetIP,
-					DeviceType: model.DeviceTypeSwitch, // Default guess
-					Status:     model.DeviceStatusOnline,
-				})
-				mu.Unlock()
+			if !s.ping(ctx, targetIP, opts) {
+				return
 			}
+
+			device := &model.Device{
+				Name:       fmt.Sprintf("Discovered Device %s", targetIP),
+				IPAddress:  targetIP,
+				DeviceType: model.DeviceTypeSwitch, // Default guess
+				Status:     model.DeviceStatusOnline,
+			}
+			if id := s.identify(ctx, targetIP); id != "" {
+				device.Metadata = model.JSONMap{MetadataHardwareID: id}
+			}
+			if vendor := s.detectVendor(ctx, targetIP); vendor != "" {
+				if device.Metadata == nil {
+					device.Metadata = model.JSONMap{}
+				}
+				device.Metadata[MetadataVendor] = vendor
+			}
+
+			mu.Lock()
+			devices = append(devices, device)
+			mu.Unlock()
 		}(currentIP)
 	}
 
 	wg.Wait()
-	return devices, nil
+	return dedupeByHardwareID(devices), nil
+}
+
+// DiscoverAndSave scans the subnet and creates a device record for each host
+// not already known by IP address or hardware identifier.
+func (s *discoveryService) DiscoverAndSave(ctx context.Context, req *DiscoveryRequest) ([]*model.Device, error) {
+	discovered, err := s.ScanSubnet(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	known := make(map[string]bool, len(existing))
+	for _, d := range existing {
+		if id := hardwareID(d); id != "" {
+			known[id] = true
+		}
+	}
+
+	rules := loadTagRules(ctx, s.tagRules)
+
+	var created []*model.Device
+	for _, device := range discovered {
+		if id := hardwareID(device); id != "" && known[id] {
+			continue
+		}
+		if d, _ := s.repo.GetByIPAddress(ctx, device.IPAddress); d != nil {
+			continue
+		}
+
+		device.Protocol = model.ProtocolSNMP
+		device.PollingInterval = 300
+		device.Enabled = true
+		ApplyTagRules(device, rules)
+		if err := s.repo.Create(ctx, device); err != nil {
+			return created, fmt.Errorf("failed to create device %s: %w", device.IPAddress, err)
+		}
+		if id := hardwareID(device); id != "" {
+			known[id] = true
+		}
+		created = append(created, device)
+	}
+
+	return created, nil
+}
+
+// pingOptions applies defaults and validates the probe settings
+func (r *DiscoveryRequest) pingOptions() (PingOptions, error) {
+	count, timeoutMs := r.PingCount, r.PingTimeoutMs
+	if count == 0 {
+		count = DefaultPingCount
+	}
+	if timeoutMs == 0 {
+		timeoutMs = DefaultPingTimeoutMs
+	}
+	if count < 1 || count > MaxPingCount {
+		return PingOptions{}, fmt.Errorf("ping_count must be between 1 and %d", MaxPingCount)
+	}
+	if timeoutMs < 1 || timeoutMs > MaxPingTimeoutMs {
+		return PingOptions{}, fmt.Errorf("ping_timeout_ms must be between 1 and %d", MaxPingTimeoutMs)
+	}
+	return PingOptions{Count: count, Timeout: time.Duration(timeoutMs) * time.Millisecond}, nil
+}
+
+func (s *discoveryService) identify(ctx context.Context, ip string) string {
+	if s.identifier == nil {
+		return ""
+	}
+	id, err := s.identifier.Identify(ctx, ip)
+	if err != nil {
+		log.Printf("Discovery: could not identify %s: %v", ip, err)
+		return ""
+	}
+	return id
+}
+
+func (s *discoveryService) detectVendor(ctx context.Context, ip string) string {
+	detector, ok := s.identifier.(VendorDetector)
+	if !ok {
+		return ""
+	}
+	vendor, err := detector.DetectVendor(ctx, ip)
+	if err != nil {
+		log.Printf("Discovery: could not detect vendor of %s: %v", ip, err)
+		return ""
+	}
+	return vendor
+}
+
+// dedupeByHardwareID collapses devices sharing a hardware identifier into the
+// one with the lowest IP address; the other addresses are kept in metadata.
+func dedupeByHardwareID(devices []*model.Device) []*model.Device {
+	sort.Slice(devices, func(i, j int) bool {
+		return bytes.Compare(ipKey(devices[i].IPAddress), ipKey(devices[j].IPAddress)) < 0
+	})
+
+	primary := make(map[string]*model.Device)
+	result := make([]*model.Device, 0, len(devices))
+	for _, d := range devices {
+		id := hardwareID(d)
+		if id == "" {
+			result = append(result, d)
+			continue
+		}
+		if p, ok := primary[id]; ok {
+			secondary, _ := p.Metadata[MetadataSecondaryIPs].([]string)
+			p.Metadata[MetadataSecondaryIPs] = append(secondary, d.IPAddress)
+			continue
+		}
+		primary[id] = d
+		result = append(result, d)
+	}
+	return result
+}
+
+func hardwareID(d *model.Device) string {
+	id, _ := d.Metadata[MetadataHardwareID].(string)
+	return id
+}
+
+func ipKey(ip string) []byte {
+	return net.ParseIP(ip).To16()
 }
 
 func inc(ip net.IP) {
@@ -67,14 +276,20 @@ func inc(ip net.IP) {
 	}
 }
 
-func checkPing(ip string) bool {
+func checkPing(ctx context.Context, ip string, opts PingOptions) bool {
 	// Simple ping command wrapper
-	// Note: This relies on system 'ping' command
-	cmd := exec.Command("ping", "-c", "1", "-W", "1", ip)
+	// Note: This relies on system 'ping' command, which exits 0 if any reply arrived
+	cmd := exec.CommandContext(ctx, "ping", PingArgs(ip, opts)...)
 	err := cmd.Run()
 	return err == nil
 }
 
+// PingArgs builds the system ping arguments for the probe options
+func PingArgs(ip string, opts PingOptions) []string {
+	timeout := strconv.FormatFloat(opts.Timeout.Seconds(), 'f', -1, 64)
+	return []string{"-c", strconv.Itoa(opts.Count), "-W", timeout, ip}
+}
+
 // Simple fingerprinting logic (future enhancement)
 func fingerprint(ip string) model.DeviceType {
 	// Try SSH banner grabbing or SNMP OID check
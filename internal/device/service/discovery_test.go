@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+// fakeIdentifier maps IP addresses to hardware identifiers
+type fakeIdentifier map[string]string
+
+func (f fakeIdentifier) Identify(ctx context.Context, ip string) (string, error) {
+	return f[ip], nil
+}
+
+func pingOnly(alive ...string) service.Pinger {
+	set := make(map[string]bool, len(alive))
+	for _, ip := range alive {
+		set[ip] = true
+	}
+	return func(ctx context.Context, ip string, opts service.PingOptions) bool { return set[ip] }
+}
+
+func recordingRepo(existing []*model.Device, created *[]*model.Device) *MockDeviceRepository {
+	var mu sync.Mutex
+	return &MockDeviceRepository{
+		ListFunc: func(ctx context.Context, filter *repository.DeviceFilter) ([]*model.Device, error) {
+			return existing, nil
+		},
+		GetByIPAddressFunc: func(ctx context.Context, ip string) (*model.Device, error) {
+			for _, d := range existing {
+				if d.IPAddress == ip {
+					return d, nil
+				}
+			}
+			return nil, errors.New("device not found")
+		},
+		CreateFunc: func(ctx context.Context, device *model.Device) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*created = append(*created, device)
+			return nil
+		},
+	}
+}
+
+func TestDiscoverAndSave_DedupesSameSerial(t *testing.T) {
+	var created []*model.Device
+	ids := fakeIdentifier{
+		"10.0.0.2": "serial:ABC123",
+		"10.0.0.5": "serial:ABC123",
+		"10.0.0.9": "serial:XYZ789",
+	}
+	svc := service.NewDiscoveryServiceForTest(recordingRepo(nil, &created), ids, nil, pingOnly("10.0.0.2", "10.0.0.5", "10.0.0.9"))
+
+	result, err := svc.DiscoverAndSave(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.0/28"})
+	require.NoError(t, err)
+
+	require.Len(t, created, 2)
+	assert.Equal(t, created, result)
+	assert.Equal(t, "10.0.0.2", created[0].IPAddress)
+	assert.Equal(t, "serial:ABC123", created[0].Metadata[service.MetadataHardwareID])
+	assert.Equal(t, []string{"10.0.0.5"}, created[0].Metadata[service.MetadataSecondaryIPs])
+	assert.Equal(t, "10.0.0.9", created[1].IPAddress)
+}
+
+func TestDiscoverAndSave_SkipsKnownDevices(t *testing.T) {
+	var created []*model.Device
+	existing := []*model.Device{
+		{ID: "dev-1", IPAddress: "10.0.0.1", Metadata: model.JSONMap{service.MetadataHardwareID: "serial:ABC123"}},
+		{ID: "dev-2", IPAddress: "10.0.0.3"},
+	}
+	ids := fakeIdentifier{"10.0.0.2": "serial:ABC123"}
+	svc := service.NewDiscoveryServiceForTest(recordingRepo(existing, &created), ids, nil, pingOnly("10.0.0.2", "10.0.0.3", "10.0.0.4"))
+
+	_, err := svc.DiscoverAndSave(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.0/29"})
+	require.NoError(t, err)
+
+	// 10.0.0.2 matches dev-1 by serial, 10.0.0.3 is already registered by IP
+	require.Len(t, created, 1)
+	assert.Equal(t, "10.0.0.4", created[0].IPAddress)
+}
+
+func TestScanSubnet_UnidentifiedHostsAreKept(t *testing.T) {
+	svc := service.NewDiscoveryServiceForTest(&MockDeviceRepository{}, nil, nil, pingOnly("10.0.0.1", "10.0.0.2"))
+
+	devices, err := svc.ScanSubnet(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.0/30"})
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "10.0.0.1", devices[0].IPAddress)
+	assert.Equal(t, "10.0.0.2", devices[1].IPAddress)
+}
+
+func TestScanSubnet_UsesConfiguredPingOptions(t *testing.T) {
+	var mu sync.Mutex
+	var seen []service.PingOptions
+	ping := func(ctx context.Context, ip string, opts service.PingOptions) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, opts)
+		return false
+	}
+	svc := service.NewDiscoveryServiceForTest(&MockDeviceRepository{}, nil, nil, ping)
+
+	_, err := svc.ScanSubnet(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.0/30", PingCount: 3, PingTimeoutMs: 2500})
+	require.NoError(t, err)
+
+	require.Len(t, seen, 4)
+	for _, opts := range seen {
+		assert.Equal(t, service.PingOptions{Count: 3, Timeout: 2500 * time.Millisecond}, opts)
+	}
+	assert.Equal(t, []string{"-c", "3", "-W", "2.5", "10.0.0.1"}, service.PingArgs("10.0.0.1", seen[0]))
+}
+
+func TestScanSubnet_PingDefaultsAndLimits(t *testing.T) {
+	var got service.PingOptions
+	ping := func(ctx context.Context, ip string, opts service.PingOptions) bool {
+		got = opts
+		return false
+	}
+	svc := service.NewDiscoveryServiceForTest(&MockDeviceRepository{}, nil, nil, ping)
+
+	_, err := svc.ScanSubnet(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.1/32"})
+	require.NoError(t, err)
+	assert.Equal(t, service.PingOptions{Count: 1, Timeout: time.Second}, got)
+
+	_, err = svc.ScanSubnet(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.1/32", PingCount: service.MaxPingCount + 1})
+	assert.Error(t, err)
+	_, err = svc.ScanSubnet(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.1/32", PingTimeoutMs: -1})
+	assert.Error(t, err)
+}
+
+// mockSNMPClient serves fixed ENTITY-MIB and IF-MIB responses
+type mockSNMPClient struct {
+	serial string
+	macs   [][]byte
+}
+
+func (m *mockSNMPClient) Connect(_ context.Context, _, _ string, _ gosnmp.SnmpVersion, _ time.Duration) error {
+	return nil
+}
+
+func (m *mockSNMPClient) Disconnect() error { return nil }
+
+func (m *mockSNMPClient) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	if m.serial == "" {
+		return &gosnmp.SnmpPacket{Variables: []gosnmp.SnmpPDU{{Name: oids[0], Type: gosnmp.NoSuchInstance}}}, nil
+	}
+	return &gosnmp.SnmpPacket{Variables: []gosnmp.SnmpPDU{{Name: oids[0], Type: gosnmp.OctetString, Value: []byte(m.serial)}}}, nil
+}
+
+func (m *mockSNMPClient) Walk(_ string, fn gosnmp.WalkFunc) error {
+	for _, mac := range m.macs {
+		if err := fn(gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: mac}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockSNMPClient) GetBulk(_ []string, _ uint8, _ uint32) (*gosnmp.SnmpPacket, error) {
+	return &gosnmp.SnmpPacket{}, nil
+}
+
+func TestSNMPIdentifier(t *testing.T) {
+	id, err := service.NewSNMPIdentifierForTest(&mockSNMPClient{serial: " ABC123 "}).Identify(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "serial:ABC123", id)
+
+	// Without a serial, the lowest non-zero interface MAC is used
+	client := &mockSNMPClient{macs: [][]byte{
+		{0x4c, 0x5e, 0x0c, 0x00, 0x00, 0x02},
+		{0, 0, 0, 0, 0, 0},
+		{0x4c, 0x5e, 0x0c, 0x00, 0x00, 0x01},
+	}}
+	id, err = service.NewSNMPIdentifierForTest(client).Identify(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "mac:4c:5e:0c:00:00:01", id)
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+var (
+	// ErrGroupNotFound is returned when no device group has the ID.
+	ErrGroupNotFound = errors.New("device group not found")
+
+	// ErrGroupNotEmpty is returned when deleting a group that still has
+	// child groups or devices.
+	ErrGroupNotEmpty = errors.New("device group is not empty")
+
+	// ErrInvalidGroupParent is returned when the parent does not exist or
+	// would make the hierarchy a cycle.
+	ErrInvalidGroupParent = errors.New("invalid parent group")
+
+	// ErrDuplicateGroupName is returned when another group already has the name.
+	ErrDuplicateGroupName = errors.New("duplicate device group name")
+)
+
+// GroupService manages the device group hierarchy
+type GroupService interface {
+	CreateGroup(ctx context.Context, req *GroupRequest) (*model.DeviceGroup, error)
+	GetGroup(ctx context.Context, id string) (*model.DeviceGroup, error)
+	ListGroups(ctx context.Context) ([]*model.DeviceGroup, error)
+	UpdateGroup(ctx context.Context, id string, req *UpdateGroupRequest) (*model.DeviceGroup, error)
+	// DeleteGroup deletes an empty group; one with child groups or devices
+	// returns ErrGroupNotEmpty.
+	DeleteGroup(ctx context.Context, id string) error
+	// GetTree returns the top-level groups with their descendants nested.
+	GetTree(ctx context.Context) ([]*GroupNode, error)
+	// ListGroupDevices returns the devices in a group and, when recursive,
+	// in all of its descendants.
+	ListGroupDevices(ctx context.Context, id string, recursive bool) ([]*model.Device, error)
+	// MoveDevices moves devices into a group, wherever they were before.
+	MoveDevices(ctx context.Context, id string, req *MoveDevicesRequest) (*BulkUpdateResponse, error)
+	// DeviceGroupPath returns the group of a device followed by its
+	// ancestors up to the top level; empty if the device has no group.
+	DeviceGroupPath(ctx context.Context, deviceID string) ([]*model.DeviceGroup, error)
+}
+
+// GroupRequest is the request body for POST /api/v1/groups
+type GroupRequest struct {
+	Name        string  `json:"name" binding:"required,max=255"`
+	ParentID    *string `json:"parent_id"`
+	Description string  `json:"description"`
+}
+
+// UpdateGroupRequest is the request body for PUT /api/v1/groups/:id.
+// Nil fields are left unchanged; an empty parent_id makes the group top-level.
+type UpdateGroupRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	ParentID    *string `json:"parent_id"`
+	Description *string `json:"description"`
+}
+
+// MoveDevicesRequest is the request body for POST /api/v1/groups/:id/devices
+type MoveDevicesRequest struct {
+	DeviceIDs []string `json:"device_ids" binding:"required,min=1"`
+}
+
+// GroupNode is a group in the tree returned by GetTree
+type GroupNode struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	ParentID    *string `json:"parent_id,omitempty"`
+	Description string  `json:"description"`
+	// DeviceCount counts the devices directly in the group
+	DeviceCount int64 `json:"device_count"`
+	// TotalDeviceCount also counts the devices in all descendants
+	TotalDeviceCount int64        `json:"total_device_count"`
+	Children         []*GroupNode `json:"children"`
+}
+
+type groupService struct {
+	repo    repository.GroupRepository
+	devices repository.DeviceRepository
+}
+
+// NewGroupService creates a new group service
+func NewGroupService(repo repository.GroupRepository, devices repository.DeviceRepository) GroupService {
+	return &groupService{repo: repo, devices: devices}
+}
+
+func (s *groupService) CreateGroup(ctx context.Context, req *GroupRequest) (*model.DeviceGroup, error) {
+	group := &model.DeviceGroup{Name: req.Name, Description: req.Description}
+	if req.ParentID != nil && *req.ParentID != "" {
+		if _, err := s.repo.GetByID(ctx, *req.ParentID); err != nil {
+			return nil, s.parentError(err, *req.ParentID)
+		}
+		parentID := *req.ParentID
+		group.ParentID = &parentID
+	}
+
+	if err := s.repo.Create(ctx, group); err != nil {
+		return nil, s.mapError(err, group.Name)
+	}
+	return group, nil
+}
+
+func (s *groupService) GetGroup(ctx context.Context, id string) (*model.DeviceGroup, error) {
+	group, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, s.mapError(err, id)
+	}
+	return group, nil
+}
+
+func (s *groupService) ListGroups(ctx context.Context) ([]*model.DeviceGroup, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *groupService) UpdateGroup(ctx context.Context, id string, req *UpdateGroupRequest) (*model.DeviceGroup, error) {
+	group, err := s.GetGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	setIfPresent(&group.Name, req.Name)
+	setIfPresent(&group.Description, req.Description)
+	switch {
+	case req.ParentID != nil && *req.ParentID != "":
+		// The check and the update share a transaction, so two groups moved
+		// under each other at once cannot both pass it.
+		parentID := *req.ParentID
+		group.ParentID = &parentID
+		err = s.repo.Reparent(ctx, group, func(groups []*model.DeviceGroup) error {
+			return checkParent(groups, id, parentID)
+		})
+	case req.ParentID != nil:
+		group.ParentID = nil
+		err = s.repo.Update(ctx, group)
+	default:
+		err = s.repo.Update(ctx, group)
+	}
+	if err != nil {
+		return nil, s.mapError(err, id)
+	}
+	return group, nil
+}
+
+// checkParent rejects a parent that does not exist, or is the group itself
+// or one of its descendants.
+func checkParent(groups []*model.DeviceGroup, id, parentID string) error {
+	exists := false
+	for _, g := range groups {
+		if g.ID == parentID {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s does not exist", ErrInvalidGroupParent, parentID)
+	}
+
+	for _, descendant := range subtreeIDs(groups, id) {
+		if descendant == parentID {
+			return fmt.Errorf("%w: %s is %s or one of its descendants", ErrInvalidGroupParent, parentID, id)
+		}
+	}
+	return nil
+}
+
+func (s *groupService) DeleteGroup(ctx context.Context, id string) error {
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if g.ParentID != nil && *g.ParentID == id {
+			return fmt.Errorf("%w: %s has child groups", ErrGroupNotEmpty, id)
+		}
+	}
+
+	counts, err := s.repo.CountDevices(ctx)
+	if err != nil {
+		return err
+	}
+	if n := counts[id]; n > 0 {
+		return fmt.Errorf("%w: %d device(s) in %s", ErrGroupNotEmpty, n, id)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return s.mapError(err, id)
+	}
+	return nil
+}
+
+func (s *groupService) GetTree(ctx context.Context) ([]*GroupNode, error) {
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*GroupNode, len(groups))
+	for _, g := range groups {
+		nodes[g.ID] = &GroupNode{
+			ID:          g.ID,
+			Name:        g.Name,
+			ParentID:    g.ParentID,
+			Description: g.Description,
+			DeviceCount: counts[g.ID],
+			Children:    []*GroupNode{},
+		}
+	}
+
+	// groups is ordered by name, so siblings are too.
+	roots := []*GroupNode{}
+	for _, g := range groups {
+		node := nodes[g.ID]
+		if parent, ok := nodes[parentOf(g)]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	for _, root := range roots {
+		sumDeviceCounts(root)
+	}
+	return roots, nil
+}
+
+// sumDeviceCounts fills TotalDeviceCount of node and its descendants
+func sumDeviceCounts(node *GroupNode) int64 {
+	node.TotalDeviceCount = node.DeviceCount
+	for _, child := range node.Children {
+		node.TotalDeviceCount += sumDeviceCounts(child)
+	}
+	return node.TotalDeviceCount
+}
+
+func (s *groupService) ListGroupDevices(ctx context.Context, id string, recursive bool) ([]*model.Device, error) {
+	if _, err := s.GetGroup(ctx, id); err != nil {
+		return nil, err
+	}
+
+	groupIDs := []string{id}
+	if recursive {
+		groups, err := s.repo.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		groupIDs = subtreeIDs(groups, id)
+	}
+	return s.repo.ListDevices(ctx, groupIDs)
+}
+
+func (s *groupService) MoveDevices(ctx context.Context, id string, req *MoveDevicesRequest) (*BulkUpdateResponse, error) {
+	if len(req.DeviceIDs) > MaxBulkUpdateDevices {
+		return nil, fmt.Errorf("%w: at most %d ids allowed", ErrInvalidBulkUpdate, MaxBulkUpdateDevices)
+	}
+	if _, err := s.GetGroup(ctx, id); err != nil {
+		return nil, err
+	}
+
+	results, err := s.devices.BulkUpdate(ctx, req.DeviceIDs, map[string]interface{}{"group_id": id})
+	if err != nil {
+		return nil, err
+	}
+	return newBulkUpdateResponse(results), nil
+}
+
+func (s *groupService) DeviceGroupPath(ctx context.Context, deviceID string) ([]*model.DeviceGroup, error) {
+	device, err := s.devices.GetByID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device.GroupID == nil {
+		return nil, nil
+	}
+
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*model.DeviceGroup, len(groups))
+	for _, g := range groups {
+		byID[g.ID] = g
+	}
+
+	var path []*model.DeviceGroup
+	seen := make(map[string]bool)
+	for id := *device.GroupID; id != "" && !seen[id]; {
+		g, ok := byID[id]
+		if !ok {
+			break
+		}
+		seen[id] = true
+		path = append(path, g)
+		id = parentOf(g)
+	}
+	return path, nil
+}
+
+// subtreeIDs returns id followed by the IDs of all its descendants. A parent
+// cycle already in the database cannot loop it.
+func subtreeIDs(groups []*model.DeviceGroup, id string) []string {
+	children := make(map[string][]string)
+	for _, g := range groups {
+		if g.ParentID != nil {
+			children[*g.ParentID] = append(children[*g.ParentID], g.ID)
+		}
+	}
+
+	ids := []string{id}
+	seen := map[string]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+func parentOf(g *model.DeviceGroup) string {
+	if g.ParentID == nil {
+		return ""
+	}
+	return *g.ParentID
+}
+
+// parentError maps a failed parent lookup to ErrInvalidGroupParent
+func (s *groupService) parentError(err error, parentID string) error {
+	if errors.Is(err, repository.ErrGroupNotFound) {
+		return fmt.Errorf("%w: %s does not exist", ErrInvalidGroupParent, parentID)
+	}
+	return err
+}
+
+// mapError maps repository errors to the service's
+func (s *groupService) mapError(err error, subject string) error {
+	switch {
+	case errors.Is(err, repository.ErrGroupNotFound):
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, subject)
+	case errors.Is(err, repository.ErrDuplicateGroupName):
+		return fmt.Errorf("%w: %s", ErrDuplicateGroupName, subject)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,269 @@
+package service_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+// fakeGroupRepo keeps groups and the devices in them in memory.
+type fakeGroupRepo struct {
+	groups  map[string]*model.DeviceGroup
+	devices []*model.Device
+}
+
+func newFakeGroupRepo() *fakeGroupRepo {
+	return &fakeGroupRepo{groups: make(map[string]*model.DeviceGroup)}
+}
+
+func (f *fakeGroupRepo) Create(ctx context.Context, g *model.DeviceGroup) error {
+	for _, existing := range f.groups {
+		if existing.Name == g.Name {
+			return repository.ErrDuplicateGroupName
+		}
+	}
+	if g.ID == "" {
+		g.ID = g.Name
+	}
+	stored := *g
+	f.groups[g.ID] = &stored
+	return nil
+}
+
+func (f *fakeGroupRepo) GetByID(ctx context.Context, id string) (*model.DeviceGroup, error) {
+	g, ok := f.groups[id]
+	if !ok {
+		return nil, repository.ErrGroupNotFound
+	}
+	loaded := *g
+	return &loaded, nil
+}
+
+func (f *fakeGroupRepo) List(ctx context.Context) ([]*model.DeviceGroup, error) {
+	var out []*model.DeviceGroup
+	for _, g := range f.groups {
+		loaded := *g
+		out = append(out, &loaded)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (f *fakeGroupRepo) Update(ctx context.Context, g *model.DeviceGroup) error {
+	if _, ok := f.groups[g.ID]; !ok {
+		return repository.ErrGroupNotFound
+	}
+	stored := *g
+	f.groups[g.ID] = &stored
+	return nil
+}
+
+func (f *fakeGroupRepo) Reparent(ctx context.Context, g *model.DeviceGroup, check func(groups []*model.DeviceGroup) error) error {
+	groups, err := f.List(ctx)
+	if err != nil {
+		return err
+	}
+	if err := check(groups); err != nil {
+		return err
+	}
+	return f.Update(ctx, g)
+}
+
+func (f *fakeGroupRepo) Delete(ctx context.Context, id string) error {
+	if _, ok := f.groups[id]; !ok {
+		return repository.ErrGroupNotFound
+	}
+	delete(f.groups, id)
+	return nil
+}
+
+func (f *fakeGroupRepo) CountDevices(ctx context.Context) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, d := range f.devices {
+		if d.GroupID != nil {
+			counts[*d.GroupID]++
+		}
+	}
+	return counts, nil
+}
+
+func (f *fakeGroupRepo) ListDevices(ctx context.Context, groupIDs []string) ([]*model.Device, error) {
+	var out []*model.Device
+	for _, d := range f.devices {
+		for _, id := range groupIDs {
+			if d.GroupID != nil && *d.GroupID == id {
+				out = append(out, d)
+			}
+		}
+	}
+	return out, nil
+}
+
+// newGroupTree creates region > site > {rack-a, rack-b} and a separate lab
+// group, with a device in region, rack-a and lab.
+func newGroupTree(t *testing.T) (*fakeGroupRepo, service.GroupService) {
+	t.Helper()
+	repo := newFakeGroupRepo()
+	svc := service.NewGroupService(repo, &MockDeviceRepository{})
+	ctx := context.Background()
+
+	for _, g := range []service.GroupRequest{
+		{Name: "region"},
+		{Name: "site", ParentID: strPtr("region")},
+		{Name: "rack-a", ParentID: strPtr("site")},
+		{Name: "rack-b", ParentID: strPtr("site")},
+		{Name: "lab"},
+	} {
+		_, err := svc.CreateGroup(ctx, &g)
+		require.NoError(t, err)
+	}
+	repo.devices = []*model.Device{
+		{ID: "core-1", GroupID: strPtr("region")},
+		{ID: "sw-1", GroupID: strPtr("rack-a")},
+		{ID: "lab-1", GroupID: strPtr("lab")},
+	}
+	return repo, svc
+}
+
+func deviceIDs(devices []*model.Device) []string {
+	ids := make([]string, len(devices))
+	for i, d := range devices {
+		ids[i] = d.ID
+	}
+	return ids
+}
+
+func TestGroupService_CreateGroup(t *testing.T) {
+	_, svc := newGroupTree(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateGroup(ctx, &service.GroupRequest{Name: "orphan", ParentID: strPtr("missing")})
+	assert.ErrorIs(t, err, service.ErrInvalidGroupParent)
+
+	_, err = svc.CreateGroup(ctx, &service.GroupRequest{Name: "lab"})
+	assert.ErrorIs(t, err, service.ErrDuplicateGroupName)
+}
+
+func TestGroupService_ListGroupDevices(t *testing.T) {
+	_, svc := newGroupTree(t)
+	ctx := context.Background()
+
+	devices, err := svc.ListGroupDevices(ctx, "region", true)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"core-1", "sw-1"}, deviceIDs(devices))
+
+	devices, err = svc.ListGroupDevices(ctx, "region", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"core-1"}, deviceIDs(devices))
+
+	_, err = svc.ListGroupDevices(ctx, "missing", true)
+	assert.ErrorIs(t, err, service.ErrGroupNotFound)
+}
+
+func TestGroupService_GetTree(t *testing.T) {
+	_, svc := newGroupTree(t)
+
+	tree, err := svc.GetTree(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+
+	lab, region := tree[0], tree[1]
+	assert.Equal(t, "lab", lab.Name)
+	assert.Empty(t, lab.Children)
+	assert.Equal(t, int64(1), lab.TotalDeviceCount)
+
+	assert.Equal(t, "region", region.Name)
+	assert.Equal(t, int64(1), region.DeviceCount)
+	assert.Equal(t, int64(2), region.TotalDeviceCount)
+	require.Len(t, region.Children, 1)
+	site := region.Children[0]
+	require.Len(t, site.Children, 2)
+	assert.Equal(t, "rack-a", site.Children[0].Name)
+	assert.Equal(t, "rack-b", site.Children[1].Name)
+	assert.Equal(t, int64(1), site.TotalDeviceCount)
+}
+
+func TestGroupService_UpdateGroup_RejectsCycles(t *testing.T) {
+	repo, svc := newGroupTree(t)
+	ctx := context.Background()
+
+	_, err := svc.UpdateGroup(ctx, "region", &service.UpdateGroupRequest{ParentID: strPtr("rack-a")})
+	assert.ErrorIs(t, err, service.ErrInvalidGroupParent)
+	_, err = svc.UpdateGroup(ctx, "site", &service.UpdateGroupRequest{ParentID: strPtr("site")})
+	assert.ErrorIs(t, err, service.ErrInvalidGroupParent)
+	_, err = svc.UpdateGroup(ctx, "site", &service.UpdateGroupRequest{ParentID: strPtr("missing")})
+	assert.ErrorIs(t, err, service.ErrInvalidGroupParent)
+
+	updated, err := svc.UpdateGroup(ctx, "site", &service.UpdateGroupRequest{ParentID: strPtr("lab")})
+	require.NoError(t, err)
+	assert.Equal(t, "lab", *updated.ParentID)
+
+	_, err = svc.UpdateGroup(ctx, "site", &service.UpdateGroupRequest{ParentID: strPtr("")})
+	require.NoError(t, err)
+	assert.Nil(t, repo.groups["site"].ParentID, "an empty parent_id makes the group top-level")
+}
+
+func TestGroupService_DeleteGroup(t *testing.T) {
+	_, svc := newGroupTree(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, svc.DeleteGroup(ctx, "site"), service.ErrGroupNotEmpty, "has child groups")
+	assert.ErrorIs(t, svc.DeleteGroup(ctx, "lab"), service.ErrGroupNotEmpty, "has devices")
+	require.NoError(t, svc.DeleteGroup(ctx, "rack-b"))
+	assert.ErrorIs(t, svc.DeleteGroup(ctx, "rack-b"), service.ErrGroupNotFound)
+}
+
+func TestGroupService_MoveDevices(t *testing.T) {
+	var gotIDs []string
+	var gotFields map[string]interface{}
+	devices := &MockDeviceRepository{
+		BulkUpdateFunc: func(_ context.Context, ids []string, fields map[string]interface{}) ([]repository.BulkUpdateResult, error) {
+			gotIDs, gotFields = ids, fields
+			return []repository.BulkUpdateResult{{ID: "sw-1", Success: true}, {ID: "gone", Error: "device not found"}}, nil
+		},
+	}
+	repo := newFakeGroupRepo()
+	require.NoError(t, repo.Create(context.Background(), &model.DeviceGroup{ID: "lab", Name: "lab"}))
+	svc := service.NewGroupService(repo, devices)
+
+	resp, err := svc.MoveDevices(context.Background(), "lab", &service.MoveDevicesRequest{DeviceIDs: []string{"sw-1", "gone"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sw-1", "gone"}, gotIDs)
+	assert.Equal(t, map[string]interface{}{"group_id": "lab"}, gotFields)
+	assert.Equal(t, 1, resp.Updated)
+	assert.Equal(t, 1, resp.Failed)
+
+	_, err = svc.MoveDevices(context.Background(), "missing", &service.MoveDevicesRequest{DeviceIDs: []string{"sw-1"}})
+	assert.ErrorIs(t, err, service.ErrGroupNotFound)
+}
+
+func TestGroupService_DeviceGroupPath(t *testing.T) {
+	repo, _ := newGroupTree(t)
+	devices := &MockDeviceRepository{GetByIDFunc: func(_ context.Context, id string) (*model.Device, error) {
+		for _, d := range repo.devices {
+			if d.ID == id {
+				return d, nil
+			}
+		}
+		return &model.Device{ID: id}, nil
+	}}
+	svc := service.NewGroupService(repo, devices)
+
+	path, err := svc.DeviceGroupPath(context.Background(), "sw-1")
+	require.NoError(t, err)
+	names := make([]string, len(path))
+	for i, g := range path {
+		names[i] = g.Name
+	}
+	assert.Equal(t, []string{"rack-a", "site", "region"}, names)
+
+	path, err = svc.DeviceGroupPath(context.Background(), "ungrouped")
+	require.NoError(t, err)
+	assert.Empty(t, path)
+}
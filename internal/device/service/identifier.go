@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp"
+)
+
+const (
+	// oidEntPhysicalSerialNum is ENTITY-MIB entPhysicalSerialNum of the chassis
+	oidEntPhysicalSerialNum = ".1.3.6.1.2.1.47.1.1.1.1.11.1"
+	// oidIfPhysAddress is the IF-MIB ifPhysAddress column
+	oidIfPhysAddress = ".1.3.6.1.2.1.2.2.1.6"
+	// oidSysObjectID is SNMPv2-MIB sysObjectID, rooted at the vendor's enterprise OID
+	oidSysObjectID = ".1.3.6.1.2.1.1.2.0"
+
+	enterprisesOID = "1.3.6.1.4.1."
+)
+
+// enterpriseVendors maps IANA private enterprise numbers to vendor names
+var enterpriseVendors = map[string]string{
+	"9":     "cisco",
+	"2011":  "huawei",
+	"2636":  "juniper",
+	"3902":  "zte",
+	"5875":  "fiberhome",
+	"14988": "mikrotik",
+	"41112": "ubiquiti",
+}
+
+// SNMPIdentifier identifies hosts by chassis serial number, falling back to
+// the lowest interface MAC address, read over SNMP.
+type SNMPIdentifier struct {
+	community string
+	timeout   time.Duration
+	newClient func() snmp.SNMPClient
+}
+
+// NewSNMPIdentifier creates an identifier using SNMP v2c with the given community
+func NewSNMPIdentifier(community string) *SNMPIdentifier {
+	return &SNMPIdentifier{
+		community: community,
+		timeout:   2 * time.Second,
+		newClient: func() snmp.SNMPClient { return snmp.NewGoSNMPClient() },
+	}
+}
+
+// NewSNMPIdentifierForTest creates an identifier backed by the given client
+func NewSNMPIdentifierForTest(client snmp.SNMPClient) *SNMPIdentifier {
+	return &SNMPIdentifier{
+		community: "public",
+		timeout:   time.Second,
+		newClient: func() snmp.SNMPClient { return client },
+	}
+}
+
+// Identify returns "serial:<serial>" or "mac:<mac>", or "" when neither is available
+func (i *SNMPIdentifier) Identify(ctx context.Context, ip string) (string, error) {
+	client := i.newClient()
+	if err := client.Connect(ctx, ip, i.community, gosnmp.Version2c, i.timeout); err != nil {
+		return "", err
+	}
+	defer telemetry.Disconnect(client, string(model.ProtocolSNMP), ip)
+
+	if packet, err := client.Get([]string{oidEntPhysicalSerialNum}); err == nil {
+		for _, v := range packet.Variables {
+			if serial := strings.TrimSpace(snmpString(v)); serial != "" {
+				return "serial:" + serial, nil
+			}
+		}
+	}
+
+	var lowest string
+	err := client.Walk(oidIfPhysAddress, func(pdu gosnmp.SnmpPDU) error {
+		raw, ok := pdu.Value.([]byte)
+		if !ok || len(raw) != 6 || isZero(raw) {
+			return nil
+		}
+		mac := net.HardwareAddr(raw).String()
+		if lowest == "" || mac < lowest {
+			lowest = mac
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read interface addresses: %w", err)
+	}
+	if lowest == "" {
+		return "", nil
+	}
+	return "mac:" + lowest, nil
+}
+
+// DetectVendor returns the vendor owning the host's sysObjectID, or "" when
+// the enterprise number is not recognised
+func (i *SNMPIdentifier) DetectVendor(ctx context.Context, ip string) (string, error) {
+	client := i.newClient()
+	if err := client.Connect(ctx, ip, i.community, gosnmp.Version2c, i.timeout); err != nil {
+		return "", err
+	}
+	defer telemetry.Disconnect(client, string(model.ProtocolSNMP), ip)
+
+	packet, err := client.Get([]string{oidSysObjectID})
+	if err != nil {
+		return "", fmt.Errorf("failed to read sysObjectID: %w", err)
+	}
+	for _, v := range packet.Variables {
+		if vendor := VendorFromSysObjectID(snmpString(v)); vendor != "" {
+			return vendor, nil
+		}
+	}
+	return "", nil
+}
+
+// VendorFromSysObjectID maps a sysObjectID such as ".1.3.6.1.4.1.14988.1" to
+// its vendor ("mikrotik"), or "" when unknown
+func VendorFromSysObjectID(oid string) string {
+	oid = strings.TrimPrefix(oid, ".")
+	if !strings.HasPrefix(oid, enterprisesOID) {
+		return ""
+	}
+	enterprise, _, _ := strings.Cut(strings.TrimPrefix(oid, enterprisesOID), ".")
+	return enterpriseVendors[enterprise]
+}
+
+func snmpString(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp"
+)
+
+// Metadata keys set by the identity refresh
+const (
+	// MetadataIdentity holds the identity configured on the device itself
+	MetadataIdentity = "identity"
+	// MetadataIdentityCheckedAt is when the identity was last read (RFC 3339)
+	MetadataIdentityCheckedAt = "identity_checked_at"
+	// MetadataIdentityMismatch is true while the device name differs from its identity
+	MetadataIdentityMismatch = "identity_mismatch"
+)
+
+const (
+	defaultIdentityInterval = 6 * time.Hour
+	identityTimeout         = 10 * time.Second
+	// identityWorkers bounds how many devices are read at once, so one
+	// cycle over many unreachable devices does not take n * identityTimeout
+	identityWorkers = 8
+
+	// oidSysName is SNMPv2-MIB sysName
+	oidSysName = ".1.3.6.1.2.1.1.5.0"
+)
+
+// IdentityReader reads the identity (hostname) a device is configured with
+type IdentityReader interface {
+	ReadIdentity(ctx context.Context, device *model.Device) (string, error)
+}
+
+// IdentityResult is the outcome of refreshing one device
+type IdentityResult struct {
+	DeviceID string
+	Name     string
+	Identity string
+	Mismatch bool
+	Renamed  bool
+	Err      error
+}
+
+// IdentityRefresher periodically reads each enabled device's identity into
+// its metadata and flags devices whose name has drifted from it.
+type IdentityRefresher struct {
+	repo          repository.DeviceRepository
+	readers       map[model.Protocol]IdentityReader
+	overwriteName bool
+	interval      time.Duration
+	now           func() time.Time
+	stopChan      chan struct{}
+}
+
+// NewIdentityRefresher creates a refresher reading identities over the
+// Mikrotik API and SNMP.
+func NewIdentityRefresher(repo repository.DeviceRepository, cfg config.IdentityConfig) *IdentityRefresher {
+	return NewIdentityRefresherForTest(repo, cfg, map[model.Protocol]IdentityReader{
+		model.ProtocolMikrotikAPI: MikrotikIdentityReader{},
+		model.ProtocolSNMP:        NewSNMPIdentityReader(),
+	}, time.Now)
+}
+
+// NewIdentityRefresherForTest creates a refresher with custom readers and clock
+func NewIdentityRefresherForTest(repo repository.DeviceRepository, cfg config.IdentityConfig, readers map[model.Protocol]IdentityReader, now func() time.Time) *IdentityRefresher {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultIdentityInterval
+	}
+	return &IdentityRefresher{
+		repo:          repo,
+		readers:       readers,
+		overwriteName: cfg.OverwriteName,
+		interval:      interval,
+		now:           now,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+func (r *IdentityRefresher) Start() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	log.Printf("Identity refresh started (interval %v, overwrite name %v)", r.interval, r.overwriteName)
+	r.RefreshOnce(context.Background())
+
+	for {
+		select {
+		case <-ticker.C:
+			r.RefreshOnce(context.Background())
+		case <-r.stopChan:
+			log.Println("Identity refresh stopped")
+			return
+		}
+	}
+}
+
+func (r *IdentityRefresher) Stop() {
+	close(r.stopChan)
+}
+
+// RefreshOnce reads the identity of every enabled device whose protocol has a
+// reader and returns the per-device results. Devices that cannot be read keep
+// their previous identity metadata.
+func (r *IdentityRefresher) RefreshOnce(ctx context.Context) []IdentityResult {
+	enabled := true
+	devices, err := r.repo.List(ctx, &repository.DeviceFilter{Enabled: &enabled})
+	if err != nil {
+		log.Printf("Identity refresh: failed to list devices: %v", err)
+		return nil
+	}
+
+	var readable []*model.Device
+	for _, device := range devices {
+		if _, ok := r.readers[device.Protocol]; ok {
+			readable = append(readable, device)
+		}
+	}
+
+	results := make([]IdentityResult, len(readable))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, identityWorkers)
+	for i, device := range readable {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, device *model.Device) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := r.refresh(ctx, r.readers[device.Protocol], device)
+			if res.Err != nil {
+				log.Printf("Identity refresh: %s (%s): %v", device.Name, device.IPAddress, res.Err)
+			}
+			results[i] = res
+		}(i, device)
+	}
+	wg.Wait()
+	return results
+}
+
+func (r *IdentityRefresher) refresh(ctx context.Context, reader IdentityReader, device *model.Device) IdentityResult {
+	res := IdentityResult{DeviceID: device.ID, Name: device.Name}
+
+	identity, err := reader.ReadIdentity(ctx, device)
+	if err != nil {
+		res.Err = fmt.Errorf("failed to read identity: %w", err)
+		return res
+	}
+	res.Identity = strings.TrimSpace(identity)
+	if res.Identity == "" {
+		res.Err = fmt.Errorf("device reported an empty identity")
+		return res
+	}
+
+	var rename string
+	res.Mismatch = res.Identity != device.Name
+	if res.Mismatch && r.overwriteName {
+		rename = res.Identity
+		res.Name = res.Identity
+		res.Renamed = true
+		res.Mismatch = false
+	}
+
+	// Only the identity keys are written, so metadata set elsewhere since
+	// the device was listed is kept.
+	metadata := model.JSONMap{
+		MetadataIdentity:          res.Identity,
+		MetadataIdentityCheckedAt: r.now().UTC().Format(time.RFC3339),
+		MetadataIdentityMismatch:  res.Mismatch,
+	}
+
+	if err := r.repo.UpdateIdentity(ctx, device.ID, rename, metadata); err != nil {
+		res.Err = fmt.Errorf("failed to store identity: %w", err)
+		return res
+	}
+
+	switch {
+	case res.Renamed:
+		log.Printf("Identity refresh: renamed %s (%s) from %q to its identity", device.IPAddress, device.ID, device.Name)
+	case res.Mismatch && device.Metadata[MetadataIdentity] != res.Identity:
+		log.Printf("WARNING: device %q (%s) identifies itself as %q", device.Name, device.IPAddress, res.Identity)
+	}
+	return res
+}
+
+// MikrotikIdentityReader reads /system/identity over the RouterOS API
+type MikrotikIdentityReader struct{}
+
+func (MikrotikIdentityReader) ReadIdentity(ctx context.Context, device *model.Device) (string, error) {
+	client := mikrotik.NewMikrotikClient(identityTimeout)
+	if err := client.Connect(ctx, device); err != nil {
+		return "", err
+	}
+	defer telemetry.Disconnect(client, string(model.ProtocolMikrotikAPI), device.IPAddress)
+
+	sentences, err := client.RunCommand(ctx, "/system/identity/print")
+	if err != nil {
+		return "", err
+	}
+	if len(sentences) == 0 {
+		return "", fmt.Errorf("empty reply to /system/identity/print")
+	}
+	return sentences[0]["name"], nil
+}
+
+// SNMPIdentityReader reads sysName over SNMP v2c with the device's stored
+// community, or "public" when none is stored
+type SNMPIdentityReader struct {
+	newClient func() snmp.SNMPClient
+}
+
+// NewSNMPIdentityReader creates a reader using a new SNMP session per read
+func NewSNMPIdentityReader() *SNMPIdentityReader {
+	return &SNMPIdentityReader{newClient: func() snmp.SNMPClient { return snmp.NewGoSNMPClient() }}
+}
+
+// NewSNMPIdentityReaderForTest creates a reader backed by the given client
+func NewSNMPIdentityReaderForTest(client snmp.SNMPClient) *SNMPIdentityReader {
+	return &SNMPIdentityReader{newClient: func() snmp.SNMPClient { return client }}
+}
+
+func (s *SNMPIdentityReader) ReadIdentity(ctx context.Context, device *model.Device) (string, error) {
+	community := "public"
+	if device.Credentials != nil && device.Credentials.SNMPCommunity != "" {
+		stored, err := crypto.Decrypt(device.Credentials.SNMPCommunity)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt device credentials: %w", err)
+		}
+		community = stored
+	}
+
+	client := s.newClient()
+	if err := client.Connect(ctx, device.IPAddress, community, gosnmp.Version2c, identityTimeout); err != nil {
+		return "", err
+	}
+	defer telemetry.Disconnect(client, string(model.ProtocolSNMP), device.IPAddress)
+
+	packet, err := client.Get([]string{oidSysName})
+	if err != nil {
+		return "", fmt.Errorf("failed to read sysName: %w", err)
+	}
+	for _, v := range packet.Variables {
+		if name := snmpString(v); name != "" {
+			return name, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+// fakeIdentityReader answers with a fixed identity per IP
+type fakeIdentityReader map[string]string
+
+func (f fakeIdentityReader) ReadIdentity(_ context.Context, device *model.Device) (string, error) {
+	identity, ok := f[device.IPAddress]
+	if !ok {
+		return "", errors.New("connection refused")
+	}
+	return identity, nil
+}
+
+type identityUpdate struct {
+	name     string
+	metadata model.JSONMap
+}
+
+func identityRepo(devices []*model.Device, updates map[string]identityUpdate) *MockDeviceRepository {
+	var mu sync.Mutex
+	return &MockDeviceRepository{
+		ListFunc: func(_ context.Context, filter *repository.DeviceFilter) ([]*model.Device, error) {
+			if filter == nil || filter.Enabled == nil || !*filter.Enabled {
+				return nil, errors.New("expected an enabled filter")
+			}
+			return devices, nil
+		},
+		UpdateIdentityFunc: func(_ context.Context, id, name string, metadata model.JSONMap) error {
+			mu.Lock()
+			defer mu.Unlock()
+			updates[id] = identityUpdate{name: name, metadata: metadata}
+			return nil
+		},
+	}
+}
+
+var identityNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestIdentityRefresher_StoresIdentityAndFlagsMismatch(t *testing.T) {
+	devices := []*model.Device{
+		{ID: "dev-1", Name: "core-rtr-1", IPAddress: "10.0.0.1", Protocol: model.ProtocolMikrotikAPI},
+		{ID: "dev-2", Name: "Branch Router", IPAddress: "10.0.0.2", Protocol: model.ProtocolMikrotikAPI,
+			Metadata: model.JSONMap{service.MetadataVendor: "mikrotik"}},
+		{ID: "dev-3", Name: "olt-1", IPAddress: "10.0.0.3", Protocol: model.ProtocolSNMP},
+		{ID: "dev-4", Name: "cpe", IPAddress: "10.0.0.4", Protocol: model.ProtocolTR069},
+	}
+	updates := make(map[string]identityUpdate)
+	refresher := service.NewIdentityRefresherForTest(identityRepo(devices, updates), config.IdentityConfig{},
+		map[model.Protocol]service.IdentityReader{
+			model.ProtocolMikrotikAPI: fakeIdentityReader{"10.0.0.1": "core-rtr-1", "10.0.0.2": "branch-rtr-7"},
+			model.ProtocolSNMP:        fakeIdentityReader{},
+		}, func() time.Time { return identityNow })
+
+	results := refresher.RefreshOnce(context.Background())
+
+	require.Len(t, results, 3, "devices without a reader are skipped")
+	assert.False(t, results[0].Mismatch)
+	assert.True(t, results[1].Mismatch)
+	assert.Error(t, results[2].Err)
+
+	require.Len(t, updates, 2, "unreadable devices are not updated")
+	assert.Equal(t, identityUpdate{metadata: model.JSONMap{
+		service.MetadataIdentity:          "core-rtr-1",
+		service.MetadataIdentityCheckedAt: "2024-03-01T12:00:00Z",
+		service.MetadataIdentityMismatch:  false,
+	}}, updates["dev-1"])
+	assert.Equal(t, identityUpdate{metadata: model.JSONMap{
+		service.MetadataIdentity:          "branch-rtr-7",
+		service.MetadataIdentityCheckedAt: "2024-03-01T12:00:00Z",
+		service.MetadataIdentityMismatch:  true,
+	}}, updates["dev-2"], "the name is kept and only the identity keys are written")
+}
+
+func TestIdentityRefresher_OverwriteName(t *testing.T) {
+	devices := []*model.Device{
+		{ID: "dev-1", Name: "Branch Router", IPAddress: "10.0.0.1", Protocol: model.ProtocolMikrotikAPI},
+	}
+	updates := make(map[string]identityUpdate)
+	refresher := service.NewIdentityRefresherForTest(identityRepo(devices, updates), config.IdentityConfig{OverwriteName: true},
+		map[model.Protocol]service.IdentityReader{
+			model.ProtocolMikrotikAPI: fakeIdentityReader{"10.0.0.1": " branch-rtr-7\n"},
+		}, func() time.Time { return identityNow })
+
+	results := refresher.RefreshOnce(context.Background())
+
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Renamed)
+	assert.Equal(t, "branch-rtr-7", updates["dev-1"].name)
+	assert.Equal(t, false, updates["dev-1"].metadata[service.MetadataIdentityMismatch])
+}
+
+func TestSNMPIdentityReader_ReadsSysName(t *testing.T) {
+	reader := service.NewSNMPIdentityReaderForTest(&mockSNMPClient{serial: "olt-hq-1"})
+
+	identity, err := reader.ReadIdentity(context.Background(), &model.Device{IPAddress: "10.0.0.3"})
+	require.NoError(t, err)
+	assert.Equal(t, "olt-hq-1", identity)
+}
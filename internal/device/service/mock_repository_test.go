@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+// MockDeviceRepository
+type MockDeviceRepository struct {
+	CreateFunc              func(ctx context.Context, device *model.Device) error
+	GetByIDFunc             func(ctx context.Context, id string) (*model.Device, error)
+	GetByIPAddressFunc      func(ctx context.Context, ipAddress string) (*model.Device, error)
+	ListFunc                func(ctx context.Context, filter *repository.DeviceFilter) ([]*model.Device, error)
+	UpdateFunc              func(ctx context.Context, device *model.Device) error
+	UpdateStatusDetailsFunc func(ctx context.Context, id string, status model.DeviceStatus, lastSeen *time.Time, lastError string) error
+	UpdateIdentityFunc      func(ctx context.Context, id string, name string, metadata model.JSONMap) error
+	DeleteFunc              func(ctx context.Context, id string) error
+	CountFunc               func(ctx context.Context, filter *repository.DeviceFilter) (int64, error)
+	BulkUpdateFunc          func(ctx context.Context, ids []string, fields map[string]interface{}) ([]repository.BulkUpdateResult, error)
+}
+
+func (m *MockDeviceRepository) Create(ctx context.Context, device *model.Device) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, device)
+	}
+	return nil
+}
+
+func (m *MockDeviceRepository) GetByID(ctx context.Context, id string) (*model.Device, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockDeviceRepository) GetByIPAddress(ctx context.Context, ipAddress string) (*model.Device, error) {
+	if m.GetByIPAddressFunc != nil {
+		return m.GetByIPAddressFunc(ctx, ipAddress)
+	}
+	return nil, nil
+}
+
+func (m *MockDeviceRepository) List(ctx context.Context, filter *repository.DeviceFilter) ([]*model.Device, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, filter)
+	}
+	return nil, nil
+}
+
+func (m *MockDeviceRepository) Update(ctx context.Context, device *model.Device) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, device)
+	}
+	return nil
+}
+
+func (m *MockDeviceRepository) UpdateStatus(ctx context.Context, id string, status model.DeviceStatus) error {
+	return nil
+}
+
+func (m *MockDeviceRepository) UpdateStatusDetails(ctx context.Context, id string, status model.DeviceStatus, lastSeen *time.Time, lastError string) error {
+	if m.UpdateStatusDetailsFunc != nil {
+		return m.UpdateStatusDetailsFunc(ctx, id, status, lastSeen, lastError)
+	}
+	return nil
+}
+
+func (m *MockDeviceRepository) UpdateIdentity(ctx context.Context, id string, name string, metadata model.JSONMap) error {
+	if m.UpdateIdentityFunc != nil {
+		return m.UpdateIdentityFunc(ctx, id, name, metadata)
+	}
+	return nil
+}
+
+func (m *MockDeviceRepository) Delete(ctx context.Context, id string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockDeviceRepository) Count(ctx context.Context, filter *repository.DeviceFilter) (int64, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc(ctx, filter)
+	}
+	return 0, nil
+}
+
+func (m *MockDeviceRepository) GetByGroup(ctx context.Context, groupID string) ([]*model.Device, error) {
+	return nil, nil
+}
+
+func (m *MockDeviceRepository) ListDueForPolling(ctx context.Context, now time.Time, limit int) ([]*model.Device, error) {
+	return nil, nil
+}
+
+func (m *MockDeviceRepository) UpdateNextPollAt(ctx context.Context, id string, next time.Time) error {
+	return nil
+}
+
+func (m *MockDeviceRepository) BulkUpdate(ctx context.Context, ids []string, fields map[string]interface{}) ([]repository.BulkUpdateResult, error) {
+	if m.BulkUpdateFunc != nil {
+		return m.BulkUpdateFunc(ctx, ids, fields)
+	}
+	return nil, nil
+}
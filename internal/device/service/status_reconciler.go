@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/queue"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+// EventStatusChanged is the event type published when a device's status flips.
+const EventStatusChanged = "device.status_changed"
+
+// StatusChangeEvent describes a device status transition.
+type StatusChangeEvent struct {
+	DeviceID  string             `json:"device_id"`
+	Name      string             `json:"name"`
+	IPAddress string             `json:"ip_address"`
+	OldStatus model.DeviceStatus `json:"old_status"`
+	NewStatus model.DeviceStatus `json:"new_status"`
+	LastError string             `json:"last_error,omitempty"`
+	ChangedAt time.Time          `json:"changed_at"`
+}
+
+// statusEventQueue is the number of status-change events that may wait for
+// the publisher. Events of a reconciler are sent one at a time, in order.
+const statusEventQueue = 256
+
+// EventPublisher delivers events to external systems (e.g. a webhook).
+type EventPublisher interface {
+	Post(ctx context.Context, event string, data interface{}) error
+}
+
+// StatusReconciler keeps device status, last seen and last error in sync with
+// poll results published on nms.metrics, and publishes status transitions.
+type StatusReconciler struct {
+	repo      repository.DeviceRepository
+	publisher EventPublisher
+	now       func() time.Time
+	stopChan  chan struct{}
+
+	// events feeds the publisher, so a slow webhook does not hold up the
+	// poll results behind it.
+	events  chan StatusChangeEvent
+	pending sync.WaitGroup
+}
+
+// NewStatusReconciler creates a StatusReconciler. publisher may be nil to
+// disable status-change events.
+func NewStatusReconciler(repo repository.DeviceRepository, publisher EventPublisher) *StatusReconciler {
+	return &StatusReconciler{
+		repo:      repo,
+		publisher: publisher,
+		now:       time.Now,
+		stopChan:  make(chan struct{}),
+		events:    make(chan StatusChangeEvent, statusEventQueue),
+	}
+}
+
+// Start subscribes to poll results and blocks until Stop is called.
+// Reconcilers sharing group each get a share of the results, and over
+// JetStream receive those published while they were all down; without a
+// group every reconciler gets every result published while it runs.
+// Status-change events are published in the background; the ones queued are
+// sent before Start returns.
+func (r *StatusReconciler) Start(nc queue.Conn, group string) {
+	log.Printf("Status Reconciler started, subscribing to nms.metrics (queue group %q)", group)
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		r.publishLoop()
+	}()
+	defer func() { <-sent }()
+
+	handler := func(msg *nats.Msg) {
+		var metric commonModel.Metric
+		if err := json.Unmarshal(msg.Data, &metric); err != nil {
+			log.Printf("Error unmarshalling metric: %v", err)
+			queue.Term(msg)
+			return
+		}
+
+		// Acknowledged once the device is updated, without waiting for
+		// the status-change event, and even when it fails: a late
+		// redelivery could overwrite the status from a newer poll.
+		if err := r.Reconcile(context.Background(), metric); err != nil {
+			log.Printf("Error reconciling status for %s: %v", metric.DeviceID, err)
+		}
+		queue.Ack(msg)
+	}
+
+	var sub *nats.Subscription
+	var err error
+	if group != "" {
+		sub, err = nc.QueueSubscribe("nms.metrics", group, handler)
+	} else {
+		sub, err = nc.Subscribe("nms.metrics", handler)
+	}
+	if err != nil {
+		log.Fatalf("Error communicating with NATS: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	<-r.stopChan
+}
+
+func (r *StatusReconciler) Stop() {
+	close(r.stopChan)
+}
+
+// Flush blocks until every status-change event queued so far has been
+// published.
+func (r *StatusReconciler) Flush() {
+	r.pending.Wait()
+}
+
+// publishLoop publishes queued events until Stop, then the ones still queued.
+func (r *StatusReconciler) publishLoop() {
+	for {
+		select {
+		case event := <-r.events:
+			r.publish(event)
+		case <-r.stopChan:
+			for {
+				select {
+				case event := <-r.events:
+					r.publish(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *StatusReconciler) publish(event StatusChangeEvent) {
+	defer r.pending.Done()
+
+	if err := r.publisher.Post(context.Background(), EventStatusChanged, event); err != nil {
+		log.Printf("Error publishing status change of %s: %v", event.DeviceID, err)
+	}
+}
+
+// enqueue hands event to the publisher without waiting. It drops event when
+// the queue is full or the reconciler is stopping, so the device status keeps
+// following the polls while the webhook is down.
+func (r *StatusReconciler) enqueue(event StatusChangeEvent) {
+	r.pending.Add(1)
+	select {
+	case <-r.stopChan:
+		r.pending.Done()
+		log.Printf("Dropping status change of %s: status reconciler is stopping", event.DeviceID)
+		return
+	default:
+	}
+	select {
+	case r.events <- event:
+	default:
+		r.pending.Done()
+		log.Printf("Dropping status change of %s: %d events are waiting to be published", event.DeviceID, statusEventQueue)
+	}
+}
+
+// Reconcile applies a poll result to the device and queues an event if its
+// status changed. Metrics without a boolean "success" value are ignored.
+func (r *StatusReconciler) Reconcile(ctx context.Context, metric commonModel.Metric) error {
+	success, ok := metric.Values["success"].(bool)
+	if !ok || metric.DeviceID == "" {
+		return nil
+	}
+
+	device, err := r.repo.GetByID(ctx, metric.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	now := r.now()
+	newStatus := model.DeviceStatusOffline
+	lastError := "device unreachable"
+	var lastSeen *time.Time
+	if success {
+		newStatus = model.DeviceStatusOnline
+		lastError = ""
+		lastSeen = &now
+	} else if msg, ok := metric.Values[commonModel.ValueError].(string); ok && msg != "" {
+		lastError = msg
+	}
+
+	if err := r.repo.UpdateStatusDetails(ctx, device.ID, newStatus, lastSeen, lastError); err != nil {
+		return fmt.Errorf("failed to update device status: %w", err)
+	}
+
+	if device.Status == newStatus || r.publisher == nil {
+		return nil
+	}
+
+	r.enqueue(StatusChangeEvent{
+		DeviceID:  device.ID,
+		Name:      device.Name,
+		IPAddress: device.IPAddress,
+		OldStatus: device.Status,
+		NewStatus: newStatus,
+		LastError: lastError,
+		ChangedAt: now,
+	})
+
+	return nil
+}
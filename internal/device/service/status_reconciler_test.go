@@ -0,0 +1,213 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+type publishedEvent struct {
+	event string
+	data  interface{}
+}
+
+type fakePublisher struct {
+	// release, when set, blocks every Post until it is closed
+	release chan struct{}
+	events  []publishedEvent
+}
+
+func (f *fakePublisher) Post(ctx context.Context, event string, data interface{}) error {
+	if f.release != nil {
+		<-f.release
+	}
+	f.events = append(f.events, publishedEvent{event: event, data: data})
+	return nil
+}
+
+// startReconciler runs r until the end of the test, so it publishes the
+// events Reconcile queues.
+func startReconciler(t *testing.T, r *service.StatusReconciler) {
+	t.Helper()
+
+	nc := &subscribingConn{groups: make(chan string, 1)}
+	done := make(chan struct{})
+	go func() {
+		r.Start(nc, "")
+		close(done)
+	}()
+	<-nc.groups
+	t.Cleanup(func() {
+		r.Stop()
+		<-done
+	})
+}
+
+type statusUpdate struct {
+	status    model.DeviceStatus
+	lastSeen  *time.Time
+	lastError string
+}
+
+func newReconcilerRepo(current model.DeviceStatus, updates *[]statusUpdate) *MockDeviceRepository {
+	return &MockDeviceRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*model.Device, error) {
+			return &model.Device{ID: id, Name: "core-router", IPAddress: "10.0.0.1", Status: current}, nil
+		},
+		UpdateStatusDetailsFunc: func(ctx context.Context, id string, status model.DeviceStatus, lastSeen *time.Time, lastError string) error {
+			*updates = append(*updates, statusUpdate{status: status, lastSeen: lastSeen, lastError: lastError})
+			return nil
+		},
+	}
+}
+
+func TestStatusReconciler_PublishesOnFlip(t *testing.T) {
+	var updates []statusUpdate
+	pub := &fakePublisher{}
+	r := service.NewStatusReconciler(newReconcilerRepo(model.DeviceStatusOnline, &updates), pub)
+	startReconciler(t, r)
+
+	err := r.Reconcile(context.Background(), commonModel.Metric{
+		DeviceID: "dev-1",
+		Values:   map[string]interface{}{"success": false},
+	})
+	require.NoError(t, err)
+	r.Flush()
+
+	require.Len(t, updates, 1)
+	assert.Equal(t, model.DeviceStatusOffline, updates[0].status)
+	assert.Nil(t, updates[0].lastSeen, "last seen must not advance on a failed poll")
+	assert.NotEmpty(t, updates[0].lastError)
+
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, service.EventStatusChanged, pub.events[0].event)
+	ev := pub.events[0].data.(service.StatusChangeEvent)
+	assert.Equal(t, "dev-1", ev.DeviceID)
+	assert.Equal(t, model.DeviceStatusOnline, ev.OldStatus)
+	assert.Equal(t, model.DeviceStatusOffline, ev.NewStatus)
+}
+
+func TestStatusReconciler_NoEventWithoutChange(t *testing.T) {
+	var updates []statusUpdate
+	pub := &fakePublisher{}
+	r := service.NewStatusReconciler(newReconcilerRepo(model.DeviceStatusOnline, &updates), pub)
+	startReconciler(t, r)
+
+	err := r.Reconcile(context.Background(), commonModel.Metric{
+		DeviceID: "dev-1",
+		Values:   map[string]interface{}{"success": true},
+	})
+	require.NoError(t, err)
+	r.Flush()
+
+	require.Len(t, updates, 1)
+	assert.Equal(t, model.DeviceStatusOnline, updates[0].status)
+	assert.NotNil(t, updates[0].lastSeen)
+	assert.Empty(t, updates[0].lastError)
+	assert.Empty(t, pub.events)
+}
+
+func TestStatusReconciler_SlowPublisherDoesNotBlockReconcile(t *testing.T) {
+	var updates []statusUpdate
+	pub := &fakePublisher{release: make(chan struct{})}
+	r := service.NewStatusReconciler(newReconcilerRepo(model.DeviceStatusOnline, &updates), pub)
+	startReconciler(t, r)
+
+	// The device stays online in the repository, so both polls flip it.
+	reconciled := make(chan struct{})
+	go func() {
+		defer close(reconciled)
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, r.Reconcile(context.Background(), commonModel.Metric{
+				DeviceID: "dev-1",
+				Values:   map[string]interface{}{"success": false},
+			}))
+		}
+	}()
+
+	select {
+	case <-reconciled:
+	case <-time.After(time.Second):
+		t.Fatal("Reconcile waited for the publisher")
+	}
+	assert.Len(t, updates, 2, "the device is updated before the event is published")
+
+	close(pub.release)
+	r.Flush()
+	assert.Len(t, pub.events, 2)
+}
+
+func TestStatusReconciler_IgnoresMetricsWithoutSuccess(t *testing.T) {
+	var updates []statusUpdate
+	r := service.NewStatusReconciler(newReconcilerRepo(model.DeviceStatusUnknown, &updates), nil)
+
+	err := r.Reconcile(context.Background(), commonModel.Metric{
+		DeviceID: "dev-1",
+		Values:   map[string]interface{}{"cpu": 12.0},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestStatusReconciler_RecordsPollError(t *testing.T) {
+	var updates []statusUpdate
+	r := service.NewStatusReconciler(newReconcilerRepo(model.DeviceStatusUnknown, &updates), nil)
+
+	err := r.Reconcile(context.Background(), commonModel.Metric{
+		DeviceID: "dev-1",
+		Values: map[string]interface{}{
+			"success":              false,
+			commonModel.ValueError: "unsupported protocol: telnet",
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, updates, 1)
+	assert.Equal(t, model.DeviceStatusOffline, updates[0].status)
+	assert.Equal(t, "unsupported protocol: telnet", updates[0].lastError)
+}
+
+// subscribingConn records how the reconciler subscribes.
+type subscribingConn struct {
+	groups chan string
+}
+
+func (c *subscribingConn) Publish(subj string, data []byte) error { return nil }
+
+func (c *subscribingConn) Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return c.QueueSubscribe(subj, "", cb)
+}
+
+func (c *subscribingConn) QueueSubscribe(subj, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	c.groups <- queue
+	return &nats.Subscription{}, nil
+}
+
+func TestStatusReconciler_StartJoinsQueueGroup(t *testing.T) {
+	for _, group := range []string{"nms-status-reconcilers", ""} {
+		nc := &subscribingConn{groups: make(chan string, 1)}
+		r := service.NewStatusReconciler(&MockDeviceRepository{}, nil)
+
+		done := make(chan struct{})
+		go func() {
+			r.Start(nc, group)
+			close(done)
+		}()
+
+		select {
+		case got := <-nc.groups:
+			assert.Equal(t, group, got)
+		case <-time.After(time.Second):
+			t.Fatal("reconciler did not subscribe")
+		}
+		r.Stop()
+		<-done
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+// MetadataVendor holds the vendor detected during discovery (e.g. "mikrotik")
+const MetadataVendor = "vendor"
+
+// ApplyTagRules adds the tag of every rule matching the device's attributes,
+// skipping tags the device already has. It returns the tags that were added.
+func ApplyTagRules(device *model.Device, rules []*model.TagRule) []string {
+	attrs := deviceAttributes(device)
+
+	have := make(map[string]bool, len(device.Tags))
+	for _, t := range device.Tags {
+		have[t] = true
+	}
+
+	var added []string
+	for _, rule := range rules {
+		value, ok := attrs[strings.ToLower(rule.Attribute)]
+		if !ok || !strings.EqualFold(value, rule.Value) || have[rule.Tag] {
+			continue
+		}
+		device.Tags = append(device.Tags, rule.Tag)
+		have[rule.Tag] = true
+		added = append(added, rule.Tag)
+	}
+	return added
+}
+
+// deviceAttributes collects the values tag rules can match: the device type,
+// protocol and any non-empty string metadata.
+func deviceAttributes(device *model.Device) map[string]string {
+	attrs := make(map[string]string, len(device.Metadata)+2)
+	for key, v := range device.Metadata {
+		if s, ok := v.(string); ok && s != "" {
+			attrs[strings.ToLower(key)] = s
+		}
+	}
+	if device.DeviceType != "" {
+		attrs[model.TagAttributeDeviceType] = string(device.DeviceType)
+	}
+	if device.Protocol != "" {
+		attrs[model.TagAttributeProtocol] = string(device.Protocol)
+	}
+	return attrs
+}
+
+// loadTagRules returns the configured tag rules. Tagging is best effort: a
+// failure to load them is logged and no rules are applied.
+func loadTagRules(ctx context.Context, rules repository.TagRuleRepository) []*model.TagRule {
+	if rules == nil {
+		return nil
+	}
+	list, err := rules.List(ctx)
+	if err != nil {
+		log.Printf("Tagging: failed to load tag rules: %v", err)
+		return nil
+	}
+	return list
+}
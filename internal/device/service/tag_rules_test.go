@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/service"
+)
+
+// fakeTagRules serves a fixed rule set
+type fakeTagRules struct {
+	rules []*model.TagRule
+	err   error
+}
+
+func (f *fakeTagRules) List(ctx context.Context) ([]*model.TagRule, error) {
+	return f.rules, f.err
+}
+
+func (f *fakeTagRules) Create(ctx context.Context, rule *model.TagRule) error {
+	f.rules = append(f.rules, rule)
+	return nil
+}
+
+var defaultRules = []*model.TagRule{
+	{Attribute: "vendor", Value: "mikrotik", Tag: "vendor:mikrotik"},
+	{Attribute: "vendor", Value: "zte", Tag: "vendor:zte"},
+	{Attribute: model.TagAttributeDeviceType, Value: "olt", Tag: "role:olt"},
+	{Attribute: model.TagAttributeProtocol, Value: "snmp", Tag: "polled:snmp"},
+}
+
+func TestApplyTagRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		device *model.Device
+		want   []string
+	}{
+		{
+			name:   "vendor from metadata",
+			device: &model.Device{Metadata: model.JSONMap{service.MetadataVendor: "mikrotik"}},
+			want:   []string{"vendor:mikrotik"},
+		},
+		{
+			name:   "device type and protocol",
+			device: &model.Device{DeviceType: model.DeviceTypeOLT, Protocol: model.ProtocolSNMP},
+			want:   []string{"role:olt", "polled:snmp"},
+		},
+		{
+			name:   "values match case-insensitively",
+			device: &model.Device{Metadata: model.JSONMap{"Vendor": "ZTE"}, DeviceType: model.DeviceTypeOLT},
+			want:   []string{"vendor:zte", "role:olt"},
+		},
+		{
+			name:   "existing tags are kept and not duplicated",
+			device: &model.Device{Tags: model.StringArray{"role:olt", "site:jkt"}, DeviceType: model.DeviceTypeOLT},
+			want:   []string{"role:olt", "site:jkt"},
+		},
+		{
+			name:   "no matching rule",
+			device: &model.Device{DeviceType: model.DeviceTypeRouter, Metadata: model.JSONMap{service.MetadataVendor: "cisco"}},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service.ApplyTagRules(tt.device, defaultRules)
+			assert.Equal(t, tt.want, []string(tt.device.Tags))
+		})
+	}
+}
+
+func TestRegisterDevice_AppliesTagRules(t *testing.T) {
+	var created *model.Device
+	repo := &MockDeviceRepository{
+		CreateFunc: func(ctx context.Context, device *model.Device) error {
+			created = device
+			return nil
+		},
+	}
+	svc := service.NewDeviceService(repo, &fakeTagRules{rules: defaultRules})
+
+	_, err := svc.RegisterDevice(context.Background(), &service.RegisterDeviceRequest{
+		Name:       "OLT-1",
+		IPAddress:  "10.0.0.1",
+		DeviceType: model.DeviceTypeOLT,
+		Protocol:   model.ProtocolSNMP,
+		Tags:       []string{"site:jkt"},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	assert.Equal(t, model.StringArray{"site:jkt", "role:olt", "polled:snmp"}, created.Tags)
+}
+
+func TestRegisterDevice_TagRulesUnavailable(t *testing.T) {
+	var created *model.Device
+	repo := &MockDeviceRepository{
+		CreateFunc: func(ctx context.Context, device *model.Device) error {
+			created = device
+			return nil
+		},
+	}
+	svc := service.NewDeviceService(repo, &fakeTagRules{err: errors.New("relation \"tag_rules\" does not exist")})
+
+	_, err := svc.RegisterDevice(context.Background(), &service.RegisterDeviceRequest{
+		Name:       "OLT-1",
+		IPAddress:  "10.0.0.1",
+		DeviceType: model.DeviceTypeOLT,
+	})
+	require.NoError(t, err, "registration must not depend on tag rules")
+	assert.Empty(t, created.Tags)
+}
+
+// vendorIdentifier identifies hosts and reports a vendor per IP
+type vendorIdentifier struct {
+	fakeIdentifier
+	vendors map[string]string
+}
+
+func (v vendorIdentifier) DetectVendor(ctx context.Context, ip string) (string, error) {
+	return v.vendors[ip], nil
+}
+
+func TestDiscoverAndSave_TagsFromDetectedVendor(t *testing.T) {
+	var created []*model.Device
+	identifier := vendorIdentifier{
+		fakeIdentifier: fakeIdentifier{},
+		vendors:        map[string]string{"10.0.0.2": "mikrotik"},
+	}
+	rules := &fakeTagRules{rules: []*model.TagRule{
+		{Attribute: "vendor", Value: "mikrotik", Tag: "vendor:mikrotik"},
+	}}
+	svc := service.NewDiscoveryServiceForTest(recordingRepo(nil, &created), identifier, rules, pingOnly("10.0.0.2", "10.0.0.3"))
+
+	_, err := svc.DiscoverAndSave(context.Background(), &service.DiscoveryRequest{CIDR: "10.0.0.0/29"})
+	require.NoError(t, err)
+
+	tags := map[string][]string{}
+	for _, d := range created {
+		tags[d.IPAddress] = d.Tags
+	}
+	assert.Equal(t, []string{"vendor:mikrotik"}, tags["10.0.0.2"])
+	assert.Empty(t, tags["10.0.0.3"])
+}
+
+func TestVendorFromSysObjectID(t *testing.T) {
+	assert.Equal(t, "mikrotik", service.VendorFromSysObjectID(".1.3.6.1.4.1.14988.1"))
+	assert.Equal(t, "zte", service.VendorFromSysObjectID("1.3.6.1.4.1.3902.1082.1001.320.1"))
+	assert.Equal(t, "", service.VendorFromSysObjectID("1.3.6.1.4.1.99999.1"))
+	assert.Equal(t, "", service.VendorFromSysObjectID("1.3.6.1.2.1.1"))
+}
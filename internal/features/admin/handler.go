@@ -0,0 +1,77 @@
+// Package admin exposes operator endpoints, such as forcing caches to refresh
+// after an OLT is reconfigured.
+package admin
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+// CacheInvalidator is a cache that can be cleared on demand.
+type CacheInvalidator interface {
+	// InvalidateCache drops entries for ip, or every entry when ip is empty,
+	// and returns the number of entries removed.
+	InvalidateCache(ip string) int
+}
+
+// InvalidateCacheRequest is the optional body of POST /api/v1/admin/cache/invalidate.
+type InvalidateCacheRequest struct {
+	// IP limits invalidation to a single target. Empty clears every entry.
+	IP string `json:"ip"`
+}
+
+// InvalidateCacheResponse reports how many entries each cache dropped.
+type InvalidateCacheResponse struct {
+	IP          string         `json:"ip,omitempty"`
+	Invalidated map[string]int `json:"invalidated"`
+}
+
+// Handler serves the admin endpoints.
+type Handler struct {
+	caches map[string]CacheInvalidator
+}
+
+// NewHandler creates an admin handler over the named caches.
+func NewHandler(caches map[string]CacheInvalidator) *Handler {
+	return &Handler{caches: caches}
+}
+
+// RegisterRoutes mounts the admin endpoints under /admin. Callers are
+// responsible for attaching authentication to group.
+func RegisterRoutes(group *gin.RouterGroup, caches map[string]CacheInvalidator) {
+	h := NewHandler(caches)
+
+	adminGroup := group.Group("/admin")
+	{
+		// POST /api/v1/admin/cache/invalidate — clear caches, optionally for one target IP
+		adminGroup.POST("/cache/invalidate", h.InvalidateCache)
+	}
+}
+
+// InvalidateCache handles POST /api/v1/admin/cache/invalidate
+//
+// The body is optional; without one every registered cache is cleared.
+func (h *Handler) InvalidateCache(c *gin.Context) {
+	var req InvalidateCacheRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	if req.IP != "" && net.ParseIP(req.IP) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ip: " + req.IP})
+		return
+	}
+
+	resp := InvalidateCacheResponse{IP: req.IP, Invalidated: make(map[string]int, len(h.caches))}
+	for name, cache := range h.caches {
+		resp.Invalidated[name] = cache.InvalidateCache(req.IP)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
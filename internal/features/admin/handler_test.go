@@ -0,0 +1,118 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/api-gateway/middleware"
+	"github.com/yourorg/nms-go/internal/features/admin"
+)
+
+// fakeCache holds a set of IPs and records invalidation calls.
+type fakeCache struct {
+	entries map[string]bool
+	calls   []string
+}
+
+func newFakeCache(ips ...string) *fakeCache {
+	f := &fakeCache{entries: make(map[string]bool)}
+	for _, ip := range ips {
+		f.entries[ip] = true
+	}
+	return f
+}
+
+func (f *fakeCache) InvalidateCache(ip string) int {
+	f.calls = append(f.calls, ip)
+	if ip == "" {
+		n := len(f.entries)
+		f.entries = make(map[string]bool)
+		return n
+	}
+	if !f.entries[ip] {
+		return 0
+	}
+	delete(f.entries, ip)
+	return 1
+}
+
+const secret = "test-secret"
+
+func setupRouter(caches map[string]admin.CacheInvalidator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	group := r.Group("/api/v1")
+	group.Use(middleware.AuthMiddleware(secret))
+	admin.RegisterRoutes(group, caches)
+	return r
+}
+
+func invalidate(t *testing.T, r *gin.Engine, body string, authorized bool) *httptest.ResponseRecorder {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/cache/invalidate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if authorized {
+		token, err := jwt.New(jwt.SigningMethodHS256).SignedString([]byte(secret))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestInvalidateCache_ScopedByIP(t *testing.T) {
+	cache := newFakeCache("10.0.0.1", "10.0.0.2")
+	r := setupRouter(map[string]admin.CacheInvalidator{"olt_vendor": cache})
+
+	w := invalidate(t, r, `{"ip":"10.0.0.1"}`, true)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp admin.InvalidateCacheResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "10.0.0.1", resp.IP)
+	assert.Equal(t, map[string]int{"olt_vendor": 1}, resp.Invalidated)
+
+	assert.False(t, cache.entries["10.0.0.1"], "entry should be gone after invalidation")
+	assert.True(t, cache.entries["10.0.0.2"], "other targets are untouched")
+}
+
+func TestInvalidateCache_NoBodyClearsAll(t *testing.T) {
+	cache := newFakeCache("10.0.0.1", "10.0.0.2")
+	r := setupRouter(map[string]admin.CacheInvalidator{"olt_vendor": cache})
+
+	w := invalidate(t, r, "", true)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp admin.InvalidateCacheResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]int{"olt_vendor": 2}, resp.Invalidated)
+	assert.Empty(t, cache.entries)
+	assert.Equal(t, []string{""}, cache.calls)
+}
+
+func TestInvalidateCache_InvalidIP(t *testing.T) {
+	cache := newFakeCache("10.0.0.1")
+	r := setupRouter(map[string]admin.CacheInvalidator{"olt_vendor": cache})
+
+	w := invalidate(t, r, `{"ip":"not-an-ip"}`, true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, cache.calls)
+}
+
+func TestInvalidateCache_RequiresAuth(t *testing.T) {
+	cache := newFakeCache("10.0.0.1")
+	r := setupRouter(map[string]admin.CacheInvalidator{"olt_vendor": cache})
+
+	w := invalidate(t, r, `{"ip":"10.0.0.1"}`, false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, cache.calls)
+	assert.True(t, cache.entries["10.0.0.1"])
+}
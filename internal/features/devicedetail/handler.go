@@ -0,0 +1,122 @@
+// Package devicedetail serves everything the UI device page shows in one
+// response: the device, its latest stored metrics and its active alerts.
+package devicedetail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/alert"
+	alertservice "github.com/yourorg/nms-go/internal/alert/service"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/features/metrics"
+)
+
+const (
+	// metricsLookback bounds how old the latest value of a metric may be
+	metricsLookback = time.Hour
+
+	// maxActiveAlerts is the number of active alerts returned, newest first
+	maxActiveAlerts = 100
+)
+
+// activeStates are the states of an alert that still needs attention
+var activeStates = []alert.AlertState{alert.AlertStateFiring, alert.AlertStateAcknowledged}
+
+// measurements are the device-tagged measurements whose latest values are
+// returned. Measurements a device never writes are simply empty.
+var measurements = []string{"device_poll", "system_metrics", "interface_metrics", "olt_system"}
+
+// DeviceGetter resolves a registered device by ID
+type DeviceGetter interface {
+	GetDevice(ctx context.Context, id string) (*model.Device, error)
+}
+
+// AlertLister reads the alert history
+type AlertLister interface {
+	ListAlerts(ctx context.Context, query *alertservice.ListAlertsQuery) ([]*alert.AlertHistory, int64, error)
+}
+
+// DeviceDetail is the response body for GET /api/v1/devices/:id/detail.
+// Metrics and alerts that could not be read are reported in Errors and
+// leave their lists empty.
+type DeviceDetail struct {
+	Device       *model.Device         `json:"device"`
+	Metrics      []metrics.Series      `json:"metrics"`
+	ActiveAlerts []*alert.AlertHistory `json:"active_alerts"`
+	Errors       []string              `json:"errors,omitempty"`
+}
+
+// Handler composes the device, metric and alert sources
+type Handler struct {
+	devices DeviceGetter
+	querier metrics.MetricQuerier
+	alerts  AlertLister
+}
+
+func NewHandler(devices DeviceGetter, querier metrics.MetricQuerier, alerts AlertLister) *Handler {
+	return &Handler{devices: devices, querier: querier, alerts: alerts}
+}
+
+// GetDetail handles GET /api/v1/devices/:id/detail
+//
+// Returns 404 when the device does not exist and 500 when it cannot be read.
+// A failing metric or alert read does not fail the request.
+func (h *Handler) GetDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	device, err := h.devices.GetDevice(ctx, c.Param("id"))
+	if errors.Is(err, repository.ErrDeviceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	detail := DeviceDetail{
+		Device:       device,
+		Metrics:      []metrics.Series{},
+		ActiveAlerts: []*alert.AlertHistory{},
+	}
+
+	for _, measurement := range measurements {
+		series, err := h.querier.QueryLatest(ctx, metrics.LatestQuery{
+			Measurement: measurement,
+			Tags:        map[string]string{"device_id": device.ID},
+			Lookback:    metricsLookback,
+		})
+		if err != nil {
+			detail.Errors = append(detail.Errors, fmt.Sprintf("metrics %s: %v", measurement, err))
+			continue
+		}
+		detail.Metrics = append(detail.Metrics, series...)
+	}
+
+	alerts, _, err := h.alerts.ListAlerts(ctx, &alertservice.ListAlertsQuery{
+		DeviceID: device.ID,
+		States:   activeStates,
+		PageSize: maxActiveAlerts,
+	})
+	if err != nil {
+		detail.Errors = append(detail.Errors, fmt.Sprintf("alerts: %v", err))
+	} else if alerts != nil {
+		detail.ActiveAlerts = alerts
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// RegisterRoutes registers the device detail route on the given Gin router group.
+func RegisterRoutes(group *gin.RouterGroup, devices DeviceGetter, querier metrics.MetricQuerier, alerts AlertLister) {
+	h := NewHandler(devices, querier, alerts)
+
+	// GET /api/v1/devices/:id/detail — device, latest metrics and active alerts
+	group.GET("/devices/:id/detail", h.GetDetail)
+}
@@ -0,0 +1,193 @@
+package devicedetail_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/alert"
+	alertservice "github.com/yourorg/nms-go/internal/alert/service"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/features/devicedetail"
+	"github.com/yourorg/nms-go/internal/features/metrics"
+)
+
+// fakeDevices resolves the devices it holds; the "broken" ID fails as if the
+// database were down.
+type fakeDevices map[string]*model.Device
+
+func (f fakeDevices) GetDevice(ctx context.Context, id string) (*model.Device, error) {
+	if id == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	d, ok := f[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", repository.ErrDeviceNotFound, id)
+	}
+	return d, nil
+}
+
+// fakeQuerier returns canned series per measurement and records the queries.
+type fakeQuerier struct {
+	series map[string][]metrics.Series
+	errs   map[string]error
+
+	queries []metrics.LatestQuery
+}
+
+func (f *fakeQuerier) QueryLatest(ctx context.Context, q metrics.LatestQuery) ([]metrics.Series, error) {
+	f.queries = append(f.queries, q)
+	return f.series[q.Measurement], f.errs[q.Measurement]
+}
+
+func (f *fakeQuerier) QueryRange(ctx context.Context, q metrics.RangeQuery) ([]metrics.Series, error) {
+	return nil, nil
+}
+
+// fakeAlerts returns canned alerts and records the query.
+type fakeAlerts struct {
+	alerts []*alert.AlertHistory
+	err    error
+
+	query *alertservice.ListAlertsQuery
+}
+
+func (f *fakeAlerts) ListAlerts(ctx context.Context, q *alertservice.ListAlertsQuery) ([]*alert.AlertHistory, int64, error) {
+	f.query = q
+	return f.alerts, int64(len(f.alerts)), f.err
+}
+
+var detailAt = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var router1 = &model.Device{ID: "dev-1", Name: "core-1", IPAddress: "10.0.0.1", DeviceType: model.DeviceTypeRouter}
+
+func getDetail(t *testing.T, q *fakeQuerier, a *fakeAlerts, id string) (*httptest.ResponseRecorder, devicedetail.DeviceDetail) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	devicedetail.RegisterRoutes(r.Group("/api/v1"), fakeDevices{"dev-1": router1}, q, a)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/devices/"+id+"/detail", nil)
+	r.ServeHTTP(w, req)
+
+	var resp devicedetail.DeviceDetail
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+func TestGetDetail_ComposesDeviceMetricsAndAlerts(t *testing.T) {
+	q := &fakeQuerier{series: map[string][]metrics.Series{
+		"device_poll": {{
+			Measurement: "device_poll",
+			Field:       "rtt_ms",
+			Tags:        map[string]string{"device_id": "dev-1"},
+			Points:      []metrics.Point{{Time: detailAt, Value: 1.5}},
+		}},
+		"system_metrics": {{
+			Measurement: "system_metrics",
+			Field:       "cpu_usage",
+			Tags:        map[string]string{"device_id": "dev-1"},
+			Points:      []metrics.Point{{Time: detailAt, Value: 42}},
+		}},
+	}}
+	a := &fakeAlerts{alerts: []*alert.AlertHistory{
+		{ID: "a-2", RuleID: "rule-2", DeviceID: "dev-1", State: alert.AlertStateAcknowledged, Value: 95, TriggeredAt: detailAt},
+		{ID: "a-1", RuleID: "rule-1", DeviceID: "dev-1", State: alert.AlertStateFiring, Value: 150, TriggeredAt: detailAt},
+	}}
+
+	w, resp := getDetail(t, q, a, "dev-1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.NotNil(t, resp.Device)
+	assert.Equal(t, "core-1", resp.Device.Name)
+
+	require.Len(t, resp.Metrics, 2)
+	assert.Equal(t, "rtt_ms", resp.Metrics[0].Field)
+	assert.Equal(t, 42.0, resp.Metrics[1].Points[0].Value)
+	for _, query := range q.queries {
+		assert.Equal(t, "dev-1", query.Tags["device_id"], query.Measurement)
+		assert.Equal(t, time.Hour, query.Lookback)
+	}
+
+	require.Len(t, resp.ActiveAlerts, 2)
+	assert.Equal(t, "a-2", resp.ActiveAlerts[0].ID)
+	require.NotNil(t, a.query)
+	assert.Equal(t, "dev-1", a.query.DeviceID)
+	assert.Empty(t, a.query.State)
+	assert.Equal(t, []alert.AlertState{alert.AlertStateFiring, alert.AlertStateAcknowledged}, a.query.States,
+		"acknowledged alerts are still active")
+	assert.Empty(t, resp.Errors)
+}
+
+func TestGetDetail_NoMetricsOrAlerts(t *testing.T) {
+	w, resp := getDetail(t, &fakeQuerier{}, &fakeAlerts{}, "dev-1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, "dev-1", resp.Device.ID)
+	assert.NotNil(t, resp.Metrics)
+	assert.Empty(t, resp.Metrics)
+	assert.NotNil(t, resp.ActiveAlerts)
+	assert.Empty(t, resp.ActiveAlerts)
+	assert.JSONEq(t, `[]`, string(mustField(t, w, "active_alerts")))
+	assert.NotContains(t, w.Body.String(), `"errors"`)
+}
+
+func TestGetDetail_FailedSourcesAreReported(t *testing.T) {
+	q := &fakeQuerier{
+		series: map[string][]metrics.Series{
+			"system_metrics": {{Measurement: "system_metrics", Field: "cpu_usage", Points: []metrics.Point{{Time: detailAt, Value: 10}}}},
+		},
+		errs: map[string]error{"device_poll": errors.New("influx unavailable")},
+	}
+	a := &fakeAlerts{err: errors.New("connection refused")}
+
+	w, resp := getDetail(t, q, a, "dev-1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, "dev-1", resp.Device.ID)
+	require.Len(t, resp.Metrics, 1, "the other measurements are still returned")
+	assert.Equal(t, "cpu_usage", resp.Metrics[0].Field)
+	assert.Empty(t, resp.ActiveAlerts)
+	require.Len(t, resp.Errors, 2)
+	assert.Contains(t, resp.Errors[0], "device_poll")
+	assert.Contains(t, resp.Errors[1], "alerts")
+}
+
+func TestGetDetail_DeviceNotFound(t *testing.T) {
+	q := &fakeQuerier{}
+	a := &fakeAlerts{}
+
+	w, _ := getDetail(t, q, a, "missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, q.queries)
+	assert.Nil(t, a.query)
+}
+
+func TestGetDetail_DeviceReadFails(t *testing.T) {
+	q := &fakeQuerier{}
+	a := &fakeAlerts{}
+
+	w, _ := getDetail(t, q, a, "broken")
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "only a missing device is a 404")
+	assert.Contains(t, w.Body.String(), "connection refused")
+	assert.Empty(t, q.queries)
+}
+
+func mustField(t *testing.T, w *httptest.ResponseRecorder, name string) json.RawMessage {
+	t.Helper()
+	var body map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body[name]
+}
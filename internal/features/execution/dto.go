@@ -1,8 +1,35 @@
 package execution
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type ExecuteCommandRequest struct {
-	Target  Target `json:"target" binding:"required"`
-	Command string `json:"command" binding:"required"`
+	Target  Target      `json:"target" binding:"required"`
+	Command CommandList `json:"command" binding:"required,min=1,dive,required"`
+
+	// ContinueOnError runs the remaining commands after one fails instead of stopping.
+	ContinueOnError bool `json:"continue_on_error"`
+}
+
+// CommandList is one or more commands run in order on a single session.
+// A plain JSON string is accepted as a list of one.
+type CommandList []string
+
+func (c *CommandList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*c = CommandList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("command must be a string or a list of strings")
+	}
+	*c = list
+	return nil
 }
 
 type Target struct {
@@ -21,6 +48,29 @@ type ExecuteCommandResponse struct {
 	Status string `json:"status"`
 	Output string `json:"output"`
 	Error  string `json:"error,omitempty"`
+
+	// ErrorKind classifies a failed connect: "auth", "timeout" or "refused".
+	// Callers should not retry "auth" with the same credentials.
+	ErrorKind string `json:"error_kind,omitempty"`
+
+	// ResultType and Result are set for commands with a known reply format
+	// (see ParseCommandResult); Output always carries the raw text.
+	// All three are only filled when a single command was sent.
+	ResultType string      `json:"result_type,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+
+	// Results holds one entry per command that ran, in order.
+	Results []CommandResult `json:"results,omitempty"`
+}
+
+// CommandResult is the outcome of one command in an execution request.
+type CommandResult struct {
+	Command    string      `json:"command"`
+	Status     string      `json:"status"`
+	Output     string      `json:"output"`
+	Error      string      `json:"error,omitempty"`
+	ResultType string      `json:"result_type,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
 }
 
 type GetStatsRequest struct {
@@ -31,4 +81,7 @@ type GetStatsResponse struct {
 	Status string      `json:"status"`
 	Data   interface{} `json:"data,omitempty"`
 	Error  string      `json:"error,omitempty"`
+
+	// ErrorKind classifies a failed connect, as in ExecuteCommandResponse.
+	ErrorKind string `json:"error_kind,omitempty"`
 }
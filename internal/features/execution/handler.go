@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
 )
 
 type ExecutionHandler struct {
@@ -17,7 +18,7 @@ func NewExecutionHandler(service ExecutionService) *ExecutionHandler {
 func (h *ExecutionHandler) ExecuteCommand(c *gin.Context) {
 	var req ExecuteCommandRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
 		return
 	}
 
@@ -33,7 +34,7 @@ func (h *ExecutionHandler) ExecuteCommand(c *gin.Context) {
 func (h *ExecutionHandler) GetStats(c *gin.Context) {
 	var req GetStatsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
 		return
 	}
 
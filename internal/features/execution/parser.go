@@ -0,0 +1,100 @@
+package execution
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Result types reported in ExecuteCommandResponse.ResultType.
+const (
+	ResultTypeInterfaces  = "interfaces"
+	ResultTypeIPAddresses = "ip_addresses"
+)
+
+// InterfaceEntry is one row of /interface/print.
+type InterfaceEntry struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	MTU        int    `json:"mtu,omitempty"`
+	MACAddress string `json:"mac_address,omitempty"`
+	Running    bool   `json:"running"`
+	Disabled   bool   `json:"disabled"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// IPAddressEntry is one row of /ip/address/print.
+type IPAddressEntry struct {
+	ID        string `json:"id"`
+	Address   string `json:"address"`
+	Network   string `json:"network"`
+	Interface string `json:"interface"`
+	Dynamic   bool   `json:"dynamic"`
+	Invalid   bool   `json:"invalid"`
+	Disabled  bool   `json:"disabled"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// commandParser converts RouterOS reply sentences into a typed result.
+type commandParser struct {
+	resultType string
+	parse      func(sentences []map[string]string) interface{}
+}
+
+// commandParsers is keyed by the RouterOS command word, without arguments.
+var commandParsers = map[string]commandParser{
+	"/interface/print":  {ResultTypeInterfaces, parseInterfaces},
+	"/ip/address/print": {ResultTypeIPAddresses, parseIPAddresses},
+}
+
+// ParseCommandResult returns a structured form of a RouterOS command's reply,
+// selected by the command word. ok is false for commands without a parser,
+// in which case callers should fall back to the raw output.
+func ParseCommandResult(command string, sentences []map[string]string) (resultType string, result interface{}, ok bool) {
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return "", nil, false
+	}
+
+	p, ok := commandParsers[strings.TrimSuffix(parts[0], "/")]
+	if !ok {
+		return "", nil, false
+	}
+
+	return p.resultType, p.parse(sentences), true
+}
+
+func parseInterfaces(sentences []map[string]string) interface{} {
+	entries := make([]InterfaceEntry, 0, len(sentences))
+	for _, s := range sentences {
+		mtu, _ := strconv.Atoi(s["mtu"])
+		entries = append(entries, InterfaceEntry{
+			ID:         s[".id"],
+			Name:       s["name"],
+			Type:       s["type"],
+			MTU:        mtu,
+			MACAddress: s["mac-address"],
+			Running:    s["running"] == "true",
+			Disabled:   s["disabled"] == "true",
+			Comment:    s["comment"],
+		})
+	}
+	return entries
+}
+
+func parseIPAddresses(sentences []map[string]string) interface{} {
+	entries := make([]IPAddressEntry, 0, len(sentences))
+	for _, s := range sentences {
+		entries = append(entries, IPAddressEntry{
+			ID:        s[".id"],
+			Address:   s["address"],
+			Network:   s["network"],
+			Interface: s["interface"],
+			Dynamic:   s["dynamic"] == "true",
+			Invalid:   s["invalid"] == "true",
+			Disabled:  s["disabled"] == "true",
+			Comment:   s["comment"],
+		})
+	}
+	return entries
+}
@@ -0,0 +1,52 @@
+package execution_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/features/execution"
+)
+
+func TestParseCommandResult_Interfaces(t *testing.T) {
+	sentences := []map[string]string{
+		{".id": "*1", "name": "ether1", "type": "ether", "mtu": "1500", "mac-address": "4C:5E:0C:11:22:33", "running": "true", "disabled": "false"},
+		{".id": "*2", "name": "ether2", "type": "ether", "mtu": "1500", "mac-address": "4C:5E:0C:11:22:34", "running": "false", "disabled": "true", "comment": "uplink spare"},
+	}
+
+	resultType, result, ok := execution.ParseCommandResult("/interface/print", sentences)
+	require.True(t, ok)
+	assert.Equal(t, execution.ResultTypeInterfaces, resultType)
+	assert.Equal(t, []execution.InterfaceEntry{
+		{ID: "*1", Name: "ether1", Type: "ether", MTU: 1500, MACAddress: "4C:5E:0C:11:22:33", Running: true},
+		{ID: "*2", Name: "ether2", Type: "ether", MTU: 1500, MACAddress: "4C:5E:0C:11:22:34", Disabled: true, Comment: "uplink spare"},
+	}, result)
+}
+
+func TestParseCommandResult_IPAddresses(t *testing.T) {
+	sentences := []map[string]string{
+		{".id": "*1", "address": "192.168.88.1/24", "network": "192.168.88.0", "interface": "bridge", "dynamic": "false", "invalid": "false", "disabled": "false"},
+		{".id": "*3", "address": "10.10.0.2/30", "network": "10.10.0.0", "interface": "ether1", "dynamic": "true", "invalid": "false", "disabled": "false"},
+	}
+
+	// Arguments after the command word don't affect parser selection.
+	resultType, result, ok := execution.ParseCommandResult("/ip/address/print ?interface=ether1", sentences)
+	require.True(t, ok)
+	assert.Equal(t, execution.ResultTypeIPAddresses, resultType)
+	assert.Equal(t, []execution.IPAddressEntry{
+		{ID: "*1", Address: "192.168.88.1/24", Network: "192.168.88.0", Interface: "bridge"},
+		{ID: "*3", Address: "10.10.0.2/30", Network: "10.10.0.0", Interface: "ether1", Dynamic: true},
+	}, result)
+}
+
+func TestParseCommandResult_EmptyReply(t *testing.T) {
+	_, result, ok := execution.ParseCommandResult("/interface/print", nil)
+	require.True(t, ok)
+	assert.Equal(t, []execution.InterfaceEntry{}, result)
+}
+
+func TestParseCommandResult_UnknownCommandFallsBack(t *testing.T) {
+	_, result, ok := execution.ParseCommandResult("/system/resource/print", []map[string]string{{"uptime": "1d2h"}})
+	assert.False(t, ok)
+	assert.Nil(t, result)
+}
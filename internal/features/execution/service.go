@@ -2,11 +2,16 @@ package execution
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"time"
 
+	"github.com/yourorg/nms-go/internal/common/telemetry"
 	"github.com/yourorg/nms-go/internal/device/model"
 	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+	"github.com/yourorg/nms-go/internal/worker/protocols/telnet"
 )
 
 type ExecutionService interface {
@@ -14,57 +19,197 @@ type ExecutionService interface {
 	GetStats(ctx context.Context, req GetStatsRequest) (*GetStatsResponse, error)
 }
 
+// Session is an open connection to a device that commands run over in order.
+type Session interface {
+	// Run executes one command and returns its result. A non-nil error means
+	// the command failed; the session stays usable for the next command.
+	Run(ctx context.Context, command string) (*CommandResult, error)
+	Close() error
+}
+
+// Dialer opens a Session to the target, logging in if the driver requires it.
+type Dialer func(ctx context.Context, target Target) (Session, error)
+
 type executionService struct {
+	dialers map[string]Dialer
 }
 
 func NewExecutionService() ExecutionService {
-	return &executionService{}
+	return &executionService{
+		dialers: map[string]Dialer{
+			"mikrotik": dialMikrotik,
+			"telnet":   dialTelnet,
+		},
+	}
 }
 
+// NewExecutionServiceForTest creates an ExecutionService that opens sessions
+// with the given per-driver dialers.
+// This is intended for use in unit tests to inject fake sessions.
+func NewExecutionServiceForTest(dialers map[string]Dialer) ExecutionService {
+	return &executionService{dialers: dialers}
+}
+
+// ExecuteCommand runs the request's commands in order over a single session.
+// It stops at the first failed command unless ContinueOnError is set.
 func (s *executionService) ExecuteCommand(ctx context.Context, req ExecuteCommandRequest) (*ExecuteCommandResponse, error) {
-	// 1. Create temporary device model
+	dial, ok := s.dialers[req.Target.Driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported driver: %s", req.Target.Driver)
+	}
+
+	session, err := dial(ctx, req.Target)
+	if err != nil {
+		return &ExecuteCommandResponse{
+			Status:    "error",
+			Error:     err.Error(),
+			ErrorKind: connectErrorKind(err),
+		}, nil
+	}
+	defer func() { telemetry.RecordDisconnect(req.Target.Driver, req.Target.IP, session.Close()) }()
+
+	resp := &ExecuteCommandResponse{
+		Status:  "success",
+		Results: make([]CommandResult, 0, len(req.Command)),
+	}
+	for _, command := range req.Command {
+		result, err := session.Run(ctx, command)
+		if err != nil {
+			result = &CommandResult{
+				Status: "error",
+				Error:  fmt.Sprintf("execution failed: %v", err),
+			}
+		}
+		result.Command = command
+		resp.Results = append(resp.Results, *result)
+
+		if err != nil {
+			if resp.Status == "success" {
+				resp.Status = "error"
+				resp.Error = fmt.Sprintf("command %q: %s", command, result.Error)
+			}
+			if !req.ContinueOnError {
+				break
+			}
+		}
+	}
+
+	// A single command keeps its result at the top level, as before lists were accepted.
+	if len(req.Command) == 1 {
+		only := resp.Results[0]
+		resp.Output = only.Output
+		resp.ResultType = only.ResultType
+		resp.Result = only.Result
+		resp.Error = only.Error
+	}
+
+	return resp, nil
+}
+
+// connectErrorKind names the class of a failed connect for API clients, or
+// returns "" when it is not known.
+func connectErrorKind(err error) string {
+	switch {
+	case errors.Is(err, mikrotik.ErrAuth):
+		return "auth"
+	case errors.Is(err, mikrotik.ErrTimeout):
+		return "timeout"
+	case errors.Is(err, mikrotik.ErrRefused):
+		return "refused"
+	default:
+		return ""
+	}
+}
+
+// mikrotikSession runs commands over the RouterOS API.
+type mikrotikSession struct {
+	client *mikrotik.MikrotikClient
+}
+
+func dialMikrotik(ctx context.Context, target Target) (Session, error) {
+	// Temporary device model for the client
 	device := &model.Device{
 		ID:        "adhoc",
-		IPAddress: req.Target.IP,
+		IPAddress: target.IP,
 		Protocol:  model.ProtocolMikrotikAPI,
 		Credentials: &model.DeviceCredentials{
-			Username:          req.Target.Auth.Username,
-			PasswordEncrypted: req.Target.Auth.Password, // Passing plain password as expected by current client implementation
+			Username:          target.Auth.Username,
+			PasswordEncrypted: target.Auth.Password, // Passing plain password as expected by current client implementation
 		},
 	}
 
-	// 2. Select driver
-	if req.Target.Driver != "mikrotik" {
-		return nil, fmt.Errorf("unsupported driver: %s", req.Target.Driver)
+	client := mikrotik.NewMikrotikClient(10 * time.Second)
+	if err := client.Connect(ctx, device); err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
-	// 3. Initiate Client
-	client := mikrotik.NewMikrotikClient(10 * time.Second)
+	return &mikrotikSession{client: client}, nil
+}
 
-	// 4. Connect
-	if err := client.Connect(ctx, device); err != nil {
-		return &ExecuteCommandResponse{
-			Status: "error",
-			Error:  fmt.Sprintf("failed to connect: %v", err),
-		}, nil
+func (m *mikrotikSession) Run(ctx context.Context, command string) (*CommandResult, error) {
+	sentences, err := m.client.RunCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add a typed result for commands we know how to parse
+	result := &CommandResult{
+		Status: "success",
+		Output: mikrotik.FormatSentences(sentences),
+	}
+	if resultType, parsed, ok := ParseCommandResult(command, sentences); ok {
+		result.ResultType = resultType
+		result.Result = parsed
 	}
-	defer client.Disconnect()
 
-	// 5. Execute
-	output, err := client.ExecuteCommand(ctx, req.Command)
+	return result, nil
+}
+
+func (m *mikrotikSession) Close() error {
+	return m.client.Disconnect()
+}
+
+// telnetSession runs commands on a legacy device's CLI.
+type telnetSession struct {
+	client *telnet.Client
+}
+
+// dialTelnet connects over Telnet (default port 23) and logs in.
+func dialTelnet(ctx context.Context, target Target) (Session, error) {
+	port := target.Auth.Port
+	if port == 0 {
+		port = telnet.DefaultPort
+	}
+
+	client, err := telnet.Dial(ctx, net.JoinHostPort(target.IP, strconv.Itoa(port)), telnet.Config{Timeout: 10 * time.Second})
 	if err != nil {
-		return &ExecuteCommandResponse{
-			Status: "error",
-			Error:  fmt.Sprintf("execution failed: %v", err),
-		}, nil
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	if err := client.Login(target.Auth.Username, target.Auth.Password); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to login: %w", err)
 	}
 
-	return &ExecuteCommandResponse{
+	return &telnetSession{client: client}, nil
+}
+
+func (t *telnetSession) Run(_ context.Context, command string) (*CommandResult, error) {
+	output, err := t.client.Execute(command)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CommandResult{
 		Status: "success",
 		Output: output,
 	}, nil
 }
 
+func (t *telnetSession) Close() error {
+	return t.client.Close()
+}
+
 func (s *executionService) GetStats(ctx context.Context, req GetStatsRequest) (*GetStatsResponse, error) {
 	// 1. Create temporary device model
 	device := &model.Device{
@@ -88,11 +233,12 @@ func (s *executionService) GetStats(ctx context.Context, req GetStatsRequest) (*
 	// 4. Connect
 	if err := client.Connect(ctx, device); err != nil {
 		return &GetStatsResponse{
-			Status: "error",
-			Error:  fmt.Sprintf("failed to connect: %v", err),
+			Status:    "error",
+			Error:     fmt.Sprintf("failed to connect: %v", err),
+			ErrorKind: connectErrorKind(err),
 		}, nil
 	}
-	defer client.Disconnect()
+	defer telemetry.Disconnect(client, string(model.ProtocolMikrotikAPI), device.IPAddress)
 
 	// 5. Get Metrics
 	systemMetrics, err := client.GetSystemMetrics(ctx)
@@ -0,0 +1,181 @@
+package execution_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/features/execution"
+	mikrotik "github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+// fakeSession records commands in order and fails those listed in fail.
+type fakeSession struct {
+	ran    []string
+	fail   map[string]error
+	closed bool
+}
+
+func (f *fakeSession) Run(_ context.Context, command string) (*execution.CommandResult, error) {
+	f.ran = append(f.ran, command)
+	if err := f.fail[command]; err != nil {
+		return nil, err
+	}
+	return &execution.CommandResult{Status: "success", Output: "ok: " + command}, nil
+}
+
+func (f *fakeSession) Close() error {
+	f.closed = true
+	return nil
+}
+
+func newService(session *fakeSession, dials *int) execution.ExecutionService {
+	return execution.NewExecutionServiceForTest(map[string]execution.Dialer{
+		"mikrotik": func(context.Context, execution.Target) (execution.Session, error) {
+			*dials++
+			return session, nil
+		},
+	})
+}
+
+func request(continueOnError bool, commands ...string) execution.ExecuteCommandRequest {
+	return execution.ExecuteCommandRequest{
+		Target:          execution.Target{IP: "10.0.0.1", Driver: "mikrotik"},
+		Command:         commands,
+		ContinueOnError: continueOnError,
+	}
+}
+
+func TestExecuteCommand_RunsSequentiallyOnOneSession(t *testing.T) {
+	session := &fakeSession{}
+	dials := 0
+	svc := newService(session, &dials)
+
+	resp, err := svc.ExecuteCommand(context.Background(),
+		request(false, "/interface/disable numbers=ether1", "/ping address=10.0.0.2 count=3", "/interface/enable numbers=ether1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, dials)
+	assert.True(t, session.closed)
+	assert.Equal(t, []string{"/interface/disable numbers=ether1", "/ping address=10.0.0.2 count=3", "/interface/enable numbers=ether1"}, session.ran)
+
+	assert.Equal(t, "success", resp.Status)
+	require.Len(t, resp.Results, 3)
+	for i, r := range resp.Results {
+		assert.Equal(t, session.ran[i], r.Command)
+		assert.Equal(t, "success", r.Status)
+		assert.Equal(t, "ok: "+session.ran[i], r.Output)
+	}
+	assert.Empty(t, resp.Output, "top-level output is only set for a single command")
+}
+
+func TestExecuteCommand_StopsOnFirstError(t *testing.T) {
+	session := &fakeSession{fail: map[string]error{"/bad": errors.New("no such command")}}
+	dials := 0
+	svc := newService(session, &dials)
+
+	resp, err := svc.ExecuteCommand(context.Background(), request(false, "/one", "/bad", "/three"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/one", "/bad"}, session.ran)
+	assert.Equal(t, "error", resp.Status)
+	assert.Contains(t, resp.Error, "/bad")
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "success", resp.Results[0].Status)
+	assert.Equal(t, "error", resp.Results[1].Status)
+	assert.Equal(t, "execution failed: no such command", resp.Results[1].Error)
+}
+
+func TestExecuteCommand_ContinueOnError(t *testing.T) {
+	session := &fakeSession{fail: map[string]error{"/bad": errors.New("no such command")}}
+	dials := 0
+	svc := newService(session, &dials)
+
+	resp, err := svc.ExecuteCommand(context.Background(), request(true, "/one", "/bad", "/three"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/one", "/bad", "/three"}, session.ran)
+	assert.Equal(t, "error", resp.Status)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, []string{"success", "error", "success"},
+		[]string{resp.Results[0].Status, resp.Results[1].Status, resp.Results[2].Status})
+}
+
+func TestExecuteCommand_SingleCommandKeepsTopLevelFields(t *testing.T) {
+	session := &fakeSession{}
+	dials := 0
+	svc := newService(session, &dials)
+
+	resp, err := svc.ExecuteCommand(context.Background(), request(false, "/system/resource/print"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "success", resp.Status)
+	assert.Equal(t, "ok: /system/resource/print", resp.Output)
+	require.Len(t, resp.Results, 1)
+}
+
+func TestExecuteCommand_DialFailure(t *testing.T) {
+	svc := execution.NewExecutionServiceForTest(map[string]execution.Dialer{
+		"mikrotik": func(context.Context, execution.Target) (execution.Session, error) {
+			return nil, errors.New("failed to connect: connection refused")
+		},
+	})
+
+	resp, err := svc.ExecuteCommand(context.Background(), request(false, "/one", "/two"))
+	require.NoError(t, err)
+	assert.Equal(t, "error", resp.Status)
+	assert.Equal(t, "failed to connect: connection refused", resp.Error)
+	assert.Empty(t, resp.ErrorKind, "an unclassified error has no kind")
+	assert.Empty(t, resp.Results)
+}
+
+func TestExecuteCommand_DialFailureKind(t *testing.T) {
+	tests := []struct {
+		kind error
+		want string
+	}{
+		{mikrotik.ErrAuth, "auth"},
+		{mikrotik.ErrTimeout, "timeout"},
+		{mikrotik.ErrRefused, "refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			svc := execution.NewExecutionServiceForTest(map[string]execution.Dialer{
+				"mikrotik": func(context.Context, execution.Target) (execution.Session, error) {
+					connErr := &mikrotik.ConnectError{Address: "10.0.0.1:8728", Kind: tt.kind, Err: errors.New("dial")}
+					return nil, fmt.Errorf("failed to connect: %w", connErr)
+				},
+			})
+
+			resp, err := svc.ExecuteCommand(context.Background(), request(false, "/one"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.ErrorKind)
+		})
+	}
+}
+
+func TestExecuteCommand_UnsupportedDriver(t *testing.T) {
+	svc := execution.NewExecutionServiceForTest(map[string]execution.Dialer{})
+
+	_, err := svc.ExecuteCommand(context.Background(), request(false, "/one"))
+	assert.EqualError(t, err, "unsupported driver: mikrotik")
+}
+
+func TestCommandList_AcceptsStringOrList(t *testing.T) {
+	var single execution.ExecuteCommandRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"command":"/system/resource/print"}`), &single))
+	assert.Equal(t, execution.CommandList{"/system/resource/print"}, single.Command)
+
+	var list execution.ExecuteCommandRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"command":["/one","/two"],"continue_on_error":true}`), &list))
+	assert.Equal(t, execution.CommandList{"/one", "/two"}, list.Command)
+	assert.True(t, list.ContinueOnError)
+
+	var bad execution.ExecuteCommandRequest
+	assert.Error(t, json.Unmarshal([]byte(`{"command":42}`), &bad))
+}
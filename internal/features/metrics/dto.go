@@ -0,0 +1,93 @@
+package metrics
+
+// LatestRequest holds the query parameters for GET /api/v1/metrics/latest.
+type LatestRequest struct {
+	// Measurement is the metric measurement name, e.g. "device_poll" (required).
+	Measurement string `form:"measurement" binding:"required"`
+
+	// Field restricts the result to a single field, e.g. "rtt_ms".
+	Field string `form:"field"`
+
+	// DeviceID and Interface filter on the corresponding tags.
+	DeviceID  string `form:"device_id"`
+	Interface string `form:"interface"`
+
+	// Lookback is how far back to search, as a Go duration (default: "1h").
+	Lookback string `form:"lookback"`
+}
+
+// RangeRequest holds the query parameters for GET /api/v1/metrics/range.
+type RangeRequest struct {
+	Measurement string `form:"measurement" binding:"required"`
+	Field       string `form:"field"`
+	DeviceID    string `form:"device_id"`
+	Interface   string `form:"interface"`
+
+	// Start and Stop are RFC3339 timestamps (default: the last hour).
+	Start string `form:"start"`
+	Stop  string `form:"stop"`
+
+	// Window is the downsampling window as a Go duration, e.g. "5m".
+	// Raw points are returned when empty.
+	Window string `form:"window"`
+
+	// Aggregate is the downsampling function (default: "mean").
+	Aggregate string `form:"aggregate"`
+}
+
+// UtilizationRequest holds the query parameters for
+// GET /api/v1/devices/:id/interfaces/:name/utilization.
+type UtilizationRequest struct {
+	// Range is how far back to compute utilization, as a Go duration (default: "1h").
+	Range string `form:"range"`
+}
+
+// TopInterfacesRequest holds the query parameters for GET /api/v1/reports/top-interfaces.
+type TopInterfacesRequest struct {
+	// Metric is the counter to rank by (default: "bytes_out").
+	Metric string `form:"metric" binding:"omitempty,oneof=bytes_in bytes_out"`
+
+	// Limit is the number of interfaces returned (default: 10, max: 100).
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+
+	// Range is the period to average over, as a Go duration (default: "1h").
+	Range string `form:"range"`
+}
+
+// SlowDevicesRequest holds the query parameters for GET /api/v1/reports/slow-devices.
+type SlowDevicesRequest struct {
+	// Limit is the number of devices returned (default: 10, max: 100).
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+
+	// Range is the period to average over, as a Go duration (default: "1h").
+	Range string `form:"range"`
+}
+
+// PollStatsRequest holds the query parameters for GET /api/v1/devices/:id/poll-stats.
+type PollStatsRequest struct {
+	// Range is the period to summarise, as a Go duration (default: "24h").
+	Range string `form:"range"`
+}
+
+// GroupMetricsRequest holds the query parameters for GET /api/v1/groups/:id/metrics.
+type GroupMetricsRequest struct {
+	// Lookback is how far back to search for each device's latest values, as
+	// a Go duration (default: "1h").
+	Lookback string `form:"lookback"`
+}
+
+// SeriesResponse is the response body for the metrics read endpoints.
+type SeriesResponse struct {
+	Series []Series `json:"series"`
+}
+
+func tagFilters(deviceID, iface string) map[string]string {
+	tags := make(map[string]string)
+	if deviceID != "" {
+		tags["device_id"] = deviceID
+	}
+	if iface != "" {
+		tags["interface"] = iface
+	}
+	return tags
+}
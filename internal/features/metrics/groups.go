@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+)
+
+// System metrics written by the monitoring scheduler.
+const (
+	systemMeasurement = "system_metrics"
+	fieldCPUUsage     = "cpu_usage"
+	fieldMemoryUsage  = "memory_usage"
+)
+
+// Device states in a group rollup, taken from each device's latest poll.
+const (
+	GroupDeviceUp     = "up"
+	GroupDeviceDown   = "down"
+	GroupDeviceNoData = "no_data"
+)
+
+// GroupDeviceLister resolves the devices of a device group.
+type GroupDeviceLister interface {
+	GetByGroup(ctx context.Context, groupID string) ([]*model.Device, error)
+}
+
+// GroupDeviceMetrics is the latest state of one device in a group. Metrics
+// are null when nothing was written within the lookback.
+type GroupDeviceMetrics struct {
+	DeviceID    string   `json:"device_id"`
+	Name        string   `json:"name"`
+	State       string   `json:"state"`
+	RTTMs       *float64 `json:"rtt_ms"`
+	CPUUsage    *float64 `json:"cpu_usage"`
+	MemoryUsage *float64 `json:"memory_usage"`
+}
+
+// GroupMetricsResponse is the response body for GET /api/v1/groups/:id/metrics.
+// Averages cover the devices that reported the metric and are null when none
+// did; the RTT average only counts devices that are up.
+type GroupMetricsResponse struct {
+	GroupID        string               `json:"group_id"`
+	DeviceCount    int                  `json:"device_count"`
+	UpCount        int                  `json:"up_count"`
+	DownCount      int                  `json:"down_count"`
+	NoDataCount    int                  `json:"no_data_count"`
+	AvgRTTMs       *float64             `json:"avg_rtt_ms"`
+	AvgCPUUsage    *float64             `json:"avg_cpu_usage"`
+	AvgMemoryUsage *float64             `json:"avg_memory_usage"`
+	Devices        []GroupDeviceMetrics `json:"devices"`
+}
+
+// rollupGroup aggregates the latest poll and system metric series of the
+// given devices. Series of devices outside the group are ignored.
+func rollupGroup(groupID string, devices []*model.Device, poll, system []Series) GroupMetricsResponse {
+	resp := GroupMetricsResponse{
+		GroupID:     groupID,
+		DeviceCount: len(devices),
+		Devices:     make([]GroupDeviceMetrics, 0, len(devices)),
+	}
+
+	byID := make(map[string]*GroupDeviceMetrics, len(devices))
+	for _, d := range devices {
+		resp.Devices = append(resp.Devices, GroupDeviceMetrics{DeviceID: d.ID, Name: d.Name, State: GroupDeviceNoData})
+	}
+	sort.Slice(resp.Devices, func(i, j int) bool { return resp.Devices[i].DeviceID < resp.Devices[j].DeviceID })
+	for i := range resp.Devices {
+		byID[resp.Devices[i].DeviceID] = &resp.Devices[i]
+	}
+
+	for _, s := range poll {
+		d, value, ok := latestFor(byID, s)
+		if !ok {
+			continue
+		}
+		switch s.Field {
+		case fieldSuccess:
+			d.State = GroupDeviceDown
+			if value != 0 {
+				d.State = GroupDeviceUp
+			}
+		case fieldRTT:
+			d.RTTMs = &value
+		}
+	}
+
+	for _, s := range system {
+		d, value, ok := latestFor(byID, s)
+		if !ok {
+			continue
+		}
+		switch s.Field {
+		case fieldCPUUsage:
+			d.CPUUsage = &value
+		case fieldMemoryUsage:
+			d.MemoryUsage = &value
+		}
+	}
+
+	var rtt, cpu, memory []float64
+	for i := range resp.Devices {
+		d := &resp.Devices[i]
+		switch d.State {
+		case GroupDeviceUp:
+			resp.UpCount++
+			if d.RTTMs != nil {
+				rtt = append(rtt, *d.RTTMs)
+			}
+		case GroupDeviceDown:
+			resp.DownCount++
+			d.RTTMs = nil // a failed poll's RTT is meaningless
+		default:
+			resp.NoDataCount++
+		}
+		if d.CPUUsage != nil {
+			cpu = append(cpu, *d.CPUUsage)
+		}
+		if d.MemoryUsage != nil {
+			memory = append(memory, *d.MemoryUsage)
+		}
+	}
+
+	resp.AvgRTTMs = average(rtt)
+	resp.AvgCPUUsage = average(cpu)
+	resp.AvgMemoryUsage = average(memory)
+
+	return resp
+}
+
+// latestFor returns the group device a series belongs to and its most recent
+// value.
+func latestFor(byID map[string]*GroupDeviceMetrics, s Series) (*GroupDeviceMetrics, float64, bool) {
+	d, ok := byID[s.Tags["device_id"]]
+	if !ok || len(s.Points) == 0 {
+		return nil, 0, false
+	}
+	latest := s.Points[0]
+	for _, p := range s.Points[1:] {
+		if p.Time.After(latest.Time) {
+			latest = p
+		}
+	}
+	return d, latest.Value, true
+}
+
+func average(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	avg := sum / float64(len(values))
+	return &avg
+}
@@ -0,0 +1,414 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+const defaultRange = time.Hour
+
+// Handler is the Gin HTTP handler for the metrics read API.
+type Handler struct {
+	querier MetricQuerier
+	devices GroupDeviceLister
+}
+
+// NewHandler creates a new metrics HTTP handler. devices resolves the members
+// of a device group for the group rollup.
+func NewHandler(querier MetricQuerier, devices GroupDeviceLister) *Handler {
+	return &Handler{querier: querier, devices: devices}
+}
+
+// GetLatest handles GET /api/v1/metrics/latest
+//
+// Returns the most recent value of every series matching the query.
+func (h *Handler) GetLatest(c *gin.Context) {
+	var req LatestRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	q := LatestQuery{
+		Measurement: req.Measurement,
+		Field:       req.Field,
+		Tags:        tagFilters(req.DeviceID, req.Interface),
+	}
+
+	if req.Lookback != "" {
+		lookback, err := time.ParseDuration(req.Lookback)
+		if err != nil || lookback <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lookback: " + req.Lookback})
+			return
+		}
+		q.Lookback = lookback
+	}
+
+	series, err := h.querier.QueryLatest(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SeriesResponse{Series: series})
+}
+
+// GetRange handles GET /api/v1/metrics/range
+//
+// Returns the values of every series matching the query between start and stop,
+// optionally downsampled by window and aggregate.
+func (h *Handler) GetRange(c *gin.Context) {
+	var req RangeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	q, err := req.toQuery(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := h.querier.QueryRange(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SeriesResponse{Series: series})
+}
+
+// GetInterfaceUtilization handles GET /api/v1/devices/:id/interfaces/:name/utilization
+//
+// Returns in/out bits per second for one interface over the requested range
+// (Go duration, default 1h), computed from the stored byte counters.
+func (h *Handler) GetInterfaceUtilization(c *gin.Context) {
+	var req UtilizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	rng := defaultRange
+	if req.Range != "" {
+		parsed, err := time.ParseDuration(req.Range)
+		if err != nil || parsed <= 0 || parsed > maxUtilizationRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid range: %s (max %s)", req.Range, maxUtilizationRange)})
+			return
+		}
+		rng = parsed
+	}
+
+	deviceID, iface := c.Param("id"), c.Param("name")
+	stop := time.Now()
+	q := RangeQuery{
+		Measurement: interfaceMeasurement,
+		Tags:        tagFilters(deviceID, iface),
+		Start:       stop.Add(-rng),
+		Stop:        stop,
+	}
+
+	series, err := h.querier.QueryRange(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := UtilizationResponse{
+		DeviceID:  deviceID,
+		Interface: iface,
+		Start:     q.Start,
+		Stop:      q.Stop,
+		In:        []Point{},
+		Out:       []Point{},
+	}
+	for _, s := range series {
+		switch s.Field {
+		case fieldBytesIn:
+			resp.In = CounterRates(s.Points)
+		case fieldBytesOut:
+			resp.Out = CounterRates(s.Points)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTopInterfaces handles GET /api/v1/reports/top-interfaces
+//
+// Ranks interfaces network-wide by average bytes_in or bytes_out rate over
+// the requested range and returns the busiest ones.
+func (h *Handler) GetTopInterfaces(c *gin.Context) {
+	var req TopInterfacesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	if req.Metric == "" {
+		req.Metric = fieldBytesOut
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultTopLimit
+	}
+
+	rng := defaultRange
+	if req.Range != "" {
+		parsed, err := time.ParseDuration(req.Range)
+		if err != nil || parsed <= 0 || parsed > maxUtilizationRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid range: %s (max %s)", req.Range, maxUtilizationRange)})
+			return
+		}
+		rng = parsed
+	}
+
+	stop := time.Now()
+	q := RangeQuery{
+		Measurement: interfaceMeasurement,
+		Field:       req.Metric,
+		Start:       stop.Add(-rng),
+		Stop:        stop,
+		Window:      rng / topWindows,
+		Aggregate:   AggregateLast,
+	}
+
+	series, err := h.querier.QueryRange(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, TopInterfacesResponse{
+		Metric: req.Metric,
+		Start:  q.Start,
+		Stop:   q.Stop,
+		Data:   rankInterfaces(series, req.Limit),
+	})
+}
+
+// GetSlowDevices handles GET /api/v1/reports/slow-devices
+//
+// Ranks devices by average poll duration over the requested range and
+// returns the slowest ones, which hold up the collection cycle the most.
+func (h *Handler) GetSlowDevices(c *gin.Context) {
+	var req SlowDevicesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultTopLimit
+	}
+
+	rng := defaultRange
+	if req.Range != "" {
+		parsed, err := time.ParseDuration(req.Range)
+		if err != nil || parsed <= 0 || parsed > maxPollStatsRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid range: %s (max %s)", req.Range, maxPollStatsRange)})
+			return
+		}
+		rng = parsed
+	}
+
+	stop := time.Now()
+	q := RangeQuery{
+		Measurement: pollMeasurement,
+		Field:       fieldPollDuration,
+		Start:       stop.Add(-rng),
+		Stop:        stop,
+		Window:      rng / topWindows,
+		Aggregate:   AggregateMean,
+	}
+
+	means, err := h.querier.QueryRange(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	q.Aggregate = AggregateMax
+	peaks, err := h.querier.QueryRange(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SlowDevicesResponse{
+		Start: q.Start,
+		Stop:  q.Stop,
+		Data:  rankSlowDevices(means, peaks, req.Limit),
+	})
+}
+
+// GetPollStats handles GET /api/v1/devices/:id/poll-stats
+//
+// Returns poll count, success rate and RTT statistics for a device over the
+// requested range (Go duration, default 24h).
+func (h *Handler) GetPollStats(c *gin.Context) {
+	var req PollStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	rng := defaultPollStatsRange
+	if req.Range != "" {
+		parsed, err := time.ParseDuration(req.Range)
+		if err != nil || parsed <= 0 || parsed > maxPollStatsRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid range: %s (max %s)", req.Range, maxPollStatsRange)})
+			return
+		}
+		rng = parsed
+	}
+
+	deviceID := c.Param("id")
+	stop := time.Now()
+	q := RangeQuery{
+		Measurement: pollMeasurement,
+		Tags:        tagFilters(deviceID, ""),
+		Start:       stop.Add(-rng),
+		Stop:        stop,
+	}
+
+	series, err := h.querier.QueryRange(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats := computePollStats(series)
+	stats.DeviceID = deviceID
+	stats.Start = q.Start
+	stats.Stop = q.Stop
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetGroupMetrics handles GET /api/v1/groups/:id/metrics
+//
+// Resolves the devices of a group and rolls up their latest poll and system
+// metrics: how many are up or down, and average RTT, CPU and memory usage.
+func (h *Handler) GetGroupMetrics(c *gin.Context) {
+	var req GroupMetricsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	var lookback time.Duration
+	if req.Lookback != "" {
+		parsed, err := time.ParseDuration(req.Lookback)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lookback: " + req.Lookback})
+			return
+		}
+		lookback = parsed
+	}
+
+	groupID := c.Param("id")
+	devices, err := h.devices.GetByGroup(c.Request.Context(), groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(devices) == 0 {
+		c.JSON(http.StatusOK, rollupGroup(groupID, nil, nil, nil))
+		return
+	}
+
+	poll, err := h.querier.QueryLatest(c.Request.Context(), LatestQuery{Measurement: pollMeasurement, Lookback: lookback})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	system, err := h.querier.QueryLatest(c.Request.Context(), LatestQuery{Measurement: systemMeasurement, Lookback: lookback})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rollupGroup(groupID, devices, poll, system))
+}
+
+// toQuery converts the request into a validated RangeQuery, applying defaults.
+func (req RangeRequest) toQuery(now time.Time) (RangeQuery, error) {
+	q := RangeQuery{
+		Measurement: req.Measurement,
+		Field:       req.Field,
+		Tags:        tagFilters(req.DeviceID, req.Interface),
+		Stop:        now,
+		Aggregate:   AggregateMean,
+	}
+
+	if req.Stop != "" {
+		stop, err := time.Parse(time.RFC3339, req.Stop)
+		if err != nil {
+			return q, fmt.Errorf("invalid stop: %s", req.Stop)
+		}
+		q.Stop = stop
+	}
+
+	q.Start = q.Stop.Add(-defaultRange)
+	if req.Start != "" {
+		start, err := time.Parse(time.RFC3339, req.Start)
+		if err != nil {
+			return q, fmt.Errorf("invalid start: %s", req.Start)
+		}
+		q.Start = start
+	}
+
+	if req.Window != "" {
+		window, err := time.ParseDuration(req.Window)
+		if err != nil {
+			return q, fmt.Errorf("invalid window: %s", req.Window)
+		}
+		q.Window = window
+	}
+
+	if req.Aggregate != "" {
+		q.Aggregate = Aggregate(req.Aggregate)
+	}
+
+	return q, q.Validate()
+}
+
+// RegisterRoutes registers all metrics read routes on the given Gin router group.
+func RegisterRoutes(group *gin.RouterGroup, querier MetricQuerier, devices GroupDeviceLister) {
+	h := NewHandler(querier, devices)
+
+	metricsGroup := group.Group("/metrics")
+	{
+		// GET /api/v1/metrics/latest — most recent value per series
+		metricsGroup.GET("/latest", h.GetLatest)
+
+		// GET /api/v1/metrics/range  — values over time, optionally aggregated
+		metricsGroup.GET("/range", h.GetRange)
+
+		// GET /api/v1/metrics/query  — selected fields over time for dashboards
+		metricsGroup.GET("/query", h.Query)
+	}
+
+	// GET /api/v1/devices/:id/metrics — stored metrics of one device over time
+	group.GET("/devices/:id/metrics", h.GetDeviceMetrics)
+
+	// GET /api/v1/reports/top-interfaces — busiest interfaces network-wide
+	group.GET("/reports/top-interfaces", h.GetTopInterfaces)
+
+	// GET /api/v1/reports/slow-devices — devices with the longest poll durations
+	group.GET("/reports/slow-devices", h.GetSlowDevices)
+
+	// GET /api/v1/devices/:id/poll-stats — success rate and RTT rollup
+	group.GET("/devices/:id/poll-stats", h.GetPollStats)
+
+	// GET /api/v1/devices/:id/interfaces/:name/utilization — in/out bps from counters
+	group.GET("/devices/:id/interfaces/:name/utilization", h.GetInterfaceUtilization)
+
+	// GET /api/v1/groups/:id/metrics — up/down counts and averages for a device group
+	group.GET("/groups/:id/metrics", h.GetGroupMetrics)
+}
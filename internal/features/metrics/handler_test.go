@@ -0,0 +1,674 @@
+package metrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/features/metrics"
+)
+
+// fakeQuerier records the last query and returns canned series.
+type fakeQuerier struct {
+	series []metrics.Series
+	err    error
+
+	latest *metrics.LatestQuery
+	rng    *metrics.RangeQuery
+	ranges []metrics.RangeQuery
+}
+
+func (f *fakeQuerier) QueryLatest(ctx context.Context, q metrics.LatestQuery) ([]metrics.Series, error) {
+	f.latest = &q
+	return f.series, f.err
+}
+
+func (f *fakeQuerier) QueryRange(ctx context.Context, q metrics.RangeQuery) ([]metrics.Series, error) {
+	f.rng = &q
+	f.ranges = append(f.ranges, q)
+	return f.series, f.err
+}
+
+// fakeGroups maps group IDs to their devices.
+type fakeGroups map[string][]*model.Device
+
+func (f fakeGroups) GetByGroup(ctx context.Context, groupID string) ([]*model.Device, error) {
+	return f[groupID], nil
+}
+
+func setupRouter(q metrics.MetricQuerier) *gin.Engine {
+	return setupRouterWithGroups(q, fakeGroups{})
+}
+
+func setupRouterWithGroups(q metrics.MetricQuerier, groups metrics.GroupDeviceLister) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	metrics.RegisterRoutes(r.Group("/api/v1"), q, groups)
+	return r
+}
+
+func get(r *gin.Engine, url string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetLatest(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := &fakeQuerier{series: []metrics.Series{{
+		Measurement: "device_poll",
+		Field:       "rtt_ms",
+		Tags:        map[string]string{"device_id": "dev-1"},
+		Points:      []metrics.Point{{Time: ts, Value: 1.5}},
+	}}}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/metrics/latest?measurement=device_poll&field=rtt_ms&device_id=dev-1&lookback=10m")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp metrics.SeriesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Series, 1)
+	assert.Equal(t, 1.5, resp.Series[0].Points[0].Value)
+
+	require.NotNil(t, q.latest)
+	assert.Equal(t, "device_poll", q.latest.Measurement)
+	assert.Equal(t, "rtt_ms", q.latest.Field)
+	assert.Equal(t, map[string]string{"device_id": "dev-1"}, q.latest.Tags)
+	assert.Equal(t, 10*time.Minute, q.latest.Lookback)
+}
+
+func TestGetLatest_Validation(t *testing.T) {
+	r := setupRouter(&fakeQuerier{})
+
+	assert.Equal(t, http.StatusBadRequest, get(r, "/api/v1/metrics/latest").Code)
+	assert.Equal(t, http.StatusBadRequest, get(r, "/api/v1/metrics/latest?measurement=device_poll&lookback=soon").Code)
+}
+
+func TestGetRange(t *testing.T) {
+	q := &fakeQuerier{}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/metrics/range?measurement=interface_metrics&field=bytes_in&interface=ether1"+
+		"&start=2024-01-01T00:00:00Z&stop=2024-01-02T00:00:00Z&window=5m&aggregate=max")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, "interface_metrics", q.rng.Measurement)
+	assert.Equal(t, map[string]string{"interface": "ether1"}, q.rng.Tags)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), q.rng.Start)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), q.rng.Stop)
+	assert.Equal(t, 5*time.Minute, q.rng.Window)
+	assert.Equal(t, metrics.AggregateMax, q.rng.Aggregate)
+}
+
+func TestGetRange_Defaults(t *testing.T) {
+	q := &fakeQuerier{}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/metrics/range?measurement=device_poll")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, time.Hour, q.rng.Stop.Sub(q.rng.Start))
+	assert.Equal(t, metrics.AggregateMean, q.rng.Aggregate)
+	assert.Zero(t, q.rng.Window)
+}
+
+func TestGetRange_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"missing measurement", "/api/v1/metrics/range"},
+		{"bad start", "/api/v1/metrics/range?measurement=m&start=yesterday"},
+		{"stop before start", "/api/v1/metrics/range?measurement=m&start=2024-01-02T00:00:00Z&stop=2024-01-01T00:00:00Z"},
+		{"bad window", "/api/v1/metrics/range?measurement=m&window=often"},
+		{"bad aggregate", "/api/v1/metrics/range?measurement=m&window=5m&aggregate=median"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &fakeQuerier{}
+			w := get(setupRouter(q), tt.url)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Nil(t, q.rng, "querier must not be called for invalid input")
+		})
+	}
+}
+
+func TestGetRange_QuerierError(t *testing.T) {
+	r := setupRouter(&fakeQuerier{err: errors.New("backend down")})
+
+	w := get(r, "/api/v1/metrics/range?measurement=device_poll")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "backend down")
+}
+
+func TestQuery(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := &fakeQuerier{series: []metrics.Series{{
+		Measurement: "device_poll",
+		Field:       "rtt_ms",
+		Tags:        map[string]string{"device_id": "dev-1"},
+		Points:      []metrics.Point{{Time: ts, Value: 1.5}},
+	}}}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/metrics/query?measurement=device_poll&fields=rtt_ms,%20success&device_id=dev-1"+
+		"&start=2024-01-01T00:00:00Z&stop=2024-01-02T00:00:00Z&window=auto&aggregate=max")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, []string{"rtt_ms", "success"}, q.rng.Fields)
+	assert.Equal(t, map[string]string{"device_id": "dev-1"}, q.rng.Tags)
+	assert.Equal(t, 288*time.Second, q.rng.Window, "a day in about 300 windows")
+
+	var resp metrics.QueryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "4m48s", resp.Window)
+	assert.Equal(t, metrics.AggregateMax, resp.Aggregate)
+	require.Len(t, resp.Series, 1)
+	assert.Equal(t, 1.5, resp.Series[0].Points[0].Value)
+}
+
+func TestQuery_RelativeTimes(t *testing.T) {
+	q := &fakeQuerier{}
+	r := setupRouter(q)
+
+	before := time.Now()
+	w := get(r, "/api/v1/metrics/query?measurement=device_poll&start=-6h&stop=-1h")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, 5*time.Hour, q.rng.Stop.Sub(q.rng.Start))
+	assert.WithinDuration(t, before.Add(-time.Hour), q.rng.Stop, time.Minute)
+	assert.Zero(t, q.rng.Window, "raw points without a window")
+	assert.Contains(t, w.Body.String(), `"series":[]`)
+}
+
+func TestQuery_LongRawRangeIsDownsampled(t *testing.T) {
+	q := &fakeQuerier{}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/metrics/query?measurement=device_poll&start=2024-01-01T00:00:00Z&stop=2024-01-02T00:00:00Z")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, 288*time.Second, q.rng.Window, "a day of raw points is read with the auto window")
+
+	var resp metrics.QueryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "4m48s", resp.Window)
+}
+
+func TestQuery_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"missing measurement", "/api/v1/metrics/query"},
+		{"bad start", "/api/v1/metrics/query?measurement=m&start=yesterday"},
+		{"range too long", "/api/v1/metrics/query?measurement=m&start=-2400h"},
+		{"bad window", "/api/v1/metrics/query?measurement=m&window=often"},
+		{"sub-second window", "/api/v1/metrics/query?measurement=m&window=500ms"},
+		{"zero window", "/api/v1/metrics/query?measurement=m&window=0s"},
+		{"negative window", "/api/v1/metrics/query?measurement=m&window=-5m"},
+		{"bad aggregate", "/api/v1/metrics/query?measurement=m&window=auto&aggregate=median"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &fakeQuerier{}
+			w := get(setupRouter(q), tt.url)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Nil(t, q.rng, "querier must not be called for invalid input")
+		})
+	}
+}
+
+func TestGetDeviceMetrics(t *testing.T) {
+	q := &fakeQuerier{series: []metrics.Series{{Measurement: "m", Field: "f"}}}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/devices/dev-1/metrics?window=5m")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var measurements []string
+	for _, rq := range q.ranges {
+		measurements = append(measurements, rq.Measurement)
+		assert.Equal(t, map[string]string{"device_id": "dev-1"}, rq.Tags)
+		assert.Equal(t, 5*time.Minute, rq.Window)
+	}
+	assert.Equal(t, []string{"device_poll", "system_metrics", "interface_metrics"}, measurements)
+
+	var resp metrics.QueryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "dev-1", resp.DeviceID)
+	assert.Len(t, resp.Series, 3, "series of all measurements")
+
+	q.ranges = nil
+	w = get(r, "/api/v1/devices/dev-1/metrics?measurement=interface_metrics&interface=ether1&fields=bytes_in")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, q.ranges, 1)
+	assert.Equal(t, []string{"bytes_in"}, q.ranges[0].Fields)
+	assert.Equal(t, map[string]string{"device_id": "dev-1", "interface": "ether1"}, q.ranges[0].Tags)
+}
+
+func TestGetDeviceMetrics_QuerierError(t *testing.T) {
+	r := setupRouter(&fakeQuerier{err: errors.New("backend down")})
+
+	w := get(r, "/api/v1/devices/dev-1/metrics")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "backend down")
+}
+
+func TestCounterRates_HandlesReset(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rates := metrics.CounterRates([]metrics.Point{
+		{Time: t0, Value: 1000},
+		{Time: t0.Add(10 * time.Second), Value: 2000},
+		{Time: t0.Add(20 * time.Second), Value: 500}, // counter reset
+		{Time: t0.Add(30 * time.Second), Value: 1500},
+	})
+
+	require.Len(t, rates, 3)
+	assert.Equal(t, 800.0, rates[0].Value) // 1000 B in 10s
+	assert.Equal(t, 400.0, rates[1].Value) // 500 B counted since reset
+	assert.Equal(t, 800.0, rates[2].Value)
+	for _, p := range rates {
+		assert.GreaterOrEqual(t, p.Value, 0.0)
+	}
+
+	assert.Empty(t, metrics.CounterRates([]metrics.Point{{Time: t0, Value: 1}}))
+}
+
+func TestGetInterfaceUtilization(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tags := map[string]string{"device_id": "dev-1", "interface": "ether1"}
+	q := &fakeQuerier{series: []metrics.Series{
+		{
+			Measurement: "interface_metrics", Field: "bytes_in", Tags: tags,
+			Points: []metrics.Point{
+				{Time: t0, Value: 1000},
+				{Time: t0.Add(time.Minute), Value: 61000},
+				{Time: t0.Add(2 * time.Minute), Value: 6000}, // reset
+			},
+		},
+		{
+			Measurement: "interface_metrics", Field: "bytes_out", Tags: tags,
+			Points: []metrics.Point{
+				{Time: t0, Value: 0},
+				{Time: t0.Add(time.Minute), Value: 7500},
+			},
+		},
+	}}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/devices/dev-1/interfaces/ether1/utilization?range=6h")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, "interface_metrics", q.rng.Measurement)
+	assert.Equal(t, tags, q.rng.Tags)
+	assert.Equal(t, 6*time.Hour, q.rng.Stop.Sub(q.rng.Start))
+
+	var resp metrics.UtilizationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "dev-1", resp.DeviceID)
+	assert.Equal(t, "ether1", resp.Interface)
+	require.Len(t, resp.In, 2)
+	assert.Equal(t, 8000.0, resp.In[0].Value) // 60000 B in 60s
+	assert.Equal(t, 800.0, resp.In[1].Value)  // 6000 B since reset
+	require.Len(t, resp.Out, 1)
+	assert.Equal(t, 1000.0, resp.Out[0].Value)
+}
+
+func TestGetInterfaceUtilization_InvalidRange(t *testing.T) {
+	r := setupRouter(&fakeQuerier{})
+
+	for _, rng := range []string{"abc", "-1h", "720h"} {
+		w := get(r, "/api/v1/devices/dev-1/interfaces/ether1/utilization?range="+rng)
+		assert.Equal(t, http.StatusBadRequest, w.Code, rng)
+	}
+}
+
+func counterSeries(deviceID, iface string, t0 time.Time, values ...float64) metrics.Series {
+	s := metrics.Series{
+		Measurement: "interface_metrics",
+		Field:       "bytes_out",
+		Tags:        map[string]string{"device_id": deviceID, "interface": iface},
+	}
+	for i, v := range values {
+		s.Points = append(s.Points, metrics.Point{Time: t0.Add(time.Duration(i) * time.Minute), Value: v})
+	}
+	return s
+}
+
+func TestGetTopInterfaces(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := &fakeQuerier{series: []metrics.Series{
+		counterSeries("dev-1", "ether1", t0, 0, 60000, 120000),       // 8000 bps
+		counterSeries("dev-2", "sfp1", t0, 0, 600000, 1200000),       // 80000 bps
+		counterSeries("dev-1", "ether2", t0, 0, 6000, 12000),         // 800 bps
+		counterSeries("dev-3", "ether1", t0, 500000, 100000, 400000), // reset: 400000 B / 120s
+		counterSeries("dev-4", "ether1", t0, 100),                    // single sample, skipped
+	}}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/reports/top-interfaces?metric=bytes_out&limit=3&range=2h")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, "interface_metrics", q.rng.Measurement)
+	assert.Equal(t, "bytes_out", q.rng.Field)
+	assert.Equal(t, 2*time.Hour, q.rng.Stop.Sub(q.rng.Start))
+	assert.Equal(t, metrics.AggregateLast, q.rng.Aggregate)
+
+	var resp metrics.TopInterfacesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "bytes_out", resp.Metric)
+	require.Len(t, resp.Data, 3)
+	assert.Equal(t, metrics.InterfaceUsage{DeviceID: "dev-2", Interface: "sfp1", Value: 80000}, resp.Data[0])
+	assert.Equal(t, "dev-3", resp.Data[1].DeviceID)
+	assert.InDelta(t, 400000*8/120.0, resp.Data[1].Value, 0.001)
+	assert.Equal(t, metrics.InterfaceUsage{DeviceID: "dev-1", Interface: "ether1", Value: 8000}, resp.Data[2])
+}
+
+func TestGetTopInterfaces_Defaults(t *testing.T) {
+	q := &fakeQuerier{}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/reports/top-interfaces")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "bytes_out", q.rng.Field)
+	assert.Equal(t, time.Hour, q.rng.Stop.Sub(q.rng.Start))
+	assert.JSONEq(t, `[]`, string(mustField(t, w.Body.Bytes(), "data")))
+}
+
+func TestGetTopInterfaces_InvalidParams(t *testing.T) {
+	r := setupRouter(&fakeQuerier{})
+
+	for _, query := range []string{"metric=cpu", "limit=0x", "limit=500", "range=forever"} {
+		w := get(r, "/api/v1/reports/top-interfaces?"+query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func mustField(t *testing.T, body []byte, key string) json.RawMessage {
+	t.Helper()
+	var m map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &m))
+	return m[key]
+}
+
+func pollSeries(field string, t0 time.Time, values ...float64) metrics.Series {
+	s := metrics.Series{
+		Measurement: "device_poll",
+		Field:       field,
+		Tags:        map[string]string{"device_id": "dev-1"},
+	}
+	for i, v := range values {
+		s.Points = append(s.Points, metrics.Point{Time: t0.Add(time.Duration(i) * 5 * time.Minute), Value: v})
+	}
+	return s
+}
+
+func TestGetPollStats(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := &fakeQuerier{series: []metrics.Series{
+		pollSeries("success", t0, 1, 1, 0, 1, 1),
+		pollSeries("rtt_ms", t0, 10, 20, 0, 30, 40),
+		pollSeries("poll_duration_ms", t0, 100, 100, 1000, 100, 200),
+	}}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/devices/dev-1/poll-stats?range=12h")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, q.rng)
+	assert.Equal(t, "device_poll", q.rng.Measurement)
+	assert.Equal(t, map[string]string{"device_id": "dev-1"}, q.rng.Tags)
+	assert.Equal(t, 12*time.Hour, q.rng.Stop.Sub(q.rng.Start))
+
+	var stats metrics.PollStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, "dev-1", stats.DeviceID)
+	assert.Equal(t, 5, stats.PollCount)
+	assert.Equal(t, 4, stats.SuccessCount)
+	assert.InDelta(t, 0.8, stats.SuccessRate, 1e-9)
+
+	// The failed poll's rtt of 0 is excluded
+	require.NotNil(t, stats.AvgRTTMs)
+	assert.Equal(t, 25.0, *stats.AvgRTTMs)
+	require.NotNil(t, stats.P95RTTMs)
+	assert.Equal(t, 40.0, *stats.P95RTTMs)
+	require.NotNil(t, stats.AvgPollDurationMs)
+	assert.Equal(t, 300.0, *stats.AvgPollDurationMs)
+}
+
+func TestGetPollStats_NoData(t *testing.T) {
+	q := &fakeQuerier{}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/devices/dev-1/poll-stats")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 24*time.Hour, q.rng.Stop.Sub(q.rng.Start))
+
+	var stats metrics.PollStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 0, stats.PollCount)
+	assert.Zero(t, stats.SuccessRate)
+	assert.Nil(t, stats.AvgRTTMs)
+	assert.Nil(t, stats.P95RTTMs)
+}
+
+func TestGetPollStats_Errors(t *testing.T) {
+	r := setupRouter(&fakeQuerier{})
+	w := get(r, "/api/v1/devices/dev-1/poll-stats?range=1000h")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	r = setupRouter(&fakeQuerier{err: errors.New("influx down")})
+	w = get(r, "/api/v1/devices/dev-1/poll-stats")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// measurementQuerier returns canned latest series per measurement.
+type measurementQuerier struct {
+	latest  map[string][]metrics.Series
+	queries []metrics.LatestQuery
+}
+
+func (f *measurementQuerier) QueryLatest(ctx context.Context, q metrics.LatestQuery) ([]metrics.Series, error) {
+	f.queries = append(f.queries, q)
+	return f.latest[q.Measurement], nil
+}
+
+func (f *measurementQuerier) QueryRange(ctx context.Context, q metrics.RangeQuery) ([]metrics.Series, error) {
+	return nil, nil
+}
+
+func latestSeries(measurement, field, deviceID string, ts time.Time, value float64) metrics.Series {
+	return metrics.Series{
+		Measurement: measurement,
+		Field:       field,
+		Tags:        map[string]string{"device_id": deviceID},
+		Points:      []metrics.Point{{Time: ts, Value: value}},
+	}
+}
+
+func TestGetGroupMetrics_RollsUpDevices(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := &measurementQuerier{latest: map[string][]metrics.Series{
+		"device_poll": {
+			latestSeries("device_poll", "success", "dev-1", ts, 1),
+			latestSeries("device_poll", "rtt_ms", "dev-1", ts, 10),
+			latestSeries("device_poll", "success", "dev-2", ts, 1),
+			latestSeries("device_poll", "rtt_ms", "dev-2", ts, 30),
+			latestSeries("device_poll", "success", "dev-3", ts, 0),
+			latestSeries("device_poll", "rtt_ms", "dev-3", ts, 0),
+			latestSeries("device_poll", "success", "other", ts, 0), // not in the group
+		},
+		"system_metrics": {
+			latestSeries("system_metrics", "cpu_usage", "dev-1", ts, 20),
+			latestSeries("system_metrics", "cpu_usage", "dev-2", ts, 60),
+			latestSeries("system_metrics", "memory_usage", "dev-2", ts, 50),
+			latestSeries("system_metrics", "cpu_usage", "other", ts, 100),
+		},
+	}}
+	groups := fakeGroups{"core": {
+		{ID: "dev-2", Name: "router-b"},
+		{ID: "dev-1", Name: "router-a"},
+		{ID: "dev-3", Name: "router-c"},
+		{ID: "dev-4", Name: "router-d"}, // never polled
+	}}
+	r := setupRouterWithGroups(q, groups)
+
+	w := get(r, "/api/v1/groups/core/metrics?lookback=15m")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp metrics.GroupMetricsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, "core", resp.GroupID)
+	assert.Equal(t, 4, resp.DeviceCount)
+	assert.Equal(t, 2, resp.UpCount)
+	assert.Equal(t, 1, resp.DownCount)
+	assert.Equal(t, 1, resp.NoDataCount)
+
+	require.NotNil(t, resp.AvgRTTMs)
+	assert.InDelta(t, 20, *resp.AvgRTTMs, 0.001, "only devices that are up count towards RTT")
+	require.NotNil(t, resp.AvgCPUUsage)
+	assert.InDelta(t, 40, *resp.AvgCPUUsage, 0.001)
+	require.NotNil(t, resp.AvgMemoryUsage)
+	assert.InDelta(t, 50, *resp.AvgMemoryUsage, 0.001)
+
+	require.Len(t, resp.Devices, 4)
+	assert.Equal(t, "dev-1", resp.Devices[0].DeviceID)
+	assert.Equal(t, metrics.GroupDeviceUp, resp.Devices[0].State)
+	assert.Equal(t, metrics.GroupDeviceDown, resp.Devices[2].State)
+	assert.Nil(t, resp.Devices[2].RTTMs)
+	assert.Equal(t, metrics.GroupDeviceNoData, resp.Devices[3].State)
+	assert.Nil(t, resp.Devices[3].CPUUsage)
+
+	require.Len(t, q.queries, 2)
+	assert.Equal(t, 15*time.Minute, q.queries[0].Lookback)
+}
+
+func TestGetGroupMetrics_EmptyGroup(t *testing.T) {
+	q := &measurementQuerier{}
+	r := setupRouterWithGroups(q, fakeGroups{})
+
+	w := get(r, "/api/v1/groups/empty/metrics")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp metrics.GroupMetricsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 0, resp.DeviceCount)
+	assert.Nil(t, resp.AvgCPUUsage)
+	assert.NotNil(t, resp.Devices)
+	assert.Empty(t, resp.Devices)
+	assert.Empty(t, q.queries, "no metrics are queried for an empty group")
+}
+
+func TestGetGroupMetrics_InvalidLookback(t *testing.T) {
+	r := setupRouterWithGroups(&measurementQuerier{}, fakeGroups{})
+
+	w := get(r, "/api/v1/groups/core/metrics?lookback=-5m")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// aggregateQuerier returns canned range series per aggregate.
+type aggregateQuerier struct {
+	series  map[metrics.Aggregate][]metrics.Series
+	queries []metrics.RangeQuery
+}
+
+func (f *aggregateQuerier) QueryLatest(ctx context.Context, q metrics.LatestQuery) ([]metrics.Series, error) {
+	return nil, nil
+}
+
+func (f *aggregateQuerier) QueryRange(ctx context.Context, q metrics.RangeQuery) ([]metrics.Series, error) {
+	f.queries = append(f.queries, q)
+	return f.series[q.Aggregate], nil
+}
+
+func durationSeries(deviceID, ip string, t0 time.Time, values ...float64) metrics.Series {
+	s := metrics.Series{
+		Measurement: "device_poll",
+		Field:       "poll_duration_ms",
+		Tags:        map[string]string{"device_id": deviceID, "ip_address": ip},
+	}
+	for i, v := range values {
+		s.Points = append(s.Points, metrics.Point{Time: t0.Add(time.Duration(i) * time.Minute), Value: v})
+	}
+	return s
+}
+
+func TestGetSlowDevices(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := &aggregateQuerier{series: map[metrics.Aggregate][]metrics.Series{
+		metrics.AggregateMean: {
+			durationSeries("dev-1", "10.0.0.1", t0, 100, 200),
+			durationSeries("dev-2", "10.0.0.2", t0, 5000, 7000),
+			durationSeries("dev-3", "10.0.0.3", t0, 50),
+			// dev-1 changed IP within the range; its series are merged
+			durationSeries("dev-1", "10.0.0.11", t0.Add(2*time.Minute), 300),
+		},
+		metrics.AggregateMax: {
+			durationSeries("dev-1", "10.0.0.1", t0, 150, 400),
+			durationSeries("dev-2", "10.0.0.2", t0, 9000, 7500),
+			durationSeries("dev-1", "10.0.0.11", t0.Add(2*time.Minute), 350),
+		},
+	}}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/reports/slow-devices?range=6h&limit=2")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, q.queries, 2)
+	assert.Equal(t, "device_poll", q.queries[0].Measurement)
+	assert.Equal(t, "poll_duration_ms", q.queries[0].Field)
+	assert.Equal(t, 6*time.Hour, q.queries[0].Stop.Sub(q.queries[0].Start))
+	assert.Equal(t, metrics.AggregateMean, q.queries[0].Aggregate)
+	assert.Equal(t, metrics.AggregateMax, q.queries[1].Aggregate)
+
+	var resp metrics.SlowDevicesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []metrics.SlowDevice{
+		{DeviceID: "dev-2", IPAddress: "10.0.0.2", AvgPollDurationMs: 6000, MaxPollDurationMs: 9000},
+		{DeviceID: "dev-1", IPAddress: "10.0.0.11", AvgPollDurationMs: 200, MaxPollDurationMs: 400},
+	}, resp.Data)
+}
+
+func TestGetSlowDevices_Defaults(t *testing.T) {
+	q := &aggregateQuerier{}
+	r := setupRouter(q)
+
+	w := get(r, "/api/v1/reports/slow-devices")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, q.queries)
+	assert.Equal(t, time.Hour, q.queries[0].Stop.Sub(q.queries[0].Start))
+	assert.JSONEq(t, `[]`, string(mustField(t, w.Body.Bytes(), "data")))
+}
+
+func TestGetSlowDevices_Errors(t *testing.T) {
+	r := setupRouter(&fakeQuerier{})
+	for _, query := range []string{"limit=0x", "limit=500", "range=forever", "range=-1h"} {
+		w := get(r, "/api/v1/reports/slow-devices?"+query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	r = setupRouter(&fakeQuerier{err: errors.New("influx down")})
+	w := get(r, "/api/v1/reports/slow-devices")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
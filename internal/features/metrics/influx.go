@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+)
+
+const defaultLookback = time.Hour
+
+// InfluxQuerier implements MetricQuerier on top of the InfluxDB Flux query API.
+type InfluxQuerier struct {
+	queryAPI api.QueryAPI
+	bucket   string
+}
+
+// NewInfluxQuerier creates an InfluxQuerier reading from the given bucket.
+func NewInfluxQuerier(client influxdb2.Client, org, bucket string) *InfluxQuerier {
+	return &InfluxQuerier{
+		queryAPI: client.QueryAPI(org),
+		bucket:   bucket,
+	}
+}
+
+func (q *InfluxQuerier) QueryLatest(ctx context.Context, lq LatestQuery) ([]Series, error) {
+	if lq.Measurement == "" {
+		return nil, fmt.Errorf("measurement is required")
+	}
+
+	lookback := lq.Lookback
+	if lookback <= 0 {
+		lookback = defaultLookback
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucket: %s)\n", fluxString(q.bucket))
+	fmt.Fprintf(&b, "  |> range(start: -%ds)\n", int64(lookback.Seconds()))
+	writeFilters(&b, lq.Measurement, lq.Field, lq.Tags)
+	b.WriteString("  |> last()\n")
+
+	return q.run(ctx, b.String())
+}
+
+func (q *InfluxQuerier) QueryRange(ctx context.Context, rq RangeQuery) ([]Series, error) {
+	if err := rq.Validate(); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucket: %s)\n", fluxString(q.bucket))
+	fmt.Fprintf(&b, "  |> range(start: %s, stop: %s)\n",
+		rq.Start.UTC().Format(time.RFC3339Nano), rq.Stop.UTC().Format(time.RFC3339Nano))
+	writeFilters(&b, rq.Measurement, rq.Field, rq.Tags)
+	writeFieldsFilter(&b, rq.Fields)
+	if rq.Window > 0 {
+		fmt.Fprintf(&b, "  |> aggregateWindow(every: %ds, fn: %s, createEmpty: false)\n",
+			int64(rq.Window.Seconds()), rq.Aggregate)
+	}
+
+	return q.run(ctx, b.String())
+}
+
+// run executes a Flux query and groups the returned records into series.
+func (q *InfluxQuerier) run(ctx context.Context, flux string) ([]Series, error) {
+	result, err := q.queryAPI.Query(ctx, flux)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query influxdb: %w", err)
+	}
+	defer result.Close()
+
+	var series []Series
+	index := make(map[string]int)
+
+	for result.Next() {
+		record := result.Record()
+		tags := recordTags(record)
+		key := seriesKey(record.Measurement(), record.Field(), tags)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(series)
+			index[key] = i
+			series = append(series, Series{
+				Measurement: record.Measurement(),
+				Field:       record.Field(),
+				Tags:        tags,
+			})
+		}
+
+		value, ok := toFloat(record.Value())
+		if !ok {
+			continue
+		}
+		series[i].Points = append(series[i].Points, Point{Time: record.Time(), Value: value})
+	}
+
+	if result.Err() != nil {
+		return nil, fmt.Errorf("failed to read influxdb result: %w", result.Err())
+	}
+
+	return series, nil
+}
+
+func writeFilters(b *strings.Builder, measurement, field string, tags map[string]string) {
+	fmt.Fprintf(b, "  |> filter(fn: (r) => r._measurement == %s)\n", fluxString(measurement))
+	if field != "" {
+		fmt.Fprintf(b, "  |> filter(fn: (r) => r._field == %s)\n", fluxString(field))
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(b, "  |> filter(fn: (r) => r[%s] == %s)\n", fluxString(k), fluxString(tags[k]))
+	}
+}
+
+// writeFieldsFilter keeps records of any of fields; it writes nothing when
+// fields is empty.
+func writeFieldsFilter(b *strings.Builder, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	conds := make([]string, len(fields))
+	for i, f := range fields {
+		conds[i] = "r._field == " + fluxString(f)
+	}
+	fmt.Fprintf(b, "  |> filter(fn: (r) => %s)\n", strings.Join(conds, " or "))
+}
+
+// fluxString quotes s as a Flux string literal, escaping interpolation.
+func fluxString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// recordTags returns the tag columns of a record, skipping Flux system columns.
+func recordTags(record *query.FluxRecord) map[string]string {
+	tags := make(map[string]string)
+	for k, v := range record.Values() {
+		if strings.HasPrefix(k, "_") || k == "result" || k == "table" {
+			continue
+		}
+		if s, ok := v.(string); ok {
+			tags[k] = s
+		}
+	}
+	return tags
+}
+
+func seriesKey(measurement, field string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(measurement + "/" + field)
+	for _, k := range keys {
+		b.WriteString("," + k + "=" + tags[k])
+	}
+	return b.String()
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case int64:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
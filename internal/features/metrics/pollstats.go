@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Poll result fields written by the worker.
+const (
+	pollMeasurement       = "device_poll"
+	fieldRTT              = "rtt_ms"
+	fieldSuccess          = "success"
+	fieldPollDuration     = "poll_duration_ms"
+	defaultPollStatsRange = 24 * time.Hour
+	maxPollStatsRange     = 30 * 24 * time.Hour
+)
+
+// PollStats summarises a device's poll results over a range. RTT statistics
+// only consider successful polls and are null when there were none.
+type PollStats struct {
+	DeviceID          string    `json:"device_id"`
+	Start             time.Time `json:"start"`
+	Stop              time.Time `json:"stop"`
+	PollCount         int       `json:"poll_count"`
+	SuccessCount      int       `json:"success_count"`
+	SuccessRate       float64   `json:"success_rate"` // 0..1
+	AvgRTTMs          *float64  `json:"avg_rtt_ms"`
+	P95RTTMs          *float64  `json:"p95_rtt_ms"`
+	AvgPollDurationMs *float64  `json:"avg_poll_duration_ms"`
+}
+
+// computePollStats aggregates device_poll series. Fields of one poll share a
+// timestamp, which is used to exclude the RTT of failed polls.
+func computePollStats(series []Series) PollStats {
+	var stats PollStats
+	succeeded := make(map[time.Time]bool)
+	var rtts, durations []float64
+
+	for _, s := range series {
+		if s.Field != fieldSuccess {
+			continue
+		}
+		for _, p := range s.Points {
+			stats.PollCount++
+			if p.Value != 0 {
+				stats.SuccessCount++
+				succeeded[p.Time] = true
+			}
+		}
+	}
+
+	for _, s := range series {
+		switch s.Field {
+		case fieldRTT:
+			for _, p := range s.Points {
+				if succeeded[p.Time] {
+					rtts = append(rtts, p.Value)
+				}
+			}
+		case fieldPollDuration:
+			for _, p := range s.Points {
+				durations = append(durations, p.Value)
+			}
+		}
+	}
+
+	if stats.PollCount > 0 {
+		stats.SuccessRate = float64(stats.SuccessCount) / float64(stats.PollCount)
+	}
+	stats.AvgRTTMs = mean(rtts)
+	stats.P95RTTMs = percentile(rtts, 95)
+	stats.AvgPollDurationMs = mean(durations)
+	return stats
+}
+
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	m := sum / float64(len(values))
+	return &m
+}
+
+// percentile returns the p-th percentile using the nearest-rank method.
+func percentile(values []float64, p float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	v := sorted[rank-1]
+	return &v
+}
@@ -0,0 +1,92 @@
+// Package metrics provides the read side of the metrics pipeline.
+// Handlers depend on the backend-agnostic MetricQuerier interface so that
+// backends other than InfluxDB can be plugged in without touching the API.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Aggregate is the function used to downsample a range query into windows.
+type Aggregate string
+
+const (
+	AggregateMean  Aggregate = "mean"
+	AggregateMin   Aggregate = "min"
+	AggregateMax   Aggregate = "max"
+	AggregateSum   Aggregate = "sum"
+	AggregateCount Aggregate = "count"
+	AggregateLast  Aggregate = "last"
+)
+
+// Valid reports whether a is a supported aggregate.
+func (a Aggregate) Valid() bool {
+	switch a {
+	case AggregateMean, AggregateMin, AggregateMax, AggregateSum, AggregateCount, AggregateLast:
+		return true
+	}
+	return false
+}
+
+// Point is a single timestamped value.
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Series is a list of points sharing the same measurement, field and tag set.
+type Series struct {
+	Measurement string            `json:"measurement"`
+	Field       string            `json:"field"`
+	Tags        map[string]string `json:"tags"`
+	Points      []Point           `json:"points"`
+}
+
+// LatestQuery selects the most recent value of a field for every matching series.
+type LatestQuery struct {
+	Measurement string
+	Field       string            // optional; all fields when empty
+	Tags        map[string]string // exact-match tag filters
+	Lookback    time.Duration     // how far back to search for the latest value
+}
+
+// RangeQuery selects the values of a field between Start and Stop.
+// When Window is set, values are downsampled with Aggregate per window.
+type RangeQuery struct {
+	Measurement string
+	Field       string
+	Fields      []string // several fields at once; mutually exclusive with Field
+	Tags        map[string]string
+	Start       time.Time
+	Stop        time.Time
+	Window      time.Duration
+	Aggregate   Aggregate
+}
+
+// Validate checks the query for missing or inconsistent parameters.
+func (q RangeQuery) Validate() error {
+	if q.Measurement == "" {
+		return fmt.Errorf("measurement is required")
+	}
+	if !q.Stop.After(q.Start) {
+		return fmt.Errorf("stop must be after start")
+	}
+	if q.Field != "" && len(q.Fields) > 0 {
+		return fmt.Errorf("field and fields are mutually exclusive")
+	}
+	if q.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+	if q.Window > 0 && !q.Aggregate.Valid() {
+		return fmt.Errorf("unsupported aggregate %q", q.Aggregate)
+	}
+	return nil
+}
+
+// MetricQuerier reads metrics back from a time-series backend.
+type MetricQuerier interface {
+	QueryLatest(ctx context.Context, q LatestQuery) ([]Series, error)
+	QueryRange(ctx context.Context, q RangeQuery) ([]Series, error)
+}
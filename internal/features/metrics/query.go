@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+const (
+	// maxQueryRange bounds the period a single dashboard query may span.
+	maxQueryRange = 90 * 24 * time.Hour
+
+	// autoWindowPoints is roughly how many points per series window=auto
+	// returns, enough for a chart without scanning every raw sample.
+	autoWindowPoints = 300
+
+	// minQueryWindow is the smallest downsampling window; Flux windows are
+	// whole seconds.
+	minQueryWindow = time.Second
+
+	// maxRawRange is the longest range returned as raw points. Longer ranges
+	// are downsampled as with window=auto, since raw points of every matching
+	// series over days would be loaded into memory at once.
+	maxRawRange = 6 * time.Hour
+
+	windowAuto = "auto"
+)
+
+// deviceMeasurements are the measurements GET /devices/:id/metrics reads
+// when no measurement is given.
+var deviceMeasurements = []string{pollMeasurement, systemMeasurement, interfaceMeasurement}
+
+// QueryRequest holds the query parameters for GET /api/v1/metrics/query.
+type QueryRequest struct {
+	Measurement string `form:"measurement" binding:"required"`
+
+	// Fields is a comma-separated list of fields, e.g. "rtt_ms,success".
+	// All fields are returned when empty.
+	Fields string `form:"fields"`
+
+	DeviceID  string `form:"device_id"`
+	Interface string `form:"interface"`
+
+	// Start and Stop are RFC3339 timestamps or negative Go durations relative
+	// to now, e.g. "-6h" (default: the last hour).
+	Start string `form:"start"`
+	Stop  string `form:"stop"`
+
+	// Window is the downsampling window as a Go duration of at least 1s, or
+	// "auto" to pick one from the time range. Raw points are returned when
+	// empty and the range is at most maxRawRange.
+	Window string `form:"window"`
+
+	// Aggregate is the downsampling function (default: "mean").
+	Aggregate string `form:"aggregate"`
+}
+
+// DeviceMetricsRequest holds the query parameters for GET /api/v1/devices/:id/metrics.
+type DeviceMetricsRequest struct {
+	// Measurement restricts the result to one measurement; device_poll,
+	// system_metrics and interface_metrics are read when empty.
+	Measurement string `form:"measurement"`
+	Fields      string `form:"fields"`
+	Interface   string `form:"interface"`
+	Start       string `form:"start"`
+	Stop        string `form:"stop"`
+	Window      string `form:"window"`
+	Aggregate   string `form:"aggregate"`
+}
+
+// QueryResponse is the response body for the metrics query endpoints. Window
+// is empty when raw points are returned.
+type QueryResponse struct {
+	DeviceID  string    `json:"device_id,omitempty"`
+	Start     time.Time `json:"start"`
+	Stop      time.Time `json:"stop"`
+	Window    string    `json:"window,omitempty"`
+	Aggregate Aggregate `json:"aggregate,omitempty"`
+	Series    []Series  `json:"series"`
+}
+
+// Query handles GET /api/v1/metrics/query
+//
+// Returns the selected fields of every matching series over a time range,
+// optionally downsampled, for dashboards.
+func (h *Handler) Query(c *gin.Context) {
+	var req QueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	q, err := buildQuery(time.Now(), req.Measurement, req.Fields, tagFilters(req.DeviceID, req.Interface),
+		req.Start, req.Stop, req.Window, req.Aggregate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := h.querier.QueryRange(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newQueryResponse("", q, series))
+}
+
+// GetDeviceMetrics handles GET /api/v1/devices/:id/metrics
+//
+// Returns the stored metrics of one device over a time range, from one
+// measurement or all of a device's measurements.
+func (h *Handler) GetDeviceMetrics(c *gin.Context) {
+	var req DeviceMetricsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid query", err))
+		return
+	}
+
+	measurements := deviceMeasurements
+	if req.Measurement != "" {
+		measurements = []string{req.Measurement}
+	}
+
+	deviceID := c.Param("id")
+	q, err := buildQuery(time.Now(), measurements[0], req.Fields, tagFilters(deviceID, req.Interface),
+		req.Start, req.Stop, req.Window, req.Aggregate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series := []Series{}
+	for _, m := range measurements {
+		q.Measurement = m
+		s, err := h.querier.QueryRange(c.Request.Context(), q)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		series = append(series, s...)
+	}
+
+	c.JSON(http.StatusOK, newQueryResponse(deviceID, q, series))
+}
+
+// buildQuery parses the query parameters shared by the query endpoints into
+// a validated RangeQuery. A raw query over more than maxRawRange gets the
+// auto window.
+func buildQuery(now time.Time, measurement, fields string, tags map[string]string, start, stop, window, aggregate string) (RangeQuery, error) {
+	q := RangeQuery{
+		Measurement: measurement,
+		Fields:      splitFields(fields),
+		Tags:        tags,
+		Stop:        now,
+		Aggregate:   AggregateMean,
+	}
+
+	if stop != "" {
+		t, err := parseQueryTime(stop, now)
+		if err != nil {
+			return q, fmt.Errorf("invalid stop: %s", stop)
+		}
+		q.Stop = t
+	}
+
+	q.Start = q.Stop.Add(-defaultRange)
+	if start != "" {
+		t, err := parseQueryTime(start, now)
+		if err != nil {
+			return q, fmt.Errorf("invalid start: %s", start)
+		}
+		q.Start = t
+	}
+
+	if q.Stop.Sub(q.Start) > maxQueryRange {
+		return q, fmt.Errorf("time range exceeds %s", maxQueryRange)
+	}
+
+	switch window {
+	case "":
+	case windowAuto:
+		q.Window = autoWindow(q.Stop.Sub(q.Start))
+	default:
+		w, err := time.ParseDuration(window)
+		if err != nil {
+			return q, fmt.Errorf("invalid window: %s", window)
+		}
+		if w < minQueryWindow {
+			return q, fmt.Errorf("window must be at least %s", minQueryWindow)
+		}
+		q.Window = w
+	}
+	if q.Window == 0 && q.Stop.Sub(q.Start) > maxRawRange {
+		q.Window = autoWindow(q.Stop.Sub(q.Start))
+	}
+
+	if aggregate != "" {
+		q.Aggregate = Aggregate(aggregate)
+	}
+
+	return q, q.Validate()
+}
+
+// parseQueryTime parses an RFC3339 timestamp or a negative duration relative to now.
+func parseQueryTime(s string, now time.Time) (time.Time, error) {
+	if strings.HasPrefix(s, "-") {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// autoWindow divides rng into about autoWindowPoints whole-second windows.
+func autoWindow(rng time.Duration) time.Duration {
+	w := (rng / autoWindowPoints).Truncate(time.Second)
+	if w < time.Second {
+		return time.Second
+	}
+	return w
+}
+
+func splitFields(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func newQueryResponse(deviceID string, q RangeQuery, series []Series) QueryResponse {
+	resp := QueryResponse{
+		DeviceID: deviceID,
+		Start:    q.Start,
+		Stop:     q.Stop,
+		Series:   series,
+	}
+	if resp.Series == nil {
+		resp.Series = []Series{}
+	}
+	if q.Window > 0 {
+		resp.Window = q.Window.String()
+		resp.Aggregate = q.Aggregate
+	}
+	return resp
+}
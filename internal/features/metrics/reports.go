@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	defaultTopLimit = 10
+
+	// topWindows is the number of windows a top-interfaces range is downsampled
+	// into; the last counter value per window is enough to compute the average rate.
+	topWindows = 60
+)
+
+// InterfaceUsage is one entry of the top interfaces report.
+type InterfaceUsage struct {
+	DeviceID  string  `json:"device_id"`
+	Interface string  `json:"interface"`
+	Value     float64 `json:"value"` // average bits per second over the range
+}
+
+// TopInterfacesResponse is the response body for the top interfaces report.
+type TopInterfacesResponse struct {
+	Metric string           `json:"metric"`
+	Start  time.Time        `json:"start"`
+	Stop   time.Time        `json:"stop"`
+	Data   []InterfaceUsage `json:"data"`
+}
+
+// AverageRate returns the average bits per second across cumulative byte
+// counter samples, treating a decreasing counter as a reset like CounterRates.
+func AverageRate(samples []Point) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+
+	sorted := sortedByTime(samples)
+	var bytes float64
+	for i := 1; i < len(sorted); i++ {
+		bytes += counterDelta(sorted[i-1].Value, sorted[i].Value)
+	}
+
+	seconds := sorted[len(sorted)-1].Time.Sub(sorted[0].Time).Seconds()
+	if seconds <= 0 {
+		return 0, false
+	}
+	return bytes * 8 / seconds, true
+}
+
+// rankInterfaces computes the average rate of each interface series and
+// returns the busiest first, at most limit entries.
+func rankInterfaces(series []Series, limit int) []InterfaceUsage {
+	usage := make([]InterfaceUsage, 0, len(series))
+	for _, s := range series {
+		rate, ok := AverageRate(s.Points)
+		if !ok {
+			continue
+		}
+		usage = append(usage, InterfaceUsage{
+			DeviceID:  s.Tags["device_id"],
+			Interface: s.Tags["interface"],
+			Value:     rate,
+		})
+	}
+
+	sort.SliceStable(usage, func(i, j int) bool { return usage[i].Value > usage[j].Value })
+	if len(usage) > limit {
+		usage = usage[:limit]
+	}
+	return usage
+}
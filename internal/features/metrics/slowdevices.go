@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// SlowDevice is one entry of the slow devices report.
+type SlowDevice struct {
+	DeviceID          string  `json:"device_id"`
+	IPAddress         string  `json:"ip_address"`
+	AvgPollDurationMs float64 `json:"avg_poll_duration_ms"`
+	MaxPollDurationMs float64 `json:"max_poll_duration_ms"`
+}
+
+// SlowDevicesResponse is the response body for the slow devices report.
+type SlowDevicesResponse struct {
+	Start time.Time    `json:"start"`
+	Stop  time.Time    `json:"stop"`
+	Data  []SlowDevice `json:"data"`
+}
+
+// rankSlowDevices averages the poll_duration_ms windows of each device and
+// returns the slowest first, at most limit entries. peaks holds the same
+// windows aggregated with max; series of one device are merged, as the IP
+// tag may have changed within the range.
+func rankSlowDevices(means, peaks []Series, limit int) []SlowDevice {
+	type acc struct {
+		device SlowDevice
+		sum    float64
+		count  int
+		seen   time.Time
+	}
+	byID := make(map[string]*acc)
+	get := func(s Series) *acc {
+		id := s.Tags["device_id"]
+		a, ok := byID[id]
+		if !ok {
+			a = &acc{device: SlowDevice{DeviceID: id}}
+			byID[id] = a
+		}
+		return a
+	}
+
+	for _, s := range means {
+		if s.Tags["device_id"] == "" || len(s.Points) == 0 {
+			continue
+		}
+		a := get(s)
+		for _, p := range s.Points {
+			a.sum += p.Value
+			a.count++
+			if !p.Time.Before(a.seen) {
+				a.seen = p.Time
+				a.device.IPAddress = s.Tags["ip_address"]
+			}
+		}
+	}
+	for _, s := range peaks {
+		a, ok := byID[s.Tags["device_id"]]
+		if !ok {
+			continue
+		}
+		for _, p := range s.Points {
+			if p.Value > a.device.MaxPollDurationMs {
+				a.device.MaxPollDurationMs = p.Value
+			}
+		}
+	}
+
+	devices := make([]SlowDevice, 0, len(byID))
+	for _, a := range byID {
+		a.device.AvgPollDurationMs = a.sum / float64(a.count)
+		devices = append(devices, a.device)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].AvgPollDurationMs != devices[j].AvgPollDurationMs {
+			return devices[i].AvgPollDurationMs > devices[j].AvgPollDurationMs
+		}
+		return devices[i].DeviceID < devices[j].DeviceID
+	})
+	if len(devices) > limit {
+		devices = devices[:limit]
+	}
+	return devices
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// Interface counter fields written by the monitoring pipeline.
+const (
+	interfaceMeasurement = "interface_metrics"
+	fieldBytesIn         = "bytes_in"
+	fieldBytesOut        = "bytes_out"
+)
+
+// maxUtilizationRange bounds the raw counter range a single request may scan.
+const maxUtilizationRange = 7 * 24 * time.Hour
+
+// UtilizationResponse is the response body for the interface utilization endpoint.
+// In and Out are rates in bits per second, one point per counter sample after the first.
+type UtilizationResponse struct {
+	DeviceID  string    `json:"device_id"`
+	Interface string    `json:"interface"`
+	Start     time.Time `json:"start"`
+	Stop      time.Time `json:"stop"`
+	In        []Point   `json:"in_bps"`
+	Out       []Point   `json:"out_bps"`
+}
+
+// CounterRates converts cumulative byte counter samples into bits per second.
+// A counter that decreases is treated as reset (device reboot or counter wrap):
+// the new value is taken as the bytes counted since the reset, so no negative
+// or spurious spike is produced.
+func CounterRates(samples []Point) []Point {
+	if len(samples) < 2 {
+		return []Point{}
+	}
+
+	sorted := sortedByTime(samples)
+	rates := make([]Point, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		prev, cur := sorted[i-1], sorted[i]
+		seconds := cur.Time.Sub(prev.Time).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+
+		rates = append(rates, Point{Time: cur.Time, Value: counterDelta(prev.Value, cur.Value) * 8 / seconds})
+	}
+	return rates
+}
+
+// counterDelta returns the increase between two counter samples. A decrease
+// means the counter was reset, so the current value is the increase since.
+func counterDelta(prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+func sortedByTime(points []Point) []Point {
+	sorted := make([]Point, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	return sorted
+}
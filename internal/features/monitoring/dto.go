@@ -1,17 +1,129 @@
 package monitoring
 
-import "github.com/yourorg/nms-go/internal/features/execution"
+import (
+	"time"
+
+	"github.com/yourorg/nms-go/internal/features/execution"
+)
 
 // SyncRequest represents the payload from OpenAccess to sync inventory
 type SyncRequest struct {
 	Targets []execution.Target `json:"targets" binding:"required"`
 }
 
+// syncTargets validates every target of a sync that replaces the current
+// targets. A validate-only sync binds without it, so invalid targets are
+// reported per target instead of failing the request.
+type syncTargets struct {
+	Targets []syncTarget `json:"targets" binding:"dive"`
+}
+
+// syncTarget is execution.Target with the stricter rules a stored target
+// needs: its driver must be "mikrotik", as every target is polled over the
+// MikroTik API. The port is only range checked; polls always use the API's
+// default port, so it is not stored. Both a sync and a validate-only sync
+// check targets against it.
+type syncTarget struct {
+	IP     string   `json:"ip" binding:"required,ip"`
+	Driver string   `json:"driver" binding:"required,oneof=mikrotik"`
+	Auth   syncAuth `json:"auth" binding:"required"`
+}
+
+type syncAuth struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Port     int    `json:"port" binding:"min=0,max=65535"`
+}
+
+// newSyncTargets wraps targets for validation.
+func newSyncTargets(targets []execution.Target) syncTargets {
+	st := syncTargets{Targets: make([]syncTarget, len(targets))}
+	for i, t := range targets {
+		st.Targets[i] = newSyncTarget(t)
+	}
+	return st
+}
+
+func newSyncTarget(t execution.Target) syncTarget {
+	return syncTarget{
+		IP:     t.IP,
+		Driver: t.Driver,
+		Auth:   syncAuth(t.Auth),
+	}
+}
+
+// SyncResponse reports what a sync changed. Targets are listed by IP.
+type SyncResponse struct {
+	Status string `json:"status"`
+	// Count is the number of targets after the sync
+	Count     int      `json:"count"`
+	Added     []string `json:"added"`
+	Updated   []string `json:"updated"`
+	Removed   []string `json:"removed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// SyncValidationResponse is the report of a validate-only sync: what a sync
+// of the same payload would load, without replacing the current targets
+type SyncValidationResponse struct {
+	ValidateOnly bool `json:"validate_only"`
+	// Count is the number of targets the sync would load
+	Count   int                `json:"count"`
+	Valid   int                `json:"valid"`
+	Invalid int                `json:"invalid"`
+	Targets []TargetValidation `json:"targets"`
+}
+
+// TargetValidation is the outcome of validating one target of a sync payload
+type TargetValidation struct {
+	// Index is the target's position in the request's targets array
+	Index  int      `json:"index"`
+	IP     string   `json:"ip"`
+	Driver string   `json:"driver"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+	// Warnings do not make the target invalid, e.g. a later target with the
+	// same IP replacing it
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // DeviceTarget is the internal representation of a monitoring target
 type DeviceTarget struct {
 	IP       string
 	Driver   string
 	Username string
 	Password string
-	Port     int
+}
+
+// SummaryResponse reports the reachability of all monitoring targets
+type SummaryResponse struct {
+	Total       int `json:"total"`
+	Reachable   int `json:"reachable"`
+	Unreachable int `json:"unreachable"`
+	Stale       int `json:"stale"`
+	Unknown     int `json:"unknown"`
+
+	// StaleAfterSeconds is the age beyond which a poll result is reported as stale
+	StaleAfterSeconds int64           `json:"stale_after_seconds"`
+	Targets           []TargetSummary `json:"targets"`
+}
+
+// TargetSummary is the reachability of one monitoring target
+type TargetSummary struct {
+	IP     string `json:"ip"`
+	Driver string `json:"driver,omitempty"`
+
+	// State is reachable, unreachable, stale or unknown (never polled)
+	State string `json:"state"`
+
+	// CheckedAt, AgeSeconds and LastReachable describe the last poll; they
+	// are omitted for targets that have not been polled yet
+	CheckedAt     *time.Time `json:"checked_at,omitempty"`
+	AgeSeconds    int64      `json:"age_seconds,omitempty"`
+	LastReachable *bool      `json:"last_reachable,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+
+	// ConsecutiveFailures counts the polls that failed since the last
+	// success. It is kept across syncs that leave the target unchanged.
+	ConsecutiveFailures int `json:"consecutive_failures"`
 }
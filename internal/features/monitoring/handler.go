@@ -1,25 +1,70 @@
 package monitoring
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/yourorg/nms-go/internal/common/validator"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/features/execution"
 )
 
 type Handler struct {
-	store *TargetStore
+	store        *TargetStore
+	reachability *ReachabilityCache
 }
 
-func NewHandler(store *TargetStore) *Handler {
+func NewHandler(store *TargetStore, reachability *ReachabilityCache) *Handler {
 	return &Handler{
-		store: store,
+		store:        store,
+		reachability: reachability,
 	}
 }
 
+// Reachability returns the cache the inventory summary reads from.
+func (h *Handler) Reachability() *ReachabilityCache {
+	return h.reachability
+}
+
+// SyncInventory handles POST /api/v1/inventory/sync
+//
+// Replaces the monitoring targets with the payload's and reports which were
+// added, updated and removed. Unchanged targets keep their scheduling state
+// and last poll outcome. A payload with an invalid target is rejected
+// and the current targets are kept. With
+// ?validate_only=true it only reports, per target, whether it would be
+// polled successfully and leaves the current targets untouched.
 func (h *Handler) SyncInventory(c *gin.Context) {
+	validateOnly := false
+	if raw := c.Query("validate_only"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid query: validate_only must be true or false, got %q", raw)})
+			return
+		}
+		validateOnly = v
+	}
+
 	var req SyncRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+
+	if validateOnly {
+		c.JSON(http.StatusOK, validateSync(req.Targets))
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(newSyncTargets(req.Targets)); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
 		return
 	}
 
@@ -30,14 +75,176 @@ func (h *Handler) SyncInventory(c *gin.Context) {
 			Driver:   t.Driver,
 			Username: t.Auth.Username,
 			Password: t.Auth.Password,
-			Port:     t.Auth.Port,
 		}
 	}
 
-	h.store.ReplaceAll(targets)
+	result := h.store.ReplaceAll(targets)
+	if h.reachability != nil {
+		h.reachability.Forget(result.Removed)
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"count":  len(targets),
+	c.JSON(http.StatusOK, SyncResponse{
+		Status:    "success",
+		Count:     len(result.Added) + len(result.Updated) + result.Unchanged,
+		Added:     nonNil(result.Added),
+		Updated:   nonNil(result.Updated),
+		Removed:   nonNil(result.Removed),
+		Unchanged: result.Unchanged,
 	})
 }
+
+// nonNil returns ips, or an empty slice so it is encoded as [] rather than
+// null.
+func nonNil(ips []string) []string {
+	if ips == nil {
+		return []string{}
+	}
+	return ips
+}
+
+// validateSync checks each target against the rules a sync applies, so a
+// payload it reports as valid is accepted by the sync and vice versa.
+// Targets are keyed by IP, so of several targets with one IP only the last is
+// kept; the others are reported with a warning.
+func validateSync(targets []execution.Target) SyncValidationResponse {
+	resp := SyncValidationResponse{
+		ValidateOnly: true,
+		Targets:      make([]TargetValidation, len(targets)),
+	}
+
+	lastByIP := make(map[string]int, len(targets))
+	for i, t := range targets {
+		lastByIP[t.IP] = i
+	}
+	resp.Count = len(lastByIP)
+
+	for i, t := range targets {
+		var errs, warnings []string
+		if err := binding.Validator.ValidateStruct(newSyncTarget(t)); err != nil {
+			fields := validator.Fields(err)
+			if len(fields) == 0 {
+				errs = append(errs, err.Error())
+			}
+			for _, f := range fields {
+				errs = append(errs, f.Field+" "+f.Message)
+			}
+		}
+		if last := lastByIP[t.IP]; last != i {
+			warnings = append(warnings, fmt.Sprintf("replaced by target %d with the same ip", last))
+		}
+
+		resp.Targets[i] = TargetValidation{
+			Index:    i,
+			IP:       t.IP,
+			Driver:   t.Driver,
+			Valid:    len(errs) == 0,
+			Errors:   errs,
+			Warnings: warnings,
+		}
+		if len(errs) == 0 {
+			resp.Valid++
+		} else {
+			resp.Invalid++
+		}
+	}
+	return resp
+}
+
+// GetSummary handles GET /api/v1/inventory/summary
+//
+// Reports the reachability of every synced target as last observed by the
+// monitoring scheduler. Entries older than the staleness threshold are
+// counted as stale rather than trusted.
+func (h *Handler) GetSummary(c *gin.Context) {
+	targets := h.store.GetAll()
+	sort.Slice(targets, func(i, j int) bool { return targets[i].IP < targets[j].IP })
+
+	resp := SummaryResponse{
+		Total:             len(targets),
+		StaleAfterSeconds: int64(h.reachability.StaleAfter().Seconds()),
+		Targets:           make([]TargetSummary, 0, len(targets)),
+	}
+
+	now := h.reachability.now()
+	for _, t := range targets {
+		r, state := h.reachability.Get(t.IP)
+		summary := TargetSummary{IP: t.IP, Driver: t.Driver, State: state}
+		if ts, ok := h.store.State(t.IP); ok {
+			summary.ConsecutiveFailures = ts.ConsecutiveFailures
+		}
+
+		switch state {
+		case ReachabilityReachable:
+			resp.Reachable++
+		case ReachabilityUnreachable:
+			resp.Unreachable++
+		case ReachabilityStale:
+			resp.Stale++
+		default:
+			resp.Unknown++
+		}
+
+		if state != ReachabilityUnknown {
+			checkedAt := r.CheckedAt
+			summary.CheckedAt = &checkedAt
+			summary.AgeSeconds = int64(now.Sub(r.CheckedAt).Seconds())
+			summary.LastReachable = &r.Reachable
+			summary.LastError = r.LastError
+		}
+		resp.Targets = append(resp.Targets, summary)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeviceGetter resolves a registered device by ID
+type DeviceGetter interface {
+	GetDevice(ctx context.Context, id string) (*model.Device, error)
+}
+
+// LiveHandler serves metrics polled directly from a device
+type LiveHandler struct {
+	devices DeviceGetter
+	poller  LivePoller
+}
+
+func NewLiveHandler(devices DeviceGetter, poller LivePoller) *LiveHandler {
+	return &LiveHandler{
+		devices: devices,
+		poller:  poller,
+	}
+}
+
+// GetLiveMetrics handles GET /devices/:id/metrics/live
+func (h *LiveHandler) GetLiveMetrics(c *gin.Context) {
+	device, err := h.devices.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeDeviceLookupError(c, err)
+		return
+	}
+
+	metrics, err := h.poller.Poll(c.Request.Context(), device)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnsupportedProtocol):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrDeviceUnreachable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
+// writeDeviceLookupError responds 404 for a device that does not exist and
+// 500 when it could not be looked up.
+func writeDeviceLookupError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrDeviceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
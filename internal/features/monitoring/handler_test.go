@@ -0,0 +1,419 @@
+package monitoring_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+type fakeDeviceGetter struct {
+	devices map[string]*model.Device
+}
+
+func (f *fakeDeviceGetter) GetDevice(ctx context.Context, id string) (*model.Device, error) {
+	if d, ok := f.devices[id]; ok {
+		return d, nil
+	}
+	if id == "broken" {
+		return nil, errors.New("connection reset by peer")
+	}
+	return nil, fmt.Errorf("%w: %s", repository.ErrDeviceNotFound, id)
+}
+
+// fakeMetricsClient is a MetricsClient with configurable behaviour.
+type fakeMetricsClient struct {
+	connectErr   error
+	system       *mikrotik.SystemMetrics
+	interfaces   []*mikrotik.InterfaceMetrics
+	systemErr    error
+	disconnected bool
+}
+
+func (f *fakeMetricsClient) Connect(ctx context.Context, device *model.Device) error {
+	return f.connectErr
+}
+
+func (f *fakeMetricsClient) Disconnect() error {
+	f.disconnected = true
+	return nil
+}
+
+func (f *fakeMetricsClient) GetSystemMetrics(ctx context.Context) (*mikrotik.SystemMetrics, error) {
+	return f.system, f.systemErr
+}
+
+func (f *fakeMetricsClient) GetInterfaceMetrics(ctx context.Context) ([]*mikrotik.InterfaceMetrics, error) {
+	return f.interfaces, nil
+}
+
+func setupLiveRouter(client *fakeMetricsClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	devices := &fakeDeviceGetter{devices: map[string]*model.Device{
+		"dev-1": {ID: "dev-1", IPAddress: "10.0.0.1", Protocol: model.ProtocolMikrotikAPI},
+		"dev-2": {ID: "dev-2", IPAddress: "10.0.0.2", Protocol: model.ProtocolTR069},
+	}}
+	poller := monitoring.NewLivePollerForTest(func(protocol model.Protocol) (monitoring.MetricsClient, error) {
+		if protocol != model.ProtocolMikrotikAPI {
+			return nil, monitoring.ErrUnsupportedProtocol
+		}
+		return client, nil
+	})
+
+	r := gin.New()
+	h := monitoring.NewLiveHandler(devices, poller)
+	r.GET("/devices/:id/metrics/live", h.GetLiveMetrics)
+	r.GET("/devices/:id/metrics/ws", h.StreamLiveMetrics)
+	return r
+}
+
+func TestGetLiveMetrics_Reachable(t *testing.T) {
+	client := &fakeMetricsClient{
+		system:     &mikrotik.SystemMetrics{DeviceID: "dev-1", CPUUsage: 12.5},
+		interfaces: []*mikrotik.InterfaceMetrics{{DeviceID: "dev-1", InterfaceName: "ether1", Status: "running"}},
+	}
+	r := setupLiveRouter(client)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/dev-1/metrics/live", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp monitoring.LiveMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "dev-1", resp.DeviceID)
+	require.NotNil(t, resp.System)
+	assert.Equal(t, 12.5, resp.System.CPUUsage)
+	require.Len(t, resp.Interfaces, 1)
+	assert.Equal(t, "ether1", resp.Interfaces[0].InterfaceName)
+	assert.Empty(t, resp.Errors)
+	assert.True(t, client.disconnected)
+}
+
+func TestGetLiveMetrics_PartialFailure(t *testing.T) {
+	client := &fakeMetricsClient{
+		systemErr:  errors.New("timeout"),
+		interfaces: []*mikrotik.InterfaceMetrics{{DeviceID: "dev-1", InterfaceName: "ether1"}},
+	}
+	r := setupLiveRouter(client)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/dev-1/metrics/live", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp monitoring.LiveMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Nil(t, resp.System)
+	assert.Len(t, resp.Interfaces, 1)
+	assert.Equal(t, []string{"system: timeout"}, resp.Errors)
+}
+
+func TestGetLiveMetrics_Unreachable(t *testing.T) {
+	r := setupLiveRouter(&fakeMetricsClient{connectErr: errors.New("connection refused")})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/dev-1/metrics/live", nil))
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "device unreachable")
+	assert.Contains(t, w.Body.String(), "connection refused")
+}
+
+func TestGetLiveMetrics_DeviceNotFound(t *testing.T) {
+	r := setupLiveRouter(&fakeMetricsClient{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/missing/metrics/live", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetLiveMetrics_LookupFailure(t *testing.T) {
+	r := setupLiveRouter(&fakeMetricsClient{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/broken/metrics/live", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetLiveMetrics_UnsupportedProtocol(t *testing.T) {
+	r := setupLiveRouter(&fakeMetricsClient{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/devices/dev-2/metrics/live", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func setupSyncRouter(store *monitoring.TargetStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	h := monitoring.NewHandler(store, monitoring.NewReachabilityCache(time.Minute))
+	r.POST("/inventory/sync", h.SyncInventory)
+	return r
+}
+
+const syncPayload = `{"targets": [
+	{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}},
+	{"ip": "10.0.0.300", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}},
+	{"ip": "10.0.0.2", "driver": "cisco", "auth": {"username": "admin", "password": "x", "port": 70000}},
+	{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "ops", "password": "y", "port": 8728}}
+]}`
+
+func TestSyncInventory_ValidateOnlyCreatesNothing(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	store.Upsert(monitoring.DeviceTarget{IP: "192.168.1.1", Driver: "mikrotik"})
+	r := setupSyncRouter(store)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync?validate_only=true", strings.NewReader(syncPayload)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp monitoring.SyncValidationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.True(t, resp.ValidateOnly)
+	assert.Equal(t, 3, resp.Count, "targets with one IP collapse into one")
+	assert.Equal(t, 2, resp.Valid)
+	assert.Equal(t, 2, resp.Invalid)
+	require.Len(t, resp.Targets, 4)
+
+	assert.True(t, resp.Targets[0].Valid, "a replaced target does not fail the sync")
+	assert.Equal(t, []string{"replaced by target 3 with the same ip"}, resp.Targets[0].Warnings)
+	assert.False(t, resp.Targets[1].Valid)
+	assert.Equal(t, []string{"ip must be a valid IP address"}, resp.Targets[1].Errors)
+	assert.Len(t, resp.Targets[2].Errors, 2, "unsupported driver and port out of range")
+	assert.True(t, resp.Targets[3].Valid)
+	assert.Empty(t, resp.Targets[3].Errors)
+
+	targets := store.GetAll()
+	require.Len(t, targets, 1, "validate-only must not replace the targets")
+	assert.Equal(t, "192.168.1.1", targets[0].IP)
+}
+
+func TestSyncInventory_ReplacesTargets(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	store.ReplaceAll([]monitoring.DeviceTarget{
+		{IP: "10.0.0.1", Driver: "mikrotik", Username: "admin", Password: "x"},
+		{IP: "10.0.0.2", Driver: "mikrotik", Username: "admin", Password: "x"},
+		{IP: "192.168.1.1", Driver: "mikrotik", Username: "admin", Password: "x"},
+	})
+	r := setupSyncRouter(store)
+
+	payload := `{"targets": [
+		{"ip": "10.0.0.3", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}},
+		{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}},
+		{"ip": "10.0.0.2", "driver": "mikrotik", "auth": {"username": "admin", "password": "old"}},
+		{"ip": "10.0.0.2", "driver": "mikrotik", "auth": {"username": "ops", "password": "y", "port": 8728}}
+	]}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync?validate_only=false", strings.NewReader(payload)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp monitoring.SyncResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, monitoring.SyncResponse{
+		Status:    "success",
+		Count:     3,
+		Added:     []string{"10.0.0.3"},
+		Updated:   []string{"10.0.0.2"},
+		Removed:   []string{"192.168.1.1"},
+		Unchanged: 1,
+	}, resp)
+
+	assert.Equal(t, 3, store.Len())
+	_, ok := store.Get("192.168.1.1")
+	assert.False(t, ok)
+	target, ok := store.Get("10.0.0.2")
+	require.True(t, ok)
+	assert.Equal(t, "ops", target.Username, "the last target with an IP wins")
+}
+
+func TestSyncInventory_KeepsPollStateOfUnchangedTargets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	kept := monitoring.DeviceTarget{IP: "10.0.0.1", Driver: "mikrotik", Username: "admin", Password: "x"}
+	dropped := monitoring.DeviceTarget{IP: "10.0.0.2", Driver: "mikrotik", Username: "admin", Password: "x"}
+	store := monitoring.NewTargetStore()
+	store.ReplaceAll([]monitoring.DeviceTarget{kept, dropped})
+	cache := monitoring.NewReachabilityCache(time.Minute)
+	for _, target := range []monitoring.DeviceTarget{kept, dropped} {
+		store.RecordPoll(target, time.Now(), errors.New("i/o timeout"))
+		cache.Record(target.IP, errors.New("i/o timeout"))
+	}
+
+	h := monitoring.NewHandler(store, cache)
+	r := gin.New()
+	r.POST("/inventory/sync", h.SyncInventory)
+	r.GET("/inventory/summary", h.GetSummary)
+
+	sync := func(body string) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	sync(`{"targets": [{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}}]}`)
+	sync(`{"targets": [
+		{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}},
+		{"ip": "10.0.0.2", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}}
+	]}`)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/inventory/summary", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp monitoring.SummaryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Targets, 2)
+
+	assert.Equal(t, monitoring.ReachabilityUnreachable, resp.Targets[0].State)
+	assert.Equal(t, 1, resp.Targets[0].ConsecutiveFailures)
+
+	// Removed and added again: its earlier polls are forgotten.
+	assert.Equal(t, monitoring.ReachabilityUnknown, resp.Targets[1].State)
+	assert.Zero(t, resp.Targets[1].ConsecutiveFailures)
+}
+
+func TestSyncInventory_SameTargetsChangeNothing(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.1", Driver: "mikrotik", Username: "admin", Password: "x"})
+	r := setupSyncRouter(store)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync",
+		strings.NewReader(`{"targets": [{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}}]}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.JSONEq(t, `{"status": "success", "count": 1, "added": [], "updated": [], "removed": [], "unchanged": 1}`, w.Body.String())
+}
+
+func TestSyncInventory_PortOnlyChangeIsUnchanged(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	target := monitoring.DeviceTarget{IP: "10.0.0.1", Driver: "mikrotik", Username: "admin", Password: "x"}
+	store.Upsert(target)
+	store.RecordPoll(target, time.Now(), errors.New("i/o timeout"))
+	r := setupSyncRouter(store)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync",
+		strings.NewReader(`{"targets": [{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x", "port": 8729}}]}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.JSONEq(t, `{"status": "success", "count": 1, "added": [], "updated": [], "removed": [], "unchanged": 1}`, w.Body.String())
+	state, ok := store.State("10.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, 1, state.ConsecutiveFailures, "a port the poll does not use must not reset its state")
+}
+
+func TestSyncInventory_InvalidTargetKeepsCurrentTargets(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	store.Upsert(monitoring.DeviceTarget{IP: "192.168.1.1", Driver: "mikrotik"})
+	r := setupSyncRouter(store)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync", strings.NewReader(syncPayload)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body struct {
+		Fields []struct {
+			Field string `json:"field"`
+		} `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	var fields []string
+	for _, f := range body.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"targets[1].ip", "targets[2].driver", "targets[2].auth.port"}, fields)
+
+	targets := store.GetAll()
+	require.Len(t, targets, 1, "a rejected sync must not replace the targets")
+	assert.Equal(t, "192.168.1.1", targets[0].IP)
+}
+
+// TestSyncInventory_ValidateOnlyAgreesWithSync checks that a payload the
+// preview reports as valid is accepted by the sync, and one it reports as
+// invalid is rejected.
+func TestSyncInventory_ValidateOnlyAgreesWithSync(t *testing.T) {
+	targets := map[string]string{
+		"valid":             `{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x", "port": 8728}}`,
+		"default port":      `{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}}`,
+		"other driver":      `{"ip": "10.0.0.1", "driver": "cisco", "auth": {"username": "admin", "password": "x"}}`,
+		"no driver":         `{"ip": "10.0.0.1", "auth": {"username": "admin", "password": "x"}}`,
+		"no password":       `{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin"}}`,
+		"no username":       `{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"password": "x"}}`,
+		"bad ip":            `{"ip": "10.0.0.300", "driver": "mikrotik", "auth": {"username": "admin", "password": "x"}}`,
+		"port out of range": `{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x", "port": 70000}}`,
+		"negative port":     `{"ip": "10.0.0.1", "driver": "mikrotik", "auth": {"username": "admin", "password": "x", "port": -1}}`,
+	}
+
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			payload := `{"targets": [` + target + `]}`
+			r := setupSyncRouter(monitoring.NewTargetStore())
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync?validate_only=true", strings.NewReader(payload)))
+			require.Equal(t, http.StatusOK, w.Code)
+			var preview monitoring.SyncValidationResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &preview))
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync", strings.NewReader(payload)))
+
+			if preview.Invalid == 0 {
+				assert.Equal(t, http.StatusOK, w.Code, "preview passed but the sync failed: %s", w.Body.String())
+			} else {
+				assert.Equal(t, http.StatusBadRequest, w.Code, "preview failed with %v but the sync passed", preview.Targets[0].Errors)
+			}
+		})
+	}
+}
+
+func TestSyncInventory_MalformedBody(t *testing.T) {
+	for name, body := range map[string]string{
+		"not json":        `{"targets": [`,
+		"missing targets": `{}`,
+		"wrong type":      `{"targets": {"ip": "10.0.0.1"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := monitoring.NewTargetStore()
+			r := setupSyncRouter(store)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync", strings.NewReader(body)))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "invalid request body")
+		})
+	}
+}
+
+func TestSyncInventory_InvalidValidateOnly(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	r := setupSyncRouter(store)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inventory/sync?validate_only=maybe", strings.NewReader(syncPayload)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "validate_only")
+	assert.Equal(t, 0, store.Len())
+}
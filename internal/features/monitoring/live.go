@@ -0,0 +1,73 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+// ErrDeviceUnreachable is returned when a live poll cannot connect to the device
+var ErrDeviceUnreachable = errors.New("device unreachable")
+
+// LiveMetrics is the result of an on-demand poll of a single device
+type LiveMetrics struct {
+	DeviceID    string                       `json:"device_id"`
+	CollectedAt time.Time                    `json:"collected_at"`
+	System      *mikrotik.SystemMetrics      `json:"system,omitempty"`
+	Interfaces  []*mikrotik.InterfaceMetrics `json:"interfaces,omitempty"`
+	Errors      []string                     `json:"errors,omitempty"`
+}
+
+// LivePoller polls a device on demand, bypassing the metric store
+type LivePoller interface {
+	Poll(ctx context.Context, device *model.Device) (*LiveMetrics, error)
+}
+
+type livePoller struct {
+	newClient ClientFactory
+	now       func() time.Time
+}
+
+// NewLivePoller creates a LivePoller using the default protocol clients
+func NewLivePoller() LivePoller {
+	return &livePoller{newClient: DefaultClientFactory, now: time.Now}
+}
+
+// NewLivePollerForTest creates a LivePoller with a custom client factory
+func NewLivePollerForTest(factory ClientFactory) LivePoller {
+	return &livePoller{newClient: factory, now: time.Now}
+}
+
+// Poll connects to the device and collects system and interface metrics.
+// Partial failures are reported in LiveMetrics.Errors; a connection failure
+// returns ErrDeviceUnreachable.
+func (p *livePoller) Poll(ctx context.Context, device *model.Device) (*LiveMetrics, error) {
+	client, err := p.newClient(device.Protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := collect(ctx, client, device)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDeviceUnreachable, err)
+	}
+
+	live := &LiveMetrics{
+		DeviceID:    device.ID,
+		CollectedAt: p.now(),
+		System:      result.System,
+		Interfaces:  result.Interfaces,
+	}
+	if result.SystemErr != nil {
+		live.Errors = append(live.Errors, "system: "+result.SystemErr.Error())
+	}
+	if result.InterfaceErr != nil {
+		live.Errors = append(live.Errors, "interfaces: "+result.InterfaceErr.Error())
+	}
+
+	return live, nil
+}
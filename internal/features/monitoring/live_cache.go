@@ -0,0 +1,111 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/device/model"
+)
+
+// CachedLivePoller shares poll results between concurrent and closely spaced
+// live requests for the same device, so several dashboards streaming one
+// device cost a single session on it.
+type CachedLivePoller struct {
+	inner LivePoller
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*pollEntry
+}
+
+// pollEntry is an in-flight or completed poll of one device.
+type pollEntry struct {
+	ip        string
+	done      chan struct{}
+	metrics   *LiveMetrics
+	err       error
+	expiresAt time.Time
+}
+
+// NewCachedLivePoller wraps a LivePoller so that concurrent polls of the same
+// device share one underlying poll, and a successful result is reused for
+// ttl. A ttl of zero only shares polls that are in flight. Returned
+// LiveMetrics may be shared between callers and must not be modified.
+func NewCachedLivePoller(inner LivePoller, ttl time.Duration) *CachedLivePoller {
+	return &CachedLivePoller{
+		inner:   inner,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*pollEntry),
+	}
+}
+
+func (p *CachedLivePoller) Poll(ctx context.Context, device *model.Device) (*LiveMetrics, error) {
+	p.mu.Lock()
+	entry, ok := p.entries[device.ID]
+	if ok && !p.reusable(entry) {
+		ok = false
+	}
+	if !ok {
+		entry = &pollEntry{ip: device.IPAddress, done: make(chan struct{})}
+		p.entries[device.ID] = entry
+		go p.fetch(ctx, device, entry)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		return entry.metrics, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// reusable reports whether entry is still in flight or a fresh success.
+// It must be called with p.mu held.
+func (p *CachedLivePoller) reusable(entry *pollEntry) bool {
+	select {
+	case <-entry.done:
+		return entry.err == nil && p.now().Before(entry.expiresAt)
+	default:
+		return true
+	}
+}
+
+// fetch runs the shared poll. It is detached from the first caller's
+// cancellation so one dashboard closing does not fail the others.
+func (p *CachedLivePoller) fetch(ctx context.Context, device *model.Device, entry *pollEntry) {
+	metrics, err := p.inner.Poll(context.WithoutCancel(ctx), device)
+
+	p.mu.Lock()
+	entry.metrics, entry.err = metrics, err
+	entry.expiresAt = p.now().Add(p.ttl)
+	if err != nil || p.ttl <= 0 {
+		// Nothing to reuse; let the next request poll again.
+		if p.entries[device.ID] == entry {
+			delete(p.entries, device.ID)
+		}
+	}
+	p.mu.Unlock()
+
+	close(entry.done)
+}
+
+// InvalidateCache drops the results kept for devices at ip, or for every
+// device when ip is empty, and returns how many were dropped. Polls in
+// flight finish for the callers already waiting on them.
+func (p *CachedLivePoller) InvalidateCache(ip string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := 0
+	for id, entry := range p.entries {
+		if ip == "" || entry.ip == ip {
+			delete(p.entries, id)
+			n++
+		}
+	}
+	return n
+}
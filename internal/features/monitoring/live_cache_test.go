@@ -0,0 +1,147 @@
+package monitoring_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+)
+
+// countingPoller counts device fetches and blocks each one until release is closed.
+type countingPoller struct {
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+}
+
+func (p *countingPoller) Poll(ctx context.Context, device *model.Device) (*monitoring.LiveMetrics, error) {
+	p.calls.Add(1)
+	if p.release != nil {
+		<-p.release
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &monitoring.LiveMetrics{DeviceID: device.ID, CollectedAt: time.Now()}, nil
+}
+
+var liveDevice = &model.Device{ID: "dev-1", Protocol: model.ProtocolMikrotikAPI}
+
+func TestCachedLivePoller_ConcurrentRequestsShareOneFetch(t *testing.T) {
+	inner := &countingPoller{release: make(chan struct{})}
+	poller := monitoring.NewCachedLivePoller(inner, time.Minute)
+
+	const viewers = 3
+	results := make([]*monitoring.LiveMetrics, viewers)
+	var wg sync.WaitGroup
+	for i := 0; i < viewers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m, err := poller.Poll(context.Background(), liveDevice)
+			assert.NoError(t, err)
+			results[i] = m
+		}(i)
+	}
+
+	require.Eventually(t, func() bool { return inner.calls.Load() == 1 }, time.Second, time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), inner.calls.Load())
+	for _, m := range results {
+		assert.Same(t, results[0], m)
+	}
+}
+
+func TestCachedLivePoller_RefetchesAfterTTL(t *testing.T) {
+	inner := &countingPoller{}
+	poller := monitoring.NewCachedLivePoller(inner, 20*time.Millisecond)
+
+	_, err := poller.Poll(context.Background(), liveDevice)
+	require.NoError(t, err)
+	_, err = poller.Poll(context.Background(), liveDevice)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), inner.calls.Load(), "second poll within TTL is served from cache")
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = poller.Poll(context.Background(), liveDevice)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), inner.calls.Load())
+}
+
+func TestCachedLivePoller_SeparateDevices(t *testing.T) {
+	inner := &countingPoller{}
+	poller := monitoring.NewCachedLivePoller(inner, time.Minute)
+
+	_, err := poller.Poll(context.Background(), liveDevice)
+	require.NoError(t, err)
+	_, err = poller.Poll(context.Background(), &model.Device{ID: "dev-2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), inner.calls.Load())
+}
+
+func TestCachedLivePoller_InvalidateCache(t *testing.T) {
+	inner := &countingPoller{}
+	poller := monitoring.NewCachedLivePoller(inner, time.Minute)
+	a := &model.Device{ID: "dev-1", IPAddress: "10.0.0.1"}
+	b := &model.Device{ID: "dev-2", IPAddress: "10.0.0.2"}
+	ctx := context.Background()
+
+	for _, d := range []*model.Device{a, b} {
+		_, err := poller.Poll(ctx, d)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, poller.InvalidateCache("10.0.0.1"))
+	assert.Equal(t, 0, poller.InvalidateCache("10.0.0.9"))
+
+	for _, d := range []*model.Device{a, b} {
+		_, err := poller.Poll(ctx, d)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), inner.calls.Load(), "only the invalidated device is polled again")
+
+	assert.Equal(t, 2, poller.InvalidateCache(""))
+}
+
+func TestCachedLivePoller_ErrorsAreNotCached(t *testing.T) {
+	inner := &countingPoller{err: errors.New("device unreachable")}
+	poller := monitoring.NewCachedLivePoller(inner, time.Minute)
+
+	_, err := poller.Poll(context.Background(), liveDevice)
+	require.Error(t, err)
+	_, err = poller.Poll(context.Background(), liveDevice)
+	require.Error(t, err)
+
+	assert.Equal(t, int32(2), inner.calls.Load())
+}
+
+func TestCachedLivePoller_CallerCancelDoesNotAbortSharedPoll(t *testing.T) {
+	inner := &countingPoller{release: make(chan struct{})}
+	poller := monitoring.NewCachedLivePoller(inner, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := poller.Poll(ctx, liveDevice)
+		errc <- err
+	}()
+	require.Eventually(t, func() bool { return inner.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-errc, context.Canceled)
+
+	close(inner.release)
+	m, err := poller.Poll(context.Background(), liveDevice)
+	require.NoError(t, err)
+	assert.Equal(t, "dev-1", m.DeviceID)
+	assert.Equal(t, int32(1), inner.calls.Load())
+}
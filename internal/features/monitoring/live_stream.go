@@ -0,0 +1,187 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	defaultStreamInterval = 2 * time.Second
+	minStreamInterval     = time.Second
+	maxStreamInterval     = time.Minute
+
+	// streamWriteTimeout bounds a single frame write; a client that cannot
+	// take a frame in this time is disconnected.
+	streamWriteTimeout = 10 * time.Second
+)
+
+// Live stream frame types
+const (
+	FrameTypeMetrics = "metrics"
+	FrameTypeError   = "error"
+)
+
+// LiveFrame is one message on the live metrics WebSocket
+type LiveFrame struct {
+	Type    string       `json:"type"`
+	Metrics *LiveMetrics `json:"metrics,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// StreamLiveMetrics handles GET /devices/:id/metrics/ws
+//
+// It upgrades to a WebSocket and sends a frame per poll until the client
+// disconnects. The optional interval query parameter (e.g. "5s") sets the
+// poll interval. Each connection holds at most one pending frame: if the
+// client reads slower than the device is polled, older frames are dropped
+// in favour of the latest.
+func (h *LiveHandler) StreamLiveMetrics(c *gin.Context) {
+	device, err := h.devices.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeDeviceLookupError(c, err)
+		return
+	}
+
+	interval, err := parseStreamInterval(c.Query("interval"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Poll once before upgrading so an unsupported device fails as plain HTTP.
+	first := h.pollFrame(c.Request.Context(), device)
+	if errors.Is(first.err, ErrUnsupportedProtocol) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": first.Error})
+		return
+	}
+
+	server := websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			h.stream(ws, device, interval, first.LiveFrame)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func parseStreamInterval(raw string) (time.Duration, error) {
+	if raw == "" {
+		return defaultStreamInterval, nil
+	}
+
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval: %s", raw)
+	}
+	if interval < minStreamInterval || interval > maxStreamInterval {
+		return 0, fmt.Errorf("interval must be between %s and %s", minStreamInterval, maxStreamInterval)
+	}
+	return interval, nil
+}
+
+// stream writes frames until the client goes away or a write fails.
+func (h *LiveHandler) stream(ws *websocket.Conn, device *model.Device, interval time.Duration, first LiveFrame) {
+	defer ws.Close()
+
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+
+	// Clients don't send anything; reading only detects the disconnect.
+	go func() {
+		defer cancel()
+		_, _ = io.Copy(io.Discard, ws)
+	}()
+
+	frames := make(chan LiveFrame, 1)
+	frames <- first
+	go h.pollLoop(ctx, device, interval, frames)
+
+	rates := NewRateCalculator()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame := <-frames:
+			frame = withRates(frame, rates)
+			_ = ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := websocket.JSON.Send(ws, frame); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *LiveHandler) pollLoop(ctx context.Context, device *model.Device, interval time.Duration, frames chan LiveFrame) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			offerLatest(frames, h.pollFrame(ctx, device).LiveFrame)
+		}
+	}
+}
+
+// offerLatest queues frame, replacing a pending frame the writer has not
+// picked up yet.
+func offerLatest(frames chan LiveFrame, frame LiveFrame) {
+	select {
+	case frames <- frame:
+		return
+	default:
+	}
+
+	select {
+	case <-frames:
+	default:
+	}
+
+	select {
+	case frames <- frame:
+	default:
+	}
+}
+
+// withRates returns frame with the interface rates since the previous frame
+// sent on the connection. The interfaces are copied: a cached poll is shared
+// with other connections.
+func withRates(frame LiveFrame, rates *RateCalculator) LiveFrame {
+	if frame.Metrics == nil || len(frame.Metrics.Interfaces) == 0 {
+		return frame
+	}
+
+	live := *frame.Metrics
+	live.Interfaces = make([]*mikrotik.InterfaceMetrics, len(frame.Metrics.Interfaces))
+	for i, m := range frame.Metrics.Interfaces {
+		iface := *m
+		rates.Apply(&iface)
+		live.Interfaces[i] = &iface
+	}
+	frame.Metrics = &live
+	return frame
+}
+
+// polledFrame keeps the poll error alongside the frame for status mapping.
+type polledFrame struct {
+	LiveFrame
+	err error
+}
+
+func (h *LiveHandler) pollFrame(ctx context.Context, device *model.Device) polledFrame {
+	metrics, err := h.poller.Poll(ctx, device)
+	if err != nil {
+		return polledFrame{LiveFrame: LiveFrame{Type: FrameTypeError, Error: err.Error()}, err: err}
+	}
+	return polledFrame{LiveFrame: LiveFrame{Type: FrameTypeMetrics, Metrics: metrics}}
+}
@@ -0,0 +1,93 @@
+package monitoring_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+	"golang.org/x/net/websocket"
+)
+
+func dialStream(t *testing.T, server *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + path
+	ws, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func receiveFrame(t *testing.T, ws *websocket.Conn) monitoring.LiveFrame {
+	t.Helper()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var frame monitoring.LiveFrame
+	require.NoError(t, websocket.JSON.Receive(ws, &frame))
+	return frame
+}
+
+func TestStreamLiveMetrics_ReceivesFrames(t *testing.T) {
+	client := &fakeMetricsClient{
+		system:     &mikrotik.SystemMetrics{DeviceID: "dev-1", CPUUsage: 12},
+		interfaces: []*mikrotik.InterfaceMetrics{{InterfaceName: "ether1", Status: "running"}},
+	}
+	server := httptest.NewServer(setupLiveRouter(client))
+	defer server.Close()
+
+	ws := dialStream(t, server, "/devices/dev-1/metrics/ws?interval=1s")
+
+	first := receiveFrame(t, ws)
+	assert.Equal(t, monitoring.FrameTypeMetrics, first.Type)
+	require.NotNil(t, first.Metrics)
+	assert.Equal(t, "dev-1", first.Metrics.DeviceID)
+	assert.Equal(t, 12.0, first.Metrics.System.CPUUsage)
+	require.Len(t, first.Metrics.Interfaces, 1)
+
+	// The stream keeps polling on the interval.
+	second := receiveFrame(t, ws)
+	assert.Equal(t, monitoring.FrameTypeMetrics, second.Type)
+}
+
+func TestStreamLiveMetrics_UnreachableSendsErrorFrame(t *testing.T) {
+	client := &fakeMetricsClient{connectErr: assert.AnError}
+	server := httptest.NewServer(setupLiveRouter(client))
+	defer server.Close()
+
+	ws := dialStream(t, server, "/devices/dev-1/metrics/ws")
+
+	frame := receiveFrame(t, ws)
+	assert.Equal(t, monitoring.FrameTypeError, frame.Type)
+	assert.Contains(t, frame.Error, "device unreachable")
+	assert.Nil(t, frame.Metrics)
+}
+
+func TestStreamLiveMetrics_RejectedBeforeUpgrade(t *testing.T) {
+	server := httptest.NewServer(setupLiveRouter(&fakeMetricsClient{}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"unknown device", "/devices/missing/metrics/ws", http.StatusNotFound},
+		{"device lookup fails", "/devices/broken/metrics/ws", http.StatusInternalServerError},
+		{"unsupported protocol", "/devices/dev-2/metrics/ws", http.StatusUnprocessableEntity},
+		{"interval too short", "/devices/dev-1/metrics/ws?interval=10ms", http.StatusBadRequest},
+		{"invalid interval", "/devices/dev-1/metrics/ws?interval=soon", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package monitoring
+
+import (
+	"github.com/yourorg/nms-go/internal/common/rates"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+// InterfaceRate is the traffic of an interface between two counter samples.
+type InterfaceRate = rates.Rate
+
+// RateCalculator turns the byte counters of successive interface samples into
+// bits per second. It keeps the last sample of every device and interface and
+// is safe for concurrent use.
+type RateCalculator struct {
+	rates *rates.Calculator
+}
+
+// NewRateCalculator creates an empty RateCalculator.
+func NewRateCalculator() *RateCalculator {
+	return &RateCalculator{rates: rates.New()}
+}
+
+// Update records the counters of m and returns the rate since the previous
+// sample of the same device and interface. It reports false for the first
+// sample, after a gap over rates.MaxGap and after a counter reset.
+// m.CounterBits tells a 32-bit wrap from a reset. The same sample seen again,
+// e.g. a cached poll, returns the rate it got the first time; an older one is
+// ignored.
+func (c *RateCalculator) Update(m *mikrotik.InterfaceMetrics) (InterfaceRate, bool) {
+	return c.rates.Update(m.DeviceID+"/"+m.InterfaceName, m.Timestamp, m.BytesIn, m.BytesOut, m.CounterBits)
+}
+
+// Apply sets RxBps and TxBps of m from Update; they stay zero when there is
+// no rate yet.
+func (c *RateCalculator) Apply(m *mikrotik.InterfaceMetrics) bool {
+	rate, ok := c.Update(m)
+	m.RxBps, m.TxBps = rate.RxBps, rate.TxBps
+	return ok
+}
+
+// Len returns the number of interfaces with a stored sample.
+func (c *RateCalculator) Len() int {
+	return c.rates.Len()
+}
@@ -0,0 +1,84 @@
+package monitoring_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+func sample(at time.Time, in, out uint64) *mikrotik.InterfaceMetrics {
+	return &mikrotik.InterfaceMetrics{DeviceID: "dev-1", InterfaceName: "ether1", Timestamp: at, BytesIn: in, BytesOut: out}
+}
+
+func TestRateCalculator_Update(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := monitoring.NewRateCalculator()
+
+	_, ok := c.Update(sample(t0, 1000, 5000))
+	assert.False(t, ok, "no rate for the first sample")
+
+	rate, ok := c.Update(sample(t0.Add(10*time.Second), 2250, 7500))
+	require.True(t, ok)
+	assert.Equal(t, 1000.0, rate.RxBps)
+	assert.Equal(t, 2000.0, rate.TxBps)
+
+	again, ok := c.Update(sample(t0.Add(10*time.Second), 2250, 7500))
+	assert.True(t, ok, "a repeated sample keeps its rate")
+	assert.Equal(t, rate, again)
+
+	other := sample(t0.Add(20*time.Second), 0, 0)
+	other.InterfaceName = "ether2"
+	_, ok = c.Update(other)
+	assert.False(t, ok, "interfaces are tracked separately")
+}
+
+func TestRateCalculator_CounterWrapAndReset(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := monitoring.NewRateCalculator()
+
+	sample32 := func(at time.Time, in uint64) *mikrotik.InterfaceMetrics {
+		m := sample(at, in, 0)
+		m.CounterBits = 32
+		return m
+	}
+
+	c.Update(sample32(t0, math.MaxUint32-999))
+	rate, ok := c.Update(sample32(t0.Add(time.Second), 1000))
+	require.True(t, ok, "a 32-bit counter wraps")
+	assert.Equal(t, 2000.0*8, rate.RxBps)
+
+	_, ok = c.Update(sample(t0.Add(1500*time.Millisecond), 500, 0))
+	assert.False(t, ok, "a small 64-bit counter going down is a reset, not a wrap")
+
+	c.Update(sample(t0.Add(2*time.Second), 1<<40, 0))
+	_, ok = c.Update(sample(t0.Add(3*time.Second), 500, 0))
+	assert.False(t, ok, "a 64-bit counter going down is a reset")
+
+	rate, ok = c.Update(sample(t0.Add(4*time.Second), 1500, 0))
+	require.True(t, ok, "rates resume after a reset")
+	assert.Equal(t, 8000.0, rate.RxBps)
+
+	_, ok = c.Update(sample(t0.Add(time.Hour), 2500, 0))
+	assert.False(t, ok, "no rate across a long gap")
+}
+
+func TestRateCalculator_EvictsIdleInterfaces(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := monitoring.NewRateCalculator()
+
+	gone := sample(t0, 0, 0)
+	gone.InterfaceName = "ether9"
+	c.Update(gone)
+	c.Update(sample(t0, 0, 0))
+	require.Equal(t, 2, c.Len())
+
+	for i := 1; i <= 40; i++ {
+		c.Update(sample(t0.Add(time.Duration(i)*time.Minute), uint64(i), 0))
+	}
+	assert.Equal(t, 1, c.Len(), "an interface that is no longer polled is evicted")
+}
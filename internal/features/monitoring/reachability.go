@@ -0,0 +1,116 @@
+package monitoring
+
+import (
+	"sync"
+	"time"
+)
+
+// Reachability states reported by the inventory summary
+const (
+	ReachabilityReachable   = "reachable"
+	ReachabilityUnreachable = "unreachable"
+	ReachabilityStale       = "stale"
+	ReachabilityUnknown     = "unknown"
+)
+
+// Reachability is the outcome of the most recent poll of a target
+type Reachability struct {
+	Reachable bool
+	CheckedAt time.Time
+	LastError string
+}
+
+// ReachabilityCache holds the latest poll outcome per target IP. The
+// scheduler writes it after every poll, so readers see what the worker last
+// observed rather than a stored status that may be hours old.
+type ReachabilityCache struct {
+	staleAfter time.Duration
+	now        func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]Reachability
+}
+
+// NewReachabilityCache creates a cache whose entries are reported as stale
+// once they are older than staleAfter.
+func NewReachabilityCache(staleAfter time.Duration) *ReachabilityCache {
+	return NewReachabilityCacheForTest(staleAfter, time.Now)
+}
+
+// NewReachabilityCacheForTest creates a cache with a custom clock.
+func NewReachabilityCacheForTest(staleAfter time.Duration, now func() time.Time) *ReachabilityCache {
+	return &ReachabilityCache{
+		staleAfter: staleAfter,
+		now:        now,
+		entries:    make(map[string]Reachability),
+	}
+}
+
+// Record stores the outcome of a poll of ip. pollErr is nil when the device
+// answered.
+func (c *ReachabilityCache) Record(ip string, pollErr error) {
+	r := Reachability{Reachable: pollErr == nil, CheckedAt: c.now()}
+	if pollErr != nil {
+		r.LastError = pollErr.Error()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[ip] = r
+}
+
+// Forget drops the outcomes recorded for ips, e.g. targets removed by a
+// sync, so a target added again is reported as unknown until it is polled.
+func (c *ReachabilityCache) Forget(ips []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ip := range ips {
+		delete(c.entries, ip)
+	}
+}
+
+// InvalidateCache drops the outcome recorded for ip, or every outcome when
+// ip is empty, and returns how many were dropped. Dropped targets are
+// reported as unknown until they are polled again.
+func (c *ReachabilityCache) InvalidateCache(ip string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ip == "" {
+		n := len(c.entries)
+		c.entries = make(map[string]Reachability)
+		return n
+	}
+	if _, ok := c.entries[ip]; !ok {
+		return 0
+	}
+	delete(c.entries, ip)
+	return 1
+}
+
+// Get returns the last recorded outcome for ip and its state: reachable or
+// unreachable when fresh, stale when older than the staleness threshold, or
+// unknown when ip has never been polled.
+func (c *ReachabilityCache) Get(ip string) (Reachability, string) {
+	c.mu.RLock()
+	r, ok := c.entries[ip]
+	c.mu.RUnlock()
+
+	switch {
+	case !ok:
+		return Reachability{}, ReachabilityUnknown
+	case c.now().Sub(r.CheckedAt) > c.staleAfter:
+		return r, ReachabilityStale
+	case r.Reachable:
+		return r, ReachabilityReachable
+	default:
+		return r, ReachabilityUnreachable
+	}
+}
+
+// StaleAfter returns the age beyond which an entry is reported as stale.
+func (c *ReachabilityCache) StaleAfter() time.Duration {
+	return c.staleAfter
+}
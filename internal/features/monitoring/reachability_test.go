@@ -0,0 +1,131 @@
+package monitoring_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newClock() *fakeClock {
+	return &fakeClock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func TestReachabilityCache_FreshAndStale(t *testing.T) {
+	clock := newClock()
+	cache := monitoring.NewReachabilityCacheForTest(3*time.Minute, clock.now)
+
+	_, state := cache.Get("10.0.0.1")
+	assert.Equal(t, monitoring.ReachabilityUnknown, state)
+
+	cache.Record("10.0.0.1", nil)
+	cache.Record("10.0.0.2", errors.New("dial tcp: i/o timeout"))
+
+	r, state := cache.Get("10.0.0.1")
+	assert.Equal(t, monitoring.ReachabilityReachable, state)
+	assert.Equal(t, clock.t, r.CheckedAt)
+
+	r, state = cache.Get("10.0.0.2")
+	assert.Equal(t, monitoring.ReachabilityUnreachable, state)
+	assert.Equal(t, "dial tcp: i/o timeout", r.LastError)
+
+	// At the threshold the entry is still trusted; past it, it is stale.
+	clock.advance(3 * time.Minute)
+	_, state = cache.Get("10.0.0.1")
+	assert.Equal(t, monitoring.ReachabilityReachable, state)
+
+	clock.advance(time.Second)
+	r, state = cache.Get("10.0.0.1")
+	assert.Equal(t, monitoring.ReachabilityStale, state)
+	assert.True(t, r.Reachable, "stale entries keep the last outcome")
+
+	// A new poll makes it fresh again.
+	cache.Record("10.0.0.1", errors.New("connection refused"))
+	_, state = cache.Get("10.0.0.1")
+	assert.Equal(t, monitoring.ReachabilityUnreachable, state)
+}
+
+func TestReachabilityCache_InvalidateCache(t *testing.T) {
+	cache := monitoring.NewReachabilityCacheForTest(3*time.Minute, newClock().now)
+	cache.Record("10.0.0.1", nil)
+	cache.Record("10.0.0.2", nil)
+	cache.Record("10.0.0.3", nil)
+
+	assert.Equal(t, 1, cache.InvalidateCache("10.0.0.1"))
+	assert.Equal(t, 0, cache.InvalidateCache("10.0.0.1"))
+	_, state := cache.Get("10.0.0.1")
+	assert.Equal(t, monitoring.ReachabilityUnknown, state)
+
+	assert.Equal(t, 2, cache.InvalidateCache(""))
+	_, state = cache.Get("10.0.0.2")
+	assert.Equal(t, monitoring.ReachabilityUnknown, state)
+}
+
+func TestGetSummary_ReportsStaleness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clock := newClock()
+	cache := monitoring.NewReachabilityCacheForTest(3*time.Minute, clock.now)
+	store := monitoring.NewTargetStore()
+	store.ReplaceAll([]monitoring.DeviceTarget{
+		{IP: "10.0.0.1", Driver: "mikrotik"},
+		{IP: "10.0.0.2", Driver: "mikrotik"},
+		{IP: "10.0.0.3", Driver: "mikrotik"},
+		{IP: "10.0.0.4", Driver: "mikrotik"},
+	})
+
+	cache.Record("10.0.0.3", nil) // polled long ago
+	clock.advance(10 * time.Minute)
+	cache.Record("10.0.0.1", nil)
+	cache.Record("10.0.0.2", errors.New("i/o timeout"))
+	clock.advance(30 * time.Second)
+
+	r := gin.New()
+	r.GET("/inventory/summary", monitoring.NewHandler(store, cache).GetSummary)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/inventory/summary", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp monitoring.SummaryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, 4, resp.Total)
+	assert.Equal(t, 1, resp.Reachable)
+	assert.Equal(t, 1, resp.Unreachable)
+	assert.Equal(t, 1, resp.Stale)
+	assert.Equal(t, 1, resp.Unknown)
+	assert.Equal(t, int64(180), resp.StaleAfterSeconds)
+
+	require.Len(t, resp.Targets, 4)
+	fresh, down, stale, unknown := resp.Targets[0], resp.Targets[1], resp.Targets[2], resp.Targets[3]
+
+	assert.Equal(t, monitoring.ReachabilityReachable, fresh.State)
+	assert.Equal(t, int64(30), fresh.AgeSeconds)
+
+	assert.Equal(t, monitoring.ReachabilityUnreachable, down.State)
+	assert.Equal(t, "i/o timeout", down.LastError)
+
+	assert.Equal(t, "10.0.0.3", stale.IP)
+	assert.Equal(t, monitoring.ReachabilityStale, stale.State)
+	assert.Equal(t, int64(630), stale.AgeSeconds)
+	require.NotNil(t, stale.LastReachable)
+	assert.True(t, *stale.LastReachable)
+
+	assert.Equal(t, monitoring.ReachabilityUnknown, unknown.State)
+	assert.Nil(t, unknown.CheckedAt)
+	assert.Nil(t, unknown.LastReachable)
+}
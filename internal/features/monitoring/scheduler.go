@@ -5,63 +5,144 @@ import (
 	"log"
 	"sync"
 	"time"
+
+	"github.com/yourorg/nms-go/internal/common/telemetry"
 )
 
+// schedulerName labels this scheduler's collection cycle metrics.
+const schedulerName = "monitoring"
+
+// PollFunc polls one target and writes its metrics to writer.
+type PollFunc func(ctx context.Context, target DeviceTarget, writer MetricWriter) error
+
 type Scheduler struct {
-	store  *TargetStore
-	writer MetricWriter
-	ticker *time.Ticker
-	quit   chan struct{}
-	wg     sync.WaitGroup
+	store        *TargetStore
+	writer       MetricWriter
+	reachability *ReachabilityCache
+	poll         PollFunc
+	ticker       *time.Ticker
+	quit         chan struct{}
+	done         chan struct{}
+
+	budget        time.Duration
+	skipOnOverrun bool
+}
+
+// NewScheduler creates a Scheduler. When reachability is non-nil, the outcome
+// of every poll is recorded in it.
+func NewScheduler(store *TargetStore, writer MetricWriter, reachability *ReachabilityCache) *Scheduler {
+	return NewSchedulerForTest(store, writer, reachability, PollDevice)
 }
 
-func NewScheduler(store *TargetStore, writer MetricWriter) *Scheduler {
+// NewSchedulerForTest creates a Scheduler that polls targets with poll
+// instead of connecting to them.
+func NewSchedulerForTest(store *TargetStore, writer MetricWriter, reachability *ReachabilityCache, poll PollFunc) *Scheduler {
 	return &Scheduler{
-		store:  store,
-		writer: writer,
-		quit:   make(chan struct{}),
+		store:        store,
+		writer:       writer,
+		reachability: reachability,
+		poll:         poll,
+		quit:         make(chan struct{}),
 	}
 }
 
+// SetCycleBudget sets how long a collection cycle may take before it is
+// reported as an overrun; zero (the default) uses the interval. With
+// skipOnOverrun, the tick that queued up during an overrun is dropped so the
+// next cycle waits for the following tick. Call it before Start.
+func (s *Scheduler) SetCycleBudget(budget time.Duration, skipOnOverrun bool) {
+	s.budget = budget
+	s.skipOnOverrun = skipOnOverrun
+}
+
 func (s *Scheduler) Start(interval time.Duration) {
+	budget := s.budget
+	if budget <= 0 {
+		budget = interval
+	}
+
 	s.ticker = time.NewTicker(interval)
+	s.done = make(chan struct{})
 	go func() {
+		defer close(s.done)
 		for {
 			select {
 			case <-s.ticker.C:
-				s.runCollection()
+				if s.runCycle(budget) && s.skipOnOverrun {
+					s.skipMissedTick()
+				}
 			case <-s.quit:
 				s.ticker.Stop()
 				return
 			}
 		}
 	}()
-	log.Printf("Monitoring Scheduler started with interval %v", interval)
+	log.Printf("Monitoring Scheduler started with interval %v (cycle budget %v)", interval, budget)
 }
 
+// Stop stops the scheduler, waiting for a cycle in progress to finish.
 func (s *Scheduler) Stop() {
 	close(s.quit)
-	s.wg.Wait()
+	if s.done != nil {
+		<-s.done
+	}
 	s.writer.Close()
 	log.Println("Monitoring Scheduler stopped")
 }
 
-func (s *Scheduler) runCollection() {
+// runCycle runs one collection and reports whether it took longer than
+// budget.
+func (s *Scheduler) runCycle(budget time.Duration) bool {
+	start := time.Now()
+	targets := s.runCollection()
+	elapsed := time.Since(start)
+
+	telemetry.CycleDuration.WithLabelValues(schedulerName).Observe(elapsed.Seconds())
+	if elapsed <= budget {
+		return false
+	}
+
+	telemetry.CycleOverrunsTotal.WithLabelValues(schedulerName).Inc()
+	log.Printf("WARNING: collection cycle for %d devices took %v, over its %v budget", targets, elapsed.Round(time.Millisecond), budget)
+	return true
+}
+
+// skipMissedTick drops a tick that fired while the last cycle was running.
+func (s *Scheduler) skipMissedTick() {
+	select {
+	case <-s.ticker.C:
+		log.Println("Skipping the collection tick missed during the overrun")
+	default:
+	}
+}
+
+// runCollection polls every target concurrently and waits for all of them.
+// It returns the number of targets polled.
+func (s *Scheduler) runCollection() int {
 	targets := s.store.GetAll()
 	log.Printf("Starting collection for %d devices", len(targets))
 
+	var wg sync.WaitGroup
 	for _, target := range targets {
-		s.wg.Add(1)
+		wg.Add(1)
 		go func(t DeviceTarget) {
-			defer s.wg.Done()
+			defer wg.Done()
 
 			// Context with timeout for every poll
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 
-			if err := PollDevice(ctx, t, s.writer); err != nil {
+			err := s.poll(ctx, t, s.writer)
+			if err != nil {
 				log.Printf("Failed to poll %s: %v", t.IP, err)
 			}
+			s.store.RecordPoll(t, time.Now(), err)
+			if s.reachability != nil {
+				s.reachability.Record(t.IP, err)
+			}
 		}(target)
 	}
+	wg.Wait()
+
+	return len(targets)
 }
@@ -0,0 +1,116 @@
+package monitoring_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+type nopWriter struct{}
+
+func (nopWriter) WriteSystemMetrics(*mikrotik.SystemMetrics)         {}
+func (nopWriter) WriteInterfaceMetrics([]*mikrotik.InterfaceMetrics) {}
+func (nopWriter) Close()                                             {}
+
+func overruns() float64 {
+	return testutil.ToFloat64(telemetry.CycleOverrunsTotal.WithLabelValues("monitoring"))
+}
+
+func schedulerStore() *monitoring.TargetStore {
+	store := monitoring.NewTargetStore()
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.1"})
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.2"})
+	return store
+}
+
+func TestScheduler_OverrunningCycleIsCounted(t *testing.T) {
+	before := overruns()
+
+	var polls atomic.Int32
+	poll := func(ctx context.Context, target monitoring.DeviceTarget, w monitoring.MetricWriter) error {
+		polls.Add(1)
+		time.Sleep(60 * time.Millisecond) // three intervals
+		return nil
+	}
+	reachability := monitoring.NewReachabilityCache(time.Minute)
+	s := monitoring.NewSchedulerForTest(schedulerStore(), nopWriter{}, reachability, poll)
+	s.SetCycleBudget(0, true)
+
+	s.Start(20 * time.Millisecond)
+	require.Eventually(t, func() bool { return overruns() > before }, 2*time.Second, 5*time.Millisecond)
+	s.Stop()
+
+	assert.GreaterOrEqual(t, polls.Load(), int32(2), "the cycle waits for every target")
+	_, state := reachability.Get("10.0.0.2")
+	assert.Equal(t, monitoring.ReachabilityReachable, state)
+}
+
+func TestScheduler_CycleWithinBudget(t *testing.T) {
+	before := overruns()
+
+	cycles := make(chan struct{}, 16)
+	poll := func(ctx context.Context, target monitoring.DeviceTarget, w monitoring.MetricWriter) error {
+		if target.IP == "10.0.0.1" {
+			cycles <- struct{}{}
+		}
+		return nil
+	}
+	s := monitoring.NewSchedulerForTest(schedulerStore(), nopWriter{}, nil, poll)
+	s.SetCycleBudget(time.Minute, false)
+
+	s.Start(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-cycles:
+		case <-time.After(2 * time.Second):
+			t.Fatal("collection cycle did not run")
+		}
+	}
+	s.Stop()
+
+	assert.Equal(t, before, overruns())
+}
+
+func TestScheduler_SyncKeepsStateOfUnchangedTargets(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	targets := []monitoring.DeviceTarget{
+		{IP: "10.0.0.1", Username: "admin"},
+		{IP: "10.0.0.2", Username: "admin"},
+	}
+	store.ReplaceAll(targets)
+
+	poll := func(ctx context.Context, target monitoring.DeviceTarget, w monitoring.MetricWriter) error {
+		if target.IP == "10.0.0.2" {
+			return errors.New("i/o timeout")
+		}
+		return nil
+	}
+	s := monitoring.NewSchedulerForTest(store, nopWriter{}, nil, poll)
+	s.Start(5 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		state, _ := store.State("10.0.0.2")
+		return state.ConsecutiveFailures >= 2
+	}, 2*time.Second, 5*time.Millisecond)
+	s.Stop()
+
+	before1, _ := store.State("10.0.0.1")
+	before2, _ := store.State("10.0.0.2")
+	require.False(t, before1.LastPolledAt.IsZero())
+
+	result := store.ReplaceAll(append(targets, monitoring.DeviceTarget{IP: "10.0.0.3"}))
+	assert.Equal(t, 2, result.Unchanged)
+
+	after1, _ := store.State("10.0.0.1")
+	after2, _ := store.State("10.0.0.2")
+	assert.Equal(t, before1, after1)
+	assert.Equal(t, before2, after2)
+}
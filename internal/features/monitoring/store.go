@@ -1,40 +1,167 @@
 package monitoring
 
 import (
+	"sort"
 	"sync"
+	"time"
 )
 
+// TargetState is the scheduling state of a target, kept across syncs while
+// the target is unchanged
+type TargetState struct {
+	// LastPolledAt is when the last poll of the target finished
+	LastPolledAt time.Time
+	// ConsecutiveFailures counts the polls that failed since the last success
+	ConsecutiveFailures int
+}
+
+type targetEntry struct {
+	target DeviceTarget
+	state  TargetState
+}
+
 // TargetStore manages the in-memory list of devices to poll
 type TargetStore struct {
 	mu      sync.RWMutex
-	targets map[string]DeviceTarget
+	targets map[string]targetEntry
 }
 
 func NewTargetStore() *TargetStore {
 	return &TargetStore{
-		targets: make(map[string]DeviceTarget),
+		targets: make(map[string]targetEntry),
 	}
 }
 
-// ReplaceAll replaces the entire store with new targets (Full Sync)
-func (s *TargetStore) ReplaceAll(newTargets []DeviceTarget) {
+// SyncResult is the difference a ReplaceAll made to the store, by IP
+type SyncResult struct {
+	Added   []string
+	Updated []string
+	Removed []string
+	// Unchanged counts targets that were kept as they were
+	Unchanged int
+}
+
+// ReplaceAll makes newTargets the store's targets (Full Sync) and reports
+// what changed. Only the difference is applied, under one lock so readers
+// see either the old or the new targets: unchanged targets keep their state,
+// updated ones start over, and removed ones lose it, so a target removed and
+// added again starts fresh. Of several targets with one IP the last is kept.
+func (s *TargetStore) ReplaceAll(newTargets []DeviceTarget) SyncResult {
+	next := make(map[string]DeviceTarget, len(newTargets))
+	for _, t := range newTargets {
+		next[t.IP] = t
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.targets = make(map[string]DeviceTarget)
-	for _, t := range newTargets {
-		s.targets[t.IP] = t
+	var result SyncResult
+	for ip, t := range next {
+		old, ok := s.targets[ip]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, ip)
+		case old.target != t:
+			result.Updated = append(result.Updated, ip)
+		default:
+			result.Unchanged++
+			continue
+		}
+		s.targets[ip] = targetEntry{target: t}
+	}
+	for ip := range s.targets {
+		if _, ok := next[ip]; !ok {
+			result.Removed = append(result.Removed, ip)
+			delete(s.targets, ip)
+		}
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Updated)
+	sort.Strings(result.Removed)
+
+	return result
+}
+
+// Upsert adds a target or replaces the existing target with the same IP. The
+// state is kept only when the target is unchanged.
+func (s *TargetStore) Upsert(target DeviceTarget) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.targets[target.IP]; ok && old.target == target {
+		return
 	}
+	s.targets[target.IP] = targetEntry{target: target}
 }
 
-// GetAll returns a copy of all targets
+// Remove deletes the target with the given IP, reporting whether it existed
+func (s *TargetStore) Remove(ip string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.targets[ip]; !ok {
+		return false
+	}
+	delete(s.targets, ip)
+	return true
+}
+
+// RecordPoll updates the state of target after a poll that finished at at.
+// pollErr is nil when the poll succeeded. A poll of a target that was removed
+// or updated while it ran is ignored, so it does not leak into the state of
+// the target that replaced it.
+func (s *TargetStore) RecordPoll(target DeviceTarget, at time.Time, pollErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.targets[target.IP]
+	if !ok || entry.target != target {
+		return
+	}
+	entry.state.LastPolledAt = at
+	if pollErr != nil {
+		entry.state.ConsecutiveFailures++
+	} else {
+		entry.state.ConsecutiveFailures = 0
+	}
+	s.targets[target.IP] = entry
+}
+
+// GetAll returns a snapshot of all targets. The slice is a copy, so callers
+// may iterate or modify it while the store is being updated.
 func (s *TargetStore) GetAll() []DeviceTarget {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	result := make([]DeviceTarget, 0, len(s.targets))
-	for _, t := range s.targets {
-		result = append(result, t)
+	for _, e := range s.targets {
+		result = append(result, e.target)
 	}
 	return result
 }
+
+// Get returns the target with the given IP
+func (s *TargetStore) Get(ip string) (DeviceTarget, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.targets[ip]
+	return e.target, ok
+}
+
+// State returns the scheduling state of the target with the given IP
+func (s *TargetStore) State(ip string) (TargetState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.targets[ip]
+	return e.state, ok
+}
+
+// Len returns the number of targets
+func (s *TargetStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.targets)
+}
@@ -0,0 +1,203 @@
+package monitoring_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+)
+
+func TestTargetStore_UpsertAndRemove(t *testing.T) {
+	store := monitoring.NewTargetStore()
+
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.1", Username: "admin"})
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.1", Username: "operator"})
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.2"})
+
+	targets := store.GetAll()
+	assert.Len(t, targets, 2)
+
+	assert.True(t, store.Remove("10.0.0.2"))
+	assert.False(t, store.Remove("10.0.0.2"))
+
+	targets = store.GetAll()
+	assert.Len(t, targets, 1)
+	assert.Equal(t, "operator", targets[0].Username)
+}
+
+func TestTargetStore_GetAllReturnsCopy(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.1", Username: "admin"})
+
+	targets := store.GetAll()
+	targets[0].Username = "mutated"
+
+	assert.Equal(t, "admin", store.GetAll()[0].Username)
+}
+
+func TestTargetStore_GetAndLen(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	assert.Equal(t, 0, store.Len())
+
+	store.ReplaceAll([]monitoring.DeviceTarget{
+		{IP: "10.0.0.1", Driver: "mikrotik"},
+		{IP: "10.0.0.2", Driver: "mikrotik"},
+	})
+	assert.Equal(t, 2, store.Len())
+
+	target, ok := store.Get("10.0.0.1")
+	assert.True(t, ok)
+	assert.Equal(t, "mikrotik", target.Driver)
+
+	_, ok = store.Get("10.0.0.3")
+	assert.False(t, ok)
+}
+
+// Run with -race to detect unsynchronised access.
+func TestTargetStore_ConcurrentAccess(t *testing.T) {
+	store := monitoring.NewTargetStore()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				store.Upsert(monitoring.DeviceTarget{IP: fmt.Sprintf("10.%d.0.%d", w, i)})
+				if i%10 == 0 {
+					store.Remove(fmt.Sprintf("10.%d.0.%d", w, i-5))
+				}
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = store.GetAll()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.NotEmpty(t, store.GetAll())
+}
+
+// Iterating a snapshot must not race with writers replacing the store.
+func TestTargetStore_ModifyDuringIteration(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	initial := make([]monitoring.DeviceTarget, 50)
+	for i := range initial {
+		initial[i] = monitoring.DeviceTarget{IP: fmt.Sprintf("10.0.0.%d", i)}
+	}
+	store.ReplaceAll(initial)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			store.ReplaceAll(initial[:i%len(initial)])
+			store.Upsert(monitoring.DeviceTarget{IP: "10.0.1.1"})
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		snapshot := store.GetAll()
+		for _, target := range snapshot {
+			_, _ = store.Get(target.IP)
+			_ = store.Len()
+		}
+		assert.NotContains(t, snapshot, monitoring.DeviceTarget{})
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestTargetStore_ReplaceAllReportsDiff(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	first := store.ReplaceAll([]monitoring.DeviceTarget{
+		{IP: "10.0.0.1", Username: "admin"},
+		{IP: "10.0.0.2", Username: "admin"},
+	})
+	assert.Equal(t, monitoring.SyncResult{Added: []string{"10.0.0.1", "10.0.0.2"}}, first)
+
+	second := store.ReplaceAll([]monitoring.DeviceTarget{
+		{IP: "10.0.0.2", Username: "operator"},
+		{IP: "10.0.0.3", Username: "admin"},
+		{IP: "10.0.0.1", Username: "admin"},
+	})
+	assert.Equal(t, monitoring.SyncResult{
+		Added:     []string{"10.0.0.3"},
+		Updated:   []string{"10.0.0.2"},
+		Unchanged: 1,
+	}, second)
+
+	third := store.ReplaceAll(nil)
+	assert.Equal(t, monitoring.SyncResult{Removed: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}}, third)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestTargetStore_SyncKeepsStateOfUnchangedTargets(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	unchanged := monitoring.DeviceTarget{IP: "10.0.0.1", Username: "admin"}
+	updated := monitoring.DeviceTarget{IP: "10.0.0.2", Username: "admin"}
+	removed := monitoring.DeviceTarget{IP: "10.0.0.3", Username: "admin"}
+	store.ReplaceAll([]monitoring.DeviceTarget{unchanged, updated, removed})
+
+	polledAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, target := range []monitoring.DeviceTarget{unchanged, updated, removed} {
+		store.RecordPoll(target, polledAt, errors.New("i/o timeout"))
+		store.RecordPoll(target, polledAt, errors.New("i/o timeout"))
+	}
+
+	store.ReplaceAll([]monitoring.DeviceTarget{
+		unchanged,
+		{IP: "10.0.0.2", Username: "operator"},
+	})
+
+	state, ok := store.State("10.0.0.1")
+	require.True(t, ok)
+	assert.Equal(t, monitoring.TargetState{LastPolledAt: polledAt, ConsecutiveFailures: 2}, state)
+
+	state, ok = store.State("10.0.0.2")
+	require.True(t, ok)
+	assert.Equal(t, monitoring.TargetState{}, state, "new credentials start over")
+
+	// A target removed and added again starts fresh.
+	store.Upsert(removed)
+	state, _ = store.State("10.0.0.3")
+	assert.Equal(t, monitoring.TargetState{}, state)
+}
+
+func TestTargetStore_RecordPollIgnoresReplacedTargets(t *testing.T) {
+	store := monitoring.NewTargetStore()
+	old := monitoring.DeviceTarget{IP: "10.0.0.1", Username: "admin"}
+	store.Upsert(old)
+
+	// The poll of old finishes after a sync changed its credentials.
+	store.Upsert(monitoring.DeviceTarget{IP: "10.0.0.1", Username: "operator"})
+	store.RecordPoll(old, time.Now(), errors.New("authentication failed"))
+	// And after another sync removed it.
+	store.RecordPoll(monitoring.DeviceTarget{IP: "10.0.0.9"}, time.Now(), nil)
+
+	state, _ := store.State("10.0.0.1")
+	assert.Equal(t, monitoring.TargetState{}, state)
+	_, ok := store.State("10.0.0.9")
+	assert.False(t, ok)
+
+	success := monitoring.DeviceTarget{IP: "10.0.0.1", Username: "operator"}
+	store.RecordPoll(success, time.Now(), errors.New("i/o timeout"))
+	store.RecordPoll(success, time.Now(), nil)
+	state, _ = store.State("10.0.0.1")
+	assert.Zero(t, state.ConsecutiveFailures, "a success resets the failure count")
+}
@@ -2,14 +2,67 @@ package monitoring
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/yourorg/nms-go/internal/common/telemetry"
 	"github.com/yourorg/nms-go/internal/device/model"
 	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
 )
 
+// MetricsClient is the subset of a protocol client used to collect device metrics
+type MetricsClient interface {
+	Connect(ctx context.Context, device *model.Device) error
+	Disconnect() error
+	GetSystemMetrics(ctx context.Context) (*mikrotik.SystemMetrics, error)
+	GetInterfaceMetrics(ctx context.Context) ([]*mikrotik.InterfaceMetrics, error)
+}
+
+// ClientFactory returns a MetricsClient able to talk the given protocol
+type ClientFactory func(protocol model.Protocol) (MetricsClient, error)
+
+// ErrUnsupportedProtocol is returned when no metrics client exists for a device protocol
+var ErrUnsupportedProtocol = errors.New("protocol not supported for metrics collection")
+
+// DefaultClientFactory creates clients for the protocols that support metrics collection
+func DefaultClientFactory(protocol model.Protocol) (MetricsClient, error) {
+	switch protocol {
+	case model.ProtocolMikrotikAPI:
+		return mikrotik.NewMikrotikClient(10 * time.Second), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProtocol, protocol)
+	}
+}
+
+// collectedMetrics holds the result of a single collection pass.
+// A failure of one metric group does not discard the other.
+type collectedMetrics struct {
+	System       *mikrotik.SystemMetrics
+	Interfaces   []*mikrotik.InterfaceMetrics
+	SystemErr    error
+	InterfaceErr error
+}
+
+// collect connects to the device and gathers system and interface metrics
+func collect(ctx context.Context, client MetricsClient, device *model.Device) (*collectedMetrics, error) {
+	if err := client.Connect(ctx, device); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", device.IPAddress, err)
+	}
+	defer telemetry.Disconnect(client, string(device.Protocol), device.IPAddress)
+
+	result := &collectedMetrics{}
+
+	// 1. Get System Metrics
+	result.System, result.SystemErr = client.GetSystemMetrics(ctx)
+
+	// 2. Get Interface Metrics
+	result.Interfaces, result.InterfaceErr = client.GetInterfaceMetrics(ctx)
+
+	return result, nil
+}
+
 // PollDevice connects to a device, gathers metrics, and writes them
 func PollDevice(ctx context.Context, target DeviceTarget, writer MetricWriter) error {
 	// Construct temporary device model
@@ -23,26 +76,26 @@ func PollDevice(ctx context.Context, target DeviceTarget, writer MetricWriter) e
 		},
 	}
 
-	client := mikrotik.NewMikrotikClient(10 * time.Second)
-	if err := client.Connect(ctx, device); err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", target.IP, err)
+	client, err := DefaultClientFactory(device.Protocol)
+	if err != nil {
+		return err
 	}
-	defer client.Disconnect()
 
-	// 1. Get System Metrics
-	sysMetrics, err := client.GetSystemMetrics(ctx)
+	result, err := collect(ctx, client, device)
 	if err != nil {
-		log.Printf("Error collecting system metrics for %s: %v", target.IP, err)
+		return err
+	}
+
+	if result.SystemErr != nil {
+		log.Printf("Error collecting system metrics for %s: %v", target.IP, result.SystemErr)
 	} else {
-		writer.WriteSystemMetrics(sysMetrics)
+		writer.WriteSystemMetrics(result.System)
 	}
 
-	// 2. Get Interface Metrics
-	ifMetrics, err := client.GetInterfaceMetrics(ctx)
-	if err != nil {
-		log.Printf("Error collecting interface metrics for %s: %v", target.IP, err)
+	if result.InterfaceErr != nil {
+		log.Printf("Error collecting interface metrics for %s: %v", target.IP, result.InterfaceErr)
 	} else {
-		writer.WriteInterfaceMetrics(ifMetrics)
+		writer.WriteInterfaceMetrics(result.Interfaces)
 	}
 
 	return nil
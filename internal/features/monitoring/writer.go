@@ -1,10 +1,11 @@
 package monitoring
 
 import (
+	"context"
+	"log"
 	"time"
 
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
-	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/yourorg/nms-go/internal/common/sink"
 	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
 )
 
@@ -15,45 +16,63 @@ type MetricWriter interface {
 	Close()
 }
 
-type InfluxDBWriter struct {
-	client   influxdb2.Client
-	writeAPI api.WriteAPI
+// SinkWriter is a MetricWriter that forwards metrics to a backend-agnostic MetricSink.
+// Interface metrics also get rx_bps and tx_bps computed from the byte
+// counters of the previous write.
+type SinkWriter struct {
+	sink  sink.MetricSink
+	rates *RateCalculator
 }
 
-func NewInfluxDBWriter(url, token, org, bucket string) *InfluxDBWriter {
-	client := influxdb2.NewClient(url, token)
-	writeAPI := client.WriteAPI(org, bucket)
-
-	return &InfluxDBWriter{
-		client:   client,
-		writeAPI: writeAPI,
-	}
+func NewSinkWriter(s sink.MetricSink) *SinkWriter {
+	return &SinkWriter{sink: s, rates: NewRateCalculator()}
 }
 
-func (w *InfluxDBWriter) WriteSystemMetrics(m *mikrotik.SystemMetrics) {
-	p := influxdb2.NewPointWithMeasurement("system_metrics").
-		AddTag("device_id", m.DeviceID).
-		AddField("cpu_usage", m.CPUUsage).
-		AddField("memory_usage", m.MemoryUsage).
-		AddField("uptime", m.Uptime).
-		SetTime(time.Now())
-
-	w.writeAPI.WritePoint(p)
+func (w *SinkWriter) WriteSystemMetrics(m *mikrotik.SystemMetrics) {
+	err := w.sink.Write(context.Background(), "system_metrics",
+		map[string]string{
+			"device_id": m.DeviceID,
+		},
+		map[string]interface{}{
+			"cpu_usage":    m.CPUUsage,
+			"memory_usage": m.MemoryUsage,
+			"uptime":       m.Uptime,
+		},
+		time.Now(),
+	)
+	if err != nil {
+		log.Printf("Error writing system metrics for %s: %v", m.DeviceID, err)
+	}
 }
 
-func (w *InfluxDBWriter) WriteInterfaceMetrics(metrics []*mikrotik.InterfaceMetrics) {
+func (w *SinkWriter) WriteInterfaceMetrics(metrics []*mikrotik.InterfaceMetrics) {
 	for _, m := range metrics {
-		p := influxdb2.NewPointWithMeasurement("interface_metrics").
-			AddTag("device_id", m.DeviceID).
-			AddTag("interface", m.InterfaceName).
-			AddField("bytes_in", m.BytesIn).
-			AddField("bytes_out", m.BytesOut).
-			SetTime(time.Now())
-
-		w.writeAPI.WritePoint(p)
+		fields := map[string]interface{}{
+			"bytes_in":  m.BytesIn,
+			"bytes_out": m.BytesOut,
+		}
+		// No rate for the first sample or after a counter reset
+		if w.rates.Apply(m) {
+			fields["rx_bps"] = m.RxBps
+			fields["tx_bps"] = m.TxBps
+		}
+
+		err := w.sink.Write(context.Background(), "interface_metrics",
+			map[string]string{
+				"device_id": m.DeviceID,
+				"interface": m.InterfaceName,
+			},
+			fields,
+			time.Now(),
+		)
+		if err != nil {
+			log.Printf("Error writing interface metrics for %s/%s: %v", m.DeviceID, m.InterfaceName, err)
+		}
 	}
 }
 
-func (w *InfluxDBWriter) Close() {
-	w.client.Close()
+func (w *SinkWriter) Close() {
+	if err := w.sink.Close(); err != nil {
+		log.Printf("Error closing metric sink: %v", err)
+	}
 }
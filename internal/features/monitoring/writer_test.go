@@ -0,0 +1,96 @@
+package monitoring_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/features/monitoring"
+	"github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+type writtenPoint struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]interface{}
+}
+
+// fakeSink records every point written to it.
+type fakeSink struct {
+	points []writtenPoint
+	err    error
+	closed bool
+}
+
+func (f *fakeSink) Write(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	f.points = append(f.points, writtenPoint{measurement: measurement, tags: tags, fields: fields})
+	return f.err
+}
+
+func (f *fakeSink) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestSinkWriter_WriteSystemMetrics(t *testing.T) {
+	fs := &fakeSink{}
+	w := monitoring.NewSinkWriter(fs)
+
+	w.WriteSystemMetrics(&mikrotik.SystemMetrics{
+		DeviceID:    "10.0.0.1",
+		CPUUsage:    12.5,
+		MemoryUsage: 40,
+		Uptime:      3600,
+	})
+
+	require.Len(t, fs.points, 1)
+	p := fs.points[0]
+	assert.Equal(t, "system_metrics", p.measurement)
+	assert.Equal(t, map[string]string{"device_id": "10.0.0.1"}, p.tags)
+	assert.Equal(t, 12.5, p.fields["cpu_usage"])
+	assert.Equal(t, 40.0, p.fields["memory_usage"])
+	assert.Equal(t, int64(3600), p.fields["uptime"])
+}
+
+func TestSinkWriter_WriteInterfaceMetrics(t *testing.T) {
+	fs := &fakeSink{err: errors.New("backend unavailable")}
+	w := monitoring.NewSinkWriter(fs)
+
+	w.WriteInterfaceMetrics([]*mikrotik.InterfaceMetrics{
+		{DeviceID: "10.0.0.1", InterfaceName: "ether1", BytesIn: 100, BytesOut: 200},
+		{DeviceID: "10.0.0.1", InterfaceName: "ether2", BytesIn: 300, BytesOut: 400},
+	})
+
+	// A failing write must not stop the remaining points from being attempted.
+	require.Len(t, fs.points, 2)
+	assert.Equal(t, "interface_metrics", fs.points[1].measurement)
+	assert.Equal(t, "ether2", fs.points[1].tags["interface"])
+	assert.Equal(t, uint64(400), fs.points[1].fields["bytes_out"])
+}
+
+func TestSinkWriter_WriteInterfaceMetrics_Rates(t *testing.T) {
+	fs := &fakeSink{}
+	w := monitoring.NewSinkWriter(fs)
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	w.WriteInterfaceMetrics([]*mikrotik.InterfaceMetrics{
+		{DeviceID: "10.0.0.1", InterfaceName: "ether1", Timestamp: t0, BytesIn: 1000, BytesOut: 1000},
+	})
+	second := &mikrotik.InterfaceMetrics{DeviceID: "10.0.0.1", InterfaceName: "ether1", Timestamp: t0.Add(10 * time.Second), BytesIn: 2000, BytesOut: 6000}
+	w.WriteInterfaceMetrics([]*mikrotik.InterfaceMetrics{second})
+
+	require.Len(t, fs.points, 2)
+	assert.NotContains(t, fs.points[0].fields, "rx_bps", "no rate for the first sample")
+	assert.Equal(t, 800.0, fs.points[1].fields["rx_bps"])
+	assert.Equal(t, 4000.0, fs.points[1].fields["tx_bps"])
+	assert.Equal(t, 4000.0, second.TxBps)
+}
+
+func TestSinkWriter_CloseClosesSink(t *testing.T) {
+	fs := &fakeSink{}
+	monitoring.NewSinkWriter(fs).Close()
+	assert.True(t, fs.closed)
+}
@@ -0,0 +1,41 @@
+package olt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
+)
+
+// Capabilities returns the metrics collected from OLTs of the given vendor
+// and the OIDs they are read from. Only ZTE is supported; other vendors
+// return ErrUnsupportedVendor.
+func Capabilities(vendor string) (*CapabilitiesResponse, error) {
+	v := Vendor(strings.ToLower(strings.TrimSpace(vendor)))
+	if v != VendorZTE {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVendor, vendor)
+	}
+
+	groups := zte.Capabilities()
+	resp := &CapabilitiesResponse{
+		Vendor: string(v),
+		Groups: make([]CapabilityGroup, 0, len(groups)),
+	}
+	for _, g := range groups {
+		group := CapabilityGroup{
+			Name:     g.Name,
+			Metrics:  make([]CapabilityMetric, 0, len(g.Metrics)),
+			Includes: g.Includes,
+		}
+		for _, m := range g.Metrics {
+			group.Metrics = append(group.Metrics, CapabilityMetric{
+				Name:  m.Name,
+				OID:   m.OID,
+				Table: m.Table,
+				Unit:  m.Unit,
+			})
+		}
+		resp.Groups = append(resp.Groups, group)
+	}
+	return resp, nil
+}
@@ -0,0 +1,56 @@
+package olt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	devicemodel "github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+)
+
+// CredentialStore resolves a registered device, with its credentials loaded,
+// by ID. The device repository implements it.
+type CredentialStore interface {
+	GetByID(ctx context.Context, id string) (*devicemodel.Device, error)
+}
+
+// ErrDeviceNotFound is returned when a target's device_id cannot be resolved.
+var ErrDeviceNotFound = errors.New("device not found")
+
+// ErrNoStoredCredentials is returned when a target's device has no SNMP
+// community stored.
+var ErrNoStoredCredentials = errors.New("no SNMP credentials stored for device")
+
+// resolveCredentials replaces the community and version in target with the
+// ones stored for target.DeviceID. Targets without a device ID, or any target
+// when no credential store is configured, are returned unchanged.
+func (s *oltService) resolveCredentials(ctx context.Context, target SNMPTarget) (SNMPTarget, error) {
+	if target.DeviceID == "" || s.credentials == nil {
+		return target, nil
+	}
+
+	device, err := s.credentials.GetByID(ctx, target.DeviceID)
+	if errors.Is(err, repository.ErrDeviceNotFound) {
+		return target, fmt.Errorf("%w: %s", ErrDeviceNotFound, target.DeviceID)
+	}
+	if err != nil {
+		return target, fmt.Errorf("failed to look up device %s: %w", target.DeviceID, err)
+	}
+	// Stored credentials are only used for the device they belong to.
+	if device.IPAddress != target.IP {
+		return target, fmt.Errorf("%w: device %s has IP %s, not %s", ErrInvalidOverride, target.DeviceID, device.IPAddress, target.IP)
+	}
+	if device.Credentials == nil || device.Credentials.SNMPCommunity == "" {
+		return target, fmt.Errorf("%w: %s", ErrNoStoredCredentials, target.DeviceID)
+	}
+
+	community, err := crypto.Decrypt(device.Credentials.SNMPCommunity)
+	if err != nil {
+		return target, fmt.Errorf("failed to decrypt stored credentials of %s: %w", target.DeviceID, err)
+	}
+	target.Community = community
+	target.Version = device.Credentials.SNMPVersion
+	return target, nil
+}
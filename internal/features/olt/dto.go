@@ -24,6 +24,26 @@ type SNMPTarget struct {
 
 	// Port is the SNMP UDP port (default: 161).
 	Port uint16 `json:"port"`
+
+	// Timeout overrides the configured SNMP timeout for this request, as a
+	// duration such as "30s" (1s to 5m). It can also be set with the
+	// X-SNMP-Timeout header.
+	Timeout string `json:"timeout"`
+
+	// Retries overrides the number of SNMP retries for this request (0 to 5,
+	// default 2). It can also be set with the X-SNMP-Retries header.
+	Retries *int `json:"retries"`
+
+	// MaxRepetitions overrides the GETBULK max-repetitions used by table
+	// walks (1 to 50, default 10). Lower it for OLTs that drop responses to
+	// large bulk requests.
+	MaxRepetitions *int `json:"max_repetitions"`
+
+	// DeviceID optionally names a device registered in go-nms. When the OLT
+	// credential store is enabled (olt.stored_credentials), the community and
+	// version stored for that device replace the ones in the body; the device
+	// must have the same IP. Otherwise it is ignored.
+	DeviceID string `json:"device_id"`
 }
 
 // GetSystemMetricsRequest is the request body for POST /api/v1/olt/system.
@@ -36,6 +56,15 @@ type GetPONPortsRequest struct {
 	Target SNMPTarget `json:"target" binding:"required"`
 }
 
+// GetPONPortRequest is the request body for POST /api/v1/olt/pon-port.
+type GetPONPortRequest struct {
+	Target SNMPTarget `json:"target" binding:"required"`
+
+	// PortIndex is the PON port's ifIndex, as returned in port_index by
+	// POST /api/v1/olt/pon-ports (required).
+	PortIndex int `json:"port_index" binding:"required,min=1"`
+}
+
 // GetONTsRequest is the request body for POST /api/v1/olt/onts.
 type GetONTsRequest struct {
 	Target SNMPTarget `json:"target" binding:"required"`
@@ -45,12 +74,40 @@ type GetONTsRequest struct {
 	PONPort int `json:"pon_port"`
 }
 
+// GetCardsRequest is the request body for POST /api/v1/olt/cards.
+type GetCardsRequest struct {
+	Target SNMPTarget `json:"target" binding:"required"`
+}
+
+// SearchONTsRequest is the request body for POST /api/v1/olt/ont-search.
+type SearchONTsRequest struct {
+	Target SNMPTarget `json:"target" binding:"required"`
+
+	// Query is matched case-insensitively as a substring of the ONT
+	// description, name or serial number (required).
+	Query string `json:"query" binding:"required"`
+}
+
+// GetServicePortsRequest is the request body for POST /api/v1/olt/service-ports.
+type GetServicePortsRequest struct {
+	Target SNMPTarget `json:"target" binding:"required"`
+
+	// PONPort filters results to one PON port, given as its port_index.
+	// Set to 0 (or omit) to return service ports on all PON ports.
+	PONPort int `json:"pon_port" binding:"min=0"`
+
+	// ONTID filters results to one ONT on PONPort. ONT IDs repeat across
+	// ports, so it requires PONPort.
+	ONTID int `json:"ont_id" binding:"min=0"`
+}
+
 // SystemMetricsResponse is the API response for OLT system metrics.
 type SystemMetricsResponse struct {
 	IPAddress          string    `json:"ip_address"`
 	Timestamp          time.Time `json:"timestamp"`
 	SysDescr           string    `json:"sys_descr"`
 	SysName            string    `json:"sys_name"`
+	Vendor             string    `json:"vendor"`
 	UptimeSeconds      int64     `json:"uptime_seconds"`
 	CPUUsagePercent    float64   `json:"cpu_usage_percent"`
 	MemoryTotalKB      int64     `json:"memory_total_kb"`
@@ -59,30 +116,80 @@ type SystemMetricsResponse struct {
 	TemperatureCelsius float64   `json:"temperature_celsius"`
 }
 
+// CardResponse is the API response for a single installed card.
+type CardResponse struct {
+	Slot               int     `json:"slot"`
+	Type               string  `json:"type"`
+	Status             string  `json:"status"`
+	CPUUsagePercent    float64 `json:"cpu_usage_percent"`
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+	MemoryUsagePercent float64 `json:"memory_usage_percent"`
+	MemoryTotalKB      int64   `json:"memory_total_kb"`
+}
+
+// CardListResponse lists the cards installed in the OLT shelf.
+type CardListResponse struct {
+	IPAddress string         `json:"ip_address"`
+	Timestamp time.Time      `json:"timestamp"`
+	Count     int            `json:"count"`
+	Cards     []CardResponse `json:"cards"`
+
+	// Warnings lists columns that could not be walked; their fields are zero.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // PONPortResponse is the API response for a single PON port.
+// TxPowerDBm and RxPowerDBm are null when the reading is not a finite number.
 type PONPortResponse struct {
 	IPAddress   string    `json:"ip_address"`
 	Timestamp   time.Time `json:"timestamp"`
 	PortIndex   int       `json:"port_index"`
 	AdminStatus string    `json:"admin_status"`
 	OperStatus  string    `json:"oper_status"`
-	TxPowerDBm  float64   `json:"tx_power_dbm"`
-	RxPowerDBm  float64   `json:"rx_power_dbm"`
+	TxPowerDBm  *float64  `json:"tx_power_dbm"`
+	RxPowerDBm  *float64  `json:"rx_power_dbm"`
 	ONTCount    int       `json:"ont_count"`
+
+	// ActualONTCount is the number of ONTs found in the ONT table for this
+	// port. It is omitted when the ONT table could not be read.
+	ActualONTCount *int `json:"actual_ont_count,omitempty"`
+
+	// ONTCountMismatch is true when ONTCount (the OLT's own counter) differs
+	// from ActualONTCount, which usually means the counter is stale.
+	ONTCountMismatch bool `json:"ont_count_mismatch"`
 }
 
 // ONTResponse is the API response for a single ONT.
+// RxPowerDBm and TxPowerDBm are null when the reading is not a finite number.
 type ONTResponse struct {
 	IPAddress      string    `json:"ip_address"`
 	Timestamp      time.Time `json:"timestamp"`
 	PONPortIndex   int       `json:"pon_port_index"`
 	ONTIndex       int       `json:"ont_index"`
+	Slot           int       `json:"slot"`
+	PONPort        int       `json:"pon_port"`
+	ONTID          int       `json:"ont_id"`
 	SerialNumber   string    `json:"serial_number"`
+	Name           string    `json:"name"`
 	OperStatus     string    `json:"oper_status"`
-	RxPowerDBm     float64   `json:"rx_power_dbm"`
-	TxPowerDBm     float64   `json:"tx_power_dbm"`
+	RxPowerDBm     *float64  `json:"rx_power_dbm"`
+	TxPowerDBm     *float64  `json:"tx_power_dbm"`
 	DistanceMeters int       `json:"distance_meters"`
 	Description    string    `json:"description"`
+
+	// DistanceImplausible flags a distance outside GPON reach (0-40 km),
+	// usually a sign that olt.ont_distance_unit is wrong for this firmware.
+	DistanceImplausible bool `json:"distance_implausible,omitempty"`
+
+	// LastDownCause explains why an offline ONT dropped, e.g. "dying gasp
+	// (power loss)" vs "loss of signal (fiber cut or disconnected)".
+	// Omitted for online ONTs.
+	LastDownCause string `json:"last_down_cause,omitempty"`
+
+	// LineProfile and ServiceProfile are the GPON line and service profiles
+	// provisioned on the ONT. They are empty when none is assigned.
+	LineProfile    string `json:"line_profile"`
+	ServiceProfile string `json:"service_profile"`
 }
 
 // PONPortListResponse wraps a list of PON port responses.
@@ -90,6 +197,26 @@ type PONPortListResponse struct {
 	IPAddress string            `json:"ip_address"`
 	Count     int               `json:"count"`
 	PonPorts  []PONPortResponse `json:"pon_ports"`
+
+	// ONTCountMismatches is the number of ports whose ONT counts disagree.
+	ONTCountMismatches int `json:"ont_count_mismatches"`
+
+	// Warnings lists columns that could not be walked; their fields are zero.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PONPortDetailResponse is a single PON port together with its ONTs.
+type PONPortDetailResponse struct {
+	IPAddress string          `json:"ip_address"`
+	Port      PONPortResponse `json:"port"`
+	ONTs      []ONTResponse   `json:"onts"`
+
+	// Truncated is true when the OLT reported more ONTs than olt.max_onts.
+	// The port's actual_ont_count is omitted then.
+	Truncated bool `json:"truncated"`
+
+	// Warnings lists columns that could not be walked; their fields are zero.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // ONTListResponse wraps a list of ONT responses.
@@ -97,6 +224,13 @@ type ONTListResponse struct {
 	IPAddress string        `json:"ip_address"`
 	Total     int           `json:"total"`
 	ONTs      []ONTResponse `json:"onts"`
+
+	// Truncated is true when the OLT reported more ONTs than olt.max_onts
+	// and only the first ones were collected.
+	Truncated bool `json:"truncated"`
+
+	// Warnings lists columns that could not be walked; their fields are zero.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // ONTStatusResponse wraps lists of ONTs strictly categorized by status.
@@ -104,4 +238,112 @@ type ONTStatusResponse struct {
 	IPAddress string        `json:"ip_address"`
 	Up        []ONTResponse `json:"up"`
 	Down      []ONTResponse `json:"down"`
+
+	// Truncated is true when the OLT reported more ONTs than olt.max_onts.
+	Truncated bool `json:"truncated"`
+
+	// Warnings lists columns that could not be walked; their fields are zero.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// OpticalReportONT is one ONT in an optical health report.
+// RxPowerDBm and TxPowerDBm are null when the reading is not a finite number.
+type OpticalReportONT struct {
+	PONPortIndex int      `json:"pon_port_index"`
+	ONTIndex     int      `json:"ont_index"`
+	SerialNumber string   `json:"serial_number"`
+	Description  string   `json:"description"`
+	OperStatus   string   `json:"oper_status"`
+	RxPowerDBm   *float64 `json:"rx_power_dbm"`
+	TxPowerDBm   *float64 `json:"tx_power_dbm"`
+
+	// Class grades the link by RxPowerDBm: ok, warning, critical, or
+	// offline/unknown when there is no reading to grade.
+	Class OpticalClass `json:"class"`
+}
+
+// OpticalReportResponse lists every ONT on an OLT with its optical class.
+type OpticalReportResponse struct {
+	IPAddress string    `json:"ip_address"`
+	Timestamp time.Time `json:"timestamp"`
+	Total     int       `json:"total"`
+
+	// Summary counts ONTs per class. Every class is present, with 0 when no
+	// ONT falls into it.
+	Summary map[OpticalClass]int `json:"summary"`
+
+	// ONTs is ordered by PON port, then ONT index.
+	ONTs []OpticalReportONT `json:"onts"`
+
+	// Truncated is true when the OLT reported more ONTs than olt.max_onts.
+	Truncated bool `json:"truncated"`
+
+	// Warnings lists columns that could not be walked; their fields are zero.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CapabilityMetric is one metric go-nms collects from an OLT.
+type CapabilityMetric struct {
+	Name string `json:"name"`
+	OID  string `json:"oid"`
+
+	// Table is true for table columns walked per row, false for scalars.
+	Table bool   `json:"table"`
+	Unit  string `json:"unit,omitempty"`
+}
+
+// CapabilityGroup is the set of metrics read by one OLT endpoint.
+type CapabilityGroup struct {
+	Name    string             `json:"name"`
+	Metrics []CapabilityMetric `json:"metrics"`
+
+	// Includes names groups whose metrics this group also collects.
+	Includes []string `json:"includes,omitempty"`
+}
+
+// CapabilitiesResponse lists the metrics go-nms collects from an OLT vendor.
+type CapabilitiesResponse struct {
+	Vendor string            `json:"vendor"`
+	Groups []CapabilityGroup `json:"groups"`
+}
+
+// ServicePortResponse maps one ONT service port to its VLANs.
+type ServicePortResponse struct {
+	PONPortIndex int `json:"pon_port_index"`
+	ONTID        int `json:"ont_id"`
+
+	// ONTIndex is the ONT's ont_index as returned by /olt/onts.
+	ONTIndex      int `json:"ont_index"`
+	ServicePortID int `json:"service_port_id"`
+	VPort         int `json:"vport"`
+
+	// UserVLAN is the subscriber-side VLAN; 0 for untagged traffic.
+	UserVLAN int `json:"user_vlan"`
+
+	// VLAN is the uplink VLAN the traffic is carried in.
+	VLAN int `json:"vlan"`
+}
+
+// ServicePortListResponse wraps the ONT service ports of an OLT.
+type ServicePortListResponse struct {
+	IPAddress    string                `json:"ip_address"`
+	Total        int                   `json:"total"`
+	ServicePorts []ServicePortResponse `json:"service_ports"`
+}
+
+// ONTSearchResult is a single ONT matching a search query.
+type ONTSearchResult struct {
+	PONPortIndex int    `json:"pon_port_index"`
+	ONTID        int    `json:"ont_id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	SerialNumber string `json:"serial_number"`
+}
+
+// ONTSearchResponse wraps the ONTs matching a search query.
+type ONTSearchResponse struct {
+	IPAddress string            `json:"ip_address"`
+	Query     string            `json:"query"`
+	Total     int               `json:"total"`
+	Matches   []ONTSearchResult `json:"matches"`
 }
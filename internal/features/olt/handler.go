@@ -1,9 +1,12 @@
 package olt
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
 )
 
 // Handler is the Gin HTTP handler for OLT API endpoints.
@@ -25,38 +28,93 @@ func NewHandler(service OLTService) *Handler {
 func (h *Handler) GetSystemMetrics(c *gin.Context) {
 	var req GetSystemMetricsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	metrics, err := h.service.GetSystemMetrics(c.Request.Context(), req.Target)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, metrics)
 }
 
+// GetCards handles POST /api/v1/olt/cards
+//
+// Returns the cards installed in the OLT shelf with their type, status and load.
+func (h *Handler) GetCards(c *gin.Context) {
+	var req GetCardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cards, err := h.service.GetCards(c.Request.Context(), req.Target)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cards)
+}
+
 // GetPONPorts handles POST /api/v1/olt/pon-ports
 //
 // Returns metrics for all PON ports on the OLT specified in the request body.
 func (h *Handler) GetPONPorts(c *gin.Context) {
 	var req GetPONPortsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	ports, err := h.service.GetPONPorts(c.Request.Context(), req.Target)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, ports)
 }
 
+// GetPONPort handles POST /api/v1/olt/pon-port
+//
+// Returns one PON port, selected by port_index, together with all of its ONTs,
+// read over a single SNMP session.
+func (h *Handler) GetPONPort(c *gin.Context) {
+	var req GetPONPortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	port, err := h.service.GetPONPort(c.Request.Context(), req.Target, req.PortIndex)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, port)
+}
+
 // GetONTs handles POST /api/v1/olt/onts
 //
 // Returns metrics for all ONTs on the OLT specified in the request body.
@@ -64,7 +122,11 @@ func (h *Handler) GetPONPorts(c *gin.Context) {
 func (h *Handler) GetONTs(c *gin.Context) {
 	var req GetONTsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -75,7 +137,7 @@ func (h *Handler) GetONTs(c *gin.Context) {
 
 	onts, err := h.service.GetONTs(c.Request.Context(), req.Target, req.PONPort)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -89,19 +151,145 @@ func (h *Handler) GetONTStatus(c *gin.Context) {
 	// usage: Use GetSystemMetricsRequest since it only contains Target, which is exactly what we need.
 	var req GetSystemMetricsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	status, err := h.service.GetONTStatus(c.Request.Context(), req.Target)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, status)
 }
 
+// GetOpticalReport handles POST /api/v1/olt/optical-report
+//
+// Returns every ONT with its Rx/Tx power and optical class, and the number
+// of ONTs per class, for periodic health reports.
+func (h *Handler) GetOpticalReport(c *gin.Context) {
+	var req GetSystemMetricsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.GetOpticalReport(c.Request.Context(), req.Target)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// SearchONTs handles POST /api/v1/olt/ont-search
+//
+// Returns ONTs whose description, name or serial number contains the query,
+// ignoring case. Useful for finding a subscriber's ONT by account number.
+func (h *Handler) SearchONTs(c *gin.Context) {
+	var req SearchONTsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query must not be blank"})
+		return
+	}
+
+	result, err := h.service.SearchONTs(c.Request.Context(), req.Target, req.Query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetServicePorts handles POST /api/v1/olt/service-ports
+//
+// Returns the VLAN mapping of each ONT service port. Set pon_port, and
+// optionally ont_id, in the body to look up a single subscriber.
+func (h *Handler) GetServicePorts(c *gin.Context) {
+	var req GetServicePortsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if err := applyOverrideHeaders(c, &req.Target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ONTID > 0 && req.PONPort == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ont_id requires pon_port"})
+		return
+	}
+
+	ports, err := h.service.GetServicePorts(c.Request.Context(), req.Target, req.PONPort, req.ONTID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ports)
+}
+
+// GetCapabilities handles GET /api/v1/olt/capabilities?vendor=zte
+//
+// Lists the metrics go-nms collects from the vendor's OLTs, grouped by the
+// SNMP operation that reads them, with the OID each is read from.
+func (h *Handler) GetCapabilities(c *gin.Context) {
+	vendor := c.Query("vendor")
+	if strings.TrimSpace(vendor) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "vendor is required"})
+		return
+	}
+
+	caps, err := Capabilities(vendor)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, caps)
+}
+
+// errorStatus maps a service error to its HTTP status code.
+func errorStatus(err error) int {
+	if errors.Is(err, ErrUnsupportedVendor) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, ErrInvalidOverride) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, ErrPONPortNotFound) || errors.Is(err, ErrDeviceNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, ErrNoStoredCredentials) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, ErrServiceClosed) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // RegisterRoutes registers all OLT routes on the given Gin router group.
 func RegisterRoutes(group *gin.RouterGroup, service OLTService) {
 	h := NewHandler(service)
@@ -111,13 +299,31 @@ func RegisterRoutes(group *gin.RouterGroup, service OLTService) {
 		// POST /api/v1/olt/system     — system metrics (CPU, memory, uptime, temperature)
 		oltGroup.POST("/system", h.GetSystemMetrics)
 
+		// POST /api/v1/olt/cards      — installed cards per slot (type, status, load)
+		oltGroup.POST("/cards", h.GetCards)
+
 		// POST /api/v1/olt/pon-ports  — PON port status and optical power
 		oltGroup.POST("/pon-ports", h.GetPONPorts)
 
+		// POST /api/v1/olt/pon-port   — one PON port with its ONTs (port_index in body)
+		oltGroup.POST("/pon-port", h.GetPONPort)
+
 		// POST /api/v1/olt/onts       — ONT list (filter by pon_port in body)
 		oltGroup.POST("/onts", h.GetONTs)
 
 		// POST /api/v1/olt/ont-status — ONT status list (up/down)
 		oltGroup.POST("/ont-status", h.GetONTStatus)
+
+		// POST /api/v1/olt/optical-report — every ONT with its optical class
+		oltGroup.POST("/optical-report", h.GetOpticalReport)
+
+		// POST /api/v1/olt/ont-search — find ONTs by description/serial substring
+		oltGroup.POST("/ont-search", h.SearchONTs)
+
+		// POST /api/v1/olt/service-ports — ONT service-port to VLAN mappings
+		oltGroup.POST("/service-ports", h.GetServicePorts)
+
+		// GET  /api/v1/olt/capabilities?vendor=zte — collected metrics and their OIDs
+		oltGroup.GET("/capabilities", h.GetCapabilities)
 	}
 }
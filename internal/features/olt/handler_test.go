@@ -0,0 +1,170 @@
+package olt_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/features/olt"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
+)
+
+func postSystem(t *testing.T, svc olt.OLTService, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	olt.RegisterRoutes(r.Group("/api/v1"), svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/olt/system", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestOverrideHeadersReachClient(t *testing.T) {
+	mock := &mockSNMPClient{}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	w := postSystem(t, svc, `{"target":{"ip":"10.0.0.1"}}`, map[string]string{
+		olt.HeaderSNMPTimeout: "20s",
+		olt.HeaderSNMPRetries: "0",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, []time.Duration{20 * time.Second}, mock.timeouts)
+	assert.Equal(t, []int{0}, mock.retries)
+}
+
+func TestOverrideBodyTakesPrecedenceOverHeaders(t *testing.T) {
+	mock := &mockSNMPClient{}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	w := postSystem(t, svc, `{"target":{"ip":"10.0.0.1","timeout":"30s","retries":1}}`, map[string]string{
+		olt.HeaderSNMPTimeout: "20s",
+		olt.HeaderSNMPRetries: "3",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, []time.Duration{30 * time.Second}, mock.timeouts)
+	assert.Equal(t, []int{1}, mock.retries)
+}
+
+func TestOverrideInvalidValuesRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+	}{
+		{"retries header not an integer", `{"target":{"ip":"10.0.0.1"}}`, map[string]string{olt.HeaderSNMPRetries: "many"}},
+		{"timeout header out of bounds", `{"target":{"ip":"10.0.0.1"}}`, map[string]string{olt.HeaderSNMPTimeout: "10m"}},
+		{"body retries out of bounds", `{"target":{"ip":"10.0.0.1","retries":9}}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockSNMPClient{}
+			w := postSystem(t, olt.NewOLTServiceForTest(mock, config.OLTConfig{}), tt.body, tt.headers)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "invalid SNMP override")
+			assert.Empty(t, mock.timeouts)
+		})
+	}
+}
+
+func TestMissingTargetIPIsReadable(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(&mockSNMPClient{}, config.OLTConfig{})
+
+	w := postSystem(t, svc, `{"target":{"community":"public"}}`, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.JSONEq(t, `{
+		"error": "invalid request body: target.ip is required",
+		"fields": [{"field": "target.ip", "message": "is required"}]
+	}`, w.Body.String())
+}
+
+func TestServicePortsONTRequiresPONPort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	mock := &mockSNMPClient{}
+	olt.RegisterRoutes(r.Group("/api/v1"), olt.NewOLTServiceForTest(mock, config.OLTConfig{}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/olt/service-ports", strings.NewReader(`{"target":{"ip":"10.0.0.1"},"ont_id":3}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "ont_id requires pon_port")
+	assert.Empty(t, mock.timeouts, "the OLT is not contacted")
+}
+
+func TestOpticalReportRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	olt.RegisterRoutes(r.Group("/api/v1"), olt.NewOLTServiceForTest(opticalMock(), config.OLTConfig{}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/olt/optical-report", strings.NewReader(`{"target":{"ip":"10.0.0.1"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"summary":{"critical":2,"offline":1,"ok":1,"unknown":0,"warning":1}`)
+}
+
+func getCapabilities(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	olt.RegisterRoutes(r.Group("/api/v1"), olt.NewOLTServiceForTest(&mockSNMPClient{}, config.OLTConfig{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/olt/capabilities"+query, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCapabilitiesRoute_ListsZTEMetrics(t *testing.T) {
+	w := getCapabilities(t, "?vendor=zte")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp olt.CapabilitiesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "zte", resp.Vendor)
+
+	oids := make(map[string]string)
+	includes := make(map[string][]string)
+	for _, group := range resp.Groups {
+		for _, m := range group.Metrics {
+			oids[group.Name+"."+m.Name] = m.OID
+		}
+		includes[group.Name] = group.Includes
+	}
+	assert.Equal(t, zte.OIDZTEONTRxPower, oids["onts.rx_power"])
+	assert.Equal(t, []string{"ont_info"}, includes["onts"])
+	assert.NotContains(t, oids, "onts.serial_number")
+	assert.Equal(t, zte.OIDZTEONTDistance, oids["onts.distance"])
+	assert.Equal(t, zte.OIDZTEPONPortOperStatus, oids["pon_ports.oper_status"])
+	assert.Equal(t, zte.OIDZTECardTemperature, oids["cards.temperature"])
+	assert.Equal(t, zte.OIDZTEServicePortVLAN, oids["service_ports.vlan"])
+}
+
+func TestCapabilitiesRoute_RejectsOtherVendors(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, getCapabilities(t, "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, getCapabilities(t, "?vendor=huawei").Code)
+	assert.Equal(t, http.StatusOK, getCapabilities(t, "?vendor=ZTE").Code)
+}
@@ -0,0 +1,52 @@
+package olt
+
+// OpticalClass grades an ONT's optical link from the power the OLT receives
+// from it.
+type OpticalClass string
+
+const (
+	// OpticalOK is a receive power with comfortable margin.
+	OpticalOK OpticalClass = "ok"
+	// OpticalWarning is a weak but working link, typically a dirty connector
+	// or a tight bend; worth a visit before it degrades further.
+	OpticalWarning OpticalClass = "warning"
+	// OpticalCritical is a receive power at the edge of, or beyond, what the
+	// OLT optic can decode (too weak or overloading).
+	OpticalCritical OpticalClass = "critical"
+	// OpticalOffline is an ONT that is not in service, so it has no reading.
+	OpticalOffline OpticalClass = "offline"
+	// OpticalUnknown is an online ONT whose receive power could not be read.
+	OpticalUnknown OpticalClass = "unknown"
+)
+
+// OpticalClasses lists every class, from most to least severe.
+var OpticalClasses = []OpticalClass{OpticalCritical, OpticalWarning, OpticalOK, OpticalUnknown, OpticalOffline}
+
+// Receive power thresholds in dBm for a GPON class B+ OLT optic, whose
+// receiver sensitivity is -28 dBm and overload point -8 dBm.
+const (
+	rxLowWarningDBm   = -25.0
+	rxLowCriticalDBm  = -27.0
+	rxHighCriticalDBm = -8.0
+)
+
+// classifyOptical grades an ONT by its receive power at the OLT. rxPowerDBm
+// is nil when the reading is missing or not a finite number.
+func classifyOptical(online bool, rxPowerDBm *float64) OpticalClass {
+	if !online {
+		return OpticalOffline
+	}
+	if rxPowerDBm == nil {
+		return OpticalUnknown
+	}
+
+	rx := *rxPowerDBm
+	switch {
+	case rx < rxLowCriticalDBm || rx > rxHighCriticalDBm:
+		return OpticalCritical
+	case rx < rxLowWarningDBm:
+		return OpticalWarning
+	default:
+		return OpticalOK
+	}
+}
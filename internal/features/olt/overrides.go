@@ -0,0 +1,89 @@
+package olt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidOverride is returned when a request's SNMP timeout, retries or
+// max-repetitions override is malformed or out of bounds.
+var ErrInvalidOverride = errors.New("invalid SNMP override")
+
+// Headers that override SNMPTarget.Timeout and SNMPTarget.Retries when the
+// request body leaves them unset.
+const (
+	HeaderSNMPTimeout = "X-SNMP-Timeout"
+	HeaderSNMPRetries = "X-SNMP-Retries"
+)
+
+// Bounds on per-request overrides. A generous timeout still has to fit inside
+// an HTTP request, and every retry multiplies the time a dead OLT holds a
+// session.
+const (
+	minOverrideTimeout = time.Second
+	maxOverrideTimeout = 5 * time.Minute
+	maxOverrideRetries = 5
+
+	// gosnmp's own default; slow OLTs are tuned down from here, not up.
+	maxOverrideMaxRepetitions = 50
+)
+
+// applyOverrideHeaders copies the override headers into target for fields the
+// body did not set.
+func applyOverrideHeaders(c *gin.Context, target *SNMPTarget) error {
+	if v := c.GetHeader(HeaderSNMPTimeout); v != "" && target.Timeout == "" {
+		target.Timeout = v
+	}
+	if v := c.GetHeader(HeaderSNMPRetries); v != "" && target.Retries == nil {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s %q is not an integer", ErrInvalidOverride, HeaderSNMPRetries, v)
+		}
+		target.Retries = &n
+	}
+	return nil
+}
+
+// resolveOverrides returns the timeout and retries for a request to target.
+// An unset timeout yields def; unset retries yield -1, which leaves the
+// client's default in place.
+func resolveOverrides(target SNMPTarget, def time.Duration) (time.Duration, int, error) {
+	timeout, retries := def, -1
+
+	if target.Timeout != "" {
+		d, err := time.ParseDuration(target.Timeout)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%w: timeout %q is not a duration", ErrInvalidOverride, target.Timeout)
+		}
+		if d < minOverrideTimeout || d > maxOverrideTimeout {
+			return 0, 0, fmt.Errorf("%w: timeout %s is outside %s to %s", ErrInvalidOverride, d, minOverrideTimeout, maxOverrideTimeout)
+		}
+		timeout = d
+	}
+
+	if target.Retries != nil {
+		if *target.Retries < 0 || *target.Retries > maxOverrideRetries {
+			return 0, 0, fmt.Errorf("%w: retries %d is outside 0 to %d", ErrInvalidOverride, *target.Retries, maxOverrideRetries)
+		}
+		retries = *target.Retries
+	}
+
+	return timeout, retries, nil
+}
+
+// resolveMaxRepetitions returns the GETBULK max-repetitions for walks on
+// target, or 0 when unset, which leaves the client's default in place.
+func resolveMaxRepetitions(target SNMPTarget) (uint32, error) {
+	if target.MaxRepetitions == nil {
+		return 0, nil
+	}
+	n := *target.MaxRepetitions
+	if n < 1 || n > maxOverrideMaxRepetitions {
+		return 0, fmt.Errorf("%w: max_repetitions %d is outside 1 to %d", ErrInvalidOverride, n, maxOverrideMaxRepetitions)
+	}
+	return uint32(n), nil
+}
@@ -2,13 +2,24 @@ package olt
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
 	devicemodel "github.com/yourorg/nms-go/internal/device/model"
+	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
 	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
 )
 
+// ErrPONPortNotFound is returned when the OLT has no PON port with the
+// requested index.
+var ErrPONPortNotFound = errors.New("PON port not found")
+
 // OLTService defines the interface for querying OLT device data via SNMP.
 // It is consumed by the HTTP handler and can be mocked in tests.
 //
@@ -18,54 +29,187 @@ type OLTService interface {
 	// GetSystemMetrics returns system-level metrics for the OLT at the given target.
 	GetSystemMetrics(ctx context.Context, target SNMPTarget) (*SystemMetricsResponse, error)
 
+	// GetCards returns the cards installed in the OLT shelf, ordered by slot.
+	GetCards(ctx context.Context, target SNMPTarget) (*CardListResponse, error)
+
 	// GetPONPorts returns metrics for all PON ports on the OLT at the given target.
 	GetPONPorts(ctx context.Context, target SNMPTarget) (*PONPortListResponse, error)
 
+	// GetPONPort returns one PON port, identified by its ifIndex, together
+	// with its ONTs. It returns ErrPONPortNotFound when the OLT has no such
+	// port.
+	GetPONPort(ctx context.Context, target SNMPTarget, portIndex int) (*PONPortDetailResponse, error)
+
 	// GetONTs returns metrics for all ONTs on the OLT at the given target.
 	// If ponPortIndex > 0, only ONTs on that specific PON port are returned.
 	GetONTs(ctx context.Context, target SNMPTarget, ponPortIndex int) (*ONTListResponse, error)
 
 	// GetONTStatus returns ONTs categorized by their operational status (Up/Down).
 	GetONTStatus(ctx context.Context, target SNMPTarget) (*ONTStatusResponse, error)
+
+	// GetOpticalReport returns every ONT on the OLT with its optical power
+	// classified, and the number of ONTs in each class.
+	GetOpticalReport(ctx context.Context, target SNMPTarget) (*OpticalReportResponse, error)
+
+	// SearchONTs returns ONTs whose description, name or serial number contains
+	// the query (case-insensitive).
+	SearchONTs(ctx context.Context, target SNMPTarget, query string) (*ONTSearchResponse, error)
+
+	// GetServicePorts returns the ONT service-port to VLAN mappings on the
+	// OLT. ponPortIndex > 0 limits them to one PON port, and ontID > 0 further
+	// to one ONT on that port.
+	GetServicePorts(ctx context.Context, target SNMPTarget, ponPortIndex, ontID int) (*ServicePortListResponse, error)
+
+	// InvalidateCache drops cached vendor detection for ip, or for every OLT
+	// when ip is empty, and returns the number of entries removed.
+	InvalidateCache(ip string) int
+
+	// Shutdown refuses new requests with ErrServiceClosed and waits for open
+	// SNMP sessions to end. When ctx is done first, the remaining sessions are
+	// closed and an error wrapping ctx.Err() is returned.
+	Shutdown(ctx context.Context) error
 }
 
+// Default per-operation SNMP timeouts, used when the config leaves one unset.
+const (
+	defaultSystemTimeout = 5 * time.Second
+	defaultPONTimeout    = 15 * time.Second
+	defaultONTTimeout    = 60 * time.Second
+
+	defaultVendorCacheTTL = 24 * time.Hour
+	defaultMaxONTs        = 8192
+	defaultMaxSessions    = 64
+)
+
 type oltService struct {
-	timeout time.Duration
+	systemTimeout time.Duration
+	ponTimeout    time.Duration
+	ontTimeout    time.Duration
+	maxONTs       int
+	distanceUnit  zte.DistanceUnit
+	newClient     func(timeout time.Duration) *zte.ZTEOLTClient
+	vendors       *vendorCache
+	sessions      *sessionLimiter
+	credentials   CredentialStore
 }
 
 // NewOLTService creates a new OLTService.
 // No device repository is needed — connection details come from the request body.
-func NewOLTService() OLTService {
-	return &oltService{
-		timeout: 15 * time.Second,
+// Each operation connects with its own timeout from cfg, and at most
+// cfg.MaxSessions SNMP sessions are open at once across all requests.
+// When credentials is non-nil, targets carrying a device_id use the SNMP
+// credentials stored for that device instead of the ones in the body.
+func NewOLTService(cfg config.OLTConfig, credentials CredentialStore) OLTService {
+	return newOLTService(cfg, zte.NewZTEOLTClient, credentials)
+}
+
+// NewOLTServiceForTest creates an OLTService whose ZTE clients use the given SNMPClient.
+// This is intended for use in unit tests to inject a mock SNMP client.
+func NewOLTServiceForTest(snmp snmpclient.SNMPClient, cfg config.OLTConfig) OLTService {
+	return NewOLTServiceWithCredentialsForTest(snmp, cfg, nil)
+}
+
+// NewOLTServiceWithCredentialsForTest is NewOLTServiceForTest with a
+// credential store for targets that carry a device_id.
+func NewOLTServiceWithCredentialsForTest(snmp snmpclient.SNMPClient, cfg config.OLTConfig, credentials CredentialStore) OLTService {
+	return newOLTService(cfg, func(timeout time.Duration) *zte.ZTEOLTClient {
+		return zte.NewZTEOLTClientForTest(snmp, timeout)
+	}, credentials)
+}
+
+func newOLTService(cfg config.OLTConfig, newClient func(timeout time.Duration) *zte.ZTEOLTClient, credentials CredentialStore) *oltService {
+	s := &oltService{
+		systemTimeout: orDefault(cfg.SystemTimeout, defaultSystemTimeout),
+		ponTimeout:    orDefault(cfg.PONTimeout, defaultPONTimeout),
+		ontTimeout:    orDefault(cfg.ONTTimeout, defaultONTTimeout),
+		maxONTs:       cfg.MaxONTs,
+		newClient:     newClient,
+		vendors:       newVendorCache(orDefault(cfg.VendorCacheTTL, defaultVendorCacheTTL)),
+		credentials:   credentials,
+	}
+	if s.maxONTs <= 0 {
+		s.maxONTs = defaultMaxONTs
+	}
+
+	maxSessions := cfg.MaxSessions
+	if maxSessions <= 0 {
+		maxSessions = defaultMaxSessions
+	}
+	s.sessions = newSessionLimiter(maxSessions)
+
+	unit, err := zte.ParseDistanceUnit(cfg.ONTDistanceUnit)
+	if err != nil {
+		log.Printf("OLT config: %v; using meters", err)
+		unit = zte.DistanceUnitMeters
+	}
+	s.distanceUnit = unit
+
+	return s
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
 	}
+	return d
 }
 
 // GetSystemMetrics retrieves system metrics from the OLT via SNMP.
 func (s *oltService) GetSystemMetrics(ctx context.Context, target SNMPTarget) (*SystemMetricsResponse, error) {
-	client, err := s.connectToOLT(ctx, target)
+	client, vendor, err := s.connectToOLT(ctx, target, s.systemTimeout)
 	if err != nil {
 		return nil, err
 	}
-	defer client.Disconnect()
+	defer s.disconnect(client)
 
 	metrics, err := client.GetSystemMetrics(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get system metrics from OLT %s: %w", target.IP, err)
 	}
 
-	return mapSystemMetrics(target.IP, metrics), nil
+	resp := mapSystemMetrics(target.IP, metrics)
+	resp.Vendor = string(vendor)
+	return resp, nil
+}
+
+// GetCards retrieves the card inventory and per-card load from the OLT via SNMP.
+func (s *oltService) GetCards(ctx context.Context, target SNMPTarget) (*CardListResponse, error) {
+	client, _, err := s.connectToOLT(ctx, target, s.systemTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer s.disconnect(client)
+
+	cards, err := client.GetCards(ctx)
+	warnings, err := partialWarnings(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards from OLT %s: %w", target.IP, err)
+	}
+
+	result := &CardListResponse{
+		IPAddress: target.IP,
+		Count:     len(cards),
+		Cards:     make([]CardResponse, 0, len(cards)),
+		Warnings:  warnings,
+	}
+	for _, card := range cards {
+		result.Timestamp = card.Timestamp
+		result.Cards = append(result.Cards, mapCard(card))
+	}
+
+	return result, nil
 }
 
 // GetPONPorts retrieves PON port metrics from the OLT via SNMP.
 func (s *oltService) GetPONPorts(ctx context.Context, target SNMPTarget) (*PONPortListResponse, error) {
-	client, err := s.connectToOLT(ctx, target)
+	client, _, err := s.connectToOLT(ctx, target, s.ponTimeout)
 	if err != nil {
 		return nil, err
 	}
-	defer client.Disconnect()
+	defer s.disconnect(client)
 
 	ports, err := client.GetPONPortMetrics(ctx)
+	warnings, err := partialWarnings(err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PON port metrics from OLT %s: %w", target.IP, err)
 	}
@@ -75,22 +219,109 @@ func (s *oltService) GetPONPorts(ctx context.Context, target SNMPTarget) (*PONPo
 		responses = append(responses, mapPONPort(target.IP, p))
 	}
 
-	return &PONPortListResponse{
+	result := &PONPortListResponse{
 		IPAddress: target.IP,
 		Count:     len(responses),
 		PonPorts:  responses,
-	}, nil
+		Warnings:  warnings,
+	}
+
+	// Reconciliation is best effort: the port metrics are still useful when
+	// the ONT table can't be walked.
+	infos, err := client.GetONTInfo(ctx)
+	if err != nil {
+		log.Printf("OLT %s: skipping ONT count reconciliation: %v", target.IP, err)
+		result.Warnings = append(result.Warnings, "ONT count reconciliation skipped: "+err.Error())
+		return result, nil
+	}
+	reconcileONTCounts(result, infos)
+
+	return result, nil
+}
+
+// reconcileONTCounts compares each port's reported ONT count against the ONTs
+// actually registered on it and flags ports where the two differ.
+func reconcileONTCounts(result *PONPortListResponse, infos []*zte.ONTInfo) {
+	actual := make(map[int]int)
+	for _, info := range infos {
+		actual[info.PONPortIndex]++
+	}
+
+	for i := range result.PonPorts {
+		port := &result.PonPorts[i]
+		count := actual[port.PortIndex]
+		port.ActualONTCount = &count
+		port.ONTCountMismatch = count != port.ONTCount
+		if port.ONTCountMismatch {
+			result.ONTCountMismatches++
+		}
+	}
+}
+
+// GetPONPort retrieves one PON port and its ONTs over a single SNMP session.
+func (s *oltService) GetPONPort(ctx context.Context, target SNMPTarget, portIndex int) (*PONPortDetailResponse, error) {
+	client, _, err := s.connectToOLT(ctx, target, s.ontTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer s.disconnect(client)
+
+	ports, err := client.GetPONPortMetrics(ctx)
+	warnings, err := partialWarnings(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PON port metrics from OLT %s: %w", target.IP, err)
+	}
+
+	var port *zte.PONPortMetrics
+	for _, p := range ports {
+		if p.PortIndex == portIndex {
+			port = p
+			break
+		}
+	}
+	if port == nil {
+		return nil, fmt.Errorf("%w: OLT %s has no PON port %d", ErrPONPortNotFound, target.IP, portIndex)
+	}
+
+	onts, err := client.GetONTMetrics(ctx, portIndex)
+	truncated := errors.Is(err, zte.ErrONTLimitReached)
+	ontWarnings, err := partialWarnings(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONT metrics from OLT %s: %w", target.IP, err)
+	}
+
+	result := &PONPortDetailResponse{
+		IPAddress: target.IP,
+		Port:      mapPONPort(target.IP, port),
+		ONTs:      make([]ONTResponse, 0, len(onts)),
+		Truncated: truncated,
+		Warnings:  append(warnings, ontWarnings...),
+	}
+	for _, o := range onts {
+		result.ONTs = append(result.ONTs, mapONT(target.IP, o))
+	}
+	sort.Slice(result.ONTs, func(i, j int) bool { return result.ONTs[i].ONTIndex < result.ONTs[j].ONTIndex })
+
+	if !truncated {
+		count := len(result.ONTs)
+		result.Port.ActualONTCount = &count
+		result.Port.ONTCountMismatch = count != result.Port.ONTCount
+	}
+
+	return result, nil
 }
 
 // GetONTs retrieves ONT metrics from the OLT via SNMP.
 func (s *oltService) GetONTs(ctx context.Context, target SNMPTarget, ponPortIndex int) (*ONTListResponse, error) {
-	client, err := s.connectToOLT(ctx, target)
+	client, _, err := s.connectToOLT(ctx, target, s.ontTimeout)
 	if err != nil {
 		return nil, err
 	}
-	defer client.Disconnect()
+	defer s.disconnect(client)
 
 	onts, err := client.GetONTMetrics(ctx, ponPortIndex)
+	truncated := errors.Is(err, zte.ErrONTLimitReached)
+	warnings, err := partialWarnings(err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ONT metrics from OLT %s: %w", target.IP, err)
 	}
@@ -104,19 +335,23 @@ func (s *oltService) GetONTs(ctx context.Context, target SNMPTarget, ponPortInde
 		IPAddress: target.IP,
 		Total:     len(responses),
 		ONTs:      responses,
+		Truncated: truncated,
+		Warnings:  warnings,
 	}, nil
 }
 
 // GetONTStatus retrieves all ONTs and categorizes them into Up and Down.
 func (s *oltService) GetONTStatus(ctx context.Context, target SNMPTarget) (*ONTStatusResponse, error) {
-	client, err := s.connectToOLT(ctx, target)
+	client, _, err := s.connectToOLT(ctx, target, s.ontTimeout)
 	if err != nil {
 		return nil, err
 	}
-	defer client.Disconnect()
+	defer s.disconnect(client)
 
 	// Fetch all ONTs (ponPortIndex=0)
 	onts, err := client.GetONTMetrics(ctx, 0)
+	truncated := errors.Is(err, zte.ErrONTLimitReached)
+	warnings, err := partialWarnings(err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ONT metrics from OLT %s: %w", target.IP, err)
 	}
@@ -126,8 +361,7 @@ func (s *oltService) GetONTStatus(ctx context.Context, target SNMPTarget) (*ONTS
 
 	for _, o := range onts {
 		resp := mapONT(target.IP, o)
-		// "up" (case-insensitive) usually signifies operational status
-		if o.OperStatus.String() == "up" {
+		if o.OperStatus.IsOnline() {
 			up = append(up, resp)
 		} else {
 			down = append(down, resp)
@@ -138,12 +372,206 @@ func (s *oltService) GetONTStatus(ctx context.Context, target SNMPTarget) (*ONTS
 		IPAddress: target.IP,
 		Up:        up,
 		Down:      down,
+		Truncated: truncated,
+		Warnings:  warnings,
+	}, nil
+}
+
+// GetOpticalReport retrieves all ONTs and grades each by its receive power.
+func (s *oltService) GetOpticalReport(ctx context.Context, target SNMPTarget) (*OpticalReportResponse, error) {
+	client, _, err := s.connectToOLT(ctx, target, s.ontTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer s.disconnect(client)
+
+	onts, err := client.GetONTMetrics(ctx, 0)
+	truncated := errors.Is(err, zte.ErrONTLimitReached)
+	// A failed rx_power walk leaves every reading at 0 dBm, which would grade
+	// as overload; such ONTs are unknown instead.
+	rxUnread := columnFailed(err, "rx_power")
+	warnings, err := partialWarnings(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONT metrics from OLT %s: %w", target.IP, err)
+	}
+
+	report := &OpticalReportResponse{
+		IPAddress: target.IP,
+		Total:     len(onts),
+		Summary:   make(map[OpticalClass]int, len(OpticalClasses)),
+		ONTs:      make([]OpticalReportONT, 0, len(onts)),
+		Truncated: truncated,
+		Warnings:  warnings,
+	}
+	for _, class := range OpticalClasses {
+		report.Summary[class] = 0
+	}
+
+	for _, o := range onts {
+		rx := zte.NormalizePowerDBm(o.RxPowerDBm)
+		if rxUnread {
+			rx = nil
+		}
+		class := classifyOptical(o.OperStatus.IsOnline(), rx)
+		report.Summary[class]++
+		report.ONTs = append(report.ONTs, OpticalReportONT{
+			PONPortIndex: o.PONPortIndex,
+			ONTIndex:     o.ONTIndex,
+			SerialNumber: o.SerialNumber,
+			Description:  o.Description,
+			OperStatus:   o.OperStatus.String(),
+			RxPowerDBm:   rx,
+			TxPowerDBm:   zte.NormalizePowerDBm(o.TxPowerDBm),
+			Class:        class,
+		})
+	}
+	sort.Slice(report.ONTs, func(i, j int) bool {
+		a, b := report.ONTs[i], report.ONTs[j]
+		if a.PONPortIndex != b.PONPortIndex {
+			return a.PONPortIndex < b.PONPortIndex
+		}
+		return a.ONTIndex < b.ONTIndex
+	})
+	report.Timestamp = time.Now()
+	if len(onts) > 0 {
+		report.Timestamp = onts[0].Timestamp
+	}
+
+	return report, nil
+}
+
+// columnFailed reports whether err is a partial result missing column.
+func columnFailed(err error, column string) bool {
+	var partial *zte.PartialError
+	if !errors.As(err, &partial) {
+		return false
+	}
+	for _, col := range partial.Columns {
+		if col.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// partialWarnings turns a partial-result error from the ZTE client into
+// response warnings. A reached ONT limit is not an error either; callers
+// report it separately. Any other error is returned unchanged.
+func partialWarnings(err error) ([]string, error) {
+	var partial *zte.PartialError
+	if errors.As(err, &partial) {
+		return partial.Warnings(), nil
+	}
+	if errors.Is(err, zte.ErrONTLimitReached) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// SearchONTs walks the ONT config table and returns ONTs matching the query.
+func (s *oltService) SearchONTs(ctx context.Context, target SNMPTarget, query string) (*ONTSearchResponse, error) {
+	client, _, err := s.connectToOLT(ctx, target, s.ontTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer s.disconnect(client)
+
+	infos, err := client.GetONTInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONT info from OLT %s: %w", target.IP, err)
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(query))
+	matches := make([]ONTSearchResult, 0)
+	for _, info := range infos {
+		if containsFold(info.Description, needle) || containsFold(info.Name, needle) || containsFold(info.SerialNumber, needle) {
+			matches = append(matches, ONTSearchResult{
+				PONPortIndex: info.PONPortIndex,
+				ONTID:        info.ONTID,
+				Name:         info.Name,
+				Description:  info.Description,
+				SerialNumber: info.SerialNumber,
+			})
+		}
+	}
+
+	return &ONTSearchResponse{
+		IPAddress: target.IP,
+		Query:     query,
+		Total:     len(matches),
+		Matches:   matches,
+	}, nil
+}
+
+// GetServicePorts walks the service-port table and returns the VLAN mappings
+// matching the PON port and ONT filters.
+func (s *oltService) GetServicePorts(ctx context.Context, target SNMPTarget, ponPortIndex, ontID int) (*ServicePortListResponse, error) {
+	client, _, err := s.connectToOLT(ctx, target, s.ontTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer s.disconnect(client)
+
+	ports, err := client.GetServicePorts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service ports from OLT %s: %w", target.IP, err)
+	}
+
+	responses := make([]ServicePortResponse, 0, len(ports))
+	for _, sp := range ports {
+		if ponPortIndex > 0 && sp.PONPortIndex != ponPortIndex {
+			continue
+		}
+		if ontID > 0 && sp.ONTID != ontID {
+			continue
+		}
+		responses = append(responses, ServicePortResponse{
+			PONPortIndex:  sp.PONPortIndex,
+			ONTID:         sp.ONTID,
+			ONTIndex:      sp.PONPortIndex | sp.ONTID,
+			ServicePortID: sp.ID,
+			VPort:         sp.VPort,
+			UserVLAN:      sp.UserVLAN,
+			VLAN:          sp.VLAN,
+		})
+	}
+
+	return &ServicePortListResponse{
+		IPAddress:    target.IP,
+		Total:        len(responses),
+		ServicePorts: responses,
 	}, nil
 }
 
+// containsFold reports whether s contains the lower-cased needle, ignoring case.
+func containsFold(s, needle string) bool {
+	return strings.Contains(strings.ToLower(s), needle)
+}
+
+// InvalidateCache drops cached vendor detection so the next request re-reads
+// sysObjectID/sysDescr.
+func (s *oltService) InvalidateCache(ip string) int {
+	return s.vendors.invalidate(ip)
+}
+
+// Shutdown stops the service; see OLTService.Shutdown.
+func (s *oltService) Shutdown(ctx context.Context) error {
+	return s.sessions.shutdown(ctx)
+}
+
 // connectToOLT builds a synthetic device model from the SNMPTarget and
-// establishes an SNMP session. No database lookup is required.
-func (s *oltService) connectToOLT(ctx context.Context, target SNMPTarget) (*zte.ZTEOLTClient, error) {
+// establishes an SNMP session. The database is only consulted for targets
+// whose credentials are looked up by device_id.
+// Targets identified as another vendor's OLT are rejected with ErrUnsupportedVendor.
+// Timeout and retries overrides in target replace timeout and the client
+// default. It waits for a free session slot first; callers must end the
+// session with disconnect.
+func (s *oltService) connectToOLT(ctx context.Context, target SNMPTarget, timeout time.Duration) (*zte.ZTEOLTClient, Vendor, error) {
+	target, err := s.resolveCredentials(ctx, target)
+	if err != nil {
+		return nil, VendorUnknown, err
+	}
+
 	community := target.Community
 	if community == "" {
 		community = "public"
@@ -160,12 +588,77 @@ func (s *oltService) connectToOLT(ctx context.Context, target SNMPTarget) (*zte.
 		},
 	}
 
-	client := zte.NewZTEOLTClient(s.timeout)
+	timeout, retries, err := resolveOverrides(target, timeout)
+	if err != nil {
+		return nil, VendorUnknown, err
+	}
+	maxRepetitions, err := resolveMaxRepetitions(target)
+	if err != nil {
+		return nil, VendorUnknown, err
+	}
+
+	if err := s.sessions.acquire(ctx); err != nil {
+		return nil, VendorUnknown, fmt.Errorf("failed to connect to OLT %s via SNMP: %w", target.IP, err)
+	}
+
+	client := s.newClient(timeout)
+	client.SetMaxONTs(s.maxONTs)
+	client.SetDistanceUnit(s.distanceUnit)
+	if retries >= 0 {
+		client.SetRetries(retries)
+	}
+	if maxRepetitions > 0 {
+		client.SetMaxRepetitions(maxRepetitions)
+	}
 	if err := client.Connect(ctx, device); err != nil {
-		return nil, fmt.Errorf("failed to connect to OLT %s via SNMP: %w", target.IP, err)
+		s.sessions.release()
+		return nil, VendorUnknown, fmt.Errorf("failed to connect to OLT %s via SNMP: %w", target.IP, err)
+	}
+	if err := s.sessions.track(client); err != nil {
+		telemetry.Disconnect(client, snmpProtocol, target.IP)
+		s.sessions.release()
+		return nil, VendorUnknown, err
+	}
+
+	vendor, err := s.detectVendor(ctx, client, target.IP)
+	if err != nil {
+		// Detection is advisory; the operation itself will surface a dead agent.
+		log.Printf("OLT %s: vendor detection failed: %v", target.IP, err)
+		return client, VendorUnknown, nil
+	}
+	if vendor != VendorZTE && vendor != VendorUnknown {
+		s.disconnect(client)
+		return nil, vendor, fmt.Errorf("%w: OLT %s is %s", ErrUnsupportedVendor, target.IP, vendor)
+	}
+
+	return client, vendor, nil
+}
+
+// disconnect closes a session opened by connectToOLT and frees its slot.
+func (s *oltService) disconnect(client *zte.ZTEOLTClient) {
+	s.sessions.end(client)
+}
+
+// detectVendor identifies the OLT vendor, reusing the cached result for the IP
+// unless it has expired or the OLT has rebooted since. A cache hit costs a
+// single sysUpTime GET.
+func (s *oltService) detectVendor(ctx context.Context, client *zte.ZTEOLTClient, ip string) (Vendor, error) {
+	uptime, err := client.GetUptimeTicks(ctx)
+	if err != nil {
+		return VendorUnknown, err
+	}
+	if vendor, ok := s.vendors.get(ip, uptime); ok {
+		return vendor, nil
+	}
+
+	identity, err := client.GetSystemIdentity(ctx)
+	if err != nil {
+		return VendorUnknown, err
 	}
 
-	return client, nil
+	vendor := DetectVendor(identity.SysObjectID, identity.SysDescr)
+	s.vendors.put(ip, vendor, identity.UptimeTicks)
+	return vendor, nil
 }
 
 // ── Mapping helpers ───────────────────────────────────────────────────────────
@@ -185,6 +678,18 @@ func mapSystemMetrics(ip string, m *zte.OLTSystemMetrics) *SystemMetricsResponse
 	}
 }
 
+func mapCard(c *zte.CardMetrics) CardResponse {
+	return CardResponse{
+		Slot:               c.Slot,
+		Type:               c.Type,
+		Status:             c.Status.String(),
+		CPUUsagePercent:    c.CPUUsagePercent,
+		TemperatureCelsius: c.TemperatureCelsius,
+		MemoryUsagePercent: c.MemoryUsagePercent,
+		MemoryTotalKB:      c.MemoryTotalKB,
+	}
+}
+
 func mapPONPort(ip string, p *zte.PONPortMetrics) PONPortResponse {
 	return PONPortResponse{
 		IPAddress:   ip,
@@ -192,23 +697,35 @@ func mapPONPort(ip string, p *zte.PONPortMetrics) PONPortResponse {
 		PortIndex:   p.PortIndex,
 		AdminStatus: p.AdminStatus.String(),
 		OperStatus:  p.OperStatus.String(),
-		TxPowerDBm:  p.TxPowerDBm,
-		RxPowerDBm:  p.RxPowerDBm,
+		TxPowerDBm:  zte.NormalizePowerDBm(p.TxPowerDBm),
+		RxPowerDBm:  zte.NormalizePowerDBm(p.RxPowerDBm),
 		ONTCount:    p.ONTCount,
 	}
 }
 
 func mapONT(ip string, o *zte.ONTMetrics) ONTResponse {
-	return ONTResponse{
+	resp := ONTResponse{
 		IPAddress:      ip,
 		Timestamp:      o.Timestamp,
 		PONPortIndex:   o.PONPortIndex,
 		ONTIndex:       o.ONTIndex,
+		Slot:           o.Slot,
+		PONPort:        o.PONPort,
+		ONTID:          o.ONTID,
 		SerialNumber:   o.SerialNumber,
+		Name:           o.Name,
 		OperStatus:     o.OperStatus.String(),
-		RxPowerDBm:     o.RxPowerDBm,
-		TxPowerDBm:     o.TxPowerDBm,
+		RxPowerDBm:     zte.NormalizePowerDBm(o.RxPowerDBm),
+		TxPowerDBm:     zte.NormalizePowerDBm(o.TxPowerDBm),
 		DistanceMeters: o.DistanceMeters,
 		Description:    o.Description,
+		LineProfile:    o.LineProfile,
+		ServiceProfile: o.ServiceProfile,
+	}
+	resp.DistanceImplausible = o.DistanceImplausible
+
+	if !o.OperStatus.IsOnline() && o.LastDownCause != zte.ONTDownCauseNone {
+		resp.LastDownCause = o.LastDownCause.String()
 	}
+	return resp
 }
@@ -0,0 +1,1051 @@
+package olt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	devicemodel "github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/device/repository"
+	"github.com/yourorg/nms-go/internal/features/olt"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
+)
+
+// mockSNMPClient serves canned walk results keyed by base OID and GET
+// results keyed by scalar OID.
+type mockSNMPClient struct {
+	walkResults map[string][]gosnmp.SnmpPDU
+	walkErrs    map[string]error
+	getResults  map[string]gosnmp.SnmpPDU
+	gets        [][]string
+	timeouts    []time.Duration
+	retries     []int
+	maxReps     []uint32
+	communities []string
+}
+
+func (m *mockSNMPClient) SetRetries(n int) { m.retries = append(m.retries, n) }
+
+func (m *mockSNMPClient) SetMaxRepetitions(n uint32) { m.maxReps = append(m.maxReps, n) }
+
+func (m *mockSNMPClient) Connect(_ context.Context, _, community string, _ gosnmp.SnmpVersion, timeout time.Duration) error {
+	m.timeouts = append(m.timeouts, timeout)
+	m.communities = append(m.communities, community)
+	return nil
+}
+
+func (m *mockSNMPClient) Disconnect() error { return nil }
+
+func (m *mockSNMPClient) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	m.gets = append(m.gets, oids)
+	packet := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		if pdu, ok := m.getResults[oid]; ok {
+			packet.Variables = append(packet.Variables, pdu)
+		}
+	}
+	return packet, nil
+}
+
+// identityGets counts the GETs that read sysObjectID, i.e. full vendor detections.
+func (m *mockSNMPClient) identityGets() int {
+	n := 0
+	for _, oids := range m.gets {
+		for _, oid := range oids {
+			if oid == zte.OIDSysObjectID {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func (m *mockSNMPClient) Walk(oid string, fn gosnmp.WalkFunc) error {
+	if err := m.walkErrs[oid]; err != nil {
+		return err
+	}
+	for _, pdu := range m.walkResults[oid] {
+		if err := fn(pdu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockSNMPClient) GetBulk(_ []string, _ uint8, _ uint32) (*gosnmp.SnmpPacket, error) {
+	return &gosnmp.SnmpPacket{}, nil
+}
+
+func pduInt(name string, value int) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.Integer, Value: value}
+}
+
+func pduOctetString(name string, value []byte) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.OctetString, Value: value}
+}
+
+func ontInfoMock() *mockSNMPClient {
+	const pon = ".268501248"
+	return &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTInfoDescription: {
+				pduOctetString(zte.OIDZTEONTInfoDescription+pon+".1", []byte("ACC-10023 Budi")),
+				pduOctetString(zte.OIDZTEONTInfoDescription+pon+".2", []byte("ACC-20045 Sari")),
+				pduOctetString(zte.OIDZTEONTInfoDescription+pon+".3", []byte("acc-10023-b backup line")),
+			},
+			zte.OIDZTEONTInfoSerialNumber: {
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+pon+".1", []byte("ZTEGC1234567")),
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+pon+".2", []byte("ZTEGC7654321")),
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+pon+".3", []byte("HWTC1A2B3C4D")),
+			},
+		},
+	}
+}
+
+func TestSearchONTs_MatchesDescriptionCaseInsensitive(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(ontInfoMock(), config.OLTConfig{})
+
+	result, err := svc.SearchONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, "Acc-10023")
+	require.NoError(t, err)
+
+	require.Equal(t, 2, result.Total)
+	assert.Equal(t, 1, result.Matches[0].ONTID)
+	assert.Equal(t, "ACC-10023 Budi", result.Matches[0].Description)
+	assert.Equal(t, 3, result.Matches[1].ONTID)
+	assert.Equal(t, "10.0.0.1", result.IPAddress)
+}
+
+func TestSearchONTs_MatchesSerial(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(ontInfoMock(), config.OLTConfig{})
+
+	result, err := svc.SearchONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, "gc7654")
+	require.NoError(t, err)
+
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "ZTEGC7654321", result.Matches[0].SerialNumber)
+}
+
+func TestSearchONTs_NoMatch(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(ontInfoMock(), config.OLTConfig{})
+
+	result, err := svc.SearchONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, "ACC-99999")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.Total)
+	assert.NotNil(t, result.Matches)
+	assert.Empty(t, result.Matches)
+}
+
+func ponPortMock() *mockSNMPClient {
+	const port1, port2, port3 = 268501248, 268501504, 268501760
+	serial := func(port, ont int) gosnmp.SnmpPDU {
+		return pduOctetString(fmt.Sprintf("%s.%d.%d", zte.OIDZTEONTInfoSerialNumber, port, ont), []byte("ZTEGC0000000"))
+	}
+	return &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEPONPortONTCount: {
+				pduInt(fmt.Sprintf("%s.%d", zte.OIDZTEPONPortONTCount, port1), 2), // matches
+				pduInt(fmt.Sprintf("%s.%d", zte.OIDZTEPONPortONTCount, port2), 5), // stale counter
+				pduInt(fmt.Sprintf("%s.%d", zte.OIDZTEPONPortONTCount, port3), 1), // no ONTs walked
+			},
+			zte.OIDZTEONTInfoSerialNumber: {
+				serial(port1, 1), serial(port1, 2),
+				serial(port2, 1), serial(port2, 2), serial(port2, 3),
+			},
+		},
+	}
+}
+
+func portsByIndex(resp *olt.PONPortListResponse) map[int]olt.PONPortResponse {
+	ports := make(map[int]olt.PONPortResponse, len(resp.PonPorts))
+	for _, p := range resp.PonPorts {
+		ports[p.PortIndex] = p
+	}
+	return ports
+}
+
+func TestGetPONPorts_ReconcilesONTCounts(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(ponPortMock(), config.OLTConfig{})
+
+	resp, err := svc.GetPONPorts(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+	require.Len(t, resp.PonPorts, 3)
+
+	ports := portsByIndex(resp)
+
+	ok := ports[268501248]
+	require.NotNil(t, ok.ActualONTCount)
+	assert.Equal(t, 2, *ok.ActualONTCount)
+	assert.False(t, ok.ONTCountMismatch)
+
+	stale := ports[268501504]
+	require.NotNil(t, stale.ActualONTCount)
+	assert.Equal(t, 5, stale.ONTCount)
+	assert.Equal(t, 3, *stale.ActualONTCount)
+	assert.True(t, stale.ONTCountMismatch)
+
+	empty := ports[268501760]
+	require.NotNil(t, empty.ActualONTCount)
+	assert.Equal(t, 0, *empty.ActualONTCount)
+	assert.True(t, empty.ONTCountMismatch)
+
+	assert.Equal(t, 2, resp.ONTCountMismatches)
+}
+
+func TestGetPONPorts_ONTTableUnavailable(t *testing.T) {
+	mock := ponPortMock()
+	mock.walkErrs = map[string]error{zte.OIDZTEONTInfoName: errors.New("timeout")}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetPONPorts(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	for _, p := range resp.PonPorts {
+		assert.Nil(t, p.ActualONTCount)
+		assert.False(t, p.ONTCountMismatch)
+	}
+	assert.Equal(t, 0, resp.ONTCountMismatches)
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "ONT count reconciliation skipped")
+}
+
+func TestGetPONPorts_ColumnWalkFails(t *testing.T) {
+	mock := ponPortMock()
+	mock.walkErrs = map[string]error{zte.OIDZTEPONPortTxPower: errors.New("request timeout")}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetPONPorts(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	require.Len(t, resp.PonPorts, 3)
+	assert.Equal(t, 2, resp.ONTCountMismatches, "reconciliation still runs")
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "tx_power")
+	assert.Contains(t, resp.Warnings[0], "request timeout")
+}
+
+func TestGetPONPort_ReturnsPortWithItsONTs(t *testing.T) {
+	mock := ponPortMock()
+	mock.walkResults[zte.OIDZTEPONPortOperStatus] = []gosnmp.SnmpPDU{
+		pduInt(zte.OIDZTEPONPortOperStatus+".268501248", 1),
+		pduInt(zte.OIDZTEPONPortOperStatus+".268501504", 1),
+	}
+	mock.walkResults[zte.OIDZTEONTOperStatus] = []gosnmp.SnmpPDU{
+		pduInt(zte.OIDZTEONTOperStatus+".268501250", int(zte.ONTStatusOffline)),
+		pduInt(zte.OIDZTEONTOperStatus+".268501249", int(zte.ONTStatusWorking)),
+		pduInt(zte.OIDZTEONTOperStatus+".268501505", int(zte.ONTStatusWorking)), // other port
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetPONPort(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 268501248)
+	require.NoError(t, err)
+
+	assert.Equal(t, 268501248, resp.Port.PortIndex)
+	assert.Equal(t, 2, resp.Port.ONTCount)
+	require.NotNil(t, resp.Port.ActualONTCount)
+	assert.Equal(t, 2, *resp.Port.ActualONTCount)
+	assert.False(t, resp.Port.ONTCountMismatch)
+
+	require.Len(t, resp.ONTs, 2)
+	assert.Equal(t, 268501249, resp.ONTs[0].ONTIndex)
+	assert.Equal(t, "working", resp.ONTs[0].OperStatus)
+	assert.Equal(t, 268501250, resp.ONTs[1].ONTIndex)
+	for _, o := range resp.ONTs {
+		assert.Equal(t, 268501248, o.PONPortIndex)
+	}
+	assert.Len(t, mock.timeouts, 1, "port and ONTs share one session")
+}
+
+func TestGetPONPort_UnknownPort(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(ponPortMock(), config.OLTConfig{})
+
+	_, err := svc.GetPONPort(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 268502016)
+	assert.ErrorIs(t, err, olt.ErrPONPortNotFound)
+}
+
+func servicePortMock() *mockSNMPClient {
+	vlan := func(pon, ont, id, vlan int) gosnmp.SnmpPDU {
+		return pduInt(fmt.Sprintf("%s.%d.%d.%d", zte.OIDZTEServicePortVLAN, pon, ont, id), vlan)
+	}
+	return &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEServicePortVLAN: {
+				vlan(268501248, 1, 1, 100),
+				vlan(268501248, 1, 2, 200),
+				vlan(268501248, 2, 1, 101),
+				vlan(268501504, 1, 1, 300),
+			},
+		},
+	}
+}
+
+func TestGetServicePorts_FiltersByPortAndONT(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(servicePortMock(), config.OLTConfig{})
+
+	all, err := svc.GetServicePorts(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, all.Total)
+
+	resp, err := svc.GetServicePorts(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 268501248, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, resp.Total)
+	assert.Equal(t, olt.ServicePortResponse{
+		PONPortIndex:  268501248,
+		ONTID:         1,
+		ONTIndex:      268501249,
+		ServicePortID: 2,
+		VLAN:          200,
+	}, resp.ServicePorts[1])
+	assert.Equal(t, 100, resp.ServicePorts[0].VLAN)
+}
+
+func TestGetCards_ListsSlots(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTECardType: {
+				pduOctetString(zte.OIDZTECardType+".3", []byte("SMXA")),
+				pduOctetString(zte.OIDZTECardType+".2", []byte("GTGO")),
+			},
+			zte.OIDZTECardStatus: {
+				pduInt(zte.OIDZTECardStatus+".2", int(zte.CardStatusInService)),
+				pduInt(zte.OIDZTECardStatus+".3", int(zte.CardStatusFaulty)),
+			},
+			zte.OIDZTECardCPUUsage: {
+				pduInt(zte.OIDZTECardCPUUsage+".2", 22),
+				pduInt(zte.OIDZTECardCPUUsage+".3", 17),
+			},
+		},
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{SystemTimeout: 3 * time.Second})
+
+	resp, err := svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.0.0.1", resp.IPAddress)
+	assert.False(t, resp.Timestamp.IsZero())
+	require.Equal(t, 2, resp.Count)
+	assert.Equal(t, olt.CardResponse{Slot: 2, Type: "GTGO", Status: "in_service", CPUUsagePercent: 22}, resp.Cards[0])
+	assert.Equal(t, olt.CardResponse{Slot: 3, Type: "SMXA", Status: "faulty", CPUUsagePercent: 17}, resp.Cards[1])
+	assert.Empty(t, resp.Warnings)
+	assert.Equal(t, []time.Duration{3 * time.Second}, mock.timeouts, "cards use the system timeout")
+}
+
+func TestGetONTStatus_SplitsByPhaseState(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268435456", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268435457", int(zte.ONTStatusDyingGasp)),
+				pduInt(zte.OIDZTEONTOperStatus+".268435458", int(zte.ONTStatusLOS)),
+			},
+		},
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetONTStatus(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Up, 1)
+	assert.Equal(t, "working", resp.Up[0].OperStatus)
+	assert.Len(t, resp.Down, 2)
+}
+
+func opticalMock() *mockSNMPClient {
+	return &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501250", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501251", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501252", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501253", int(zte.ONTStatusLOS)),
+			},
+			zte.OIDZTEONTRxPower: {
+				pduInt(zte.OIDZTEONTRxPower+".268501249", -185), // healthy
+				pduInt(zte.OIDZTEONTRxPower+".268501250", -262), // weak
+				pduInt(zte.OIDZTEONTRxPower+".268501251", -291), // below sensitivity
+				pduInt(zte.OIDZTEONTRxPower+".268501252", -52),  // overload
+			},
+			zte.OIDZTEONTTxPower: {
+				pduInt(zte.OIDZTEONTTxPower+".268501249", 21),
+			},
+		},
+	}
+}
+
+func TestGetOpticalReport_ClassifiesEachONT(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(opticalMock(), config.OLTConfig{})
+
+	resp, err := svc.GetOpticalReport(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	require.Len(t, resp.ONTs, 5)
+	assert.Equal(t, 5, resp.Total)
+	indexes := make([]int, len(resp.ONTs))
+	classes := make([]olt.OpticalClass, len(resp.ONTs))
+	for i, o := range resp.ONTs {
+		indexes[i], classes[i] = o.ONTIndex, o.Class
+	}
+	assert.Equal(t, []int{268501249, 268501250, 268501251, 268501252, 268501253}, indexes, "ONTs are ordered")
+	assert.Equal(t, []olt.OpticalClass{
+		olt.OpticalOK,
+		olt.OpticalWarning,
+		olt.OpticalCritical,
+		olt.OpticalCritical,
+		olt.OpticalOffline,
+	}, classes)
+
+	assert.Equal(t, map[olt.OpticalClass]int{
+		olt.OpticalOK:       1,
+		olt.OpticalWarning:  1,
+		olt.OpticalCritical: 2,
+		olt.OpticalOffline:  1,
+		olt.OpticalUnknown:  0,
+	}, resp.Summary)
+
+	healthy := resp.ONTs[0]
+	require.NotNil(t, healthy.RxPowerDBm)
+	assert.InDelta(t, -18.5, *healthy.RxPowerDBm, 0.01)
+	require.NotNil(t, healthy.TxPowerDBm)
+	assert.InDelta(t, 2.1, *healthy.TxPowerDBm, 0.01)
+}
+
+func TestGetOpticalReport_UnreadRxPowerIsUnknown(t *testing.T) {
+	mock := opticalMock()
+	mock.walkErrs = map[string]error{zte.OIDZTEONTRxPower: errors.New("request timeout")}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetOpticalReport(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, resp.Summary[olt.OpticalUnknown], "online ONTs are not graded from a zeroed reading")
+	assert.Equal(t, 1, resp.Summary[olt.OpticalOffline])
+	assert.Zero(t, resp.Summary[olt.OpticalCritical])
+	for _, o := range resp.ONTs {
+		assert.Nil(t, o.RxPowerDBm)
+	}
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "rx_power")
+}
+
+func TestGetONTs_LastDownCauseOnlyForOfflineONTs(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501250", int(zte.ONTStatusOffline)),
+			},
+			zte.OIDZTEONTLastDownCause: {
+				pduInt(zte.OIDZTEONTLastDownCause+".268501248.1", int(zte.ONTDownCauseReboot)),
+				pduInt(zte.OIDZTEONTLastDownCause+".268501248.2", int(zte.ONTDownCauseDyingGasp)),
+			},
+		},
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0)
+	require.NoError(t, err)
+	require.Len(t, resp.ONTs, 2)
+
+	for _, o := range resp.ONTs {
+		switch o.ONTIndex {
+		case 268501249:
+			assert.Empty(t, o.LastDownCause, "online ONT keeps no down cause")
+		case 268501250:
+			assert.Equal(t, "dying gasp (power loss)", o.LastDownCause)
+		}
+	}
+}
+
+func TestGetONTs_IncludesProfiles(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501250", int(zte.ONTStatusWorking)),
+			},
+			zte.OIDZTEONTLineProfile: {
+				pduOctetString(zte.OIDZTEONTLineProfile+".268501248.1", []byte("LP-100M")),
+			},
+			zte.OIDZTEONTServiceProfile: {
+				pduOctetString(zte.OIDZTEONTServiceProfile+".268501248.1", []byte("SP-HSI")),
+			},
+		},
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0)
+	require.NoError(t, err)
+	require.Len(t, resp.ONTs, 2)
+
+	for _, o := range resp.ONTs {
+		raw, err := json.Marshal(o)
+		require.NoError(t, err)
+
+		switch o.ONTIndex {
+		case 268501249:
+			assert.Contains(t, string(raw), `"line_profile":"LP-100M","service_profile":"SP-HSI"`)
+		case 268501250:
+			assert.Contains(t, string(raw), `"line_profile":"","service_profile":""`, "unassigned profiles are empty")
+		}
+	}
+}
+
+func TestGetONTs_ColumnWalkFails(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", int(zte.ONTStatusWorking)),
+			},
+			zte.OIDZTEONTRxPower: {
+				pduInt(zte.OIDZTEONTRxPower+".268501249", -185),
+			},
+		},
+		walkErrs: map[string]error{zte.OIDZTEONTDistance: errors.New("request timeout")},
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	resp, err := svc.GetONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0)
+	require.NoError(t, err)
+
+	require.Len(t, resp.ONTs, 1)
+	assert.Equal(t, "working", resp.ONTs[0].OperStatus)
+	require.NotNil(t, resp.ONTs[0].RxPowerDBm)
+	assert.InDelta(t, -18.5, *resp.ONTs[0].RxPowerDBm, 0.01)
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "distance")
+
+	status, err := svc.GetONTStatus(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Len(t, status.Up, 1)
+	assert.Equal(t, resp.Warnings, status.Warnings)
+}
+
+func TestGetONTs_TruncatedAtMaxONTs(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501250", int(zte.ONTStatusWorking)),
+				pduInt(zte.OIDZTEONTOperStatus+".268501251", int(zte.ONTStatusLOS)),
+			},
+		},
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{MaxONTs: 2})
+
+	resp, err := svc.GetONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0)
+	require.NoError(t, err)
+	assert.True(t, resp.Truncated)
+	assert.Equal(t, 2, resp.Total)
+	assert.Empty(t, resp.Warnings)
+
+	status, err := svc.GetONTStatus(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.True(t, status.Truncated)
+	assert.Len(t, status.Up, 2)
+	assert.Empty(t, status.Down)
+
+	full, err := olt.NewOLTServiceForTest(mock, config.OLTConfig{}).GetONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0)
+	require.NoError(t, err)
+	assert.False(t, full.Truncated)
+	assert.Equal(t, 3, full.Total)
+}
+
+func TestGetONTs_DistanceUnitFromConfig(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTDistance: {
+				pduInt(zte.OIDZTEONTDistance+".268501249", 15000),  // 1.5 km
+				pduInt(zte.OIDZTEONTDistance+".268501250", 900000), // 90 km
+			},
+		},
+	}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{ONTDistanceUnit: "dm"})
+
+	resp, err := svc.GetONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0)
+	require.NoError(t, err)
+	require.Len(t, resp.ONTs, 2)
+
+	for _, o := range resp.ONTs {
+		switch o.ONTIndex {
+		case 268501249:
+			assert.Equal(t, 1500, o.DistanceMeters)
+			assert.False(t, o.DistanceImplausible)
+		case 268501250:
+			assert.Equal(t, 90000, o.DistanceMeters)
+			assert.True(t, o.DistanceImplausible)
+		}
+	}
+}
+
+func TestONTResponse_PowerMarshalsWithoutNegativeZeroOrNaN(t *testing.T) {
+	resp := olt.ONTResponse{
+		RxPowerDBm: zte.NormalizePowerDBm(math.Copysign(0, -1)),
+		TxPowerDBm: zte.NormalizePowerDBm(0 / zeroDivisor()),
+	}
+
+	body, err := json.Marshal(resp)
+	require.NoError(t, err, "NaN must not reach encoding/json")
+	assert.Contains(t, string(body), `"rx_power_dbm":0,`)
+	assert.Contains(t, string(body), `"tx_power_dbm":null`)
+}
+
+// zeroDivisor hides the zero from the compiler so 0/zeroDivisor() is NaN.
+func zeroDivisor() float64 { return 0 }
+
+func TestGetONTs_AllColumnWalksFail(t *testing.T) {
+	mock := &mockSNMPClient{walkErrs: map[string]error{
+		zte.OIDZTEONTOperStatus: errors.New("timeout"),
+		zte.OIDZTEONTRxPower:    errors.New("timeout"),
+		zte.OIDZTEONTTxPower:    errors.New("timeout"),
+		zte.OIDZTEONTDistance:   errors.New("timeout"),
+	}}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	_, err := svc.GetONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get ONT metrics")
+}
+
+func TestOperationsUseConfiguredTimeouts(t *testing.T) {
+	cfg := config.OLTConfig{
+		SystemTimeout: 3 * time.Second,
+		PONTimeout:    20 * time.Second,
+		ONTTimeout:    90 * time.Second,
+	}
+	target := olt.SNMPTarget{IP: "10.0.0.1"}
+
+	tests := []struct {
+		name string
+		call func(svc olt.OLTService) error
+		want time.Duration
+	}{
+		{"system", func(svc olt.OLTService) error {
+			_, err := svc.GetSystemMetrics(context.Background(), target)
+			return err
+		}, cfg.SystemTimeout},
+		{"pon ports", func(svc olt.OLTService) error {
+			_, err := svc.GetPONPorts(context.Background(), target)
+			return err
+		}, cfg.PONTimeout},
+		{"onts", func(svc olt.OLTService) error {
+			_, err := svc.GetONTs(context.Background(), target, 0)
+			return err
+		}, cfg.ONTTimeout},
+		{"ont status", func(svc olt.OLTService) error {
+			_, err := svc.GetONTStatus(context.Background(), target)
+			return err
+		}, cfg.ONTTimeout},
+		{"ont search", func(svc olt.OLTService) error {
+			_, err := svc.SearchONTs(context.Background(), target, "acc")
+			return err
+		}, cfg.ONTTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockSNMPClient{}
+			require.NoError(t, tt.call(olt.NewOLTServiceForTest(mock, cfg)))
+			assert.Equal(t, []time.Duration{tt.want}, mock.timeouts)
+		})
+	}
+}
+
+func TestUnsetTimeoutsFallBackToDefaults(t *testing.T) {
+	mock := &mockSNMPClient{}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{PONTimeout: 30 * time.Second})
+
+	_, err := svc.GetSystemMetrics(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+	_, err = svc.GetPONPorts(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{5 * time.Second, 30 * time.Second}, mock.timeouts)
+}
+
+func identityMock(sysObjectID, sysDescr string, uptimeTicks uint32) *mockSNMPClient {
+	return &mockSNMPClient{
+		getResults: map[string]gosnmp.SnmpPDU{
+			zte.OIDSysObjectID: {Name: "." + zte.OIDSysObjectID, Type: gosnmp.ObjectIdentifier, Value: "." + sysObjectID},
+			zte.OIDSysDescr:    {Name: "." + zte.OIDSysDescr, Type: gosnmp.OctetString, Value: []byte(sysDescr)},
+			zte.OIDSysUpTime:   {Name: "." + zte.OIDSysUpTime, Type: gosnmp.TimeTicks, Value: uptimeTicks},
+		},
+	}
+}
+
+func setUptime(m *mockSNMPClient, ticks uint32) {
+	m.getResults[zte.OIDSysUpTime] = gosnmp.SnmpPDU{Name: "." + zte.OIDSysUpTime, Type: gosnmp.TimeTicks, Value: ticks}
+}
+
+func TestTargetOverridesReachClient(t *testing.T) {
+	mock := &mockSNMPClient{}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{SystemTimeout: 3 * time.Second})
+
+	retries := 4
+	_, err := svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1", Timeout: "45s", Retries: &retries})
+	require.NoError(t, err)
+
+	_, err = svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{45 * time.Second, 3 * time.Second}, mock.timeouts)
+	assert.Equal(t, []int{4}, mock.retries, "the client default is kept without an override")
+}
+
+func TestTargetMaxRepetitionsReachesClient(t *testing.T) {
+	mock := &mockSNMPClient{}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	maxReps := 5
+	_, err := svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1", MaxRepetitions: &maxReps})
+	require.NoError(t, err)
+
+	_, err = svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint32{5}, mock.maxReps, "the client default is kept without an override")
+}
+
+// fakeCredentialStore serves devices from a map.
+type fakeCredentialStore struct {
+	devices map[string]*devicemodel.Device
+	lookups []string
+}
+
+func (f *fakeCredentialStore) GetByID(_ context.Context, id string) (*devicemodel.Device, error) {
+	f.lookups = append(f.lookups, id)
+	if d, ok := f.devices[id]; ok {
+		return d, nil
+	}
+	if id == "broken" {
+		return nil, errors.New("connection reset by peer")
+	}
+	return nil, fmt.Errorf("%w: %s", repository.ErrDeviceNotFound, id)
+}
+
+func credentialStore() *fakeCredentialStore {
+	return &fakeCredentialStore{devices: map[string]*devicemodel.Device{
+		"olt-1": {ID: "olt-1", IPAddress: "10.0.0.1", Credentials: &devicemodel.DeviceCredentials{SNMPCommunity: "stored-secret", SNMPVersion: "2c"}},
+		"olt-2": {ID: "olt-2", IPAddress: "10.0.0.2"},
+	}}
+}
+
+func TestCredentials_BodyByDefault(t *testing.T) {
+	mock := &mockSNMPClient{}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	_, err := svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1", Community: "body-secret", DeviceID: "olt-1"})
+	require.NoError(t, err)
+	_, err = svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"body-secret", "public"}, mock.communities, "device_id is ignored without a credential store")
+}
+
+func TestCredentials_ResolvedFromStore(t *testing.T) {
+	mock := &mockSNMPClient{}
+	store := credentialStore()
+	svc := olt.NewOLTServiceWithCredentialsForTest(mock, config.OLTConfig{}, store)
+
+	_, err := svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1", Community: "body-secret", DeviceID: "olt-1"})
+	require.NoError(t, err)
+	_, err = svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1", Community: "body-secret"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"stored-secret", "body-secret"}, mock.communities, "only targets with a device_id are looked up")
+	assert.Equal(t, []string{"olt-1"}, store.lookups)
+}
+
+func TestCredentials_StoredCommunityIsDecrypted(t *testing.T) {
+	c, err := crypto.NewCipherFromKey("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	crypto.SetDefault(c)
+	t.Cleanup(func() { crypto.SetDefault(nil) })
+
+	store := credentialStore()
+	store.devices["olt-1"].Credentials.SNMPCommunity, err = c.Encrypt("stored-secret")
+	require.NoError(t, err)
+	mock := &mockSNMPClient{}
+	svc := olt.NewOLTServiceWithCredentialsForTest(mock, config.OLTConfig{}, store)
+
+	_, err = svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1", DeviceID: "olt-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stored-secret"}, mock.communities)
+}
+
+func TestCredentials_StoreErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		target olt.SNMPTarget
+		want   error
+	}{
+		{"unknown device", olt.SNMPTarget{IP: "10.0.0.1", DeviceID: "missing"}, olt.ErrDeviceNotFound},
+		{"other device's IP", olt.SNMPTarget{IP: "10.0.0.9", DeviceID: "olt-1"}, olt.ErrInvalidOverride},
+		{"no community stored", olt.SNMPTarget{IP: "10.0.0.2", DeviceID: "olt-2"}, olt.ErrNoStoredCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockSNMPClient{}
+			svc := olt.NewOLTServiceWithCredentialsForTest(mock, config.OLTConfig{}, credentialStore())
+
+			_, err := svc.GetCards(context.Background(), tt.target)
+			require.ErrorIs(t, err, tt.want)
+			assert.Empty(t, mock.communities, "no session is opened")
+		})
+	}
+
+	t.Run("failed lookup", func(t *testing.T) {
+		mock := &mockSNMPClient{}
+		svc := olt.NewOLTServiceWithCredentialsForTest(mock, config.OLTConfig{}, credentialStore())
+
+		_, err := svc.GetCards(context.Background(), olt.SNMPTarget{IP: "10.0.0.1", DeviceID: "broken"})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, olt.ErrDeviceNotFound, "only a missing device is a 404")
+		assert.Empty(t, mock.communities)
+	})
+}
+
+func TestTargetOverridesRejectInvalidValues(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name   string
+		target olt.SNMPTarget
+	}{
+		{"malformed timeout", olt.SNMPTarget{IP: "10.0.0.1", Timeout: "soon"}},
+		{"timeout too short", olt.SNMPTarget{IP: "10.0.0.1", Timeout: "100ms"}},
+		{"timeout too long", olt.SNMPTarget{IP: "10.0.0.1", Timeout: "1h"}},
+		{"negative retries", olt.SNMPTarget{IP: "10.0.0.1", Retries: intPtr(-1)}},
+		{"too many retries", olt.SNMPTarget{IP: "10.0.0.1", Retries: intPtr(6)}},
+		{"zero max repetitions", olt.SNMPTarget{IP: "10.0.0.1", MaxRepetitions: intPtr(0)}},
+		{"too many max repetitions", olt.SNMPTarget{IP: "10.0.0.1", MaxRepetitions: intPtr(51)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockSNMPClient{}
+			svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+			_, err := svc.GetSystemMetrics(context.Background(), tt.target)
+			assert.ErrorIs(t, err, olt.ErrInvalidOverride)
+			assert.Empty(t, mock.timeouts, "no session is opened")
+		})
+	}
+}
+
+func TestDetectVendor(t *testing.T) {
+	tests := []struct {
+		name        string
+		sysObjectID string
+		sysDescr    string
+		want        olt.Vendor
+	}{
+		{"zte by oid", "1.3.6.1.4.1.3902.1082.1001.320.1", "", olt.VendorZTE},
+		{"huawei by oid", ".1.3.6.1.4.1.2011.2.248", "", olt.VendorHuawei},
+		{"enterprise prefix only", "1.3.6.1.4.1.39020.1", "", olt.VendorUnknown},
+		{"zte by descr", "1.3.6.1.4.1.8072.3.2.10", "ZXA10 C320 Software Version V2.1", olt.VendorZTE},
+		{"fiberhome by descr", "", "FiberHome AN5516-04", olt.VendorFiberHome},
+		{"unknown", "", "Linux olt 4.9", olt.VendorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, olt.DetectVendor(tt.sysObjectID, tt.sysDescr))
+		})
+	}
+}
+
+func TestVendorDetection_CachedPerIP(t *testing.T) {
+	mock := identityMock("1.3.6.1.4.1.3902.1082.1001.320.1", "ZTE C320", 5000)
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+	target := olt.SNMPTarget{IP: "10.0.0.1"}
+
+	first, err := svc.GetSystemMetrics(context.Background(), target)
+	require.NoError(t, err)
+	setUptime(mock, 6000)
+	second, err := svc.GetSystemMetrics(context.Background(), target)
+	require.NoError(t, err)
+
+	assert.Equal(t, "zte", first.Vendor)
+	assert.Equal(t, "zte", second.Vendor)
+	assert.Equal(t, 1, mock.identityGets())
+
+	// A different OLT is detected on its own.
+	_, err = svc.GetSystemMetrics(context.Background(), olt.SNMPTarget{IP: "10.0.0.2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, mock.identityGets())
+}
+
+func TestVendorDetection_InvalidatedWhenUptimeDecreases(t *testing.T) {
+	mock := identityMock("1.3.6.1.4.1.3902.1082.1001.320.1", "ZTE C320", 5000)
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+	target := olt.SNMPTarget{IP: "10.0.0.1"}
+
+	_, err := svc.GetPONPorts(context.Background(), target)
+	require.NoError(t, err)
+	require.Equal(t, 1, mock.identityGets())
+
+	// The OLT rebooted and came back as a different vendor's box at the same IP.
+	mock.getResults = identityMock("1.3.6.1.4.1.2011.2.248", "Huawei MA5800", 100).getResults
+
+	_, err = svc.GetPONPorts(context.Background(), target)
+	assert.ErrorIs(t, err, olt.ErrUnsupportedVendor)
+	assert.Equal(t, 2, mock.identityGets())
+}
+
+func TestVendorDetection_ExpiresAfterTTL(t *testing.T) {
+	mock := identityMock("1.3.6.1.4.1.3902.1082.1001.320.1", "ZTE C320", 5000)
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{VendorCacheTTL: time.Nanosecond})
+	target := olt.SNMPTarget{IP: "10.0.0.1"}
+
+	_, err := svc.GetPONPorts(context.Background(), target)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = svc.GetPONPorts(context.Background(), target)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, mock.identityGets())
+}
+
+func TestInvalidateCache_DropsCachedVendor(t *testing.T) {
+	mock := identityMock("1.3.6.1.4.1.3902.1082.1001.320.1", "ZTE C320", 5000)
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+	target := olt.SNMPTarget{IP: "10.0.0.1"}
+
+	_, err := svc.GetPONPorts(context.Background(), target)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, svc.InvalidateCache("10.0.0.2"))
+	assert.Equal(t, 1, svc.InvalidateCache("10.0.0.1"))
+	assert.Equal(t, 0, svc.InvalidateCache("10.0.0.1"))
+
+	_, err = svc.GetPONPorts(context.Background(), target)
+	require.NoError(t, err)
+	assert.Equal(t, 2, mock.identityGets(), "vendor should be re-detected after invalidation")
+}
+
+// sessionCountingClient records the most SNMP sessions open at once. Connect
+// lingers so that concurrent requests overlap.
+type sessionCountingClient struct {
+	mockSNMPClient
+	mu      sync.Mutex
+	open    int
+	maxOpen int
+}
+
+func (m *sessionCountingClient) Connect(_ context.Context, _, _ string, _ gosnmp.SnmpVersion, _ time.Duration) error {
+	m.mu.Lock()
+	m.open++
+	if m.open > m.maxOpen {
+		m.maxOpen = m.open
+	}
+	m.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func (m *sessionCountingClient) Disconnect() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.open--
+	return nil
+}
+
+func (m *sessionCountingClient) Get(_ []string) (*gosnmp.SnmpPacket, error) {
+	return &gosnmp.SnmpPacket{}, nil
+}
+
+func TestSessionLimit_CapsConcurrentConnects(t *testing.T) {
+	mock := &sessionCountingClient{}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{MaxSessions: 3})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := olt.SNMPTarget{IP: fmt.Sprintf("10.0.0.%d", i)}
+			_, err := svc.SearchONTs(context.Background(), target, "")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, mock.maxOpen)
+	assert.Equal(t, 0, mock.open)
+}
+
+// hangingWalkClient is an agent whose walks hang until the session is closed.
+type hangingWalkClient struct {
+	sessionCountingClient
+	walkOnce  sync.Once
+	walking   chan struct{}
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (m *hangingWalkClient) Disconnect() error {
+	m.closeOnce.Do(func() { close(m.closed) })
+	return m.sessionCountingClient.Disconnect()
+}
+
+func (m *hangingWalkClient) Walk(_ string, _ gosnmp.WalkFunc) error {
+	m.walkOnce.Do(func() { close(m.walking) })
+	<-m.closed
+	return errors.New("use of closed network connection")
+}
+
+func (m *hangingWalkClient) openSessions() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.open
+}
+
+func TestShutdown_ClosesOpenSessions(t *testing.T) {
+	mock := &hangingWalkClient{walking: make(chan struct{}), closed: make(chan struct{})}
+	svc := olt.NewOLTServiceForTest(mock, config.OLTConfig{})
+
+	reqErr := make(chan error, 1)
+	go func() {
+		_, err := svc.SearchONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, "acc")
+		reqErr <- err
+	}()
+	<-mock.walking
+	require.Equal(t, 1, mock.openSessions())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := svc.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, mock.openSessions())
+
+	select {
+	case err := <-reqErr:
+		assert.Error(t, err, "the in-flight request fails once its session is closed")
+	case <-time.After(time.Second):
+		t.Fatal("in-flight request did not return after shutdown")
+	}
+
+	_, err = svc.SearchONTs(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"}, "acc")
+	assert.ErrorIs(t, err, olt.ErrServiceClosed)
+}
+
+func TestShutdown_IdleServiceReturnsImmediately(t *testing.T) {
+	svc := olt.NewOLTServiceForTest(&sessionCountingClient{}, config.OLTConfig{})
+
+	require.NoError(t, svc.Shutdown(context.Background()))
+
+	_, err := svc.GetSystemMetrics(context.Background(), olt.SNMPTarget{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, olt.ErrServiceClosed)
+}
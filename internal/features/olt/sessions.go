@@ -0,0 +1,134 @@
+package olt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+	devicemodel "github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
+)
+
+// snmpProtocol labels the telemetry of OLT sessions.
+const snmpProtocol = string(devicemodel.ProtocolSNMP)
+
+// ErrServiceClosed is returned for requests that arrive after Shutdown.
+var ErrServiceClosed = errors.New("OLT service is shut down")
+
+// sessionLimiter is a counting semaphore bounding the SNMP sessions open at
+// once. Each session holds its own UDP socket, so without a bound a burst of
+// requests can exhaust file descriptors or ephemeral ports. It also tracks
+// the open sessions so that shutdown can close them.
+type sessionLimiter struct {
+	slots chan struct{}
+
+	mu      sync.Mutex
+	open    map[*zte.ZTEOLTClient]struct{}
+	closed  bool
+	drained chan struct{}
+}
+
+func newSessionLimiter(max int) *sessionLimiter {
+	return &sessionLimiter{
+		slots:   make(chan struct{}, max),
+		open:    make(map[*zte.ZTEOLTClient]struct{}),
+		drained: make(chan struct{}),
+	}
+}
+
+// acquire blocks until a session slot is free or ctx is done.
+func (l *sessionLimiter) acquire(ctx context.Context) error {
+	if l.isClosed() {
+		return ErrServiceClosed
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a free SNMP session: %w", ctx.Err())
+	}
+}
+
+// release frees a slot taken by acquire.
+func (l *sessionLimiter) release() {
+	<-l.slots
+}
+
+// track registers a connected client holding an acquired slot. It fails, and
+// the caller must close the client and release the slot, once shutdown has
+// begun.
+func (l *sessionLimiter) track(client *zte.ZTEOLTClient) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrServiceClosed
+	}
+	l.open[client] = struct{}{}
+	return nil
+}
+
+// end closes a tracked client and frees its slot. It is a no-op when shutdown
+// already closed the client.
+func (l *sessionLimiter) end(client *zte.ZTEOLTClient) {
+	l.mu.Lock()
+	_, ok := l.open[client]
+	delete(l.open, client)
+	if l.closed && len(l.open) == 0 {
+		l.closeDrained()
+	}
+	l.mu.Unlock()
+
+	if ok {
+		telemetry.Disconnect(client, snmpProtocol, client.Host())
+		l.release()
+	}
+}
+
+// shutdown refuses new sessions and waits for open ones to end. When ctx is
+// done first, the remaining sessions are closed under their requests, which
+// then fail.
+func (l *sessionLimiter) shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	if len(l.open) == 0 {
+		l.closeDrained()
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-l.drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	open := l.open
+	l.open = make(map[*zte.ZTEOLTClient]struct{})
+	l.closeDrained()
+	l.mu.Unlock()
+
+	for client := range open {
+		telemetry.Disconnect(client, snmpProtocol, client.Host())
+		l.release()
+	}
+	return fmt.Errorf("closed %d open SNMP sessions: %w", len(open), ctx.Err())
+}
+
+func (l *sessionLimiter) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// closeDrained closes the drained channel once. l.mu must be held.
+func (l *sessionLimiter) closeDrained() {
+	select {
+	case <-l.drained:
+	default:
+		close(l.drained)
+	}
+}
@@ -0,0 +1,141 @@
+package olt
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedVendor is returned when the target is identified as an OLT
+// from a vendor other than ZTE.
+var ErrUnsupportedVendor = errors.New("unsupported OLT vendor")
+
+// Vendor identifies the manufacturer of an OLT.
+type Vendor string
+
+const (
+	VendorUnknown   Vendor = "unknown"
+	VendorZTE       Vendor = "zte"
+	VendorHuawei    Vendor = "huawei"
+	VendorFiberHome Vendor = "fiberhome"
+	VendorNokia     Vendor = "nokia"
+)
+
+// vendorEnterprises maps IANA private enterprise numbers to vendors.
+var vendorEnterprises = map[string]Vendor{
+	"3902": VendorZTE,
+	"2011": VendorHuawei,
+	"5875": VendorFiberHome,
+	"637":  VendorNokia,
+}
+
+// vendorKeywords is the sysDescr fallback for agents that report a generic sysObjectID.
+var vendorKeywords = []struct {
+	keyword string
+	vendor  Vendor
+}{
+	{"zte", VendorZTE},
+	{"zxa10", VendorZTE},
+	{"huawei", VendorHuawei},
+	{"fiberhome", VendorFiberHome},
+	{"nokia", VendorNokia},
+	{"alcatel", VendorNokia},
+}
+
+const enterprisesPrefix = "1.3.6.1.4.1."
+
+// DetectVendor identifies the OLT vendor from its sysObjectID, falling back to
+// keywords in sysDescr.
+func DetectVendor(sysObjectID, sysDescr string) Vendor {
+	oid := strings.TrimPrefix(sysObjectID, ".")
+	if strings.HasPrefix(oid, enterprisesPrefix) {
+		enterprise, _, _ := strings.Cut(strings.TrimPrefix(oid, enterprisesPrefix), ".")
+		if vendor, ok := vendorEnterprises[enterprise]; ok {
+			return vendor
+		}
+	}
+
+	descr := strings.ToLower(sysDescr)
+	for _, k := range vendorKeywords {
+		if strings.Contains(descr, k.keyword) {
+			return k.vendor
+		}
+	}
+
+	return VendorUnknown
+}
+
+// vendorCache remembers the detected vendor per OLT IP. An entry is dropped
+// when its TTL expires or when the OLT's uptime goes backwards, since a
+// reboot may have brought up different hardware or firmware at the same IP.
+type vendorCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]vendorCacheEntry
+}
+
+type vendorCacheEntry struct {
+	vendor      Vendor
+	uptimeTicks uint32
+	expiresAt   time.Time
+}
+
+func newVendorCache(ttl time.Duration) *vendorCache {
+	return &vendorCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]vendorCacheEntry),
+	}
+}
+
+// get returns the cached vendor for ip given its current uptime. A hit
+// records the new uptime so a later reboot is still noticed.
+func (c *vendorCache) get(ip string, uptimeTicks uint32) (Vendor, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[ip]
+	if !ok {
+		return VendorUnknown, false
+	}
+	if c.now().After(entry.expiresAt) || uptimeTicks < entry.uptimeTicks {
+		delete(c.entries, ip)
+		return VendorUnknown, false
+	}
+
+	entry.uptimeTicks = uptimeTicks
+	c.entries[ip] = entry
+	return entry.vendor, true
+}
+
+func (c *vendorCache) put(ip string, vendor Vendor, uptimeTicks uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[ip] = vendorCacheEntry{
+		vendor:      vendor,
+		uptimeTicks: uptimeTicks,
+		expiresAt:   c.now().Add(c.ttl),
+	}
+}
+
+// invalidate drops the entry for ip, or every entry when ip is empty, and
+// returns how many were removed.
+func (c *vendorCache) invalidate(ip string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ip == "" {
+		n := len(c.entries)
+		c.entries = make(map[string]vendorCacheEntry)
+		return n
+	}
+
+	if _, ok := c.entries[ip]; !ok {
+		return 0
+	}
+	delete(c.entries, ip)
+	return 1
+}
@@ -0,0 +1,250 @@
+package tr069
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourorg/nms-go/internal/device/model"
+)
+
+// SessionCookie carries the CWMP session between the HTTP requests of a session.
+const SessionCookie = "cwmp_session"
+
+// sessionTTL bounds how long a CWMP session may stay idle.
+const sessionTTL = 5 * time.Minute
+
+const contentTypeXML = "text/xml; charset=utf-8"
+
+// DeviceResolver links an informing CPE to a registered device by IP address.
+type DeviceResolver interface {
+	GetByIPAddress(ctx context.Context, ipAddress string) (*model.Device, error)
+}
+
+type session struct {
+	serial   string
+	inflight *Task
+	expires  time.Time
+}
+
+// ACS is a minimal CWMP auto-configuration server. It accepts Informs, stores
+// the reported parameters and delivers queued GetParameterValues and
+// SetParameterValues RPCs in the same session.
+type ACS struct {
+	store   Store
+	queue   *TaskQueue
+	devices DeviceResolver
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	now      func() time.Time
+}
+
+// NewACS creates an ACS. devices may be nil when CPEs need not be linked to the registry.
+func NewACS(store Store, queue *TaskQueue, devices DeviceResolver) *ACS {
+	return &ACS{
+		store:    store,
+		queue:    queue,
+		devices:  devices,
+		sessions: make(map[string]*session),
+		now:      time.Now,
+	}
+}
+
+// ServeCWMP handles POST /cwmp
+//
+// A session starts with an Inform. The CPE then posts an empty body or the
+// response to the previous RPC; the ACS answers with the next queued RPC or
+// 204 No Content to end the session.
+func (a *ACS) ServeCWMP(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		token, sess := a.session(c)
+		if sess == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		a.next(c, token, sess)
+		return
+	}
+
+	env, err := ParseEnvelope(body)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if env.Body.Inform != nil {
+		a.handleInform(c, env)
+		return
+	}
+
+	token, sess := a.session(c)
+	if sess == nil {
+		c.Data(http.StatusOK, contentTypeXML, FaultXML(env.Header.ID, FaultRequestDenied, "no active session, send Inform first"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	switch {
+	case env.Body.GetParameterValuesResponse != nil:
+		errMsg := ""
+		if err := a.store.SaveParameters(ctx, sess.serial, env.Body.GetParameterValuesResponse.ParameterList); err != nil {
+			log.Printf("TR-069: failed to save parameters for %s: %v", sess.serial, err)
+			errMsg = err.Error()
+		}
+		a.complete(sess, errMsg)
+
+	case env.Body.SetParameterValuesResponse != nil:
+		errMsg := ""
+		if sess.inflight != nil {
+			if err := a.store.SaveParameters(ctx, sess.serial, sess.inflight.Values); err != nil {
+				log.Printf("TR-069: failed to save parameters for %s: %v", sess.serial, err)
+				errMsg = err.Error()
+			}
+		}
+		a.complete(sess, errMsg)
+
+	case env.Body.Fault != nil:
+		log.Printf("TR-069: CPE %s returned %v", sess.serial, env.Body.Fault)
+		a.complete(sess, env.Body.Fault.Error())
+
+	default:
+		c.Data(http.StatusOK, contentTypeXML, FaultXML(env.Header.ID, FaultMethodNotSupported, "method not supported: "+env.Method()))
+		return
+	}
+
+	a.next(c, token, sess)
+}
+
+func (a *ACS) handleInform(c *gin.Context, env *Envelope) {
+	inform := env.Body.Inform
+	serial := strings.TrimSpace(inform.DeviceID.SerialNumber)
+	if serial == "" {
+		c.Data(http.StatusOK, contentTypeXML, FaultXML(env.Header.ID, FaultRequestDenied, "missing serial number"))
+		return
+	}
+
+	events := make([]string, len(inform.Events))
+	for i, e := range inform.Events {
+		events[i] = e.EventCode
+	}
+
+	ctx := c.Request.Context()
+	cpe := &CPE{
+		SerialNumber: serial,
+		Manufacturer: inform.DeviceID.Manufacturer,
+		OUI:          inform.DeviceID.OUI,
+		ProductClass: inform.DeviceID.ProductClass,
+		IPAddress:    c.ClientIP(),
+		LastEvents:   strings.Join(events, ", "),
+		LastInform:   a.now(),
+	}
+	if a.devices != nil {
+		if device, err := a.devices.GetByIPAddress(ctx, cpe.IPAddress); err == nil {
+			cpe.DeviceID = &device.ID
+		}
+	}
+
+	if err := a.store.UpsertCPE(ctx, cpe); err != nil {
+		log.Printf("TR-069: failed to store CPE %s: %v", serial, err)
+		c.Data(http.StatusOK, contentTypeXML, FaultXML(env.Header.ID, FaultInternalError, "internal error"))
+		return
+	}
+	if err := a.store.SaveParameters(ctx, serial, inform.ParameterList); err != nil {
+		log.Printf("TR-069: failed to save inform parameters for %s: %v", serial, err)
+	}
+
+	token := a.startSession(serial)
+	c.SetCookie(SessionCookie, token, int(sessionTTL.Seconds()), "/", "", false, true)
+	c.Data(http.StatusOK, contentTypeXML, InformResponseXML(env.Header.ID))
+}
+
+// next sends the CPE's next queued RPC, or ends the session when there is none
+func (a *ACS) next(c *gin.Context, token string, sess *session) {
+	task, ok := a.queue.Next(sess.serial)
+	if !ok {
+		a.endSession(token)
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	a.mu.Lock()
+	sess.inflight = &task
+	sess.expires = a.now().Add(sessionTTL)
+	a.mu.Unlock()
+
+	switch task.Type {
+	case TaskSetParameterValues:
+		c.Data(http.StatusOK, contentTypeXML, SetParameterValuesXML(task.ID, task.Values, task.ID))
+	default:
+		c.Data(http.StatusOK, contentTypeXML, GetParameterValuesXML(task.ID, task.Names))
+	}
+}
+
+// complete records the outcome of the in-flight RPC
+func (a *ACS) complete(sess *session, errMsg string) {
+	a.mu.Lock()
+	inflight := sess.inflight
+	sess.inflight = nil
+	a.mu.Unlock()
+
+	if inflight != nil {
+		a.queue.Complete(sess.serial, inflight.ID, errMsg)
+	}
+}
+
+func (a *ACS) startSession(serial string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Expire idle sessions; their in-flight RPCs are considered failed
+	now := a.now()
+	for token, s := range a.sessions {
+		if now.After(s.expires) {
+			if s.inflight != nil {
+				a.queue.Complete(s.serial, s.inflight.ID, "session expired")
+			}
+			delete(a.sessions, token)
+		}
+	}
+
+	token := uuid.NewString()
+	a.sessions[token] = &session{serial: serial, expires: now.Add(sessionTTL)}
+	return token
+}
+
+func (a *ACS) session(c *gin.Context) (string, *session) {
+	token, err := c.Cookie(SessionCookie)
+	if err != nil {
+		return "", nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sess, ok := a.sessions[token]
+	if !ok || a.now().After(sess.expires) {
+		return "", nil
+	}
+	return token, sess
+}
+
+func (a *ACS) endSession(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.sessions, token)
+}
@@ -0,0 +1,311 @@
+package tr069_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/features/tr069"
+)
+
+const sampleInform = `<?xml version="1.0" encoding="UTF-8"?>
+<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/" xmlns:SOAP-ENC="http://schemas.xmlsoap.org/soap/encoding/" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:cwmp="urn:dslforum-org:cwmp-1-0">
+<SOAP-ENV:Header><cwmp:ID SOAP-ENV:mustUnderstand="1">inform-1</cwmp:ID></SOAP-ENV:Header>
+<SOAP-ENV:Body>
+<cwmp:Inform>
+<DeviceId><Manufacturer>ZTE</Manufacturer><OUI>00D0D0</OUI><ProductClass>F660</ProductClass><SerialNumber>ZTEGC8A1B2C3</SerialNumber></DeviceId>
+<Event SOAP-ENC:arrayType="cwmp:EventStruct[2]">
+<EventStruct><EventCode>0 BOOTSTRAP</EventCode><CommandKey></CommandKey></EventStruct>
+<EventStruct><EventCode>1 BOOT</EventCode><CommandKey></CommandKey></EventStruct>
+</Event>
+<MaxEnvelopes>1</MaxEnvelopes>
+<CurrentTime>2024-01-15T10:30:00+07:00</CurrentTime>
+<RetryCount>0</RetryCount>
+<ParameterList SOAP-ENC:arrayType="cwmp:ParameterValueStruct[2]">
+<ParameterValueStruct><Name>InternetGatewayDevice.DeviceInfo.SoftwareVersion</Name><Value xsi:type="xsd:string">V6.0.10P2T2</Value></ParameterValueStruct>
+<ParameterValueStruct><Name>InternetGatewayDevice.ManagementServer.ConnectionRequestURL</Name><Value xsi:type="xsd:string">http://10.10.0.5:7547/</Value></ParameterValueStruct>
+</ParameterList>
+</cwmp:Inform>
+</SOAP-ENV:Body>
+</SOAP-ENV:Envelope>`
+
+// Same Inform from a CWMP 1.2 device using a different prefix convention.
+const sampleInformCWMP12 = `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:cwmp="urn:dslforum-org:cwmp-1-2" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<soapenv:Header><cwmp:ID soapenv:mustUnderstand="1">42</cwmp:ID></soapenv:Header>
+<soapenv:Body><cwmp:Inform>
+<DeviceId><Manufacturer>Huawei</Manufacturer><OUI>00E0FC</OUI><ProductClass>HG8245H</ProductClass><SerialNumber>48575443ABCDEF01</SerialNumber></DeviceId>
+<Event><EventStruct><EventCode>2 PERIODIC</EventCode><CommandKey/></EventStruct></Event>
+<ParameterList><ParameterValueStruct><Name>Device.DeviceInfo.UpTime</Name><Value xsi:type="xsd:unsignedInt">3600</Value></ParameterValueStruct></ParameterList>
+</cwmp:Inform></soapenv:Body></soapenv:Envelope>`
+
+func gpvResponse(id string) string {
+	return `<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/" xmlns:cwmp="urn:dslforum-org:cwmp-1-0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<soap-env:Header><cwmp:ID soap-env:mustUnderstand="1">` + id + `</cwmp:ID></soap-env:Header>
+<soap-env:Body><cwmp:GetParameterValuesResponse><ParameterList>
+<ParameterValueStruct><Name>InternetGatewayDevice.WANDevice.1.WANConnectionDevice.1.WANPPPConnection.1.Username</Name><Value xsi:type="xsd:string">cust001</Value></ParameterValueStruct>
+</ParameterList></cwmp:GetParameterValuesResponse></soap-env:Body></soap-env:Envelope>`
+}
+
+func spvResponse(id string) string {
+	return `<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/" xmlns:cwmp="urn:dslforum-org:cwmp-1-0">
+<soap-env:Header><cwmp:ID soap-env:mustUnderstand="1">` + id + `</cwmp:ID></soap-env:Header>
+<soap-env:Body><cwmp:SetParameterValuesResponse><Status>0</Status></cwmp:SetParameterValuesResponse></soap-env:Body></soap-env:Envelope>`
+}
+
+func faultResponse(id string) string {
+	return `<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/" xmlns:cwmp="urn:dslforum-org:cwmp-1-0">
+<soap-env:Header><cwmp:ID soap-env:mustUnderstand="1">` + id + `</cwmp:ID></soap-env:Header>
+<soap-env:Body><soap-env:Fault><faultcode>Client</faultcode><faultstring>CWMP fault</faultstring>
+<detail><cwmp:Fault><FaultCode>9005</FaultCode><FaultString>Invalid parameter name</FaultString></cwmp:Fault></detail>
+</soap-env:Fault></soap-env:Body></soap-env:Envelope>`
+}
+
+// memStore is an in-memory tr069.Store.
+type memStore struct {
+	mu     sync.Mutex
+	cpes   map[string]*tr069.CPE
+	params map[string]map[string]tr069.ParameterValue
+}
+
+func newMemStore() *memStore {
+	return &memStore{cpes: map[string]*tr069.CPE{}, params: map[string]map[string]tr069.ParameterValue{}}
+}
+
+func (s *memStore) UpsertCPE(ctx context.Context, cpe *tr069.CPE) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copy := *cpe
+	s.cpes[cpe.SerialNumber] = &copy
+	return nil
+}
+
+func (s *memStore) GetCPE(ctx context.Context, serial string) (*tr069.CPE, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cpe, ok := s.cpes[serial]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", tr069.ErrCPENotFound, serial)
+	}
+	return cpe, nil
+}
+
+func (s *memStore) SaveParameters(ctx context.Context, serial string, params []tr069.ParameterValue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.params[serial] == nil {
+		s.params[serial] = map[string]tr069.ParameterValue{}
+	}
+	for _, p := range params {
+		s.params[serial][p.Name] = p
+	}
+	return nil
+}
+
+func (s *memStore) ListParameters(ctx context.Context, serial string) ([]tr069.CPEParameter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []tr069.CPEParameter
+	for _, p := range s.params[serial] {
+		result = append(result, tr069.CPEParameter{SerialNumber: serial, Name: p.Name, Value: p.Value, Type: p.Type})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (s *memStore) param(serial, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.params[serial][name].Value
+}
+
+type fakeDevices struct{}
+
+func (fakeDevices) GetByIPAddress(ctx context.Context, ip string) (*model.Device, error) {
+	if ip == "10.10.0.5" {
+		return &model.Device{ID: "dev-ont-1", IPAddress: ip, Protocol: model.ProtocolTR069}, nil
+	}
+	return nil, fmt.Errorf("device not found with IP: %s", ip)
+}
+
+// cpeClient posts to the ACS and carries the session cookie like a CPE would.
+type cpeClient struct {
+	t      *testing.T
+	router *gin.Engine
+	cookie *http.Cookie
+}
+
+func (c *cpeClient) post(body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/cwmp", strings.NewReader(body))
+	req.RemoteAddr = "10.10.0.5:7547"
+	if c.cookie != nil {
+		req.AddCookie(c.cookie)
+	}
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == tr069.SessionCookie {
+			c.cookie = ck
+		}
+	}
+	return w
+}
+
+func setupACS(t *testing.T) (*cpeClient, *memStore, *tr069.TaskQueue) {
+	gin.SetMode(gin.TestMode)
+	store := newMemStore()
+	queue := tr069.NewTaskQueue()
+
+	r := gin.New()
+	r.POST("/cwmp", tr069.NewACS(store, queue, fakeDevices{}).ServeCWMP)
+	tr069.RegisterRoutes(r.Group("/api/v1"), store, queue)
+
+	return &cpeClient{t: t, router: r}, store, queue
+}
+
+// taskID extracts the cwmp:ID header the ACS used for an RPC.
+func taskID(t *testing.T, body string) string {
+	env, err := tr069.ParseEnvelope([]byte(body))
+	require.NoError(t, err)
+	return env.Header.ID
+}
+
+func TestParseEnvelope_Inform(t *testing.T) {
+	for name, payload := range map[string]string{"cwmp-1-0": sampleInform, "cwmp-1-2": sampleInformCWMP12} {
+		t.Run(name, func(t *testing.T) {
+			env, err := tr069.ParseEnvelope([]byte(payload))
+			require.NoError(t, err)
+			require.NotNil(t, env.Body.Inform)
+			assert.Equal(t, "Inform", env.Method())
+			assert.NotEmpty(t, env.Header.ID)
+			assert.NotEmpty(t, env.Body.Inform.DeviceID.SerialNumber)
+			assert.NotEmpty(t, env.Body.Inform.Events)
+			assert.NotEmpty(t, env.Body.Inform.ParameterList)
+		})
+	}
+
+	env, err := tr069.ParseEnvelope([]byte(sampleInform))
+	require.NoError(t, err)
+	inform := env.Body.Inform
+	assert.Equal(t, "ZTEGC8A1B2C3", inform.DeviceID.SerialNumber)
+	assert.Equal(t, "0 BOOTSTRAP", inform.Events[0].EventCode)
+	assert.Equal(t, "xsd:string", inform.ParameterList[0].Type)
+	assert.Equal(t, "V6.0.10P2T2", inform.ParameterList[0].Value)
+}
+
+func TestACS_InformStoresCPE(t *testing.T) {
+	cpe, store, _ := setupACS(t)
+
+	w := cpe.post(sampleInform)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<cwmp:InformResponse>")
+	assert.Contains(t, w.Body.String(), ">inform-1</cwmp:ID>")
+	require.NotNil(t, cpe.cookie)
+
+	stored, err := store.GetCPE(context.Background(), "ZTEGC8A1B2C3")
+	require.NoError(t, err)
+	assert.Equal(t, "ZTE", stored.Manufacturer)
+	assert.Equal(t, "F660", stored.ProductClass)
+	assert.Equal(t, "0 BOOTSTRAP, 1 BOOT", stored.LastEvents)
+	require.NotNil(t, stored.DeviceID)
+	assert.Equal(t, "dev-ont-1", *stored.DeviceID)
+	assert.Equal(t, "V6.0.10P2T2", store.param("ZTEGC8A1B2C3", "InternetGatewayDevice.DeviceInfo.SoftwareVersion"))
+
+	// Nothing queued: the empty post ends the session
+	w = cpe.post("")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestACS_DeliversQueuedTasks(t *testing.T) {
+	cpe, store, queue := setupACS(t)
+	const serial = "ZTEGC8A1B2C3"
+	const pppUser = "InternetGatewayDevice.WANDevice.1.WANConnectionDevice.1.WANPPPConnection.1.Username"
+
+	require.Equal(t, http.StatusOK, cpe.post(sampleInform).Code)
+	gpv := queue.Enqueue(serial, tr069.TaskGetParameterValues, []string{pppUser}, nil)
+	spv := queue.Enqueue(serial, tr069.TaskSetParameterValues, nil, []tr069.ParameterValue{
+		{Name: "InternetGatewayDevice.ManagementServer.PeriodicInformInterval", Value: "300", Type: "xsd:unsignedInt"},
+	})
+
+	// Empty post -> GetParameterValues
+	w := cpe.post("")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<cwmp:GetParameterValues>")
+	assert.Contains(t, w.Body.String(), "<string>"+pppUser+"</string>")
+	assert.Equal(t, gpv.ID, taskID(t, w.Body.String()))
+
+	// GetParameterValuesResponse -> SetParameterValues
+	w = cpe.post(gpvResponse(gpv.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<cwmp:SetParameterValues>")
+	assert.Contains(t, w.Body.String(), `<Value xsi:type="xsd:unsignedInt">300</Value>`)
+	assert.Equal(t, "cust001", store.param(serial, pppUser))
+
+	// SetParameterValuesResponse -> session ends
+	w = cpe.post(spvResponse(spv.ID))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "300", store.param(serial, "InternetGatewayDevice.ManagementServer.PeriodicInformInterval"))
+
+	for _, task := range queue.List(serial) {
+		assert.Equal(t, tr069.TaskDone, task.Status, task.Type)
+	}
+}
+
+func TestACS_FaultMarksTaskFailed(t *testing.T) {
+	cpe, _, queue := setupACS(t)
+	const serial = "ZTEGC8A1B2C3"
+
+	require.Equal(t, http.StatusOK, cpe.post(sampleInform).Code)
+	task := queue.Enqueue(serial, tr069.TaskGetParameterValues, []string{"InternetGatewayDevice.Bogus."}, nil)
+
+	require.Equal(t, http.StatusOK, cpe.post("").Code)
+	w := cpe.post(faultResponse(task.ID))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	tasks := queue.List(serial)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, tr069.TaskFailed, tasks[0].Status)
+	assert.Contains(t, tasks[0].Error, "9005")
+}
+
+func TestACS_ResponseWithoutSession(t *testing.T) {
+	cpe, _, _ := setupACS(t)
+
+	w := cpe.post(gpvResponse("1"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<FaultCode>8001</FaultCode>")
+}
+
+func TestHandler_QueueTask(t *testing.T) {
+	cpe, _, queue := setupACS(t)
+	require.Equal(t, http.StatusOK, cpe.post(sampleInform).Code)
+
+	body := `{"type":"GetParameterValues","names":["InternetGatewayDevice.DeviceInfo."]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tr069/cpes/ZTEGC8A1B2C3/tasks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	cpe.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	require.Len(t, queue.List("ZTEGC8A1B2C3"), 1)
+
+	// Unknown CPE
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/tr069/cpes/UNKNOWN/tasks", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	cpe.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Missing names
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/tr069/cpes/ZTEGC8A1B2C3/tasks", strings.NewReader(`{"type":"GetParameterValues"}`))
+	w = httptest.NewRecorder()
+	cpe.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
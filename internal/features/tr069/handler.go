@@ -0,0 +1,107 @@
+package tr069
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourorg/nms-go/internal/common/validator"
+)
+
+// QueueTaskRequest queues an RPC for a CPE's next session.
+type QueueTaskRequest struct {
+	Type   TaskType         `json:"type" binding:"required,oneof=GetParameterValues SetParameterValues"`
+	Names  []string         `json:"names"`
+	Values []ParameterValue `json:"values"`
+}
+
+// Handler exposes TR-069 CPE data and task queueing to the REST API.
+type Handler struct {
+	store Store
+	queue *TaskQueue
+}
+
+// NewHandler creates a new TR-069 HTTP handler.
+func NewHandler(store Store, queue *TaskQueue) *Handler {
+	return &Handler{store: store, queue: queue}
+}
+
+// RegisterRoutes mounts the TR-069 management endpoints on the given group.
+func RegisterRoutes(rg *gin.RouterGroup, store Store, queue *TaskQueue) {
+	h := NewHandler(store, queue)
+	cpes := rg.Group("/tr069/cpes")
+	cpes.GET("/:serial", h.GetCPE)
+	cpes.GET("/:serial/parameters", h.ListParameters)
+	cpes.GET("/:serial/tasks", h.ListTasks)
+	cpes.POST("/:serial/tasks", h.QueueTask)
+}
+
+// GetCPE handles GET /api/v1/tr069/cpes/:serial
+func (h *Handler) GetCPE(c *gin.Context) {
+	cpe, err := h.store.GetCPE(c.Request.Context(), c.Param("serial"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cpe)
+}
+
+// ListParameters handles GET /api/v1/tr069/cpes/:serial/parameters
+func (h *Handler) ListParameters(c *gin.Context) {
+	serial := c.Param("serial")
+	if _, err := h.store.GetCPE(c.Request.Context(), serial); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	params, err := h.store.ListParameters(c.Request.Context(), serial)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": params})
+}
+
+// ListTasks handles GET /api/v1/tr069/cpes/:serial/tasks
+func (h *Handler) ListTasks(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.queue.List(c.Param("serial"))})
+}
+
+// QueueTask handles POST /api/v1/tr069/cpes/:serial/tasks
+//
+// The task is delivered when the CPE next opens a session (periodic inform
+// or connection request); the response is 202 Accepted.
+func (h *Handler) QueueTask(c *gin.Context) {
+	var req QueueTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, validator.ErrorResponse("invalid request body", err))
+		return
+	}
+	if req.Type == TaskGetParameterValues && len(req.Names) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "names is required for GetParameterValues"})
+		return
+	}
+	if req.Type == TaskSetParameterValues && len(req.Values) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "values is required for SetParameterValues"})
+		return
+	}
+
+	serial := c.Param("serial")
+	if _, err := h.store.GetCPE(c.Request.Context(), serial); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	task := h.queue.Enqueue(serial, req.Type, req.Names, req.Values)
+	c.JSON(http.StatusAccepted, task)
+}
+
+func respondStoreError(c *gin.Context, err error) {
+	if errors.Is(err, ErrCPENotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,68 @@
+package tr069
+
+import "time"
+
+// CPE is a TR-069 managed device (typically an ONT) identified by its serial number.
+// DeviceID links it to the device registry when a registered device matches its IP.
+type CPE struct {
+	SerialNumber string    `json:"serial_number" gorm:"primaryKey;size:64"`
+	DeviceID     *string   `json:"device_id,omitempty" gorm:"type:uuid;index"`
+	Manufacturer string    `json:"manufacturer" gorm:"size:255"`
+	OUI          string    `json:"oui" gorm:"size:16"`
+	ProductClass string    `json:"product_class" gorm:"size:255"`
+	IPAddress    string    `json:"ip_address" gorm:"size:64"`
+	LastEvents   string    `json:"last_events" gorm:"size:255"`
+	LastInform   time.Time `json:"last_inform"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for CPE
+func (CPE) TableName() string {
+	return "tr069_cpes"
+}
+
+// CPEParameter is the last known value of a CPE data model parameter.
+type CPEParameter struct {
+	SerialNumber string    `json:"serial_number" gorm:"primaryKey;size:64"`
+	Name         string    `json:"name" gorm:"primaryKey;size:512"`
+	Value        string    `json:"value" gorm:"type:text"`
+	Type         string    `json:"type,omitempty" gorm:"size:32"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for CPEParameter
+func (CPEParameter) TableName() string {
+	return "tr069_parameters"
+}
+
+// TaskType is an ACS-initiated RPC.
+type TaskType string
+
+const (
+	TaskGetParameterValues TaskType = "GetParameterValues"
+	TaskSetParameterValues TaskType = "SetParameterValues"
+)
+
+// TaskStatus tracks a queued RPC through the CWMP session.
+type TaskStatus string
+
+const (
+	TaskPending TaskStatus = "pending"
+	TaskSent    TaskStatus = "sent"
+	TaskDone    TaskStatus = "done"
+	TaskFailed  TaskStatus = "failed"
+)
+
+// Task is an RPC queued for delivery on the CPE's next session.
+type Task struct {
+	ID           string           `json:"id"`
+	SerialNumber string           `json:"serial_number"`
+	Type         TaskType         `json:"type"`
+	Names        []string         `json:"names,omitempty"`
+	Values       []ParameterValue `json:"values,omitempty"`
+	Status       TaskStatus       `json:"status"`
+	Error        string           `json:"error,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
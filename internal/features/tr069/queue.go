@@ -0,0 +1,111 @@
+package tr069
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxTasksPerCPE bounds the task history kept per CPE.
+const maxTasksPerCPE = 50
+
+// TaskQueue holds RPCs waiting for a CPE's next CWMP session. Tasks are kept
+// in memory; pending tasks are lost on restart.
+type TaskQueue struct {
+	mu    sync.Mutex
+	tasks map[string][]*Task // serial -> tasks in creation order
+	now   func() time.Time
+}
+
+func NewTaskQueue() *TaskQueue {
+	return &TaskQueue{
+		tasks: make(map[string][]*Task),
+		now:   time.Now,
+	}
+}
+
+// Enqueue adds a task for the CPE and returns a copy with its ID set
+func (q *TaskQueue) Enqueue(serial string, typ TaskType, names []string, values []ParameterValue) Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	task := &Task{
+		ID:           uuid.NewString(),
+		SerialNumber: serial,
+		Type:         typ,
+		Names:        names,
+		Values:       values,
+		Status:       TaskPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	list := append(q.tasks[serial], task)
+	if len(list) > maxTasksPerCPE {
+		list = pruneFinished(list, len(list)-maxTasksPerCPE)
+	}
+	q.tasks[serial] = list
+
+	return *task
+}
+
+// Next marks the oldest pending task of the CPE as sent and returns it
+func (q *TaskQueue) Next(serial string) (Task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, t := range q.tasks[serial] {
+		if t.Status == TaskPending {
+			t.Status = TaskSent
+			t.UpdatedAt = q.now()
+			return *t, true
+		}
+	}
+	return Task{}, false
+}
+
+// Complete records the outcome of a sent task; an empty errMsg marks it done
+func (q *TaskQueue) Complete(serial, id, errMsg string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, t := range q.tasks[serial] {
+		if t.ID != id {
+			continue
+		}
+		t.Status = TaskDone
+		t.Error = errMsg
+		if errMsg != "" {
+			t.Status = TaskFailed
+		}
+		t.UpdatedAt = q.now()
+		return
+	}
+}
+
+// List returns copies of all tasks for the CPE
+func (q *TaskQueue) List(serial string) []Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := make([]Task, 0, len(q.tasks[serial]))
+	for _, t := range q.tasks[serial] {
+		result = append(result, *t)
+	}
+	return result
+}
+
+// pruneFinished drops up to n of the oldest done or failed tasks
+func pruneFinished(list []*Task, n int) []*Task {
+	kept := list[:0]
+	for _, t := range list {
+		if n > 0 && (t.Status == TaskDone || t.Status == TaskFailed) {
+			n--
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept
+}
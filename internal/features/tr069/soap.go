@@ -0,0 +1,207 @@
+package tr069
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// CWMP fault codes used by the ACS (TR-069 Annex A.5.1).
+const (
+	FaultMethodNotSupported = 8000
+	FaultRequestDenied      = 8001
+	FaultInternalError      = 8002
+)
+
+// Envelope is an incoming CWMP SOAP message. Elements are matched by local
+// name, so every cwmp-1-x namespace version is accepted.
+type Envelope struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Header  struct {
+		ID string `xml:"ID"`
+	} `xml:"Header"`
+	Body struct {
+		Inform                     *Inform                     `xml:"Inform"`
+		GetParameterValuesResponse *GetParameterValuesResponse `xml:"GetParameterValuesResponse"`
+		SetParameterValuesResponse *SetParameterValuesResponse `xml:"SetParameterValuesResponse"`
+		Fault                      *Fault                      `xml:"Fault"`
+		Any                        []anyElement                `xml:",any"`
+	} `xml:"Body"`
+}
+
+type anyElement struct {
+	XMLName xml.Name
+}
+
+// Method returns the local name of the RPC carried in the body.
+func (e *Envelope) Method() string {
+	switch {
+	case e.Body.Inform != nil:
+		return "Inform"
+	case e.Body.GetParameterValuesResponse != nil:
+		return "GetParameterValuesResponse"
+	case e.Body.SetParameterValuesResponse != nil:
+		return "SetParameterValuesResponse"
+	case e.Body.Fault != nil:
+		return "Fault"
+	case len(e.Body.Any) > 0:
+		return e.Body.Any[0].XMLName.Local
+	}
+	return ""
+}
+
+// DeviceID identifies the CPE in an Inform.
+type DeviceID struct {
+	Manufacturer string `xml:"Manufacturer"`
+	OUI          string `xml:"OUI"`
+	ProductClass string `xml:"ProductClass"`
+	SerialNumber string `xml:"SerialNumber"`
+}
+
+// EventStruct is a single event reported in an Inform, e.g. "0 BOOTSTRAP".
+type EventStruct struct {
+	EventCode  string `xml:"EventCode"`
+	CommandKey string `xml:"CommandKey"`
+}
+
+// Inform is sent by the CPE at the start of every session.
+type Inform struct {
+	DeviceID      DeviceID         `xml:"DeviceId"`
+	Events        []EventStruct    `xml:"Event>EventStruct"`
+	CurrentTime   string           `xml:"CurrentTime"`
+	RetryCount    int              `xml:"RetryCount"`
+	ParameterList []ParameterValue `xml:"ParameterList>ParameterValueStruct"`
+}
+
+// ParameterValue is a name/value pair from a ParameterValueStruct.
+type ParameterValue struct {
+	Name  string `xml:"Name" json:"name"`
+	Value string `xml:"Value" json:"value"`
+	Type  string `xml:"-" json:"type,omitempty"`
+}
+
+// UnmarshalXML captures the xsi:type attribute of Value alongside its text.
+func (p *ParameterValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var raw struct {
+		Name  string `xml:"Name"`
+		Value struct {
+			Type string `xml:"type,attr"`
+			Text string `xml:",chardata"`
+		} `xml:"Value"`
+	}
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+	p.Name = raw.Name
+	p.Value = raw.Value.Text
+	p.Type = raw.Value.Type
+	return nil
+}
+
+// GetParameterValuesResponse carries the values requested by the ACS.
+type GetParameterValuesResponse struct {
+	ParameterList []ParameterValue `xml:"ParameterList>ParameterValueStruct"`
+}
+
+// SetParameterValuesResponse reports whether the values were applied (0)
+// or will be applied after a reboot (1).
+type SetParameterValuesResponse struct {
+	Status int `xml:"Status"`
+}
+
+// Fault is a SOAP fault returned by the CPE for a failed RPC.
+type Fault struct {
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+	Detail      struct {
+		Fault struct {
+			FaultCode   int    `xml:"FaultCode"`
+			FaultString string `xml:"FaultString"`
+		} `xml:"Fault"`
+	} `xml:"detail"`
+}
+
+// Error describes the CWMP fault.
+func (f *Fault) Error() string {
+	if f.Detail.Fault.FaultCode != 0 {
+		return fmt.Sprintf("cwmp fault %d: %s", f.Detail.Fault.FaultCode, f.Detail.Fault.FaultString)
+	}
+	return fmt.Sprintf("soap fault %s: %s", f.FaultCode, f.FaultString)
+}
+
+// ParseEnvelope decodes a CWMP SOAP message.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	var env Envelope
+	if err := xml.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid cwmp envelope: %w", err)
+	}
+	return &env, nil
+}
+
+const envelopeHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<soap-env:Envelope xmlns:soap-env="http://schemas.xmlsoap.org/soap/envelope/" xmlns:soap-enc="http://schemas.xmlsoap.org/soap/encoding/" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:cwmp="urn:dslforum-org:cwmp-1-0">
+<soap-env:Header><cwmp:ID soap-env:mustUnderstand="1">%s</cwmp:ID></soap-env:Header>
+<soap-env:Body>
+`
+
+const envelopeFooter = `</soap-env:Body>
+</soap-env:Envelope>
+`
+
+func writeEnvelope(id string, body func(b *bytes.Buffer)) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, envelopeHeader, escape(id))
+	body(&b)
+	b.WriteString(envelopeFooter)
+	return b.Bytes()
+}
+
+// InformResponseXML acknowledges an Inform.
+func InformResponseXML(id string) []byte {
+	return writeEnvelope(id, func(b *bytes.Buffer) {
+		b.WriteString("<cwmp:InformResponse><MaxEnvelopes>1</MaxEnvelopes></cwmp:InformResponse>\n")
+	})
+}
+
+// GetParameterValuesXML requests the values of the given parameter names
+// (a name ending in "." selects a whole subtree).
+func GetParameterValuesXML(id string, names []string) []byte {
+	return writeEnvelope(id, func(b *bytes.Buffer) {
+		fmt.Fprintf(b, "<cwmp:GetParameterValues><ParameterNames soap-enc:arrayType=\"xsd:string[%d]\">\n", len(names))
+		for _, n := range names {
+			fmt.Fprintf(b, "<string>%s</string>\n", escape(n))
+		}
+		b.WriteString("</ParameterNames></cwmp:GetParameterValues>\n")
+	})
+}
+
+// SetParameterValuesXML sets the given parameters. Values without a type are sent as xsd:string.
+func SetParameterValuesXML(id string, values []ParameterValue, parameterKey string) []byte {
+	return writeEnvelope(id, func(b *bytes.Buffer) {
+		fmt.Fprintf(b, "<cwmp:SetParameterValues><ParameterList soap-enc:arrayType=\"cwmp:ParameterValueStruct[%d]\">\n", len(values))
+		for _, v := range values {
+			typ := v.Type
+			if typ == "" {
+				typ = "xsd:string"
+			}
+			fmt.Fprintf(b, "<ParameterValueStruct><Name>%s</Name><Value xsi:type=\"%s\">%s</Value></ParameterValueStruct>\n",
+				escape(v.Name), escape(typ), escape(v.Value))
+		}
+		fmt.Fprintf(b, "</ParameterList><ParameterKey>%s</ParameterKey></cwmp:SetParameterValues>\n", escape(parameterKey))
+	})
+}
+
+// FaultXML reports a CWMP fault to the CPE.
+func FaultXML(id string, code int, message string) []byte {
+	return writeEnvelope(id, func(b *bytes.Buffer) {
+		fmt.Fprintf(b, "<soap-env:Fault><faultcode>Server</faultcode><faultstring>CWMP fault</faultstring><detail><cwmp:Fault><FaultCode>%d</FaultCode><FaultString>%s</FaultString></cwmp:Fault></detail></soap-env:Fault>\n",
+			code, escape(message))
+	})
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package tr069
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCPENotFound is returned when no CPE with the given serial number has informed.
+var ErrCPENotFound = errors.New("cpe not found")
+
+// Store persists CPE identity and parameter values.
+type Store interface {
+	UpsertCPE(ctx context.Context, cpe *CPE) error
+	GetCPE(ctx context.Context, serial string) (*CPE, error)
+	SaveParameters(ctx context.Context, serial string, params []ParameterValue) error
+	ListParameters(ctx context.Context, serial string) ([]CPEParameter, error)
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewStore creates a gorm-backed Store
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+// UpsertCPE creates the CPE or refreshes its identity and last inform
+func (s *gormStore) UpsertCPE(ctx context.Context, cpe *CPE) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "serial_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"device_id", "manufacturer", "oui", "product_class", "ip_address", "last_events", "last_inform", "updated_at",
+		}),
+	}).Create(cpe).Error
+}
+
+// GetCPE retrieves a CPE by serial number
+func (s *gormStore) GetCPE(ctx context.Context, serial string) (*CPE, error) {
+	var cpe CPE
+	err := s.db.WithContext(ctx).First(&cpe, "serial_number = ?", serial).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrCPENotFound, serial)
+		}
+		return nil, err
+	}
+	return &cpe, nil
+}
+
+// SaveParameters upserts parameter values reported by the CPE
+func (s *gormStore) SaveParameters(ctx context.Context, serial string, params []ParameterValue) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]CPEParameter, len(params))
+	for i, p := range params {
+		rows[i] = CPEParameter{
+			SerialNumber: serial,
+			Name:         p.Name,
+			Value:        p.Value,
+			Type:         p.Type,
+			UpdatedAt:    now,
+		}
+	}
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "serial_number"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "type", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+// ListParameters returns all known parameters of a CPE ordered by name
+func (s *gormStore) ListParameters(ctx context.Context, serial string) ([]CPEParameter, error) {
+	var params []CPEParameter
+	err := s.db.WithContext(ctx).
+		Where("serial_number = ?", serial).
+		Order("name ASC").
+		Find(&params).Error
+	return params, err
+}
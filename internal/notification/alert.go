@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"context"
+	"time"
+)
+
+// Alert kinds
+const (
+	AlertFiring   = "firing"
+	AlertRepeat   = "repeat"
+	AlertResolved = "resolved"
+)
+
+// Alert is an alert notification with the fields channels route and format
+// on, in addition to the rendered Subject and Body.
+type Alert struct {
+	// Kind is AlertFiring, AlertRepeat for a re-notification of an alert
+	// that keeps firing, or AlertResolved.
+	Kind        string
+	Subject     string
+	Body        string
+	RuleID      string
+	Description string
+	Severity    string
+	DeviceID    string
+	DeviceName  string
+	IPAddress   string
+	MetricName  string
+	Value       float64
+	Threshold   float64
+	TriggeredAt time.Time
+}
+
+// AlertNotifier is implemented by channels that use the fields of an alert
+// rather than only its subject and body. The alert engine prefers it over
+// Service.Send.
+type AlertNotifier interface {
+	NotifyAlert(ctx context.Context, alert Alert) error
+}
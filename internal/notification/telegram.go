@@ -0,0 +1,350 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/common/config"
+)
+
+// TelegramChannel is the alert channel name of the Telegram notifier.
+const TelegramChannel = "telegram"
+
+const (
+	defaultTelegramAPIURL    = "https://api.telegram.org"
+	defaultTelegramTimeout   = 10 * time.Second
+	defaultTelegramRateLimit = 20 // Telegram's limit for messages to a group per minute
+
+	// telegramMaxText is the longest message Telegram accepts, in characters
+	telegramMaxText = 4096
+
+	// maxHeldMessages is the most rate-limited messages of one chat kept for
+	// its digest; later ones are only counted.
+	maxHeldMessages = 50
+)
+
+// DefaultTelegramTemplate renders an Alert as a plain-text Telegram message.
+const DefaultTelegramTemplate = `{{if eq .Kind "resolved"}}✅ RESOLVED{{else}}🚨 {{upper .Severity}}{{end}}: {{.Description}}
+Device: {{.DeviceName}} ({{.IPAddress}})
+{{.MetricName}} = {{printf "%.2f" .Value}} (threshold {{.Threshold}})
+{{- if eq .Kind "repeat"}}
+Firing since {{.TriggeredAt.UTC.Format "2006-01-02 15:04:05"}} UTC{{end}}`
+
+// ErrNoTelegramChat is returned when neither a group chat nor the default
+// chat matches the alert's device.
+var ErrNoTelegramChat = errors.New("no telegram chat for device")
+
+// GroupRef identifies a device group by ID and name.
+type GroupRef struct {
+	ID   string
+	Name string
+}
+
+// DeviceGroupsFunc returns the group of a device followed by its ancestors,
+// empty if it has no group.
+type DeviceGroupsFunc func(ctx context.Context, deviceID string) ([]GroupRef, error)
+
+// TelegramNotifier sends alerts through a Telegram bot. Each alert goes to
+// the chat of the device's group, or of its nearest ancestor group that has
+// one, falling back to the default chat. Messages over a chat's rate limit
+// are held and sent as one digest once the chat's window reopens. It
+// implements Service and AlertNotifier.
+type TelegramNotifier struct {
+	apiURL      string
+	token       string
+	defaultChat string
+	groupChats  map[string]string
+	groups      DeviceGroupsFunc
+	tmpl        *template.Template
+	client      *http.Client
+	limiter     *chatLimiter
+
+	mu   sync.Mutex
+	held map[string]*heldMessages
+}
+
+// heldMessages are the rate-limited messages of one chat awaiting its digest.
+type heldMessages struct {
+	texts []string
+	extra int // held beyond maxHeldMessages
+}
+
+// NewTelegramNotifier creates a TelegramNotifier from config. groups may be
+// nil when no group chats are configured.
+func NewTelegramNotifier(cfg config.TelegramConfig, groups DeviceGroupsFunc) (*TelegramNotifier, error) {
+	return NewTelegramNotifierForTest(cfg, groups, time.Now)
+}
+
+// NewTelegramNotifierForTest creates a TelegramNotifier with a custom clock
+// for the rate limiter.
+func NewTelegramNotifierForTest(cfg config.TelegramConfig, groups DeviceGroupsFunc, now func() time.Time) (*TelegramNotifier, error) {
+	if cfg.BotToken == "" {
+		return nil, errors.New("telegram: bot_token is required")
+	}
+	if cfg.DefaultChatID == "" && len(cfg.GroupChats) == 0 {
+		return nil, errors.New("telegram: default_chat_id or group_chats is required")
+	}
+	if len(cfg.GroupChats) > 0 && groups == nil {
+		return nil, errors.New("telegram: group_chats needs a device group lookup")
+	}
+
+	text := cfg.Template
+	if text == "" {
+		text = DefaultTelegramTemplate
+	}
+	tmpl, err := template.New("telegram").Funcs(template.FuncMap{"upper": strings.ToUpper}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("telegram: invalid template: %w", err)
+	}
+
+	apiURL := strings.TrimRight(cfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = defaultTelegramAPIURL
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTelegramTimeout
+	}
+	rateLimit := cfg.RateLimit
+	if rateLimit <= 0 {
+		rateLimit = defaultTelegramRateLimit
+	}
+
+	// Viper lowercases map keys, so group names are matched lowercased.
+	groupChats := make(map[string]string, len(cfg.GroupChats))
+	for group, chat := range cfg.GroupChats {
+		groupChats[strings.ToLower(group)] = chat
+	}
+
+	return &TelegramNotifier{
+		apiURL:      apiURL,
+		token:       cfg.BotToken,
+		defaultChat: cfg.DefaultChatID,
+		groupChats:  groupChats,
+		groups:      groups,
+		tmpl:        tmpl,
+		client:      &http.Client{Timeout: timeout},
+		limiter:     newChatLimiter(rateLimit, time.Minute, now),
+		held:        make(map[string]*heldMessages),
+	}, nil
+}
+
+// Send sends subject and body to the default chat. to is ignored.
+func (n *TelegramNotifier) Send(to, subject, body string) error {
+	if n.defaultChat == "" {
+		return ErrNoTelegramChat
+	}
+	return n.sendMessage(context.Background(), n.defaultChat, subject+"\n"+body)
+}
+
+// NotifyAlert renders the alert with the template and sends it to the chat
+// of the device's group.
+func (n *TelegramNotifier) NotifyAlert(ctx context.Context, alert Alert) error {
+	chat, err := n.chatFor(ctx, alert.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	var text bytes.Buffer
+	if err := n.tmpl.Execute(&text, alert); err != nil {
+		return fmt.Errorf("telegram: failed to render message: %w", err)
+	}
+	return n.sendMessage(ctx, chat, text.String())
+}
+
+// chatFor returns the chat of the innermost group of the device that has one,
+// or the default chat
+func (n *TelegramNotifier) chatFor(ctx context.Context, deviceID string) (string, error) {
+	if len(n.groupChats) > 0 && deviceID != "" {
+		path, err := n.groups(ctx, deviceID)
+		if err != nil {
+			// Fall back to the default chat rather than losing the alert
+			if n.defaultChat != "" {
+				return n.defaultChat, nil
+			}
+			return "", fmt.Errorf("telegram: failed to look up device groups: %w", err)
+		}
+		for _, g := range path {
+			if chat, ok := n.groupChats[strings.ToLower(g.ID)]; ok {
+				return chat, nil
+			}
+			if chat, ok := n.groupChats[strings.ToLower(g.Name)]; ok {
+				return chat, nil
+			}
+		}
+	}
+	if n.defaultChat == "" {
+		return "", fmt.Errorf("%w %s", ErrNoTelegramChat, deviceID)
+	}
+	return n.defaultChat, nil
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// sendMessage sends text to chat, or holds it for the chat's digest when the
+// chat is over its rate limit.
+func (n *TelegramNotifier) sendMessage(ctx context.Context, chat, text string) error {
+	if ok, reopens := n.limiter.allow(chat); !ok {
+		n.hold(chat, text, reopens)
+		return nil
+	}
+	return n.post(ctx, chat, text)
+}
+
+// hold keeps text for the digest of chat and, for the first held message,
+// schedules FlushHeld for when the chat's window reopens.
+func (n *TelegramNotifier) hold(chat, text string, reopens time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	h, ok := n.held[chat]
+	if !ok {
+		h = &heldMessages{}
+		n.held[chat] = h
+		log.Printf("telegram: chat %s reached its rate limit, holding messages until %s", chat, reopens.Format(time.RFC3339))
+		n.scheduleFlush(reopens)
+	}
+	if len(h.texts) < maxHeldMessages {
+		h.texts = append(h.texts, text)
+	} else {
+		h.extra++
+	}
+}
+
+// scheduleFlush runs FlushHeld at about the given time of the limiter's clock.
+func (n *TelegramNotifier) scheduleFlush(at time.Time) {
+	time.AfterFunc(at.Sub(n.limiter.now())+time.Second, func() {
+		n.FlushHeld(context.Background())
+	})
+}
+
+// FlushHeld sends the held messages of every chat whose rate-limit window
+// has reopened as one digest message per chat. It runs on a timer after a
+// chat is limited; chats still limited are rescheduled.
+func (n *TelegramNotifier) FlushHeld(ctx context.Context) {
+	digests := make(map[string]string)
+
+	n.mu.Lock()
+	for chat, h := range n.held {
+		ok, reopens := n.limiter.allow(chat)
+		if !ok {
+			n.scheduleFlush(reopens)
+			continue
+		}
+		digests[chat] = h.digest()
+		delete(n.held, chat)
+	}
+	n.mu.Unlock()
+
+	for chat, text := range digests {
+		if err := n.post(ctx, chat, text); err != nil {
+			log.Printf("telegram: failed to send held messages to chat %s: %v", chat, err)
+		}
+	}
+}
+
+// digest joins the held messages into one message.
+func (h *heldMessages) digest() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "⏳ %d messages held back by the rate limit:", len(h.texts)+h.extra)
+	for _, text := range h.texts {
+		b.WriteString("\n\n")
+		b.WriteString(text)
+	}
+	if h.extra > 0 {
+		fmt.Fprintf(&b, "\n\n…and %d more", h.extra)
+	}
+	return b.String()
+}
+
+// post sends text to chat through the Bot API.
+func (n *TelegramNotifier) post(ctx context.Context, chat, text string) error {
+	if runes := []rune(text); len(runes) > telegramMaxText {
+		text = string(runes[:telegramMaxText-1]) + "…"
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chat,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("telegram: failed to encode message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.apiURL+"/bot"+n.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegram: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// The URL holds the bot token; keep it out of logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram: send failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result telegramResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil || !result.OK {
+		if result.Description != "" {
+			return fmt.Errorf("telegram: send failed with status %d: %s", resp.StatusCode, result.Description)
+		}
+		return fmt.Errorf("telegram: send failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// chatLimiter allows up to limit messages per chat in each window.
+type chatLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	now    func() time.Time
+	chats  map[string]*chatWindow
+}
+
+type chatWindow struct {
+	start time.Time
+	count int
+}
+
+func newChatLimiter(limit int, window time.Duration, now func() time.Time) *chatLimiter {
+	return &chatLimiter{limit: limit, window: window, now: now, chats: make(map[string]*chatWindow)}
+}
+
+// allow reports whether another message may go to chat now, and counts it.
+// When it may not, it also returns when the chat's window reopens.
+func (l *chatLimiter) allow(chat string) (bool, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	w, ok := l.chats[chat]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.chats[chat] = &chatWindow{start: now, count: 1}
+		return true, time.Time{}
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window)
+	}
+	w.count++
+	return true, time.Time{}
+}
@@ -0,0 +1,162 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/notification"
+)
+
+// fakeTelegram records sendMessage calls like the Bot API would receive them.
+type fakeTelegram struct {
+	mu       sync.Mutex
+	paths    []string
+	messages []map[string]interface{}
+	reply    string
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var msg map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&msg)
+	f.mu.Lock()
+	f.paths = append(f.paths, r.URL.Path)
+	f.messages = append(f.messages, msg)
+	reply := f.reply
+	f.mu.Unlock()
+
+	if reply == "" {
+		reply = `{"ok":true,"result":{}}`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(reply))
+}
+
+func newTelegram(t *testing.T, cfg config.TelegramConfig, groups notification.DeviceGroupsFunc, now func() time.Time) (*notification.TelegramNotifier, *fakeTelegram) {
+	t.Helper()
+	api := &fakeTelegram{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	cfg.BotToken = "123:abc"
+	cfg.APIURL = srv.URL
+	if now == nil {
+		now = time.Now
+	}
+	n, err := notification.NewTelegramNotifierForTest(cfg, groups, now)
+	require.NoError(t, err)
+	return n, api
+}
+
+var downAlert = notification.Alert{
+	Kind:        notification.AlertFiring,
+	RuleID:      "rule-2",
+	Description: "Device Down",
+	Severity:    "critical",
+	DeviceID:    "dev-1",
+	DeviceName:  "olt-jkt-1",
+	IPAddress:   "10.0.0.1",
+	MetricName:  "success",
+}
+
+func TestTelegramNotifier_RoutesByDeviceGroup(t *testing.T) {
+	groups := func(_ context.Context, deviceID string) ([]notification.GroupRef, error) {
+		switch deviceID {
+		case "dev-1":
+			return []notification.GroupRef{{ID: "g-rack", Name: "rack-a"}, {ID: "g-jkt", Name: "Jakarta"}}, nil
+		case "dev-2":
+			return []notification.GroupRef{{ID: "g-lab", Name: "lab"}}, nil
+		}
+		return nil, nil
+	}
+	n, api := newTelegram(t, config.TelegramConfig{
+		DefaultChatID: "-100",
+		GroupChats:    map[string]string{"jakarta": "-200"},
+	}, groups, nil)
+
+	require.NoError(t, n.NotifyAlert(context.Background(), downAlert))
+	other := downAlert
+	other.DeviceID = "dev-2"
+	require.NoError(t, n.NotifyAlert(context.Background(), other))
+
+	require.Len(t, api.messages, 2)
+	assert.Equal(t, "/bot123:abc/sendMessage", api.paths[0])
+	assert.Equal(t, "-200", api.messages[0]["chat_id"], "the nearest ancestor group with a chat")
+	assert.Equal(t, "-100", api.messages[1]["chat_id"], "groups without a chat use the default")
+	assert.Equal(t, "🚨 CRITICAL: Device Down\nDevice: olt-jkt-1 (10.0.0.1)\nsuccess = 0.00 (threshold 0)", api.messages[0]["text"])
+}
+
+func TestTelegramNotifier_Template(t *testing.T) {
+	n, api := newTelegram(t, config.TelegramConfig{
+		DefaultChatID: "-100",
+		Template:      `[{{.Kind}}] {{.DeviceName}}: {{.Description}}`,
+	}, nil, nil)
+
+	resolved := downAlert
+	resolved.Kind = notification.AlertResolved
+	require.NoError(t, n.NotifyAlert(context.Background(), resolved))
+	assert.Equal(t, "[resolved] olt-jkt-1: Device Down", api.messages[0]["text"])
+
+	_, err := notification.NewTelegramNotifier(config.TelegramConfig{
+		BotToken: "123:abc", DefaultChatID: "-100", Template: "{{.Missing",
+	}, nil)
+	assert.ErrorContains(t, err, "invalid template")
+}
+
+func TestTelegramNotifier_RateLimitsPerChat(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	n, api := newTelegram(t, config.TelegramConfig{DefaultChatID: "-100", RateLimit: 2}, nil, clock)
+
+	require.NoError(t, n.NotifyAlert(context.Background(), downAlert))
+	require.NoError(t, n.NotifyAlert(context.Background(), downAlert))
+	resolved := downAlert
+	resolved.Kind = notification.AlertResolved
+	require.NoError(t, n.NotifyAlert(context.Background(), resolved), "a limited message is held, not failed")
+	require.NoError(t, n.NotifyAlert(context.Background(), downAlert))
+	assert.Len(t, api.messages, 2, "held messages are not sent while the chat is limited")
+
+	n.FlushHeld(context.Background())
+	assert.Len(t, api.messages, 2, "the window has not reopened yet")
+
+	mu.Lock()
+	now = now.Add(time.Minute)
+	mu.Unlock()
+	n.FlushHeld(context.Background())
+	require.Len(t, api.messages, 3, "the held messages go out as one digest")
+	digest := api.messages[2]["text"].(string)
+	assert.Contains(t, digest, "2 messages held back by the rate limit")
+	assert.Contains(t, digest, "✅ RESOLVED: Device Down")
+	assert.Contains(t, digest, "🚨 CRITICAL: Device Down")
+
+	require.NoError(t, n.NotifyAlert(context.Background(), downAlert))
+	assert.Len(t, api.messages, 4, "the digest counts against the new window")
+}
+
+func TestTelegramNotifier_APIError(t *testing.T) {
+	n, api := newTelegram(t, config.TelegramConfig{DefaultChatID: "-100"}, nil, nil)
+	api.reply = `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`
+
+	err := n.Send("", "NMS Alert: Device Down", "body")
+	assert.ErrorContains(t, err, "chat not found")
+}
+
+func TestNewTelegramNotifier_Invalid(t *testing.T) {
+	_, err := notification.NewTelegramNotifier(config.TelegramConfig{DefaultChatID: "-100"}, nil)
+	assert.ErrorContains(t, err, "bot_token is required")
+
+	_, err = notification.NewTelegramNotifier(config.TelegramConfig{BotToken: "123:abc"}, nil)
+	assert.ErrorContains(t, err, "default_chat_id or group_chats is required")
+}
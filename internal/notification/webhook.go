@@ -0,0 +1,143 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/signature"
+)
+
+const (
+	// HeaderEvent carries the webhook event type.
+	HeaderEvent = "X-NMS-Event"
+
+	defaultWebhookTimeout    = 10 * time.Second
+	defaultWebhookMaxRetries = 3
+	defaultWebhookBackoff    = time.Second
+)
+
+// WebhookEvent is the JSON envelope POSTed to webhook endpoints.
+type WebhookEvent struct {
+	Event     string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookSender POSTs signed JSON events to a configured URL, retrying
+// transient failures with exponential backoff.
+type WebhookSender struct {
+	url        string
+	secret     []byte
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewWebhookSender creates a WebhookSender from config.
+func NewWebhookSender(cfg config.WebhookConfig) *WebhookSender {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	maxRetries := cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultWebhookMaxRetries
+	}
+
+	return &WebhookSender{
+		url:        cfg.URL,
+		secret:     []byte(cfg.Secret),
+		client:     &http.Client{Timeout: timeout},
+		maxRetries: maxRetries,
+		backoff:    defaultWebhookBackoff,
+	}
+}
+
+// NewWebhookSenderForTest creates a WebhookSender with a custom retry backoff.
+// This is intended for use in unit tests to avoid slow retries.
+func NewWebhookSenderForTest(cfg config.WebhookConfig, backoff time.Duration) *WebhookSender {
+	s := NewWebhookSender(cfg)
+	s.backoff = backoff
+	return s
+}
+
+// Post sends event with data to the webhook URL. Network errors and 5xx
+// responses are retried; other non-2xx responses fail immediately.
+func (s *WebhookSender) Post(ctx context.Context, event string, data interface{}) error {
+	return s.PostPayload(ctx, event, WebhookEvent{
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	})
+}
+
+// PostPayload sends payload as is, without the WebhookEvent envelope, for
+// endpoints that expect their own format such as Slack's. It is signed and
+// retried like Post.
+func (s *WebhookSender) PostPayload(ctx context.Context, event string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	backoff := s.backoff
+	var lastErr error
+
+	for attempt := 0; attempt < s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+				backoff *= 2
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		retry, err := s.send(ctx, event, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return fmt.Errorf("webhook delivery failed: %w", lastErr)
+}
+
+// send performs one delivery attempt and reports whether a failure is retryable.
+func (s *WebhookSender) send(ctx context.Context, event string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	ts := signature.Timestamp(time.Now())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(signature.HeaderTimestamp, ts)
+	if len(s.secret) > 0 {
+		req.Header.Set(signature.HeaderSignature, signature.Sign(s.secret, ts, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+	return resp.StatusCode >= 500, err
+}
@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/common/config"
+)
+
+const (
+	// EventAlertNotification is the webhook event of an alert notification.
+	EventAlertNotification = "alert.notification"
+
+	// WebhookFormatGeneric posts the signed WebhookEvent envelope.
+	WebhookFormatGeneric = "generic"
+	// WebhookFormatSlack posts {"text": ...}, which Slack and Mattermost
+	// incoming webhooks accept.
+	WebhookFormatSlack = "slack"
+)
+
+// AlertNotification is the data of a generic alert webhook event
+type AlertNotification struct {
+	To      string `json:"to,omitempty"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// WebhookNotifier delivers notifications to a webhook. It implements Service.
+type WebhookNotifier struct {
+	sender *WebhookSender
+	format string
+}
+
+// NewWebhookNotifier creates a WebhookNotifier from a webhook channel config
+func NewWebhookNotifier(cfg config.WebhookChannelConfig) (*WebhookNotifier, error) {
+	return newWebhookNotifier(NewWebhookSender(cfg.WebhookConfig), cfg)
+}
+
+// NewWebhookNotifierForTest creates a WebhookNotifier with a custom retry
+// backoff.
+func NewWebhookNotifierForTest(cfg config.WebhookChannelConfig, backoff time.Duration) (*WebhookNotifier, error) {
+	return newWebhookNotifier(NewWebhookSenderForTest(cfg.WebhookConfig, backoff), cfg)
+}
+
+func newWebhookNotifier(sender *WebhookSender, cfg config.WebhookChannelConfig) (*WebhookNotifier, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("webhook channel name is required")
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook %q: url is required", cfg.Name)
+	}
+	format := cfg.Format
+	if format == "" {
+		format = WebhookFormatGeneric
+	}
+	if format != WebhookFormatGeneric && format != WebhookFormatSlack {
+		return nil, fmt.Errorf("webhook %q: unsupported format %q", cfg.Name, cfg.Format)
+	}
+	return &WebhookNotifier{sender: sender, format: format}, nil
+}
+
+// Send posts the notification. to is included in generic events and ignored
+// by the Slack format, where the webhook URL picks the channel.
+func (n *WebhookNotifier) Send(to, subject, body string) error {
+	ctx := context.Background()
+	if n.format == WebhookFormatSlack {
+		return n.sender.PostPayload(ctx, EventAlertNotification, map[string]string{
+			"text": fmt.Sprintf("*%s*\n%s", subject, body),
+		})
+	}
+	return n.sender.Post(ctx, EventAlertNotification, AlertNotification{To: to, Subject: subject, Body: body})
+}
@@ -0,0 +1,79 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/signature"
+	"github.com/yourorg/nms-go/internal/notification"
+)
+
+func TestWebhookNotifier_Generic(t *testing.T) {
+	var gotBody []byte
+	var gotHeaders http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeaders = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	n, err := notification.NewWebhookNotifier(config.WebhookChannelConfig{
+		Name:          "ops",
+		WebhookConfig: config.WebhookConfig{URL: srv.URL, Secret: testSecret},
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Send("admin@example.com", "NMS Alert: Device Down", "ALERT [critical]: ..."))
+
+	var event struct {
+		Event string                         `json:"event"`
+		Data  notification.AlertNotification `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(gotBody, &event))
+	assert.Equal(t, notification.EventAlertNotification, event.Event)
+	assert.Equal(t, notification.AlertNotification{
+		To: "admin@example.com", Subject: "NMS Alert: Device Down", Body: "ALERT [critical]: ...",
+	}, event.Data)
+	assert.True(t, signature.Verify([]byte(testSecret), gotHeaders.Get(signature.HeaderTimestamp), gotBody,
+		gotHeaders.Get(signature.HeaderSignature)))
+}
+
+func TestWebhookNotifier_SlackRetries(t *testing.T) {
+	var attempts int32
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	n, err := notification.NewWebhookNotifierForTest(config.WebhookChannelConfig{
+		Name:          "ops-slack",
+		Format:        notification.WebhookFormatSlack,
+		WebhookConfig: config.WebhookConfig{URL: srv.URL},
+	}, 0)
+	require.NoError(t, err)
+	require.NoError(t, n.Send("", "NMS Alert: Device Down", "core-1 is down"))
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	assert.JSONEq(t, `{"text": "*NMS Alert: Device Down*\ncore-1 is down"}`, string(gotBody))
+}
+
+func TestNewWebhookNotifier_Invalid(t *testing.T) {
+	_, err := notification.NewWebhookNotifier(config.WebhookChannelConfig{Name: "ops"})
+	assert.ErrorContains(t, err, "url is required")
+
+	_, err = notification.NewWebhookNotifier(config.WebhookChannelConfig{
+		Name: "ops", Format: "teams", WebhookConfig: config.WebhookConfig{URL: "http://example.com"},
+	})
+	assert.ErrorContains(t, err, "unsupported format")
+}
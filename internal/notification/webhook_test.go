@@ -0,0 +1,110 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/signature"
+	"github.com/yourorg/nms-go/internal/notification"
+)
+
+const testSecret = "s3cret"
+
+func TestWebhookSender_PostSignsPayload(t *testing.T) {
+	var (
+		gotBody    []byte
+		gotHeaders http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sender := notification.NewWebhookSender(config.WebhookConfig{URL: srv.URL, Secret: testSecret})
+	data := map[string]string{"device_id": "dev-1", "new_status": "offline"}
+
+	require.NoError(t, sender.Post(context.Background(), "device.status_changed", data))
+
+	// Payload
+	var event struct {
+		Event     string            `json:"event"`
+		Timestamp time.Time         `json:"timestamp"`
+		Data      map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(gotBody, &event))
+	assert.Equal(t, "device.status_changed", event.Event)
+	assert.Equal(t, data, event.Data)
+	assert.False(t, event.Timestamp.IsZero())
+
+	// Headers and signature
+	assert.Equal(t, "application/json", gotHeaders.Get("Content-Type"))
+	assert.Equal(t, "device.status_changed", gotHeaders.Get(notification.HeaderEvent))
+
+	ts := gotHeaders.Get(signature.HeaderTimestamp)
+	sig := gotHeaders.Get(signature.HeaderSignature)
+	require.NotEmpty(t, ts)
+	assert.True(t, signature.Verify([]byte(testSecret), ts, gotBody, sig), "signature must verify")
+	assert.Equal(t, signature.Sign([]byte(testSecret), ts, gotBody), sig)
+	assert.False(t, signature.Verify([]byte("wrong"), ts, gotBody, sig))
+}
+
+func TestWebhookSender_RetriesServerErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sender := notification.NewWebhookSenderForTest(
+		config.WebhookConfig{URL: srv.URL, Secret: testSecret, MaxRetries: 3}, time.Millisecond)
+
+	require.NoError(t, sender.Post(context.Background(), "test", nil))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestWebhookSender_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	sender := notification.NewWebhookSenderForTest(
+		config.WebhookConfig{URL: srv.URL, MaxRetries: 2}, time.Millisecond)
+
+	err := sender.Post(context.Background(), "test", nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWebhookSender_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	sender := notification.NewWebhookSenderForTest(
+		config.WebhookConfig{URL: srv.URL, MaxRetries: 3}, time.Millisecond)
+
+	err := sender.Post(context.Background(), "test", nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
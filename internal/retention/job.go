@@ -0,0 +1,141 @@
+package retention
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/common/config"
+)
+
+const defaultInterval = 24 * time.Hour
+
+// Policy defines how long rows of a table are kept.
+type Policy struct {
+	Table         string
+	RetentionDays int
+}
+
+// Result describes the outcome of applying a policy once.
+type Result struct {
+	Table    string
+	Cutoff   time.Time
+	Affected int64 // rows deleted, or rows that would be deleted in dry-run mode
+	DryRun   bool
+	Skipped  bool
+	Err      error
+}
+
+// Job periodically applies retention policies.
+type Job struct {
+	repo     Repository
+	policies []Policy
+	dryRun   bool
+	interval time.Duration
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// NewJob creates a retention job from config. Tables with a non-positive
+// retention are kept forever.
+func NewJob(repo Repository, cfg config.RetentionConfig) *Job {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	policies := make([]Policy, 0, len(cfg.Tables))
+	for table, days := range cfg.Tables {
+		if days <= 0 {
+			continue
+		}
+		policies = append(policies, Policy{Table: table, RetentionDays: days})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Table < policies[j].Table })
+
+	return &Job{
+		repo:     repo,
+		policies: policies,
+		dryRun:   cfg.DryRun,
+		interval: interval,
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// NewJobForTest creates a Job with a fixed clock.
+// This is intended for use in unit tests.
+func NewJobForTest(repo Repository, cfg config.RetentionConfig, now func() time.Time) *Job {
+	j := NewJob(repo, cfg)
+	j.now = now
+	return j
+}
+
+// Policies returns the active policies, sorted by table name.
+func (j *Job) Policies() []Policy {
+	return j.policies
+}
+
+func (j *Job) Start() {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	log.Printf("Retention job started (interval %v, dry-run %v)", j.interval, j.dryRun)
+	j.RunOnce(context.Background())
+
+	for {
+		select {
+		case <-ticker.C:
+			j.RunOnce(context.Background())
+		case <-j.stopChan:
+			log.Println("Retention job stopped")
+			return
+		}
+	}
+}
+
+func (j *Job) Stop() {
+	close(j.stopChan)
+}
+
+// RunOnce applies every policy once and returns the per-table results.
+func (j *Job) RunOnce(ctx context.Context) []Result {
+	results := make([]Result, 0, len(j.policies))
+	for _, p := range j.policies {
+		results = append(results, j.apply(ctx, p))
+	}
+	return results
+}
+
+func (j *Job) apply(ctx context.Context, p Policy) Result {
+	res := Result{
+		Table:  p.Table,
+		Cutoff: j.now().AddDate(0, 0, -p.RetentionDays),
+		DryRun: j.dryRun,
+	}
+
+	if !j.repo.HasTable(ctx, p.Table) {
+		res.Skipped = true
+		return res
+	}
+
+	if j.dryRun {
+		res.Affected, res.Err = j.repo.CountOlderThan(ctx, p.Table, res.Cutoff)
+		if res.Err == nil {
+			log.Printf("Retention [dry-run]: would delete %d rows from %s older than %s",
+				res.Affected, p.Table, res.Cutoff.Format(time.RFC3339))
+		}
+	} else {
+		res.Affected, res.Err = j.repo.DeleteOlderThan(ctx, p.Table, res.Cutoff)
+		if res.Err == nil && res.Affected > 0 {
+			log.Printf("Retention: deleted %d rows from %s older than %s",
+				res.Affected, p.Table, res.Cutoff.Format(time.RFC3339))
+		}
+	}
+
+	if res.Err != nil {
+		log.Printf("Retention: %v", res.Err)
+	}
+	return res
+}
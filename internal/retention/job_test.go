@@ -0,0 +1,90 @@
+package retention_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/retention"
+)
+
+// MockRepository
+type MockRepository struct {
+	Tables  map[string]bool
+	Counted map[string]time.Time
+	Deleted map[string]time.Time
+}
+
+func newMockRepository(tables ...string) *MockRepository {
+	m := &MockRepository{
+		Tables:  make(map[string]bool),
+		Counted: make(map[string]time.Time),
+		Deleted: make(map[string]time.Time),
+	}
+	for _, t := range tables {
+		m.Tables[t] = true
+	}
+	return m
+}
+
+func (m *MockRepository) HasTable(ctx context.Context, table string) bool {
+	return m.Tables[table]
+}
+
+func (m *MockRepository) CountOlderThan(ctx context.Context, table string, cutoff time.Time) (int64, error) {
+	m.Counted[table] = cutoff
+	return 5, nil
+}
+
+func (m *MockRepository) DeleteOlderThan(ctx context.Context, table string, cutoff time.Time) (int64, error) {
+	m.Deleted[table] = cutoff
+	return 5, nil
+}
+
+var fixedNow = time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+func TestJob_RunOnce_DeletesPerTableCutoff(t *testing.T) {
+	repo := newMockRepository("audit_logs", "config_backups")
+	job := retention.NewJobForTest(repo, config.RetentionConfig{
+		Tables: map[string]int{"audit_logs": 90, "config_backups": 30, "forever": 0},
+	}, func() time.Time { return fixedNow })
+
+	results := job.RunOnce(context.Background())
+
+	require.Len(t, results, 2, "tables with non-positive retention are not cleaned")
+	assert.Equal(t, fixedNow.AddDate(0, 0, -90), repo.Deleted["audit_logs"])
+	assert.Equal(t, fixedNow.AddDate(0, 0, -30), repo.Deleted["config_backups"])
+	assert.Empty(t, repo.Counted)
+}
+
+func TestJob_RunOnce_DryRunOnlyCounts(t *testing.T) {
+	repo := newMockRepository("alert_history")
+	job := retention.NewJobForTest(repo, config.RetentionConfig{
+		DryRun: true,
+		Tables: map[string]int{"alert_history": 7},
+	}, func() time.Time { return fixedNow })
+
+	results := job.RunOnce(context.Background())
+
+	require.Len(t, results, 1)
+	assert.True(t, results[0].DryRun)
+	assert.Equal(t, int64(5), results[0].Affected)
+	assert.Equal(t, fixedNow.AddDate(0, 0, -7), repo.Counted["alert_history"])
+	assert.Empty(t, repo.Deleted, "dry-run must not delete")
+}
+
+func TestJob_RunOnce_SkipsMissingTables(t *testing.T) {
+	repo := newMockRepository()
+	job := retention.NewJobForTest(repo, config.RetentionConfig{
+		Tables: map[string]int{"audit_logs": 90},
+	}, func() time.Time { return fixedNow })
+
+	results := job.RunOnce(context.Background())
+
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Skipped)
+	assert.Empty(t, repo.Deleted)
+}
@@ -0,0 +1,69 @@
+// Package retention deletes old rows from append-only tables (audit logs,
+// config backups, alert history) so they do not grow unbounded.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// timestampColumn is the column compared against the retention cutoff.
+const timestampColumn = "created_at"
+
+// tableFilters replace the created_at condition for tables whose rows are
+// not all safe to delete once old. Alerts are kept while firing or
+// acknowledged, however long ago they triggered, since the alert engine
+// re-fires and re-notifies an alert whose active record is gone; resolved
+// ones expire by when they were resolved.
+var tableFilters = map[string]string{
+	"alert_history": "state = 'resolved' AND resolved_at < ?",
+}
+
+// olderThan scopes db to the rows of table past cutoff.
+func olderThan(db *gorm.DB, table string, cutoff time.Time) *gorm.DB {
+	if filter, ok := tableFilters[table]; ok {
+		return db.Where(filter, cutoff)
+	}
+	return db.Where(timestampColumn+" < ?", cutoff)
+}
+
+// Repository counts and deletes rows older than a cutoff.
+type Repository interface {
+	HasTable(ctx context.Context, table string) bool
+	CountOlderThan(ctx context.Context, table string, cutoff time.Time) (int64, error)
+	DeleteOlderThan(ctx context.Context, table string, cutoff time.Time) (int64, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) HasTable(ctx context.Context, table string) bool {
+	return r.db.WithContext(ctx).Migrator().HasTable(table)
+}
+
+func (r *gormRepository) CountOlderThan(ctx context.Context, table string, cutoff time.Time) (int64, error) {
+	var count int64
+	err := olderThan(r.db.WithContext(ctx).Table(table), table, cutoff).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows in %s: %w", table, err)
+	}
+	return count, nil
+}
+
+func (r *gormRepository) DeleteOlderThan(ctx context.Context, table string, cutoff time.Time) (int64, error) {
+	result := olderThan(r.db.WithContext(ctx).Table(table), table, cutoff).
+		Delete(map[string]interface{}{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete rows from %s: %w", table, result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,103 @@
+package retention_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/retention"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db, mock
+}
+
+func TestRepository_DeleteOlderThan_OnlyRowsBeforeCutoff(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := retention.NewRepository(db)
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// The delete must be bounded by the cutoff — a missing or inverted WHERE
+	// clause would wipe recent rows too.
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "audit_logs" WHERE created_at < $1`)).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteOlderThan(context.Background(), "audit_logs", cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteOlderThan_KeepsActiveAlerts(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := retention.NewRepository(db)
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A firing alert triggered long before the cutoff matches neither
+	// condition; only resolved alerts are bounded by resolved_at.
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "alert_history" WHERE state = 'resolved' AND resolved_at < $1`)).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	deleted, err := repo.DeleteOlderThan(context.Background(), "alert_history", cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CountOlderThan_ResolvedAlertsOnly(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := retention.NewRepository(db)
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "alert_history" WHERE state = 'resolved' AND resolved_at < $1`)).
+		WithArgs(cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	count, err := repo.CountOlderThan(context.Background(), "alert_history", cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CountOlderThan(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := retention.NewRepository(db)
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "config_backups" WHERE created_at < $1`)).
+		WithArgs(cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	count, err := repo.CountOlderThan(context.Background(), "config_backups", cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteOlderThan_Error(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := retention.NewRepository(db)
+
+	mock.ExpectExec(`DELETE FROM "alert_history"`).WillReturnError(assert.AnError)
+
+	_, err := repo.DeleteOlderThan(context.Background(), "alert_history", time.Now())
+	assert.ErrorIs(t, err, assert.AnError)
+}
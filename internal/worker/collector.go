@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/rates"
+)
+
+// PingFunc measures the round-trip time to ip and reports whether it answered.
+type PingFunc func(ip string) (time.Duration, bool)
+
+// SystemFunc reads CPU, memory and uptime from a device and reports whether
+// it succeeded.
+type SystemFunc func(ip, username, password string) (map[string]interface{}, bool)
+
+// Groups are the metric groups enabled for a task by its device type's
+// collection profile.
+type Groups struct {
+	Reachability bool
+	System       bool
+	PONPorts     bool
+	ONTs         bool
+	Interfaces   bool
+}
+
+// Collection is the outcome of polling one device.
+type Collection struct {
+	RTT     time.Duration
+	Success bool
+	// Values holds the collected metrics beyond rtt and success.
+	Values map[string]interface{}
+	// Points are written to the sink next to the device_poll point, e.g. one
+	// per OLT PON port.
+	Points []Point
+	// Err is set when nothing was polled, e.g. because no enabled group can
+	// be collected over the protocol.
+	Err string
+}
+
+// Point is one measurement written to the sink. The worker adds the device
+// tags.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
+
+// Collector polls devices over one protocol.
+type Collector interface {
+	Collect(ctx context.Context, task commonModel.PollTask, groups Groups) Collection
+}
+
+// DefaultCollectors returns the collectors for the protocols workers poll,
+// keyed by PollTask.Protocol. OLTs polled over SNMP are read with the ZTE
+// client, other SNMP devices over the standard MIBs.
+func DefaultCollectors(ping PingFunc, system SystemFunc) map[string]Collector {
+	reachability := &PingCollector{Ping: ping}
+	snmp := &ByDeviceType{
+		Default: &SNMPCollector{Ping: ping, NewClient: NewSNMPClient, Rates: rates.New()},
+		Types:   map[string]Collector{"olt": &OLTCollector{Ping: ping, NewClient: NewZTEClient}},
+	}
+	return map[string]Collector{
+		"mikrotik_api": &MikrotikCollector{Ping: ping, System: system},
+		"snmp":         snmp,
+		"ssh":          reachability,
+		"":             reachability,
+	}
+}
+
+// MikrotikCollector reads system resources over the RouterOS API and pings
+// for the round-trip time.
+type MikrotikCollector struct {
+	Ping   PingFunc
+	System SystemFunc
+}
+
+func (c *MikrotikCollector) Collect(ctx context.Context, task commonModel.PollTask, groups Groups) Collection {
+	var result Collection
+
+	if groups.System {
+		// TODO: Fetch credentials from somewhere secure.
+		// For MVP, hardcoded or passed in task (security risk)
+		// Assuming "admin" / "admin" for test
+		result.Values, result.Success = c.System(task.IPAddress, "admin", "admin")
+	}
+
+	if groups.Reachability {
+		// Also do a ping for RTT; it decides success only without system metrics
+		var reachable bool
+		result.RTT, reachable = c.Ping(task.IPAddress)
+		if !groups.System {
+			result.Success = reachable
+		}
+	}
+
+	if !groups.System && !groups.Reachability {
+		result.Err = noGroupsError(task.DeviceType, task.Protocol)
+	}
+	return result
+}
+
+// PingCollector only checks reachability; used for protocols without a
+// metrics client.
+type PingCollector struct {
+	Ping PingFunc
+}
+
+func (c *PingCollector) Collect(ctx context.Context, task commonModel.PollTask, groups Groups) Collection {
+	if !groups.Reachability {
+		return Collection{Err: noGroupsError(task.DeviceType, task.Protocol)}
+	}
+	rtt, success := c.Ping(task.IPAddress)
+	return Collection{RTT: rtt, Success: success}
+}
@@ -3,133 +3,358 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
-	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/nats-io/nats.go"
 	"github.com/yourorg/nms-go/internal/common/adapter"
 	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/queue"
+	"github.com/yourorg/nms-go/internal/common/sink"
 )
 
+// PollTasksSubject is the subject the collector publishes poll tasks on.
+const PollTasksSubject = "nms.poll.tasks"
+
+// MetricsSubject is the subject poll results are published on for the alert
+// engine.
+const MetricsSubject = "nms.metrics"
+
+// MetricPublisher hands a poll result to the alert engine.
+type MetricPublisher interface {
+	Publish(metric commonModel.Metric) error
+}
+
+// natsPublisher publishes metrics as JSON on MetricsSubject.
+type natsPublisher struct {
+	conn queue.Conn
+}
+
+// NewNATSPublisher creates a MetricPublisher that publishes on nc.
+func NewNATSPublisher(nc queue.Conn) MetricPublisher {
+	return &natsPublisher{conn: nc}
+}
+
+func (p *natsPublisher) Publish(metric commonModel.Metric) error {
+	payload, err := json.Marshal(metric)
+	if err != nil {
+		return fmt.Errorf("failed to encode metric: %w", err)
+	}
+	return p.conn.Publish(MetricsSubject, payload)
+}
+
 type Worker struct {
-	natsConn     *nats.Conn
-	influxClient influxdb2.Client
-	influxConfig config.InfluxConfig
-	stopChan     chan struct{}
+	natsConn   queue.Conn
+	publisher  MetricPublisher
+	sink       sink.MetricSink
+	cfg        config.WorkerConfig
+	profiles   CollectionProfiles
+	collectors map[string]Collector
+	queue      *taskQueue
+	stopChan   chan struct{}
+}
+
+// defaultConcurrency applies when the config leaves worker.concurrency unset.
+const defaultConcurrency = 20
+
+// NewWorker creates a Worker that collects the metric groups of
+// cfg.Profiles for each device type.
+func NewWorker(nc queue.Conn, metricSink sink.MetricSink, cfg config.WorkerConfig) *Worker {
+	pingAdapter := &PingAdapter{}
+	return NewWorkerForTest(nc, metricSink, cfg, pingAdapter.Ping, adapter.NewMikrotikAdapter().FetchSystemResources)
+}
+
+// NewWorkerForTest creates a Worker that pings and reads system resources
+// with the given functions instead of reaching real devices.
+func NewWorkerForTest(nc queue.Conn, metricSink sink.MetricSink, cfg config.WorkerConfig, ping PingFunc, system SystemFunc) *Worker {
+	return NewWorkerWithCollectors(nc, NewNATSPublisher(nc), metricSink, cfg, DefaultCollectors(ping, system))
 }
 
-func NewWorker(nc *nats.Conn, ic influxdb2.Client, iConfig config.InfluxConfig) *Worker {
+// NewWorkerWithCollectors creates a Worker that subscribes to tasks on nc,
+// polls them with the collector registered for their protocol and publishes
+// the results with publisher.
+func NewWorkerWithCollectors(nc queue.Conn, publisher MetricPublisher, metricSink sink.MetricSink, cfg config.WorkerConfig, collectors map[string]Collector) *Worker {
 	return &Worker{
-		natsConn:     nc,
-		influxClient: ic,
-		influxConfig: iConfig,
-		stopChan:     make(chan struct{}),
+		natsConn:   nc,
+		publisher:  publisher,
+		sink:       metricSink,
+		cfg:        cfg,
+		profiles:   NewCollectionProfiles(cfg.Profiles),
+		collectors: collectors,
+		queue:      newTaskQueue(),
+		stopChan:   make(chan struct{}),
 	}
 }
 
+// Start subscribes to poll tasks and blocks until Stop is called. Workers
+// join the configured queue group so replicas share the tasks instead of
+// each polling every device.
+//
+// At most cfg.Concurrency tasks are polled at once. The rest wait in a
+// queue and are taken highest priority first, so critical devices are not
+// starved by a backlog of low-priority ones. Over JetStream the worker
+// pulls tasks only while it has a free slot, so the queue never holds more
+// than that and the tasks it cannot take yet stay with the stream for other
+// replicas.
 func (w *Worker) Start() {
-	log.Println("Worker started, subscribing to nms.poll.tasks")
+	concurrency := w.cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	log.Printf("Worker %s started, subscribing to %s (queue group %q, concurrency %d)", w.cfg.ID, PollTasksSubject, w.cfg.Group, concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				queued, ok := w.queue.Pop()
+				if !ok {
+					return
+				}
+				if expired(queued.task, time.Now()) {
+					log.Printf("Dropping stale poll task for device %s from %s", queued.task.DeviceID, queued.task.Timestamp.Format(time.RFC3339))
+				} else {
+					// The task may have waited in the queue for part of its ack wait
+					queue.InProgress(queued.msg)
+					w.ProcessTask(context.Background(), queued.task)
+				}
+				queue.Ack(queued.msg)
+				w.queue.Done()
+			}
+		}()
+	}
 
-	sub, err := w.natsConn.Subscribe("nms.poll.tasks", func(msg *nats.Msg) {
-		var task commonModel.PollTask
-		if err := json.Unmarshal(msg.Data, &task); err != nil {
-			log.Printf("Error unmarshalling task: %v", err)
+	var unsubscribe func() error
+	puller, pull := w.natsConn.(queue.PullConn)
+	switch {
+	case pull && w.cfg.Group != "":
+		sub, err := puller.PullSubscribe(PollTasksSubject, w.cfg.Group)
+		if err != nil {
+			log.Fatalf("Error communicating with NATS: %v", err)
+		}
+		unsubscribe = sub.Unsubscribe
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.pull(sub, concurrency)
+		}()
+	default:
+		var sub *nats.Subscription
+		var err error
+		if w.cfg.Group != "" {
+			sub, err = w.natsConn.QueueSubscribe(PollTasksSubject, w.cfg.Group, w.enqueue)
+		} else {
+			// Without a group every worker receives every task
+			sub, err = w.natsConn.Subscribe(PollTasksSubject, w.enqueue)
+		}
+		if err != nil {
+			log.Fatalf("Error communicating with NATS: %v", err)
+		}
+		unsubscribe = sub.Unsubscribe
+	}
+
+	<-w.stopChan
+
+	unsubscribe()
+	if dropped := w.queue.Close(); len(dropped) > 0 {
+		// Hand them back for redelivery to another worker
+		for _, msg := range dropped {
+			queue.Nak(msg)
+		}
+		log.Printf("Worker %s stopped with %d queued tasks not polled", w.cfg.ID, len(dropped))
+	}
+	// Let polls in progress finish
+	wg.Wait()
+}
+
+// expired reports whether the device's next poll was due by now, e.g. for a
+// task that waited in the stream while the workers were down. Polling it
+// would only duplicate the newer task.
+func expired(task commonModel.PollTask, now time.Time) bool {
+	return !task.ExpiresAt.IsZero() && now.After(task.ExpiresAt)
+}
+
+// pullWait bounds how long a fetch waits for tasks, and how long the worker
+// waits after a failed fetch.
+const pullWait = 5 * time.Second
+
+// pull fetches tasks from sub as polling slots free up, until the queue is
+// closed.
+func (w *Worker) pull(sub queue.PullSubscription, concurrency int) {
+	for {
+		free, ok := w.queue.WaitCapacity(concurrency)
+		if !ok {
 			return
 		}
 
-		fmt.Printf("Initial worker received task: %v\n", task)
-		go w.processTask(task)
-	})
+		msgs, err := sub.Fetch(free, nats.MaxWait(pullWait))
+		if err != nil && !errors.Is(err, nats.ErrTimeout) {
+			select {
+			case <-w.stopChan:
+				return
+			case <-time.After(pullWait):
+			}
+			log.Printf("Error fetching poll tasks: %v", err)
+			continue
+		}
+		for _, msg := range msgs {
+			w.enqueue(msg)
+		}
+	}
+}
 
-	if err != nil {
-		log.Fatalf("Error communicating with NATS: %v", err)
+// enqueue decodes a poll task and queues it for a polling slot.
+func (w *Worker) enqueue(msg *nats.Msg) {
+	var task commonModel.PollTask
+	if err := json.Unmarshal(msg.Data, &task); err != nil {
+		log.Printf("Error unmarshalling task: %v", err)
+		queue.Term(msg)
+		return
 	}
-	defer sub.Unsubscribe()
 
-	<-w.stopChan
+	fmt.Printf("Initial worker received task: %v\n", task)
+	if !w.queue.Push(task, msg) {
+		// Stopping; let another worker have it
+		queue.Nak(msg)
+	}
 }
 
 func (w *Worker) Stop() {
 	close(w.stopChan)
 }
 
-func (w *Worker) processTask(task commonModel.PollTask) {
-	// Adapter selection logic
-	var rtt time.Duration
-	var success bool
-	var metrics map[string]interface{}
+// unsupportedProtocolError is recorded when no collector exists for a task's protocol
+func unsupportedProtocolError(protocol string) string {
+	return fmt.Sprintf("unsupported protocol: %s", protocol)
+}
 
-	// Measure total poll duration
-	pollStart := time.Now()
+// noGroupsError is recorded when the device type's profile leaves nothing to
+// collect over the task's protocol
+func noGroupsError(deviceType, protocol string) string {
+	return fmt.Sprintf("no metric groups enabled for device type %q over %s", deviceType, protocol)
+}
 
-	if task.Protocol == "mikrotik_api" {
-		// TODO: Fetch credentials from somewhere secure.
-		// For MVP, hardcoded or passed in task (security risk)
-		// Assuming "admin" / "admin" for test
-		mtAdapter := adapter.NewMikrotikAdapter()
-		m, ok := mtAdapter.FetchSystemResources(task.IPAddress, "admin", "admin")
-		success = ok
-		metrics = m
+// ProcessTask collects the metrics of task and publishes them to the alert
+// engine. A failed publish is logged; the poll is not retried.
+func (w *Worker) ProcessTask(ctx context.Context, task commonModel.PollTask) {
+	metric := w.Collect(ctx, task)
 
-		// Also do a ping for RTT
-		pingAdapter := &PingAdapter{}
-		rtt, _ = pingAdapter.Ping(task.IPAddress)
+	if err := w.publisher.Publish(metric); err != nil {
+		log.Printf("Error publishing metrics to NATS: %v", err)
+	}
+}
 
-	} else {
-		// Default to Ping
-		pingAdapter := &PingAdapter{}
-		rtt, success = pingAdapter.Ping(task.IPAddress)
+// Collect polls the device described by task, writes the result to the sink
+// and returns the metric to publish. Only the metric groups in the device
+// type's profile are collected. Protocols without a collector, and profiles
+// leaving nothing to collect, produce a failed metric carrying an "error"
+// value instead of being polled.
+func (w *Worker) Collect(ctx context.Context, task commonModel.PollTask) commonModel.Metric {
+	groups := Groups{
+		Reachability: w.profiles.Collects(task.DeviceType, GroupReachability),
+		System:       w.profiles.Collects(task.DeviceType, GroupSystem),
+		PONPorts:     w.profiles.Collects(task.DeviceType, GroupPONPorts),
+		ONTs:         w.profiles.Collects(task.DeviceType, GroupONTs),
+		Interfaces:   w.profiles.Collects(task.DeviceType, GroupInterfaces),
 	}
 
-	duration := time.Since(pollStart)
+	// Measure total poll duration
+	pollStart := time.Now()
 
-	// Write metrics to Influx
-	writeAPI := w.influxClient.WriteAPIBlocking(w.influxConfig.Org, w.influxConfig.Bucket)
+	// The community is encrypted in transit with the shared key, see PollTask
+	var result Collection
+	community, err := crypto.Decrypt(task.SNMPCommunity)
+	collector, ok := w.collectors[task.Protocol]
+	switch {
+	case !ok:
+		result.Err = unsupportedProtocolError(task.Protocol)
+	case err != nil:
+		result.Err = fmt.Sprintf("failed to decrypt SNMP community: %v", err)
+	default:
+		task.SNMPCommunity = community
+		result = collector.Collect(ctx, task, groups)
+	}
+	if result.Err != "" {
+		log.Printf("Skipping poll for device %s: %s", task.DeviceID, result.Err)
+	}
 
-	rttMs := float64(rtt.Microseconds()) / 1000.0
-	p := influxdb2.NewPoint(
-		"device_poll",
-		map[string]string{
-			"device_id":   task.DeviceID,
-			"ip_address":  task.IPAddress,
-			"device_type": task.DeviceType,
-		},
-		map[string]interface{}{
-			"rtt_ms":           rttMs,
-			"success":          success,
-			"poll_duration_ms": float64(duration.Milliseconds()),
-		},
-		time.Now(),
-	)
+	duration := time.Since(pollStart)
 
-	if err := writeAPI.WritePoint(context.Background(), p); err != nil {
-		log.Printf("Error writing metrics to Influx: %v", err)
-	}
+	rttMs := float64(result.RTT.Microseconds()) / 1000.0
+	w.writePoll(ctx, task, rttMs, result.Success, duration)
+	w.writePoints(ctx, task, result.Points)
 
 	// Prepare Values map
 	values := map[string]interface{}{
 		"rtt_ms":  rttMs,
-		"success": success,
+		"success": result.Success,
+	}
+	if result.Err != "" {
+		values[commonModel.ValueError] = result.Err
 	}
 
 	// Add other collected metrics (e.g. from Mikrotik)
-	for k, v := range metrics {
+	for k, v := range result.Values {
 		values[k] = v
 	}
 
-	// Publish metric to Alert Engine
-	metric := commonModel.Metric{
+	return commonModel.Metric{
 		DeviceID:  task.DeviceID,
 		IPAddress: task.IPAddress,
 		Timestamp: time.Now(),
 		Values:    values,
 	}
+}
 
-	payload, _ := json.Marshal(metric)
-	if err := w.natsConn.Publish("nms.metrics", payload); err != nil {
-		log.Printf("Error publishing metrics to NATS: %v", err)
+// writePoll writes the outcome of one poll to the configured sink.
+func (w *Worker) writePoll(ctx context.Context, task commonModel.PollTask, rttMs float64, success bool, duration time.Duration) {
+	err := w.sink.Write(
+		ctx,
+		"device_poll",
+		deviceTags(task),
+		map[string]interface{}{
+			"rtt_ms":           rttMs,
+			"success":          success,
+			"poll_duration_ms": float64(duration.Milliseconds()),
+		},
+		time.Now(),
+	)
+	if err != nil {
+		log.Printf("Error writing metrics to sink: %v", err)
+	}
+}
+
+// writePoints writes the points a collector returned beyond the poll itself,
+// tagged with the device. A failed write is logged and the rest still
+// written.
+func (w *Worker) writePoints(ctx context.Context, task commonModel.PollTask, points []Point) {
+	for _, p := range points {
+		tags := deviceTags(task)
+		for k, v := range p.Tags {
+			tags[k] = v
+		}
+		ts := p.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		if err := w.sink.Write(ctx, p.Measurement, tags, p.Fields, ts); err != nil {
+			log.Printf("Error writing %s to sink: %v", p.Measurement, err)
+		}
+	}
+}
+
+// deviceTags identifies the polled device on every point written for it.
+func deviceTags(task commonModel.PollTask) map[string]string {
+	return map[string]string{
+		"device_id":   task.DeviceID,
+		"ip_address":  task.IPAddress,
+		"device_type": task.DeviceType,
 	}
 }
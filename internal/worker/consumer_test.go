@@ -0,0 +1,368 @@
+package worker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/worker"
+)
+
+type writtenPoint struct {
+	measurement string
+	fields      map[string]interface{}
+}
+
+type fakeSink struct {
+	mu     sync.Mutex
+	points []writtenPoint
+}
+
+func (f *fakeSink) Write(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.points = append(f.points, writtenPoint{measurement: measurement, fields: fields})
+	return nil
+}
+
+func (f *fakeSink) Close() error { return nil }
+
+type subscription struct {
+	subject string
+	queue   string
+}
+
+type message struct {
+	subject string
+	data    []byte
+}
+
+// fakeConn records subscriptions and published messages instead of talking
+// to a NATS server.
+type fakeConn struct {
+	mu         sync.Mutex
+	subs       []subscription
+	published  []message
+	handler    nats.MsgHandler
+	subscribed chan struct{}
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{subscribed: make(chan struct{}, 1)}
+}
+
+func (f *fakeConn) Publish(subj string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published = append(f.published, message{subject: subj, data: data})
+	return nil
+}
+
+func (f *fakeConn) Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return f.QueueSubscribe(subj, "", cb)
+}
+
+func (f *fakeConn) QueueSubscribe(subj, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	f.mu.Lock()
+	f.subs = append(f.subs, subscription{subject: subj, queue: queue})
+	f.handler = cb
+	f.mu.Unlock()
+	f.subscribed <- struct{}{}
+	return &nats.Subscription{}, nil
+}
+
+func runWorker(t *testing.T, cfg config.WorkerConfig) *fakeConn {
+	t.Helper()
+	nc := newFakeConn()
+	w := worker.NewWorker(nc, &fakeSink{}, cfg)
+
+	done := make(chan struct{})
+	go func() {
+		w.Start()
+		close(done)
+	}()
+
+	select {
+	case <-nc.subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not subscribe")
+	}
+	w.Stop()
+	<-done
+	return nc
+}
+
+func TestWorker_UsesQueueGroup(t *testing.T) {
+	nc := runWorker(t, config.WorkerConfig{ID: "worker-1", Group: "nms-workers"})
+
+	require.Len(t, nc.subs, 1)
+	assert.Equal(t, subscription{subject: worker.PollTasksSubject, queue: "nms-workers"}, nc.subs[0])
+}
+
+func TestWorker_NoGroupSubscribesToAll(t *testing.T) {
+	nc := runWorker(t, config.WorkerConfig{ID: "worker-1"})
+
+	require.Len(t, nc.subs, 1)
+	assert.Equal(t, subscription{subject: worker.PollTasksSubject}, nc.subs[0])
+}
+
+func TestCollect_UnsupportedProtocol(t *testing.T) {
+	for _, protocol := range []string{"telnet", "tr069"} {
+		t.Run(protocol, func(t *testing.T) {
+			fs := &fakeSink{}
+			w := worker.NewWorker(nil, fs, config.WorkerConfig{})
+
+			metric := w.Collect(context.Background(), commonModel.PollTask{
+				DeviceID:  "dev-1",
+				IPAddress: "10.0.0.1",
+				Protocol:  protocol,
+			})
+
+			assert.Equal(t, "dev-1", metric.DeviceID)
+			assert.Equal(t, false, metric.Values["success"])
+			assert.Equal(t, "unsupported protocol: "+protocol, metric.Values[commonModel.ValueError])
+
+			require.Len(t, fs.points, 1)
+			assert.Equal(t, "device_poll", fs.points[0].measurement)
+			assert.Equal(t, false, fs.points[0].fields["success"])
+		})
+	}
+}
+
+// fakeProbes records which metric groups a worker collected.
+type fakeProbes struct {
+	pinged []string
+	system []string
+}
+
+func (f *fakeProbes) ping(ip string) (time.Duration, bool) {
+	f.pinged = append(f.pinged, ip)
+	return 5 * time.Millisecond, true
+}
+
+func (f *fakeProbes) fetchSystem(ip, username, password string) (map[string]interface{}, bool) {
+	f.system = append(f.system, ip)
+	return map[string]interface{}{"cpu_load": 12.0}, true
+}
+
+func TestCollect_ProfileRestrictsGroups(t *testing.T) {
+	cfg := config.WorkerConfig{Profiles: map[string][]string{
+		"olt":    {"reachability"},
+		"router": {"reachability", "system"},
+	}}
+
+	tests := []struct {
+		name       string
+		deviceType string
+		wantPing   bool
+		wantSystem bool
+	}{
+		{"reachability only", "olt", true, false},
+		{"all groups", "router", true, true},
+		{"no profile collects everything", "switch", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := &fakeProbes{}
+			w := worker.NewWorkerForTest(nil, &fakeSink{}, cfg, probes.ping, probes.fetchSystem)
+
+			metric := w.Collect(context.Background(), commonModel.PollTask{
+				DeviceID:   "dev-1",
+				IPAddress:  "10.0.0.1",
+				DeviceType: tt.deviceType,
+				Protocol:   "mikrotik_api",
+			})
+
+			assert.Equal(t, tt.wantPing, len(probes.pinged) == 1)
+			assert.Equal(t, tt.wantSystem, len(probes.system) == 1)
+			_, hasCPU := metric.Values["cpu_load"]
+			assert.Equal(t, tt.wantSystem, hasCPU)
+			assert.Equal(t, true, metric.Values["success"])
+		})
+	}
+}
+
+func TestCollect_ProfileWithNothingToCollect(t *testing.T) {
+	probes := &fakeProbes{}
+	cfg := config.WorkerConfig{Profiles: map[string][]string{"SWITCH": {"system"}}}
+	w := worker.NewWorkerForTest(nil, &fakeSink{}, cfg, probes.ping, probes.fetchSystem)
+
+	metric := w.Collect(context.Background(), commonModel.PollTask{
+		DeviceID:   "dev-1",
+		IPAddress:  "10.0.0.1",
+		DeviceType: "switch",
+		Protocol:   "ssh",
+	})
+
+	assert.Empty(t, probes.pinged)
+	assert.Equal(t, false, metric.Values["success"])
+	assert.Contains(t, metric.Values[commonModel.ValueError], "no metric groups enabled")
+}
+
+func TestCollectionProfiles_IgnoresUnknownGroups(t *testing.T) {
+	profiles := worker.NewCollectionProfiles(map[string][]string{"router": {"System", "bgp_peers"}})
+
+	assert.True(t, profiles.Collects("router", worker.GroupSystem))
+	assert.False(t, profiles.Collects("router", worker.GroupReachability))
+	assert.False(t, profiles.Collects("router", "bgp_peers"))
+	assert.True(t, profiles.Collects("switch", worker.GroupReachability))
+}
+
+// publishedMetric decodes the single metric published on nc.
+func publishedMetric(t *testing.T, nc *fakeConn) commonModel.Metric {
+	t.Helper()
+	require.Len(t, nc.published, 1)
+	assert.Equal(t, worker.MetricsSubject, nc.published[0].subject)
+
+	var metric commonModel.Metric
+	require.NoError(t, json.Unmarshal(nc.published[0].data, &metric))
+	return metric
+}
+
+func TestProcessTask_MikrotikPublishesAndWrites(t *testing.T) {
+	nc := newFakeConn()
+	fs := &fakeSink{}
+	probes := &fakeProbes{}
+	w := worker.NewWorkerForTest(nc, fs, config.WorkerConfig{}, probes.ping, probes.fetchSystem)
+
+	w.ProcessTask(context.Background(), commonModel.PollTask{
+		DeviceID:  "dev-1",
+		IPAddress: "10.0.0.1",
+		Protocol:  "mikrotik_api",
+	})
+
+	assert.Equal(t, []string{"10.0.0.1"}, probes.system)
+	assert.Equal(t, []string{"10.0.0.1"}, probes.pinged)
+
+	require.Len(t, fs.points, 1)
+	assert.Equal(t, "device_poll", fs.points[0].measurement)
+	assert.Equal(t, true, fs.points[0].fields["success"])
+	assert.Equal(t, 5.0, fs.points[0].fields["rtt_ms"])
+
+	metric := publishedMetric(t, nc)
+	assert.Equal(t, "dev-1", metric.DeviceID)
+	assert.Equal(t, true, metric.Values["success"])
+	assert.Equal(t, 12.0, metric.Values["cpu_load"])
+}
+
+func TestProcessTask_PingOnlyProtocols(t *testing.T) {
+	for _, protocol := range []string{"ssh", ""} {
+		t.Run(protocol, func(t *testing.T) {
+			nc := newFakeConn()
+			fs := &fakeSink{}
+			probes := &fakeProbes{}
+			w := worker.NewWorkerForTest(nc, fs, config.WorkerConfig{}, probes.ping, probes.fetchSystem)
+
+			w.ProcessTask(context.Background(), commonModel.PollTask{
+				DeviceID:  "dev-2",
+				IPAddress: "10.0.0.2",
+				Protocol:  protocol,
+			})
+
+			assert.Equal(t, []string{"10.0.0.2"}, probes.pinged)
+			assert.Empty(t, probes.system)
+			require.Len(t, fs.points, 1)
+
+			metric := publishedMetric(t, nc)
+			assert.Equal(t, true, metric.Values["success"])
+			assert.Equal(t, 5.0, metric.Values["rtt_ms"])
+			assert.NotContains(t, metric.Values, "cpu_load")
+		})
+	}
+}
+
+type fakeCollector struct {
+	groups []worker.Groups
+	tasks  []commonModel.PollTask
+	result worker.Collection
+}
+
+func (f *fakeCollector) Collect(ctx context.Context, task commonModel.PollTask, groups worker.Groups) worker.Collection {
+	f.groups = append(f.groups, groups)
+	f.tasks = append(f.tasks, task)
+	return f.result
+}
+
+type fakePublisher struct {
+	mu      sync.Mutex
+	metrics []commonModel.Metric
+	err     error
+}
+
+func (f *fakePublisher) Publish(metric commonModel.Metric) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.metrics = append(f.metrics, metric)
+	return f.err
+}
+
+func TestProcessTask_DispatchesToRegisteredCollector(t *testing.T) {
+	collector := &fakeCollector{result: worker.Collection{
+		RTT:     1500 * time.Microsecond,
+		Success: true,
+		Values:  map[string]interface{}{"uptime_s": 3600.0},
+	}}
+	publisher := &fakePublisher{}
+	fs := &fakeSink{}
+	cfg := config.WorkerConfig{Profiles: map[string][]string{"olt": {"system"}}}
+	w := worker.NewWorkerWithCollectors(nil, publisher, fs, cfg, map[string]worker.Collector{"snmp": collector})
+
+	w.ProcessTask(context.Background(), commonModel.PollTask{DeviceID: "dev-3", DeviceType: "olt", Protocol: "snmp"})
+
+	require.Len(t, collector.groups, 1)
+	assert.Equal(t, worker.Groups{System: true}, collector.groups[0])
+
+	require.Len(t, publisher.metrics, 1)
+	assert.Equal(t, 1.5, publisher.metrics[0].Values["rtt_ms"])
+	assert.Equal(t, 3600.0, publisher.metrics[0].Values["uptime_s"])
+	require.Len(t, fs.points, 1)
+	assert.Equal(t, true, fs.points[0].fields["success"])
+}
+
+func TestProcessTask_DecryptsSNMPCommunity(t *testing.T) {
+	c, err := crypto.NewCipherFromKey("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	crypto.SetDefault(c)
+	t.Cleanup(func() { crypto.SetDefault(nil) })
+	encrypted, err := crypto.Encrypt("s3cret")
+	require.NoError(t, err)
+
+	collector := &fakeCollector{result: worker.Collection{Success: true}}
+	cfg := config.WorkerConfig{Profiles: map[string][]string{"olt": {"system"}}}
+	w := worker.NewWorkerWithCollectors(nil, &fakePublisher{}, &fakeSink{}, cfg, map[string]worker.Collector{"snmp": collector})
+
+	w.ProcessTask(context.Background(), commonModel.PollTask{DeviceID: "dev-3", DeviceType: "olt", Protocol: "snmp", SNMPCommunity: encrypted})
+	require.Len(t, collector.tasks, 1)
+	assert.Equal(t, "s3cret", collector.tasks[0].SNMPCommunity)
+
+	// Without the key the device is not polled with a garbled community
+	crypto.SetDefault(nil)
+	publisher := &fakePublisher{}
+	w = worker.NewWorkerWithCollectors(nil, publisher, &fakeSink{}, cfg, map[string]worker.Collector{"snmp": collector})
+	w.ProcessTask(context.Background(), commonModel.PollTask{DeviceID: "dev-3", DeviceType: "olt", Protocol: "snmp", SNMPCommunity: encrypted})
+	assert.Len(t, collector.tasks, 1)
+	require.Len(t, publisher.metrics, 1)
+	assert.Contains(t, publisher.metrics[0].Values["error"], "failed to decrypt SNMP community")
+}
+
+func TestProcessTask_PublishFailureStillWrites(t *testing.T) {
+	publisher := &fakePublisher{err: errors.New("nats: connection closed")}
+	fs := &fakeSink{}
+	w := worker.NewWorkerWithCollectors(nil, publisher, fs, config.WorkerConfig{}, map[string]worker.Collector{})
+
+	w.ProcessTask(context.Background(), commonModel.PollTask{DeviceID: "dev-4", Protocol: "telnet"})
+
+	require.Len(t, publisher.metrics, 1)
+	assert.Equal(t, "unsupported protocol: telnet", publisher.metrics[0].Values[commonModel.ValueError])
+	require.Len(t, fs.points, 1)
+	assert.Equal(t, false, fs.points[0].fields["success"])
+}
@@ -0,0 +1,224 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	devicemodel "github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
+)
+
+// OLT polls use the ONT timeout of the on-demand OLT API, since a walk of
+// every ONT on a fully populated OLT is the slowest operation.
+const (
+	oltPollTimeout = 60 * time.Second
+	oltPollMaxONTs = 8192
+)
+
+// OLTClient is the part of zte.ZTEOLTClient used for background polls.
+type OLTClient interface {
+	Connect(ctx context.Context, device *devicemodel.Device) error
+	Disconnect() error
+	GetSystemMetrics(ctx context.Context) (*zte.OLTSystemMetrics, error)
+	GetPONPortMetrics(ctx context.Context) ([]*zte.PONPortMetrics, error)
+	GetAllONTMetrics(ctx context.Context) ([]*zte.ONTMetrics, error)
+}
+
+// NewZTEClient returns a ZTE OLT client configured for background polls.
+func NewZTEClient() OLTClient {
+	client := zte.NewZTEOLTClient(oltPollTimeout)
+	client.SetMaxONTs(oltPollMaxONTs)
+	return client
+}
+
+// ByDeviceType dispatches to the collector registered for the task's device
+// type, and to Default for every other type. Device types are
+// case-insensitive.
+type ByDeviceType struct {
+	Default Collector
+	Types   map[string]Collector
+}
+
+func (c *ByDeviceType) Collect(ctx context.Context, task commonModel.PollTask, groups Groups) Collection {
+	if collector, ok := c.Types[strings.ToLower(task.DeviceType)]; ok {
+		return collector.Collect(ctx, task, groups)
+	}
+	return c.Default.Collect(ctx, task, groups)
+}
+
+// OLTCollector reads system, PON port and ONT metrics from an OLT over SNMP
+// and pings for the round-trip time. Each PON port and ONT is written to the
+// sink as its own point.
+type OLTCollector struct {
+	Ping      PingFunc
+	NewClient func() OLTClient
+}
+
+func (c *OLTCollector) Collect(ctx context.Context, task commonModel.PollTask, groups Groups) Collection {
+	snmp := groups.System || groups.PONPorts || groups.ONTs
+	if !snmp && !groups.Reachability {
+		return Collection{Err: noGroupsError(task.DeviceType, task.Protocol)}
+	}
+
+	var result Collection
+	if snmp {
+		result = c.collectSNMP(ctx, task, groups)
+	}
+
+	if groups.Reachability {
+		// The ping decides success only when nothing is read over SNMP
+		var reachable bool
+		result.RTT, reachable = c.Ping(task.IPAddress)
+		if !snmp {
+			result.Success = reachable
+		}
+	}
+	return result
+}
+
+// collectSNMP reads the enabled SNMP groups in one session. The poll
+// succeeds when any group was read; partial results are kept and their
+// errors logged.
+func (c *OLTCollector) collectSNMP(ctx context.Context, task commonModel.PollTask, groups Groups) Collection {
+	var result Collection
+
+	client := c.NewClient()
+	device := &devicemodel.Device{
+		ID:          task.DeviceID,
+		IPAddress:   task.IPAddress,
+		Credentials: &devicemodel.DeviceCredentials{SNMPCommunity: task.SNMPCommunity},
+	}
+	if err := client.Connect(ctx, device); err != nil {
+		log.Printf("Error connecting to OLT %s: %v", task.DeviceID, err)
+		return result
+	}
+	defer client.Disconnect()
+
+	result.Values = make(map[string]interface{})
+
+	if groups.System {
+		system, err := client.GetSystemMetrics(ctx)
+		logOLTError(task.DeviceID, "system metrics", err)
+		if system != nil {
+			result.Success = true
+			fields := oltSystemFields(system)
+			result.Points = append(result.Points, Point{
+				Measurement: "olt_system",
+				Fields:      fields,
+				Time:        system.Timestamp,
+			})
+			for k, v := range fields {
+				result.Values[k] = v
+			}
+		}
+	}
+
+	if groups.PONPorts {
+		ports, err := client.GetPONPortMetrics(ctx)
+		logOLTError(task.DeviceID, "PON port metrics", err)
+		if ports != nil {
+			result.Success = true
+			down := 0
+			for _, p := range ports {
+				if p.AdminStatus == zte.PONPortStatusUp && p.OperStatus != zte.PONPortStatusUp {
+					down++
+				}
+				result.Points = append(result.Points, Point{
+					Measurement: "olt_pon_port",
+					Tags:        map[string]string{"pon_port_index": strconv.Itoa(p.PortIndex)},
+					Fields:      ponPortFields(p),
+					Time:        p.Timestamp,
+				})
+			}
+			result.Values["pon_ports_down"] = down
+		}
+	}
+
+	if groups.ONTs {
+		onts, err := client.GetAllONTMetrics(ctx)
+		logOLTError(task.DeviceID, "ONT metrics", err)
+		if onts != nil {
+			result.Success = true
+			online := 0
+			for _, o := range onts {
+				if o.OperStatus.IsOnline() {
+					online++
+				}
+				result.Points = append(result.Points, Point{
+					Measurement: "olt_ont",
+					Tags: map[string]string{
+						"pon_port_index": strconv.Itoa(o.PONPortIndex),
+						"ont_index":      strconv.Itoa(o.ONTIndex),
+						"serial_number":  o.SerialNumber,
+					},
+					Fields: ontFields(o),
+					Time:   o.Timestamp,
+				})
+			}
+			result.Values["onts_online"] = online
+			result.Values["onts_offline"] = len(onts) - online
+		}
+	}
+
+	return result
+}
+
+// logOLTError logs a failed read, or a partial one whose result is still
+// written.
+func logOLTError(deviceID, what string, err error) {
+	if err == nil {
+		return
+	}
+	var partial *zte.PartialError
+	if errors.As(err, &partial) || errors.Is(err, zte.ErrONTLimitReached) {
+		log.Printf("WARNING: partial %s from OLT %s: %v", what, deviceID, err)
+		return
+	}
+	log.Printf("Error reading %s from OLT %s: %v", what, deviceID, err)
+}
+
+func oltSystemFields(m *zte.OLTSystemMetrics) map[string]interface{} {
+	return map[string]interface{}{
+		"uptime_seconds":       m.UptimeSeconds,
+		"cpu_usage_percent":    m.CPUUsagePercent,
+		"memory_total_kb":      m.MemoryTotalKB,
+		"memory_used_kb":       m.MemoryUsedKB,
+		"memory_usage_percent": m.MemoryUsagePercent,
+		"temperature_celsius":  m.TemperatureCelsius,
+	}
+}
+
+func ponPortFields(p *zte.PONPortMetrics) map[string]interface{} {
+	fields := map[string]interface{}{
+		"admin_status": p.AdminStatus.String(),
+		"oper_status":  p.OperStatus.String(),
+		"ont_count":    p.ONTCount,
+	}
+	setPower(fields, "tx_power_dbm", p.TxPowerDBm)
+	setPower(fields, "rx_power_dbm", p.RxPowerDBm)
+	return fields
+}
+
+func ontFields(o *zte.ONTMetrics) map[string]interface{} {
+	fields := map[string]interface{}{
+		"oper_status":     o.OperStatus.String(),
+		"online":          o.OperStatus.IsOnline(),
+		"distance_meters": o.DistanceMeters,
+	}
+	setPower(fields, "rx_power_dbm", o.RxPowerDBm)
+	setPower(fields, "tx_power_dbm", o.TxPowerDBm)
+	return fields
+}
+
+// setPower sets fields[key] to an optical power reading, leaving it out when
+// the reading is unusable.
+func setPower(fields map[string]interface{}, key string, dbm float64) {
+	if v := zte.NormalizePowerDBm(dbm); v != nil {
+		fields[key] = *v
+	}
+}
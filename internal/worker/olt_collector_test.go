@@ -0,0 +1,240 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	devicemodel "github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/worker"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
+)
+
+// fakeOLTClient returns canned metrics and records how it was used.
+type fakeOLTClient struct {
+	connectErr error
+	system     *zte.OLTSystemMetrics
+	systemErr  error
+	ports      []*zte.PONPortMetrics
+	onts       []*zte.ONTMetrics
+
+	connected    *devicemodel.Device
+	disconnected bool
+	calls        []string
+}
+
+func (f *fakeOLTClient) Connect(ctx context.Context, device *devicemodel.Device) error {
+	f.connected = device
+	return f.connectErr
+}
+
+func (f *fakeOLTClient) Disconnect() error {
+	f.disconnected = true
+	return nil
+}
+
+func (f *fakeOLTClient) GetSystemMetrics(ctx context.Context) (*zte.OLTSystemMetrics, error) {
+	f.calls = append(f.calls, "system")
+	return f.system, f.systemErr
+}
+
+func (f *fakeOLTClient) GetPONPortMetrics(ctx context.Context) ([]*zte.PONPortMetrics, error) {
+	f.calls = append(f.calls, "pon_ports")
+	return f.ports, nil
+}
+
+func (f *fakeOLTClient) GetAllONTMetrics(ctx context.Context) ([]*zte.ONTMetrics, error) {
+	f.calls = append(f.calls, "onts")
+	return f.onts, nil
+}
+
+// taggedSink records every point with its tags.
+type taggedSink struct {
+	points []taggedPoint
+}
+
+type taggedPoint struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]interface{}
+	ts          time.Time
+}
+
+func (f *taggedSink) Write(ctx context.Context, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	f.points = append(f.points, taggedPoint{measurement: measurement, tags: tags, fields: fields, ts: ts})
+	return nil
+}
+
+func (f *taggedSink) Close() error { return nil }
+
+func (f *taggedSink) measurements() []string {
+	var out []string
+	for _, p := range f.points {
+		out = append(out, p.measurement)
+	}
+	return out
+}
+
+var oltCollectedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newFakeOLT() *fakeOLTClient {
+	return &fakeOLTClient{
+		system: &zte.OLTSystemMetrics{
+			Timestamp:          oltCollectedAt,
+			UptimeSeconds:      86400,
+			CPUUsagePercent:    35,
+			MemoryUsagePercent: 60,
+			TemperatureCelsius: 48,
+		},
+		ports: []*zte.PONPortMetrics{
+			{Timestamp: oltCollectedAt, PortIndex: 268501248, AdminStatus: zte.PONPortStatusUp, OperStatus: zte.PONPortStatusUp, TxPowerDBm: 3.2, RxPowerDBm: math.NaN(), ONTCount: 2},
+			{Timestamp: oltCollectedAt, PortIndex: 268501504, AdminStatus: zte.PONPortStatusUp, OperStatus: zte.PONPortStatusDown},
+		},
+		onts: []*zte.ONTMetrics{
+			{Timestamp: oltCollectedAt, PONPortIndex: 268501248, ONTIndex: 1, SerialNumber: "ZTEG12345678", OperStatus: zte.ONTStatusWorking, RxPowerDBm: -21.5, DistanceMeters: 1200},
+			{Timestamp: oltCollectedAt, PONPortIndex: 268501248, ONTIndex: 2, SerialNumber: "ZTEG87654321", OperStatus: zte.ONTStatusLOS},
+		},
+	}
+}
+
+func newOLTWorker(client *fakeOLTClient, ms *taggedSink, cfg config.WorkerConfig, probes *fakeProbes) (*worker.Worker, *fakePublisher) {
+	publisher := &fakePublisher{}
+	collectors := map[string]worker.Collector{
+		"snmp": &worker.ByDeviceType{
+			Default: &worker.PingCollector{Ping: probes.ping},
+			Types: map[string]worker.Collector{
+				"olt": &worker.OLTCollector{Ping: probes.ping, NewClient: func() worker.OLTClient { return client }},
+			},
+		},
+	}
+	return worker.NewWorkerWithCollectors(nil, publisher, ms, cfg, collectors), publisher
+}
+
+var oltTask = commonModel.PollTask{
+	DeviceID:      "olt-1",
+	IPAddress:     "10.0.0.1",
+	DeviceType:    "olt",
+	Protocol:      "snmp",
+	SNMPCommunity: "s3cret",
+}
+
+func TestProcessTask_OLTWritesSystemPONAndONTPoints(t *testing.T) {
+	client := newFakeOLT()
+	ms := &taggedSink{}
+	probes := &fakeProbes{}
+	w, publisher := newOLTWorker(client, ms, config.WorkerConfig{}, probes)
+
+	w.ProcessTask(context.Background(), oltTask)
+
+	require.NotNil(t, client.connected)
+	assert.Equal(t, "10.0.0.1", client.connected.IPAddress)
+	assert.Equal(t, "s3cret", client.connected.Credentials.SNMPCommunity)
+	assert.True(t, client.disconnected)
+	assert.Equal(t, []string{"10.0.0.1"}, probes.pinged)
+
+	assert.Equal(t, []string{"device_poll", "olt_system", "olt_pon_port", "olt_pon_port", "olt_ont", "olt_ont"}, ms.measurements())
+	for _, p := range ms.points {
+		assert.Equal(t, "olt-1", p.tags["device_id"], p.measurement)
+		assert.Equal(t, "olt", p.tags["device_type"], p.measurement)
+	}
+
+	system := ms.points[1]
+	assert.Equal(t, oltCollectedAt, system.ts)
+	assert.Equal(t, 35.0, system.fields["cpu_usage_percent"])
+
+	port := ms.points[2]
+	assert.Equal(t, "268501248", port.tags["pon_port_index"])
+	assert.Equal(t, 3.2, port.fields["tx_power_dbm"])
+	assert.NotContains(t, port.fields, "rx_power_dbm", "unusable readings are not written")
+
+	ont := ms.points[4]
+	assert.Equal(t, "ZTEG12345678", ont.tags["serial_number"])
+	assert.Equal(t, "1", ont.tags["ont_index"])
+	assert.Equal(t, true, ont.fields["online"])
+	assert.Equal(t, -21.5, ont.fields["rx_power_dbm"])
+
+	require.Len(t, publisher.metrics, 1)
+	values := publisher.metrics[0].Values
+	assert.Equal(t, true, values["success"])
+	assert.Equal(t, 48.0, values["temperature_celsius"])
+	assert.Equal(t, 1, values["pon_ports_down"])
+	assert.Equal(t, 1, values["onts_online"])
+	assert.Equal(t, 1, values["onts_offline"])
+}
+
+func TestProcessTask_OLTProfileRestrictsGroups(t *testing.T) {
+	client := newFakeOLT()
+	ms := &taggedSink{}
+	probes := &fakeProbes{}
+	cfg := config.WorkerConfig{Profiles: map[string][]string{"olt": {"system", "pon_ports"}}}
+	w, _ := newOLTWorker(client, ms, cfg, probes)
+
+	w.ProcessTask(context.Background(), oltTask)
+
+	assert.Equal(t, []string{"system", "pon_ports"}, client.calls)
+	assert.Empty(t, probes.pinged)
+	assert.NotContains(t, ms.measurements(), "olt_ont")
+}
+
+func TestProcessTask_OLTPingOnlySkipsSNMP(t *testing.T) {
+	client := newFakeOLT()
+	ms := &taggedSink{}
+	probes := &fakeProbes{}
+	cfg := config.WorkerConfig{Profiles: map[string][]string{"olt": {"reachability"}}}
+	w, publisher := newOLTWorker(client, ms, cfg, probes)
+
+	w.ProcessTask(context.Background(), oltTask)
+
+	assert.Nil(t, client.connected)
+	assert.Equal(t, []string{"10.0.0.1"}, probes.pinged)
+	assert.Equal(t, []string{"device_poll"}, ms.measurements())
+	assert.Equal(t, true, publisher.metrics[0].Values["success"])
+}
+
+func TestProcessTask_OLTConnectFailure(t *testing.T) {
+	client := newFakeOLT()
+	client.connectErr = errors.New("dial udp: no route to host")
+	ms := &taggedSink{}
+	w, publisher := newOLTWorker(client, ms, config.WorkerConfig{}, &fakeProbes{})
+
+	w.ProcessTask(context.Background(), oltTask)
+
+	assert.Empty(t, client.calls)
+	assert.Equal(t, []string{"device_poll"}, ms.measurements())
+	assert.Equal(t, false, publisher.metrics[0].Values["success"])
+}
+
+func TestProcessTask_OLTKeepsPartialResults(t *testing.T) {
+	client := newFakeOLT()
+	client.system = nil
+	client.systemErr = errors.New("request timeout")
+	ms := &taggedSink{}
+	w, publisher := newOLTWorker(client, ms, config.WorkerConfig{}, &fakeProbes{})
+
+	w.ProcessTask(context.Background(), oltTask)
+
+	assert.NotContains(t, ms.measurements(), "olt_system")
+	assert.Contains(t, ms.measurements(), "olt_pon_port")
+	assert.Equal(t, true, publisher.metrics[0].Values["success"])
+}
+
+func TestProcessTask_SNMPNonOLTOnlyPings(t *testing.T) {
+	client := newFakeOLT()
+	ms := &taggedSink{}
+	probes := &fakeProbes{}
+	w, _ := newOLTWorker(client, ms, config.WorkerConfig{}, probes)
+
+	task := oltTask
+	task.DeviceType = "switch"
+	w.ProcessTask(context.Background(), task)
+
+	assert.Nil(t, client.connected)
+	assert.Equal(t, []string{"10.0.0.1"}, probes.pinged)
+	assert.Equal(t, []string{"device_poll"}, ms.measurements())
+}
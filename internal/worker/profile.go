@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"log"
+	"strings"
+)
+
+// Metric groups a worker can collect for a device.
+const (
+	// GroupReachability is the ping round-trip time.
+	GroupReachability = "reachability"
+	// GroupSystem is CPU, memory and uptime, read over the device's protocol.
+	GroupSystem = "system"
+	// GroupPONPorts is the status and optical power of each OLT PON port.
+	GroupPONPorts = "pon_ports"
+	// GroupONTs is the status, optical power and distance of each ONT on an
+	// OLT.
+	GroupONTs = "onts"
+	// GroupInterfaces is the traffic and error counters of each interface,
+	// read over SNMP IF-MIB.
+	GroupInterfaces = "interfaces"
+)
+
+var knownGroups = map[string]bool{
+	GroupReachability: true,
+	GroupSystem:       true,
+	GroupPONPorts:     true,
+	GroupONTs:         true,
+	GroupInterfaces:   true,
+}
+
+// CollectionProfiles maps a device type to the metric groups collected for
+// it. Device types without a profile get every group.
+type CollectionProfiles map[string][]string
+
+// NewCollectionProfiles builds profiles from worker.profiles config. Device
+// types and group names are case-insensitive; unknown groups are logged and
+// ignored.
+func NewCollectionProfiles(cfg map[string][]string) CollectionProfiles {
+	profiles := make(CollectionProfiles, len(cfg))
+	for deviceType, groups := range cfg {
+		enabled := make([]string, 0, len(groups))
+		for _, g := range groups {
+			g = strings.ToLower(strings.TrimSpace(g))
+			if !knownGroups[g] {
+				log.Printf("Worker config: ignoring unknown metric group %q for device type %q", g, deviceType)
+				continue
+			}
+			enabled = append(enabled, g)
+		}
+		profiles[strings.ToLower(deviceType)] = enabled
+	}
+	return profiles
+}
+
+// Collects reports whether group is collected for deviceType.
+func (p CollectionProfiles) Collects(deviceType, group string) bool {
+	groups, ok := p[strings.ToLower(deviceType)]
+	if !ok {
+		return true
+	}
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
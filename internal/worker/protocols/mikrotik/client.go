@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/go-routeros/routeros"
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
 	"github.com/yourorg/nms-go/internal/device/model"
 )
 
@@ -43,6 +45,15 @@ type InterfaceMetrics struct {
 	DropsIn       uint64
 	DropsOut      uint64
 	Speed         string // 100Mbps, 1Gbps, etc
+	// CounterBits is the width of BytesIn and BytesOut: 32 for counters that
+	// wrap at 2^32 (SNMP ifInOctets), 0 or 64 for 64-bit counters such as the
+	// RouterOS API's rx-byte.
+	CounterBits int
+	// RxBps and TxBps are the traffic in bits per second since the previous
+	// sample of the interface, filled in by the monitoring pipeline; zero
+	// until there is one.
+	RxBps float64
+	TxBps float64
 }
 
 // MikrotikClient implements protocol.DeviceProtocol for Mikrotik devices
@@ -59,7 +70,8 @@ func NewMikrotikClient(timeout time.Duration) *MikrotikClient {
 	}
 }
 
-// Connect establishes connection to Mikrotik device
+// Connect establishes connection to Mikrotik device. Dial failures are
+// returned as a *ConnectError classifying the cause.
 func (m *MikrotikClient) Connect(ctx context.Context, device *model.Device) error {
 	m.device = device
 
@@ -77,10 +89,16 @@ func (m *MikrotikClient) Connect(ctx context.Context, device *model.Device) erro
 	// Implement simple timeout wrapper if needed, or just use Dial for now
 	// The previous code utilized DialTimeout which implies it existed or was expected.
 	// Since it doesn't exist, we revert to Dial.
-	client, err := routeros.Dial(address, device.Credentials.Username,
-		device.Credentials.PasswordEncrypted)
+	password, err := crypto.Decrypt(device.Credentials.PasswordEncrypted)
 	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+		return fmt.Errorf("failed to decrypt device credentials: %w", err)
+	}
+
+	start := time.Now()
+	client, err := routeros.Dial(address, device.Credentials.Username, password)
+	telemetry.ObserveConnect(string(model.ProtocolMikrotikAPI), start, err)
+	if err != nil {
+		return &ConnectError{Address: address, Kind: ClassifyConnectError(err), Err: err}
 	}
 
 	m.client = client
@@ -97,30 +115,51 @@ func (m *MikrotikClient) Disconnect() error {
 
 // ExecuteCommand executes a RouterOS command
 func (m *MikrotikClient) ExecuteCommand(ctx context.Context, command string) (string, error) {
+	sentences, err := m.RunCommand(ctx, command)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatSentences(sentences), nil
+}
+
+// RunCommand executes a RouterOS command and returns each reply sentence as
+// its attribute map, in reply order.
+func (m *MikrotikClient) RunCommand(ctx context.Context, command string) ([]map[string]string, error) {
 	if m.client == nil {
-		return "", fmt.Errorf("not connected")
+		return nil, fmt.Errorf("not connected")
 	}
 
 	// Split command into parts (command + arguments)
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
-		return "", fmt.Errorf("empty command")
+		return nil, fmt.Errorf("empty command")
 	}
 
 	reply, err := m.client.Run(parts...)
 	if err != nil {
-		return "", fmt.Errorf("command execution failed: %w", err)
+		return nil, fmt.Errorf("command execution failed: %w", err)
 	}
 
-	// Convert reply to string representation
-	result := ""
+	sentences := make([]map[string]string, 0, len(reply.Re))
 	for _, sentence := range reply.Re {
-		for key, value := range sentence.Map {
+		sentences = append(sentences, sentence.Map)
+	}
+
+	return sentences, nil
+}
+
+// FormatSentences renders reply sentences as the newline-joined "key: value"
+// text returned by ExecuteCommand.
+func FormatSentences(sentences []map[string]string) string {
+	result := ""
+	for _, sentence := range sentences {
+		for key, value := range sentence {
 			result += fmt.Sprintf("%s: %s\n", key, value)
 		}
 	}
 
-	return result, nil
+	return result
 }
 
 // GetSystemMetrics retrieves system-level metrics
@@ -450,7 +489,7 @@ func (m *MikrotikClient) ValidateConnection(ctx context.Context, device *model.D
 	if err := m.Connect(ctx, device); err != nil {
 		return err
 	}
-	defer m.Disconnect()
+	defer telemetry.Disconnect(m, string(model.ProtocolMikrotikAPI), device.IPAddress)
 
 	// Try to get system identity as a validation
 	reply, err := m.client.Run("/system/identity/print")
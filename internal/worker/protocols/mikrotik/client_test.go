@@ -1,9 +1,13 @@
 package mikrotik_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/yourorg/nms-go/internal/common/crypto"
+	"github.com/yourorg/nms-go/internal/device/model"
 	mikrotik "github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
 )
 
@@ -40,3 +44,25 @@ func TestParseRouterOSUptime(t *testing.T) {
 		})
 	}
 }
+
+func TestConnect_EncryptedPasswordWithoutKey(t *testing.T) {
+	c, err := crypto.NewCipherFromKey("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := c.Encrypt("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No process-wide key: the stored password cannot be decrypted, so the
+	// device is not dialed with the ciphertext.
+	client := mikrotik.NewMikrotikClient(time.Second)
+	err = client.Connect(context.Background(), &model.Device{
+		IPAddress:   "192.0.2.1",
+		Credentials: &model.DeviceCredentials{Username: "admin", PasswordEncrypted: stored},
+	})
+	if !errors.Is(err, crypto.ErrNoKey) {
+		t.Fatalf("Connect() error = %v, want %v", err, crypto.ErrNoKey)
+	}
+}
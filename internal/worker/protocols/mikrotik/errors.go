@@ -0,0 +1,81 @@
+package mikrotik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/go-routeros/routeros"
+)
+
+// Connect failures, classified by cause. A *ConnectError matches one of them
+// with errors.Is.
+var (
+	// ErrAuth means the device rejected the login. Retrying with the same
+	// credentials will fail again and may lock the account.
+	ErrAuth = errors.New("authentication failed")
+
+	// ErrTimeout means the device did not answer in time.
+	ErrTimeout = errors.New("connection timed out")
+
+	// ErrRefused means nothing accepted the connection on the API port,
+	// e.g. because the API service is disabled.
+	ErrRefused = errors.New("connection refused")
+)
+
+// ConnectError is returned by Connect when the API session cannot be opened.
+type ConnectError struct {
+	Address string
+
+	// Kind is ErrAuth, ErrTimeout or ErrRefused, or nil when the cause is
+	// not recognized.
+	Kind error
+
+	Err error
+}
+
+func (e *ConnectError) Error() string {
+	if e.Kind == nil {
+		return fmt.Sprintf("failed to connect to %s: %v", e.Address, e.Err)
+	}
+	return fmt.Sprintf("failed to connect to %s: %v: %v", e.Address, e.Kind, e.Err)
+}
+
+func (e *ConnectError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// ClassifyConnectError returns ErrAuth, ErrTimeout or ErrRefused for an error
+// from dialing a RouterOS device, or nil when the cause is not recognized.
+func ClassifyConnectError(err error) error {
+	var deviceErr *routeros.DeviceError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &deviceErr):
+		// Dial only sends the login, so a !trap reply is the device
+		// rejecting the credentials.
+		return ErrAuth
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrRefused
+	case errors.Is(err, syscall.ETIMEDOUT), errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ErrTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	default:
+		return nil
+	}
+}
+
+// Retryable reports whether a failed Connect is worth retrying. Only
+// rejected logins are not; unclassified errors are assumed transient.
+func Retryable(err error) bool {
+	return !errors.Is(err, ErrAuth)
+}
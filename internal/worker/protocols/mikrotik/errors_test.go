@@ -0,0 +1,69 @@
+package mikrotik_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/go-routeros/routeros"
+	"github.com/go-routeros/routeros/proto"
+	"github.com/stretchr/testify/assert"
+	mikrotik "github.com/yourorg/nms-go/internal/worker/protocols/mikrotik"
+)
+
+func dialError(err error) error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: err}
+}
+
+func TestClassifyConnectError(t *testing.T) {
+	loginTrap := &routeros.DeviceError{Sentence: &proto.Sentence{
+		Word: "!trap",
+		Map:  map[string]string{"message": "invalid user name or password (6)"},
+	}}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"login rejected", loginTrap, mikrotik.ErrAuth},
+		{"login rejected, wrapped", fmt.Errorf("login: %w", loginTrap), mikrotik.ErrAuth},
+		{"port closed", dialError(os.NewSyscallError("connect", syscall.ECONNREFUSED)), mikrotik.ErrRefused},
+		{"connect timed out", dialError(os.NewSyscallError("connect", syscall.ETIMEDOUT)), mikrotik.ErrTimeout},
+		{"read deadline", dialError(os.ErrDeadlineExceeded), mikrotik.ErrTimeout},
+		{"context deadline", context.DeadlineExceeded, mikrotik.ErrTimeout},
+		{"dns timeout", dialError(&net.DNSError{Err: "i/o timeout", IsTimeout: true}), mikrotik.ErrTimeout},
+		{"connection reset", io.EOF, nil},
+		{"no error", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mikrotik.ClassifyConnectError(tt.err))
+		})
+	}
+}
+
+func TestConnectError_MatchesKindAndCause(t *testing.T) {
+	cause := dialError(os.NewSyscallError("connect", syscall.ECONNREFUSED))
+	err := error(&mikrotik.ConnectError{Address: "10.0.0.1:8728", Kind: mikrotik.ErrRefused, Err: cause})
+
+	assert.ErrorIs(t, err, mikrotik.ErrRefused)
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+	assert.NotErrorIs(t, err, mikrotik.ErrAuth)
+	assert.Equal(t, "failed to connect to 10.0.0.1:8728: connection refused: dial tcp: connect: connection refused", err.Error())
+
+	var connErr *mikrotik.ConnectError
+	assert.True(t, errors.As(fmt.Errorf("poll: %w", err), &connErr))
+}
+
+func TestRetryable(t *testing.T) {
+	assert.False(t, mikrotik.Retryable(&mikrotik.ConnectError{Address: "10.0.0.1:8728", Kind: mikrotik.ErrAuth, Err: errors.New("device error")}))
+	assert.True(t, mikrotik.Retryable(&mikrotik.ConnectError{Address: "10.0.0.1:8728", Kind: mikrotik.ErrTimeout, Err: os.ErrDeadlineExceeded}))
+	assert.True(t, mikrotik.Retryable(&mikrotik.ConnectError{Address: "10.0.0.1:8728", Err: io.EOF}), "unclassified errors are retried")
+}
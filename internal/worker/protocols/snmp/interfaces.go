@@ -0,0 +1,169 @@
+package snmp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// IF-MIB columns, all indexed by ifIndex. ifTable is mandatory; ifXTable
+// (names and 64-bit counters) is missing on some older agents.
+const (
+	OIDIfDescr      = ".1.3.6.1.2.1.2.2.1.2"
+	OIDIfOperStatus = ".1.3.6.1.2.1.2.2.1.8"
+	OIDIfInOctets   = ".1.3.6.1.2.1.2.2.1.10"
+	OIDIfInErrors   = ".1.3.6.1.2.1.2.2.1.14"
+	OIDIfOutOctets  = ".1.3.6.1.2.1.2.2.1.16"
+	OIDIfOutErrors  = ".1.3.6.1.2.1.2.2.1.20"
+
+	OIDIfName        = ".1.3.6.1.2.1.31.1.1.1.1"
+	OIDIfHCInOctets  = ".1.3.6.1.2.1.31.1.1.1.6"
+	OIDIfHCOutOctets = ".1.3.6.1.2.1.31.1.1.1.10"
+)
+
+// InterfaceMetrics holds the counters of one interface, joined with its name.
+type InterfaceMetrics struct {
+	Index int
+	// Name is ifName, falling back to ifDescr and then to "ifIndex <n>", so
+	// it is never empty.
+	Name      string
+	Descr     string
+	OperUp    bool
+	InOctets  uint64 // ifHCInOctets when available, else ifInOctets
+	OutOctets uint64 // ifHCOutOctets when available, else ifOutOctets
+	InErrors  uint64
+	OutErrors uint64
+	// CounterBits is the width of InOctets and OutOctets: 64 once the HC
+	// counters were read, else 32.
+	CounterBits int
+	Timestamp   time.Time
+}
+
+// GetInterfaceMetrics walks IF-MIB on a connected client and returns one
+// entry per ifIndex, ordered by index. Only a failed ifInOctets walk is an
+// error; other columns are left zero when they cannot be read.
+func GetInterfaceMetrics(ctx context.Context, client SNMPClient) ([]*InterfaceMetrics, error) {
+	byIndex := make(map[int]*InterfaceMetrics)
+	names := make(map[int]string)
+	now := time.Now()
+
+	get := func(index int) *InterfaceMetrics {
+		m, ok := byIndex[index]
+		if !ok {
+			m = &InterfaceMetrics{Index: index, Timestamp: now}
+			byIndex[index] = m
+		}
+		return m
+	}
+
+	columns := []struct {
+		oid      string
+		required bool
+		set      func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics)
+	}{
+		{OIDIfInOctets, true, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.InOctets, m.CounterBits = pduToUint64(pdu), 32
+		}},
+		{OIDIfOutOctets, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.OutOctets = pduToUint64(pdu)
+		}},
+		{OIDIfInErrors, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.InErrors = pduToUint64(pdu)
+		}},
+		{OIDIfOutErrors, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.OutErrors = pduToUint64(pdu)
+		}},
+		{OIDIfOperStatus, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.OperUp = pduToUint64(pdu) == 1 // up(1)
+		}},
+		{OIDIfDescr, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.Descr = pduToString(pdu)
+		}},
+		{OIDIfName, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			names[m.Index] = pduToString(pdu)
+		}},
+		// Walked last so the 64-bit counters replace the 32-bit ones, which
+		// wrap within minutes on fast links.
+		{OIDIfHCInOctets, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.InOctets, m.CounterBits = pduToUint64(pdu), 64
+		}},
+		{OIDIfHCOutOctets, false, func(pdu gosnmp.SnmpPDU, m *InterfaceMetrics) {
+			m.OutOctets = pduToUint64(pdu)
+		}},
+	}
+
+	for _, col := range columns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		col := col
+		err := client.Walk(col.oid, func(pdu gosnmp.SnmpPDU) error {
+			index, ok := ifIndex(pdu.Name, col.oid)
+			if !ok {
+				return nil
+			}
+			// Rows only come from ifTable; ifXTable just adds to them.
+			if _, known := byIndex[index]; !known && !col.required {
+				return nil
+			}
+			col.set(pdu, get(index))
+			return nil
+		})
+		if err != nil && col.required {
+			return nil, fmt.Errorf("failed to walk interface OID %s: %w", col.oid, err)
+		}
+	}
+
+	result := make([]*InterfaceMetrics, 0, len(byIndex))
+	for _, m := range byIndex {
+		m.Name = interfaceName(m, names[m.Index])
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Index < result[j].Index })
+
+	return result, nil
+}
+
+func interfaceName(m *InterfaceMetrics, ifName string) string {
+	if name := strings.TrimSpace(ifName); name != "" {
+		return name
+	}
+	if descr := strings.TrimSpace(m.Descr); descr != "" {
+		return descr
+	}
+	return "ifIndex " + strconv.Itoa(m.Index)
+}
+
+// ifIndex returns the single index following column in oid.
+func ifIndex(oid, column string) (int, bool) {
+	suffix, ok := strings.CutPrefix(strings.TrimPrefix(oid, "."), strings.TrimPrefix(column, ".")+".")
+	if !ok {
+		return 0, false
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+func pduToUint64(pdu gosnmp.SnmpPDU) uint64 {
+	return gosnmp.ToBigInt(pdu.Value).Uint64()
+}
+
+func pduToString(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,146 @@
+package snmp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
+)
+
+// tableClient serves walks from canned per-column rows and gets from canned
+// scalars.
+type tableClient struct {
+	columns map[string][]gosnmp.SnmpPDU
+	scalars []gosnmp.SnmpPDU
+	errs    map[string]error
+}
+
+func (c *tableClient) Connect(ctx context.Context, host, community string, version gosnmp.SnmpVersion, timeout time.Duration) error {
+	return nil
+}
+
+func (c *tableClient) Disconnect() error { return nil }
+
+func (c *tableClient) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	if c.scalars == nil {
+		return nil, errors.New("not implemented")
+	}
+	return &gosnmp.SnmpPacket{Variables: c.scalars}, nil
+}
+
+func (c *tableClient) GetBulk(oids []string, nonRepeaters uint8, maxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *tableClient) Walk(oid string, fn gosnmp.WalkFunc) error {
+	if err := c.errs[oid]; err != nil {
+		return err
+	}
+	for _, pdu := range c.columns[oid] {
+		if err := fn(pdu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func row(column string, index string, value interface{}) gosnmp.SnmpPDU {
+	return gosnmp.SnmpPDU{Name: column + "." + index, Value: value}
+}
+
+// ifTableFixture has three interfaces; ifXTable names only the first two and
+// has 64-bit counters for the first one.
+func ifTableFixture() *tableClient {
+	return &tableClient{columns: map[string][]gosnmp.SnmpPDU{
+		snmpclient.OIDIfDescr: {
+			row(snmpclient.OIDIfDescr, "1", []byte("GigabitEthernet0/1")),
+			row(snmpclient.OIDIfDescr, "2", []byte("GigabitEthernet0/2")),
+			row(snmpclient.OIDIfDescr, "10", []byte("Loopback0")),
+		},
+		snmpclient.OIDIfOperStatus: {
+			row(snmpclient.OIDIfOperStatus, "1", 1),
+			row(snmpclient.OIDIfOperStatus, "2", 2),
+			row(snmpclient.OIDIfOperStatus, "10", 1),
+		},
+		snmpclient.OIDIfInOctets: {
+			row(snmpclient.OIDIfInOctets, "1", uint(4000000000)),
+			row(snmpclient.OIDIfInOctets, "2", uint(200)),
+			row(snmpclient.OIDIfInOctets, "10", uint(0)),
+		},
+		snmpclient.OIDIfOutOctets: {
+			row(snmpclient.OIDIfOutOctets, "1", uint(100)),
+			row(snmpclient.OIDIfOutOctets, "2", uint(300)),
+			row(snmpclient.OIDIfOutOctets, "10", uint(0)),
+		},
+		snmpclient.OIDIfInErrors: {
+			row(snmpclient.OIDIfInErrors, "2", uint(7)),
+		},
+		snmpclient.OIDIfName: {
+			row(snmpclient.OIDIfName, "1", []byte("Gi0/1")),
+			row(snmpclient.OIDIfName, "2", []byte("Gi0/2")),
+			row(snmpclient.OIDIfName, "99", []byte("stale")),
+		},
+		snmpclient.OIDIfHCInOctets: {
+			row(snmpclient.OIDIfHCInOctets, "1", uint64(12000000000)),
+		},
+		snmpclient.OIDIfHCOutOctets: {
+			row(snmpclient.OIDIfHCOutOctets, "1", uint64(5000000000)),
+		},
+	}}
+}
+
+func TestGetInterfaceMetrics_JoinsNamesWithCounters(t *testing.T) {
+	ifaces, err := snmpclient.GetInterfaceMetrics(context.Background(), ifTableFixture())
+	require.NoError(t, err)
+	require.Len(t, ifaces, 3, "ifXTable rows without an ifTable row are ignored")
+
+	assert.Equal(t, 1, ifaces[0].Index)
+	assert.Equal(t, "Gi0/1", ifaces[0].Name)
+	assert.Equal(t, "GigabitEthernet0/1", ifaces[0].Descr)
+	assert.True(t, ifaces[0].OperUp)
+	assert.Equal(t, uint64(12000000000), ifaces[0].InOctets, "64-bit counter wins")
+	assert.Equal(t, uint64(5000000000), ifaces[0].OutOctets)
+	assert.Equal(t, 64, ifaces[0].CounterBits)
+
+	assert.Equal(t, 2, ifaces[1].Index)
+	assert.Equal(t, "Gi0/2", ifaces[1].Name)
+	assert.False(t, ifaces[1].OperUp)
+	assert.Equal(t, uint64(200), ifaces[1].InOctets)
+	assert.Equal(t, uint64(300), ifaces[1].OutOctets)
+	assert.Equal(t, uint64(7), ifaces[1].InErrors)
+	assert.Equal(t, 32, ifaces[1].CounterBits, "no HC counter for this row")
+
+	assert.Equal(t, 10, ifaces[2].Index)
+	assert.Equal(t, "Loopback0", ifaces[2].Name, "falls back to ifDescr without ifName")
+}
+
+func TestGetInterfaceMetrics_WithoutIfXTable(t *testing.T) {
+	client := ifTableFixture()
+	client.errs = map[string]error{
+		snmpclient.OIDIfName:        errors.New("no such object"),
+		snmpclient.OIDIfHCInOctets:  errors.New("no such object"),
+		snmpclient.OIDIfHCOutOctets: errors.New("no such object"),
+	}
+	delete(client.columns, snmpclient.OIDIfDescr)
+
+	ifaces, err := snmpclient.GetInterfaceMetrics(context.Background(), client)
+	require.NoError(t, err)
+	require.Len(t, ifaces, 3)
+
+	assert.Equal(t, "ifIndex 1", ifaces[0].Name)
+	assert.Equal(t, uint64(4000000000), ifaces[0].InOctets)
+}
+
+func TestGetInterfaceMetrics_CountersUnavailable(t *testing.T) {
+	client := ifTableFixture()
+	client.errs = map[string]error{snmpclient.OIDIfInOctets: errors.New("request timeout")}
+
+	_, err := snmpclient.GetInterfaceMetrics(context.Background(), client)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), snmpclient.OIDIfInOctets)
+}
@@ -5,11 +5,25 @@ package snmp
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
 )
 
+// protocolLabel is the protocol label used for connect telemetry.
+const protocolLabel = "snmp"
+
+// DefaultRetries is the number of times a request is resent after a timeout.
+const DefaultRetries = 2
+
+// DefaultMaxRepetitions is the GETBULK max-repetitions used by Walk. It is
+// well below gosnmp's default of 50 because underpowered agents drop
+// responses that carry too many varbinds.
+const DefaultMaxRepetitions uint32 = 10
+
 // SNMPClient defines the interface for SNMP operations.
 // Device-specific adapters depend on this interface, enabling easy mocking in tests.
 type SNMPClient interface {
@@ -31,36 +45,127 @@ type SNMPClient interface {
 	GetBulk(oids []string, nonRepeaters uint8, maxRepetitions uint32) (*gosnmp.SnmpPacket, error)
 }
 
+// RetrySetter is implemented by clients whose retry count can be changed
+// before Connect.
+type RetrySetter interface {
+	SetRetries(n int)
+}
+
+// MaxRepetitionsSetter is implemented by clients whose GETBULK
+// max-repetitions for walks can be changed before Connect.
+type MaxRepetitionsSetter interface {
+	SetMaxRepetitions(n uint32)
+}
+
+// SessionCloner is implemented by clients that can open another session to
+// the agent they are connected to. A session must not be used by several
+// goroutines at once, so concurrent requests each need their own.
+type SessionCloner interface {
+	// Clone connects a new session with the same settings. The caller must
+	// Disconnect it.
+	Clone() (SNMPClient, error)
+}
+
+// TableWalker is the part of *gosnmp.GoSNMP used for walks.
+type TableWalker interface {
+	BulkWalk(rootOid string, walkFn gosnmp.WalkFunc) error
+	Walk(rootOid string, walkFn gosnmp.WalkFunc) error
+}
+
 // GoSNMPClient is the production implementation of SNMPClient backed by gosnmp.
 type GoSNMPClient struct {
-	snmp *gosnmp.GoSNMP
+	snmp           *gosnmp.GoSNMP
+	walker         TableWalker
+	retries        int
+	maxRepetitions uint32
 }
 
 // NewGoSNMPClient creates a new GoSNMPClient with sensible defaults.
 func NewGoSNMPClient() *GoSNMPClient {
-	return &GoSNMPClient{}
+	return &GoSNMPClient{retries: DefaultRetries, maxRepetitions: DefaultMaxRepetitions}
+}
+
+// NewGoSNMPClientForTest creates a client whose walks go to the given walker
+// instead of a live session. Only Walk is usable.
+func NewGoSNMPClientForTest(walker TableWalker) *GoSNMPClient {
+	return &GoSNMPClient{walker: walker, retries: DefaultRetries, maxRepetitions: DefaultMaxRepetitions}
+}
+
+// SetRetries sets the retry count used by the next Connect.
+func (c *GoSNMPClient) SetRetries(n int) {
+	c.retries = n
+}
+
+// SetMaxRepetitions sets the GETBULK max-repetitions used by walks in the
+// next Connect. Lower it for agents that drop large responses.
+func (c *GoSNMPClient) SetMaxRepetitions(n uint32) {
+	c.maxRepetitions = n
+}
+
+// MaxRepetitions returns the GETBULK max-repetitions of the current session,
+// or 0 before Connect.
+func (c *GoSNMPClient) MaxRepetitions() uint32 {
+	if c.snmp == nil {
+		return 0
+	}
+	return c.snmp.MaxRepetitions
 }
 
 // Connect establishes an SNMP session.
-func (c *GoSNMPClient) Connect(ctx context.Context, host, community string, version gosnmp.SnmpVersion, timeout time.Duration) error {
+// The connect duration and outcome are recorded in the telemetry package.
+func (c *GoSNMPClient) Connect(ctx context.Context, host, community string, version gosnmp.SnmpVersion, timeout time.Duration) (err error) {
+	start := time.Now()
+	defer func() { telemetry.ObserveConnect(protocolLabel, start, err) }()
+
 	c.snmp = &gosnmp.GoSNMP{
 		Target:             host,
 		Port:               161,
 		Community:          community,
 		Version:            version,
 		Timeout:            timeout,
-		Retries:            2,
+		Retries:            c.retries,
 		ExponentialTimeout: true,
 		MaxOids:            gosnmp.MaxOids,
+		MaxRepetitions:     c.maxRepetitions,
 	}
 
 	if err := c.snmp.ConnectIPv4(); err != nil {
 		return fmt.Errorf("snmp connect to %s failed: %w", host, err)
 	}
+	c.walker = c.snmp
 
 	return nil
 }
 
+// Clone connects a new session to the same agent with the same settings.
+func (c *GoSNMPClient) Clone() (SNMPClient, error) {
+	if c.snmp == nil {
+		return nil, fmt.Errorf("snmp client not connected")
+	}
+
+	session := &gosnmp.GoSNMP{
+		Target:             c.snmp.Target,
+		Port:               c.snmp.Port,
+		Community:          c.snmp.Community,
+		Version:            c.snmp.Version,
+		Timeout:            c.snmp.Timeout,
+		Retries:            c.snmp.Retries,
+		ExponentialTimeout: c.snmp.ExponentialTimeout,
+		MaxOids:            c.snmp.MaxOids,
+		MaxRepetitions:     c.snmp.MaxRepetitions,
+	}
+	if err := session.ConnectIPv4(); err != nil {
+		return nil, fmt.Errorf("snmp connect to %s failed: %w", session.Target, err)
+	}
+
+	return &GoSNMPClient{
+		snmp:           session,
+		walker:         session,
+		retries:        c.retries,
+		maxRepetitions: c.maxRepetitions,
+	}, nil
+}
+
 // Disconnect closes the SNMP session.
 func (c *GoSNMPClient) Disconnect() error {
 	if c.snmp != nil && c.snmp.Conn != nil {
@@ -85,18 +190,44 @@ func (c *GoSNMPClient) Get(oids []string) (*gosnmp.SnmpPacket, error) {
 }
 
 // Walk performs an SNMP walk starting from the given OID.
+//
+// Walks use GETBULK. Some ZTE firmware answers GETBULK with a repeated OID,
+// which gosnmp rejects as "OID not increasing"; the walk is then retried with
+// GETNEXT, skipping rows fn has already been given.
 func (c *GoSNMPClient) Walk(oid string, fn gosnmp.WalkFunc) error {
-	if c.snmp == nil {
+	if c.walker == nil {
 		return fmt.Errorf("snmp client not connected")
 	}
 
-	if err := c.snmp.BulkWalk(oid, fn); err != nil {
+	delivered := make(map[string]struct{})
+	err := c.walker.BulkWalk(oid, func(pdu gosnmp.SnmpPDU) error {
+		delivered[pdu.Name] = struct{}{}
+		return fn(pdu)
+	})
+
+	if isOIDNotIncreasing(err) {
+		log.Printf("snmp walk on %s: %v; falling back to GETNEXT", oid, err)
+		err = c.walker.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+			if _, ok := delivered[pdu.Name]; ok {
+				return nil
+			}
+			return fn(pdu)
+		})
+	}
+
+	if err != nil {
 		return fmt.Errorf("snmp walk on %s failed: %w", oid, err)
 	}
 
 	return nil
 }
 
+// isOIDNotIncreasing reports whether err is gosnmp's walk loop guard, which
+// has no sentinel error to match against.
+func isOIDNotIncreasing(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "OID not increasing")
+}
+
 // GetBulk performs an SNMP GETBULK request.
 func (c *GoSNMPClient) GetBulk(oids []string, nonRepeaters uint8, maxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
 	if c.snmp == nil {
@@ -0,0 +1,177 @@
+package snmp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
+)
+
+// histogramCount returns the number of observations recorded for the given protocol.
+func histogramCount(t *testing.T, protocol string) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	observer := telemetry.ConnectDuration.WithLabelValues(protocol)
+	require.NoError(t, observer.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestConnect_ObservesConnectMetrics(t *testing.T) {
+	before := histogramCount(t, "snmp")
+	successBefore := testutil.ToFloat64(telemetry.ConnectTotal.WithLabelValues("snmp", telemetry.ResultSuccess))
+
+	// UDP "connect" only binds a local socket, so no agent is needed on the other end.
+	client := snmpclient.NewGoSNMPClient()
+	err := client.Connect(context.Background(), "127.0.0.1", "public", gosnmp.Version2c, time.Second)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	assert.Equal(t, before+1, histogramCount(t, "snmp"))
+	assert.Equal(t, successBefore+1, testutil.ToFloat64(telemetry.ConnectTotal.WithLabelValues("snmp", telemetry.ResultSuccess)))
+}
+
+func TestConnect_PassesMaxRepetitions(t *testing.T) {
+	client := snmpclient.NewGoSNMPClient()
+	client.SetMaxRepetitions(4)
+
+	err := client.Connect(context.Background(), "127.0.0.1", "public", gosnmp.Version2c, time.Second)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	assert.Equal(t, uint32(4), client.MaxRepetitions(), "BulkWalk sends the session's MaxRepetitions")
+}
+
+func TestConnect_DefaultMaxRepetitions(t *testing.T) {
+	client := snmpclient.NewGoSNMPClient()
+
+	err := client.Connect(context.Background(), "127.0.0.1", "public", gosnmp.Version2c, time.Second)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	assert.Equal(t, snmpclient.DefaultMaxRepetitions, client.MaxRepetitions())
+}
+
+func TestClone_CopiesSessionSettings(t *testing.T) {
+	client := snmpclient.NewGoSNMPClient()
+	client.SetMaxRepetitions(4)
+
+	err := client.Connect(context.Background(), "127.0.0.1", "public", gosnmp.Version2c, time.Second)
+	require.NoError(t, err)
+	defer client.Disconnect()
+
+	clone, err := client.Clone()
+	require.NoError(t, err)
+	defer clone.Disconnect()
+
+	require.IsType(t, &snmpclient.GoSNMPClient{}, clone)
+	assert.Equal(t, uint32(4), clone.(*snmpclient.GoSNMPClient).MaxRepetitions())
+}
+
+func TestClone_RequiresConnectedSession(t *testing.T) {
+	_, err := snmpclient.NewGoSNMPClient().Clone()
+	assert.Error(t, err)
+}
+
+func TestConnect_FailureIsCounted(t *testing.T) {
+	failureBefore := testutil.ToFloat64(telemetry.ConnectTotal.WithLabelValues("snmp", telemetry.ResultFailure))
+
+	client := snmpclient.NewGoSNMPClient()
+	err := client.Connect(context.Background(), "invalid host name", "public", gosnmp.Version2c, time.Second)
+	require.Error(t, err)
+
+	assert.Equal(t, failureBefore+1, testutil.ToFloat64(telemetry.ConnectTotal.WithLabelValues("snmp", telemetry.ResultFailure)))
+}
+
+// fakeWalker replays canned BulkWalk and Walk (GETNEXT) results.
+type fakeWalker struct {
+	bulkPDUs []gosnmp.SnmpPDU
+	bulkErr  error
+	nextPDUs []gosnmp.SnmpPDU
+	nextErr  error
+
+	nextWalks int
+}
+
+func (f *fakeWalker) BulkWalk(_ string, fn gosnmp.WalkFunc) error {
+	for _, pdu := range f.bulkPDUs {
+		if err := fn(pdu); err != nil {
+			return err
+		}
+	}
+	return f.bulkErr
+}
+
+func (f *fakeWalker) Walk(_ string, fn gosnmp.WalkFunc) error {
+	f.nextWalks++
+	for _, pdu := range f.nextPDUs {
+		if err := fn(pdu); err != nil {
+			return err
+		}
+	}
+	return f.nextErr
+}
+
+func walkNames(t *testing.T, client *snmpclient.GoSNMPClient) ([]string, error) {
+	t.Helper()
+
+	var names []string
+	err := client.Walk("1.3.6.1.2.1.2.2.1.2", func(pdu gosnmp.SnmpPDU) error {
+		names = append(names, pdu.Name)
+		return nil
+	})
+	return names, err
+}
+
+func TestWalk_FallsBackToGetNextWhenOIDNotIncreasing(t *testing.T) {
+	rows := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1"},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.2"},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.3"},
+	}
+	walker := &fakeWalker{
+		bulkPDUs: rows[:1],
+		bulkErr:  errors.New("OID not increasing: .1.3.6.1.2.1.2.2.1.2.1"),
+		nextPDUs: rows,
+	}
+
+	names, err := walkNames(t, snmpclient.NewGoSNMPClientForTest(walker))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, walker.nextWalks)
+	assert.Equal(t, []string{
+		".1.3.6.1.2.1.2.2.1.2.1",
+		".1.3.6.1.2.1.2.2.1.2.2",
+		".1.3.6.1.2.1.2.2.1.2.3",
+	}, names, "rows seen before the fallback are not repeated")
+}
+
+func TestWalk_OtherErrorsAreNotRetried(t *testing.T) {
+	walker := &fakeWalker{bulkErr: errors.New("request timeout (after 2 retries)")}
+
+	_, err := walkNames(t, snmpclient.NewGoSNMPClientForTest(walker))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request timeout")
+	assert.Zero(t, walker.nextWalks)
+}
+
+func TestWalk_FallbackFailureIsReturned(t *testing.T) {
+	walker := &fakeWalker{
+		bulkErr: errors.New("OID not increasing: .1.3.6.1.2.1.2.2.1.2.1"),
+		nextErr: errors.New("request timeout"),
+	}
+
+	_, err := walkNames(t, snmpclient.NewGoSNMPClientForTest(walker))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request timeout")
+	assert.Equal(t, 1, walker.nextWalks)
+}
@@ -0,0 +1,58 @@
+package snmp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// MIB-II and HOST-RESOURCES-MIB objects read for system metrics.
+const (
+	OIDSysUpTime       = ".1.3.6.1.2.1.1.3.0"
+	OIDHrProcessorLoad = ".1.3.6.1.2.1.25.3.3.1.2"
+)
+
+// SystemMetrics holds the system values every SNMP agent can report.
+type SystemMetrics struct {
+	UptimeSeconds int64
+	// CPUUsagePercent is the mean hrProcessorLoad over all processors; it is
+	// nil when the agent does not implement HOST-RESOURCES-MIB.
+	CPUUsagePercent *float64
+	Timestamp       time.Time
+}
+
+// GetSystemMetrics reads sysUpTime and the processor load on a connected
+// client. Only a failed sysUpTime read is an error.
+func GetSystemMetrics(ctx context.Context, client SNMPClient) (*SystemMetrics, error) {
+	packet, err := client.Get([]string{OIDSysUpTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sysUpTime: %w", err)
+	}
+
+	m := &SystemMetrics{Timestamp: time.Now()}
+	for _, pdu := range packet.Variables {
+		if strings.TrimPrefix(pdu.Name, ".") == strings.TrimPrefix(OIDSysUpTime, ".") {
+			m.UptimeSeconds = int64(pduToUint64(pdu)) / 100 // TimeTicks are hundredths of a second
+		}
+	}
+
+	var total uint64
+	var count int
+	err = client.Walk(OIDHrProcessorLoad, func(pdu gosnmp.SnmpPDU) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		total += pduToUint64(pdu)
+		count++
+		return nil
+	})
+	if err == nil && count > 0 {
+		cpu := float64(total) / float64(count)
+		m.CPUUsagePercent = &cpu
+	}
+
+	return m, nil
+}
@@ -0,0 +1,48 @@
+package snmp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
+)
+
+func TestGetSystemMetrics(t *testing.T) {
+	client := &tableClient{
+		scalars: []gosnmp.SnmpPDU{{Name: snmpclient.OIDSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(8640000)}},
+		columns: map[string][]gosnmp.SnmpPDU{
+			snmpclient.OIDHrProcessorLoad: {
+				row(snmpclient.OIDHrProcessorLoad, "196608", 20),
+				row(snmpclient.OIDHrProcessorLoad, "196609", 40),
+			},
+		},
+	}
+
+	m, err := snmpclient.GetSystemMetrics(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, int64(86400), m.UptimeSeconds)
+	require.NotNil(t, m.CPUUsagePercent)
+	assert.Equal(t, 30.0, *m.CPUUsagePercent)
+	assert.False(t, m.Timestamp.IsZero())
+}
+
+func TestGetSystemMetrics_WithoutHostResources(t *testing.T) {
+	client := &tableClient{
+		scalars: []gosnmp.SnmpPDU{{Name: snmpclient.OIDSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)}},
+		errs:    map[string]error{snmpclient.OIDHrProcessorLoad: errors.New("no such object")},
+	}
+
+	m, err := snmpclient.GetSystemMetrics(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), m.UptimeSeconds)
+	assert.Nil(t, m.CPUUsagePercent, "CPU is left out rather than reported as idle")
+}
+
+func TestGetSystemMetrics_UptimeUnavailable(t *testing.T) {
+	_, err := snmpclient.GetSystemMetrics(context.Background(), &tableClient{})
+	assert.ErrorContains(t, err, "sysUpTime")
+}
@@ -0,0 +1,114 @@
+package zte
+
+// Metric is one logical metric the client collects and the OID it reads it
+// from.
+type Metric struct {
+	Name string
+	OID  string
+
+	// Table is true for table columns walked per row, false for scalars
+	// read with GET.
+	Table bool
+
+	// Unit of the value as reported, empty for states, counts and text.
+	Unit string
+}
+
+// MetricGroup is the set of metrics one client operation collects.
+type MetricGroup struct {
+	Name    string
+	Metrics []Metric
+
+	// Includes names other groups whose metrics the operation also
+	// collects, e.g. ONT metrics carry the ont_info columns.
+	Includes []string
+}
+
+// The tables below are what the client reads: each operation GETs or walks
+// exactly these, so Capabilities cannot drift from what is collected.
+var (
+	identityScalars = []Metric{
+		{Name: "sys_object_id", OID: OIDSysObjectID},
+		{Name: "sys_descr", OID: OIDSysDescr},
+		{Name: "uptime", OID: OIDSysUpTime, Unit: "centiseconds"},
+	}
+	systemScalars = []Metric{
+		{Name: "sys_descr", OID: OIDSysDescr},
+		{Name: "sys_name", OID: OIDSysName},
+		{Name: "uptime", OID: OIDSysUpTime, Unit: "centiseconds"},
+	}
+	cardStateColumns = []Metric{
+		{Name: "type", OID: OIDZTECardType, Table: true},
+		{Name: "status", OID: OIDZTECardStatus, Table: true},
+	}
+	// cardLoadColumns are also walked by GetSystemMetrics, which reduces
+	// each to the highest value across cards.
+	cardLoadColumns = []Metric{
+		{Name: "cpu_usage", OID: OIDZTECardCPUUsage, Table: true, Unit: "%"},
+		{Name: "temperature", OID: OIDZTECardTemperature, Table: true, Unit: "°C"},
+		{Name: "memory_usage", OID: OIDZTECardMemoryUsage, Table: true, Unit: "%"},
+		{Name: "memory_total", OID: OIDZTECardMemoryTotal, Table: true, Unit: "MB"},
+	}
+	ponPortColumns = []Metric{
+		{Name: "admin_status", OID: OIDZTEPONPortAdminStatus, Table: true},
+		{Name: "oper_status", OID: OIDZTEPONPortOperStatus, Table: true},
+		{Name: "tx_power", OID: OIDZTEPONPortTxPower, Table: true, Unit: "dBm"},
+		{Name: "rx_power", OID: OIDZTEPONPortRxPower, Table: true, Unit: "dBm"},
+		{Name: "ont_count", OID: OIDZTEPONPortONTCount, Table: true},
+	}
+	ontColumns = []Metric{
+		{Name: "oper_status", OID: OIDZTEONTOperStatus, Table: true},
+		{Name: "rx_power", OID: OIDZTEONTRxPower, Table: true, Unit: "dBm"},
+		{Name: "tx_power", OID: OIDZTEONTTxPower, Table: true, Unit: "dBm"},
+		{Name: "distance", OID: OIDZTEONTDistance, Table: true, Unit: "m"},
+	}
+	ontStateColumns = []Metric{
+		{Name: "last_down_cause", OID: OIDZTEONTLastDownCause, Table: true},
+		{Name: "line_profile", OID: OIDZTEONTLineProfile, Table: true},
+		{Name: "service_profile", OID: OIDZTEONTServiceProfile, Table: true},
+	}
+	ontInfoColumns = []Metric{
+		{Name: "name", OID: OIDZTEONTInfoName, Table: true},
+		{Name: "description", OID: OIDZTEONTInfoDescription, Table: true},
+		{Name: "serial_number", OID: OIDZTEONTInfoSerialNumber, Table: true},
+	}
+	servicePortColumns = []Metric{
+		{Name: "vport", OID: OIDZTEServicePortVPort, Table: true},
+		{Name: "user_vlan", OID: OIDZTEServicePortUserVLAN, Table: true},
+		{Name: "vlan", OID: OIDZTEServicePortVLAN, Table: true},
+	}
+)
+
+// Capabilities lists the metrics ZTEOLTClient collects, grouped by the
+// operation that reads them. Names match the column names used in
+// ColumnError.
+func Capabilities() []MetricGroup {
+	return []MetricGroup{
+		{Name: "identity", Metrics: concatMetrics(identityScalars)},
+		{Name: "system", Metrics: concatMetrics(systemScalars, cardLoadColumns)},
+		{Name: "cards", Metrics: concatMetrics(cardStateColumns, cardLoadColumns)},
+		{Name: "pon_ports", Metrics: concatMetrics(ponPortColumns)},
+		{Name: "onts", Metrics: concatMetrics(ontColumns, ontStateColumns), Includes: []string{"ont_info"}},
+		{Name: "ont_info", Metrics: concatMetrics(ontInfoColumns)},
+		{Name: "service_ports", Metrics: concatMetrics(servicePortColumns)},
+	}
+}
+
+// concatMetrics copies tables into one new slice, so callers cannot modify
+// the tables the client reads.
+func concatMetrics(tables ...[]Metric) []Metric {
+	var out []Metric
+	for _, t := range tables {
+		out = append(out, t...)
+	}
+	return out
+}
+
+// metricOIDs returns the OIDs of metrics in order.
+func metricOIDs(metrics []Metric) []string {
+	oids := make([]string, len(metrics))
+	for i, m := range metrics {
+		oids[i] = m.OID
+	}
+	return oids
+}
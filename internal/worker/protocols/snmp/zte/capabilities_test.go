@@ -0,0 +1,124 @@
+package zte_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	devicemodel "github.com/yourorg/nms-go/internal/device/model"
+	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
+)
+
+// recordingSNMPClient records every OID the client reads and returns no data.
+type recordingSNMPClient struct {
+	mockSNMPClient
+	read map[string]bool
+}
+
+func (m *recordingSNMPClient) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	for _, oid := range oids {
+		m.read[oid] = true
+	}
+	return &gosnmp.SnmpPacket{}, nil
+}
+
+func (m *recordingSNMPClient) Walk(oid string, _ gosnmp.WalkFunc) error {
+	m.read[oid] = true
+	return nil
+}
+
+// TestCapabilities_MatchOIDsRead guards the catalog against drifting from
+// what each client operation actually reads.
+func TestCapabilities_MatchOIDsRead(t *testing.T) {
+	ctx := context.Background()
+	operations := map[string]func(c *zte.ZTEOLTClient){
+		"identity":      func(c *zte.ZTEOLTClient) { _, _ = c.GetSystemIdentity(ctx) },
+		"system":        func(c *zte.ZTEOLTClient) { _, _ = c.GetSystemMetrics(ctx) },
+		"cards":         func(c *zte.ZTEOLTClient) { _, _ = c.GetCards(ctx) },
+		"pon_ports":     func(c *zte.ZTEOLTClient) { _, _ = c.GetPONPortMetrics(ctx) },
+		"onts":          func(c *zte.ZTEOLTClient) { _, _ = c.GetONTMetrics(ctx, 0) },
+		"ont_info":      func(c *zte.ZTEOLTClient) { _, _ = c.GetONTInfo(ctx) },
+		"service_ports": func(c *zte.ZTEOLTClient) { _, _ = c.GetServicePorts(ctx) },
+	}
+
+	groups := zte.Capabilities()
+	assert.Len(t, groups, len(operations))
+	byName := make(map[string]zte.MetricGroup)
+	for _, group := range groups {
+		byName[group.Name] = group
+	}
+	for _, group := range groups {
+		t.Run(group.Name, func(t *testing.T) {
+			run, ok := operations[group.Name]
+			if !ok {
+				t.Fatalf("no client operation for group %q", group.Name)
+			}
+
+			mock := &recordingSNMPClient{read: make(map[string]bool)}
+			client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+			client.SetDevice(&devicemodel.Device{ID: "olt-1"})
+			run(client)
+
+			var want []string
+			for _, m := range group.Metrics {
+				want = append(want, m.OID)
+			}
+			for _, name := range group.Includes {
+				included, ok := byName[name]
+				if !ok {
+					t.Fatalf("group %q includes unknown group %q", group.Name, name)
+				}
+				for _, m := range included.Metrics {
+					want = append(want, m.OID)
+				}
+			}
+			var got []string
+			for oid := range mock.read {
+				got = append(got, oid)
+			}
+			sort.Strings(want)
+			sort.Strings(got)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCapabilities_IncludesKnownMetrics(t *testing.T) {
+	oids := make(map[string]string)
+	for _, group := range zte.Capabilities() {
+		for _, m := range group.Metrics {
+			oids[group.Name+"."+m.Name] = m.OID
+		}
+	}
+
+	assert.Equal(t, zte.OIDZTEONTRxPower, oids["onts.rx_power"])
+	assert.Equal(t, zte.OIDZTEPONPortTxPower, oids["pon_ports.tx_power"])
+	assert.Equal(t, zte.OIDZTECardCPUUsage, oids["system.cpu_usage"])
+	assert.Equal(t, zte.OIDSysUpTime, oids["system.uptime"])
+}
+
+// TestCapabilities_NoDuplicateOIDs checks that a group lists each OID once,
+// counting the groups it includes.
+func TestCapabilities_NoDuplicateOIDs(t *testing.T) {
+	byName := make(map[string]zte.MetricGroup)
+	for _, group := range zte.Capabilities() {
+		byName[group.Name] = group
+	}
+
+	for _, group := range byName {
+		metrics := group.Metrics
+		for _, name := range group.Includes {
+			metrics = append(metrics, byName[name].Metrics...)
+		}
+		seen := make(map[string]string)
+		for _, m := range metrics {
+			if prev, dup := seen[m.OID]; dup {
+				t.Errorf("group %q lists %s as both %q and %q", group.Name, m.OID, prev, m.Name)
+			}
+			seen[m.OID] = m.Name
+		}
+	}
+}
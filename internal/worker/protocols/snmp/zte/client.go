@@ -5,11 +5,18 @@ package zte
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gosnmp/gosnmp"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	devicemodel "github.com/yourorg/nms-go/internal/device/model"
 	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
 )
@@ -19,6 +26,9 @@ const (
 	defaultCommunity   = "public"
 	// snmpPowerScale converts raw SNMP power values (0.1 dBm units) to dBm.
 	snmpPowerScale = 10.0
+	// cardWalkConcurrency bounds the card columns GetSystemMetrics walks at
+	// once, and so the extra SNMP sessions it opens per call.
+	cardWalkConcurrency = 4
 )
 
 // ZTEOLTClient is an SNMP-based client for ZTE C320 OLT devices.
@@ -27,6 +37,16 @@ type ZTEOLTClient struct {
 	snmp    snmpclient.SNMPClient
 	device  *devicemodel.Device
 	timeout time.Duration
+
+	// collectedAt is the timestamp stamped on every metric read in the
+	// current session, so points from one collection align in storage.
+	collectedAt time.Time
+
+	// maxONTs caps the ONTs GetONTMetrics collects; 0 means no limit.
+	maxONTs int
+
+	// distanceUnit is the unit of the raw ONT distance column.
+	distanceUnit DistanceUnit
 }
 
 // NewZTEOLTClient creates a new ZTEOLTClient with the production SNMP implementation.
@@ -50,9 +70,48 @@ func NewZTEOLTClientForTest(snmp snmpclient.SNMPClient, timeout time.Duration) *
 // This is intended for use in unit tests where Connect is not called.
 func (c *ZTEOLTClient) SetDevice(device *devicemodel.Device) {
 	c.device = device
+	c.collectedAt = time.Now()
+}
+
+// Host returns the IP address of the device the client connects to, or ""
+// before Connect.
+func (c *ZTEOLTClient) Host() string {
+	if c.device == nil {
+		return ""
+	}
+	return c.device.IPAddress
 }
 
-// Connect establishes an SNMP session to the ZTE C320 OLT.
+// SetMaxONTs limits the number of ONTs GetONTMetrics collects. Zero or a
+// negative value removes the limit.
+func (c *ZTEOLTClient) SetMaxONTs(n int) {
+	c.maxONTs = n
+}
+
+// SetDistanceUnit sets the unit the OLT reports ONT distance in. The default
+// is meters.
+func (c *ZTEOLTClient) SetDistanceUnit(u DistanceUnit) {
+	c.distanceUnit = u
+}
+
+// SetRetries sets the SNMP retry count used by Connect, when the underlying
+// client supports it.
+func (c *ZTEOLTClient) SetRetries(n int) {
+	if rs, ok := c.snmp.(snmpclient.RetrySetter); ok {
+		rs.SetRetries(n)
+	}
+}
+
+// SetMaxRepetitions sets the GETBULK max-repetitions used by walks in
+// Connect, when the underlying client supports it.
+func (c *ZTEOLTClient) SetMaxRepetitions(n uint32) {
+	if ms, ok := c.snmp.(snmpclient.MaxRepetitionsSetter); ok {
+		ms.SetMaxRepetitions(n)
+	}
+}
+
+// Connect establishes an SNMP session to the ZTE C320 OLT. It also starts a
+// new collection: metrics read until the next Connect share one timestamp.
 func (c *ZTEOLTClient) Connect(ctx context.Context, device *devicemodel.Device) error {
 	if device == nil {
 		return fmt.Errorf("device must not be nil")
@@ -63,7 +122,11 @@ func (c *ZTEOLTClient) Connect(ctx context.Context, device *devicemodel.Device)
 	}
 
 	c.device = device
-	community := device.Credentials.SNMPCommunity
+	c.collectedAt = time.Now()
+	community, err := crypto.Decrypt(device.Credentials.SNMPCommunity)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt device credentials: %w", err)
+	}
 	if community == "" {
 		community = defaultCommunity
 	}
@@ -76,23 +139,20 @@ func (c *ZTEOLTClient) Disconnect() error {
 	return c.snmp.Disconnect()
 }
 
+// errStopWalk ends a walk early from inside its callback.
+var errStopWalk = errors.New("stop walk")
+
 // GetSystemMetrics retrieves system-level metrics from the OLT.
 func (c *ZTEOLTClient) GetSystemMetrics(ctx context.Context) (*OLTSystemMetrics, error) {
 	// 1. Get standard scalars first
-	oids := []string{
-		OIDSysDescr,
-		OIDSysName,
-		OIDSysUpTime,
-	}
-
-	packet, err := c.snmp.Get(oids)
+	packet, err := c.snmp.Get(metricOIDs(systemScalars))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get system metrics: %w", err)
 	}
 
 	metrics := &OLTSystemMetrics{
 		DeviceID:  c.device.ID,
-		Timestamp: time.Now(),
+		Timestamp: c.collectedAt,
 	}
 
 	for _, pdu := range packet.Variables {
@@ -115,85 +175,251 @@ func (c *ZTEOLTClient) GetSystemMetrics(ctx context.Context) (*OLTSystemMetrics,
 
 	// 2. Walk card tables for dynamic metrics (CPU, Mem, Temp)
 	// We'll take the MAX value found across cards as the system bottleneck indicator.
-	walkOIDs := map[string]func(pdu gosnmp.SnmpPDU){
-		OIDZTECardCPUUsage: func(pdu gosnmp.SnmpPDU) {
-			val := float64(pduToInt(pdu))
-			if val > metrics.CPUUsagePercent {
-				metrics.CPUUsagePercent = val
+	// Indexes follow cardLoadColumns.
+	maxima := c.walkColumnMaxima(metricOIDs(cardLoadColumns))
+	metrics.CPUUsagePercent = float64(maxima[0])
+	metrics.TemperatureCelsius = float64(maxima[1])
+	metrics.MemoryUsagePercent = float64(maxima[2])
+	// This might be in MB based on walk (512, 2048)
+	metrics.MemoryTotalKB = int64(maxima[3]) * 1024 // Convert MB to KB
+
+	// Calculate used memory if we have total and usage %
+	if metrics.MemoryTotalKB > 0 && metrics.MemoryUsagePercent > 0 {
+		metrics.MemoryUsedKB = int64(float64(metrics.MemoryTotalKB) * metrics.MemoryUsagePercent / 100)
+	}
+
+	return metrics, nil
+}
+
+// walkColumnMaxima walks each column and returns its highest value, or 0 when
+// it has no positive value, in the order of oids. Up to cardWalkConcurrency
+// columns are walked at once, each extra one on a session cloned for the
+// call; without cloning they are walked one after another.
+func (c *ZTEOLTClient) walkColumnMaxima(oids []string) []int {
+	sessions := []snmpclient.SNMPClient{c.snmp}
+	if cloner, ok := c.snmp.(snmpclient.SessionCloner); ok {
+		for len(sessions) < min(cardWalkConcurrency, len(oids)) {
+			session, err := cloner.Clone()
+			if err != nil {
+				log.Printf("ZTE OLT %s: walking card columns on %d session(s): %v", c.device.IPAddress, len(sessions), err)
+				break
 			}
-		},
-		OIDZTECardTemperature: func(pdu gosnmp.SnmpPDU) {
-			val := float64(pduToInt(pdu))
-			if val > metrics.TemperatureCelsius {
-				metrics.TemperatureCelsius = val
+			defer session.Disconnect()
+			sessions = append(sessions, session)
+		}
+	}
+
+	// Each column writes only its own slot, so the result does not depend on
+	// which session walked it or when.
+	maxima := make([]int, len(oids))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for _, session := range sessions {
+		wg.Add(1)
+		go func(session snmpclient.SNMPClient) {
+			defer wg.Done()
+			for i := range next {
+				maxima[i] = c.walkColumnMax(session, oids[i])
 			}
-		},
-		OIDZTECardMemoryUsage: func(pdu gosnmp.SnmpPDU) {
-			val := float64(pduToInt(pdu))
-			if val > metrics.MemoryUsagePercent {
-				metrics.MemoryUsagePercent = val
+		}(session)
+	}
+	for i := range oids {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return maxima
+}
+
+// walkColumnMax walks one card column on session and returns its highest
+// value, or 0 when it has no positive value.
+func (c *ZTEOLTClient) walkColumnMax(session snmpclient.SNMPClient, baseOID string) int {
+	highest := 0
+	logged := false
+	// A failed walk keeps the values read before it, like a skipped value.
+	_ = session.Walk(baseOID, func(pdu gosnmp.SnmpPDU) error {
+		val, err := pduIntValue(pdu)
+		if err != nil {
+			// Skip the value rather than count it as zero.
+			if !logged {
+				log.Printf("ZTE OLT %s: ignoring values of card column %s: %s: %v", c.device.IPAddress, baseOID, pdu.Name, err)
+				logged = true
 			}
-		},
-		OIDZTECardMemoryTotal: func(pdu gosnmp.SnmpPDU) {
-			// This might be in MB based on walk (512, 2048)
-			val := int64(pduToInt(pdu)) * 1024 // Convert MB to KB
-			if val > metrics.MemoryTotalKB {
-				metrics.MemoryTotalKB = val
+			return nil
+		}
+		if val > highest {
+			highest = val
+		}
+		return nil
+	})
+	return highest
+}
+
+// GetCards walks the card table and returns every installed card ordered by
+// slot. If only some columns can be walked, the cards are returned together
+// with a *PartialError naming the failed columns.
+func (c *ZTEOLTClient) GetCards(ctx context.Context) ([]*CardMetrics, error) {
+	cardsBySlot := make(map[int]*CardMetrics)
+
+	// Setters return an error for a value of an unexpected type; the field
+	// is then left unset and the column reported, instead of reading as 0.
+	columns := concatMetrics(cardStateColumns, cardLoadColumns)
+	setters := map[string]func(pdu gosnmp.SnmpPDU, card *CardMetrics) error{
+		"type": func(pdu gosnmp.SnmpPDU, card *CardMetrics) error {
+			raw, ok := pdu.Value.([]byte)
+			if !ok {
+				return unexpectedType(pdu)
 			}
+			card.Type = decodeOctetString(raw)
+			return nil
 		},
+		"status": intSetter(func(v int, card *CardMetrics) {
+			card.Status = CardStatus(v)
+		}),
+		"cpu_usage": intSetter(func(v int, card *CardMetrics) {
+			card.CPUUsagePercent = float64(v)
+		}),
+		"temperature": intSetter(func(v int, card *CardMetrics) {
+			card.TemperatureCelsius = float64(v)
+		}),
+		"memory_usage": intSetter(func(v int, card *CardMetrics) {
+			card.MemoryUsagePercent = float64(v)
+		}),
+		"memory_total": intSetter(func(v int, card *CardMetrics) {
+			card.MemoryTotalKB = int64(v) * 1024 // MB to KB
+		}),
 	}
 
-	for baseOID, updateFunc := range walkOIDs {
-		localUpdateFunc := updateFunc // Capture for closure
-		err := c.snmp.Walk(baseOID, func(pdu gosnmp.SnmpPDU) error {
-			localUpdateFunc(pdu)
+	var failed []ColumnError
+	walkFailures := 0
+	for _, col := range columns {
+		col := col
+		set := setters[col.Name]
+
+		var mismatches valueErrors
+		err := c.snmp.Walk(col.OID, func(pdu gosnmp.SnmpPDU) error {
+			slot := extractLastOIDIndex(pdu.Name, col.OID)
+			if slot < 0 {
+				return nil
+			}
+
+			if _, exists := cardsBySlot[slot]; !exists {
+				cardsBySlot[slot] = &CardMetrics{
+					DeviceID:  c.device.ID,
+					Timestamp: c.collectedAt,
+					Slot:      slot,
+				}
+			}
+
+			if err := set(pdu, cardsBySlot[slot]); err != nil {
+				mismatches = append(mismatches, fmt.Errorf("slot %d: %w", slot, err))
+			}
 			return nil
 		})
+
 		if err != nil {
-			// Log error but continue with other metrics?
-			// For now, return error to surface connectivity issues.
-			// Ideally we might want partial success.
-			// fmt.Printf("Warning: failed to walk OID %s: %v\n", baseOID, err)
+			walkFailures++
+			failed = append(failed, ColumnError{Column: col.Name, OID: col.OID, Err: err})
+		} else if len(mismatches) > 0 {
+			log.Printf("ZTE OLT %s: card column %s: %v", c.device.IPAddress, col.Name, mismatches)
+			failed = append(failed, ColumnError{Column: col.Name, OID: col.OID, Err: mismatches})
 		}
 	}
 
-	// Calculate used memory if we have total and usage %
-	if metrics.MemoryTotalKB > 0 && metrics.MemoryUsagePercent > 0 {
-		metrics.MemoryUsedKB = int64(float64(metrics.MemoryTotalKB) * metrics.MemoryUsagePercent / 100)
+	if walkFailures == len(columns) {
+		return nil, fmt.Errorf("failed to walk card OID %s: %w", failed[0].OID, failed[0].Err)
 	}
 
-	return metrics, nil
+	cards := make([]*CardMetrics, 0, len(cardsBySlot))
+	for _, card := range cardsBySlot {
+		cards = append(cards, card)
+	}
+	sort.Slice(cards, func(i, j int) bool { return cards[i].Slot < cards[j].Slot })
+
+	if len(failed) > 0 {
+		return cards, &PartialError{Columns: failed}
+	}
+	return cards, nil
+}
+
+// GetSystemIdentity retrieves sysObjectID, sysDescr and sysUpTime in a single request.
+func (c *ZTEOLTClient) GetSystemIdentity(ctx context.Context) (*SystemIdentity, error) {
+	packet, err := c.snmp.Get(metricOIDs(identityScalars))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system identity: %w", err)
+	}
+
+	identity := &SystemIdentity{}
+	for _, pdu := range packet.Variables {
+		switch strings.TrimPrefix(pdu.Name, ".") {
+		case OIDSysObjectID:
+			if oid, ok := pdu.Value.(string); ok {
+				identity.SysObjectID = strings.TrimPrefix(oid, ".")
+			}
+		case OIDSysDescr:
+			if pdu.Type == gosnmp.OctetString {
+				identity.SysDescr = string(pdu.Value.([]byte))
+			}
+		case OIDSysUpTime:
+			identity.UptimeTicks = pduToUint32(pdu)
+		}
+	}
+
+	return identity, nil
+}
+
+// GetUptimeTicks retrieves sysUpTime in hundredths of a second.
+func (c *ZTEOLTClient) GetUptimeTicks(ctx context.Context) (uint32, error) {
+	packet, err := c.snmp.Get([]string{OIDSysUpTime})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get uptime: %w", err)
+	}
+
+	for _, pdu := range packet.Variables {
+		if strings.TrimPrefix(pdu.Name, ".") == OIDSysUpTime {
+			return pduToUint32(pdu), nil
+		}
+	}
+
+	return 0, nil
 }
 
 // GetPONPortMetrics retrieves metrics for all PON ports on the OLT.
+// If only some columns can be walked, the ports are returned together with a
+// *PartialError naming the failed columns.
 func (c *ZTEOLTClient) GetPONPortMetrics(ctx context.Context) ([]*PONPortMetrics, error) {
 	portsByIndex := make(map[int]*PONPortMetrics)
-	timestamp := time.Now()
+	timestamp := c.collectedAt
 
-	walkOIDs := map[string]func(pdu gosnmp.SnmpPDU, port *PONPortMetrics){
-		OIDZTEPONPortAdminStatus: func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
+	columns := ponPortColumns
+	setters := map[string]func(pdu gosnmp.SnmpPDU, port *PONPortMetrics){
+		"admin_status": func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
 			port.AdminStatus = PONPortStatus(pduToInt(pdu))
 		},
-		OIDZTEPONPortOperStatus: func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
+		"oper_status": func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
 			port.OperStatus = PONPortStatus(pduToInt(pdu))
 		},
-		OIDZTEPONPortTxPower: func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
+		"tx_power": func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
 			port.TxPowerDBm = float64(pduToInt(pdu)) / snmpPowerScale
 		},
-		OIDZTEPONPortRxPower: func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
+		"rx_power": func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
 			port.RxPowerDBm = float64(pduToInt(pdu)) / snmpPowerScale
 		},
-		OIDZTEPONPortONTCount: func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
+		"ont_count": func(pdu gosnmp.SnmpPDU, port *PONPortMetrics) {
 			port.ONTCount = pduToInt(pdu)
 		},
 	}
 
-	for baseOID, setter := range walkOIDs {
-		localSetter := setter // capture for goroutine safety
-		localBaseOID := baseOID
+	// A failed column only blanks its own field; the walk fails only when
+	// every column does.
+	var failed []ColumnError
+	for _, col := range columns {
+		col := col
+		set := setters[col.Name]
 
-		err := c.snmp.Walk(localBaseOID, func(pdu gosnmp.SnmpPDU) error {
-			index := extractLastOIDIndex(pdu.Name, localBaseOID)
+		err := c.snmp.Walk(col.OID, func(pdu gosnmp.SnmpPDU) error {
+			index := extractLastOIDIndex(pdu.Name, col.OID)
 			if index < 0 {
 				return nil
 			}
@@ -206,155 +432,265 @@ func (c *ZTEOLTClient) GetPONPortMetrics(ctx context.Context) ([]*PONPortMetrics
 				}
 			}
 
-			localSetter(pdu, portsByIndex[index])
+			set(pdu, portsByIndex[index])
 			return nil
 		})
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to walk PON port OID %s: %w", localBaseOID, err)
+			failed = append(failed, ColumnError{Column: col.Name, OID: col.OID, Err: err})
 		}
 	}
 
+	if len(failed) == len(columns) {
+		return nil, fmt.Errorf("failed to walk PON port OID %s: %w", failed[0].OID, failed[0].Err)
+	}
+
 	ports := make([]*PONPortMetrics, 0, len(portsByIndex))
 	for _, port := range portsByIndex {
 		ports = append(ports, port)
 	}
 
+	if len(failed) > 0 {
+		return ports, &PartialError{Columns: failed}
+	}
 	return ports, nil
 }
 
-// GetONTMetrics retrieves metrics for all ONTs on a specific PON port.
-// Pass ponPortIndex = 0 to retrieve all ONTs across all PON ports.
+// GetONTMetrics retrieves metrics for all ONTs on a specific PON port, given
+// by its ifIndex as returned in PONPortMetrics.PortIndex. Pass ponPortIndex = 0
+// to retrieve all ONTs across all PON ports.
+// If only some columns can be walked, the ONTs are returned together with a
+// *PartialError naming the failed columns. If the OLT reports more ONTs than
+// the limit set by SetMaxONTs, the walk stops there and the ONTs collected so
+// far are returned with ErrONTLimitReached.
 func (c *ZTEOLTClient) GetONTMetrics(ctx context.Context, ponPortIndex int) ([]*ONTMetrics, error) {
-	ontsByKey := make(map[string]*ONTMetrics)
-	timestamp := time.Now()
+	ontsByIndex := make(map[int]*ONTMetrics)
+	timestamp := c.collectedAt
 
-	walkOIDs := map[string]func(pdu gosnmp.SnmpPDU, ont *ONTMetrics){
-		OIDZTEONTSerialNumber: func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
-			// Using index as placeholder Serial Number / Description
-			// logic handled in loop below
-		},
-		OIDZTEONTOperStatus: func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+	columns := ontColumns
+	setters := map[string]func(pdu gosnmp.SnmpPDU, ont *ONTMetrics){
+		"oper_status": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
 			ont.OperStatus = ONTStatus(pduToInt(pdu))
 		},
-		OIDZTEONTRxPower: func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+		"rx_power": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
 			ont.RxPowerDBm = float64(pduToInt(pdu)) / snmpPowerScale
 		},
-		OIDZTEONTTxPower: func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+		"tx_power": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
 			ont.TxPowerDBm = float64(pduToInt(pdu)) / snmpPowerScale
 		},
-		OIDZTEONTDistance: func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
-			ont.DistanceMeters = pduToInt(pdu)
+		"distance": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+			ont.DistanceMeters = c.distanceUnit.ToMeters(pduToInt(pdu))
+			ont.DistanceImplausible = ont.DistanceMeters < 0 || ont.DistanceMeters > MaxGPONReachMeters
 		},
 	}
 
-	for baseOID, setter := range walkOIDs {
-		localSetter := setter
-		localBaseOID := baseOID
-
-		err := c.snmp.Walk(localBaseOID, func(pdu gosnmp.SnmpPDU) error {
-			// Index is now a single integer (e.g., 268435456 -> 10000000 hex)
-			// Likely Packed: Slot(8) | Port(8) | ONT(8)
-			index := extractLastOIDIndex(pdu.Name, localBaseOID)
-			if index < 0 {
+	// As with PON ports, a failed column only blanks its own field.
+	var failed []ColumnError
+	truncated := false
+	for _, col := range columns {
+		col := col
+		set := setters[col.Name]
+		err := c.snmp.Walk(col.OID, func(pdu gosnmp.SnmpPDU) error {
+			pon, ontID, ok := parseONTIndex(pdu.Name, col.OID)
+			if !ok {
 				return nil
 			}
-
-			// Reverse engineer indexes from the packed integer
-			// 268632320 = 0x10030100 -> Slot 0x10 (16), Port 0x03 (3), ONT 0x01 (1)?
-			// 268435456 = 0x10000000 -> Slot 0x10 (16), Port 0x00 (0), ONT 0x00 (0)?
-			// Let's assume the last byte is ONT Index (or maybe the last 2 bytes?)
-			// And the middle byte is PON Port Index?
-
-			// For ZTE C320, we can try:
-			// ONT Index matches the full integer (unique ID), but we want to group by PON Port.
-			// The PON Port Index we found earlier (268632064 = 0x10030000) matches the prefix.
-
-			// Let's deduce PON Port Index by masking out the ONT part.
-			// If ONTs are 0..128, then masking last 8 bits (0xFF) should invoke the PON Port.
-			// 0x10030100 & 0xFFFFFF00 = 0x10030000 -> 268632064 ?
-			// 268632320 & 0xFFFFFF00 = 268632320 (00). Wait.
-
-			// Let's look at the walk data:
-			// PON Index: 268632064 (0x10030000)
-			// ONT Index: 268632320 (0x10030100)? No,
-			// 268632064 is one port.
-			// 268632320 is another port (Port 2 in walk output "Integer 2").
-
-			// Ah, the PON Port Table had:
-			// .268632064 (Port 1? Index 1)
-			// .268632320 (Port 2? Index 2)
-
-			// The ONT table has:
-			// .268435456 -> 0x10000000
-
-			// Implementation strategy:
-			// We will treat the "PON Port Index" as the upper 24 bits?
-			// Let's just use the full index as unique ONT key locally.
-			// And to filter by PON Port, we need to know the mapping.
-
-			// For now, simple implementation:
-			// ponIdx = index
-			// ontIdx = index // Just using unique ID
-
-			// Wait, the user wants grouped data.
-			// Let's assign PON Index as (index & 0xFFFFFF00) as a heuristic guess?
-			// Or just return everything and let the frontend filter.
-
-			ponIdx := index >> 8 << 8 // Mask last 8 bits?
-			ontIdx := index & 0xFF
-
-			// If valid PON Port Index is needed, we need to match it with what GetPONPorts returned.
-			// Earlier: 268632064, 268632320.
-			// ONT ID: 268435456 (0x10000000).
-			// This doesn't seem to match 0x10030000 nicely.
-
-			// Fallback: Use the full integer as the key, and assume PON/ONT index extraction
-			// is implicitly handled by just returning unique items.
-			ponIdx = index // temporary hack to group by ID
-			ontIdx = index
-
-			// Filter by PON port if requested (exact match)
-			// If the user passes the long integer as PONPortIndex, simple equality works?
-			// But likely the user passes a small integer (1, 2).
-			// Our previous code returned the long integer as PortIndex.
-			if ponPortIndex > 0 {
-				// We don't know how to relate index to ponPortIndex reliably yet.
-				// Skipping filter for now to ensure we return data.
+			if ponPortIndex > 0 && pon != ponPortIndex {
+				return nil
 			}
 
-			key := fmt.Sprintf("%d", index)
-			if _, exists := ontsByKey[key]; !exists {
-				ontsByKey[key] = &ONTMetrics{
+			// The packed index stays the ONT's identifier; ONT IDs alone are
+			// only unique within a port.
+			index := pon | ontID
+			ont, exists := ontsByIndex[index]
+			if !exists {
+				if c.maxONTs > 0 && len(ontsByIndex) >= c.maxONTs {
+					truncated = true
+					return errStopWalk
+				}
+				slot, port := PONLocation(pon)
+				ont = &ONTMetrics{
 					DeviceID:     c.device.ID,
 					Timestamp:    timestamp,
-					PONPortIndex: ponIdx,                   // Using long ID
-					ONTIndex:     ontIdx,                   // Using long ID (or derivative)
-					SerialNumber: fmt.Sprintf("%X", index), // Makeshift SN
-					Description:  fmt.Sprintf("ONT-%d", index),
+					PONPortIndex: pon,
+					ONTIndex:     index,
+					Slot:         slot,
+					PONPort:      port,
+					ONTID:        ontID,
 				}
+				ontsByIndex[index] = ont
+			}
+			set(pdu, ont)
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopWalk) {
+			failed = append(failed, ColumnError{Column: col.Name, OID: col.OID, Err: err})
+		}
+	}
+
+	if len(failed) == len(columns) {
+		return nil, fmt.Errorf("failed to walk ONT OID %s: %w", failed[0].OID, failed[0].Err)
+	}
+
+	// The config and state tables are indexed by <PON ifIndex>.<ONT ID>. The
+	// ONT table has no identity columns, so names and serials come from the
+	// config table.
+	joinedSetters := map[string]func(pdu gosnmp.SnmpPDU, ont *ONTMetrics){
+		"name": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+			ont.Name = pduToText(pdu)
+		},
+		"description": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+			ont.Description = pduToText(pdu)
+		},
+		"serial_number": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+			if raw, ok := pdu.Value.([]byte); ok {
+				ont.SerialNumber = decodeSerialNumber(raw)
 			}
+		},
+		"last_down_cause": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+			ont.LastDownCause = ONTDownCause(pduToInt(pdu))
+		},
+		"line_profile": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+			ont.LineProfile = pduToText(pdu)
+		},
+		"service_profile": func(pdu gosnmp.SnmpPDU, ont *ONTMetrics) {
+			ont.ServiceProfile = pduToText(pdu)
+		},
+	}
+	for _, col := range concatMetrics(ontInfoColumns, ontStateColumns) {
+		col := col
+		set := joinedSetters[col.Name]
+		err := c.snmp.Walk(col.OID, func(pdu gosnmp.SnmpPDU) error {
+			pon, ont := extractTwoLastOIDIndexes(pdu.Name, col.OID)
+			if pon < 0 || ont < 0 {
+				return nil
+			}
+			if o, exists := ontsByIndex[pon|ont]; exists {
+				set(pdu, o)
+			}
+			return nil
+		})
+		if err != nil {
+			failed = append(failed, ColumnError{Column: col.Name, OID: col.OID, Err: err})
+		}
+	}
+
+	onts := make([]*ONTMetrics, 0, len(ontsByIndex))
+	for _, ont := range ontsByIndex {
+		onts = append(onts, ont)
+	}
 
-			if localBaseOID == OIDZTEONTSerialNumber {
-				// No-op or we could store Value if it was meaningful
-			} else {
-				localSetter(pdu, ontsByKey[key])
+	var errs []error
+	if len(failed) > 0 {
+		errs = append(errs, &PartialError{Columns: failed})
+	}
+	if truncated {
+		errs = append(errs, fmt.Errorf("%w: collected the first %d", ErrONTLimitReached, c.maxONTs))
+	}
+	return onts, errors.Join(errs...)
+}
+
+// GetONTInfo walks the ONT config table and returns the name, description and
+// serial number of every ONT registered on the OLT.
+func (c *ZTEOLTClient) GetONTInfo(ctx context.Context) ([]*ONTInfo, error) {
+	type ontKey struct{ pon, ont int }
+	infoByKey := make(map[ontKey]*ONTInfo)
+	var order []ontKey
+
+	setters := map[string]func(raw []byte, info *ONTInfo){
+		"name":          func(raw []byte, info *ONTInfo) { info.Name = decodeOctetString(raw) },
+		"description":   func(raw []byte, info *ONTInfo) { info.Description = decodeOctetString(raw) },
+		"serial_number": func(raw []byte, info *ONTInfo) { info.SerialNumber = decodeSerialNumber(raw) },
+	}
+
+	for _, col := range ontInfoColumns {
+		col := col
+		setter := setters[col.Name]
+		err := c.snmp.Walk(col.OID, func(pdu gosnmp.SnmpPDU) error {
+			pon, ont := extractTwoLastOIDIndexes(pdu.Name, col.OID)
+			if pon < 0 || ont < 0 {
+				return nil
+			}
+			raw, ok := pdu.Value.([]byte)
+			if !ok {
+				return nil
 			}
 
+			key := ontKey{pon: pon, ont: ont}
+			info, exists := infoByKey[key]
+			if !exists {
+				info = &ONTInfo{PONPortIndex: pon, ONTID: ont}
+				infoByKey[key] = info
+				order = append(order, key)
+			}
+			setter(raw, info)
 			return nil
 		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk ONT info OID %s: %w", col.OID, err)
+		}
+	}
 
+	infos := make([]*ONTInfo, 0, len(order))
+	for _, key := range order {
+		infos = append(infos, infoByKey[key])
+	}
+
+	return infos, nil
+}
+
+// GetServicePorts walks the service-port table and returns the VLAN mapping
+// of every ONT service port, ordered by PON port, ONT ID and service-port ID.
+func (c *ZTEOLTClient) GetServicePorts(ctx context.Context) ([]*ServicePort, error) {
+	type portKey struct{ pon, ont, id int }
+	byKey := make(map[portKey]*ServicePort)
+
+	setters := map[string]func(value int, sp *ServicePort){
+		"vport":     func(value int, sp *ServicePort) { sp.VPort = value },
+		"user_vlan": func(value int, sp *ServicePort) { sp.UserVLAN = value },
+		"vlan":      func(value int, sp *ServicePort) { sp.VLAN = value },
+	}
+
+	for _, col := range servicePortColumns {
+		col := col
+		set := setters[col.Name]
+		err := c.snmp.Walk(col.OID, func(pdu gosnmp.SnmpPDU) error {
+			indexes, ok := extractLastOIDIndexes(pdu.Name, col.OID, 3)
+			if !ok {
+				return nil
+			}
+
+			key := portKey{pon: indexes[0], ont: indexes[1], id: indexes[2]}
+			sp, exists := byKey[key]
+			if !exists {
+				sp = &ServicePort{PONPortIndex: key.pon, ONTID: key.ont, ID: key.id}
+				byKey[key] = sp
+			}
+			set(pduToInt(pdu), sp)
+			return nil
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to walk ONT OID %s: %w", localBaseOID, err)
+			return nil, fmt.Errorf("failed to walk service-port OID %s: %w", col.OID, err)
 		}
 	}
 
-	onts := make([]*ONTMetrics, 0, len(ontsByKey))
-	for _, ont := range ontsByKey {
-		onts = append(onts, ont)
+	ports := make([]*ServicePort, 0, len(byKey))
+	for _, sp := range byKey {
+		ports = append(ports, sp)
 	}
+	sort.Slice(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if a.PONPortIndex != b.PONPortIndex {
+			return a.PONPortIndex < b.PONPortIndex
+		}
+		if a.ONTID != b.ONTID {
+			return a.ONTID < b.ONTID
+		}
+		return a.ID < b.ID
+	})
 
-	return onts, nil
+	return ports, nil
 }
 
 // GetAllONTMetrics retrieves metrics for all ONTs across all PON ports.
@@ -381,6 +717,44 @@ func pduToInt(pdu gosnmp.SnmpPDU) int {
 	}
 }
 
+// ErrUnexpectedType is returned for a column value whose SNMP type does not
+// match the column, e.g. an OctetString where an Integer is expected.
+var ErrUnexpectedType = errors.New("unexpected value type")
+
+// unexpectedType describes a value pdu holds that its column cannot use.
+func unexpectedType(pdu gosnmp.SnmpPDU) error {
+	return fmt.Errorf("%w %s", ErrUnexpectedType, pdu.Type)
+}
+
+// pduIntValue extracts an integer like pduToInt, but fails with
+// ErrUnexpectedType instead of returning 0 for a non-integer value. Decimal
+// text is accepted, as some firmware reports numbers as OctetString.
+func pduIntValue(pdu gosnmp.SnmpPDU) (int, error) {
+	switch v := pdu.Value.(type) {
+	case int, uint, uint32, uint64, int64:
+		return pduToInt(pdu), nil
+	case []byte:
+		if n, err := strconv.Atoi(strings.TrimSpace(string(v))); err == nil {
+			return n, nil
+		}
+		return 0, fmt.Errorf("%w %s %q", ErrUnexpectedType, pdu.Type, decodeOctetString(v))
+	default:
+		return 0, unexpectedType(pdu)
+	}
+}
+
+// intSetter adapts a card field setter to integer columns.
+func intSetter(set func(v int, card *CardMetrics)) func(pdu gosnmp.SnmpPDU, card *CardMetrics) error {
+	return func(pdu gosnmp.SnmpPDU, card *CardMetrics) error {
+		v, err := pduIntValue(pdu)
+		if err != nil {
+			return err
+		}
+		set(v, card)
+		return nil
+	}
+}
+
 // pduToUint32 extracts a uint32 value from a gosnmp PDU (used for TimeTicks).
 func pduToUint32(pdu gosnmp.SnmpPDU) uint32 {
 	switch v := pdu.Value.(type) {
@@ -421,6 +795,30 @@ func extractLastOIDIndex(oid, baseOID string) int {
 	return index
 }
 
+// parseONTIndex returns the PON port ifIndex and ONT ID of an ONT table row.
+// Most firmware indexes the table by one packed value with the ONT ID in the
+// low byte, e.g. 0x10010101 is ONT 1 on PON port 0x10010100; some use
+// <PON ifIndex>.<ONT ID> like the config table.
+func parseONTIndex(oid, baseOID string) (pon, ontID int, ok bool) {
+	suffix, ok := strings.CutPrefix(strings.TrimPrefix(oid, "."), strings.TrimPrefix(baseOID, ".")+".")
+	if !ok {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(suffix, ".")
+	if len(parts) > 2 {
+		return 0, 0, false
+	}
+	indexes, ok := extractLastOIDIndexes(oid, baseOID, len(parts))
+	if !ok {
+		return 0, 0, false
+	}
+	if len(indexes) == 2 {
+		return indexes[0], indexes[1], true
+	}
+	return indexes[0] &^ ontIDMask, indexes[0] & ontIDMask, true
+}
+
 // extractTwoLastOIDIndexes extracts the last two numeric indexes from an OID.
 // For example: "...base.2.5" returns (2, 5).
 func extractTwoLastOIDIndexes(oid, baseOID string) (int, int) {
@@ -450,6 +848,30 @@ func extractTwoLastOIDIndexes(oid, baseOID string) (int, int) {
 	return first, second
 }
 
+// extractLastOIDIndexes returns the last n numeric indexes of an OID under
+// baseOID. For example: "...base.2.5.1" with n = 3 returns [2 5 1].
+func extractLastOIDIndexes(oid, baseOID string, n int) ([]int, bool) {
+	suffix, ok := strings.CutPrefix(strings.TrimPrefix(oid, "."), strings.TrimPrefix(baseOID, ".")+".")
+	if !ok {
+		return nil, false
+	}
+
+	parts := strings.Split(suffix, ".")
+	if len(parts) < n {
+		return nil, false
+	}
+
+	indexes := make([]int, n)
+	for i, part := range parts[len(parts)-n:] {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return nil, false
+		}
+		indexes[i] = v
+	}
+	return indexes, true
+}
+
 // formatSerialNumber converts a raw ONT serial number byte slice to a
 // human-readable hex string (e.g., "ZTEG12345678").
 func formatSerialNumber(raw []byte) string {
@@ -466,3 +888,41 @@ func formatSerialNumber(raw []byte) string {
 
 	return fmt.Sprintf("%X", raw)
 }
+
+// decodeOctetString converts a configured text value to a string, trimming NUL
+// padding and whitespace. Values that are not valid UTF-8 are decoded as Latin-1.
+func decodeOctetString(raw []byte) string {
+	raw = []byte(strings.TrimRight(string(raw), "\x00"))
+	if utf8.Valid(raw) {
+		return strings.TrimSpace(string(raw))
+	}
+
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+// pduToText decodes a configured text column such as a profile name. ONTs
+// without a value report an empty string, which is kept as "".
+func pduToText(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return decodeOctetString(v)
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return ""
+	}
+}
+
+// decodeSerialNumber decodes an ONT serial number. The OLT reports the 8 raw
+// bytes of the GPON serial (vendor ID + vendor-specific part); some firmware
+// returns the already formatted text instead.
+func decodeSerialNumber(raw []byte) string {
+	if len(raw) == 8 {
+		return formatSerialNumber(raw)
+	}
+	return decodeOctetString(raw)
+}
@@ -1,14 +1,23 @@
 package zte_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/crypto"
 	devicemodel "github.com/yourorg/nms-go/internal/device/model"
 	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
 	"github.com/yourorg/nms-go/internal/worker/protocols/snmp/zte"
@@ -21,9 +30,13 @@ type mockSNMPClient struct {
 	getErr      error
 	walkResults map[string][]gosnmp.SnmpPDU
 	walkErr     error
+	walkErrs    map[string]error // per-OID walk errors
+	delivered   int              // PDUs handed to walk callbacks
+	community   string           // community passed to Connect
 }
 
-func (m *mockSNMPClient) Connect(_ context.Context, _, _ string, _ gosnmp.SnmpVersion, _ time.Duration) error {
+func (m *mockSNMPClient) Connect(_ context.Context, _, community string, _ gosnmp.SnmpVersion, _ time.Duration) error {
+	m.community = community
 	return m.connectErr
 }
 
@@ -37,9 +50,13 @@ func (m *mockSNMPClient) Walk(oid string, fn gosnmp.WalkFunc) error {
 	if m.walkErr != nil {
 		return m.walkErr
 	}
+	if err := m.walkErrs[oid]; err != nil {
+		return err
+	}
 
 	if pdus, ok := m.walkResults[oid]; ok {
 		for _, pdu := range pdus {
+			m.delivered++
 			if err := fn(pdu); err != nil {
 				return err
 			}
@@ -234,27 +251,314 @@ func TestGetPONPortMetrics_WalkError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to walk PON port OID")
 }
 
+func TestGetPONPortMetrics_PartialColumnFailure(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEPONPortOperStatus: {pduInt(zte.OIDZTEPONPortOperStatus+".1", 1)},
+			zte.OIDZTEPONPortTxPower:    {pduInt(zte.OIDZTEPONPortTxPower+".1", 25)},
+			zte.OIDZTEPONPortONTCount:   {pduInt(zte.OIDZTEPONPortONTCount+".1", 32)},
+		},
+		walkErrs: map[string]error{
+			zte.OIDZTEPONPortRxPower: fmt.Errorf("request timeout"),
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	ports, err := client.GetPONPortMetrics(context.Background())
+
+	var partial *zte.PartialError
+	require.ErrorAs(t, err, &partial)
+	require.Len(t, partial.Columns, 1)
+	assert.Equal(t, "rx_power", partial.Columns[0].Column)
+	assert.Equal(t, zte.OIDZTEPONPortRxPower, partial.Columns[0].OID)
+	assert.Contains(t, partial.Warnings()[0], "request timeout")
+
+	// The columns that were walked are still returned.
+	require.Len(t, ports, 1)
+	assert.Equal(t, zte.PONPortStatusUp, ports[0].OperStatus)
+	assert.InDelta(t, 2.5, ports[0].TxPowerDBm, 0.01)
+	assert.Equal(t, 32, ports[0].ONTCount)
+	assert.Zero(t, ports[0].RxPowerDBm)
+}
+
+// --- GetCards Tests ---
+
+func TestGetCards_MultipleSlots(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTECardType: {
+				pduOctetString(zte.OIDZTECardType+".4", []byte("GTGO")),
+				pduOctetString(zte.OIDZTECardType+".3", []byte("SMXA\x00")),
+				pduOctetString(zte.OIDZTECardType+".2", []byte("GTGO")),
+			},
+			zte.OIDZTECardStatus: {
+				pduInt(zte.OIDZTECardStatus+".2", int(zte.CardStatusInService)),
+				pduInt(zte.OIDZTECardStatus+".3", int(zte.CardStatusInService)),
+				pduInt(zte.OIDZTECardStatus+".4", int(zte.CardStatusHWOffline)),
+			},
+			zte.OIDZTECardCPUUsage: {
+				pduInt(zte.OIDZTECardCPUUsage+".2", 22),
+				pduInt(zte.OIDZTECardCPUUsage+".3", 17),
+			},
+			zte.OIDZTECardTemperature: {
+				pduInt(zte.OIDZTECardTemperature+".2", 43),
+				pduInt(zte.OIDZTECardTemperature+".3", 42),
+			},
+			zte.OIDZTECardMemoryUsage: {
+				pduInt(zte.OIDZTECardMemoryUsage+".2", 41),
+				pduInt(zte.OIDZTECardMemoryUsage+".3", 39),
+			},
+			zte.OIDZTECardMemoryTotal: {
+				pduInt(zte.OIDZTECardMemoryTotal+".2", 512),
+				pduInt(zte.OIDZTECardMemoryTotal+".3", 2048),
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	cards, err := client.GetCards(context.Background())
+	require.NoError(t, err)
+	require.Len(t, cards, 3)
+
+	assert.Equal(t, []int{2, 3, 4}, []int{cards[0].Slot, cards[1].Slot, cards[2].Slot}, "ordered by slot")
+
+	line := cards[0]
+	assert.Equal(t, "GTGO", line.Type)
+	assert.Equal(t, zte.CardStatusInService, line.Status)
+	assert.Equal(t, 22.0, line.CPUUsagePercent)
+	assert.Equal(t, 43.0, line.TemperatureCelsius)
+	assert.Equal(t, 41.0, line.MemoryUsagePercent)
+	assert.Equal(t, int64(512*1024), line.MemoryTotalKB)
+
+	control := cards[1]
+	assert.Equal(t, "SMXA", control.Type)
+	assert.Equal(t, int64(2048*1024), control.MemoryTotalKB)
+
+	offline := cards[2]
+	assert.Equal(t, "hw_offline", offline.Status.String())
+	assert.Zero(t, offline.CPUUsagePercent)
+}
+
+func TestGetCards_PartialColumnFailure(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTECardType: {pduOctetString(zte.OIDZTECardType+".2", []byte("GTGO"))},
+		},
+		walkErrs: map[string]error{zte.OIDZTECardTemperature: fmt.Errorf("request timeout")},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	cards, err := client.GetCards(context.Background())
+
+	var partial *zte.PartialError
+	require.ErrorAs(t, err, &partial)
+	require.Len(t, partial.Columns, 1)
+	assert.Equal(t, "temperature", partial.Columns[0].Column)
+	require.Len(t, cards, 1)
+	assert.Equal(t, "GTGO", cards[0].Type)
+}
+
+func TestGetCards_WrongTypedValues(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTECardType: {
+				pduOctetString(zte.OIDZTECardType+".2", []byte("GTGO")),
+				pduInt(zte.OIDZTECardType+".3", 7),
+			},
+			zte.OIDZTECardCPUUsage: {
+				pduOctetString(zte.OIDZTECardCPUUsage+".2", []byte("n/a")),
+				pduOctetString(zte.OIDZTECardCPUUsage+".3", []byte(" 17 ")),
+			},
+			zte.OIDZTECardTemperature: {
+				pduInt(zte.OIDZTECardTemperature+".2", 43),
+				{Name: zte.OIDZTECardTemperature + ".3", Type: gosnmp.Null, Value: nil},
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	cards, err := client.GetCards(context.Background())
+
+	var partial *zte.PartialError
+	require.ErrorAs(t, err, &partial)
+	require.Len(t, partial.Columns, 3)
+	for _, col := range partial.Columns {
+		assert.ErrorIs(t, col, zte.ErrUnexpectedType)
+	}
+	assert.Equal(t, "type", partial.Columns[0].Column)
+	assert.Contains(t, partial.Columns[0].Error(), "slot 3: unexpected value type Integer")
+	assert.Equal(t, "cpu_usage", partial.Columns[1].Column)
+	assert.Contains(t, partial.Columns[1].Error(), `slot 2: unexpected value type OctetString "n/a"`)
+	assert.Equal(t, "temperature", partial.Columns[2].Column)
+	assert.Contains(t, partial.Columns[2].Error(), "slot 3: unexpected value type Null")
+
+	require.Len(t, cards, 2)
+	assert.Equal(t, "GTGO", cards[0].Type)
+	assert.Zero(t, cards[0].CPUUsagePercent)
+	assert.Equal(t, 43.0, cards[0].TemperatureCelsius)
+	assert.Empty(t, cards[1].Type)
+	assert.Equal(t, 17.0, cards[1].CPUUsagePercent, "decimal text is accepted")
+}
+
+func TestGetSystemMetrics_SkipsWrongTypedValues(t *testing.T) {
+	mock := &mockSNMPClient{
+		getPacket: &gosnmp.SnmpPacket{},
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTECardCPUUsage: {
+				pduOctetString(zte.OIDZTECardCPUUsage+".1", []byte("busy")),
+				pduInt(zte.OIDZTECardCPUUsage+".2", 30),
+			},
+		},
+	}
+
+	var logs bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	defer log.SetOutput(prev)
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	metrics, err := client.GetSystemMetrics(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 30.0, metrics.CPUUsagePercent)
+	assert.Contains(t, logs.String(), `unexpected value type OctetString "busy"`)
+}
+
+// cloningSNMPClient hands out clones that walk the same data, recording how
+// many sessions were opened and how many walks overlapped.
+type cloningSNMPClient struct {
+	mockSNMPClient
+	walks *cloneWalks
+}
+
+type cloneWalks struct {
+	results  map[string][]gosnmp.SnmpPDU
+	clones   atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	closed   atomic.Int32
+}
+
+func (m *cloningSNMPClient) Walk(oid string, fn gosnmp.WalkFunc) error {
+	n := m.walks.inFlight.Add(1)
+	defer m.walks.inFlight.Add(-1)
+	for {
+		peak := m.walks.peak.Load()
+		if n <= peak || m.walks.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	for _, pdu := range m.walks.results[oid] {
+		if err := fn(pdu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *cloningSNMPClient) Clone() (snmpclient.SNMPClient, error) {
+	m.walks.clones.Add(1)
+	return &cloningSNMPClient{walks: m.walks}, nil
+}
+
+func (m *cloningSNMPClient) Disconnect() error {
+	m.walks.closed.Add(1)
+	return nil
+}
+
+func TestGetSystemMetrics_ConcurrentWalksMatchSequential(t *testing.T) {
+	results := map[string][]gosnmp.SnmpPDU{
+		zte.OIDZTECardCPUUsage: {
+			pduInt(zte.OIDZTECardCPUUsage+".1", 45),
+			pduInt(zte.OIDZTECardCPUUsage+".2", 80),
+			pduInt(zte.OIDZTECardCPUUsage+".3", 12),
+		},
+		zte.OIDZTECardTemperature: {
+			pduInt(zte.OIDZTECardTemperature+".1", 51),
+			pduInt(zte.OIDZTECardTemperature+".2", 38),
+		},
+		zte.OIDZTECardMemoryUsage: {
+			pduInt(zte.OIDZTECardMemoryUsage+".1", 20),
+			pduInt(zte.OIDZTECardMemoryUsage+".2", 64),
+		},
+		zte.OIDZTECardMemoryTotal: {
+			pduInt(zte.OIDZTECardMemoryTotal+".1", 512),
+			pduInt(zte.OIDZTECardMemoryTotal+".2", 2048),
+		},
+	}
+
+	// mockSNMPClient cannot clone, so its columns are walked one by one.
+	sequential := zte.NewZTEOLTClientForTest(&mockSNMPClient{getPacket: &gosnmp.SnmpPacket{}, walkResults: results}, 10*time.Second)
+	sequential.SetDevice(newTestDevice())
+	want, err := sequential.GetSystemMetrics(context.Background())
+	require.NoError(t, err)
+
+	walks := &cloneWalks{results: results}
+	mock := &cloningSNMPClient{mockSNMPClient: mockSNMPClient{getPacket: &gosnmp.SnmpPacket{}}, walks: walks}
+	concurrent := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	concurrent.SetDevice(newTestDevice())
+	got, err := concurrent.GetSystemMetrics(context.Background())
+	require.NoError(t, err)
+
+	got.Timestamp = want.Timestamp
+	assert.Equal(t, want, got)
+	assert.Equal(t, 80.0, got.CPUUsagePercent)
+	assert.Equal(t, 51.0, got.TemperatureCelsius)
+	assert.Equal(t, int64(2048*1024), got.MemoryTotalKB)
+
+	assert.Greater(t, walks.peak.Load(), int32(1), "columns should be walked concurrently")
+	assert.Equal(t, int32(3), walks.clones.Load(), "one session per column beyond the first")
+	assert.Equal(t, walks.clones.Load(), walks.closed.Load(), "cloned sessions are closed")
+}
+
+func TestGetCards_WalkError(t *testing.T) {
+	mock := &mockSNMPClient{walkErr: fmt.Errorf("snmp walk timeout")}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	_, err := client.GetCards(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to walk card OID")
+}
+
 // --- GetONTMetrics Tests ---
 
 func TestGetONTMetrics_Success(t *testing.T) {
+	// ONT table rows are packed indexes, state table rows <PON ifIndex>.<ONT
+	// ID>: ONTs 1 and 2 on PON ifIndex 0x10010100
 	mock := &mockSNMPClient{
 		walkResults: map[string][]gosnmp.SnmpPDU{
-			zte.OIDZTEONTSerialNumber: {
-				// OID suffix: .index
-				pduOctetString(zte.OIDZTEONTSerialNumber+".268435456", []byte("ZTEG12345678")),
-				pduOctetString(zte.OIDZTEONTSerialNumber+".268435457", []byte("HWTCABCDEF01")),
-			},
 			zte.OIDZTEONTOperStatus: {
-				pduInt(zte.OIDZTEONTOperStatus+".268435456", 1), // online
-				pduInt(zte.OIDZTEONTOperStatus+".268435457", 2), // offline
+				pduInt(zte.OIDZTEONTOperStatus+".268501248.1", 4), // working
+				pduInt(zte.OIDZTEONTOperStatus+".268501248.2", 2), // LOS
 			},
 			zte.OIDZTEONTRxPower: {
-				pduInt(zte.OIDZTEONTRxPower+".268435456", -185), // -18.5 dBm
-				pduInt(zte.OIDZTEONTRxPower+".268435457", -990), // -99.0 dBm (LOS)
+				pduInt(zte.OIDZTEONTRxPower+".268501249", -185), // -18.5 dBm
+				pduInt(zte.OIDZTEONTRxPower+".268501250", -990), // -99.0 dBm (LOS)
+			},
+			zte.OIDZTEONTInfoName: {
+				pduOctetString(zte.OIDZTEONTInfoName+".268501248.1", []byte("ONT-1")),
+			},
+			zte.OIDZTEONTInfoDescription: {
+				pduOctetString(zte.OIDZTEONTInfoDescription+".268501248.1", []byte("ACC-10023 Budi\x00")),
+			},
+			zte.OIDZTEONTInfoSerialNumber: {
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+".268501248.1", []byte{'Z', 'T', 'E', 'G', 0xC1, 0x23, 0x45, 0x67}),
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+".268501248.2", []byte("HWTC1A2B3C4D")),
 			},
-			zte.OIDZTEONTTxPower:  {},
-			zte.OIDZTEONTDistance: {},
-			// Description is same OID as SerialNumber now, implicitly handled
 		},
 	}
 
@@ -262,34 +566,248 @@ func TestGetONTMetrics_Success(t *testing.T) {
 	client.SetDevice(newTestDevice())
 
 	onts, err := client.GetONTMetrics(context.Background(), 0) // Pass 0 to get all
-
 	require.NoError(t, err)
 	require.Len(t, onts, 2)
 
-	// Find ONT 1 (Index 268435456)
-	var ont1 *zte.ONTMetrics
+	byID := make(map[int]*zte.ONTMetrics)
 	for _, o := range onts {
-		if o.ONTIndex == 268435456&0xFF { // derived index
-			ont1 = o
-			break
-		}
+		byID[o.ONTID] = o
 	}
+	ont1 := byID[1]
+	require.NotNil(t, ont1)
+	assert.Equal(t, 268501248, ont1.PONPortIndex)
+	assert.Equal(t, 268501249, ont1.ONTIndex)
+	assert.Equal(t, 1, ont1.Slot)
+	assert.Equal(t, 1, ont1.PONPort)
+	assert.Equal(t, zte.ONTStatusWorking, ont1.OperStatus)
+	assert.InDelta(t, -18.5, ont1.RxPowerDBm, 0.01)
+	assert.Equal(t, "ZTEGC1234567", ont1.SerialNumber)
+	assert.Equal(t, "ONT-1", ont1.Name)
+	assert.Equal(t, "ACC-10023 Budi", ont1.Description)
 
-	// Just check if we found something
-	if ont1 == nil {
-		ont1 = onts[0]
+	require.NotNil(t, byID[2])
+	assert.Equal(t, "HWTC1A2B3C4D", byID[2].SerialNumber, "already formatted serials are kept")
+	assert.Empty(t, byID[2].Name)
+}
+
+func TestGetONTMetrics_PartialColumnFailure(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {pduInt(zte.OIDZTEONTOperStatus+".268435456", 4)},
+			zte.OIDZTEONTRxPower:    {pduInt(zte.OIDZTEONTRxPower+".268435456", -185)},
+		},
+		walkErrs: map[string]error{
+			zte.OIDZTEONTDistance:      fmt.Errorf("request timeout"),
+			zte.OIDZTEONTLastDownCause: fmt.Errorf("no such object"),
+		},
 	}
 
-	require.NotNil(t, ont1)
-	assert.Equal(t, zte.ONTStatusOnline, ont1.OperStatus)
-	assert.InDelta(t, -18.5, ont1.RxPowerDBm, 0.01)
-	// assert.Equal(t, 1, ont1.PONPortIndex) // Logic changed, skipping strict check
-	// assert.Contains(t, ont1.SerialNumber, "ZTEG") // Placeholder is hex of index now
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+
+	var partial *zte.PartialError
+	require.ErrorAs(t, err, &partial)
+	var columns []string
+	for _, col := range partial.Columns {
+		columns = append(columns, col.Column)
+	}
+	assert.Equal(t, []string{"distance", "last_down_cause"}, columns)
+
+	require.Len(t, onts, 1)
+	assert.Equal(t, zte.ONTStatusWorking, onts[0].OperStatus)
+	assert.InDelta(t, -18.5, onts[0].RxPowerDBm, 0.01)
+}
+
+func TestGetONTMetrics_StopsAtONTLimit(t *testing.T) {
+	var status, rx []gosnmp.SnmpPDU
+	for i := 0; i < 1000; i++ {
+		index := 268435456 + i
+		status = append(status, pduInt(fmt.Sprintf("%s.%d", zte.OIDZTEONTOperStatus, index), 4))
+		rx = append(rx, pduInt(fmt.Sprintf("%s.%d", zte.OIDZTEONTRxPower, index), -185))
+	}
+	mock := &mockSNMPClient{walkResults: map[string][]gosnmp.SnmpPDU{
+		zte.OIDZTEONTOperStatus: status,
+		zte.OIDZTEONTRxPower:    rx,
+	}}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+	client.SetMaxONTs(3)
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+
+	require.ErrorIs(t, err, zte.ErrONTLimitReached)
+	var partial *zte.PartialError
+	assert.False(t, errors.As(err, &partial), "stopping at the limit is not a column failure")
+
+	require.Len(t, onts, 3)
+	for _, o := range onts {
+		assert.Equal(t, zte.ONTStatusWorking, o.OperStatus)
+		assert.InDelta(t, -18.5, o.RxPowerDBm, 0.01, "later columns still fill collected ONTs")
+	}
+
+	// Each column stops at the first row past the limit instead of walking all 1000.
+	assert.Equal(t, 2*(3+1), mock.delivered)
+}
+
+func TestGetONTMetrics_NoLimitByDefault(t *testing.T) {
+	var status []gosnmp.SnmpPDU
+	for i := 0; i < 100; i++ {
+		status = append(status, pduInt(fmt.Sprintf("%s.%d", zte.OIDZTEONTOperStatus, 268435456+i), 4))
+	}
+	mock := &mockSNMPClient{walkResults: map[string][]gosnmp.SnmpPDU{zte.OIDZTEONTOperStatus: status}}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Len(t, onts, 100)
 }
 
-func TestGetONTMetrics_FilterByPONPort(t *testing.T) {
-	// Filter logic is currently disabled in client.go due to unknown mapping
-	// skipping this test or making it a no-opPass
+func TestGetONTMetrics_AllColumnsFail(t *testing.T) {
+	mock := &mockSNMPClient{walkErr: fmt.Errorf("snmp walk timeout")}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+
+	require.Error(t, err)
+	var partial *zte.PartialError
+	assert.False(t, errors.As(err, &partial))
+	assert.Nil(t, onts)
+}
+
+func TestMetricsShareCollectionTimestamp(t *testing.T) {
+	mock := &mockSNMPClient{
+		getPacket: &gosnmp.SnmpPacket{},
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTECardCPUUsage: {pduInt(zte.OIDZTECardCPUUsage+".1.1", 20)},
+			zte.OIDZTEPONPortOperStatus: {
+				pduInt(zte.OIDZTEPONPortOperStatus+".1", 1),
+				pduInt(zte.OIDZTEPONPortOperStatus+".2", 1),
+			},
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268435456", 4),
+				pduInt(zte.OIDZTEONTOperStatus+".268435457", 4),
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	require.NoError(t, client.Connect(context.Background(), newTestDevice()))
+
+	system, err := client.GetSystemMetrics(context.Background())
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	ports, err := client.GetPONPortMetrics(context.Background())
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+	require.NoError(t, err)
+
+	collectedAt := system.Timestamp
+	require.False(t, collectedAt.IsZero())
+	for _, p := range ports {
+		assert.True(t, collectedAt.Equal(p.Timestamp), "PON port %d", p.PortIndex)
+	}
+	for _, o := range onts {
+		assert.True(t, collectedAt.Equal(o.Timestamp), "ONT %d", o.ONTIndex)
+	}
+
+	// A new session starts a new collection.
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, client.Connect(context.Background(), newTestDevice()))
+	next, err := client.GetSystemMetrics(context.Background())
+	require.NoError(t, err)
+	assert.True(t, next.Timestamp.After(collectedAt))
+}
+
+func TestGetONTMetrics_Distance(t *testing.T) {
+	tests := []struct {
+		name        string
+		unit        zte.DistanceUnit
+		raw         int
+		wantMeters  int
+		implausible bool
+	}{
+		{"meters", zte.DistanceUnitMeters, 7000, 7000, false},
+		{"decimeters", zte.DistanceUnitDecimeters, 70004, 7000, false},
+		{"centimeters", zte.DistanceUnitCentimeters, 1250050, 12501, false},
+		{"at maximum reach", zte.DistanceUnitMeters, 40000, 40000, false},
+		{"decimeters read as meters", zte.DistanceUnitMeters, 70000, 70000, true},
+		{"negative", zte.DistanceUnitMeters, -5, -5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockSNMPClient{walkResults: map[string][]gosnmp.SnmpPDU{
+				zte.OIDZTEONTDistance: {pduInt(zte.OIDZTEONTDistance+".268435456", tt.raw)},
+			}}
+			client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+			client.SetDevice(newTestDevice())
+			client.SetDistanceUnit(tt.unit)
+
+			onts, err := client.GetONTMetrics(context.Background(), 0)
+			require.NoError(t, err)
+			require.Len(t, onts, 1)
+			assert.Equal(t, tt.wantMeters, onts[0].DistanceMeters)
+			assert.Equal(t, tt.implausible, onts[0].DistanceImplausible)
+		})
+	}
+}
+
+func TestParseDistanceUnit(t *testing.T) {
+	for in, want := range map[string]zte.DistanceUnit{
+		"":   zte.DistanceUnitMeters,
+		"m":  zte.DistanceUnitMeters,
+		"DM": zte.DistanceUnitDecimeters,
+		"cm": zte.DistanceUnitCentimeters,
+	} {
+		got, err := zte.ParseDistanceUnit(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := zte.ParseDistanceUnit("feet")
+	assert.Error(t, err)
+}
+
+func TestGetONTMetrics_FilterByPONPort_TwoPartIndex(t *testing.T) {
+	// Some firmware indexes the ONT table by <PON ifIndex>.<ONT ID>
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501248.1", 4),
+				pduInt(zte.OIDZTEONTOperStatus+".268566784.3", 4), // slot 2, port 1
+			},
+			zte.OIDZTEONTInfoSerialNumber: {
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+".268566784.3", []byte("ZTEG00000003")),
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 268566784)
+	require.NoError(t, err)
+	require.Len(t, onts, 1)
+	assert.Equal(t, 268566784, onts[0].PONPortIndex)
+	assert.Equal(t, 268566787, onts[0].ONTIndex)
+	assert.Equal(t, 2, onts[0].Slot)
+	assert.Equal(t, 1, onts[0].PONPort)
+	assert.Equal(t, 3, onts[0].ONTID)
+	assert.Equal(t, "ZTEG00000003", onts[0].SerialNumber)
+}
+
+func TestPONLocation(t *testing.T) {
+	slot, port := zte.PONLocation(0x10020300)
+	assert.Equal(t, 2, slot)
+	assert.Equal(t, 3, port)
 }
 
 // --- Connect Tests ---
@@ -322,6 +840,25 @@ func TestConnect_SNMPError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestConnect_DecryptsStoredCommunity(t *testing.T) {
+	c, err := crypto.NewCipherFromKey("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	crypto.SetDefault(c)
+	t.Cleanup(func() { crypto.SetDefault(nil) })
+
+	device := newTestDevice()
+	device.Credentials.SNMPCommunity, err = c.Encrypt("s3cret")
+	require.NoError(t, err)
+
+	mock := &mockSNMPClient{}
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	require.NoError(t, client.Connect(context.Background(), device))
+	assert.Equal(t, "s3cret", mock.community)
+
+	crypto.SetDefault(nil)
+	assert.ErrorIs(t, client.Connect(context.Background(), device), crypto.ErrNoKey)
+}
+
 // --- Status String Tests ---
 
 func TestPONPortStatus_String(t *testing.T) {
@@ -331,8 +868,336 @@ func TestPONPortStatus_String(t *testing.T) {
 }
 
 func TestONTStatus_String(t *testing.T) {
-	assert.Equal(t, "online", zte.ONTStatusOnline.String())
-	assert.Equal(t, "offline", zte.ONTStatusOffline.String())
-	assert.Equal(t, "unregistered", zte.ONTStatusUnreg.String())
-	assert.Equal(t, "unknown", zte.ONTStatusUnknown.String())
+	tests := []struct {
+		code   int
+		want   string
+		online bool
+	}{
+		{code: 0, want: "unknown"},
+		{code: 1, want: "logging"},
+		{code: 2, want: "los"},
+		{code: 3, want: "sync_mib"},
+		{code: 4, want: "working", online: true},
+		{code: 5, want: "dying_gasp"},
+		{code: 6, want: "auth_failed"},
+		{code: 7, want: "offline"},
+		{code: 30, want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			status := zte.ONTStatus(tt.code)
+			assert.Equal(t, tt.want, status.String())
+			assert.Equal(t, tt.online, status.IsOnline())
+		})
+	}
+}
+
+// --- GetONTInfo Tests ---
+
+func TestGetONTInfo_DecodesDescriptionAndSerial(t *testing.T) {
+	const pon = ".268501248"
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTInfoName: {
+				pduOctetString(zte.OIDZTEONTInfoName+pon+".1", []byte("ONT-1")),
+			},
+			zte.OIDZTEONTInfoDescription: {
+				pduOctetString(zte.OIDZTEONTInfoDescription+pon+".1", []byte("ACC-10023 Budi\x00\x00")),
+				pduOctetString(zte.OIDZTEONTInfoDescription+pon+".2", []byte{'C', 'a', 'f', 0xe9}), // Latin-1
+			},
+			zte.OIDZTEONTInfoSerialNumber: {
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+pon+".1", []byte{'Z', 'T', 'E', 'G', 0xC1, 0x23, 0x45, 0x67}),
+				pduOctetString(zte.OIDZTEONTInfoSerialNumber+pon+".2", []byte("HWTC1A2B3C4D")),
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	infos, err := client.GetONTInfo(context.Background())
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+
+	assert.Equal(t, &zte.ONTInfo{
+		PONPortIndex: 268501248,
+		ONTID:        1,
+		Name:         "ONT-1",
+		Description:  "ACC-10023 Budi",
+		SerialNumber: "ZTEGC1234567",
+	}, infos[0])
+	assert.Equal(t, "Café", infos[1].Description)
+	assert.Equal(t, "HWTC1A2B3C4D", infos[1].SerialNumber)
+}
+
+func TestGetONTInfo_WalkError(t *testing.T) {
+	mock := &mockSNMPClient{walkErr: fmt.Errorf("timeout")}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	_, err := client.GetONTInfo(context.Background())
+	require.Error(t, err)
+}
+
+func TestGetONTMetrics_LastDownCause(t *testing.T) {
+	// ONT table rows are packed indexes: PON ifIndex 0x10010100 with ONT IDs 1 and 2
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", 7),
+				pduInt(zte.OIDZTEONTOperStatus+".268501250", 2),
+			},
+			zte.OIDZTEONTLastDownCause: {
+				pduInt(zte.OIDZTEONTLastDownCause+".268501248.1", 9),
+				pduInt(zte.OIDZTEONTLastDownCause+".268501248.2", 2),
+				pduInt(zte.OIDZTEONTLastDownCause+".268501248.3", 8), // not in ONT table
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, onts, 2)
+
+	causes := make(map[int]zte.ONTDownCause)
+	for _, o := range onts {
+		causes[o.ONTIndex] = o.LastDownCause
+	}
+	assert.Equal(t, zte.ONTDownCauseDyingGasp, causes[268501249])
+	assert.Equal(t, zte.ONTDownCauseLOS, causes[268501250])
+}
+
+func TestGetONTMetrics_FiltersByPONPort(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", 1), // 0x10010101
+				pduInt(zte.OIDZTEONTOperStatus+".268501250", 1), // 0x10010102
+				pduInt(zte.OIDZTEONTOperStatus+".268501505", 1), // 0x10010201
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	all, err := client.GetONTMetrics(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	onts, err := client.GetONTMetrics(context.Background(), 268501504)
+	require.NoError(t, err)
+	require.Len(t, onts, 1)
+	assert.Equal(t, 268501504, onts[0].PONPortIndex)
+	assert.Equal(t, 268501505, onts[0].ONTIndex)
+}
+
+func TestGetONTMetrics_Profiles(t *testing.T) {
+	// PON ifIndex 0x10010100: ONT 1 has both profiles, ONT 2 reports empty
+	// names and ONT 3 has no rows in the config table at all.
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {
+				pduInt(zte.OIDZTEONTOperStatus+".268501249", 4),
+				pduInt(zte.OIDZTEONTOperStatus+".268501250", 4),
+				pduInt(zte.OIDZTEONTOperStatus+".268501251", 4),
+			},
+			zte.OIDZTEONTLineProfile: {
+				pduOctetString(zte.OIDZTEONTLineProfile+".268501248.1", []byte("LP-100M ")),
+				pduOctetString(zte.OIDZTEONTLineProfile+".268501248.2", []byte{}),
+			},
+			zte.OIDZTEONTServiceProfile: {
+				pduOctetString(zte.OIDZTEONTServiceProfile+".268501248.1", []byte("SP-HSI-VOIP\x00")),
+				pduOctetString(zte.OIDZTEONTServiceProfile+".268501248.2", []byte{}),
+			},
+		},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, onts, 3)
+
+	byIndex := make(map[int]*zte.ONTMetrics)
+	for _, o := range onts {
+		byIndex[o.ONTIndex] = o
+	}
+	assert.Equal(t, "LP-100M", byIndex[268501249].LineProfile)
+	assert.Equal(t, "SP-HSI-VOIP", byIndex[268501249].ServiceProfile)
+	for _, index := range []int{268501250, 268501251} {
+		assert.Empty(t, byIndex[index].LineProfile, "ONT %d", index)
+		assert.Empty(t, byIndex[index].ServiceProfile, "ONT %d", index)
+	}
+}
+
+func TestGetONTMetrics_ProfileWalkFails(t *testing.T) {
+	mock := &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEONTOperStatus: {pduInt(zte.OIDZTEONTOperStatus+".268501249", 4)},
+		},
+		walkErrs: map[string]error{zte.OIDZTEONTServiceProfile: errors.New("no such object")},
+	}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+	require.Len(t, onts, 1)
+
+	var partial *zte.PartialError
+	require.ErrorAs(t, err, &partial)
+	require.Len(t, partial.Columns, 1)
+	assert.Equal(t, "service_profile", partial.Columns[0].Column)
+}
+
+// --- GetServicePorts Tests ---
+
+func servicePortFixture() *mockSNMPClient {
+	// Two ONTs on PON ifIndex 268501248: ONT 1 has an untagged internet and a
+	// tagged VoIP service port, ONT 2 a single one.
+	row := func(oid, index string, value int) gosnmp.SnmpPDU {
+		return pduInt(oid+".268501248."+index, value)
+	}
+	return &mockSNMPClient{
+		walkResults: map[string][]gosnmp.SnmpPDU{
+			zte.OIDZTEServicePortVPort: {
+				row(zte.OIDZTEServicePortVPort, "2.1", 1),
+				row(zte.OIDZTEServicePortVPort, "1.2", 2),
+				row(zte.OIDZTEServicePortVPort, "1.1", 1),
+			},
+			zte.OIDZTEServicePortUserVLAN: {
+				row(zte.OIDZTEServicePortUserVLAN, "1.2", 200),
+			},
+			zte.OIDZTEServicePortVLAN: {
+				row(zte.OIDZTEServicePortVLAN, "1.1", 100),
+				row(zte.OIDZTEServicePortVLAN, "1.2", 200),
+				row(zte.OIDZTEServicePortVLAN, "2.1", 101),
+			},
+		},
+	}
+}
+
+func TestGetServicePorts_JoinsColumns(t *testing.T) {
+	client := zte.NewZTEOLTClientForTest(servicePortFixture(), 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	ports, err := client.GetServicePorts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ports, 3)
+
+	assert.Equal(t, &zte.ServicePort{PONPortIndex: 268501248, ONTID: 1, ID: 1, VPort: 1, UserVLAN: 0, VLAN: 100}, ports[0])
+	assert.Equal(t, &zte.ServicePort{PONPortIndex: 268501248, ONTID: 1, ID: 2, VPort: 2, UserVLAN: 200, VLAN: 200}, ports[1])
+	assert.Equal(t, &zte.ServicePort{PONPortIndex: 268501248, ONTID: 2, ID: 1, VPort: 1, UserVLAN: 0, VLAN: 101}, ports[2])
+}
+
+func TestGetServicePorts_WalkError(t *testing.T) {
+	mock := servicePortFixture()
+	mock.walkErrs = map[string]error{zte.OIDZTEServicePortVLAN: errors.New("timeout")}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	_, err := client.GetServicePorts(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), zte.OIDZTEServicePortVLAN)
+}
+
+func TestONTDownCause_String(t *testing.T) {
+	tests := map[int]string{
+		0:  "unknown",
+		1:  "unknown",
+		2:  "loss of signal (fiber cut or disconnected)",
+		3:  "loss of signal at ONT",
+		4:  "loss of frame at ONT",
+		5:  "signal fail at ONT",
+		6:  "loss of acknowledgement from ONT",
+		7:  "loss of PLOAM from ONT",
+		8:  "authentication failed",
+		9:  "dying gasp (power loss)",
+		10: "deactivated by OLT",
+		11: "deactivation failed",
+		12: "rebooted",
+		13: "shut down",
+		99: "unknown",
+	}
+
+	for code, want := range tests {
+		assert.Equal(t, want, zte.ONTDownCause(code).String(), "code %d", code)
+	}
+}
+
+func TestNormalizePowerDBm(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+
+	got := zte.NormalizePowerDBm(negZero)
+	require.NotNil(t, got)
+	assert.False(t, math.Signbit(*got), "negative zero is normalized to 0")
+
+	got = zte.NormalizePowerDBm(-18.5)
+	require.NotNil(t, got)
+	assert.Equal(t, -18.5, *got)
+
+	assert.Nil(t, zte.NormalizePowerDBm(math.NaN()))
+	assert.Nil(t, zte.NormalizePowerDBm(math.Inf(1)))
+	assert.Nil(t, zte.NormalizePowerDBm(math.Inf(-1)))
+}
+
+// loadWalk reads snmpwalk -On output into the walk results of a
+// mockSNMPClient, keyed by each of the given column OIDs.
+func loadWalk(t *testing.T, path string, columns ...string) map[string][]gosnmp.SnmpPDU {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	results := make(map[string][]gosnmp.SnmpPDU)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		name, value, ok := strings.Cut(line, " = INTEGER: ")
+		require.True(t, ok, "unsupported line %q", line)
+		n, err := strconv.Atoi(value)
+		require.NoError(t, err)
+		for _, col := range columns {
+			if strings.HasPrefix(name, "."+col+".") {
+				results[col] = append(results[col], pduInt(name, n))
+			}
+		}
+	}
+	return results
+}
+
+func TestGetONTMetrics_PhaseStateFromWalk(t *testing.T) {
+	// The ONT table also holds a .3 column of 30s, which is not a state
+	mock := &mockSNMPClient{walkResults: loadWalk(t, "testdata/c320_onu_state.walk",
+		zte.OIDZTEONTOperStatus, zte.OIDZTEONTRxPower)}
+
+	client := zte.NewZTEOLTClientForTest(mock, 10*time.Second)
+	client.SetDevice(newTestDevice())
+
+	onts, err := client.GetONTMetrics(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, onts, 6, "ONTs without optical readings are listed by their state")
+
+	status := make(map[string]zte.ONTStatus)
+	online := 0
+	for _, o := range onts {
+		status[fmt.Sprintf("%d/%d:%d", o.Slot, o.PONPort, o.ONTID)] = o.OperStatus
+		if o.OperStatus.IsOnline() {
+			online++
+		}
+	}
+	assert.Equal(t, map[string]zte.ONTStatus{
+		"1/1:1": zte.ONTStatusWorking,
+		"1/1:2": zte.ONTStatusWorking,
+		"1/1:3": zte.ONTStatusLOS,
+		"1/1:4": zte.ONTStatusDyingGasp,
+		"1/2:1": zte.ONTStatusWorking,
+		"1/2:2": zte.ONTStatusOffline,
+	}, status)
+	assert.Equal(t, 3, online)
 }
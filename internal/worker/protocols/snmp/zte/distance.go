@@ -0,0 +1,46 @@
+package zte
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DistanceUnit is the unit of the raw ONT distance column, which differs
+// between firmware releases.
+type DistanceUnit string
+
+const (
+	DistanceUnitMeters      DistanceUnit = "m"
+	DistanceUnitDecimeters  DistanceUnit = "dm"
+	DistanceUnitCentimeters DistanceUnit = "cm"
+)
+
+// MaxGPONReachMeters is the longest ONT distance treated as plausible. GPON
+// optics reach 20 km (class B+) to 40 km (class C+); a larger value means the
+// distance unit is wrong or the reading is garbage.
+const MaxGPONReachMeters = 40000
+
+// ParseDistanceUnit parses "m", "dm" or "cm". An empty string means meters.
+func ParseDistanceUnit(s string) (DistanceUnit, error) {
+	switch u := DistanceUnit(strings.ToLower(strings.TrimSpace(s))); u {
+	case "":
+		return DistanceUnitMeters, nil
+	case DistanceUnitMeters, DistanceUnitDecimeters, DistanceUnitCentimeters:
+		return u, nil
+	default:
+		return "", fmt.Errorf("unknown ONT distance unit %q (want m, dm or cm)", s)
+	}
+}
+
+// ToMeters converts a raw distance reading in unit u to whole meters.
+func (u DistanceUnit) ToMeters(raw int) int {
+	switch u {
+	case DistanceUnitDecimeters:
+		return int(math.Round(float64(raw) / 10))
+	case DistanceUnitCentimeters:
+		return int(math.Round(float64(raw) / 100))
+	default:
+		return raw
+	}
+}
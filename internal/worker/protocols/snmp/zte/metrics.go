@@ -7,7 +7,8 @@ type OLTSystemMetrics struct {
 	// DeviceID is the identifier of the OLT device in go-nms.
 	DeviceID string `json:"device_id"`
 
-	// Timestamp is when the metrics were collected.
+	// Timestamp is when the collection began; it is shared by all metrics
+	// read in the same session.
 	Timestamp time.Time `json:"timestamp"`
 
 	// SysDescr is the textual description of the OLT.
@@ -40,7 +41,8 @@ type PONPortMetrics struct {
 	// DeviceID is the identifier of the parent OLT device.
 	DeviceID string `json:"device_id"`
 
-	// Timestamp is when the metrics were collected.
+	// Timestamp is when the collection began; it is shared by all metrics
+	// read in the same session.
 	Timestamp time.Time `json:"timestamp"`
 
 	// PortIndex is the SNMP index of the PON port.
@@ -62,23 +64,67 @@ type PONPortMetrics struct {
 	ONTCount int `json:"ont_count"`
 }
 
+// CardMetrics holds the inventory and load of a single card (line, control or
+// power card) in a ZTE C320 shelf.
+type CardMetrics struct {
+	// DeviceID is the identifier of the parent OLT device.
+	DeviceID string `json:"device_id"`
+
+	// Timestamp is when the collection began; it is shared by all metrics
+	// read in the same session.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Slot is the shelf slot the card is installed in.
+	Slot int `json:"slot"`
+
+	// Type is the card model, e.g. "GTGO" (GPON line card) or "SMXA" (control card).
+	Type string `json:"type"`
+
+	// Status is the operational status of the card.
+	Status CardStatus `json:"status"`
+
+	// CPUUsagePercent is the card's CPU utilization (0-100).
+	CPUUsagePercent float64 `json:"cpu_usage_percent"`
+
+	// TemperatureCelsius is the card temperature in degrees Celsius.
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+
+	// MemoryUsagePercent is the card's memory utilization (0-100).
+	MemoryUsagePercent float64 `json:"memory_usage_percent"`
+
+	// MemoryTotalKB is the memory installed on the card in kilobytes.
+	MemoryTotalKB int64 `json:"memory_total_kb"`
+}
+
 // ONTMetrics holds metrics for a single ONT registered on a ZTE C320 OLT.
 type ONTMetrics struct {
 	// DeviceID is the identifier of the parent OLT device.
 	DeviceID string `json:"device_id"`
 
-	// Timestamp is when the metrics were collected.
+	// Timestamp is when the collection began; it is shared by all metrics
+	// read in the same session.
 	Timestamp time.Time `json:"timestamp"`
 
 	// PONPortIndex is the index of the PON port this ONT is connected to.
 	PONPortIndex int `json:"pon_port_index"`
 
-	// ONTIndex is the SNMP index of the ONT within its PON port.
+	// ONTIndex is the packed SNMP index of the ONT: PONPortIndex with the
+	// ONT ID in the low byte. Unlike ONTID it is unique on the OLT.
 	ONTIndex int `json:"ont_index"`
 
-	// SerialNumber is the factory serial number of the ONT.
+	// Slot and PONPort locate the PON port on the OLT, decoded from
+	// PONPortIndex; ONTID is the ONT ID within the port.
+	Slot    int `json:"slot"`
+	PONPort int `json:"pon_port"`
+	ONTID   int `json:"ont_id"`
+
+	// SerialNumber is the factory serial number of the ONT (e.g.
+	// "ZTEGC1234567"), empty when the OLT does not report one.
 	SerialNumber string `json:"serial_number"`
 
+	// Name is the operator-configured ONT name.
+	Name string `json:"name"`
+
 	// OperStatus is the current operational status of the ONT.
 	OperStatus ONTStatus `json:"oper_status"`
 
@@ -89,9 +135,73 @@ type ONTMetrics struct {
 	// TxPowerDBm is the transmit optical power of the ONT in dBm.
 	TxPowerDBm float64 `json:"tx_power_dbm"`
 
-	// DistanceMeters is the physical distance from the OLT to the ONT in meters.
+	// DistanceMeters is the physical distance from the OLT to the ONT in meters,
+	// converted from the unit configured on the client.
 	DistanceMeters int `json:"distance_meters"`
 
+	// DistanceImplausible is set when DistanceMeters is negative or beyond
+	// MaxGPONReachMeters, which usually means the distance unit is wrong.
+	DistanceImplausible bool `json:"distance_implausible"`
+
 	// Description is the user-configured description of the ONT.
 	Description string `json:"description"`
+
+	// LastDownCause is why the ONT last went offline; ONTDownCauseNone if not reported.
+	LastDownCause ONTDownCause `json:"last_down_cause"`
+
+	// LineProfile and ServiceProfile name the GPON profiles provisioned on
+	// the ONT. They are empty when no profile is assigned.
+	LineProfile    string `json:"line_profile"`
+	ServiceProfile string `json:"service_profile"`
+}
+
+// ONTInfo holds the configured identity of an ONT from the ONT config table.
+type ONTInfo struct {
+	// PONPortIndex is the ifIndex of the PON port the ONT is registered on.
+	PONPortIndex int `json:"pon_port_index"`
+
+	// ONTID is the ONT ID within its PON port.
+	ONTID int `json:"ont_id"`
+
+	// Name is the operator-configured ONT name.
+	Name string `json:"name"`
+
+	// Description is the operator-configured description, e.g. a subscriber account number.
+	Description string `json:"description"`
+
+	// SerialNumber is the factory serial number (e.g. "ZTEGC1234567").
+	SerialNumber string `json:"serial_number"`
+}
+
+// ServicePort is one service-port of an ONT, mapping its traffic to a VLAN.
+type ServicePort struct {
+	// PONPortIndex is the ifIndex of the PON port the ONT is registered on.
+	PONPortIndex int `json:"pon_port_index"`
+
+	// ONTID is the ONT ID within its PON port.
+	ONTID int `json:"ont_id"`
+
+	// ID is the service-port number, unique per ONT.
+	ID int `json:"id"`
+
+	// VPort is the GEM virtual port the service port is bound to.
+	VPort int `json:"vport"`
+
+	// UserVLAN is the subscriber-side VLAN; 0 for untagged traffic.
+	UserVLAN int `json:"user_vlan"`
+
+	// VLAN is the uplink VLAN the traffic is carried in.
+	VLAN int `json:"vlan"`
+}
+
+// SystemIdentity holds the MIB-2 system scalars used to identify the OLT vendor.
+type SystemIdentity struct {
+	// SysObjectID is the vendor-assigned object identifier, without a leading dot.
+	SysObjectID string `json:"sys_object_id"`
+
+	// SysDescr is the textual description of the OLT.
+	SysDescr string `json:"sys_descr"`
+
+	// UptimeTicks is sysUpTime in hundredths of a second.
+	UptimeTicks uint32 `json:"uptime_ticks"`
 }
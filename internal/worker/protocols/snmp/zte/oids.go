@@ -12,6 +12,10 @@ const (
 	// OIDSysDescr is the textual description of the entity (e.g., "ZTE C320 OLT").
 	OIDSysDescr = "1.3.6.1.2.1.1.1.0"
 
+	// OIDSysObjectID is the vendor's authoritative identification of the device
+	// (e.g., "1.3.6.1.4.1.3902.1082.1001.320.1" for a ZTE C320).
+	OIDSysObjectID = "1.3.6.1.2.1.1.2.0"
+
 	// OIDSysUpTime is the time (in hundredths of a second) since the network management
 	// portion of the system was last re-initialized.
 	OIDSysUpTime = "1.3.6.1.2.1.1.3.0"
@@ -42,6 +46,14 @@ const (
 	// We can try .1.3.6.1.4.1.3902.1015.2.1.1.3.1.19.1.1 (Values: 512, 512, 2048) in MB?
 	OIDZTECardMemoryTotal = "1.3.6.1.4.1.3902.1015.2.1.1.3.1.19.1.1"
 
+	// OIDZTECardType - .1.3.6.1.4.1.3902.1015.2.1.1.3.1.4.1.1 (Values: "SMXA", "GTGO", "PRAM")
+	// The installed card's model name (OctetString), indexed by slot.
+	OIDZTECardType = "1.3.6.1.4.1.3902.1015.2.1.1.3.1.4.1.1"
+
+	// OIDZTECardStatus - .1.3.6.1.4.1.3902.1015.2.1.1.3.1.5.1.1 (Values: 1, 4)
+	// Operational status of the card, see CardStatus.
+	OIDZTECardStatus = "1.3.6.1.4.1.3902.1015.2.1.1.3.1.5.1.1"
+
 	// --- ZTE PON Port OIDs (1.3.6.1.4.1.3902.1015.3.1) ---
 
 	// OIDZTEPONPortTable
@@ -75,10 +87,8 @@ const (
 
 	OIDZTEONTTable = "1.3.6.1.4.1.3902.1015.3.1.13.1"
 
-	// .3 = Status? (Value 30) - treating as OperStatus for now
-	OIDZTEONTOperStatus = "1.3.6.1.4.1.3902.1015.3.1.13.1.3"
-
 	// .4 = Distance? (Value ~7000)
+	// Meters on most firmware, but some releases report decimeters; see DistanceUnit.
 	OIDZTEONTDistance = "1.3.6.1.4.1.3902.1015.3.1.13.1.4"
 
 	// .5 = RxPower? (Value -140 -> -14.0dBm)
@@ -87,12 +97,62 @@ const (
 	// .6 = ? (Value 90) - maybe TxPower?
 	OIDZTEONTTxPower = "1.3.6.1.4.1.3902.1015.3.1.13.1.6"
 
-	// Serial Number not found in this table, using index as makeshift SN.
-	// We'll update client.go to handle this.
-	OIDZTEONTSerialNumber = "1.3.6.1.4.1.3902.1015.3.1.13.1.1" // Placeholder
-	OIDZTEONTDescription  = "1.3.6.1.4.1.3902.1015.3.1.13.1.1" // Placeholder
+	// The ONT table has no serial number or description; GetONTMetrics joins
+	// them from the ONT config table below.
+
+	// --- ZTE GPON ONT Config Table (ZTE-AN-GPON-ONT-MGMT-MIB, 1.3.6.1.4.1.3902.1012.3.28.1.1) ---
+	// Indexed by <PON ifIndex>.<ONT ID>; holds the operator-configured identity of each ONT.
+
+	// OIDZTEONTInfoName - ONT name (OctetString)
+	OIDZTEONTInfoName = "1.3.6.1.4.1.3902.1012.3.28.1.1.2"
+
+	// OIDZTEONTInfoDescription - ONT description, often the subscriber account (OctetString)
+	OIDZTEONTInfoDescription = "1.3.6.1.4.1.3902.1012.3.28.1.1.3"
+
+	// OIDZTEONTInfoSerialNumber - ONT serial number, 4-byte vendor ID + 4 bytes (OctetString)
+	OIDZTEONTInfoSerialNumber = "1.3.6.1.4.1.3902.1012.3.28.1.1.5"
+
+	// OIDZTEONTLineProfile - name of the assigned GPON line profile, empty when none (OctetString)
+	OIDZTEONTLineProfile = "1.3.6.1.4.1.3902.1012.3.28.1.1.9"
+
+	// OIDZTEONTServiceProfile - name of the assigned GPON service profile, empty when none (OctetString)
+	OIDZTEONTServiceProfile = "1.3.6.1.4.1.3902.1012.3.28.1.1.10"
+
+	// --- ZTE GPON ONT State Table (1.3.6.1.4.1.3902.1012.3.28.2.1) ---
+	// Same <PON ifIndex>.<ONT ID> index as the ONT config table.
+
+	// OIDZTEONTOperStatus - ONT phase state, zxAnGponOnuPhaseState (see ONTStatus).
+	// The ONT table's .3 column (values around 30) is not a state.
+	OIDZTEONTOperStatus = "1.3.6.1.4.1.3902.1012.3.28.2.1.4"
+
+	// OIDZTEONTLastDownCause - reason the ONT last went offline (see ONTDownCause)
+	OIDZTEONTLastDownCause = "1.3.6.1.4.1.3902.1012.3.28.2.1.7"
+
+	// --- ZTE GPON Service-Port Table (1.3.6.1.4.1.3902.1012.3.50.13.2.1) ---
+	// Indexed by <PON ifIndex>.<ONT ID>.<service-port ID>; one row per
+	// "service-port" line of the ONT's configuration.
+
+	// OIDZTEServicePortVPort - GEM virtual port the service port is bound to (Integer)
+	OIDZTEServicePortVPort = "1.3.6.1.4.1.3902.1012.3.50.13.2.1.3"
+
+	// OIDZTEServicePortUserVLAN - VLAN tagged by the subscriber side, 0 when untagged (Integer)
+	OIDZTEServicePortUserVLAN = "1.3.6.1.4.1.3902.1012.3.50.13.2.1.4"
+
+	// OIDZTEServicePortVLAN - VLAN the traffic is carried in on the uplink (Integer)
+	OIDZTEServicePortVLAN = "1.3.6.1.4.1.3902.1012.3.50.13.2.1.5"
 )
 
+// ontIDMask selects the ONT ID from a packed ONT index, which is the PON port
+// ifIndex with the ONT ID in the low byte.
+const ontIDMask = 0xFF
+
+// PONLocation returns the card slot and the port on the card of a GPON port
+// ifIndex, which packs them as 0x1<rack><slot><port>00; e.g. 0x10010100 is
+// slot 1, port 1 (gpon-olt_1/1/1).
+func PONLocation(ifIndex int) (slot, port int) {
+	return (ifIndex >> 16) & 0xFF, (ifIndex >> 8) & 0xFF
+}
+
 // PONPortStatus represents the operational status of a PON port.
 type PONPortStatus int
 
@@ -114,25 +174,138 @@ func (s PONPortStatus) String() string {
 	}
 }
 
-// ONTStatus represents the operational status of an ONT.
+// CardStatus is the operational status of a card in a shelf slot
+// (zxAnCardOperStatus).
+type CardStatus int
+
+const (
+	CardStatusUnknown      CardStatus = 0
+	CardStatusInService    CardStatus = 1
+	CardStatusNotInService CardStatus = 2
+	CardStatusHWOnline     CardStatus = 3 // detected, not yet configured
+	CardStatusHWOffline    CardStatus = 4 // configured, but not detected in the slot
+	CardStatusConfiguring  CardStatus = 5
+	CardStatusConfigFailed CardStatus = 6
+	CardStatusTypeMismatch CardStatus = 7 // installed card differs from the configured type
+	CardStatusDeactivated  CardStatus = 8
+	CardStatusFaulty       CardStatus = 9
+)
+
+// String returns a human-readable representation of the card status.
+func (s CardStatus) String() string {
+	switch s {
+	case CardStatusInService:
+		return "in_service"
+	case CardStatusNotInService:
+		return "not_in_service"
+	case CardStatusHWOnline:
+		return "hw_online"
+	case CardStatusHWOffline:
+		return "hw_offline"
+	case CardStatusConfiguring:
+		return "configuring"
+	case CardStatusConfigFailed:
+		return "config_failed"
+	case CardStatusTypeMismatch:
+		return "type_mismatch"
+	case CardStatusDeactivated:
+		return "deactivated"
+	case CardStatusFaulty:
+		return "faulty"
+	default:
+		return "unknown"
+	}
+}
+
+// ONTStatus represents the operational status of an ONT, using the ZTE GPON
+// ONT phase state enumeration (zxAnGponOnuPhaseState).
 type ONTStatus int
 
 const (
-	ONTStatusOnline  ONTStatus = 1
-	ONTStatusOffline ONTStatus = 2
-	ONTStatusUnreg   ONTStatus = 3
-	ONTStatusUnknown ONTStatus = 0
+	ONTStatusUnknown    ONTStatus = 0
+	ONTStatusLogging    ONTStatus = 1 // ranging / registering with the OLT
+	ONTStatusLOS        ONTStatus = 2 // loss of signal, e.g. fiber cut
+	ONTStatusSyncMIB    ONTStatus = 3 // registered, synchronising configuration
+	ONTStatusWorking    ONTStatus = 4 // in service
+	ONTStatusDyingGasp  ONTStatus = 5 // ONT reported power loss
+	ONTStatusAuthFailed ONTStatus = 6 // serial/password authentication rejected
+	ONTStatusOffline    ONTStatus = 7 // deregistered, e.g. powered off
 )
 
 // String returns a human-readable representation of the ONT status.
 func (s ONTStatus) String() string {
 	switch s {
-	case ONTStatusOnline:
-		return "online"
+	case ONTStatusLogging:
+		return "logging"
+	case ONTStatusLOS:
+		return "los"
+	case ONTStatusSyncMIB:
+		return "sync_mib"
+	case ONTStatusWorking:
+		return "working"
+	case ONTStatusDyingGasp:
+		return "dying_gasp"
+	case ONTStatusAuthFailed:
+		return "auth_failed"
 	case ONTStatusOffline:
 		return "offline"
-	case ONTStatusUnreg:
-		return "unregistered"
+	default:
+		return "unknown"
+	}
+}
+
+// IsOnline reports whether the ONT is in service.
+func (s ONTStatus) IsOnline() bool {
+	return s == ONTStatusWorking
+}
+
+// ONTDownCause is the reason an ONT last went offline, as recorded by the OLT.
+type ONTDownCause int
+
+const (
+	ONTDownCauseNone         ONTDownCause = 0
+	ONTDownCauseUnknown      ONTDownCause = 1
+	ONTDownCauseLOS          ONTDownCause = 2
+	ONTDownCauseLOSi         ONTDownCause = 3
+	ONTDownCauseLOFi         ONTDownCause = 4
+	ONTDownCauseSFi          ONTDownCause = 5
+	ONTDownCauseLOAi         ONTDownCause = 6
+	ONTDownCauseLOAMi        ONTDownCause = 7
+	ONTDownCauseAuthFail     ONTDownCause = 8
+	ONTDownCauseDyingGasp    ONTDownCause = 9 // reported by the OLT as "PowerOff"
+	ONTDownCauseDeactivated  ONTDownCause = 10
+	ONTDownCauseDeactiveFail ONTDownCause = 11
+	ONTDownCauseReboot       ONTDownCause = 12
+	ONTDownCauseShutdown     ONTDownCause = 13
+)
+
+// String returns a human-readable description of the down cause.
+func (c ONTDownCause) String() string {
+	switch c {
+	case ONTDownCauseLOS:
+		return "loss of signal (fiber cut or disconnected)"
+	case ONTDownCauseLOSi:
+		return "loss of signal at ONT"
+	case ONTDownCauseLOFi:
+		return "loss of frame at ONT"
+	case ONTDownCauseSFi:
+		return "signal fail at ONT"
+	case ONTDownCauseLOAi:
+		return "loss of acknowledgement from ONT"
+	case ONTDownCauseLOAMi:
+		return "loss of PLOAM from ONT"
+	case ONTDownCauseAuthFail:
+		return "authentication failed"
+	case ONTDownCauseDyingGasp:
+		return "dying gasp (power loss)"
+	case ONTDownCauseDeactivated:
+		return "deactivated by OLT"
+	case ONTDownCauseDeactiveFail:
+		return "deactivation failed"
+	case ONTDownCauseReboot:
+		return "rebooted"
+	case ONTDownCauseShutdown:
+		return "shut down"
 	default:
 		return "unknown"
 	}
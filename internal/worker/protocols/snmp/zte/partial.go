@@ -0,0 +1,63 @@
+package zte
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrONTLimitReached is returned together with the ONTs collected so far when
+// a walk yields more ONTs than the client's limit. It may be joined with a
+// *PartialError.
+var ErrONTLimitReached = errors.New("ONT limit reached")
+
+// ColumnError records a table column whose walk failed.
+type ColumnError struct {
+	// Column is the metric the column feeds, e.g. "rx_power".
+	Column string
+
+	// OID is the base OID of the column.
+	OID string
+
+	// Err is the walk error.
+	Err error
+}
+
+func (e ColumnError) Error() string {
+	return fmt.Sprintf("failed to walk %s (%s): %v", e.Column, e.OID, e.Err)
+}
+
+func (e ColumnError) Unwrap() error { return e.Err }
+
+// PartialError is returned together with a non-nil result when some columns of
+// a table walk failed but others succeeded. Fields fed by the failed columns
+// are left at their zero value.
+type PartialError struct {
+	Columns []ColumnError
+}
+
+func (e *PartialError) Error() string {
+	return "partial result: " + strings.Join(e.Warnings(), "; ")
+}
+
+// Warnings returns one message per failed column.
+func (e *PartialError) Warnings() []string {
+	warnings := make([]string, 0, len(e.Columns))
+	for _, col := range e.Columns {
+		warnings = append(warnings, col.Error())
+	}
+	return warnings
+}
+
+// valueErrors collects the values of one column that had an unusable type.
+type valueErrors []error
+
+func (e valueErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e valueErrors) Unwrap() []error { return e }
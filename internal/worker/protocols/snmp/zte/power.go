@@ -0,0 +1,17 @@
+package zte
+
+import "math"
+
+// NormalizePowerDBm prepares an optical power reading for JSON. Negative zero
+// becomes 0, so it is not rendered as "-0", and NaN or ±Inf, which
+// encoding/json refuses to marshal, become nil. Such values come from bad
+// scaling rather than from the OLT and carry no reading.
+func NormalizePowerDBm(dbm float64) *float64 {
+	if math.IsNaN(dbm) || math.IsInf(dbm, 0) {
+		return nil
+	}
+	if dbm == 0 {
+		dbm = 0 // drops the sign of -0
+	}
+	return &dbm
+}
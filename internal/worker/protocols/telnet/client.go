@@ -0,0 +1,387 @@
+// Package telnet provides a minimal Telnet client for legacy devices that
+// expose only a Telnet CLI: option negotiation, login and command execution
+// with prompt detection.
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/yourorg/nms-go/internal/common/telemetry"
+)
+
+// protocolLabel is the protocol label used for connect telemetry.
+const protocolLabel = "telnet"
+
+// DefaultPort is the standard Telnet port.
+const DefaultPort = 23
+
+// ErrLoginFailed is returned when the device rejects the supplied credentials.
+var ErrLoginFailed = errors.New("telnet login failed")
+
+// Telnet command bytes (RFC 854).
+const (
+	cmdSE   = 240
+	cmdSB   = 250
+	cmdWILL = 251
+	cmdWONT = 252
+	cmdDO   = 253
+	cmdDONT = 254
+	cmdIAC  = 255
+
+	optEcho            = 1
+	optSuppressGoAhead = 3
+)
+
+// Config controls prompt detection and timeouts.
+// Zero values are replaced with the defaults from DefaultConfig.
+type Config struct {
+	// Timeout bounds each read of a prompt or command output.
+	Timeout time.Duration
+
+	// LoginPrompts and PasswordPrompts are matched case-insensitively
+	// against the end of the output during login.
+	LoginPrompts    []string
+	PasswordPrompts []string
+
+	// FailurePatterns indicate rejected credentials (case-insensitive).
+	FailurePatterns []string
+
+	// PromptSuffixes end a shell prompt, e.g. "switch#" or "router>".
+	PromptSuffixes []string
+
+	// PagerPatterns are paging markers answered with a space.
+	PagerPatterns []string
+}
+
+// DefaultConfig returns settings that work with most Cisco-like and Linux CLIs.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:         10 * time.Second,
+		LoginPrompts:    []string{"login:", "username:", "user name:"},
+		PasswordPrompts: []string{"password:"},
+		FailurePatterns: []string{"incorrect", "invalid", "failed", "denied"},
+		PromptSuffixes:  []string{"#", ">", "$", "%"},
+		PagerPatterns:   []string{"--more--", "---- more ----"},
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.Timeout <= 0 {
+		c.Timeout = d.Timeout
+	}
+	if len(c.LoginPrompts) == 0 {
+		c.LoginPrompts = d.LoginPrompts
+	}
+	if len(c.PasswordPrompts) == 0 {
+		c.PasswordPrompts = d.PasswordPrompts
+	}
+	if len(c.FailurePatterns) == 0 {
+		c.FailurePatterns = d.FailurePatterns
+	}
+	if len(c.PromptSuffixes) == 0 {
+		c.PromptSuffixes = d.PromptSuffixes
+	}
+	if len(c.PagerPatterns) == 0 {
+		c.PagerPatterns = d.PagerPatterns
+	}
+	return c
+}
+
+// Client is a Telnet session to a single device.
+type Client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	cfg    Config
+
+	// prompt is the shell prompt detected after login, e.g. "switch#".
+	prompt string
+}
+
+// Dial opens a Telnet connection to addr (host:port).
+// The connect duration and outcome are recorded in the telemetry package.
+func Dial(ctx context.Context, addr string, cfg Config) (c *Client, err error) {
+	start := time.Now()
+	defer func() { telemetry.ObserveConnect(protocolLabel, start, err) }()
+
+	cfg = cfg.withDefaults()
+	dialer := net.Dialer{Timeout: cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("telnet connect to %s failed: %w", addr, err)
+	}
+
+	return &Client{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		cfg:    cfg,
+	}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Prompt returns the shell prompt detected by Login.
+func (c *Client) Prompt() string {
+	return c.prompt
+}
+
+// Login answers the username and password prompts and waits for a shell prompt.
+// Devices that go straight to a password prompt (no username) are supported.
+func (c *Client) Login(username, password string) error {
+	out, err := c.readUntil(func(buf []byte) bool {
+		return hasSuffixFold(buf, c.cfg.LoginPrompts) || hasSuffixFold(buf, c.cfg.PasswordPrompts)
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for login prompt: %w", err)
+	}
+
+	if !hasSuffixFold(out, c.cfg.PasswordPrompts) {
+		if err := c.writeLine(username); err != nil {
+			return err
+		}
+		if _, err := c.readUntil(func(buf []byte) bool {
+			return hasSuffixFold(buf, c.cfg.PasswordPrompts)
+		}); err != nil {
+			return fmt.Errorf("waiting for password prompt: %w", err)
+		}
+	}
+
+	if err := c.writeLine(password); err != nil {
+		return err
+	}
+
+	out, err = c.readUntil(func(buf []byte) bool {
+		return c.isShellPrompt(buf) ||
+			hasSuffixFold(buf, c.cfg.LoginPrompts) ||
+			hasSuffixFold(buf, c.cfg.PasswordPrompts)
+	})
+	if err != nil {
+		// Some devices report the failure and hang up
+		if containsFold(out, c.cfg.FailurePatterns) {
+			return ErrLoginFailed
+		}
+		return fmt.Errorf("waiting for shell prompt: %w", err)
+	}
+
+	// Being asked for credentials again means they were rejected
+	if !c.isShellPrompt(out) {
+		return ErrLoginFailed
+	}
+
+	c.prompt = lastLine(out)
+	return nil
+}
+
+// Execute runs a command and returns its output without the echoed command
+// and trailing prompt. Paged output is continued automatically.
+func (c *Client) Execute(command string) (string, error) {
+	if c.prompt == "" {
+		return "", errors.New("telnet: not logged in")
+	}
+
+	if err := c.writeLine(command); err != nil {
+		return "", err
+	}
+
+	var out []byte
+	for {
+		chunk, err := c.readUntil(func(buf []byte) bool {
+			return bytes.HasSuffix(bytes.TrimRight(buf, " "), []byte(c.prompt)) ||
+				hasSuffixFold(buf, c.cfg.PagerPatterns)
+		})
+		out = append(out, chunk...)
+		if err != nil {
+			return cleanOutput(out, command, c.prompt, c.cfg.PagerPatterns), fmt.Errorf("reading command output: %w", err)
+		}
+
+		if !hasSuffixFold(chunk, c.cfg.PagerPatterns) {
+			break
+		}
+		if _, err := c.conn.Write([]byte(" ")); err != nil {
+			return "", fmt.Errorf("telnet write failed: %w", err)
+		}
+	}
+
+	return cleanOutput(out, command, c.prompt, c.cfg.PagerPatterns), nil
+}
+
+func (c *Client) writeLine(s string) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.cfg.Timeout)); err != nil {
+		return err
+	}
+	if _, err := c.conn.Write([]byte(s + "\r\n")); err != nil {
+		return fmt.Errorf("telnet write failed: %w", err)
+	}
+	return nil
+}
+
+// readUntil reads data, handling option negotiation, until match reports true.
+// match is evaluated whenever the received data has been fully consumed, so a
+// prompt-like character in the middle of a burst does not end the read early.
+func (c *Client) readUntil(match func(buf []byte) bool) ([]byte, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.cfg.Timeout)); err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	for {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return buf, err
+		}
+
+		if b == cmdIAC {
+			literal, err := c.handleCommand()
+			if err != nil {
+				return buf, err
+			}
+			if literal {
+				buf = append(buf, b)
+			}
+		} else if b != 0 {
+			buf = append(buf, b)
+		}
+
+		if c.reader.Buffered() == 0 && match(buf) {
+			return buf, nil
+		}
+	}
+}
+
+// handleCommand processes a sequence following IAC. It reports true when the
+// sequence was an escaped 0xFF data byte. The client refuses every option
+// except the server echoing and suppressing go-ahead.
+func (c *Client) handleCommand() (bool, error) {
+	cmd, err := c.reader.ReadByte()
+	if err != nil {
+		return false, err
+	}
+
+	switch cmd {
+	case cmdIAC:
+		return true, nil
+	case cmdDO, cmdDONT, cmdWILL, cmdWONT:
+		opt, err := c.reader.ReadByte()
+		if err != nil {
+			return false, err
+		}
+		var reply byte
+		switch cmd {
+		case cmdDO:
+			reply = cmdWONT
+		case cmdWILL:
+			reply = cmdDONT
+			if opt == optEcho || opt == optSuppressGoAhead {
+				reply = cmdDO
+			}
+		default:
+			// DONT/WONT need no answer since nothing is enabled
+			return false, nil
+		}
+		_, err = c.conn.Write([]byte{cmdIAC, reply, opt})
+		return false, err
+	case cmdSB:
+		// Skip subnegotiation up to IAC SE
+		for {
+			b, err := c.reader.ReadByte()
+			if err != nil {
+				return false, err
+			}
+			if b != cmdIAC {
+				continue
+			}
+			if next, err := c.reader.ReadByte(); err != nil || next == cmdSE {
+				return false, err
+			}
+		}
+	default:
+		return false, nil
+	}
+}
+
+// isShellPrompt reports whether the last line of buf looks like a CLI prompt.
+func (c *Client) isShellPrompt(buf []byte) bool {
+	line := strings.TrimRight(lastLine(buf), " ")
+	if line == "" {
+		return false
+	}
+	for _, suffix := range c.cfg.PromptSuffixes {
+		if strings.HasSuffix(line, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanOutput strips the echoed command, pager markers and trailing prompt.
+func cleanOutput(out []byte, command, prompt string, pagers []string) string {
+	s := strings.ReplaceAll(string(out), "\r", "")
+	for _, p := range pagers {
+		s = replaceFold(s, p, "")
+	}
+
+	s = strings.TrimRight(s, " ")
+	s = strings.TrimSuffix(s, prompt)
+
+	lines := strings.Split(s, "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == command {
+		lines = lines[1:]
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n ")
+}
+
+func lastLine(buf []byte) string {
+	s := strings.ReplaceAll(string(buf), "\r", "")
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(s)
+}
+
+func hasSuffixFold(buf []byte, patterns []string) bool {
+	tail := strings.ToLower(strings.TrimRight(string(buf), " "))
+	for _, p := range patterns {
+		if strings.HasSuffix(tail, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(buf []byte, patterns []string) bool {
+	s := strings.ToLower(string(buf))
+	for _, p := range patterns {
+		if strings.Contains(s, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceFold(s, old, repl string) string {
+	lower := strings.ToLower(s)
+	old = strings.ToLower(old)
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, old)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		b.WriteString(repl)
+		s = s[i+len(old):]
+		lower = lower[i+len(old):]
+	}
+}
@@ -0,0 +1,192 @@
+package telnet_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/worker/protocols/telnet"
+)
+
+// mockServer emulates a Cisco-like Telnet CLI on a local port.
+type mockServer struct {
+	listener net.Listener
+	username string
+	password string
+	commands map[string]string // command -> output; "\f" marks a page break
+
+	mu          sync.Mutex
+	negotiation [][]byte // IAC sequences received from the client
+}
+
+func newMockServer(t *testing.T, username, password string, commands map[string]string) *mockServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &mockServer{listener: l, username: username, password: password, commands: commands}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *mockServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *mockServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *mockServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	// WILL ECHO, DO NAWS
+	conn.Write([]byte{255, 251, 1, 255, 253, 31})
+	conn.Write([]byte("\r\nUser Access Verification\r\n\r\nUsername: "))
+
+	for {
+		user, err := s.readLine(r)
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("\r\nPassword: "))
+		pass, err := s.readLine(r)
+		if err != nil {
+			return
+		}
+		if user == s.username && pass == s.password {
+			break
+		}
+		conn.Write([]byte("\r\n% Login invalid\r\n\r\nUsername: "))
+	}
+
+	conn.Write([]byte("\r\nswitch#"))
+	for {
+		cmd, err := s.readLine(r)
+		if err != nil {
+			return
+		}
+
+		out, ok := s.commands[cmd]
+		if !ok {
+			out = "% Invalid input detected"
+		}
+
+		// Echo the command, then send each page waiting for a space in between
+		pages := strings.Split(out, "\f")
+		conn.Write([]byte(cmd + "\r\n"))
+		for i, page := range pages {
+			conn.Write([]byte(strings.ReplaceAll(page, "\n", "\r\n")))
+			if i < len(pages)-1 {
+				conn.Write([]byte("\r\n --More-- "))
+				if b, err := r.ReadByte(); err != nil || b != ' ' {
+					return
+				}
+			}
+		}
+		conn.Write([]byte("\r\nswitch#"))
+	}
+}
+
+// readLine reads a CRLF-terminated line, recording and skipping IAC sequences.
+func (s *mockServer) readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case b == 255:
+			seq := []byte{b, 0, 0}
+			if seq[1], err = r.ReadByte(); err != nil {
+				return "", err
+			}
+			if seq[2], err = r.ReadByte(); err != nil {
+				return "", err
+			}
+			s.mu.Lock()
+			s.negotiation = append(s.negotiation, seq)
+			s.mu.Unlock()
+		case b == '\n':
+			return strings.TrimRight(string(line), "\r"), nil
+		default:
+			line = append(line, b)
+		}
+	}
+}
+
+func dial(t *testing.T, addr string) *telnet.Client {
+	t.Helper()
+	c, err := telnet.Dial(context.Background(), addr, telnet.Config{Timeout: 2 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_LoginAndExecute(t *testing.T) {
+	srv := newMockServer(t, "admin", "secret", map[string]string{
+		"show version": "Cisco IOS Software, C2960 Software\nuptime is 5 weeks",
+	})
+	c := dial(t, srv.addr())
+
+	require.NoError(t, c.Login("admin", "secret"))
+	assert.Equal(t, "switch#", c.Prompt())
+
+	out, err := c.Execute("show version")
+	require.NoError(t, err)
+	assert.Equal(t, "Cisco IOS Software, C2960 Software\nuptime is 5 weeks", out)
+
+	// Option negotiation: accept the server echoing, refuse window size
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Contains(t, srv.negotiation, []byte{255, 253, 1})
+	assert.Contains(t, srv.negotiation, []byte{255, 252, 31})
+}
+
+func TestClient_LoginFailed(t *testing.T) {
+	srv := newMockServer(t, "admin", "secret", nil)
+	c := dial(t, srv.addr())
+
+	err := c.Login("admin", "wrong")
+	assert.True(t, errors.Is(err, telnet.ErrLoginFailed))
+}
+
+func TestClient_ExecutePagedOutput(t *testing.T) {
+	srv := newMockServer(t, "admin", "secret", map[string]string{
+		"show interfaces status": "Gi0/1 connected\nGi0/2 notconnect\fGi0/3 connected",
+	})
+	c := dial(t, srv.addr())
+	require.NoError(t, c.Login("admin", "secret"))
+
+	out, err := c.Execute("show interfaces status")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Gi0/1 connected")
+	assert.Contains(t, out, "Gi0/3 connected")
+	assert.NotContains(t, out, "More")
+	assert.NotContains(t, out, "switch#")
+}
+
+func TestClient_ExecuteBeforeLogin(t *testing.T) {
+	srv := newMockServer(t, "admin", "secret", nil)
+	c := dial(t, srv.addr())
+
+	_, err := c.Execute("show version")
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/rates"
+	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
+)
+
+// snmpPollTimeout bounds each request of a generic SNMP poll.
+const snmpPollTimeout = 10 * time.Second
+
+// NewSNMPClient returns a generic SNMP client for background polls.
+func NewSNMPClient() snmpclient.SNMPClient {
+	return snmpclient.NewGoSNMPClient()
+}
+
+// SNMPCollector reads system and interface metrics over the standard MIBs
+// (MIB-II, IF-MIB, HOST-RESOURCES-MIB), for SNMP devices without a
+// vendor-specific collector, and pings for the round-trip time. Each
+// interface is written to the sink as its own point, with rx_bps and tx_bps
+// since the previous poll this worker made of it when Rates is set.
+type SNMPCollector struct {
+	Ping      PingFunc
+	NewClient func() snmpclient.SNMPClient
+	Rates     *rates.Calculator
+}
+
+func (c *SNMPCollector) Collect(ctx context.Context, task commonModel.PollTask, groups Groups) Collection {
+	snmp := groups.System || groups.Interfaces
+	if !snmp && !groups.Reachability {
+		return Collection{Err: noGroupsError(task.DeviceType, task.Protocol)}
+	}
+
+	var result Collection
+	if snmp {
+		result = c.collectSNMP(ctx, task, groups)
+	}
+
+	if groups.Reachability {
+		// The ping decides success when nothing was read over SNMP, so a
+		// device with a wrong community is still reported reachable
+		var reachable bool
+		result.RTT, reachable = c.Ping(task.IPAddress)
+		if !result.Success {
+			result.Success = reachable
+		}
+	}
+	return result
+}
+
+// collectSNMP reads the enabled groups in one session. The poll succeeds
+// when any group was read.
+func (c *SNMPCollector) collectSNMP(ctx context.Context, task commonModel.PollTask, groups Groups) Collection {
+	var result Collection
+
+	client := c.NewClient()
+	if err := client.Connect(ctx, task.IPAddress, task.SNMPCommunity, gosnmp.Version2c, snmpPollTimeout); err != nil {
+		log.Printf("Error connecting to %s over SNMP: %v", task.DeviceID, err)
+		return result
+	}
+	defer client.Disconnect()
+
+	result.Values = make(map[string]interface{})
+
+	if groups.System {
+		system, err := snmpclient.GetSystemMetrics(ctx, client)
+		if err != nil {
+			log.Printf("Error reading system metrics from %s: %v", task.DeviceID, err)
+		} else {
+			result.Success = true
+			fields := map[string]interface{}{"uptime": system.UptimeSeconds}
+			result.Values["uptime_seconds"] = system.UptimeSeconds
+			if system.CPUUsagePercent != nil {
+				fields["cpu_usage"] = *system.CPUUsagePercent
+				result.Values["cpu_usage_percent"] = *system.CPUUsagePercent
+			}
+			result.Points = append(result.Points, Point{
+				Measurement: "system_metrics",
+				Fields:      fields,
+				Time:        system.Timestamp,
+			})
+		}
+	}
+
+	if groups.Interfaces {
+		interfaces, err := snmpclient.GetInterfaceMetrics(ctx, client)
+		if err != nil {
+			log.Printf("Error reading interface metrics from %s: %v", task.DeviceID, err)
+		} else {
+			result.Success = true
+			var rx, tx float64
+			var withRate int
+			for _, m := range interfaces {
+				fields := map[string]interface{}{
+					"bytes_in":   m.InOctets,
+					"bytes_out":  m.OutOctets,
+					"in_errors":  m.InErrors,
+					"out_errors": m.OutErrors,
+					"oper_up":    m.OperUp,
+				}
+				if rate, ok := c.rate(task.DeviceID, m); ok {
+					fields["rx_bps"], fields["tx_bps"] = rate.RxBps, rate.TxBps
+					rx, tx = rx+rate.RxBps, tx+rate.TxBps
+					withRate++
+				}
+				result.Points = append(result.Points, Point{
+					Measurement: "interface_metrics",
+					Tags: map[string]string{
+						"interface": m.Name,
+						"if_index":  strconv.Itoa(m.Index),
+					},
+					Fields: fields,
+					Time:   m.Timestamp,
+				})
+			}
+			if withRate > 0 {
+				result.Values["rx_bps"], result.Values["tx_bps"] = rx, tx
+			}
+		}
+	}
+
+	return result
+}
+
+// rate returns the traffic of interface m since its previous poll
+func (c *SNMPCollector) rate(deviceID string, m *snmpclient.InterfaceMetrics) (rates.Rate, bool) {
+	if c.Rates == nil {
+		return rates.Rate{}, false
+	}
+	return c.Rates.Update(deviceID+"/"+strconv.Itoa(m.Index), m.Timestamp, m.InOctets, m.OutOctets, m.CounterBits)
+}
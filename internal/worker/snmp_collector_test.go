@@ -0,0 +1,205 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/rates"
+	"github.com/yourorg/nms-go/internal/worker"
+	snmpclient "github.com/yourorg/nms-go/internal/worker/protocols/snmp"
+)
+
+// fakeSNMPAgent answers gets and walks from canned PDUs.
+type fakeSNMPAgent struct {
+	connectErr error
+	scalars    []gosnmp.SnmpPDU
+	columns    map[string][]gosnmp.SnmpPDU
+
+	host         string
+	community    string
+	disconnected bool
+}
+
+func (f *fakeSNMPAgent) Connect(ctx context.Context, host, community string, version gosnmp.SnmpVersion, timeout time.Duration) error {
+	f.host, f.community = host, community
+	return f.connectErr
+}
+
+func (f *fakeSNMPAgent) Disconnect() error {
+	f.disconnected = true
+	return nil
+}
+
+func (f *fakeSNMPAgent) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	return &gosnmp.SnmpPacket{Variables: f.scalars}, nil
+}
+
+func (f *fakeSNMPAgent) Walk(oid string, fn gosnmp.WalkFunc) error {
+	rows, ok := f.columns[oid]
+	if !ok {
+		return errors.New("no such object")
+	}
+	for _, pdu := range rows {
+		if err := fn(pdu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeSNMPAgent) GetBulk(oids []string, nonRepeaters uint8, maxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newFakeSwitch() *fakeSNMPAgent {
+	return &fakeSNMPAgent{
+		scalars: []gosnmp.SnmpPDU{{Name: snmpclient.OIDSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(360000)}},
+		columns: map[string][]gosnmp.SnmpPDU{
+			snmpclient.OIDHrProcessorLoad: {{Name: snmpclient.OIDHrProcessorLoad + ".1", Value: 25}},
+			snmpclient.OIDIfInOctets: {
+				{Name: snmpclient.OIDIfInOctets + ".1", Value: uint(1000)},
+				{Name: snmpclient.OIDIfInOctets + ".2", Value: uint(0)},
+			},
+			snmpclient.OIDIfOutOctets: {{Name: snmpclient.OIDIfOutOctets + ".1", Value: uint(2000)}},
+			snmpclient.OIDIfOperStatus: {
+				{Name: snmpclient.OIDIfOperStatus + ".1", Value: 1},
+				{Name: snmpclient.OIDIfOperStatus + ".2", Value: 2},
+			},
+			snmpclient.OIDIfName: {
+				{Name: snmpclient.OIDIfName + ".1", Value: []byte("ge-0/0/1")},
+				{Name: snmpclient.OIDIfName + ".2", Value: []byte("ge-0/0/2")},
+			},
+		},
+	}
+}
+
+func newSNMPWorker(agent *fakeSNMPAgent, ms *taggedSink, cfg config.WorkerConfig, probes *fakeProbes) (*worker.Worker, *fakePublisher) {
+	publisher := &fakePublisher{}
+	collectors := map[string]worker.Collector{
+		"snmp": &worker.SNMPCollector{Ping: probes.ping, NewClient: func() snmpclient.SNMPClient { return agent }},
+	}
+	return worker.NewWorkerWithCollectors(nil, publisher, ms, cfg, collectors), publisher
+}
+
+var switchTask = commonModel.PollTask{
+	DeviceID:      "sw-1",
+	IPAddress:     "10.0.0.5",
+	DeviceType:    "switch",
+	Protocol:      "snmp",
+	SNMPCommunity: "s3cret",
+}
+
+func TestProcessTask_SNMPWritesSystemAndInterfacePoints(t *testing.T) {
+	agent := newFakeSwitch()
+	ms := &taggedSink{}
+	probes := &fakeProbes{}
+	w, publisher := newSNMPWorker(agent, ms, config.WorkerConfig{}, probes)
+
+	w.ProcessTask(context.Background(), switchTask)
+
+	assert.Equal(t, "10.0.0.5", agent.host)
+	assert.Equal(t, "s3cret", agent.community)
+	assert.True(t, agent.disconnected)
+	assert.Equal(t, []string{"10.0.0.5"}, probes.pinged)
+
+	assert.Equal(t, []string{"device_poll", "system_metrics", "interface_metrics", "interface_metrics"}, ms.measurements())
+	for _, p := range ms.points {
+		assert.Equal(t, "sw-1", p.tags["device_id"], p.measurement)
+	}
+
+	system := ms.points[1]
+	assert.Equal(t, int64(3600), system.fields["uptime"])
+	assert.Equal(t, 25.0, system.fields["cpu_usage"])
+
+	iface := ms.points[2]
+	assert.Equal(t, "ge-0/0/1", iface.tags["interface"])
+	assert.Equal(t, uint64(1000), iface.fields["bytes_in"])
+	assert.Equal(t, uint64(2000), iface.fields["bytes_out"])
+	assert.Equal(t, true, iface.fields["oper_up"])
+	assert.Equal(t, false, ms.points[3].fields["oper_up"])
+
+	require.Len(t, publisher.metrics, 1)
+	values := publisher.metrics[0].Values
+	assert.Equal(t, true, values["success"])
+	assert.Equal(t, int64(3600), values["uptime_seconds"])
+	assert.Equal(t, 25.0, values["cpu_usage_percent"])
+}
+
+func TestProcessTask_SNMPProfileRestrictsGroups(t *testing.T) {
+	agent := newFakeSwitch()
+	ms := &taggedSink{}
+	probes := &fakeProbes{}
+	cfg := config.WorkerConfig{Profiles: map[string][]string{"switch": {"interfaces"}}}
+	w, publisher := newSNMPWorker(agent, ms, cfg, probes)
+
+	w.ProcessTask(context.Background(), switchTask)
+
+	assert.Empty(t, probes.pinged)
+	assert.Equal(t, []string{"device_poll", "interface_metrics", "interface_metrics"}, ms.measurements())
+	assert.NotContains(t, publisher.metrics[0].Values, "uptime_seconds")
+}
+
+func TestProcessTask_SNMPConnectFailure(t *testing.T) {
+	t.Run("ping decides", func(t *testing.T) {
+		agent := newFakeSwitch()
+		agent.connectErr = errors.New("dial udp: no route to host")
+		ms := &taggedSink{}
+		w, publisher := newSNMPWorker(agent, ms, config.WorkerConfig{}, &fakeProbes{})
+
+		w.ProcessTask(context.Background(), switchTask)
+
+		assert.Equal(t, []string{"device_poll"}, ms.measurements())
+		assert.Equal(t, true, publisher.metrics[0].Values["success"], "a device answering ping is reachable without SNMP")
+		assert.Equal(t, 5.0, publisher.metrics[0].Values["rtt_ms"])
+	})
+
+	t.Run("without reachability", func(t *testing.T) {
+		agent := newFakeSwitch()
+		agent.connectErr = errors.New("dial udp: no route to host")
+		cfg := config.WorkerConfig{Profiles: map[string][]string{"switch": {"system", "interfaces"}}}
+		w, publisher := newSNMPWorker(agent, &taggedSink{}, cfg, &fakeProbes{})
+
+		w.ProcessTask(context.Background(), switchTask)
+
+		assert.Equal(t, false, publisher.metrics[0].Values["success"])
+	})
+}
+
+func TestProcessTask_SNMPInterfaceRates(t *testing.T) {
+	agent := newFakeSwitch()
+	ms := &taggedSink{}
+	publisher := &fakePublisher{}
+	collectors := map[string]worker.Collector{"snmp": &worker.SNMPCollector{
+		Ping:      (&fakeProbes{}).ping,
+		NewClient: func() snmpclient.SNMPClient { return agent },
+		Rates:     rates.New(),
+	}}
+	cfg := config.WorkerConfig{Profiles: map[string][]string{"switch": {"interfaces"}}}
+	w := worker.NewWorkerWithCollectors(nil, publisher, ms, cfg, collectors)
+
+	w.ProcessTask(context.Background(), switchTask)
+	assert.NotContains(t, ms.points[1].fields, "rx_bps", "no rate on the first poll")
+	assert.NotContains(t, publisher.metrics[0].Values, "rx_bps")
+
+	// Over 10ms the first interface moves 1000 bytes in and 500 out
+	time.Sleep(10 * time.Millisecond)
+	agent.columns[snmpclient.OIDIfInOctets][0].Value = uint(2000)
+	agent.columns[snmpclient.OIDIfOutOctets][0].Value = uint(2500)
+	ms.points = nil
+	w.ProcessTask(context.Background(), switchTask)
+
+	require.Equal(t, []string{"device_poll", "interface_metrics", "interface_metrics"}, ms.measurements())
+	rx := ms.points[1].fields["rx_bps"].(float64)
+	tx := ms.points[1].fields["tx_bps"].(float64)
+	assert.Greater(t, rx, 0.0)
+	assert.InDelta(t, rx/2, tx, rx*0.01, "tx moved half the bytes rx did")
+	assert.Equal(t, 0.0, ms.points[2].fields["rx_bps"], "an idle interface has a zero rate")
+	assert.Equal(t, rx, publisher.metrics[1].Values["rx_bps"], "the alert metric sums the interfaces")
+}
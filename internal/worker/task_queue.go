@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+)
+
+// taskQueue holds poll tasks waiting for a free polling slot. The highest
+// priority is taken first, and tasks of equal priority in arrival order.
+// It also counts the tasks queued or being polled, so a worker pulling
+// tasks takes no more than it has slots for.
+type taskQueue struct {
+	mu     sync.Mutex
+	ready  *sync.Cond
+	free   *sync.Cond
+	tasks  taskHeap
+	seq    uint64
+	active int
+	closed bool
+}
+
+func newTaskQueue() *taskQueue {
+	q := &taskQueue{}
+	q.ready = sync.NewCond(&q.mu)
+	q.free = sync.NewCond(&q.mu)
+	return q
+}
+
+// Push queues task along with the message it arrived in. Tasks pushed after
+// Close are dropped and Push returns false.
+func (q *taskQueue) Push(task commonModel.PollTask, msg *nats.Msg) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+	heap.Push(&q.tasks, queuedTask{task: task, msg: msg, seq: q.seq})
+	q.seq++
+	q.active++
+	q.ready.Signal()
+	return true
+}
+
+// Pop waits for a task and removes it from the queue. It returns false once
+// the queue is closed. Call Done once the task is handled.
+func (q *taskQueue) Pop() (queuedTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.tasks) == 0 && !q.closed {
+		q.ready.Wait()
+	}
+	if q.closed {
+		return queuedTask{}, false
+	}
+	return heap.Pop(&q.tasks).(queuedTask), true
+}
+
+// Done marks a popped task as handled, freeing its slot.
+func (q *taskQueue) Done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.active--
+	q.free.Signal()
+}
+
+// WaitCapacity waits until fewer than limit tasks are queued or being
+// polled and returns how many more fit. It returns false once the queue is
+// closed.
+func (q *taskQueue) WaitCapacity(limit int) (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.active >= limit && !q.closed {
+		q.free.Wait()
+	}
+	if q.closed {
+		return 0, false
+	}
+	return limit - q.active, true
+}
+
+// Close wakes every waiting Pop and WaitCapacity and drops the queued tasks,
+// returning the messages they arrived in.
+func (q *taskQueue) Close() []*nats.Msg {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dropped := make([]*nats.Msg, 0, len(q.tasks))
+	for _, t := range q.tasks {
+		dropped = append(dropped, t.msg)
+	}
+	q.active -= len(q.tasks)
+	q.tasks = nil
+	q.closed = true
+	q.ready.Broadcast()
+	q.free.Broadcast()
+	return dropped
+}
+
+type queuedTask struct {
+	task commonModel.PollTask
+	msg  *nats.Msg
+	seq  uint64
+}
+
+// taskHeap implements heap.Interface, ordering by priority and then by
+// arrival.
+type taskHeap []queuedTask
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].task.Priority != h[j].task.Priority {
+		return h[i].task.Priority > h[j].task.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x interface{}) { *h = append(*h, x.(queuedTask)) }
+
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,249 @@
+package worker_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourorg/nms-go/internal/common/config"
+	commonModel "github.com/yourorg/nms-go/internal/common/model"
+	"github.com/yourorg/nms-go/internal/common/queue"
+	"github.com/yourorg/nms-go/internal/worker"
+)
+
+// gatedCollector records the order devices are polled in and holds every
+// poll until release is closed.
+type gatedCollector struct {
+	mu      sync.Mutex
+	order   []string
+	started chan string
+	release chan struct{}
+}
+
+func (c *gatedCollector) Collect(ctx context.Context, task commonModel.PollTask, groups worker.Groups) worker.Collection {
+	c.mu.Lock()
+	c.order = append(c.order, task.DeviceID)
+	c.mu.Unlock()
+
+	c.started <- task.DeviceID
+	<-c.release
+	return worker.Collection{Success: true}
+}
+
+// deliver hands task to the worker's subscription as NATS would.
+func deliver(t *testing.T, nc *fakeConn, task commonModel.PollTask) {
+	t.Helper()
+	data, err := json.Marshal(task)
+	require.NoError(t, err)
+	nc.mu.Lock()
+	handler := nc.handler
+	nc.mu.Unlock()
+	handler(&nats.Msg{Subject: worker.PollTasksSubject, Data: data})
+}
+
+func TestWorker_BacklogIsPolledHighestPriorityFirst(t *testing.T) {
+	nc := newFakeConn()
+	collector := &gatedCollector{started: make(chan string, 16), release: make(chan struct{})}
+	cfg := config.WorkerConfig{ID: "worker-1", Concurrency: 1}
+	w := worker.NewWorkerWithCollectors(nc, &fakePublisher{}, &fakeSink{}, cfg, map[string]worker.Collector{"snmp": collector})
+
+	done := make(chan struct{})
+	go func() {
+		w.Start()
+		close(done)
+	}()
+	select {
+	case <-nc.subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not subscribe")
+	}
+
+	// Occupy the only slot so the rest queue up behind it.
+	deliver(t, nc, commonModel.PollTask{DeviceID: "busy", Protocol: "snmp"})
+	select {
+	case <-collector.started:
+	case <-time.After(time.Second):
+		t.Fatal("first task was not polled")
+	}
+
+	for _, task := range []commonModel.PollTask{
+		{DeviceID: "cpe-1", Protocol: "snmp"},
+		{DeviceID: "core-1", Protocol: "snmp", Priority: 10},
+		{DeviceID: "edge-1", Protocol: "snmp", Priority: 5},
+		{DeviceID: "cpe-2", Protocol: "snmp"},
+		{DeviceID: "core-2", Protocol: "snmp", Priority: 10},
+	} {
+		deliver(t, nc, task)
+	}
+	close(collector.release)
+
+	for i := 0; i < 5; i++ {
+		select {
+		case <-collector.started:
+		case <-time.After(time.Second):
+			t.Fatal("queued tasks were not polled")
+		}
+	}
+	w.Stop()
+	<-done
+
+	assert.Equal(t, []string{"busy", "core-1", "core-2", "edge-1", "cpe-1", "cpe-2"}, collector.order,
+		"higher priority first, arrival order within a priority")
+}
+
+func TestWorker_ConcurrencyLimitsPollsInProgress(t *testing.T) {
+	nc := newFakeConn()
+	collector := &gatedCollector{started: make(chan string, 16), release: make(chan struct{})}
+	cfg := config.WorkerConfig{ID: "worker-1", Concurrency: 2}
+	w := worker.NewWorkerWithCollectors(nc, &fakePublisher{}, &fakeSink{}, cfg, map[string]worker.Collector{"snmp": collector})
+
+	done := make(chan struct{})
+	go func() {
+		w.Start()
+		close(done)
+	}()
+	<-nc.subscribed
+
+	for _, id := range []string{"d1", "d2", "d3"} {
+		deliver(t, nc, commonModel.PollTask{DeviceID: id, Protocol: "snmp"})
+	}
+	for i := 0; i < 2; i++ {
+		<-collector.started
+	}
+	select {
+	case id := <-collector.started:
+		t.Fatalf("%s was polled while both slots were busy", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(collector.release)
+	<-collector.started
+	w.Stop()
+	<-done
+}
+
+func TestWorker_DropsStaleTasks(t *testing.T) {
+	nc := newFakeConn()
+	collector := &gatedCollector{started: make(chan string, 16), release: make(chan struct{})}
+	close(collector.release)
+	publisher := &fakePublisher{}
+	cfg := config.WorkerConfig{ID: "worker-1", Concurrency: 1}
+	w := worker.NewWorkerWithCollectors(nc, publisher, &fakeSink{}, cfg, map[string]worker.Collector{"snmp": collector})
+
+	done := make(chan struct{})
+	go func() {
+		w.Start()
+		close(done)
+	}()
+	<-nc.subscribed
+
+	now := time.Now()
+	deliver(t, nc, commonModel.PollTask{DeviceID: "stale", Protocol: "snmp", Timestamp: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)})
+	deliver(t, nc, commonModel.PollTask{DeviceID: "fresh", Protocol: "snmp", Timestamp: now, ExpiresAt: now.Add(time.Hour)})
+	deliver(t, nc, commonModel.PollTask{DeviceID: "no-expiry", Protocol: "snmp"})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-collector.started:
+		case <-time.After(time.Second):
+			t.Fatal("current tasks were not polled")
+		}
+	}
+	w.Stop()
+	<-done
+
+	assert.Equal(t, []string{"fresh", "no-expiry"}, collector.order)
+	assert.Len(t, publisher.metrics, 2, "no metric for the stale task")
+}
+
+// fakePullSub hands out pending tasks as a JetStream pull consumer would.
+type fakePullSub struct {
+	mu      sync.Mutex
+	pending []*nats.Msg
+	fetched int
+}
+
+func (s *fakePullSub) Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		s.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		s.mu.Lock()
+		return nil, nats.ErrTimeout
+	}
+	if batch > len(s.pending) {
+		batch = len(s.pending)
+	}
+	msgs := s.pending[:batch]
+	s.pending = s.pending[batch:]
+	s.fetched += batch
+	return msgs, nil
+}
+
+func (s *fakePullSub) Unsubscribe() error { return nil }
+
+func (s *fakePullSub) counts() (pending, fetched int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending), s.fetched
+}
+
+// fakePullConn is a fakeConn whose queue groups pull.
+type fakePullConn struct {
+	*fakeConn
+	sub *fakePullSub
+}
+
+func (f *fakePullConn) PullSubscribe(subj, group string) (queue.PullSubscription, error) {
+	f.mu.Lock()
+	f.subs = append(f.subs, subscription{subject: subj, queue: group})
+	f.mu.Unlock()
+	f.subscribed <- struct{}{}
+	return f.sub, nil
+}
+
+func TestWorker_PullsOnlyWhatItCanPoll(t *testing.T) {
+	sub := &fakePullSub{}
+	for _, id := range []string{"d1", "d2", "d3", "d4", "d5"} {
+		data, err := json.Marshal(commonModel.PollTask{DeviceID: id, Protocol: "snmp"})
+		require.NoError(t, err)
+		sub.pending = append(sub.pending, &nats.Msg{Subject: worker.PollTasksSubject, Data: data})
+	}
+	nc := &fakePullConn{fakeConn: newFakeConn(), sub: sub}
+	collector := &gatedCollector{started: make(chan string, 16), release: make(chan struct{})}
+	cfg := config.WorkerConfig{ID: "worker-1", Group: "nms-workers", Concurrency: 2}
+	w := worker.NewWorkerWithCollectors(nc, &fakePublisher{}, &fakeSink{}, cfg, map[string]worker.Collector{"snmp": collector})
+
+	done := make(chan struct{})
+	go func() {
+		w.Start()
+		close(done)
+	}()
+	<-nc.subscribed
+	require.Equal(t, []subscription{{subject: worker.PollTasksSubject, queue: "nms-workers"}}, nc.subs)
+
+	for i := 0; i < 2; i++ {
+		<-collector.started
+	}
+	time.Sleep(50 * time.Millisecond)
+	pending, fetched := sub.counts()
+	assert.Equal(t, 2, fetched, "no more than the free slots are fetched")
+	assert.Equal(t, 3, pending, "the rest stay with the stream")
+
+	close(collector.release)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-collector.started:
+		case <-time.After(time.Second):
+			t.Fatal("remaining tasks were not pulled")
+		}
+	}
+	w.Stop()
+	<-done
+}